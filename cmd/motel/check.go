@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/andrewh/motel/pkg/synth"
 	"github.com/spf13/cobra"
@@ -13,6 +15,8 @@ func checkCmd() *cobra.Command {
 		maxDepth         int
 		maxFanOut        int
 		maxSpans         int
+		maxLatency       time.Duration
+		maxCardinality   int
 		maxSpansPerTrace int
 		samples          int
 		seed             uint64
@@ -20,13 +24,15 @@ func checkCmd() *cobra.Command {
 		checksPath       string
 		sampleStrategy   string
 		skipScenarios    bool
+		noColor          bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "check <topology.yaml | URL>",
 		Short: "Run structural checks on a topology",
 		Long: "Run structural checks on a topology.\n\n" +
-			"The topology source can be a local file path or an HTTP/HTTPS URL.\n" +
+			"The topology source can be a local file path, an HTTP/HTTPS URL, or - to\n" +
+			"read it from stdin.\n" +
 			"URL fetches have a 10-second timeout and a 10 MB response body limit.\n\n" +
 			"Scenarios defined in the topology are explored automatically: every\n" +
 			"distinct combination of co-active scenarios is checked alongside the\n" +
@@ -41,9 +47,12 @@ func checkCmd() *cobra.Command {
 			return cobra.ExactArgs(1)(cmd, args)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if maxDepth < 0 || maxFanOut < 0 || maxSpans < 0 || maxSpansPerTrace < 0 || samples < 0 {
+			if maxDepth < 0 || maxFanOut < 0 || maxSpans < 0 || maxCardinality < 0 || maxSpansPerTrace < 0 || samples < 0 {
 				return fmt.Errorf("limit and sample flags must be non-negative")
 			}
+			if maxLatency < 0 {
+				return fmt.Errorf("--max-latency must not be negative, got %s", maxLatency)
+			}
 			strategy, err := synth.ParseSampleStrategy(sampleStrategy)
 			if err != nil {
 				return err
@@ -96,6 +105,8 @@ func checkCmd() *cobra.Command {
 				MaxDepth:         maxDepth,
 				MaxFanOut:        maxFanOut,
 				MaxSpans:         maxSpans,
+				MaxLatency:       maxLatency,
+				MaxCardinality:   maxCardinality,
 				MaxSpansPerTrace: maxSpansPerTrace,
 				Samples:          samples,
 				Seed:             seed,
@@ -108,33 +119,47 @@ func checkCmd() *cobra.Command {
 
 			anyFailed := false
 			w := cmd.OutOrStdout()
+			style := newCheckStyle(results, colorEnabled(w, noColor))
 			for _, r := range results {
-				status := "PASS"
+				head := style.head(r)
 				if !r.Pass {
-					status = "FAIL"
 					anyFailed = true
 				}
 
 				switch r.Name {
 				case synth.CheckNameMaxDepth:
-					_, _ = fmt.Fprintf(w, "%s  %s: %d (limit: %d)\n", status, r.Name, r.Actual, r.Limit)
+					_, _ = fmt.Fprintf(w, "%s %d (limit: %d)\n", head, r.Actual, r.Limit)
 					if len(r.Path) > 0 {
 						_, _ = fmt.Fprintf(w, "      path: %s\n", strings.Join(r.Path, " \u2192 "))
 					}
 				case synth.CheckNameMaxFanOut:
-					_, _ = fmt.Fprintf(w, "%s  %s: %d (limit: %d)\n", status, r.Name, r.Actual, r.Limit)
+					_, _ = fmt.Fprintf(w, "%s %d (limit: %d)\n", head, r.Actual, r.Limit)
 					if r.Ref != "" {
 						_, _ = fmt.Fprintf(w, "      worst: %s\n", r.Ref)
 					}
 				case synth.CheckNameMaxSpans:
-					line := fmt.Sprintf("%s  %s: %d static worst-case", status, r.Name, r.Actual)
+					line := fmt.Sprintf("%s %d static worst-case", head, r.Actual)
 					if r.Sampled != nil {
 						line += fmt.Sprintf(", %d observed/%d samples", *r.Sampled, r.SamplesRun)
 					}
 					line += fmt.Sprintf(" (limit: %d)", r.Limit)
 					_, _ = fmt.Fprintln(w, line)
+				case synth.CheckNameMaxLatency:
+					_, _ = fmt.Fprintf(w, "%s %s (limit: %s)\n", head, r.Latency, r.LatencyLimit)
+					if len(r.Path) > 0 {
+						_, _ = fmt.Fprintf(w, "      path: %s\n", strings.Join(r.Path, " \u2192 "))
+					}
+				case synth.CheckNameMaxCardinality:
+					actual := strconv.Itoa(r.Actual)
+					if r.Actual == synth.UnboundedCardinality {
+						actual = "unbounded"
+					}
+					_, _ = fmt.Fprintf(w, "%s %s (limit: %d)\n", head, actual, r.Limit)
+					if r.Ref != "" {
+						_, _ = fmt.Fprintf(w, "      worst: %s\n", r.Ref)
+					}
 				default:
-					_, _ = fmt.Fprintf(w, "%s  %s: %d (limit: %d)\n", status, r.Name, r.Actual, r.Limit)
+					_, _ = fmt.Fprintf(w, "%s %d (limit: %d)\n", head, r.Actual, r.Limit)
 				}
 
 				if len(r.Scenarios) > 0 {
@@ -158,6 +183,8 @@ func checkCmd() *cobra.Command {
 	cmd.Flags().IntVar(&maxDepth, "max-depth", 10, "fail if worst-case trace depth exceeds this")
 	cmd.Flags().IntVar(&maxFanOut, "max-fan-out", 100, "fail if worst-case children per span exceeds this")
 	cmd.Flags().IntVar(&maxSpans, "max-spans", 10000, "fail if worst-case spans per trace exceeds this")
+	cmd.Flags().DurationVar(&maxLatency, "max-latency", 0, "fail if worst-case critical-path latency exceeds this (0 = skip the check)")
+	cmd.Flags().IntVar(&maxCardinality, "max-cardinality", 0, "fail if any metric attribute can take more distinct values than this (0 = skip the check)")
 	cmd.Flags().IntVar(&samples, "samples", 1000, "sampled traces for empirical measurement")
 	cmd.Flags().Uint64Var(&seed, "seed", 0, "random seed for reproducibility (0 = random)")
 	cmd.Flags().IntVar(&maxSpansPerTrace, "max-spans-per-trace", 0, fmt.Sprintf("maximum spans per sampled trace (0 = default %d)", synth.DefaultMaxSpansPerTrace))
@@ -165,8 +192,37 @@ func checkCmd() *cobra.Command {
 	cmd.Flags().StringVar(&checksPath, "checks", "", "YAML checks file or URL with structural thresholds")
 	cmd.Flags().StringVar(&sampleStrategy, "sample-strategy", string(synth.SampleStrategyRandom), "sample strategy: random or swarm")
 	cmd.Flags().BoolVar(&skipScenarios, "skip-scenarios", false, "check the baseline topology only, ignoring scenarios")
+	cmd.Flags().BoolVar(&noColor, "no-color", false, "disable colored, aligned output on a terminal")
 
 	return cmd
 }
 
 func checkLimitPtr(v int) *int { return &v }
+
+// checkStyle formats the "PASS  name:" head of each check line. Plain
+// output is left exactly as is; colored output also pads the names so the
+// values line up.
+type checkStyle struct {
+	color     bool
+	nameWidth int
+}
+
+func newCheckStyle(results []synth.CheckResult, color bool) checkStyle {
+	s := checkStyle{color: color}
+	for _, r := range results {
+		s.nameWidth = max(s.nameWidth, len(r.Name))
+	}
+	return s
+}
+
+// head returns the status and name that start r's line, ending in a colon.
+func (s checkStyle) head(r synth.CheckResult) string {
+	status, code := "PASS", ansiGreen
+	if !r.Pass {
+		status, code = "FAIL", ansiRed
+	}
+	if !s.color {
+		return status + "  " + r.Name + ":"
+	}
+	return fmt.Sprintf("%s%s%s  %-*s", code, status, ansiReset, s.nameWidth+1, r.Name+":")
+}
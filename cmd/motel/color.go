@@ -0,0 +1,28 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// ANSI escape sequences for colored terminal output.
+const (
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiReset = "\x1b[0m"
+)
+
+// colorEnabled reports whether output to w should be colored: w must be a
+// terminal, and neither --no-color nor the NO_COLOR environment variable
+// may be set.
+func colorEnabled(w io.Writer, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
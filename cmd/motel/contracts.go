@@ -0,0 +1,232 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/andrewh/motel/pkg/synth"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func exportContractsCmd() *cobra.Command {
+	var (
+		outDir     string
+		semconvDir string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export-contracts <topology.yaml | URL>",
+		Short: "Write consumer-driven contract files derived from the call graph",
+		Long: "Write one contract file per provider service, describing what each calling\n" +
+			"service expects from it.\n\n" +
+			"Requests take their HTTP method and path from operation names like\n" +
+			"\"GET /users\", falling back to static http.request.method and http.route\n" +
+			"attributes. Response status codes come from the provider operation's\n" +
+			"http.response.status_code attribute; operations in the http domain without\n" +
+			"one respond 200.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("missing topology file or URL\n\nUsage: motel export-contracts <topology.yaml | URL>")
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := synth.LoadConfig(args[0])
+			if err != nil {
+				return err
+			}
+			if err := synth.ValidateConfig(cfg); err != nil {
+				return err
+			}
+			topo, err := buildTopology(cfg, semconvDir)
+			if err != nil {
+				return err
+			}
+
+			contracts := buildContracts(topo)
+			if len(contracts) == 0 {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "No cross-service calls found; no contracts written")
+				return nil
+			}
+			if err := os.MkdirAll(outDir, 0o750); err != nil {
+				return fmt.Errorf("creating output directory: %w", err)
+			}
+			for _, c := range contracts {
+				data, err := yaml.Marshal(c)
+				if err != nil {
+					return fmt.Errorf("encoding contract for %s: %w", c.Provider, err)
+				}
+				path := filepath.Join(outDir, contractFileName(c.Provider))
+				if err := os.WriteFile(path, data, 0o600); err != nil {
+					return fmt.Errorf("writing contract: %w", err)
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), path)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&outDir, "out", "contracts", "directory to write contract files into")
+	cmd.Flags().StringVar(&semconvDir, "semconv", "", "directory of additional semantic convention YAML files")
+
+	return cmd
+}
+
+// contract lists the interactions that consumers expect from one provider.
+type contract struct {
+	Provider     string                `yaml:"provider"`
+	Interactions []contractInteraction `yaml:"interactions"`
+}
+
+// contractInteraction is a single consumer operation calling a provider operation.
+type contractInteraction struct {
+	Consumer          string           `yaml:"consumer"`
+	ConsumerOperation string           `yaml:"consumer_operation"`
+	Operation         string           `yaml:"operation"`
+	Async             bool             `yaml:"async,omitempty"`
+	Request           contractRequest  `yaml:"request,omitempty"`
+	Response          contractResponse `yaml:"response,omitempty"`
+}
+
+type contractRequest struct {
+	Method string `yaml:"method,omitempty"`
+	Path   string `yaml:"path,omitempty"`
+}
+
+// contractResponse describes the expected responses. StatusCodes maps each
+// code to its relative weight as configured on the provider operation.
+type contractResponse struct {
+	StatusCodes map[string]int `yaml:"status_codes,omitempty"`
+	ErrorRate   float64        `yaml:"error_rate,omitempty"`
+}
+
+// buildContracts groups every cross-service call in the topology by the
+// called service. Calls within a service are internal and produce no contract.
+// Providers and interactions are sorted so output is stable.
+func buildContracts(topo *synth.Topology) []contract {
+	type key struct{ consumer, consumerOp, provider, op string }
+	seen := make(map[key]bool)
+	byProvider := make(map[string][]contractInteraction)
+
+	for _, svc := range topo.Services {
+		for _, op := range svc.Operations {
+			for _, call := range slices.Concat(op.Calls, op.OneOf) {
+				callee := call.Operation
+				if callee.Service == svc {
+					continue
+				}
+				k := key{svc.Name, op.Name, callee.Service.Name, callee.Name}
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+				byProvider[callee.Service.Name] = append(byProvider[callee.Service.Name], contractInteraction{
+					Consumer:          svc.Name,
+					ConsumerOperation: op.Name,
+					Operation:         callee.Name,
+					Async:             call.Async,
+					Request:           contractRequestFor(callee),
+					Response: contractResponse{
+						StatusCodes: statusCodeWeights(callee),
+						ErrorRate:   callee.ErrorRate,
+					},
+				})
+			}
+		}
+	}
+
+	contracts := make([]contract, 0, len(byProvider))
+	for _, provider := range slices.Sorted(maps.Keys(byProvider)) {
+		interactions := byProvider[provider]
+		slices.SortFunc(interactions, func(a, b contractInteraction) int {
+			return cmp.Or(
+				cmp.Compare(a.Consumer, b.Consumer),
+				cmp.Compare(a.ConsumerOperation, b.ConsumerOperation),
+				cmp.Compare(a.Operation, b.Operation),
+			)
+		})
+		contracts = append(contracts, contract{Provider: provider, Interactions: interactions})
+	}
+	return contracts
+}
+
+var httpMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true,
+	"DELETE": true, "OPTIONS": true, "TRACE": true, "CONNECT": true,
+}
+
+// contractRequestFor derives the request line for an operation, preferring a
+// "METHOD /path" operation name over static HTTP attributes.
+func contractRequestFor(op *synth.Operation) contractRequest {
+	if method, path, ok := strings.Cut(op.Name, " "); ok && httpMethods[method] && strings.HasPrefix(path, "/") {
+		return contractRequest{Method: method, Path: path}
+	}
+	var req contractRequest
+	if v, ok := staticAttribute(op, "http.request.method"); ok {
+		req.Method = fmt.Sprint(v)
+	}
+	if v, ok := staticAttribute(op, "http.route"); ok {
+		req.Path = fmt.Sprint(v)
+	}
+	return req
+}
+
+func staticAttribute(op *synth.Operation, key string) (any, bool) {
+	for _, attr := range op.Attributes {
+		if attr.Key != key {
+			continue
+		}
+		if s, ok := attr.Gen.(*synth.StaticValue); ok {
+			return s.Value, true
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+// statusCodeWeights reads the http.response.status_code generator. A static
+// code gets the full weight of 100; other generator kinds are not described.
+// An http domain operation without the attribute has its code derived from
+// the span outcome, so it succeeds with 200.
+func statusCodeWeights(op *synth.Operation) map[string]int {
+	for _, attr := range op.Attributes {
+		if attr.Key != "http.response.status_code" {
+			continue
+		}
+		switch gen := attr.Gen.(type) {
+		case *synth.StaticValue:
+			return map[string]int{fmt.Sprint(gen.Value): 100}
+		case *synth.WeightedChoice:
+			weights := make(map[string]int, len(gen.Choices))
+			prev := 0
+			for i, choice := range gen.Choices {
+				weights[fmt.Sprint(choice)] = gen.CumulWeights[i] - prev
+				prev = gen.CumulWeights[i]
+			}
+			return weights
+		}
+		return nil
+	}
+	if slices.Contains(op.Domains, "http") {
+		return map[string]int{"200": 100}
+	}
+	return nil
+}
+
+// contractFileName turns a service name into a file name, replacing
+// characters that would escape the output directory.
+func contractFileName(service string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == filepath.Separator {
+			return '_'
+		}
+		return r
+	}, service)
+	return name + ".yaml"
+}
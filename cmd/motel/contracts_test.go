@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExportContractsCommand(t *testing.T) {
+	t.Parallel()
+
+	cfg := `
+version: 1
+services:
+  gateway:
+    operations:
+      GET /users:
+        duration: 30ms
+        calls:
+          - backend.GET /users
+          - gateway.auth
+      auth:
+        duration: 5ms
+  backend:
+    operations:
+      GET /users:
+        duration: 20ms
+        error_rate: 2%
+        attributes:
+          http.response.status_code:
+            values: {"200": 95, "500": 5}
+traffic:
+  rate: 10/s
+`
+	path := writeTestConfig(t, cfg)
+	outDir := filepath.Join(t.TempDir(), "contracts")
+
+	root := rootCmd()
+	root.SetArgs([]string{"export-contracts", "--out", outDir, path})
+	var out bytes.Buffer
+	root.SetOut(&out)
+	require.NoError(t, root.Execute())
+
+	entries, err := os.ReadDir(outDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "only backend is called by another service")
+	assert.Equal(t, "backend.yaml", entries[0].Name())
+	assert.Contains(t, out.String(), filepath.Join(outDir, "backend.yaml"))
+
+	data, err := os.ReadFile(filepath.Join(outDir, "backend.yaml"))
+	require.NoError(t, err)
+	var c contract
+	require.NoError(t, yaml.Unmarshal(data, &c))
+
+	assert.Equal(t, "backend", c.Provider)
+	require.Len(t, c.Interactions, 1)
+	got := c.Interactions[0]
+	assert.Equal(t, "gateway", got.Consumer)
+	assert.Equal(t, "GET /users", got.ConsumerOperation)
+	assert.Equal(t, contractRequest{Method: "GET", Path: "/users"}, got.Request)
+	assert.Equal(t, map[string]int{"200": 95, "500": 5}, got.Response.StatusCodes)
+	assert.InDelta(t, 0.02, got.Response.ErrorRate, 1e-9)
+}
+
+func TestContractFileName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "payments.yaml", contractFileName("payments"))
+	assert.Equal(t, "team_payments.yaml", contractFileName("team/payments"))
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/andrewh/motel/pkg/synth"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// maxDescribeExamples caps how many distinct example values are listed per key.
+const maxDescribeExamples = 5
+
+// slowStatusAttribute is the slow_status that adds synth.slow to slow spans.
+const slowStatusAttribute = "attribute"
+
+func describeCmd() *cobra.Command {
+	var (
+		format         string
+		semconvDir     string
+		labelScenarios bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "describe <topology.yaml | URL>",
+		Short: "List the span and resource attribute keys a topology emits",
+		Long: "List the span and resource attribute keys a topology emits, with example\n" +
+			"values and the services that emit them, for pre-configuring search tags\n" +
+			"and dashboards in a tracing backend.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("missing topology file or URL\n\nUsage: motel describe <topology.yaml | URL>")
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf("--format must be text or json, got %q", format)
+			}
+			cfg, err := synth.LoadConfig(args[0])
+			if err != nil {
+				return err
+			}
+			if err := synth.ValidateConfig(cfg); err != nil {
+				return err
+			}
+			topo, err := buildTopology(cfg, semconvDir)
+			if err != nil {
+				return err
+			}
+			scenarios, err := synth.BuildScenarios(cfg.Scenarios, topo)
+			if err != nil {
+				return err
+			}
+
+			desc := describeTopology(topo, scenarios, labelScenarios)
+			if format == "json" {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(desc)
+			}
+			return writeDescription(cmd.OutOrStdout(), desc)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "output format: text or json")
+	cmd.Flags().StringVar(&semconvDir, "semconv", "", "directory of additional semantic convention YAML files")
+	cmd.Flags().BoolVar(&labelScenarios, "label-scenarios", false, "include the synth.scenarios attribute added by 'motel run --label-scenarios'")
+
+	return cmd
+}
+
+// topologyDescription lists the attribute keys a topology emits, the
+// operations whose spans can be marked slow, and the services that flap.
+type topologyDescription struct {
+	SpanAttributes     []describedAttribute     `json:"span_attributes"`
+	ResourceAttributes []describedAttribute     `json:"resource_attributes"`
+	SlowOperations     []describedSlowOperation `json:"slow_operations,omitempty"`
+	FlappingServices   []describedFlap          `json:"flapping_services,omitempty"`
+}
+
+// describedAttribute is one attribute key with example values and the
+// services whose telemetry carries it.
+type describedAttribute struct {
+	Key      string   `json:"key"`
+	Examples []string `json:"examples"`
+	Services []string `json:"services"`
+}
+
+// describedSlowOperation is an operation with a slow_threshold and what a
+// span exceeding it does: error, attribute (synth.slow), or unset.
+type describedSlowOperation struct {
+	Operation string `json:"operation"`
+	Threshold string `json:"threshold"`
+	Status    string `json:"status"`
+}
+
+// describedFlap is a service's flap: how long each up and down window lasts,
+// and how much either side of that a window can vary.
+type describedFlap struct {
+	Service string `json:"service"`
+	Up      string `json:"up"`
+	Down    string `json:"down"`
+	Jitter  string `json:"jitter,omitempty"`
+}
+
+// attributeCollector accumulates keys, examples, and services in a stable order.
+type attributeCollector map[string]*describedAttribute
+
+func (c attributeCollector) add(key, service string, examples ...any) {
+	attr, ok := c[key]
+	if !ok {
+		attr = &describedAttribute{Key: key}
+		c[key] = attr
+	}
+	if !slices.Contains(attr.Services, service) {
+		attr.Services = append(attr.Services, service)
+	}
+	for _, ex := range examples {
+		s := fmt.Sprint(ex)
+		if len(attr.Examples) < maxDescribeExamples && !slices.Contains(attr.Examples, s) {
+			attr.Examples = append(attr.Examples, s)
+		}
+	}
+}
+
+func (c attributeCollector) sorted() []describedAttribute {
+	out := make([]describedAttribute, 0, len(c))
+	for _, key := range slices.Sorted(maps.Keys(c)) {
+		attr := *c[key]
+		slices.Sort(attr.Services)
+		out = append(out, attr)
+	}
+	return out
+}
+
+// describeTopology collects the attributes that 'motel run' would emit for
+// topo: configured and domain attributes, trace attributes, scenario
+// attribute overrides, and the synth.* and other attributes the engine adds
+// itself.
+func describeTopology(topo *synth.Topology, scenarios []synth.Scenario, labelScenarios bool) topologyDescription {
+	rng := rand.New(rand.NewPCG(1, 2)) //nolint:gosec // example values only
+	span := attributeCollector{}
+	res := attributeCollector{}
+	var slow []describedSlowOperation
+	var flaps []describedFlap
+
+	for _, kv := range resource.Default().Attributes() {
+		if kv.Key == "service.name" {
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(topo.Services)) {
+			res.add(string(kv.Key), name, kv.Value.Emit())
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(topo.Services)) {
+		svc := topo.Services[name]
+		res.add("service.name", name, name)
+		res.add("motel.version", name, version)
+		for _, k := range slices.Sorted(maps.Keys(svc.ResourceAttributes)) {
+			res.add(k, name, svc.ResourceAttributes[k])
+		}
+
+		span.add("synth.service", name, name)
+		for _, k := range slices.Sorted(maps.Keys(svc.Attributes)) {
+			span.add(k, name, svc.Attributes[k])
+		}
+		if svc.Regions != nil {
+			span.add("cloud.region", name, exampleValues(svc.Regions, rng)...)
+		}
+		if svc.Flap != nil {
+			flap := describedFlap{Service: name, Up: svc.Flap.Up.String(), Down: svc.Flap.Down.String()}
+			if svc.Flap.Jitter > 0 {
+				flap.Jitter = svc.Flap.Jitter.String()
+			}
+			flaps = append(flaps, flap)
+		}
+		// Trace attributes are generated at the root and carried by every
+		// span of the trace, whichever service it reaches.
+		for _, a := range topo.TraceAttributes {
+			span.add(a.Key, name, exampleValues(a.Gen, rng)...)
+		}
+		// Scenario trace attributes and labels likewise reach every
+		// service while the scenario is active.
+		for _, sc := range scenarios {
+			for _, a := range sc.TraceAttributes {
+				span.add(a.Key, name, exampleValues(a.Gen, rng)...)
+			}
+			for _, k := range slices.Sorted(maps.Keys(sc.Labels)) {
+				span.add(k, name, sc.Labels[k])
+			}
+		}
+
+		for _, opName := range slices.Sorted(maps.Keys(svc.Operations)) {
+			op := svc.Operations[opName]
+			span.add("synth.operation", name, opName)
+			for _, a := range op.Attributes {
+				span.add(a.Key, name, exampleValues(a.Gen, rng)...)
+			}
+			// Error type attributes come first, so a code an error type maps
+			// to leads the derived ones.
+			for _, et := range op.ErrorTypes {
+				for _, a := range et.Attributes {
+					span.add(a.Key, name, exampleValues(a.Gen, rng)...)
+				}
+			}
+			if codes := synth.GRPCStatusCodes(op); len(codes) > 0 {
+				span.add("rpc.grpc.status_code", name, anySlice(codes)...)
+			}
+			if codes := synth.HTTPStatusCodes(op); len(codes) > 0 {
+				span.add("http.response.status_code", name, anySlice(codes)...)
+			}
+			if op.BaggageAsAttributes {
+				for _, k := range slices.Sorted(maps.Keys(op.Baggage)) {
+					span.add("baggage."+k, name, op.Baggage[k])
+				}
+			}
+			// Call attributes are set on the callee's span, so they are
+			// listed under the callee's service.
+			calls := slices.Concat(op.Calls, op.OneOf)
+			for _, sc := range scenarios {
+				calls = append(calls, sc.Overrides[op.Ref].AddCalls...)
+			}
+			for _, call := range calls {
+				for _, a := range call.Attributes {
+					span.add(a.Key, call.Operation.Service.Name, exampleValues(a.Gen, rng)...)
+				}
+			}
+			if op.Cache != nil {
+				span.add("cache.hit", name, true, false)
+			}
+			if op.SlowThreshold > 0 {
+				status := op.EffectiveSlowStatus()
+				slow = append(slow, describedSlowOperation{Operation: op.Ref, Threshold: op.SlowThreshold.String(), Status: status})
+				if status == slowStatusAttribute {
+					span.add("synth.slow", name, true)
+				}
+			}
+			if reasons := rejectionReasons(op); len(reasons) > 0 {
+				span.add("synth.rejected", name, true)
+				span.add("synth.rejection_reason", name, reasons...)
+			}
+			for _, sc := range scenarios {
+				if ov, ok := sc.Overrides[op.Ref]; ok {
+					for _, a := range ov.Attributes {
+						span.add(a.Key, name, exampleValues(a.Gen, rng)...)
+					}
+				}
+				if labelScenarios {
+					span.add("synth.scenarios", name, sc.Name)
+				}
+			}
+		}
+	}
+
+	return topologyDescription{
+		SpanAttributes:     span.sorted(),
+		ResourceAttributes: res.sorted(),
+		SlowOperations:     slow,
+		FlappingServices:   flaps,
+	}
+}
+
+// exampleValues lists every choice of a weighted choice, the value of a
+// static attribute, and a few samples of any other generator.
+func exampleValues(gen synth.AttributeGenerator, rng *rand.Rand) []any {
+	switch g := gen.(type) {
+	case *synth.StaticValue:
+		return []any{g.Value}
+	case *synth.WeightedChoice:
+		return g.Choices
+	case *synth.BoolValue:
+		return []any{true, false}
+	}
+	examples := make([]any, 0, 3)
+	for range 3 {
+		examples = append(examples, gen.Generate(rng))
+	}
+	return examples
+}
+
+// anySlice converts values for attributeCollector.add.
+func anySlice[T any](values []T) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+func rejectionReasons(op *synth.Operation) []any {
+	var reasons []any
+	if op.QueueDepth > 0 || op.Concurrency > 0 {
+		reasons = append(reasons, synth.ReasonQueueFull)
+	}
+	if op.CircuitBreaker != nil {
+		reasons = append(reasons, synth.ReasonCircuitOpen)
+	}
+	if op.RateLimit != nil {
+		reasons = append(reasons, synth.ReasonRateLimited)
+	}
+	if op.Service.Flap != nil {
+		reasons = append(reasons, synth.ReasonUnavailable)
+	}
+	return reasons
+}
+
+func writeDescription(w io.Writer, desc topologyDescription) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	sections := []struct {
+		title string
+		attrs []describedAttribute
+	}{
+		{"Span attributes", desc.SpanAttributes},
+		{"Resource attributes", desc.ResourceAttributes},
+	}
+	for i, section := range sections {
+		if i > 0 {
+			_, _ = fmt.Fprintln(tw)
+		}
+		_, _ = fmt.Fprintf(tw, "%s:\n", section.title)
+		for _, attr := range section.attrs {
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\t(%s)\n", attr.Key, strings.Join(attr.Examples, ", "), strings.Join(attr.Services, ", "))
+		}
+	}
+	if len(desc.SlowOperations) > 0 {
+		_, _ = fmt.Fprintf(tw, "\nSlow operations:\n")
+		for _, op := range desc.SlowOperations {
+			_, _ = fmt.Fprintf(tw, "  %s\t> %s\tstatus %s\n", op.Operation, op.Threshold, op.Status)
+		}
+	}
+	if len(desc.FlappingServices) > 0 {
+		_, _ = fmt.Fprintf(tw, "\nFlapping services:\n")
+		for _, flap := range desc.FlappingServices {
+			windows := fmt.Sprintf("up %s, down %s", flap.Up, flap.Down)
+			if flap.Jitter != "" {
+				windows += fmt.Sprintf(", jitter %s", flap.Jitter)
+			}
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\n", flap.Service, windows)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const describeConfig = `
+version: 1
+services:
+  gateway:
+    resource_attributes:
+      deployment.environment: production
+    operations:
+      GET /users:
+        duration: 30ms
+        domain: http
+        calls:
+          - backend.list
+  backend:
+    operations:
+      list:
+        duration: 20ms
+        queue_depth: 10
+        error_rate: 1%
+        error_types:
+          - name: deadlock
+            attributes:
+              db.response.status_code:
+                value: "40P01"
+        attributes:
+          db.system:
+            value: postgresql
+traffic:
+  rate: 10/s
+`
+
+func findDescribed(attrs []describedAttribute, key string) *describedAttribute {
+	for i := range attrs {
+		if attrs[i].Key == key {
+			return &attrs[i]
+		}
+	}
+	return nil
+}
+
+// describeJSON runs 'motel describe --format json' on config with args.
+func describeJSON(t *testing.T, config string, args ...string) topologyDescription {
+	t.Helper()
+	path := writeTestConfig(t, config)
+	root := rootCmd()
+	root.SetArgs(append(append([]string{"describe", "--format", "json"}, args...), path))
+	var out bytes.Buffer
+	root.SetOut(&out)
+	require.NoError(t, root.Execute())
+
+	var desc topologyDescription
+	require.NoError(t, json.Unmarshal(out.Bytes(), &desc))
+	return desc
+}
+
+func TestDescribeCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("json lists span and resource attributes", func(t *testing.T) {
+		t.Parallel()
+		path := writeTestConfig(t, describeConfig)
+		root := rootCmd()
+		root.SetArgs([]string{"describe", "--format", "json", path})
+		var out bytes.Buffer
+		root.SetOut(&out)
+		require.NoError(t, root.Execute())
+
+		var desc topologyDescription
+		require.NoError(t, json.Unmarshal(out.Bytes(), &desc))
+
+		svc := findDescribed(desc.SpanAttributes, "synth.service")
+		require.NotNil(t, svc)
+		assert.ElementsMatch(t, []string{"backend", "gateway"}, svc.Examples)
+
+		method := findDescribed(desc.SpanAttributes, "http.request.method")
+		require.NotNil(t, method, "domain attributes should be listed")
+		assert.Equal(t, []string{"gateway"}, method.Services)
+		assert.NotEmpty(t, method.Examples)
+
+		dbSystem := findDescribed(desc.SpanAttributes, "db.system")
+		require.NotNil(t, dbSystem)
+		assert.Equal(t, []string{"postgresql"}, dbSystem.Examples)
+
+		status := findDescribed(desc.SpanAttributes, "db.response.status_code")
+		require.NotNil(t, status, "error type attributes should be listed")
+		assert.Equal(t, []string{"40P01"}, status.Examples)
+
+		reason := findDescribed(desc.SpanAttributes, "synth.rejection_reason")
+		require.NotNil(t, reason)
+		assert.Equal(t, []string{"queue_full"}, reason.Examples)
+		assert.Nil(t, findDescribed(desc.SpanAttributes, "synth.scenarios"))
+
+		env := findDescribed(desc.ResourceAttributes, "deployment.environment")
+		require.NotNil(t, env)
+		assert.Equal(t, []string{"production"}, env.Examples)
+		assert.Equal(t, []string{"gateway"}, env.Services)
+		assert.NotNil(t, findDescribed(desc.ResourceAttributes, "service.name"))
+	})
+
+	t.Run("trace attributes on every service", func(t *testing.T) {
+		t.Parallel()
+		desc := describeJSON(t, `
+version: 1
+trace_attributes:
+  tenant.id:
+    values: {acme: 3, globex: 1}
+services:
+  gateway:
+    operations:
+      GET /:
+        duration: 10ms
+        calls: [backend.list]
+  backend:
+    operations:
+      list:
+        duration: 5ms
+traffic:
+  rate: 10/s
+scenarios:
+  - name: canary
+    at: +1m
+    duration: 1m
+    trace_attributes:
+      deployment.canary:
+        value: true
+`)
+
+		tenant := findDescribed(desc.SpanAttributes, "tenant.id")
+		require.NotNil(t, tenant)
+		assert.ElementsMatch(t, []string{"acme", "globex"}, tenant.Examples)
+		assert.Equal(t, []string{"backend", "gateway"}, tenant.Services)
+		canary := findDescribed(desc.SpanAttributes, "deployment.canary")
+		require.NotNil(t, canary, "scenario trace attributes should be listed")
+		assert.Equal(t, []string{"true"}, canary.Examples)
+		assert.Equal(t, []string{"backend", "gateway"}, canary.Services)
+	})
+
+	t.Run("grpc status codes", func(t *testing.T) {
+		t.Parallel()
+		desc := describeJSON(t, `
+version: 1
+services:
+  gateway:
+    operations:
+      GET /:
+        duration: 10ms
+        calls: [users.Get]
+  users:
+    operations:
+      Get:
+        domain: rpc
+        duration: 5ms
+        error_rate: 1%
+        error_types:
+          - name: not found
+            attributes:
+              rpc.grpc.status_code:
+                value: 5
+traffic:
+  rate: 10/s
+`)
+
+		code := findDescribed(desc.SpanAttributes, "rpc.grpc.status_code")
+		require.NotNil(t, code)
+		assert.Equal(t, []string{"users"}, code.Services, "only rpc-domain operations derive a code")
+		require.NotEmpty(t, code.Examples)
+		assert.Equal(t, "5", code.Examples[0], "an error type's code is listed first")
+		assert.Contains(t, code.Examples, "14", "derived codes are listed")
+		assert.Contains(t, code.Examples, "4", "timeouts derive DEADLINE_EXCEEDED")
+
+		desc = describeJSON(t, `
+version: 1
+services:
+  users:
+    operations:
+      Get:
+        domain: rpc
+        duration: 5ms
+        error_rate: 1%
+        attributes:
+          rpc.grpc.status_code:
+            value: 7
+traffic:
+  rate: 10/s
+`)
+		code = findDescribed(desc.SpanAttributes, "rpc.grpc.status_code")
+		require.NotNil(t, code)
+		assert.Equal(t, []string{"7"}, code.Examples, "a configured code replaces the derived ones")
+	})
+
+	t.Run("http status codes", func(t *testing.T) {
+		t.Parallel()
+		const config = `
+version: 1
+services:
+  gateway:
+    operations:
+      GET /:
+        domain: http
+        duration: 10ms
+  legacy:
+    operations:
+      get:
+        domain: http
+        duration: 5ms
+        attributes:
+          http.response.status_code:
+            value: 203
+traffic:
+  rate: 10/s
+`
+		desc := describeJSON(t, config)
+		code := findDescribed(desc.SpanAttributes, "http.response.status_code")
+		require.NotNil(t, code)
+		assert.Equal(t, []string{"gateway", "legacy"}, code.Services)
+		assert.Equal(t, []string{"200", "504", "500", "503", "502"}, code.Examples,
+			"success, timeout, then error codes")
+
+		desc = describeJSON(t, strings.Replace(config, "        domain: http\n        duration: 10ms\n", "        duration: 10ms\n", 1))
+		code = findDescribed(desc.SpanAttributes, "http.response.status_code")
+		require.NotNil(t, code)
+		assert.Equal(t, []string{"203"}, code.Examples, "a configured code replaces the derived ones")
+	})
+
+	t.Run("slow operations", func(t *testing.T) {
+		t.Parallel()
+		const config = `
+version: 1
+services:
+  gateway:
+    operations:
+      GET /:
+        duration: 10ms
+        slow_threshold: 200ms
+        calls: [backend.list, backend.get]
+  backend:
+    operations:
+      list:
+        duration: 5ms
+        slow_threshold: 50ms
+        slow_status: attribute
+      get:
+        duration: 5ms
+        slow_threshold: 1s
+        slow_status: error
+traffic:
+  rate: 10/s
+`
+		desc := describeJSON(t, config)
+		slow := findDescribed(desc.SpanAttributes, "synth.slow")
+		require.NotNil(t, slow)
+		assert.Equal(t, []string{"true"}, slow.Examples)
+		assert.Equal(t, []string{"backend"}, slow.Services, "only slow_status: attribute adds synth.slow")
+		assert.Equal(t, []describedSlowOperation{
+			{Operation: "backend.get", Threshold: "1s", Status: "error"},
+			{Operation: "backend.list", Threshold: "50ms", Status: "attribute"},
+			{Operation: "gateway.GET /", Threshold: "200ms", Status: "unset"},
+		}, desc.SlowOperations)
+
+		path := writeTestConfig(t, config)
+		root := rootCmd()
+		root.SetArgs([]string{"describe", path})
+		var out bytes.Buffer
+		root.SetOut(&out)
+		require.NoError(t, root.Execute())
+		assert.Contains(t, out.String(), "Slow operations:")
+		assert.Regexp(t, `backend\.list +> 50ms +status attribute`, out.String())
+	})
+
+	t.Run("service regions", func(t *testing.T) {
+		t.Parallel()
+		desc := describeJSON(t, `
+version: 1
+services:
+  gateway:
+    regions:
+      us-east-1: 3
+      eu-west-1: 1
+    operations:
+      GET /:
+        duration: 10ms
+        calls: [backend.list]
+  backend:
+    operations:
+      list:
+        duration: 5ms
+traffic:
+  rate: 10/s
+`)
+
+		region := findDescribed(desc.SpanAttributes, "cloud.region")
+		require.NotNil(t, region)
+		assert.ElementsMatch(t, []string{"us-east-1", "eu-west-1"}, region.Examples)
+		assert.Equal(t, []string{"gateway"}, region.Services, "only services with regions carry it")
+	})
+
+	t.Run("scenario labels", func(t *testing.T) {
+		t.Parallel()
+		desc := describeJSON(t, `
+version: 1
+services:
+  gateway:
+    operations:
+      GET /:
+        duration: 10ms
+        calls: [backend.list]
+  backend:
+    operations:
+      list:
+        duration: 5ms
+traffic:
+  rate: 10/s
+scenarios:
+  - name: outage
+    at: +1m
+    duration: 1m
+    labels:
+      incident.id: INC-123
+  - name: second outage
+    at: +5m
+    duration: 1m
+    labels:
+      incident.id: INC-124
+`)
+
+		incident := findDescribed(desc.SpanAttributes, "incident.id")
+		require.NotNil(t, incident)
+		assert.Equal(t, []string{"INC-123", "INC-124"}, incident.Examples)
+		assert.Equal(t, []string{"backend", "gateway"}, incident.Services)
+	})
+
+	t.Run("call attributes on the callee", func(t *testing.T) {
+		t.Parallel()
+		desc := describeJSON(t, `
+version: 1
+services:
+  gateway:
+    operations:
+      GET /:
+        duration: 10ms
+        calls:
+          - target: backend.list
+            attributes:
+              request.origin:
+                value: gateway
+  backend:
+    operations:
+      list:
+        duration: 5ms
+  admin:
+    operations:
+      purge:
+        duration: 5ms
+traffic:
+  rate: 10/s
+scenarios:
+  - name: purge
+    at: +1m
+    duration: 1m
+    override:
+      gateway.GET /:
+        add_calls:
+          - target: admin.purge
+            attributes:
+              request.origin:
+                value: scenario
+`)
+
+		origin := findDescribed(desc.SpanAttributes, "request.origin")
+		require.NotNil(t, origin)
+		assert.ElementsMatch(t, []string{"gateway", "scenario"}, origin.Examples)
+		assert.Equal(t, []string{"admin", "backend"}, origin.Services, "listed under the callees, not the caller")
+	})
+
+	t.Run("flapping services", func(t *testing.T) {
+		t.Parallel()
+		const config = `
+version: 1
+services:
+  gateway:
+    operations:
+      GET /:
+        duration: 10ms
+        calls: [payments.charge]
+  payments:
+    flap:
+      up_duration: 5m
+      down_duration: 30s
+      jitter: 10s
+    operations:
+      charge:
+        duration: 40ms
+traffic:
+  rate: 10/s
+`
+		desc := describeJSON(t, config)
+		assert.Equal(t, []describedFlap{{Service: "payments", Up: "5m0s", Down: "30s", Jitter: "10s"}}, desc.FlappingServices)
+		reason := findDescribed(desc.SpanAttributes, "synth.rejection_reason")
+		require.NotNil(t, reason)
+		assert.Equal(t, []string{"unavailable"}, reason.Examples)
+		assert.Equal(t, []string{"payments"}, reason.Services)
+		assert.NotNil(t, findDescribed(desc.SpanAttributes, "synth.rejected"))
+
+		path := writeTestConfig(t, config)
+		root := rootCmd()
+		root.SetArgs([]string{"describe", path})
+		var out bytes.Buffer
+		root.SetOut(&out)
+		require.NoError(t, root.Execute())
+		assert.Contains(t, out.String(), "Flapping services:")
+		assert.Regexp(t, `payments +up 5m0s, down 30s, jitter 10s`, out.String())
+	})
+
+	t.Run("text output", func(t *testing.T) {
+		t.Parallel()
+		path := writeTestConfig(t, describeConfig)
+		root := rootCmd()
+		root.SetArgs([]string{"describe", path})
+		var out bytes.Buffer
+		root.SetOut(&out)
+		require.NoError(t, root.Execute())
+
+		assert.Contains(t, out.String(), "Span attributes:")
+		assert.Contains(t, out.String(), "Resource attributes:")
+		assert.Contains(t, out.String(), "synth.operation")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		t.Parallel()
+		path := writeTestConfig(t, describeConfig)
+		root := rootCmd()
+		root.SetArgs([]string{"describe", "--format", "xml", path})
+		require.Error(t, root.Execute())
+	})
+}
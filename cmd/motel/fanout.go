@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// fanOut calls fn for every exporter concurrently and joins the errors, so a
+// slow or failing endpoint does not hold back delivery to the others.
+func fanOut[E any](exporters []E, fn func(E) error) error {
+	errs := make([]error, len(exporters))
+	var wg sync.WaitGroup
+	for i, exp := range exporters {
+		wg.Go(func() {
+			errs[i] = fn(exp)
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// fanOutSpanExporter forwards every span batch to each wrapped exporter.
+type fanOutSpanExporter struct {
+	exporters []sdktrace.SpanExporter
+}
+
+func (f *fanOutSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	return fanOut(f.exporters, func(e sdktrace.SpanExporter) error {
+		return e.ExportSpans(ctx, spans)
+	})
+}
+
+func (f *fanOutSpanExporter) Shutdown(ctx context.Context) error {
+	shutdownAll(ctx, f.exporters, "span exporter")
+	return nil
+}
+
+// fanOutMetricExporter forwards every metric collection to each wrapped
+// exporter. Temporality and aggregation come from the first exporter; all
+// endpoints share the same protocol and so the same defaults.
+type fanOutMetricExporter struct {
+	exporters []sdkmetric.Exporter
+}
+
+func (f *fanOutMetricExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	return f.exporters[0].Temporality(kind)
+}
+
+func (f *fanOutMetricExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return f.exporters[0].Aggregation(kind)
+}
+
+func (f *fanOutMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	return fanOut(f.exporters, func(e sdkmetric.Exporter) error {
+		return e.Export(ctx, rm)
+	})
+}
+
+func (f *fanOutMetricExporter) ForceFlush(ctx context.Context) error {
+	return fanOut(f.exporters, func(e sdkmetric.Exporter) error {
+		return e.ForceFlush(ctx)
+	})
+}
+
+func (f *fanOutMetricExporter) Shutdown(ctx context.Context) error {
+	shutdownAll(ctx, f.exporters, "metric exporter")
+	return nil
+}
+
+// fanOutLogExporter forwards every log batch to each wrapped exporter.
+type fanOutLogExporter struct {
+	exporters []sdklog.Exporter
+}
+
+func (f *fanOutLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	return fanOut(f.exporters, func(e sdklog.Exporter) error {
+		return e.Export(ctx, records)
+	})
+}
+
+func (f *fanOutLogExporter) ForceFlush(ctx context.Context) error {
+	return fanOut(f.exporters, func(e sdklog.Exporter) error {
+		return e.ForceFlush(ctx)
+	})
+}
+
+func (f *fanOutLogExporter) Shutdown(ctx context.Context) error {
+	shutdownAll(ctx, f.exporters, "log exporter")
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/andrewh/motel/pkg/synth"
+	"github.com/spf13/cobra"
+)
+
+// defaultLoadStages is how many stages the step is chosen to produce when
+// --step is not given.
+const defaultLoadStages = 60
+
+func exportLoadCmd() *cobra.Command {
+	var (
+		duration time.Duration
+		step     time.Duration
+		output   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export-load <topology.yaml | URL>",
+		Short: "Write a k6 load profile that follows the topology's traffic pattern",
+		Long: "Write a k6 load profile that follows the topology's traffic pattern.\n\n" +
+			"The traffic rate, including scenario traffic overrides, is sampled every\n" +
+			"--step and written as the stages of a ramping-arrival-rate scenario, so\n" +
+			"real services can be driven with the same traffic shape motel generates.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("missing topology file or URL\n\nUsage: motel export-load <topology.yaml | URL>")
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if step < 0 {
+				return fmt.Errorf("--step must not be negative, got %s", step)
+			}
+			cfg, err := synth.LoadConfig(args[0])
+			if err != nil {
+				return err
+			}
+			if err := synth.ValidateConfig(cfg); err != nil {
+				return err
+			}
+			topo, err := buildTopology(cfg, "")
+			if err != nil {
+				return err
+			}
+			traffic, err := synth.NewTrafficPattern(cfg.Traffic)
+			if err != nil {
+				return err
+			}
+			scenarios, err := synth.BuildScenarios(cfg.Scenarios, topo)
+			if err != nil {
+				return err
+			}
+
+			if duration == 0 {
+				duration = inferDuration(scenarios)
+			}
+			if step == 0 {
+				step = max(duration/defaultLoadStages, time.Second).Truncate(time.Second)
+			}
+			if step > duration {
+				return fmt.Errorf("--step %s is longer than --duration %s", step, duration)
+			}
+
+			profile := k6Profile(loadSamples(traffic, scenarios, duration, step))
+
+			var w io.Writer = cmd.OutOrStdout()
+			if output != "" {
+				f, err := os.Create(output) //nolint:gosec // user-supplied output path is expected
+				if err != nil {
+					return fmt.Errorf("creating output file: %w", err)
+				}
+				defer f.Close() //nolint:errcheck // best-effort close on write
+				w = f
+			}
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(profile)
+		},
+	}
+
+	cmd.Flags().DurationVar(&duration, "duration", 0, "profile duration (default: inferred from topology)")
+	cmd.Flags().DurationVar(&step, "step", 0, "interval between sampled rates (default: duration/60, at least 1s)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output file path (default: stdout)")
+
+	return cmd
+}
+
+// loadSamples samples the rate every step and always ends with a sample at
+// duration, so the final partial step is not dropped.
+func loadSamples(traffic synth.TrafficPattern, scenarios []synth.Scenario, duration, step time.Duration) []rateSample {
+	samples := sampleRatesEvery(traffic, scenarios, duration, step)
+	if samples[len(samples)-1].Elapsed < duration {
+		samples = append(samples, rateSample{Elapsed: duration, Rate: rateAt(traffic, scenarios, duration)})
+	}
+	return samples
+}
+
+// k6Options is the subset of k6 options motel writes. It can be passed to
+// k6 with --config or merged into a script's exported options.
+type k6Options struct {
+	Scenarios map[string]k6Scenario `json:"scenarios"`
+}
+
+type k6Scenario struct {
+	Executor        string    `json:"executor"`
+	StartRate       int64     `json:"startRate"`
+	TimeUnit        string    `json:"timeUnit"`
+	PreAllocatedVUs int64     `json:"preAllocatedVUs"`
+	Stages          []k6Stage `json:"stages"`
+}
+
+type k6Stage struct {
+	Duration string `json:"duration"`
+	Target   int64  `json:"target"`
+}
+
+// k6Profile converts rate samples into a ramping-arrival-rate scenario. k6
+// ramps linearly from each target to the next. Targets must be whole
+// numbers, so low-rate profiles count iterations per minute instead of per
+// second to keep their shape.
+func k6Profile(samples []rateSample) k6Options {
+	peak := 0.0
+	for _, s := range samples {
+		peak = max(peak, s.Rate)
+	}
+	timeUnit, scale := "1s", 1.0
+	if peak < 10 {
+		timeUnit, scale = "1m", 60
+	}
+	target := func(rate float64) int64 {
+		return int64(math.Round(rate * scale))
+	}
+
+	stages := make([]k6Stage, 0, len(samples)-1)
+	for i := 1; i < len(samples); i++ {
+		stages = append(stages, k6Stage{
+			Duration: (samples[i].Elapsed - samples[i-1].Elapsed).String(),
+			Target:   target(samples[i].Rate),
+		})
+	}
+	return k6Options{Scenarios: map[string]k6Scenario{
+		"motel": {
+			Executor:        "ramping-arrival-rate",
+			StartRate:       target(samples[0].Rate),
+			TimeUnit:        timeUnit,
+			PreAllocatedVUs: max(1, int64(math.Ceil(peak))),
+			Stages:          stages,
+		},
+	}}
+}
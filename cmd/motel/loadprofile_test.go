@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/andrewh/motel/pkg/synth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportLoadCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("diurnal stages vary between trough and peak", func(t *testing.T) {
+		t.Parallel()
+		cfg := `
+version: 1
+services:
+  gateway:
+    operations:
+      handle:
+        duration: 10ms
+traffic:
+  rate: 100/s
+  pattern: diurnal
+  period: 1h
+  peak_multiplier: 2
+  trough_multiplier: 0.5
+`
+		path := writeTestConfig(t, cfg)
+		root := rootCmd()
+		root.SetArgs([]string{"export-load", "--duration", "1h", "--step", "1m", path})
+		var out bytes.Buffer
+		root.SetOut(&out)
+		require.NoError(t, root.Execute())
+
+		var profile k6Options
+		require.NoError(t, json.Unmarshal(out.Bytes(), &profile))
+		sc, ok := profile.Scenarios["motel"]
+		require.True(t, ok)
+		assert.Equal(t, "ramping-arrival-rate", sc.Executor)
+		assert.Equal(t, "1s", sc.TimeUnit)
+		require.Len(t, sc.Stages, 60)
+
+		targets := make([]int64, 0, len(sc.Stages)+1)
+		targets = append(targets, sc.StartRate)
+		for _, st := range sc.Stages {
+			assert.Equal(t, "1m0s", st.Duration)
+			targets = append(targets, st.Target)
+		}
+		assert.InDelta(t, 50, slices.Min(targets), 2, "trough should be rate*trough_multiplier")
+		assert.InDelta(t, 200, slices.Max(targets), 2, "peak should be rate*peak_multiplier")
+	})
+
+	t.Run("step longer than duration", func(t *testing.T) {
+		t.Parallel()
+		path := writeTestConfig(t, validConfig)
+		root := rootCmd()
+		root.SetArgs([]string{"export-load", "--duration", "10s", "--step", "1m", path})
+		require.Error(t, root.Execute())
+	})
+}
+
+func TestK6Profile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("low rates use per-minute targets", func(t *testing.T) {
+		t.Parallel()
+		traffic, err := synth.NewTrafficPattern(synth.TrafficConfig{Rate: "30/m"})
+		require.NoError(t, err)
+
+		profile := k6Profile(loadSamples(traffic, nil, 25*time.Second, 10*time.Second))
+		sc := profile.Scenarios["motel"]
+		assert.Equal(t, "1m", sc.TimeUnit)
+		assert.Equal(t, int64(30), sc.StartRate)
+		require.Len(t, sc.Stages, 3)
+		assert.Equal(t, "5s", sc.Stages[2].Duration, "final partial step is kept")
+	})
+}
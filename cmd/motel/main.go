@@ -3,8 +3,12 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"maps"
@@ -16,16 +20,17 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"strconv"
 	"strings"
-	"sync"
 	"syscall"
 	"time"
 
 	"github.com/andrewh/motel/pkg/semconv"
 	"github.com/andrewh/motel/pkg/synth"
 	"github.com/andrewh/motel/pkg/synth/traceimport"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
@@ -39,6 +44,7 @@ import (
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -46,6 +52,7 @@ import (
 	otelsc "go.opentelemetry.io/otel/semconv/v1.39.0"
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/credentials"
 )
 
 var (
@@ -71,9 +78,14 @@ func rootCmd() *cobra.Command {
 	root.AddCommand(emitCmd())
 	root.AddCommand(doctorCmd())
 	root.AddCommand(validateCmd())
+	root.AddCommand(renderCmd())
 	root.AddCommand(importCmd())
+	root.AddCommand(replayCmd())
 	root.AddCommand(previewCmd())
 	root.AddCommand(checkCmd())
+	root.AddCommand(exportContractsCmd())
+	root.AddCommand(describeCmd())
+	root.AddCommand(exportLoadCmd())
 	root.AddCommand(versionCmd())
 
 	return root
@@ -81,32 +93,67 @@ func rootCmd() *cobra.Command {
 
 func runCmd() *cobra.Command {
 	var (
-		endpoint         string
+		endpoints        []string
 		stdout           bool
 		duration         time.Duration
 		protocol         string
 		headers          string
+		headerPairs      []string
+		authBearer       string
 		insecure         bool
+		useTLS           bool
+		tlsCA            string
+		tlsCert          string
+		tlsKey           string
 		exportTimeout    time.Duration
 		signals          string
 		slowThreshold    time.Duration
+		logFormat        string
 		maxSpansPerTrace int
+		maxTraceDuration time.Duration
+		shutdownTimeout  time.Duration
+		maxAttributes    int
+		maxAttrLength    int
 		semconvDir       string
+		semconvFill      string
 		labelScenarios   bool
+		linkRetries      bool
 		pprofAddr        string
 		timeOffset       time.Duration
+		startTime        string
+		backfill         time.Duration
+		rate             string
 		realtime         bool
 		seed             uint64
 		verbatim         bool
 		preserveIDs      bool
+		sampleRatio      float64
+		services         []string
+		root             string
+		maxTraces        int
+		untilScenarios   bool
+		warmup           time.Duration
+		progress         bool
+		statsFile        string
+		statsFormat      string
+		perOpStats       bool
+		watch            bool
+		resourceDetect   bool
+		deterministicIDs bool
+		prometheusAddr   string
+		scenarioMetrics  bool
+		fetchTimeout     time.Duration
+		fetchMaxBytes    int64
 	)
 
 	cmd := &cobra.Command{
 		Use:   "run <topology.yaml | URL>",
 		Short: "Generate synthetic signals from a topology definition",
 		Long: "Generate synthetic signals from a topology definition.\n\n" +
-			"The topology source can be a local file path or an HTTP/HTTPS URL.\n" +
-			"URL fetches have a 10-second timeout and a 10 MB response body limit.",
+			"The topology source can be a local file path, an HTTP/HTTPS URL, or - to\n" +
+			"read it from stdin.\n" +
+			"URL fetches have a 10-second timeout and a 10 MB response body limit;\n" +
+			"--fetch-timeout and --fetch-max-bytes change them.",
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("missing topology file or URL\n\nUsage: motel run <topology.yaml | URL>")
@@ -117,11 +164,77 @@ func runCmd() *cobra.Command {
 			if cmd.Flags().Changed("slow-threshold") && !strings.Contains(signals, "logs") {
 				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Warning: --slow-threshold has no effect without --signals logs")
 			}
+			if cmd.Flags().Changed("log-format") && !strings.Contains(signals, "logs") {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Warning: --log-format has no effect without --signals logs")
+			}
 			if realtime && cmd.Flags().Changed("time-offset") {
 				return fmt.Errorf("--realtime and --time-offset cannot be used together")
 			}
+			if cmd.Flags().Changed("start-time") {
+				if cmd.Flags().Changed("time-offset") {
+					return fmt.Errorf("--start-time and --time-offset cannot be used together")
+				}
+				if realtime {
+					return fmt.Errorf("--realtime and --start-time cannot be used together")
+				}
+				start, err := time.Parse(time.RFC3339, startTime)
+				if err != nil {
+					return fmt.Errorf("--start-time must be an RFC3339 timestamp such as 2024-05-01T00:00:00Z, got %q", startTime)
+				}
+				timeOffset = time.Until(start)
+			}
+			if cmd.Flags().Changed("backfill") {
+				if backfill <= 0 {
+					return fmt.Errorf("--backfill must be positive, got %s", backfill)
+				}
+				if realtime {
+					return fmt.Errorf("--realtime and --backfill cannot be used together")
+				}
+				if cmd.Flags().Changed("duration") {
+					return fmt.Errorf("--backfill sets the simulated duration; --duration cannot be used with it")
+				}
+				if strings.Contains(signals, "metrics") {
+					return fmt.Errorf("--backfill does not support metrics: metric data points are aggregated over wall-clock time")
+				}
+				duration = backfill
+				if !cmd.Flags().Changed("time-offset") && !cmd.Flags().Changed("start-time") {
+					timeOffset = -backfill
+				}
+			}
+			if cmd.Flags().Changed("rate") {
+				if _, err := synth.ParseRate(rate); err != nil {
+					return fmt.Errorf("--rate: %w", err)
+				}
+			}
+			if semconvFill != semconvFillAll && semconvFill != semconvFillRequired {
+				return fmt.Errorf("--semconv-fill must be %s or %s, got %q", semconvFillAll, semconvFillRequired, semconvFill)
+			}
+			if statsFormat != statsFormatCompact && statsFormat != statsFormatPretty {
+				return fmt.Errorf("--stats-format must be %s or %s, got %q", statsFormatCompact, statsFormatPretty, statsFormat)
+			}
+			if shutdownTimeout <= 0 {
+				return fmt.Errorf("--shutdown-timeout must be positive, got %s", shutdownTimeout)
+			}
+			if maxAttributes < 0 {
+				return fmt.Errorf("--max-attributes must not be negative, got %d", maxAttributes)
+			}
+			if maxAttrLength < 0 {
+				return fmt.Errorf("--max-attribute-length must not be negative, got %d", maxAttrLength)
+			}
+			if maxTraces < 0 {
+				return fmt.Errorf("--max-traces must not be negative, got %d", maxTraces)
+			}
+			loadOpts, err := fetchOptions(fetchTimeout, fetchMaxBytes)
+			if err != nil {
+				return err
+			}
+			var endpoint string
+			if len(endpoints) > 0 {
+				endpoint = endpoints[0]
+			}
 			return runGenerate(cmd.Context(), args[0], runOptions{
 				endpoint:         endpoint,
+				endpoints:        endpoints,
 				endpointSet:      cmd.Flags().Changed("endpoint"),
 				stdout:           stdout,
 				duration:         duration,
@@ -129,44 +242,107 @@ func runCmd() *cobra.Command {
 				protocolSet:      cmd.Flags().Changed("protocol"),
 				headers:          headers,
 				headersSet:       cmd.Flags().Changed("headers"),
+				headerPairs:      headerPairs,
+				authBearer:       authBearer,
 				insecure:         insecure,
 				insecureSet:      cmd.Flags().Changed("insecure"),
+				useTLS:           useTLS,
+				tlsCA:            tlsCA,
+				tlsCert:          tlsCert,
+				tlsKey:           tlsKey,
 				exportTimeout:    exportTimeout,
 				timeoutSet:       cmd.Flags().Changed("timeout"),
 				signals:          signals,
 				signalsChanged:   cmd.Flags().Changed("signals"),
 				slowThreshold:    slowThreshold,
+				logFormat:        logFormat,
 				maxSpansPerTrace: maxSpansPerTrace,
+				maxTraceDuration: maxTraceDuration,
+				shutdownTimeout:  shutdownTimeout,
+				maxAttributes:    maxAttributes,
+				maxAttrLength:    maxAttrLength,
 				semconvDir:       semconvDir,
+				semconvFill:      semconvFill,
 				labelScenarios:   labelScenarios,
+				linkRetries:      linkRetries,
 				pprofAddr:        pprofAddr,
 				timeOffset:       timeOffset,
+				backfill:         backfill > 0,
+				rate:             rate,
 				realtime:         realtime,
 				seed:             seed,
 				verbatim:         verbatim,
 				preserveIDs:      preserveIDs,
+				sampleRatio:      sampleRatio,
+				services:         services,
+				root:             root,
+				maxTraces:        maxTraces,
+				untilScenarios:   untilScenarios,
+				warmup:           warmup,
+				progress:         progress,
+				statsFile:        statsFile,
+				statsFormat:      statsFormat,
+				perOpStats:       perOpStats,
+				watch:            watch,
+				resourceDetect:   resourceDetect,
+				deterministicIDs: deterministicIDs,
+				prometheusAddr:   prometheusAddr,
+				scenarioMetrics:  scenarioMetrics,
+				loadOptions:      loadOpts,
 			})
 		},
 	}
 
-	cmd.Flags().StringVar(&endpoint, "endpoint", "", "OTLP endpoint (overrides OTEL_EXPORTER_OTLP_ENDPOINT)")
+	cmd.Flags().StringArrayVar(&endpoints, "endpoint", nil, "OTLP endpoint (overrides OTEL_EXPORTER_OTLP_ENDPOINT); repeat to send to several collectors")
 	cmd.Flags().BoolVar(&stdout, "stdout", false, "emit signals to stdout as JSON")
 	cmd.Flags().DurationVar(&duration, "duration", 0, "simulation duration, e.g. 10s, 5m, 1h (default 1m)")
 	cmd.Flags().StringVar(&protocol, "protocol", "http/protobuf", "OTLP protocol: http/protobuf or grpc (overrides OTEL_EXPORTER_OTLP_PROTOCOL)")
 	cmd.Flags().StringVar(&headers, "headers", "", "OTLP headers as comma-separated key=value pairs (overrides OTEL_EXPORTER_OTLP_HEADERS)")
+	cmd.Flags().StringArrayVar(&headerPairs, "header", nil, "OTLP header in key=value format, added to --headers or the environment (repeatable)")
+	cmd.Flags().StringVar(&authBearer, "otlp-auth-bearer", "", "bearer token sent as the OTLP Authorization header")
 	cmd.Flags().BoolVar(&insecure, "insecure", false, "disable TLS for OTLP exporters")
+	cmd.Flags().BoolVar(&useTLS, "tls", false, "use TLS for OTLP exporters, verified against the system roots or --tls-ca")
+	cmd.Flags().StringVar(&tlsCA, "tls-ca", "", "PEM file of CA certificates for verifying the collector (implies --tls)")
+	cmd.Flags().StringVar(&tlsCert, "tls-cert", "", "PEM client certificate for mutual TLS, used with --tls-key (implies --tls)")
+	cmd.Flags().StringVar(&tlsKey, "tls-key", "", "PEM client private key for mutual TLS, used with --tls-cert (implies --tls)")
 	cmd.Flags().DurationVar(&exportTimeout, "timeout", 0, "OTLP export timeout (overrides OTEL_EXPORTER_OTLP_TIMEOUT)")
 	cmd.Flags().StringVar(&signals, "signals", "traces", "comma-separated signals to emit: traces,metrics,logs")
 	cmd.Flags().DurationVar(&slowThreshold, "slow-threshold", time.Second, "duration threshold for slow span log emission")
+	cmd.Flags().StringVar(&logFormat, "log-format", synth.LogFormatPlain, "log record body format: plain, json, logfmt, or a Go template such as '{{.Level}} {{.Message}}'")
 	cmd.Flags().IntVar(&maxSpansPerTrace, "max-spans-per-trace", 0, "maximum spans per trace (0 = default 10000)")
+	cmd.Flags().DurationVar(&maxTraceDuration, "max-trace-duration", 0, "cut off spans still running this long after their trace's root started, with a timeout error (0 = no cap)")
+	cmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", defaultShutdownTimeout, "how long to wait for buffered signals to drain to the collector at exit")
+	cmd.Flags().IntVar(&maxAttributes, "max-attributes", 0, "SDK span limit: drop attributes beyond this many per span (0 = SDK default of 128)")
+	cmd.Flags().IntVar(&maxAttrLength, "max-attribute-length", 0, "SDK span limit: truncate string attribute values to this many characters (0 = no limit)")
 	cmd.Flags().StringVar(&semconvDir, "semconv", "", "directory of additional semantic convention YAML files")
+	cmd.Flags().StringVar(&semconvFill, "semconv-fill", semconvFillAll, "attributes generated for an operation's domain: all, or only required ones")
 	cmd.Flags().BoolVar(&labelScenarios, "label-scenarios", false, "add synth.scenarios attribute to spans with active scenario names")
+	cmd.Flags().BoolVar(&linkRetries, "link-retries", false, "link each retry attempt's span to the previous attempt's span")
 	cmd.Flags().StringVar(&pprofAddr, "pprof", "", "start pprof HTTP server on this address (e.g. :6060)")
 	cmd.Flags().DurationVar(&timeOffset, "time-offset", 0, "shift span, metric, and log timestamps by this duration (e.g. -1h for past, 1h for future)")
+	cmd.Flags().StringVar(&startTime, "start-time", "", "start span, metric, and log timestamps at this RFC3339 time instead of now (e.g. 2024-05-01T00:00:00Z)")
+	cmd.Flags().DurationVar(&backfill, "backfill", 0, "generate this much simulated time as fast as possible, with timestamps ending now (e.g. 24h)")
+	cmd.Flags().StringVar(&rate, "rate", "", "override the topology's base traffic rate (e.g. 10/s), keeping its pattern's shape")
 	cmd.Flags().BoolVar(&realtime, "realtime", false, "emit spans at wall-clock times matching simulated timestamps")
 	cmd.Flags().Uint64Var(&seed, "seed", 0, "seed for deterministic simulation decisions (0 = random); determinism is best-effort and not guaranteed across motel versions")
 	cmd.Flags().BoolVar(&verbatim, "verbatim", false, "replay mode: emit spans with their original recorded timestamps instead of shifting them to run time")
 	cmd.Flags().BoolVar(&preserveIDs, "preserve-ids", false, "replay mode: preserve recorded trace and span IDs instead of generating fresh IDs")
+	cmd.Flags().Float64Var(&sampleRatio, "sample-ratio", 1, "fraction of generated traces to emit, simulating a head sampler (0 < ratio <= 1)")
+	cmd.Flags().DurationVar(&warmup, "warmup", 0, "emit traces but leave them out of the reported stats until this much time has elapsed")
+	cmd.Flags().BoolVar(&progress, "progress", false, "write a status line to stderr every few seconds")
+	cmd.Flags().StringVar(&statsFile, "stats-file", "", "write the final stats to this file, or - for stdout (default: stderr)")
+	cmd.Flags().StringVar(&statsFormat, "stats-format", statsFormatCompact, "final stats JSON format: compact or pretty")
+	cmd.Flags().BoolVar(&perOpStats, "per-operation-stats", false, "break the final stats down by operation")
+	cmd.Flags().BoolVar(&watch, "watch", false, "restart the run whenever the topology file changes")
+	cmd.Flags().StringVar(&prometheusAddr, "prometheus-addr", "", "also serve metrics for Prometheus scraping at http://ADDR/metrics (e.g. :9464); requires metrics in --signals")
+	cmd.Flags().BoolVar(&scenarioMetrics, "scenario-metrics", false, "count scenario activations in the synth.scenario.activations metric, reported as service motel; requires metrics in --signals")
+	cmd.Flags().BoolVar(&deterministicIDs, "deterministic-ids", false, "derive trace and span IDs from --seed so runs with the same seed emit the same IDs")
+	cmd.Flags().BoolVar(&resourceDetect, "resource-detect", false, "add host, OS, and process attributes detected on this machine to every service's resource")
+	cmd.Flags().StringArrayVar(&services, "service", nil, "generate only this service and calls between selected services (repeatable)")
+	cmd.Flags().StringVar(&root, "root", "", "start every trace at this root operation (service.operation), e.g. with --max-traces 1")
+	cmd.Flags().IntVar(&maxTraces, "max-traces", 0, "stop after generating this many traces (0 = no limit)")
+	cmd.Flags().BoolVar(&untilScenarios, "until-scenarios-complete", false, "stop once the last scenario window has ended, if that is before --duration")
+	addFetchFlags(cmd, &fetchTimeout, &fetchMaxBytes)
 
 	return cmd
 }
@@ -419,7 +595,7 @@ func runDoctor(ctx context.Context, out io.Writer, opts runOptions) error {
 		sdktrace.WithResource(res),
 	)
 	defer func() {
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.drainTimeout())
 		defer cancel()
 		shutdownAll(shutdownCtx, []*sdktrace.TracerProvider{tp}, "tracer provider")
 	}()
@@ -427,7 +603,7 @@ func runDoctor(ctx context.Context, out io.Writer, opts runOptions) error {
 	_, span := tp.Tracer("motel-doctor").Start(ctx, "motel.doctor.canary")
 	span.End()
 
-	flushCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	flushCtx, cancel := context.WithTimeout(ctx, opts.drainTimeout())
 	defer cancel()
 	if err := tp.ForceFlush(flushCtx); err != nil {
 		return fmt.Errorf("canary trace export failed for %s: %w\n\nThe collector is reachable over TCP but rejected the trace. Check the protocol, path, TLS mode, and any required headers", resolved.hostPort, err)
@@ -460,14 +636,22 @@ func checkEndpointForEmit(opts runOptions) error {
 }
 
 func validateCmd() *cobra.Command {
-	var semconvDir string
+	var (
+		semconvDir    string
+		strictSemconv bool
+		fetchTimeout  time.Duration
+		fetchMaxBytes int64
+		warnSpans     int
+	)
 
 	cmd := &cobra.Command{
 		Use:   "validate <topology.yaml | URL>",
 		Short: "Parse and validate a topology configuration",
 		Long: "Parse and validate a topology configuration.\n\n" +
-			"The topology source can be a local file path or an HTTP/HTTPS URL.\n" +
-			"URL fetches have a 10-second timeout and a 10 MB response body limit.",
+			"The topology source can be a local file path, an HTTP/HTTPS URL, or - to\n" +
+			"read it from stdin.\n" +
+			"URL fetches have a 10-second timeout and a 10 MB response body limit;\n" +
+			"--fetch-timeout and --fetch-max-bytes change them.",
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("missing topology file or URL\n\nUsage: motel validate <topology.yaml | URL>")
@@ -475,7 +659,14 @@ func validateCmd() *cobra.Command {
 			return cobra.ExactArgs(1)(cmd, args)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := synth.LoadConfig(args[0])
+			if warnSpans < 0 {
+				return fmt.Errorf("--warn-spans must not be negative")
+			}
+			loadOpts, err := fetchOptions(fetchTimeout, fetchMaxBytes)
+			if err != nil {
+				return err
+			}
+			cfg, err := synth.LoadConfig(args[0], loadOpts...)
 			if err != nil {
 				return err
 			}
@@ -486,14 +677,28 @@ func validateCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			topo, err := synth.BuildTopology(cfg, domainResolver(reg))
+			topo, err := synth.BuildTopology(cfg, domainResolver(reg, semconvFillAll))
 			if err != nil {
 				return err
 			}
-			for _, w := range semconvMetricWarnings(cfg, reg) {
+			var warnings []string
+			warnings = append(warnings, semconvMetricWarnings(cfg, reg)...)
+			warnings = append(warnings, semconvLogWarnings(cfg, reg)...)
+			warnings = append(warnings, semconvAttributeWarnings(cfg, reg)...)
+			if strictSemconv && len(warnings) > 0 {
+				return fmt.Errorf("semantic convention check failed (--strict-semconv):\n  %s", strings.Join(warnings, "\n  "))
+			}
+			for _, w := range warnings {
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", w)
 			}
-			for _, w := range semconvLogWarnings(cfg, reg) {
+			if w := spanCountWarning(topo, warnSpans); w != "" {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", w)
+			}
+			scenarios, err := synth.BuildScenarios(cfg.Scenarios, topo)
+			if err != nil {
+				return err
+			}
+			for _, w := range synth.ScenarioWarnings(scenarios) {
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", w)
 			}
 			svcLabel := "services"
@@ -514,10 +719,51 @@ func validateCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&semconvDir, "semconv", "", "directory of additional semantic convention YAML files")
+	cmd.Flags().BoolVar(&strictSemconv, "strict-semconv", false, "treat semantic convention warnings as errors")
+	cmd.Flags().IntVar(&warnSpans, "warn-spans", defaultWarnSpans, "warn when a trace can reach more than this many spans (0 = never)")
+	addFetchFlags(cmd, &fetchTimeout, &fetchMaxBytes)
 
 	return cmd
 }
 
+// defaultWarnSpans is the worst-case spans per trace above which validate
+// warns.
+const defaultWarnSpans = 1000
+
+// spanCountWarning returns a warning when the worst-case spans per trace,
+// as computed by check's max-spans analysis, exceeds limit, and "" when it
+// does not or limit is 0.
+func spanCountWarning(topo *synth.Topology, limit int) string {
+	if limit == 0 {
+		return ""
+	}
+	spans, root := synth.MaxSpans(topo)
+	if spans <= limit {
+		return ""
+	}
+	return fmt.Sprintf("a trace from %s can reach %d spans, more than --warn-spans %d; "+
+		"consider capping traces with motel run --max-spans-per-trace", root, spans, limit)
+}
+
+// addFetchFlags registers the flags that limit fetching a topology from a URL.
+func addFetchFlags(cmd *cobra.Command, timeout *time.Duration, maxBytes *int64) {
+	defaults := synth.DefaultFetchOptions()
+	cmd.Flags().DurationVar(timeout, "fetch-timeout", defaults.Timeout, "timeout for fetching a topology or include from a URL")
+	cmd.Flags().Int64Var(maxBytes, "fetch-max-bytes", defaults.MaxBytes, "response body limit in bytes for fetching a topology or include from a URL")
+}
+
+// fetchOptions checks the --fetch-timeout and --fetch-max-bytes values and
+// returns them as LoadConfig options.
+func fetchOptions(timeout time.Duration, maxBytes int64) ([]synth.LoadOption, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("--fetch-timeout must be positive, got %s", timeout)
+	}
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("--fetch-max-bytes must be positive, got %d", maxBytes)
+	}
+	return []synth.LoadOption{synth.WithFetchTimeout(timeout), synth.WithFetchMaxBytes(maxBytes)}, nil
+}
+
 func importCmd() *cobra.Command {
 	var (
 		format           string
@@ -583,17 +829,34 @@ func importCmd() *cobra.Command {
 }
 
 func versionCmd() *cobra.Command {
-	return &cobra.Command{
+	var full bool
+
+	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
+		Long: "Print version information.\n\n" +
+			"With --full, also print the Go version motel was built with, the\n" +
+			"OpenTelemetry SDK version, and the topology config version it supports,\n" +
+			"for bug reports.",
 		Run: func(cmd *cobra.Command, args []string) {
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "motel %s (commit: %s, built: %s)\n", version, commit, buildTime)
+			w := cmd.OutOrStdout()
+			_, _ = fmt.Fprintf(w, "motel %s (commit: %s, built: %s)\n", version, commit, buildTime)
+			if full {
+				_, _ = fmt.Fprintf(w, "go: %s\n", runtime.Version())
+				_, _ = fmt.Fprintf(w, "otel sdk: %s\n", sdk.Version())
+				_, _ = fmt.Fprintf(w, "config version: %d\n", synth.CurrentVersion)
+			}
 		},
 	}
+
+	cmd.Flags().BoolVar(&full, "full", false, "also print Go, OpenTelemetry SDK, and config versions")
+
+	return cmd
 }
 
 type runOptions struct {
 	endpoint         string
+	endpoints        []string // every --endpoint value when the flag is repeated
 	endpointSet      bool
 	stdout           bool
 	duration         time.Duration
@@ -601,30 +864,62 @@ type runOptions struct {
 	protocolSet      bool
 	headers          string
 	headersSet       bool
+	headerPairs      []string // repeated --header key=value flags
+	authBearer       string
 	insecure         bool
 	insecureSet      bool
+	useTLS           bool
+	tlsCA            string
+	tlsCert          string
+	tlsKey           string
 	exportTimeout    time.Duration
 	timeoutSet       bool
 	signals          string
 	signalsChanged   bool
 	slowThreshold    time.Duration
+	logFormat        string // log body format for synth.ParseLogBodyFormat
 	maxSpansPerTrace int
+	maxTraceDuration time.Duration // zero means no cap
+	shutdownTimeout  time.Duration // bounds provider shutdown; zero means defaultShutdownTimeout
+	maxAttributes    int           // SDK span attribute count limit; zero keeps the SDK default
+	maxAttrLength    int           // SDK attribute value length limit; zero keeps the SDK default
 	semconvDir       string
+	semconvFill      string // semconvFillAll or semconvFillRequired; empty means all
 	labelScenarios   bool
+	linkRetries      bool
 	pprofAddr        string
 	timeOffset       time.Duration
+	backfill         bool
+	rate             string // replaces the topology's base traffic rate; empty keeps it
 	realtime         bool
 	seed             uint64
 	verbatim         bool
 	preserveIDs      bool
+	sampleRatio      float64
+	services         []string // restrict generation to these services; empty means all
+	root             string   // start every trace at this root operation; empty means all roots
+	maxTraces        int      // stop after this many traces; zero means no limit
+	untilScenarios   bool     // stop once the last scenario has ended
+	warmup           time.Duration
+	progress         bool
+	statsFile        string // final stats destination: empty for stderr, "-" for stdout
+	statsFormat      string // statsFormatCompact or statsFormatPretty
+	prometheusAddr   string // serve metrics for scraping at this address; empty disables
+	scenarioMetrics  bool   // count scenario activations in a metric reported as service motel
+	loadOptions      []synth.LoadOption
+	perOpStats       bool
+	watch            bool // restart the run when the topology file changes
+	resourceDetect   bool // merge detected host, OS, and process attributes into the base resource
+	deterministicIDs bool // derive trace and span IDs from the seed
 }
 
 type otlpConfig struct {
-	endpoint string
-	protocol string
-	headers  map[string]string
-	insecure bool
-	timeout  time.Duration
+	endpoint  string
+	protocol  string
+	headers   map[string]string
+	insecure  bool
+	tlsConfig *tls.Config // nil unless TLS was requested
+	timeout   time.Duration
 }
 
 const (
@@ -674,6 +969,27 @@ func resolveOTLPConfig(opts runOptions, signal string) (otlpConfig, error) {
 	if err != nil {
 		return otlpConfig{}, err
 	}
+	for _, pair := range opts.headerPairs {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return otlpConfig{}, fmt.Errorf("--header %q must be in key=value format", pair)
+		}
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	if opts.authBearer != "" {
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		for key := range headers {
+			if strings.EqualFold(key, "authorization") {
+				delete(headers, key)
+			}
+		}
+		headers["Authorization"] = "Bearer " + opts.authBearer
+	}
 	cfg.headers = headers
 
 	if opts.insecureSet {
@@ -686,6 +1002,21 @@ func resolveOTLPConfig(opts runOptions, signal string) (otlpConfig, error) {
 		cfg.insecure = parsed
 	}
 
+	tlsConfig, err := loadTLSConfig(opts)
+	if err != nil {
+		return otlpConfig{}, err
+	}
+	if tlsConfig != nil {
+		if opts.insecureSet && opts.insecure {
+			return otlpConfig{}, fmt.Errorf("--tls and --insecure cannot be used together")
+		}
+		if strings.HasPrefix(strings.ToLower(cfg.endpoint), "http://") {
+			return otlpConfig{}, fmt.Errorf("--tls requires an https endpoint URL, got %q", cfg.endpoint)
+		}
+		cfg.insecure = false
+		cfg.tlsConfig = tlsConfig
+	}
+
 	if opts.timeoutSet {
 		cfg.timeout = opts.exportTimeout
 	} else if timeoutValue := envFirst(signalEnv(signal, "TIMEOUT"), envOTLPTimeout); timeoutValue != "" {
@@ -698,6 +1029,73 @@ func resolveOTLPConfig(opts runOptions, signal string) (otlpConfig, error) {
 	return cfg, nil
 }
 
+// loadTLSConfig builds the client TLS configuration for OTLP exporters from
+// the --tls flags, or returns nil when TLS was not requested.
+func loadTLSConfig(opts runOptions) (*tls.Config, error) {
+	if !opts.useTLS && opts.tlsCA == "" && opts.tlsCert == "" && opts.tlsKey == "" {
+		return nil, nil
+	}
+	if (opts.tlsCert == "") != (opts.tlsKey == "") {
+		return nil, fmt.Errorf("--tls-cert and --tls-key must be used together")
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.tlsCA != "" {
+		pem, err := os.ReadFile(opts.tlsCA)
+		if err != nil {
+			return nil, fmt.Errorf("reading --tls-ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("--tls-ca %s: no PEM certificates found", opts.tlsCA)
+		}
+		cfg.RootCAs = pool
+	}
+	if opts.tlsCert != "" {
+		cert, err := tls.LoadX509KeyPair(opts.tlsCert, opts.tlsKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading TLS client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// perEndpoint returns one copy of opts for each --endpoint value, so that
+// exporter builders which handle a single endpoint can be fanned out across
+// several collectors. Without repeated endpoints it returns opts unchanged.
+func perEndpoint(opts runOptions) []runOptions {
+	if len(opts.endpoints) <= 1 {
+		return []runOptions{opts}
+	}
+	out := make([]runOptions, 0, len(opts.endpoints))
+	for _, endpoint := range opts.endpoints {
+		o := opts
+		o.endpoint = endpoint
+		o.endpoints = nil
+		out = append(out, o)
+	}
+	return out
+}
+
+// buildPerEndpoint builds one exporter per endpoint in opts. If any build
+// fails, the exporters already created are shut down.
+func buildPerEndpoint[E shutdownable](ctx context.Context, opts runOptions, build func(context.Context, runOptions) (E, error)) ([]E, error) {
+	targets := perEndpoint(opts)
+	exporters := make([]E, 0, len(targets))
+	for _, o := range targets {
+		exp, err := build(ctx, o)
+		if err != nil {
+			shutdownAll(ctx, exporters, "exporter")
+			if len(targets) > 1 {
+				return nil, fmt.Errorf("endpoint %s: %w", o.endpoint, err)
+			}
+			return nil, err
+		}
+		exporters = append(exporters, exp)
+	}
+	return exporters, nil
+}
+
 func parseOTLPHeaders(value string) (map[string]string, error) {
 	if strings.TrimSpace(value) == "" {
 		return nil, nil
@@ -735,6 +1133,7 @@ const (
 	rngStreamEngine  = 1
 	rngStreamMetrics = 2
 	rngStreamLogs    = 3
+	rngStreamIDs     = 4
 )
 
 // newRunRng returns the RNG for one consumer of randomness during a run.
@@ -783,12 +1182,25 @@ func parseSignals(s string) (map[string]bool, error) {
 const (
 	defaultDuration     = 1 * time.Minute
 	unlimitedDuration   = 24 * 365 * time.Hour
-	shutdownTimeout     = 5 * time.Second
 	connectCheckTimeout = 2 * time.Second
 	defaultHTTPPort     = "4318"
 	defaultGRPCPort     = "4317"
 )
 
+// scenarioMetricsService is the service.name --scenario-metrics reports the
+// scenario activations counter under, unless the topology has a service of
+// that name, whose resource it then shares.
+const scenarioMetricsService = "motel"
+
+// defaultShutdownTimeout bounds how long providers drain buffered signals at
+// exit when --shutdown-timeout is not given.
+const defaultShutdownTimeout = 5 * time.Second
+
+// drainTimeout returns the bound on provider shutdown for a run.
+func (o runOptions) drainTimeout() time.Duration {
+	return cmp.Or(o.shutdownTimeout, defaultShutdownTimeout)
+}
+
 type resolvedEndpoint struct {
 	hostPort    string
 	endpointURL string
@@ -852,21 +1264,33 @@ func dialEndpoint(endpoint, protocol string) (string, error) {
 	return resolved.hostPort, nil
 }
 
+// checkEndpoint verifies that the OTLP collector is reachable. With several
+// endpoints, unreachable ones are reported as warnings as long as at least
+// one collector accepts connections.
 func checkEndpoint(opts runOptions, configPath string) error {
-	cfg, err := resolveOTLPConfig(opts, "traces")
-	if err != nil {
-		return err
+	var unreachable []string
+	targets := perEndpoint(opts)
+	for _, o := range targets {
+		cfg, err := resolveOTLPConfig(o, "traces")
+		if err != nil {
+			return err
+		}
+		if host, err := dialEndpoint(cfg.endpoint, cfg.protocol); err != nil {
+			unreachable = append(unreachable, host)
+		}
 	}
-	host, err := dialEndpoint(cfg.endpoint, cfg.protocol)
-	if err != nil {
-		return fmt.Errorf("cannot reach OTLP collector at %s\n\n"+
-			"To emit signals as JSON to the terminal, use --stdout:\n"+
-			"  motel run --stdout --duration 10s %s\n\n"+
-			"To send to a specific collector, use --endpoint:\n"+
-			"  motel run --endpoint collector.example.com:4318 %s\n\n"+
-			"Without --duration, motel runs for 1 minute", host, configPath, configPath)
+	if len(unreachable) < len(targets) {
+		for _, host := range unreachable {
+			fmt.Fprintf(os.Stderr, "Warning: cannot reach OTLP collector at %s; continuing with the other endpoints\n", host)
+		}
+		return nil
 	}
-	return nil
+	return fmt.Errorf("cannot reach OTLP collector at %s\n\n"+
+		"To emit signals as JSON to the terminal, use --stdout:\n"+
+		"  motel run --stdout --duration 10s %s\n\n"+
+		"To send to a specific collector, use --endpoint:\n"+
+		"  motel run --endpoint collector.example.com:4318 %s\n\n"+
+		"Without --duration, motel runs for 1 minute", strings.Join(unreachable, ", "), configPath, configPath)
 }
 
 func runGenerate(ctx context.Context, configPath string, opts runOptions) error {
@@ -884,7 +1308,7 @@ func runGenerate(ctx context.Context, configPath string, opts runOptions) error
 			}
 		}()
 		defer func() {
-			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.drainTimeout())
 			defer cancel()
 			if err := pprofServer.Shutdown(shutdownCtx); err != nil {
 				fmt.Fprintf(os.Stderr, "pprof server shutdown error: %v\n", err)
@@ -892,7 +1316,11 @@ func runGenerate(ctx context.Context, configPath string, opts runOptions) error
 		}()
 	}
 
-	cfg, err := synth.LoadConfig(configPath)
+	if opts.watch {
+		return watchGenerate(ctx, configPath, opts)
+	}
+
+	cfg, err := synth.LoadConfig(configPath, opts.loadOptions...)
 	if err != nil {
 		return err
 	}
@@ -902,27 +1330,100 @@ func runGenerate(ctx context.Context, configPath string, opts runOptions) error
 	if cfg.Mode == synth.ModeReplay {
 		return runReplay(ctx, configPath, cfg, opts)
 	}
-	topo, err := buildTopology(cfg, opts.semconvDir)
+	plan, err := prepareRun(cfg, opts)
 	if err != nil {
 		return err
 	}
-	traffic, err := synth.NewTrafficPattern(cfg.Traffic)
+	return generate(ctx, configPath, plan, opts)
+}
+
+// prepareRun builds the topology, traffic pattern, and scenarios of cfg and
+// applies --rate, --service, and --root.
+func prepareRun(cfg *synth.Config, opts runOptions) (*synth.Generation, error) {
+	reg, err := loadRegistry(opts.semconvDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	scenarios, err := synth.BuildScenarios(cfg.Scenarios, topo)
+	if opts.rate != "" {
+		if err := synth.ScaleTrafficRate(&cfg.Traffic, opts.rate); err != nil {
+			return nil, fmt.Errorf("--rate: %w", err)
+		}
+	}
+	plan, err := synth.NewGeneration(cfg, domainResolver(reg, opts.semconvFill))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	if err := selectPlan(plan, opts); err != nil {
+		plan.Close() //nolint:errcheck,gosec // reporting the selection error instead
+		return nil, err
+	}
+	return plan, nil
+}
+
+// selectPlan applies --service, --root, and --until-scenarios-complete to
+// plan.
+func selectPlan(plan *synth.Generation, opts runOptions) error {
+	topo := plan.Topology
+	if len(opts.services) > 0 {
+		warnings, err := synth.SelectServices(topo, plan.Scenarios, opts.services)
+		if err != nil {
+			return fmt.Errorf("--service: %w", err)
+		}
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+	}
+	if opts.root != "" {
+		if err := synth.SelectRoot(topo, opts.root); err != nil {
+			return fmt.Errorf("--root: %w", err)
+		}
+	}
+	if opts.untilScenarios && len(plan.Scenarios) == 0 {
+		return fmt.Errorf("--until-scenarios-complete requires scenarios in the topology")
+	}
+	return nil
+}
+
+// generate runs the simulation for plan, closes its observers, and writes
+// the final stats.
+func generate(ctx context.Context, configPath string, plan *synth.Generation, opts runOptions) (err error) {
+	defer func() {
+		if closeErr := plan.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("closing observers: %w", closeErr)
+		}
+	}()
+	topo := plan.Topology
 
 	if opts.slowThreshold < 0 {
 		return fmt.Errorf("--slow-threshold must not be negative, got %s", opts.slowThreshold)
 	}
+	logFormat, err := synth.ParseLogBodyFormat(opts.logFormat)
+	if err != nil {
+		return fmt.Errorf("--log-format: %w", err)
+	}
+	if opts.sampleRatio <= 0 || opts.sampleRatio > 1 {
+		return fmt.Errorf("--sample-ratio must be greater than 0 and at most 1, got %v", opts.sampleRatio)
+	}
+	if opts.warmup < 0 {
+		return fmt.Errorf("--warmup must not be negative, got %s", opts.warmup)
+	}
+	if opts.maxTraceDuration < 0 {
+		return fmt.Errorf("--max-trace-duration must not be negative, got %s", opts.maxTraceDuration)
+	}
+	if opts.deterministicIDs && opts.seed == 0 {
+		return fmt.Errorf("--deterministic-ids requires a non-zero --seed")
+	}
 
 	enabledSignals, err := parseSignals(opts.signals)
 	if err != nil {
 		return err
 	}
+	if opts.prometheusAddr != "" && !enabledSignals["metrics"] {
+		return fmt.Errorf("--prometheus-addr requires metrics in --signals, e.g. --signals traces,metrics")
+	}
+	if opts.scenarioMetrics && !enabledSignals["metrics"] {
+		return fmt.Errorf("--scenario-metrics requires metrics in --signals, e.g. --signals traces,metrics")
+	}
 
 	if err := validateProtocol(opts.protocol); err != nil {
 		return err
@@ -934,9 +1435,7 @@ func runGenerate(ctx context.Context, configPath string, opts runOptions) error
 		}
 	}
 
-	baseRes, err := resource.Merge(resource.Default(), resource.NewSchemaless(
-		attribute.String("motel.version", version),
-	))
+	baseRes, err := newBaseResource(ctx, opts.resourceDetect)
 	if err != nil {
 		return fmt.Errorf("creating resource: %w", err)
 	}
@@ -975,7 +1474,18 @@ func runGenerate(ctx context.Context, configPath string, opts runOptions) error
 		if !topoHasMetrics(topo) {
 			fmt.Fprintln(os.Stderr, "warning: --signals includes metrics but the topology defines no metric instruments; no metric data will be emitted. Add a metrics: section to at least one service or operation.")
 		}
-		meters, shutdownMetrics, mErr := createMetricProviders(ctx, opts, serviceResources)
+		metricResources := serviceResources
+		if opts.scenarioMetrics && metricResources[scenarioMetricsService] == nil {
+			// Scenarios belong to no service, so their counter is reported
+			// under a resource of its own.
+			metricResources = maps.Clone(serviceResources)
+			metricResources[scenarioMetricsService], err = resource.Merge(baseRes,
+				resource.NewSchemaless(attribute.String("service.name", scenarioMetricsService)))
+			if err != nil {
+				return fmt.Errorf("creating resource for scenario metrics: %w", err)
+			}
+		}
+		meters, shutdownMetrics, mErr := createMetricProviders(ctx, opts, metricResources, serviceScopes(topo))
 		if mErr != nil {
 			return fmt.Errorf("creating metric providers: %w", mErr)
 		}
@@ -984,13 +1494,18 @@ func runGenerate(ctx context.Context, configPath string, opts runOptions) error
 		if mErr != nil {
 			return fmt.Errorf("creating metric observer: %w", mErr)
 		}
+		if opts.scenarioMetrics {
+			if mErr := obs.EnableScenarioActivations(meters[scenarioMetricsService]); mErr != nil {
+				return fmt.Errorf("creating metric observer: %w", mErr)
+			}
+		}
 		stopIntervals := obs.Start()
 		defer stopIntervals()
 		observers = append(observers, obs)
 	}
 
 	if enabledSignals["logs"] {
-		loggers, shutdownLogs, lErr := createLogProviders(ctx, opts, serviceResources)
+		loggers, shutdownLogs, lErr := createLogProviders(ctx, opts, serviceResources, serviceScopes(topo))
 		if lErr != nil {
 			return fmt.Errorf("creating log providers: %w", lErr)
 		}
@@ -999,6 +1514,7 @@ func runGenerate(ctx context.Context, configPath string, opts runOptions) error
 		if lErr != nil {
 			return fmt.Errorf("creating log observer: %w", lErr)
 		}
+		obs.SetBodyFormat(logFormat)
 		observers = append(observers, obs)
 	}
 
@@ -1006,32 +1522,47 @@ func runGenerate(ctx context.Context, configPath string, opts runOptions) error
 	if duration == 0 {
 		duration = defaultDuration
 	}
-
-	engine := &synth.Engine{
-		Topology:         topo,
-		Traffic:          traffic,
-		Scenarios:        scenarios,
-		Tracers:          tracers,
-		Rng:              newRunRng(opts.seed, rngStreamEngine),
-		Duration:         duration,
-		Observers:        observers,
-		MaxSpansPerTrace: opts.maxSpansPerTrace,
-		State:            synth.NewSimulationState(topo),
-		LabelScenarios:   opts.labelScenarios,
-		TimeOffset:       opts.timeOffset,
-		Realtime:         opts.realtime,
+	if opts.warmup >= duration {
+		return fmt.Errorf("--warmup %s must be shorter than --duration %s", opts.warmup, duration)
+	}
+
+	engine, err := plan.NewEngine(synth.GenerateOptions{
+		Tracers:           tracers,
+		Rng:               newRunRng(opts.seed, rngStreamEngine),
+		Duration:          duration,
+		Observers:         observers,
+		Traces:            opts.maxTraces,
+		MaxSpansPerTrace:  opts.maxSpansPerTrace,
+		MaxTraceDuration:  opts.maxTraceDuration,
+		LabelScenarios:    opts.labelScenarios,
+		LinkRetries:       opts.linkRetries,
+		TimeOffset:        opts.timeOffset,
+		Realtime:          opts.realtime,
+		Backfill:          opts.backfill,
+		SampleRatio:       opts.sampleRatio,
+		Warmup:            opts.warmup,
+		CollectPerOp:      opts.perOpStats,
+		UntilScenariosEnd: opts.untilScenarios,
+	})
+	if err != nil {
+		return err
 	}
 
 	// Handle OS signals for graceful shutdown
 	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	stopProgress := func() {}
+	if opts.progress {
+		stopProgress = startProgress(os.Stderr, engine, progressInterval)
+	}
 	stats, err := engine.Run(ctx)
+	stopProgress()
 	if err != nil {
 		return err
 	}
 
-	return json.NewEncoder(os.Stderr).Encode(stats)
+	return writeStats(stats, opts.statsFile, opts.statsFormat)
 }
 
 // runReplay re-emits a recorded trace sidecar referenced by a replay-mode
@@ -1045,6 +1576,48 @@ func runReplay(ctx context.Context, configPath string, cfg *synth.Config, opts r
 	if opts.signalsChanged {
 		return fmt.Errorf("--signals is not supported with mode: replay; leave --signals off because replay emits recorded traces only")
 	}
+	if opts.sampleRatio != 1 {
+		return fmt.Errorf("--sample-ratio is not supported with mode: replay")
+	}
+	if len(opts.services) > 0 {
+		return fmt.Errorf("--service is not supported with mode: replay")
+	}
+	if opts.root != "" {
+		return fmt.Errorf("--root is not supported with mode: replay")
+	}
+	if opts.maxTraces != 0 {
+		return fmt.Errorf("--max-traces is not supported with mode: replay")
+	}
+	if opts.untilScenarios {
+		return fmt.Errorf("--until-scenarios-complete is not supported with mode: replay")
+	}
+	if opts.warmup != 0 {
+		return fmt.Errorf("--warmup is not supported with mode: replay")
+	}
+	if opts.progress {
+		return fmt.Errorf("--progress is not supported with mode: replay")
+	}
+	if opts.perOpStats {
+		return fmt.Errorf("--per-operation-stats is not supported with mode: replay")
+	}
+	if opts.deterministicIDs {
+		return fmt.Errorf("--deterministic-ids is not supported with mode: replay")
+	}
+	if opts.prometheusAddr != "" {
+		return fmt.Errorf("--prometheus-addr is not supported with mode: replay")
+	}
+	if opts.scenarioMetrics {
+		return fmt.Errorf("--scenario-metrics is not supported with mode: replay")
+	}
+	if opts.maxTraceDuration != 0 {
+		return fmt.Errorf("--max-trace-duration is not supported with mode: replay")
+	}
+	if opts.backfill {
+		return fmt.Errorf("--backfill is not supported with mode: replay")
+	}
+	if opts.rate != "" {
+		return fmt.Errorf("--rate is not supported with mode: replay")
+	}
 	if err := validateProtocol(opts.protocol); err != nil {
 		return err
 	}
@@ -1071,11 +1644,27 @@ func runReplay(ctx context.Context, configPath string, cfg *synth.Config, opts r
 		}
 	}
 
-	baseRes, err := resource.Merge(resource.Default(), resource.NewSchemaless(
-		attribute.String("motel.version", version),
-	))
+	f, err := os.Open(recordingPath) //nolint:gosec // recording path comes from the user's config
 	if err != nil {
-		return fmt.Errorf("creating resource: %w", err)
+		return fmt.Errorf("opening recording: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // read-only file, close error is not actionable
+
+	stats, err := emitRecording(ctx, f, info, opts)
+	if err != nil {
+		return err
+	}
+
+	return writeStats(stats, opts.statsFile, opts.statsFormat)
+}
+
+// emitRecording builds trace providers for the services in info and streams
+// the recording read from r through them. info must come from an earlier
+// scan of the same recording.
+func emitRecording(ctx context.Context, r io.Reader, info synth.RecordingInfo, opts runOptions) (*synth.Stats, error) {
+	baseRes, err := newBaseResource(ctx, opts.resourceDetect)
+	if err != nil {
+		return nil, fmt.Errorf("creating resource: %w", err)
 	}
 
 	serviceResources := make(map[string]*resource.Resource, len(info.Services))
@@ -1084,20 +1673,20 @@ func runReplay(ctx context.Context, configPath string, cfg *synth.Config, opts r
 			attribute.String("service.name", name),
 		))
 		if resErr != nil {
-			return fmt.Errorf("creating resource for service %s: %w", name, resErr)
+			return nil, fmt.Errorf("creating resource for service %s: %w", name, resErr)
 		}
 		serviceResources[name] = svcRes
 	}
 
 	traceProviders, shutdownTraces, err := createTraceProviders(ctx, opts, true, serviceResources)
 	if err != nil {
-		return fmt.Errorf("creating trace providers: %w", err)
+		return nil, fmt.Errorf("creating trace providers: %w", err)
 	}
 	defer shutdownTraces()
 
-	tracers, err := tracerSourceForServices(info.Services, traceProviders)
+	tracers, err := tracerSourceForServices(info.Services, traceProviders, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
@@ -1109,12 +1698,7 @@ func runReplay(ctx context.Context, configPath string, cfg *synth.Config, opts r
 		Start:       info.Start,
 		Anchor:      time.Now().Add(opts.timeOffset),
 	}
-	stats, err := synth.ReplayRecording(ctx, recordingPath, tracers, nil, replayOpts)
-	if err != nil {
-		return err
-	}
-
-	return json.NewEncoder(os.Stderr).Encode(stats)
+	return synth.ReplayRecordingFrom(ctx, r, tracers, nil, replayOpts)
 }
 
 func tracerSource(topo *synth.Topology, providers map[string]*sdktrace.TracerProvider) (synth.TracerSource, error) {
@@ -1122,10 +1706,27 @@ func tracerSource(topo *synth.Topology, providers map[string]*sdktrace.TracerPro
 	for name := range topo.Services {
 		names = append(names, name)
 	}
-	return tracerSourceForServices(names, providers)
+	return tracerSourceForServices(names, providers, serviceScopes(topo))
 }
 
-func tracerSourceForServices(names []string, providers map[string]*sdktrace.TracerProvider) (synth.TracerSource, error) {
+// instrumentationScope is the scope a service's signals are emitted under.
+// Empty fields fall back to motel's own scope name and version.
+type instrumentationScope struct {
+	name    string
+	version string
+}
+
+// serviceScopes returns the instrumentation scope configured for each
+// service in topo.
+func serviceScopes(topo *synth.Topology) map[string]instrumentationScope {
+	scopes := make(map[string]instrumentationScope, len(topo.Services))
+	for name, svc := range topo.Services {
+		scopes[name] = instrumentationScope{name: svc.ScopeName, version: svc.ScopeVersion}
+	}
+	return scopes
+}
+
+func tracerSourceForServices(names []string, providers map[string]*sdktrace.TracerProvider, scopes map[string]instrumentationScope) (synth.TracerSource, error) {
 	for _, name := range names {
 		if providers[name] == nil {
 			return nil, fmt.Errorf("missing tracer provider for service %q", name)
@@ -1139,8 +1740,9 @@ func tracerSourceForServices(names []string, providers map[string]*sdktrace.Trac
 			return missingProvider.Tracer("github.com/andrewh/motel")
 		}
 
-		return provider.Tracer("github.com/andrewh/motel",
-			trace.WithInstrumentationVersion(version),
+		scope := scopes[name]
+		return provider.Tracer(cmp.Or(scope.name, "github.com/andrewh/motel"),
+			trace.WithInstrumentationVersion(cmp.Or(scope.version, version)),
 			trace.WithSchemaURL(otelsc.SchemaURL),
 			trace.WithInstrumentationAttributes(
 				attribute.Bool("motel.synthetic", true),
@@ -1149,6 +1751,27 @@ func tracerSourceForServices(names []string, providers map[string]*sdktrace.Trac
 	}, nil
 }
 
+// newBaseResource returns the resource every service's resource is built on:
+// the SDK defaults plus motel.version. With detect set, host, OS, and process
+// attributes detected on this machine are merged in first, so motel's own
+// attributes, service.name, and topology resource_attributes take precedence.
+func newBaseResource(ctx context.Context, detect bool) (*resource.Resource, error) {
+	base := resource.Default()
+	if detect {
+		detected, err := resource.New(ctx, resource.WithHost(), resource.WithOS(), resource.WithProcess())
+		if err != nil && !errors.Is(err, resource.ErrPartialResource) {
+			return nil, fmt.Errorf("detecting resource: %w", err)
+		}
+		base, err = resource.Merge(base, detected)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resource.Merge(base, resource.NewSchemaless(
+		attribute.String("motel.version", version),
+	))
+}
+
 // createTraceProviders creates one TracerProvider per service sharing a single exporter
 // and processor. Returns a map of service name → provider and a shutdown function.
 func createTraceProviders(ctx context.Context, opts runOptions, enabled bool, resources map[string]*resource.Resource) (map[string]*sdktrace.TracerProvider, func(), error) {
@@ -1171,12 +1794,22 @@ func createTraceProviders(ctx context.Context, opts runOptions, enabled bool, re
 	}
 
 	var sp sdktrace.SpanProcessor
-	if opts.stdout {
+	switch {
+	case opts.stdout:
 		sp = sdktrace.NewSimpleSpanProcessor(exporter)
-	} else {
+	case opts.backfill:
+		// Backfill produces spans with no pause, so a full queue must hold
+		// up generation rather than drop them.
+		sp = sdktrace.NewBatchSpanProcessor(exporter, sdktrace.WithBlocking())
+	default:
 		sp = sdktrace.NewBatchSpanProcessor(exporter)
 	}
 
+	// Every provider shares one generator so IDs stay unique across services.
+	var ids sdktrace.IDGenerator
+	if opts.deterministicIDs {
+		ids = synth.NewSeededIDGenerator(newRunRng(opts.seed, rngStreamIDs))
+	}
 	for name, res := range resources {
 		providerOpts := []sdktrace.TracerProviderOption{
 			sdktrace.WithSpanProcessor(sp),
@@ -1185,21 +1818,58 @@ func createTraceProviders(ctx context.Context, opts runOptions, enabled bool, re
 		if opts.preserveIDs {
 			providerOpts = append(providerOpts, sdktrace.WithIDGenerator(synth.NewReplayIDGenerator()))
 		}
+		if ids != nil {
+			providerOpts = append(providerOpts, sdktrace.WithIDGenerator(ids))
+		}
+		if limits, ok := spanLimits(opts); ok {
+			providerOpts = append(providerOpts, sdktrace.WithSpanLimits(limits))
+		}
 		providers[name] = sdktrace.NewTracerProvider(providerOpts...)
 	}
 
 	shutdown := func() {
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.drainTimeout())
 		defer cancel()
 		shutdownAll(shutdownCtx, slices.Collect(maps.Values(providers)), "tracer provider")
 	}
 	return providers, shutdown, nil
 }
 
+// spanLimits returns the SDK span limits requested by --max-attributes and
+// --max-attribute-length, starting from the SDK defaults (which honour the
+// OTEL_SPAN_ATTRIBUTE_* environment variables). It reports false when neither
+// flag is set, leaving the provider's limits alone.
+func spanLimits(opts runOptions) (sdktrace.SpanLimits, bool) {
+	if opts.maxAttributes == 0 && opts.maxAttrLength == 0 {
+		return sdktrace.SpanLimits{}, false
+	}
+	limits := sdktrace.NewSpanLimits()
+	if opts.maxAttributes > 0 {
+		limits.AttributeCountLimit = opts.maxAttributes
+	}
+	if opts.maxAttrLength > 0 {
+		limits.AttributeValueLengthLimit = opts.maxAttrLength
+	}
+	return limits, true
+}
+
+// createTraceExporter builds the traces exporter. Repeated --endpoint flags
+// produce a fan-out exporter that forwards to every collector.
 func createTraceExporter(ctx context.Context, opts runOptions) (sdktrace.SpanExporter, error) {
 	if opts.stdout {
 		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
 	}
+	exporters, err := buildPerEndpoint(ctx, opts, newOTLPTraceExporter)
+	if err != nil {
+		return nil, err
+	}
+	if len(exporters) == 1 {
+		return exporters[0], nil
+	}
+	return &fanOutSpanExporter{exporters: exporters}, nil
+}
+
+func newOTLPTraceExporter(ctx context.Context, opts runOptions) (sdktrace.SpanExporter, error) {
 	cfg, err := resolveOTLPConfig(opts, "traces")
 	if err != nil {
 		return nil, err
@@ -1218,7 +1888,9 @@ func createTraceExporter(ctx context.Context, opts runOptions) (sdktrace.SpanExp
 				grpcOpts = append(grpcOpts, otlptracegrpc.WithEndpoint(resolved.hostPort))
 			}
 		}
-		if cfg.insecure || (cfg.endpoint != "" && resolved.endpointURL == "") {
+		if cfg.tlsConfig != nil {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(cfg.tlsConfig)))
+		} else if cfg.insecure || (cfg.endpoint != "" && resolved.endpointURL == "") {
 			grpcOpts = append(grpcOpts, otlptracegrpc.WithInsecure())
 		}
 		if len(cfg.headers) > 0 {
@@ -1237,7 +1909,9 @@ func createTraceExporter(ctx context.Context, opts runOptions) (sdktrace.SpanExp
 				httpOpts = append(httpOpts, otlptracehttp.WithEndpoint(resolved.hostPort))
 			}
 		}
-		if cfg.insecure || (cfg.endpoint != "" && resolved.endpointURL == "") {
+		if cfg.tlsConfig != nil {
+			httpOpts = append(httpOpts, otlptracehttp.WithTLSClientConfig(cfg.tlsConfig))
+		} else if cfg.insecure || (cfg.endpoint != "" && resolved.endpointURL == "") {
 			httpOpts = append(httpOpts, otlptracehttp.WithInsecure())
 		}
 		if len(cfg.headers) > 0 {
@@ -1263,8 +1937,9 @@ type noopShutdownMetricExporter struct {
 func (e *noopShutdownMetricExporter) Shutdown(context.Context) error { return nil }
 
 // createMetricProviders creates per-service meters sharing a single exporter.
+// With --prometheus-addr the meters are also served for scraping.
 // Returns a map of service name → Meter and a shutdown function.
-func createMetricProviders(ctx context.Context, opts runOptions, resources map[string]*resource.Resource) (map[string]metric.Meter, func(), error) {
+func createMetricProviders(ctx context.Context, opts runOptions, resources map[string]*resource.Resource, scopes map[string]instrumentationScope) (map[string]metric.Meter, func(), error) {
 	exporter, err := createMetricExporter(ctx, opts)
 	if err != nil {
 		return nil, func() {}, err
@@ -1274,17 +1949,40 @@ func createMetricProviders(ctx context.Context, opts runOptions, resources map[s
 	providers := make([]*sdkmetric.MeterProvider, 0, len(resources))
 	meters := make(map[string]metric.Meter, len(resources))
 
+	var promReg *prometheus.Registry
+	if opts.prometheusAddr != "" {
+		promReg = prometheus.NewRegistry()
+	}
+
 	for name, res := range resources {
-		mp := sdkmetric.NewMeterProvider(
+		providerOpts := []sdkmetric.Option{
 			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(wrapper)),
 			sdkmetric.WithResource(res),
-		)
+		}
+		if promReg != nil {
+			reader, promErr := newPrometheusReader(promReg)
+			if promErr != nil {
+				return nil, func() {}, fmt.Errorf("creating Prometheus exporter: %w", promErr)
+			}
+			providerOpts = append(providerOpts, sdkmetric.WithReader(reader))
+		}
+		mp := sdkmetric.NewMeterProvider(providerOpts...)
 		providers = append(providers, mp)
-		meters[name] = mp.Meter("motel")
+		scope := scopes[name]
+		meters[name] = mp.Meter(cmp.Or(scope.name, "motel"), metric.WithInstrumentationVersion(cmp.Or(scope.version, version)))
+	}
+
+	stopPrometheus := func() {}
+	if promReg != nil {
+		stopPrometheus, err = startPrometheus(opts.prometheusAddr, promReg)
+		if err != nil {
+			return nil, func() {}, err
+		}
 	}
 
 	shutdown := func() {
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		stopPrometheus()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.drainTimeout())
 		defer cancel()
 		shutdownAll(shutdownCtx, providers, "meter provider")
 		if err := exporter.Shutdown(shutdownCtx); err != nil {
@@ -1294,10 +1992,23 @@ func createMetricProviders(ctx context.Context, opts runOptions, resources map[s
 	return meters, shutdown, nil
 }
 
+// createMetricExporter builds the metrics exporter. Repeated --endpoint flags
+// produce a fan-out exporter that forwards to every collector.
 func createMetricExporter(ctx context.Context, opts runOptions) (sdkmetric.Exporter, error) {
 	if opts.stdout {
 		return stdoutmetric.New(stdoutmetric.WithWriter(os.Stdout))
 	}
+	exporters, err := buildPerEndpoint(ctx, opts, newOTLPMetricExporter)
+	if err != nil {
+		return nil, err
+	}
+	if len(exporters) == 1 {
+		return exporters[0], nil
+	}
+	return &fanOutMetricExporter{exporters: exporters}, nil
+}
+
+func newOTLPMetricExporter(ctx context.Context, opts runOptions) (sdkmetric.Exporter, error) {
 	cfg, err := resolveOTLPConfig(opts, "metrics")
 	if err != nil {
 		return nil, err
@@ -1316,7 +2027,9 @@ func createMetricExporter(ctx context.Context, opts runOptions) (sdkmetric.Expor
 				grpcOpts = append(grpcOpts, otlpmetricgrpc.WithEndpoint(resolved.hostPort))
 			}
 		}
-		if cfg.insecure || (cfg.endpoint != "" && resolved.endpointURL == "") {
+		if cfg.tlsConfig != nil {
+			grpcOpts = append(grpcOpts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(cfg.tlsConfig)))
+		} else if cfg.insecure || (cfg.endpoint != "" && resolved.endpointURL == "") {
 			grpcOpts = append(grpcOpts, otlpmetricgrpc.WithInsecure())
 		}
 		if len(cfg.headers) > 0 {
@@ -1335,7 +2048,9 @@ func createMetricExporter(ctx context.Context, opts runOptions) (sdkmetric.Expor
 				httpOpts = append(httpOpts, otlpmetrichttp.WithEndpoint(resolved.hostPort))
 			}
 		}
-		if cfg.insecure || (cfg.endpoint != "" && resolved.endpointURL == "") {
+		if cfg.tlsConfig != nil {
+			httpOpts = append(httpOpts, otlpmetrichttp.WithTLSClientConfig(cfg.tlsConfig))
+		} else if cfg.insecure || (cfg.endpoint != "" && resolved.endpointURL == "") {
 			httpOpts = append(httpOpts, otlpmetrichttp.WithInsecure())
 		}
 		if len(cfg.headers) > 0 {
@@ -1352,7 +2067,7 @@ func createMetricExporter(ctx context.Context, opts runOptions) (sdkmetric.Expor
 
 // createLogProviders creates per-service loggers sharing a single exporter and processor.
 // Returns a map of service name → Logger and a shutdown function.
-func createLogProviders(ctx context.Context, opts runOptions, resources map[string]*resource.Resource) (map[string]log.Logger, func(), error) {
+func createLogProviders(ctx context.Context, opts runOptions, resources map[string]*resource.Resource, scopes map[string]instrumentationScope) (map[string]log.Logger, func(), error) {
 	exporter, err := createLogExporter(ctx, opts)
 	if err != nil {
 		return nil, func() {}, err
@@ -1374,21 +2089,35 @@ func createLogProviders(ctx context.Context, opts runOptions, resources map[stri
 			sdklog.WithResource(res),
 		)
 		providers = append(providers, lp)
-		loggers[name] = lp.Logger("motel")
+		scope := scopes[name]
+		loggers[name] = lp.Logger(cmp.Or(scope.name, "motel"), log.WithInstrumentationVersion(cmp.Or(scope.version, version)))
 	}
 
 	shutdown := func() {
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.drainTimeout())
 		defer cancel()
 		shutdownAll(shutdownCtx, providers, "logger provider")
 	}
 	return loggers, shutdown, nil
 }
 
+// createLogExporter builds the logs exporter. Repeated --endpoint flags
+// produce a fan-out exporter that forwards to every collector.
 func createLogExporter(ctx context.Context, opts runOptions) (sdklog.Exporter, error) {
 	if opts.stdout {
 		return stdoutlog.New(stdoutlog.WithWriter(os.Stdout))
 	}
+	exporters, err := buildPerEndpoint(ctx, opts, newOTLPLogExporter)
+	if err != nil {
+		return nil, err
+	}
+	if len(exporters) == 1 {
+		return exporters[0], nil
+	}
+	return &fanOutLogExporter{exporters: exporters}, nil
+}
+
+func newOTLPLogExporter(ctx context.Context, opts runOptions) (sdklog.Exporter, error) {
 	cfg, err := resolveOTLPConfig(opts, "logs")
 	if err != nil {
 		return nil, err
@@ -1407,7 +2136,9 @@ func createLogExporter(ctx context.Context, opts runOptions) (sdklog.Exporter, e
 				grpcOpts = append(grpcOpts, otlploggrpc.WithEndpoint(resolved.hostPort))
 			}
 		}
-		if cfg.insecure || (cfg.endpoint != "" && resolved.endpointURL == "") {
+		if cfg.tlsConfig != nil {
+			grpcOpts = append(grpcOpts, otlploggrpc.WithTLSCredentials(credentials.NewTLS(cfg.tlsConfig)))
+		} else if cfg.insecure || (cfg.endpoint != "" && resolved.endpointURL == "") {
 			grpcOpts = append(grpcOpts, otlploggrpc.WithInsecure())
 		}
 		if len(cfg.headers) > 0 {
@@ -1426,7 +2157,9 @@ func createLogExporter(ctx context.Context, opts runOptions) (sdklog.Exporter, e
 				httpOpts = append(httpOpts, otlploghttp.WithEndpoint(resolved.hostPort))
 			}
 		}
-		if cfg.insecure || (cfg.endpoint != "" && resolved.endpointURL == "") {
+		if cfg.tlsConfig != nil {
+			httpOpts = append(httpOpts, otlploghttp.WithTLSClientConfig(cfg.tlsConfig))
+		} else if cfg.insecure || (cfg.endpoint != "" && resolved.endpointURL == "") {
 			httpOpts = append(httpOpts, otlploghttp.WithInsecure())
 		}
 		if len(cfg.headers) > 0 {
@@ -1448,16 +2181,24 @@ type shutdownable interface {
 
 // shutdownAll shuts down all items concurrently within the given context.
 // Errors are logged to stderr individually; a slow item does not block others.
+// It returns once ctx is done even if an item ignores ctx and is still
+// shutting down, so the context deadline bounds the whole shutdown.
 func shutdownAll[S shutdownable](ctx context.Context, items []S, label string) {
-	var wg sync.WaitGroup
+	errs := make(chan error, len(items))
 	for _, item := range items {
-		wg.Go(func() {
-			if err := item.Shutdown(ctx); err != nil {
+		go func() { errs <- item.Shutdown(ctx) }()
+	}
+	for done := range len(items) {
+		select {
+		case err := <-errs:
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "error shutting down %s: %v\n", label, err)
 			}
-		})
+		case <-ctx.Done():
+			fmt.Fprintf(os.Stderr, "error shutting down %s: %v (%d of %d still running)\n", label, ctx.Err(), len(items)-done, len(items))
+			return
+		}
 	}
-	wg.Wait()
 }
 
 func buildTopology(cfg *synth.Config, semconvDir string) (*synth.Topology, error) {
@@ -1465,7 +2206,7 @@ func buildTopology(cfg *synth.Config, semconvDir string) (*synth.Topology, error
 	if err != nil {
 		return nil, err
 	}
-	return synth.BuildTopology(cfg, domainResolver(reg))
+	return synth.BuildTopology(cfg, domainResolver(reg, semconvFillAll))
 }
 
 // loadRegistry loads the embedded semantic convention registry, merged with
@@ -1565,6 +2306,91 @@ func semconvLogWarnings(cfg *synth.Config, reg *semconv.Registry) []string {
 	return warnings
 }
 
+// semconvAttributeWarnings checks the attributes of operations that declare
+// a domain against that domain's semantic convention group, returning
+// warnings for values whose type does not match the convention and for keys
+// in the domain's namespace (e.g. http.* for http) that it does not define.
+// Keys outside the namespace are custom attributes and are not warned about.
+func semconvAttributeWarnings(cfg *synth.Config, reg *semconv.Registry) []string {
+	var warnings []string
+	for _, svc := range cfg.Services {
+		for _, op := range svc.Operations {
+			domains := op.DomainNames()
+			if len(domains) == 0 {
+				continue
+			}
+			defs := make(map[string]*semconv.Attribute)
+			for _, domain := range domains {
+				g := domainGroup(reg, domain)
+				if g == nil {
+					continue
+				}
+				for i := range g.Attributes {
+					defs[g.Attributes[i].ID] = &g.Attributes[i]
+				}
+			}
+			scope := fmt.Sprintf("service %q operation %q", svc.Name, op.Name)
+			for _, name := range slices.Sorted(maps.Keys(op.Attributes)) {
+				def, ok := defs[name]
+				if !ok {
+					if domain := domainOwning(domains, name); domain != "" {
+						warnings = append(warnings, fmt.Sprintf("%s: attribute %q is not defined by semantic convention domain %q",
+							scope, name, domain))
+					}
+					continue
+				}
+				want := semconvValueKind(def)
+				got := synth.AttributeValueKind(op.Attributes[name])
+				if want != "" && got != "" && want != got {
+					warnings = append(warnings, fmt.Sprintf("%s: attribute %q: generates %s values; semantic convention type is %s",
+						scope, name, got, def.Type.Value))
+				}
+			}
+		}
+	}
+	return warnings
+}
+
+// domainOwning returns the domain whose namespace contains the attribute
+// key, or "" if none does.
+func domainOwning(domains []string, key string) string {
+	for _, domain := range domains {
+		if strings.HasPrefix(key, domain+".") {
+			return domain
+		}
+	}
+	return ""
+}
+
+// semconvValueKind maps a semantic convention attribute type to the value
+// kind reported by synth.AttributeValueKind. Enums take the kind of their
+// members. It returns "" for types motel does not check, such as arrays.
+func semconvValueKind(def *semconv.Attribute) string {
+	switch def.Type.Value {
+	case "string":
+		return "string"
+	case "int":
+		return "integer"
+	case "double":
+		return "float"
+	case "boolean":
+		return "boolean"
+	case "enum":
+		if len(def.Type.Members) == 0 {
+			return ""
+		}
+		switch def.Type.Members[0].Value.(type) {
+		case string:
+			return "string"
+		case int, int64:
+			return "integer"
+		case float64:
+			return "float"
+		}
+	}
+	return ""
+}
+
 // enumAllows reports whether v matches one of the enum members of def,
 // comparing string representations to tolerate YAML scalar typing.
 func enumAllows(def *semconv.Attribute, v any) bool {
@@ -1576,10 +2402,11 @@ func enumAllows(def *semconv.Attribute, v any) bool {
 	return false
 }
 
-// topoHasMetrics reports whether any service or operation in the topology defines at least one metric.
+// topoHasMetrics reports whether any service or operation in the topology defines at least one metric,
+// or any service declares an SLO, whose burn rate is reported as a metric.
 func topoHasMetrics(topo *synth.Topology) bool {
 	for _, svc := range topo.Services {
-		if len(svc.Metrics) > 0 {
+		if len(svc.Metrics) > 0 || svc.SLO != nil {
 			return true
 		}
 		for _, op := range svc.Operations {
@@ -1591,17 +2418,35 @@ func topoHasMetrics(topo *synth.Topology) bool {
 	return false
 }
 
-func domainResolver(reg *semconv.Registry) synth.DomainResolver {
+// Values for run --semconv-fill: which attributes of an operation's domain
+// are generated.
+const (
+	semconvFillAll      = "all"
+	semconvFillRequired = "required"
+)
+
+// domainResolver resolves operation domains to the generators of their
+// semconv group: every attribute by default, or with fill set to
+// semconvFillRequired only those the group marks as required.
+func domainResolver(reg *semconv.Registry, fill string) synth.DomainResolver {
 	return func(domain string) map[string]synth.AttributeGenerator {
-		g := reg.Group(domain)
-		if g == nil {
-			// Semconv registry groups use a "registry." prefix (e.g. "registry.http")
-			// but configs use the short domain name (e.g. "http") for convenience.
-			g = reg.Group("registry." + domain)
-		}
+		g := domainGroup(reg, domain)
 		if g == nil {
 			return nil
 		}
+		if fill == semconvFillRequired {
+			return semconv.RequiredGeneratorsFor(reg, g)
+		}
 		return semconv.GeneratorsFor(g)
 	}
 }
+
+// domainGroup looks up the semconv group for an operation domain.
+func domainGroup(reg *semconv.Registry, domain string) *semconv.Group {
+	if g := reg.Group(domain); g != nil {
+		return g
+	}
+	// Semconv registry groups use a "registry." prefix (e.g. "registry.http")
+	// but configs use the short domain name (e.g. "http") for convenience.
+	return reg.Group("registry." + domain)
+}
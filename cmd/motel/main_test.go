@@ -5,21 +5,34 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand/v2"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"testing/fstest"
 	"time"
 
+	"github.com/andrewh/motel/pkg/pipelinetest"
 	"github.com/andrewh/motel/pkg/semconv"
 	"github.com/andrewh/motel/pkg/synth"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/protobuf/proto"
 )
 
 func writeTestConfig(t *testing.T, content string) string {
@@ -80,6 +93,71 @@ func TestTracerSourceReturnsTracer(t *testing.T) {
 	assert.NotNil(t, tracers("gateway"))
 }
 
+func TestTracerSourceScope(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := synth.ParseConfig([]byte(`
+version: 1
+scope_name: io.opentelemetry.okhttp-3.0
+scope_version: 2.1.0
+services:
+  gateway:
+    operations:
+      request:
+        duration: 1ms
+        calls: [backend.list]
+  backend:
+    scope_name: io.opentelemetry.jdbc
+    operations:
+      list:
+        duration: 1ms
+traffic:
+  rate: 1/s
+`))
+	require.NoError(t, err)
+	topo, err := synth.BuildTopology(cfg)
+	require.NoError(t, err)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { require.NoError(t, tp.Shutdown(context.Background())) })
+	tracers, err := tracerSource(topo, map[string]*sdktrace.TracerProvider{"gateway": tp, "backend": tp})
+	require.NoError(t, err)
+
+	for _, name := range []string{"gateway", "backend"} {
+		_, span := tracers(name).Start(t.Context(), name)
+		span.End()
+	}
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "io.opentelemetry.okhttp-3.0", spans[0].InstrumentationScope.Name)
+	assert.Equal(t, "2.1.0", spans[0].InstrumentationScope.Version)
+	assert.Equal(t, "io.opentelemetry.jdbc", spans[1].InstrumentationScope.Name, "a service's scope_name overrides the top-level one")
+	assert.Equal(t, "2.1.0", spans[1].InstrumentationScope.Version)
+}
+
+func TestTracerSourceDefaultScope(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { require.NoError(t, tp.Shutdown(context.Background())) })
+	topo := &synth.Topology{Services: map[string]*synth.Service{
+		"gateway": {Name: "gateway"},
+	}}
+
+	tracers, err := tracerSource(topo, map[string]*sdktrace.TracerProvider{"gateway": tp})
+	require.NoError(t, err)
+	_, span := tracers("gateway").Start(t.Context(), "request")
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "github.com/andrewh/motel", spans[0].InstrumentationScope.Name)
+	assert.Equal(t, version, spans[0].InstrumentationScope.Version)
+}
+
 func TestValidateCommand(t *testing.T) {
 	t.Parallel()
 
@@ -180,6 +258,110 @@ traffic:
 	})
 }
 
+func TestRenderCommand(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, `
+version: 1
+templates:
+  endpoint:
+    domain: http
+    duration: 20ms
+    error_rate: 1%
+services:
+  gateway:
+    operations:
+      GET /users:
+        template: endpoint
+        calls:
+          - target: backend.list
+      GET /orders:
+        template: endpoint
+        duration: 40ms
+  backend:
+    operations:
+      list:
+        duration: 5ms
+traffic:
+  rate: 10/s
+`)
+
+	root := rootCmd()
+	root.SetArgs([]string{"render", path})
+	var out bytes.Buffer
+	root.SetOut(&out)
+	require.NoError(t, root.Execute())
+
+	assert.NotContains(t, out.String(), "template")
+	rendered, err := synth.ParseConfig(out.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, synth.ValidateConfig(rendered))
+	ops := rendered.Services[1].Operations
+	require.Len(t, ops, 2)
+	for _, op := range ops {
+		assert.Equal(t, "http", op.Domain, op.Name)
+		assert.Equal(t, "1%", op.ErrorRate, op.Name)
+	}
+
+	original, err := synth.LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, rendered)
+}
+
+func TestFetchFlags(t *testing.T) {
+	t.Parallel()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		fmt.Fprint(w, validConfig)
+	}))
+	t.Cleanup(slow.Close)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, validConfig)
+	}))
+	t.Cleanup(fast.Close)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"validate honours --fetch-timeout", []string{"validate", "--fetch-timeout", "50ms", slow.URL}, "timed out after 50ms"},
+		{"run honours --fetch-timeout", []string{"run", "--stdout", "--fetch-timeout", "50ms", slow.URL}, "timed out after 50ms"},
+		{"validate honours --fetch-max-bytes", []string{"validate", "--fetch-max-bytes", "16", fast.URL}, "response body exceeds 16 bytes"},
+		{"run honours --fetch-max-bytes", []string{"run", "--stdout", "--fetch-max-bytes", "16", fast.URL}, "response body exceeds 16 bytes"},
+		{"rejects zero timeout", []string{"validate", "--fetch-timeout", "0s", fast.URL}, "--fetch-timeout must be positive"},
+		{"rejects negative max bytes", []string{"run", "--stdout", "--fetch-max-bytes", "-1", fast.URL}, "--fetch-max-bytes must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			root := rootCmd()
+			root.SetArgs(tt.args)
+			root.SetOut(io.Discard)
+			root.SetErr(io.Discard)
+
+			err := root.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("validate within limits", func(t *testing.T) {
+		t.Parallel()
+		root := rootCmd()
+		root.SetArgs([]string{"validate", "--fetch-timeout", "5s", "--fetch-max-bytes", "4096", fast.URL})
+		var out bytes.Buffer
+		root.SetOut(&out)
+
+		require.NoError(t, root.Execute())
+		assert.Contains(t, out.String(), "Configuration valid")
+	})
+}
+
 func TestVersionCommand(t *testing.T) {
 	t.Parallel()
 
@@ -191,6 +373,23 @@ func TestVersionCommand(t *testing.T) {
 
 	err := root.Execute()
 	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"), "default output is a single line")
+}
+
+func TestVersionCommandFull(t *testing.T) {
+	t.Parallel()
+
+	root := rootCmd()
+	root.SetArgs([]string{"version", "--full"})
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+
+	require.NoError(t, root.Execute())
+	assert.Contains(t, out.String(), "motel ")
+	assert.Contains(t, out.String(), "go: "+runtime.Version()+"\n")
+	assert.Contains(t, out.String(), "otel sdk: ")
+	assert.Contains(t, out.String(), fmt.Sprintf("config version: %d\n", synth.CurrentVersion))
 }
 
 func TestRunCommand(t *testing.T) {
@@ -344,6 +543,23 @@ func TestRunCommandNegativeSlowThreshold(t *testing.T) {
 	assert.Contains(t, err.Error(), "slow-threshold")
 }
 
+func TestRunCommandInvalidSampleRatio(t *testing.T) {
+	t.Parallel()
+
+	for _, ratio := range []string{"0", "-0.5", "1.5"} {
+		t.Run(ratio, func(t *testing.T) {
+			t.Parallel()
+			path := writeTestConfig(t, validConfig)
+			root := rootCmd()
+			root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--sample-ratio", ratio, path})
+
+			err := root.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "--sample-ratio must be greater than 0 and at most 1")
+		})
+	}
+}
+
 func TestRunCommandSlowThresholdWithoutLogs(t *testing.T) {
 	t.Parallel()
 
@@ -436,6 +652,73 @@ traffic:
 		require.NoError(t, err)
 	})
 
+	t.Run("run with multiple domains from custom semconv dir", func(t *testing.T) {
+		t.Parallel()
+		semconvDir := t.TempDir()
+		myappDir := filepath.Join(semconvDir, "myapp")
+		require.NoError(t, os.MkdirAll(myappDir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(myappDir, "registry.yaml"), []byte(`
+groups:
+  - id: registry.myapp
+    type: attribute_group
+    brief: 'My app attributes.'
+    attributes:
+      - id: myapp.request_id
+        type: string
+        brief: 'Request ID.'
+        examples: ["abc-123"]
+  - id: registry.tenant
+    type: attribute_group
+    brief: 'Tenant attributes.'
+    attributes:
+      - id: tenant.id
+        type: string
+        brief: 'Tenant ID.'
+        examples: ["acme"]
+`), 0o600))
+
+		cfg, err := synth.ParseConfig([]byte(`
+version: 1
+services:
+  svc:
+    operations:
+      op:
+        duration: 10ms
+        domains: [myapp, tenant]
+traffic:
+  rate: 10/s
+`))
+		require.NoError(t, err)
+		require.NoError(t, synth.ValidateConfig(cfg))
+		topo, err := buildTopology(cfg, semconvDir)
+		require.NoError(t, err)
+		traffic, err := synth.NewTrafficPattern(cfg.Traffic)
+		require.NoError(t, err)
+
+		exporter := tracetest.NewInMemoryExporter()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		engine := &synth.Engine{
+			Topology:  topo,
+			Traffic:   traffic,
+			Tracers:   func(name string) trace.Tracer { return tp.Tracer(name) },
+			Rng:       rand.New(rand.NewPCG(1, 2)), //nolint:gosec // deterministic seed for testing
+			Duration:  time.Second,
+			MaxTraces: 1,
+		}
+		t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+		_, err = engine.Run(context.Background())
+		require.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		keys := make(map[string]bool)
+		for _, attr := range spans[0].Attributes {
+			keys[string(attr.Key)] = true
+		}
+		assert.True(t, keys["myapp.request_id"], "attribute from first domain")
+		assert.True(t, keys["tenant.id"], "attribute from second domain")
+	})
+
 	t.Run("nonexistent semconv dir", func(t *testing.T) {
 		t.Parallel()
 		path := writeTestConfig(t, validConfig)
@@ -462,6 +745,121 @@ traffic:
 	})
 }
 
+func TestRunMultipleEndpoints(t *testing.T) {
+	t.Parallel()
+
+	newCollector := func(received *atomic.Int64) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/traces" {
+				received.Add(1)
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	var first, second atomic.Int64
+	srv1 := newCollector(&first)
+	srv2 := newCollector(&second)
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run",
+		"--endpoint", srv1.Listener.Addr().String(),
+		"--endpoint", srv2.Listener.Addr().String(),
+		"--duration", "100ms", path})
+
+	require.NoError(t, root.Execute())
+	assert.Positive(t, first.Load(), "first collector received no spans")
+	assert.Positive(t, second.Load(), "second collector received no spans")
+}
+
+type failingSpanExporter struct{}
+
+func (failingSpanExporter) ExportSpans(context.Context, []sdktrace.ReadOnlySpan) error {
+	return errors.New("collector unavailable")
+}
+
+func (failingSpanExporter) Shutdown(context.Context) error { return nil }
+
+func TestFanOutSpanExporterContinuesPastFailure(t *testing.T) {
+	t.Parallel()
+
+	healthy := tracetest.NewInMemoryExporter()
+	fan := &fanOutSpanExporter{exporters: []sdktrace.SpanExporter{failingSpanExporter{}, healthy}}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sdktrace.NewSimpleSpanProcessor(fan)))
+	_, span := tp.Tracer("test").Start(context.Background(), "op")
+	span.End()
+
+	require.Len(t, healthy.GetSpans(), 1)
+	err := fan.ExportSpans(context.Background(), healthy.GetSpans().Snapshots())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "collector unavailable")
+	assert.Len(t, healthy.GetSpans(), 2)
+	require.NoError(t, tp.Shutdown(context.Background()))
+}
+
+func TestCheckStyle(t *testing.T) {
+	t.Parallel()
+
+	results := []synth.CheckResult{
+		{Name: synth.CheckNameMaxDepth, Pass: true},
+		{Name: synth.CheckNameMaxFanOut, Pass: false},
+	}
+
+	plain := newCheckStyle(results, false)
+	assert.Equal(t, "PASS  max-depth:", plain.head(results[0]))
+	assert.Equal(t, "FAIL  max-fan-out:", plain.head(results[1]))
+
+	colored := newCheckStyle(results, true)
+	assert.Equal(t, ansiGreen+"PASS"+ansiReset+"  max-depth:  ", colored.head(results[0]), "names are padded to line up")
+	assert.Equal(t, ansiRed+"FAIL"+ansiReset+"  max-fan-out:", colored.head(results[1]))
+}
+
+func TestColorEnabled(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, colorEnabled(&bytes.Buffer{}, false), "a buffer is not a terminal")
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+	assert.False(t, colorEnabled(f, false), "a regular file is not a terminal")
+}
+
+func TestCheckEndpointMultiple(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	t.Run("one reachable endpoint is enough", func(t *testing.T) {
+		t.Parallel()
+		err := checkEndpoint(runOptions{
+			endpoints:   []string{"192.0.2.1:9999", ln.Addr().String()},
+			endpointSet: true,
+			protocol:    "http/protobuf",
+			protocolSet: true,
+		}, "test.yaml")
+		require.NoError(t, err)
+	})
+
+	t.Run("all unreachable", func(t *testing.T) {
+		t.Parallel()
+		err := checkEndpoint(runOptions{
+			endpoints:   []string{"192.0.2.1:9999", "192.0.2.2:9999"},
+			endpointSet: true,
+			protocol:    "http/protobuf",
+			protocolSet: true,
+		}, "test.yaml")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "192.0.2.1:9999, 192.0.2.2:9999")
+	})
+}
+
 func TestCheckEndpoint(t *testing.T) {
 	t.Parallel()
 
@@ -634,53 +1032,318 @@ func TestResolveOTLPConfig(t *testing.T) {
 	})
 }
 
-func TestDoctorCommandRedactsHeaders(t *testing.T) {
-	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "authorization=secret")
+func TestResolveOTLPConfigHeaderFlags(t *testing.T) {
+	t.Run("header flags add to headers", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-team=platform")
+
+		cfg, err := resolveOTLPConfig(runOptions{headerPairs: []string{"x-env=prod", "x-team=payments"}}, "traces")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"x-team": "payments", "x-env": "prod"}, cfg.headers)
+	})
+
+	t.Run("bearer replaces authorization header", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "authorization=old")
+
+		cfg, err := resolveOTLPConfig(runOptions{authBearer: "tok"}, "metrics")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"Authorization": "Bearer tok"}, cfg.headers)
+	})
+
+	t.Run("malformed header flag errors", func(t *testing.T) {
+		_, err := resolveOTLPConfig(runOptions{headerPairs: []string{"novalue"}}, "logs")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `--header "novalue" must be in key=value format`)
+	})
+}
+
+func TestRunSendsAuthHeaders(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	auth := map[string]string{}
+	tenant := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		auth[r.URL.Path] = r.Header.Get("Authorization")
+		tenant[r.URL.Path] = r.Header.Get("X-Tenant")
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	path := writeTestConfig(t, validConfig)
 	root := rootCmd()
-	root.SetArgs([]string{"doctor", "--endpoint", "192.0.2.1:9999"})
-	var out bytes.Buffer
-	root.SetOut(&out)
+	root.SetArgs([]string{"run",
+		"--endpoint", srv.Listener.Addr().String(),
+		"--otlp-auth-bearer", "s3cret",
+		"--header", "x-tenant=acme",
+		"--signals", "traces,metrics,logs",
+		"--slow-threshold", "1ms",
+		"--duration", "100ms", path})
+	require.NoError(t, root.Execute())
 
-	err := root.Execute()
-	require.Error(t, err)
-	assert.Contains(t, out.String(), "authorization=<redacted>")
-	assert.NotContains(t, out.String(), "secret")
+	mu.Lock()
+	defer mu.Unlock()
+	for _, signalPath := range []string{"/v1/traces", "/v1/metrics", "/v1/logs"} {
+		assert.Equal(t, "Bearer s3cret", auth[signalPath], signalPath)
+		assert.Equal(t, "acme", tenant[signalPath], signalPath)
+	}
 }
 
-func TestDoctorCommandReportsCanaryExportFailure(t *testing.T) {
-	// A listener that accepts TCP connections but immediately closes them passes
-	// the TCP reachability check yet fails the OTLP canary export.
-	ln, err := net.Listen("tcp", "127.0.0.1:0")
-	require.NoError(t, err)
-	defer ln.Close() //nolint:errcheck // best-effort close in test
-	go func() {
-		for {
-			conn, err := ln.Accept()
-			if err != nil {
-				return
-			}
-			_ = conn.Close()
-		}
-	}()
+func TestRunCommandScenarioMetrics(t *testing.T) {
+	t.Parallel()
 
+	var mu sync.Mutex
+	var metricsBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/v1/metrics" {
+			mu.Lock()
+			metricsBody = append(metricsBody, body...)
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	path := writeTestConfig(t, validConfig+`scenarios:
+  - name: outage
+    at: +0s
+    duration: 1m
+`)
 	root := rootCmd()
-	root.SetArgs([]string{"doctor", "--endpoint", ln.Addr().String(), "--insecure", "--timeout", "1s"})
-	var out bytes.Buffer
-	root.SetOut(&out)
+	root.SetArgs([]string{"run",
+		"--endpoint", srv.Listener.Addr().String(),
+		"--signals", "traces,metrics",
+		"--scenario-metrics",
+		"--duration", "100ms", path})
+	require.NoError(t, root.Execute())
 
-	err = root.Execute()
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, string(metricsBody), synth.ScenarioActivationsMetric)
+	assert.Contains(t, string(metricsBody), "outage")
+}
+
+func TestRunCommandScenarioMetricsRequiresMetrics(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--scenario-metrics", path})
+	err := root.Execute()
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "canary trace export failed")
-	assert.Contains(t, out.String(), "TCP check: ok")
-	assert.NotContains(t, out.String(), "Canary trace: sent")
+	assert.Contains(t, err.Error(), "--scenario-metrics requires metrics in --signals")
 }
 
-func TestCheckCommand(t *testing.T) {
+func TestRunCommandLogFormatJSON(t *testing.T) {
 	t.Parallel()
 
-	t.Run("passing checks", func(t *testing.T) {
-		t.Parallel()
-		path := writeTestConfig(t, validConfig)
+	var mu sync.Mutex
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/v1/logs" {
+			var req collogspb.ExportLogsServiceRequest
+			if err := proto.Unmarshal(body, &req); err == nil {
+				mu.Lock()
+				for _, rl := range req.GetResourceLogs() {
+					for _, sl := range rl.GetScopeLogs() {
+						for _, lr := range sl.GetLogRecords() {
+							bodies = append(bodies, lr.GetBody().GetStringValue())
+						}
+					}
+				}
+				mu.Unlock()
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	path := writeTestConfig(t, `
+version: 1
+services:
+  api:
+    operations:
+      get:
+        duration: 1ms
+        error_rate: 100%
+traffic:
+  rate: 100/s
+`)
+	root := rootCmd()
+	root.SetArgs([]string{"run",
+		"--endpoint", srv.Listener.Addr().String(),
+		"--signals", "traces,logs",
+		"--log-format", "json",
+		"--duration", "100ms", path})
+	require.NoError(t, root.Execute())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, bodies)
+	for _, b := range bodies {
+		var fields map[string]any
+		require.NoError(t, json.Unmarshal([]byte(b), &fields), b)
+		assert.Equal(t, "ERROR", fields["level"])
+		assert.Equal(t, "api", fields["service.name"])
+		assert.Len(t, fields["trace_id"], 32)
+		assert.Len(t, fields["span_id"], 16)
+	}
+}
+
+func TestRunCommandLogFormatInvalid(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--signals", "traces,logs", "--log-format", "xml", path})
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--log-format: log format must be plain, json, logfmt, or a Go template")
+}
+
+func TestTraceExporterTLS(t *testing.T) {
+	t.Parallel()
+
+	var received atomic.Int64
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caPath, caPEM, 0o600))
+
+	spans := tracetest.SpanStubs{{Name: "op"}}.Snapshots()
+	baseOpts := runOptions{
+		endpoint:      srv.Listener.Addr().String(),
+		endpointSet:   true,
+		protocol:      "http/protobuf",
+		protocolSet:   true,
+		exportTimeout: 2 * time.Second,
+		timeoutSet:    true,
+	}
+
+	t.Run("secure connection succeeds", func(t *testing.T) {
+		opts := baseOpts
+		opts.useTLS = true
+		opts.tlsCA = caPath
+		exporter, err := createTraceExporter(context.Background(), opts)
+		require.NoError(t, err)
+		defer exporter.Shutdown(context.Background()) //nolint:errcheck // best-effort shutdown in test
+
+		require.NoError(t, exporter.ExportSpans(context.Background(), spans))
+		assert.Equal(t, int64(1), received.Load())
+	})
+
+	t.Run("insecure connection fails", func(t *testing.T) {
+		exporter, err := createTraceExporter(context.Background(), baseOpts)
+		require.NoError(t, err)
+		defer exporter.Shutdown(context.Background()) //nolint:errcheck // best-effort shutdown in test
+
+		require.Error(t, exporter.ExportSpans(context.Background(), spans))
+	})
+}
+
+func TestResolveOTLPConfigTLS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("insecure by default", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := resolveOTLPConfig(runOptions{endpoint: "collector:4318", endpointSet: true}, "traces")
+		require.NoError(t, err)
+		assert.Nil(t, cfg.tlsConfig)
+	})
+
+	t.Run("tls flag builds config", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := resolveOTLPConfig(runOptions{endpoint: "collector:4318", endpointSet: true, useTLS: true}, "traces")
+		require.NoError(t, err)
+		require.NotNil(t, cfg.tlsConfig)
+		assert.False(t, cfg.insecure)
+	})
+
+	t.Run("tls and insecure conflict", func(t *testing.T) {
+		t.Parallel()
+		_, err := resolveOTLPConfig(runOptions{useTLS: true, insecure: true, insecureSet: true}, "traces")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--tls and --insecure cannot be used together")
+	})
+
+	t.Run("tls rejects http URL", func(t *testing.T) {
+		t.Parallel()
+		_, err := resolveOTLPConfig(runOptions{endpoint: "http://collector:4318", endpointSet: true, useTLS: true}, "traces")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires an https endpoint URL")
+	})
+
+	t.Run("client cert requires key", func(t *testing.T) {
+		t.Parallel()
+		_, err := resolveOTLPConfig(runOptions{tlsCert: "client.pem"}, "traces")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--tls-cert and --tls-key must be used together")
+	})
+
+	t.Run("CA file without certificates", func(t *testing.T) {
+		t.Parallel()
+		path := writeTestFile(t, "ca.pem", "not a certificate")
+		_, err := resolveOTLPConfig(runOptions{tlsCA: path}, "traces")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no PEM certificates found")
+	})
+}
+
+func TestDoctorCommandRedactsHeaders(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "authorization=secret")
+	root := rootCmd()
+	root.SetArgs([]string{"doctor", "--endpoint", "192.0.2.1:9999"})
+	var out bytes.Buffer
+	root.SetOut(&out)
+
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, out.String(), "authorization=<redacted>")
+	assert.NotContains(t, out.String(), "secret")
+}
+
+func TestDoctorCommandReportsCanaryExportFailure(t *testing.T) {
+	// A listener that accepts TCP connections but immediately closes them passes
+	// the TCP reachability check yet fails the OTLP canary export.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close() //nolint:errcheck // best-effort close in test
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	root := rootCmd()
+	root.SetArgs([]string{"doctor", "--endpoint", ln.Addr().String(), "--insecure", "--timeout", "1s"})
+	var out bytes.Buffer
+	root.SetOut(&out)
+
+	err = root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "canary trace export failed")
+	assert.Contains(t, out.String(), "TCP check: ok")
+	assert.NotContains(t, out.String(), "Canary trace: sent")
+}
+
+func TestCheckCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("passing checks", func(t *testing.T) {
+		t.Parallel()
+		path := writeTestConfig(t, validConfig)
 		root := rootCmd()
 		root.SetArgs([]string{"check", path})
 		var out bytes.Buffer
@@ -693,6 +1356,7 @@ func TestCheckCommand(t *testing.T) {
 		assert.Contains(t, out.String(), "PASS  max-spans:")
 		assert.Contains(t, out.String(), "path:")
 		assert.Contains(t, out.String(), "worst:")
+		assert.NotContains(t, out.String(), "max-latency", "the latency check is skipped without --max-latency")
 	})
 
 	t.Run("failing depth limit", func(t *testing.T) {
@@ -709,6 +1373,20 @@ func TestCheckCommand(t *testing.T) {
 		assert.Contains(t, out.String(), "FAIL  max-depth:")
 	})
 
+	t.Run("no color", func(t *testing.T) {
+		t.Parallel()
+		path := writeTestConfig(t, validConfig)
+		root := rootCmd()
+		root.SetArgs([]string{"check", "--no-color", "--max-depth", "0", path})
+		var out bytes.Buffer
+		root.SetOut(&out)
+
+		require.Error(t, root.Execute())
+		assert.Contains(t, out.String(), "FAIL  max-depth:")
+		assert.Contains(t, out.String(), "PASS  max-fan-out:")
+		assert.NotContains(t, out.String(), "\x1b[", "no escape sequences")
+	})
+
 	t.Run("failing fan-out limit", func(t *testing.T) {
 		t.Parallel()
 		cfg := `
@@ -752,6 +1430,70 @@ traffic:
 		assert.Contains(t, out.String(), "FAIL  max-spans:")
 	})
 
+	t.Run("latency limit", func(t *testing.T) {
+		t.Parallel()
+		cfg := `
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 10ms
+        calls: [backend.op]
+  backend:
+    operations:
+      op:
+        duration: 40ms
+traffic:
+  rate: 10/s
+`
+		path := writeTestConfig(t, cfg)
+
+		root := rootCmd()
+		root.SetArgs([]string{"check", "--samples", "0", "--max-latency", "100ms", path})
+		var out bytes.Buffer
+		root.SetOut(&out)
+		require.NoError(t, root.Execute())
+		assert.Contains(t, out.String(), "PASS  max-latency: 50ms (limit: 100ms)")
+		assert.Contains(t, out.String(), "path: gateway.request \u2192 backend.op")
+
+		root = rootCmd()
+		root.SetArgs([]string{"check", "--samples", "0", "--max-latency", "45ms", path})
+		out.Reset()
+		root.SetOut(&out)
+		require.Error(t, root.Execute())
+		assert.Contains(t, out.String(), "FAIL  max-latency: 50ms (limit: 45ms)")
+	})
+
+	t.Run("cardinality limit", func(t *testing.T) {
+		t.Parallel()
+		cfg := `
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 10ms
+        metrics:
+          - name: requests.by.session
+            type: counter
+            attributes:
+              session.id:
+                sequence: "session-{n}"
+traffic:
+  rate: 10/s
+`
+		path := writeTestConfig(t, cfg)
+
+		root := rootCmd()
+		root.SetArgs([]string{"check", "--samples", "0", "--max-cardinality", "100", path})
+		var out bytes.Buffer
+		root.SetOut(&out)
+		require.Error(t, root.Execute())
+		assert.Contains(t, out.String(), "FAIL  max-cardinality: unbounded (limit: 100)")
+		assert.Contains(t, out.String(), "worst: gateway.request metric requests.by.session attribute session.id")
+	})
+
 	t.Run("static only with samples 0", func(t *testing.T) {
 		t.Parallel()
 		path := writeTestConfig(t, validConfig)
@@ -1150,31 +1892,12 @@ func TestRunStdoutImportRoundTrip(t *testing.T) {
 	// into one service named after the Go module path.
 	topoPath := writeTestConfig(t, validConfig)
 
-	origStdout := os.Stdout
-	r, w, err := os.Pipe()
-	require.NoError(t, err)
-	os.Stdout = w
-
-	var traces bytes.Buffer
-	done := make(chan struct{})
-	go func() {
-		defer close(done)
-		_, _ = traces.ReadFrom(r)
-	}()
-
-	runCmd := rootCmd()
-	runCmd.SetArgs([]string{"run", "--stdout", "--duration", "100ms", topoPath})
-	runErr := runCmd.Execute()
-
-	w.Close()
-	os.Stdout = origStdout
-	<-done
-	require.NoError(t, runErr)
-	require.NotEmpty(t, traces.Bytes(), "run --stdout produced no trace output")
+	traces := captureStdout(t, "run", "--stdout", "--duration", "100ms", topoPath)
+	require.NotEmpty(t, traces, "run --stdout produced no trace output")
 
 	dir := t.TempDir()
 	tracesPath := filepath.Join(dir, "traces.jsonl")
-	require.NoError(t, os.WriteFile(tracesPath, traces.Bytes(), 0o600))
+	require.NoError(t, os.WriteFile(tracesPath, traces, 0o600))
 
 	importCmd := rootCmd()
 	importCmd.SetArgs([]string{"import", tracesPath})
@@ -1195,6 +1918,156 @@ func TestRunStdoutImportRoundTrip(t *testing.T) {
 	require.NoError(t, validateCmd.Execute())
 }
 
+func TestRunDeterministicIDs(t *testing.T) {
+	// Not parallel: swaps os.Stdout, which the stdouttrace exporter writes to.
+	topoPath := writeTestConfig(t, validConfig)
+
+	traceIDs := func() []string {
+		traces := captureStdout(t, "run", "--stdout", "--duration", "100ms", "--seed", "42", "--deterministic-ids", topoPath)
+
+		var ids []string
+		seen := make(map[string]bool)
+		dec := json.NewDecoder(bytes.NewReader(traces))
+		for dec.More() {
+			var span struct {
+				SpanContext struct{ TraceID string }
+			}
+			require.NoError(t, dec.Decode(&span))
+			if id := span.SpanContext.TraceID; !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+
+	first, second := traceIDs(), traceIDs()
+	// Wall-clock pacing decides how many traces fit in the run, so compare
+	// the traces both runs emitted.
+	n := min(len(first), len(second))
+	require.Positive(t, n)
+	assert.Equal(t, first[:n], second[:n])
+}
+
+func TestRunDeterministicIDsRequiresSeed(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--deterministic-ids", path})
+
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--deterministic-ids requires a non-zero --seed")
+}
+
+func TestRunServiceSelection(t *testing.T) {
+	// Not parallel: swaps os.Stdout, which the stdouttrace exporter writes to.
+	topoPath := writeTestConfig(t, validConfig)
+
+	traces := captureStdout(t, "run", "--stdout", "--duration", "100ms", "--service", "gateway", topoPath)
+
+	services := make(map[string]int)
+	dec := json.NewDecoder(bytes.NewReader(traces))
+	for dec.More() {
+		var span struct {
+			Name     string
+			Resource []struct {
+				Key   string
+				Value struct{ Value any }
+			}
+		}
+		require.NoError(t, dec.Decode(&span))
+		for _, kv := range span.Resource {
+			if kv.Key == "service.name" {
+				services[fmt.Sprint(kv.Value.Value)]++
+			}
+		}
+	}
+	require.NotZero(t, services["gateway"], "selected service emits spans")
+	assert.Len(t, services, 1, "only the selected service emits spans")
+}
+
+func TestRunServiceSelectionUnknownService(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--service", "nope", path})
+
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `--service: unknown service "nope" (available: backend, gateway)`)
+}
+
+func TestRunRootSingleTrace(t *testing.T) {
+	// Not parallel: swaps os.Stdout, which the stdouttrace exporter writes to.
+	topoPath := writeTestConfig(t, `
+version: 1
+services:
+  web:
+    operations:
+      home:
+        duration: 5ms
+  gateway:
+    operations:
+      checkout:
+        duration: 10ms
+        calls:
+          - payments.charge
+  payments:
+    operations:
+      charge:
+        duration: 5ms
+traffic:
+  rate: 100/s
+`)
+
+	traces := captureStdout(t, "run", "--stdout", "--duration", "10s", "--root", "gateway.checkout", "--max-traces", "1", topoPath)
+
+	traceIDs := make(map[string]bool)
+	var roots []string
+	dec := json.NewDecoder(bytes.NewReader(traces))
+	for dec.More() {
+		var span struct {
+			Name        string
+			SpanContext struct{ TraceID string }
+			Parent      struct{ SpanID string }
+		}
+		require.NoError(t, dec.Decode(&span))
+		traceIDs[span.SpanContext.TraceID] = true
+		if span.Parent.SpanID == "0000000000000000" {
+			roots = append(roots, span.Name)
+		}
+	}
+	assert.Len(t, traceIDs, 1, "exactly one trace")
+	assert.Equal(t, []string{"checkout"}, roots)
+}
+
+func TestRunRootMustBeARoot(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--root", "backend.list", path})
+
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `--root: "backend.list" is not a root operation (roots: gateway.GET /users)`)
+}
+
+func TestRunUntilScenariosCompleteRequiresScenarios(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--until-scenarios-complete", path})
+
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--until-scenarios-complete requires scenarios in the topology")
+}
+
 // mockShutdownable records shutdown calls and executes a configurable function.
 type mockShutdownable struct {
 	shutdownFunc func(context.Context) error
@@ -1322,17 +2195,229 @@ func TestShutdownAllRespectsContext(t *testing.T) {
 	assert.Contains(t, buf.String(), "context deadline exceeded")
 }
 
-func TestRunCommandTimeOffset(t *testing.T) {
-	t.Parallel()
+func TestShutdownAllBoundedBySlowItem(t *testing.T) {
+	// Not parallel: swaps os.Stderr which is a global.
 
-	path := writeTestConfig(t, validConfig)
-	root := rootCmd()
+	release := make(chan struct{})
+	defer close(release)
+	items := []*mockShutdownable{
+		{},
+		{shutdownFunc: func(context.Context) error {
+			// Ignores ctx, like a provider stuck on a blocking export.
+			<-release
+			return nil
+		}},
+	}
+
+	opts := runOptions{shutdownTimeout: 100 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), opts.drainTimeout())
+	defer cancel()
+
+	origStderr := os.Stderr
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stderr = w
+
+	start := time.Now()
+	shutdownAll(ctx, items, "test")
+	elapsed := time.Since(start)
+
+	w.Close()
+	os.Stderr = origStderr
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+
+	assert.GreaterOrEqual(t, elapsed, 100*time.Millisecond)
+	assert.Less(t, elapsed, time.Second,
+		"expected shutdownAll to return at the shutdown timeout, got %v", elapsed)
+	assert.Contains(t, buf.String(), "error shutting down test: context deadline exceeded (1 of 2 still running)")
+}
+
+func TestDrainTimeoutDefault(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, defaultShutdownTimeout, runOptions{}.drainTimeout())
+	assert.Equal(t, time.Minute, runOptions{shutdownTimeout: time.Minute}.drainTimeout())
+}
+
+func TestRunCommandTimeOffset(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
 	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--time-offset", "-1h", path})
 
 	err := root.Execute()
 	require.NoError(t, err)
 }
 
+func TestRunCommandStartTime(t *testing.T) {
+	t.Parallel()
+
+	sink := pipelinetest.NewSink()
+	t.Cleanup(sink.Close)
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run",
+		"--endpoint", strings.TrimPrefix(sink.URL(), "http://"),
+		"--start-time", start.Format(time.RFC3339),
+		"--duration", "100ms", path})
+	require.NoError(t, root.Execute())
+
+	spans := sink.Spans()
+	require.NotEmpty(t, spans)
+	earliest := time.Unix(0, int64(spans[0].GetStartTimeUnixNano())) //nolint:gosec // span timestamps fit in int64
+	for _, s := range spans {
+		st := time.Unix(0, int64(s.GetStartTimeUnixNano())) //nolint:gosec // span timestamps fit in int64
+		if st.Before(earliest) {
+			earliest = st
+		}
+		assert.WithinDuration(t, start, st, time.Minute)
+	}
+	assert.WithinDuration(t, start, earliest, 5*time.Second, "the first span starts at --start-time")
+}
+
+func TestRunCommandStartTimeErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--start-time", "yesterday"}, "--start-time must be an RFC3339 timestamp"},
+		{[]string{"--start-time", "2024-05-01T00:00:00Z", "--time-offset", "-1h"}, "--start-time and --time-offset cannot be used together"},
+		{[]string{"--start-time", "2024-05-01T00:00:00Z", "--realtime"}, "--realtime and --start-time cannot be used together"},
+	} {
+		path := writeTestConfig(t, validConfig)
+		root := rootCmd()
+		root.SetArgs(append(append([]string{"run", "--stdout", "--duration", "100ms"}, tc.args...), path))
+		err := root.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tc.want)
+	}
+}
+
+func TestRunCommandBackfill(t *testing.T) {
+	t.Parallel()
+
+	sink := pipelinetest.NewSink()
+	t.Cleanup(sink.Close)
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run",
+		"--endpoint", strings.TrimPrefix(sink.URL(), "http://"),
+		"--backfill", "10s", path})
+	begin := time.Now()
+	require.NoError(t, root.Execute())
+
+	spans := sink.Spans()
+	require.NotEmpty(t, spans)
+	earliest, latest := time.Unix(0, 0), time.Unix(0, 0)
+	for i, s := range spans {
+		st := time.Unix(0, int64(s.GetStartTimeUnixNano())) //nolint:gosec // span timestamps fit in int64
+		if i == 0 || st.Before(earliest) {
+			earliest = st
+		}
+		if i == 0 || st.After(latest) {
+			latest = st
+		}
+	}
+	assert.WithinDuration(t, begin.Add(-10*time.Second), earliest, 2*time.Second, "the window ends now")
+	assert.Greater(t, latest.Sub(earliest), 8*time.Second, "timestamps spread across the whole window")
+}
+
+func TestRunCommandBackfillExportsEverySpan(t *testing.T) {
+	t.Parallel()
+
+	sink := pipelinetest.NewSink()
+	t.Cleanup(sink.Close)
+
+	// A minute at 100/s is far more spans than the batch processor queues
+	// by default, and backfill produces them with no pause.
+	path := writeTestConfig(t, validConfig)
+	statsPath := filepath.Join(t.TempDir(), "stats.json")
+	root := rootCmd()
+	root.SetArgs([]string{"run",
+		"--endpoint", strings.TrimPrefix(sink.URL(), "http://"),
+		"--backfill", "1m", "--stats-file", statsPath, path})
+	require.NoError(t, root.Execute())
+
+	data, err := os.ReadFile(statsPath)
+	require.NoError(t, err)
+	var stats map[string]any
+	require.NoError(t, json.Unmarshal(data, &stats))
+	spans := int(stats["spans"].(float64))
+	require.Greater(t, spans, 2048)
+	assert.Equal(t, spans, sink.Count(), "backfill waits for the exporter instead of dropping spans")
+}
+
+func TestRunCommandBackfillErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--backfill", "-1h"}, "--backfill must be positive"},
+		{[]string{"--backfill", "1h", "--realtime"}, "--realtime and --backfill cannot be used together"},
+		{[]string{"--backfill", "1h", "--duration", "1m"}, "--duration cannot be used with it"},
+		{[]string{"--backfill", "1h", "--signals", "traces,metrics"}, "--backfill does not support metrics"},
+	} {
+		path := writeTestConfig(t, validConfig)
+		root := rootCmd()
+		root.SetArgs(append(append([]string{"run", "--stdout"}, tc.args...), path))
+		err := root.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tc.want)
+	}
+}
+
+func TestRunCommandRate(t *testing.T) {
+	t.Parallel()
+
+	traces := func(args ...string) float64 {
+		path := writeTestConfig(t, validConfig)
+		statsPath := filepath.Join(t.TempDir(), "stats.json")
+		root := rootCmd()
+		root.SetArgs(append(append([]string{"run", "--stdout", "--backfill", "10s", "--stats-file", statsPath}, args...), path))
+		root.SetOut(io.Discard)
+		require.NoError(t, root.Execute())
+
+		data, err := os.ReadFile(statsPath)
+		require.NoError(t, err)
+		var stats map[string]any
+		require.NoError(t, json.Unmarshal(data, &stats))
+		return stats["traces"].(float64)
+	}
+
+	base := traces()
+	scaled := traces("--rate", "10/s")
+	assert.InDelta(t, 1000, base, 200, "the topology's 100/s for 10s")
+	assert.InDelta(t, 100, scaled, 40, "--rate 10/s for 10s")
+}
+
+func TestRunCommandRateErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		rate string
+		want string
+	}{
+		{"fast", "--rate: "},
+		{"0/s", "--rate: "},
+	} {
+		path := writeTestConfig(t, validConfig)
+		root := rootCmd()
+		root.SetArgs([]string{"run", "--stdout", "--rate", tc.rate, path})
+		err := root.Execute()
+		require.Error(t, err, tc.rate)
+		assert.Contains(t, err.Error(), tc.want)
+	}
+}
+
 func TestRunCommandRealtime(t *testing.T) {
 	t.Parallel()
 
@@ -1356,6 +2441,194 @@ func TestRunCommandRealtimeWithTimeOffset(t *testing.T) {
 	assert.Contains(t, err.Error(), "--realtime and --time-offset cannot be used together")
 }
 
+func TestRunCommandInvalidSemconvFill(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--semconv-fill", "some", path})
+
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `--semconv-fill must be all or required, got "some"`)
+}
+
+func TestRunCommandInvalidWarmup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		warmup  string
+		wantErr string
+	}{
+		{"negative", "-1s", "--warmup must not be negative, got -1s"},
+		{"not shorter than duration", "100ms", "--warmup 100ms must be shorter than --duration 100ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := writeTestConfig(t, validConfig)
+			root := rootCmd()
+			root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--warmup", tt.warmup, path})
+
+			err := root.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestRunCommandNegativeMaxTraceDuration(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--max-trace-duration", "-1s", path})
+
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--max-trace-duration must not be negative, got -1s")
+}
+
+func TestRunCommandNonPositiveShutdownTimeout(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--shutdown-timeout", "0s", path})
+
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--shutdown-timeout must be positive, got 0s")
+}
+
+func TestRunSpanLimits(t *testing.T) {
+	// Not parallel: swaps os.Stdout, which the stdouttrace exporter writes to.
+	topoPath := writeTestConfig(t, `
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 10ms
+        attributes:
+          a.first:
+            value: "abcdefgh"
+          b.second:
+            value: "ijklmnop"
+          c.third:
+            value: "qrstuvwx"
+traffic:
+  rate: 20/s
+`)
+
+	traces := captureStdout(t, "run", "--stdout", "--duration", "200ms", "--max-attributes", "2", "--max-attribute-length", "3", topoPath)
+
+	dec := json.NewDecoder(bytes.NewReader(traces))
+	spans := 0
+	for dec.More() {
+		var span struct {
+			Attributes []struct {
+				Key   string
+				Value struct{ Value any }
+			}
+			DroppedAttributes int
+		}
+		require.NoError(t, dec.Decode(&span))
+		spans++
+		assert.LessOrEqual(t, len(span.Attributes), 2)
+		assert.Positive(t, span.DroppedAttributes, "attributes beyond the limit are dropped and counted")
+		for _, attr := range span.Attributes {
+			if v, ok := attr.Value.Value.(string); ok {
+				assert.LessOrEqual(t, len(v), 3, "attribute %s is truncated", attr.Key)
+			}
+		}
+	}
+	assert.Positive(t, spans)
+}
+
+func TestSpanLimits(t *testing.T) {
+	t.Parallel()
+
+	_, ok := spanLimits(runOptions{})
+	assert.False(t, ok, "no flags leaves the SDK limits alone")
+
+	limits, ok := spanLimits(runOptions{maxAttrLength: 16})
+	require.True(t, ok)
+	assert.Equal(t, 16, limits.AttributeValueLengthLimit)
+	assert.Equal(t, sdktrace.NewSpanLimits().AttributeCountLimit, limits.AttributeCountLimit)
+}
+
+func TestRunCommandNegativeMaxAttributes(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--max-attributes", "-1", path})
+
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--max-attributes must not be negative, got -1")
+}
+
+func TestRunStatsFile(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig+`scenarios:
+  - name: slow backend
+    at: "0s"
+    duration: 1m
+    override:
+      backend.list:
+        duration: 50ms
+`)
+	statsPath := filepath.Join(t.TempDir(), "stats.json")
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--stats-file", statsPath, "--stats-format", "pretty", path})
+	require.NoError(t, root.Execute())
+
+	data, err := os.ReadFile(statsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n  \"traces\"", "pretty output is indented")
+
+	var stats map[string]any
+	require.NoError(t, json.Unmarshal(data, &stats))
+	for _, key := range []string{"traces", "spans", "errors"} {
+		assert.Contains(t, stats, key)
+	}
+	assert.Equal(t, stats["traces"], stats["scenario_traces"].(map[string]any)["slow backend"],
+		"the scenario is active for the whole run")
+}
+
+func TestRunPerOperationStats(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	statsPath := filepath.Join(t.TempDir(), "stats.json")
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--stats-file", statsPath, "--per-operation-stats", path})
+	require.NoError(t, root.Execute())
+
+	data, err := os.ReadFile(statsPath)
+	require.NoError(t, err)
+	var stats synth.Stats
+	require.NoError(t, json.Unmarshal(data, &stats))
+	require.Contains(t, stats.ByOperation, "backend.list")
+	assert.Equal(t, stats.Traces, stats.ByOperation["backend.list"].Count)
+}
+
+func TestRunCommandInvalidStatsFormat(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--stats-format", "yaml", path})
+
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `--stats-format must be compact or pretty, got "yaml"`)
+}
+
 func TestEmitCommand(t *testing.T) {
 	t.Parallel()
 
@@ -1631,6 +2904,107 @@ traffic:
 	})
 }
 
+func TestValidateCommandSpanCountWarning(t *testing.T) {
+	t.Parallel()
+
+	cfg := `
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 10ms
+        calls:
+          - target: backend.fetch
+            count: 40
+  backend:
+    operations:
+      fetch:
+        duration: 5ms
+        calls:
+          - target: db.query
+            count: 30
+  db:
+    operations:
+      query:
+        duration: 1ms
+traffic:
+  rate: 10/s
+`
+	path := writeTestConfig(t, cfg)
+
+	t.Run("warns above the default threshold", func(t *testing.T) {
+		t.Parallel()
+		root := rootCmd()
+		root.SetArgs([]string{"validate", path})
+		var out, errOut bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&errOut)
+
+		require.NoError(t, root.Execute())
+		assert.Contains(t, out.String(), "Configuration valid", "the warning must not fail validation")
+		assert.Contains(t, errOut.String(), "warning: a trace from gateway.request can reach 1241 spans, more than --warn-spans 1000")
+		assert.Contains(t, errOut.String(), "--max-spans-per-trace")
+	})
+
+	t.Run("threshold is configurable", func(t *testing.T) {
+		t.Parallel()
+		root := rootCmd()
+		root.SetArgs([]string{"validate", "--warn-spans", "2000", path})
+		var out, errOut bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&errOut)
+
+		require.NoError(t, root.Execute())
+		assert.NotContains(t, errOut.String(), "warning:")
+	})
+
+	t.Run("zero disables the warning", func(t *testing.T) {
+		t.Parallel()
+		root := rootCmd()
+		root.SetArgs([]string{"validate", "--warn-spans", "0", path})
+		var out, errOut bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&errOut)
+
+		require.NoError(t, root.Execute())
+		assert.NotContains(t, errOut.String(), "warning:")
+	})
+}
+
+func TestValidateCommandScenarioWarnings(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig+`scenarios:
+  - name: slow backend
+    at: +1m
+    duration: 5m
+    override:
+      backend.list:
+        duration: 500ms
+  - name: backend outage
+    at: +2m
+    duration: 1m
+    priority: 1
+    override:
+      backend.list:
+        duration: 5s
+  - name: recovery
+    at: +10m
+    duration: 1m
+`)
+	root := rootCmd()
+	root.SetArgs([]string{"validate", path})
+	var out, errOut bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&errOut)
+
+	require.NoError(t, root.Execute())
+	assert.Contains(t, out.String(), "Configuration valid", "warnings must not fail validation")
+	assert.Contains(t, errOut.String(), `warning: scenarios "slow backend" and "backend outage" overlap from +2m0s to +3m0s and set different duration for backend.list`)
+	assert.Contains(t, errOut.String(), "warning: no scenario is active from +6m0s to +10m0s")
+}
+
 func TestValidateCommandSemconvUnsetUnitWarning(t *testing.T) {
 	t.Parallel()
 	cfg := `
@@ -1762,6 +3136,174 @@ func TestSemconvLogWarnings_ValidEnumValue(t *testing.T) {
 	assert.Empty(t, semconvLogWarnings(cfg, reg))
 }
 
+func TestSemconvAttributeWarnings(t *testing.T) {
+	t.Parallel()
+	reg, err := semconv.Load(fstest.MapFS{
+		"http/registry.yaml": &fstest.MapFile{
+			Data: []byte(`
+groups:
+  - id: registry.http
+    type: attribute_group
+    brief: 'HTTP attributes.'
+    attributes:
+      - id: http.response.status_code
+        type: int
+        brief: 'Status code.'
+      - id: http.route
+        type: string
+        brief: 'Route.'
+`),
+		},
+	})
+	require.NoError(t, err)
+
+	cfg := &synth.Config{
+		Services: []synth.ServiceConfig{{
+			Name: "api",
+			Operations: []synth.OperationConfig{{
+				Name:   "get",
+				Domain: "http",
+				Attributes: map[string]synth.AttributeValueConfig{
+					"http.response.status_code": {Values: map[any]int{"200": 9, "500": 1}},
+					"http.route":                {Value: "/users"},
+					"http.routee":               {Value: "/typo"},
+					"app.tenant":                {Value: "acme"},
+				},
+			}},
+		}},
+	}
+
+	t.Run("type mismatch and unknown key warn", func(t *testing.T) {
+		t.Parallel()
+		warnings := semconvAttributeWarnings(cfg, reg)
+		require.Len(t, warnings, 2)
+		assert.Contains(t, warnings[0], `service "api" operation "get": attribute "http.response.status_code": generates string values; semantic convention type is int`)
+		assert.Contains(t, warnings[1], `attribute "http.routee" is not defined by semantic convention domain "http"`)
+	})
+
+	t.Run("matching types do not warn", func(t *testing.T) {
+		t.Parallel()
+		ok := &synth.Config{
+			Services: []synth.ServiceConfig{{
+				Name: "api",
+				Operations: []synth.OperationConfig{{
+					Name:   "get",
+					Domain: "http",
+					Attributes: map[string]synth.AttributeValueConfig{
+						"http.response.status_code": {Values: map[any]int{200: 9, 500: 1}},
+						"http.route":                {Regex: "/users/[0-9]+"},
+					},
+				}},
+			}},
+		}
+		assert.Empty(t, semconvAttributeWarnings(ok, reg))
+	})
+}
+
+func TestDomainResolverSemconvFill(t *testing.T) {
+	t.Parallel()
+	reg, err := semconv.Load(fstest.MapFS{
+		"myapp/spans.yaml": &fstest.MapFile{
+			Data: []byte(`
+groups:
+  - id: span.myapp.server
+    type: span
+    brief: 'My app server span.'
+    attributes:
+      - id: myapp.tenant
+        type: string
+        brief: 'Tenant.'
+        examples: ["acme"]
+        requirement_level: required
+      - id: myapp.request_id
+        type: string
+        brief: 'Request ID.'
+        examples: ["abc-123"]
+        requirement_level: required
+      - id: myapp.debug
+        type: boolean
+        brief: 'Debug flag.'
+        requirement_level: opt_in
+`),
+		},
+	})
+	require.NoError(t, err)
+
+	cfg := &synth.Config{
+		Services: []synth.ServiceConfig{{
+			Name: "svc",
+			Operations: []synth.OperationConfig{{
+				Name:     "op",
+				Domain:   "span.myapp.server",
+				Duration: "10ms",
+				Attributes: map[string]synth.AttributeValueConfig{
+					"myapp.request_id": {Value: "fixed"},
+				},
+			}},
+		}},
+		Traffic: synth.TrafficConfig{Rate: "10/s"},
+	}
+
+	t.Run("required fills only required attributes", func(t *testing.T) {
+		t.Parallel()
+		topo, err := synth.BuildTopology(cfg, domainResolver(reg, semconvFillRequired))
+		require.NoError(t, err)
+		attrs := topo.Services["svc"].Operations["op"].Attributes
+		assert.NotNil(t, attrs.Get("myapp.tenant"), "missing required attribute is added")
+		assert.Equal(t, "fixed", attrs.Get("myapp.request_id").Generate(nil), "user value wins")
+		assert.Nil(t, attrs.Get("myapp.debug"), "optional attribute is not added")
+	})
+
+	t.Run("all fills every attribute", func(t *testing.T) {
+		t.Parallel()
+		topo, err := synth.BuildTopology(cfg, domainResolver(reg, semconvFillAll))
+		require.NoError(t, err)
+		attrs := topo.Services["svc"].Operations["op"].Attributes
+		assert.NotNil(t, attrs.Get("myapp.debug"))
+	})
+}
+
+func TestValidateStrictSemconv(t *testing.T) {
+	t.Parallel()
+	path := writeTestConfig(t, `
+version: 1
+services:
+  api:
+    operations:
+      get:
+        duration: 10ms
+        domain: http
+        attributes:
+          http.response.status_code:
+            value: "200"
+traffic:
+  rate: 10/s
+`)
+
+	t.Run("warning by default", func(t *testing.T) {
+		t.Parallel()
+		var stderr bytes.Buffer
+		root := rootCmd()
+		root.SetOut(&bytes.Buffer{})
+		root.SetErr(&stderr)
+		root.SetArgs([]string{"validate", path})
+		require.NoError(t, root.Execute())
+		assert.Contains(t, stderr.String(), `warning: service "api" operation "get": attribute "http.response.status_code"`)
+	})
+
+	t.Run("error with --strict-semconv", func(t *testing.T) {
+		t.Parallel()
+		root := rootCmd()
+		root.SetOut(&bytes.Buffer{})
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs([]string{"validate", "--strict-semconv", path})
+		err := root.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--strict-semconv")
+		assert.Contains(t, err.Error(), "http.response.status_code")
+	})
+}
+
 func TestNewRunRng(t *testing.T) {
 	t.Parallel()
 
@@ -1781,3 +3323,95 @@ func TestNewRunRng(t *testing.T) {
 		assert.NotEqual(t, a.Uint64(), b.Uint64())
 	})
 }
+
+func TestNewBaseResource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("detection off", func(t *testing.T) {
+		t.Parallel()
+		res, err := newBaseResource(t.Context(), false)
+		require.NoError(t, err)
+		_, ok := res.Set().Value("host.name")
+		assert.False(t, ok, "host.name is only added when detection is on")
+		v, ok := res.Set().Value("motel.version")
+		require.True(t, ok)
+		assert.Equal(t, version, v.AsString())
+	})
+
+	t.Run("detection on", func(t *testing.T) {
+		t.Parallel()
+		res, err := newBaseResource(t.Context(), true)
+		require.NoError(t, err)
+		host, ok := res.Set().Value("host.name")
+		require.True(t, ok, "host.name is detected")
+		assert.NotEmpty(t, host.AsString())
+		_, ok = res.Set().Value("os.type")
+		assert.True(t, ok, "os.type is detected")
+		v, ok := res.Set().Value("motel.version")
+		require.True(t, ok)
+		assert.Equal(t, version, v.AsString())
+	})
+}
+
+// setStdin replaces os.Stdin with a file holding data for the rest of t.
+func setStdin(t *testing.T, data string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	f, err := os.Open(path) //nolint:gosec // test-controlled path
+	require.NoError(t, err)
+	orig := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = orig
+		_ = f.Close()
+	})
+}
+
+func TestRunFromStdin(t *testing.T) {
+	// Not parallel: swaps os.Stdin, which is a global.
+	setStdin(t, validConfig)
+
+	statsPath := filepath.Join(t.TempDir(), "stats.json")
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--stats-file", statsPath, "-"})
+	require.NoError(t, root.Execute())
+
+	data, err := os.ReadFile(statsPath)
+	require.NoError(t, err)
+	var stats map[string]any
+	require.NoError(t, json.Unmarshal(data, &stats))
+	assert.Positive(t, stats["traces"], "the topology piped to stdin runs")
+}
+
+func TestValidateFromStdin(t *testing.T) {
+	// Not parallel: swaps os.Stdin, which is a global.
+	t.Run("valid", func(t *testing.T) {
+		setStdin(t, validConfig)
+		root := rootCmd()
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetArgs([]string{"validate", "-"})
+		require.NoError(t, root.Execute())
+		assert.Contains(t, out.String(), "Configuration valid")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		setStdin(t, "\n")
+		root := rootCmd()
+		root.SetArgs([]string{"validate", "-"})
+		err := root.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "stdin is empty")
+	})
+}
+
+func TestRunWatchRejectsStdin(t *testing.T) {
+	t.Parallel()
+
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--watch", "-"})
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--watch requires a local topology file, not stdin")
+}
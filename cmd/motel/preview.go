@@ -6,6 +6,8 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,16 +16,16 @@ import (
 )
 
 func previewCmd() *cobra.Command {
-	var (
-		duration time.Duration
-		output   string
-	)
+	var opts previewOptions
 
 	cmd := &cobra.Command{
 		Use:   "preview <topology.yaml | URL>",
 		Short: "Render the traffic rate over time as an SVG chart",
 		Long: "Render the traffic rate over time as an SVG chart.\n\n" +
-			"The topology source can be a local file path or an HTTP/HTTPS URL.\n" +
+			"With --samples, instead sample that many traces and print text histograms\n" +
+			"of spans per trace and trace depth, with their percentiles.\n\n" +
+			"The topology source can be a local file path, an HTTP/HTTPS URL, or - to\n" +
+			"read it from stdin.\n" +
 			"URL fetches have a 10-second timeout and a 10 MB response body limit.",
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
@@ -32,17 +34,35 @@ func previewCmd() *cobra.Command {
 			return cobra.ExactArgs(1)(cmd, args)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runPreview(cmd, args[0], duration, output)
+			if opts.samples < 0 {
+				return fmt.Errorf("--samples must not be negative")
+			}
+			if opts.root != "" && opts.samples == 0 {
+				return fmt.Errorf("--root requires --samples")
+			}
+			return runPreview(cmd, args[0], opts)
 		},
 	}
 
-	cmd.Flags().DurationVar(&duration, "duration", 0, "preview duration (default: inferred from topology)")
-	cmd.Flags().StringVarP(&output, "output", "o", "", "output file path (default: stdout)")
+	cmd.Flags().DurationVar(&opts.duration, "duration", 0, "preview duration (default: inferred from topology)")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "output file path (default: stdout)")
+	cmd.Flags().IntVar(&opts.samples, "samples", 0, "sample this many traces and print trace-shape histograms instead of the SVG chart")
+	cmd.Flags().Uint64Var(&opts.seed, "seed", 0, "random seed for --samples (0 = random)")
+	cmd.Flags().StringVar(&opts.root, "root", "", "sample only traces starting at this root operation (service.operation)")
 
 	return cmd
 }
 
-func runPreview(cmd *cobra.Command, configPath string, duration time.Duration, output string) error {
+// previewOptions holds the preview command's flags.
+type previewOptions struct {
+	duration time.Duration
+	output   string
+	samples  int
+	seed     uint64
+	root     string
+}
+
+func runPreview(cmd *cobra.Command, configPath string, opts previewOptions) error {
 	cfg, err := synth.LoadConfig(configPath)
 	if err != nil {
 		return err
@@ -54,6 +74,27 @@ func runPreview(cmd *cobra.Command, configPath string, duration time.Duration, o
 	if err != nil {
 		return err
 	}
+
+	var w io.Writer = cmd.OutOrStdout()
+	if opts.output != "" {
+		f, err := os.Create(opts.output) //nolint:gosec // user-supplied output path is expected
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer f.Close() //nolint:errcheck // best-effort close on write
+		w = f
+	}
+
+	if opts.samples > 0 {
+		if opts.root != "" {
+			if err := synth.SelectRoot(topo, opts.root); err != nil {
+				return fmt.Errorf("--root: %w", err)
+			}
+		}
+		results := synth.SampleTraces(topo, opts.samples, opts.seed, 0)
+		return renderSampleHistograms(w, results.Distribution)
+	}
+
 	traffic, err := synth.NewTrafficPattern(cfg.Traffic)
 	if err != nil {
 		return err
@@ -63,22 +104,13 @@ func runPreview(cmd *cobra.Command, configPath string, duration time.Duration, o
 		return err
 	}
 
+	duration := opts.duration
 	if duration == 0 {
 		duration = inferDuration(scenarios)
 	}
 
 	samples := sampleRates(traffic, scenarios, duration)
 
-	var w io.Writer = cmd.OutOrStdout()
-	if output != "" {
-		f, err := os.Create(output) //nolint:gosec // user-supplied output path is expected
-		if err != nil {
-			return fmt.Errorf("creating output file: %w", err)
-		}
-		defer f.Close() //nolint:errcheck // best-effort close on write
-		w = f
-	}
-
 	title := filepath.Base(configPath)
 	return renderSVG(w, samples, scenarios, title)
 }
@@ -89,13 +121,7 @@ func inferDuration(scenarios []synth.Scenario) time.Duration {
 	if len(scenarios) == 0 {
 		return defaultPreviewDuration
 	}
-	var latest time.Duration
-	for _, sc := range scenarios {
-		if sc.End > latest {
-			latest = sc.End
-		}
-	}
-	return time.Duration(float64(latest) * 1.1)
+	return time.Duration(float64(synth.ScenariosEnd(scenarios)) * 1.1)
 }
 
 type rateSample struct {
@@ -115,21 +141,31 @@ func sampleInterval(duration time.Duration) time.Duration {
 }
 
 func sampleRates(traffic synth.TrafficPattern, scenarios []synth.Scenario, duration time.Duration) []rateSample {
-	interval := sampleInterval(duration)
+	return sampleRatesEvery(traffic, scenarios, duration, sampleInterval(duration))
+}
+
+// sampleRatesEvery samples the effective traffic rate, including scenario
+// traffic overrides, every interval from zero up to duration.
+func sampleRatesEvery(traffic synth.TrafficPattern, scenarios []synth.Scenario, duration, interval time.Duration) []rateSample {
 	n := int(duration/interval) + 1
 	samples := make([]rateSample, 0, n)
 
 	for elapsed := time.Duration(0); elapsed <= duration; elapsed += interval {
-		rate := traffic.Rate(elapsed)
-		active := synth.ActiveScenarios(scenarios, elapsed)
-		if override := synth.ResolveTraffic(active); override != nil {
-			rate = override.Rate(elapsed)
-		}
-		samples = append(samples, rateSample{Elapsed: elapsed, Rate: rate})
+		samples = append(samples, rateSample{Elapsed: elapsed, Rate: rateAt(traffic, scenarios, elapsed)})
 	}
 	return samples
 }
 
+// rateAt returns the traffic rate at elapsed, honouring scenario traffic
+// overrides and spikes.
+func rateAt(traffic synth.TrafficPattern, scenarios []synth.Scenario, elapsed time.Duration) float64 {
+	active := synth.ActiveScenarios(scenarios, elapsed)
+	if override := synth.ResolveTraffic(active); override != nil {
+		traffic = override
+	}
+	return traffic.Rate(elapsed) * synth.ResolveTrafficMultiplier(active)
+}
+
 // SVG chart dimensions
 const (
 	svgWidth      = 800
@@ -253,6 +289,58 @@ func renderSVG(w io.Writer, samples []rateSample, scenarios []synth.Scenario, ti
 	return err
 }
 
+// Text histogram dimensions
+const (
+	histogramMaxBins  = 20
+	histogramBarWidth = 40
+)
+
+// renderSampleHistograms writes the percentiles and a text histogram of
+// spans per trace and trace depth across the sampled traces.
+func renderSampleHistograms(w io.Writer, dist synth.SampleDistribution) error {
+	if len(dist.Spans) == 0 {
+		return fmt.Errorf("no traces sampled; the topology has no root operations")
+	}
+	depth, spans, _ := dist.Summary()
+	var b strings.Builder
+	writeHistogram(&b, "spans per trace", dist.Spans, spans)
+	b.WriteString("\n")
+	writeHistogram(&b, "depth", dist.Depths, depth)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeHistogram writes a titled percentile summary followed by one bar per
+// bin. Values get a bin each when they fit in histogramMaxBins; otherwise the
+// range is split into histogramMaxBins bins of equal width.
+func writeHistogram(b *strings.Builder, title string, data []int, summary synth.DistributionSummary) {
+	fmt.Fprintf(b, "%s (%d samples)\n", title, len(data))
+	fmt.Fprintf(b, "  p50: %d\n  p95: %d\n  p99: %d\n  max: %d\n", summary.P50, summary.P95, summary.P99, summary.Max)
+
+	lo, hi := slices.Min(data), slices.Max(data)
+	width := max((hi-lo+histogramMaxBins)/histogramMaxBins, 1)
+	counts := make([]int, (hi-lo)/width+1)
+	for _, v := range data {
+		counts[(v-lo)/width]++
+	}
+	largest := slices.Max(counts)
+
+	labels := make([]string, len(counts))
+	labelWidth := 0
+	for i := range counts {
+		start := lo + i*width
+		labels[i] = strconv.Itoa(start)
+		if width > 1 {
+			labels[i] += "-" + strconv.Itoa(start+width-1)
+		}
+		labelWidth = max(labelWidth, len(labels[i]))
+	}
+	for i, count := range counts {
+		bar := strings.Repeat("#", (count*histogramBarWidth+largest-1)/largest)
+		fmt.Fprintf(b, "  %*s | %-*s %d\n", labelWidth, labels[i], histogramBarWidth, bar, count)
+	}
+}
+
 func formatRate(r float64) string {
 	if r >= 1000 {
 		return fmt.Sprintf("%.0fk", r/1000)
@@ -48,6 +48,66 @@ func TestPreviewCommand(t *testing.T) {
 		assert.True(t, strings.HasPrefix(string(data), "<svg"))
 	})
 
+	t.Run("samples prints percentiles and histograms", func(t *testing.T) {
+		t.Parallel()
+		cfg := `
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 10ms
+        calls:
+          - target: backend.work
+            count_distribution: poisson(mean=3)
+  backend:
+    operations:
+      work:
+        duration: 5ms
+traffic:
+  rate: 10/s
+`
+		path := writeTestConfig(t, cfg)
+		root := rootCmd()
+		root.SetArgs([]string{"preview", "--samples", "200", "--seed", "7", path})
+		var out bytes.Buffer
+		root.SetOut(&out)
+
+		err := root.Execute()
+		require.NoError(t, err)
+		got := out.String()
+		assert.NotContains(t, got, "<svg")
+		assert.Contains(t, got, "spans per trace (200 samples)")
+		assert.Contains(t, got, "depth (200 samples)")
+		for _, p := range []string{"p50", "p95", "p99"} {
+			assert.Equal(t, 2, strings.Count(got, "  "+p+": "), p)
+		}
+		assert.Regexp(t, `(?m)^\s+\d+ \| #+\s+\d+$`, got, "histogram bars")
+		assert.Regexp(t, `(?m)^\s+0 \| #+\s+\d+$`, got, "traces with no calls have depth 0")
+	})
+
+	t.Run("root requires samples", func(t *testing.T) {
+		t.Parallel()
+		path := writeTestConfig(t, validConfig)
+		root := rootCmd()
+		root.SetArgs([]string{"preview", "--root", "gateway.GET /users", path})
+
+		err := root.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--root requires --samples")
+	})
+
+	t.Run("negative samples", func(t *testing.T) {
+		t.Parallel()
+		path := writeTestConfig(t, validConfig)
+		root := rootCmd()
+		root.SetArgs([]string{"preview", "--samples", "-1", path})
+
+		err := root.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--samples")
+	})
+
 	t.Run("missing config file", func(t *testing.T) {
 		t.Parallel()
 		root := rootCmd()
@@ -231,6 +291,32 @@ func TestSampleRates(t *testing.T) {
 	})
 }
 
+func TestWriteHistogram(t *testing.T) {
+	t.Parallel()
+
+	t.Run("one bin per value", func(t *testing.T) {
+		t.Parallel()
+		var b strings.Builder
+		writeHistogram(&b, "depth", []int{1, 2, 2, 4}, synth.DistributionSummary{P50: 2, P95: 4, P99: 4, Max: 4})
+		want := "depth (4 samples)\n" +
+			"  p50: 2\n  p95: 4\n  p99: 4\n  max: 4\n" +
+			"  1 | " + strings.Repeat("#", 20) + strings.Repeat(" ", 20) + " 1\n" +
+			"  2 | " + strings.Repeat("#", 40) + " 2\n" +
+			"  3 | " + strings.Repeat(" ", 40) + " 0\n" +
+			"  4 | " + strings.Repeat("#", 20) + strings.Repeat(" ", 20) + " 1\n"
+		assert.Equal(t, want, b.String())
+	})
+
+	t.Run("wide ranges share bins", func(t *testing.T) {
+		t.Parallel()
+		var b strings.Builder
+		writeHistogram(&b, "spans", []int{1, 100}, synth.DistributionSummary{})
+		assert.Equal(t, 20, strings.Count(b.String(), " | "))
+		assert.Contains(t, b.String(), "  1-5 | ")
+		assert.Contains(t, b.String(), "96-100 | ")
+	})
+}
+
 func TestRenderSVG(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/andrewh/motel/pkg/synth"
+)
+
+// progressInterval is how often --progress writes a status line.
+const progressInterval = 5 * time.Second
+
+// startProgress writes a status line for engine to w every interval until
+// the returned stop function is called. stop waits for the reporter to exit,
+// so nothing is written after it returns.
+func startProgress(w io.Writer, engine *synth.Engine, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		start := time.Now()
+		last, lastAt := synth.Stats{}, start
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				p := engine.Progress()
+				rate := float64(p.Traces-last.Traces) / now.Sub(lastAt).Seconds()
+				_, _ = fmt.Fprintf(w, "progress: %s elapsed, %d traces, %d spans, %d errors, %.1f traces/s\n",
+					now.Sub(start).Truncate(time.Second), p.Traces, p.Spans, p.Errors, rate)
+				last, lastAt = p, now
+			}
+		}
+	})
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
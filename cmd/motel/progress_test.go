@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewh/motel/pkg/synth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestStartProgress(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := synth.ParseConfig([]byte(validConfig))
+	require.NoError(t, err)
+	topo, err := synth.BuildTopology(cfg)
+	require.NoError(t, err)
+	traffic, err := synth.NewTrafficPattern(cfg.Traffic)
+	require.NoError(t, err)
+
+	engine := &synth.Engine{
+		Topology: topo,
+		Traffic:  traffic,
+		Tracers:  func(string) trace.Tracer { return noop.NewTracerProvider().Tracer("") },
+		Rng:      rand.New(rand.NewPCG(1, 2)), //nolint:gosec // deterministic seed for testing
+		Duration: 300 * time.Millisecond,
+	}
+
+	var out bytes.Buffer
+	stop := startProgress(&out, engine, 50*time.Millisecond)
+	_, err = engine.Run(t.Context())
+	stop()
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.NotEmpty(t, lines[0], "at least one progress line is written")
+	for _, line := range lines {
+		assert.Regexp(t, `^progress: \S+ elapsed, \d+ traces, \d+ spans, \d+ errors, [\d.]+ traces/s$`, line)
+	}
+}
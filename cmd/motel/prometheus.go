@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+)
+
+// newPrometheusReader returns a metric reader that exposes a meter
+// provider's metrics through reg. Every service's provider registers its own
+// reader with the same registry, so series carry a service_name label to
+// keep the services apart.
+func newPrometheusReader(reg *prometheus.Registry) (*otelprom.Exporter, error) {
+	return otelprom.New(
+		otelprom.WithRegisterer(reg),
+		otelprom.WithResourceAsConstantLabels(attribute.NewAllowKeysFilter("service.name")),
+	)
+}
+
+// startPrometheus serves the metrics gathered by reg at http://addr/metrics
+// until the returned stop function is called.
+func startPrometheus(addr string, reg *prometheus.Registry) (stop func(), err error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("starting Prometheus endpoint: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: defaultShutdownTimeout}
+	go func() {
+		fmt.Fprintf(os.Stderr, "Prometheus metrics at http://%s/metrics\n", ln.Addr())
+		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "Prometheus endpoint error: %v\n", err)
+		}
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Prometheus endpoint shutdown error: %v\n", err)
+		}
+	}, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestPrometheusEndpoint(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	reg := prometheus.NewRegistry()
+	for _, service := range []string{"gateway", "backend"} {
+		reader, err := newPrometheusReader(reg)
+		require.NoError(t, err)
+		mp := sdkmetric.NewMeterProvider(
+			sdkmetric.WithReader(reader),
+			sdkmetric.WithResource(resource.NewSchemaless(attribute.String("service.name", service))),
+		)
+		t.Cleanup(func() { _ = mp.Shutdown(t.Context()) })
+
+		counter, err := mp.Meter("motel").Int64Counter("http.server.request.count")
+		require.NoError(t, err)
+		counter.Add(t.Context(), 3)
+	}
+
+	stop, err := startPrometheus(addr, reg)
+	require.NoError(t, err)
+	defer stop()
+
+	resp, err := http.Get("http://" + addr + "/metrics") //nolint:noctx // test request to a local server
+	require.NoError(t, err)
+	defer resp.Body.Close() //nolint:errcheck // test cleanup
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `http_server_request_count_total{otel_scope_name="motel"`)
+	assert.Contains(t, string(body), `service_name="gateway"`)
+	assert.Contains(t, string(body), `service_name="backend"`)
+}
+
+func TestRunPrometheusRequiresMetrics(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, validConfig)
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--duration", "100ms", "--prometheus-addr", "127.0.0.1:0", path})
+
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--prometheus-addr requires metrics in --signals")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andrewh/motel/pkg/synth"
+	"github.com/spf13/cobra"
+)
+
+func renderCmd() *cobra.Command {
+	var (
+		fetchTimeout  time.Duration
+		fetchMaxBytes int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "render <topology.yaml | URL>",
+		Short: "Print the effective topology with templates and includes expanded",
+		Long: "Print the effective topology as a single YAML document.\n\n" +
+			"Includes are merged, templates and vars are inlined into each operation,\n" +
+			"and YAML anchors are expanded, so the output shows exactly what motel runs.\n" +
+			"The output is itself a valid topology and loads to the same configuration.\n\n" +
+			"The topology source can be a local file path, an HTTP/HTTPS URL, or - to\n" +
+			"read it from stdin.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("missing topology file or URL\n\nUsage: motel render <topology.yaml | URL>")
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			loadOpts, err := fetchOptions(fetchTimeout, fetchMaxBytes)
+			if err != nil {
+				return err
+			}
+			cfg, err := synth.LoadConfig(args[0], loadOpts...)
+			if err != nil {
+				return err
+			}
+			if err := synth.ValidateConfig(cfg); err != nil {
+				return err
+			}
+			data, err := synth.MarshalConfig(cfg)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	}
+
+	addFetchFlags(cmd, &fetchTimeout, &fetchMaxBytes)
+
+	return cmd
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/andrewh/motel/pkg/synth"
+	"github.com/andrewh/motel/pkg/synth/traceimport"
+	"github.com/spf13/cobra"
+)
+
+func replayCmd() *cobra.Command {
+	var (
+		format        string
+		endpoint      string
+		stdout        bool
+		protocol      string
+		headers       string
+		insecure      bool
+		exportTimeout time.Duration
+		timeOffset    time.Duration
+		verbatim      bool
+		preserveIDs   bool
+		statsFile     string
+		statsFormat   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay <file>",
+		Short: "Re-export previously captured spans",
+		Long: "Reads spans captured from an earlier run (for example the output of 'motel run --stdout')\n" +
+			"and exports them again, shifting their timestamps so the earliest span starts now.\n\n" +
+			"This replays one capture repeatedly without regenerating it from the topology.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if statsFormat != statsFormatCompact && statsFormat != statsFormatPretty {
+				return fmt.Errorf("--stats-format must be %s or %s, got %q", statsFormatCompact, statsFormatPretty, statsFormat)
+			}
+			opts := runOptions{
+				endpoint:      endpoint,
+				endpointSet:   cmd.Flags().Changed("endpoint"),
+				stdout:        stdout,
+				protocol:      protocol,
+				protocolSet:   cmd.Flags().Changed("protocol"),
+				headers:       headers,
+				headersSet:    cmd.Flags().Changed("headers"),
+				insecure:      insecure,
+				insecureSet:   cmd.Flags().Changed("insecure"),
+				exportTimeout: exportTimeout,
+				timeoutSet:    cmd.Flags().Changed("timeout"),
+				timeOffset:    timeOffset,
+				verbatim:      verbatim,
+				preserveIDs:   preserveIDs,
+				statsFile:     statsFile,
+				statsFormat:   statsFormat,
+			}
+			if err := validateProtocol(opts.protocol); err != nil {
+				return err
+			}
+
+			f, err := os.Open(args[0]) //nolint:gosec // user-supplied file path is expected
+			if err != nil {
+				return fmt.Errorf("opening input: %w", err)
+			}
+			defer f.Close() //nolint:errcheck // best-effort close on read-only file
+
+			recording, info, err := loadCapture(f, traceimport.Format(format), cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			if !opts.stdout {
+				if err := checkEndpointForReplay(opts, args[0]); err != nil {
+					return err
+				}
+			}
+
+			stats, err := emitRecording(cmd.Context(), bytes.NewReader(recording), info, opts)
+			if err != nil {
+				return err
+			}
+			return writeStats(stats, opts.statsFile, opts.statsFormat)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "auto", "input format: auto, stdouttrace, otlp, or jaeger")
+	cmd.Flags().StringVar(&endpoint, "endpoint", "", "OTLP endpoint (overrides OTEL_EXPORTER_OTLP_ENDPOINT)")
+	cmd.Flags().BoolVar(&stdout, "stdout", false, "emit spans to stdout as JSON")
+	cmd.Flags().StringVar(&protocol, "protocol", "http/protobuf", "OTLP protocol: http/protobuf or grpc (overrides OTEL_EXPORTER_OTLP_PROTOCOL)")
+	cmd.Flags().StringVar(&headers, "headers", "", "OTLP headers as comma-separated key=value pairs (overrides OTEL_EXPORTER_OTLP_HEADERS)")
+	cmd.Flags().BoolVar(&insecure, "insecure", false, "disable TLS for OTLP exporters")
+	cmd.Flags().DurationVar(&exportTimeout, "timeout", 0, "OTLP export timeout (overrides OTEL_EXPORTER_OTLP_TIMEOUT)")
+	cmd.Flags().DurationVar(&timeOffset, "time-offset", 0, "shift replayed timestamps by this duration from now (e.g. -1h)")
+	cmd.Flags().BoolVar(&verbatim, "verbatim", false, "emit spans with their captured timestamps instead of shifting them to now")
+	cmd.Flags().BoolVar(&preserveIDs, "preserve-ids", false, "keep the captured trace and span IDs instead of generating fresh IDs")
+	cmd.Flags().StringVar(&statsFile, "stats-file", "", "write the final stats to this file instead of stderr (- for stdout)")
+	cmd.Flags().StringVar(&statsFormat, "stats-format", statsFormatCompact, "final stats format: compact or pretty")
+
+	return cmd
+}
+
+// loadCapture parses the spans read from r and rebuilds them into an
+// in-memory replay recording, returning it with its scanned services and
+// start time.
+func loadCapture(r io.Reader, format traceimport.Format, warnings io.Writer) ([]byte, synth.RecordingInfo, error) {
+	if format == traceimport.FormatMetaSummary {
+		return nil, synth.RecordingInfo{}, fmt.Errorf("--format %s has no per-trace span data to replay", format)
+	}
+	spans, err := traceimport.ParseSpans(r, format)
+	if err != nil {
+		return nil, synth.RecordingInfo{}, err
+	}
+	var recording bytes.Buffer
+	if err := traceimport.WriteRecording(traceimport.BuildTrees(spans, warnings), &recording); err != nil {
+		return nil, synth.RecordingInfo{}, fmt.Errorf("building recording: %w", err)
+	}
+	info, err := synth.ScanRecordingFrom(bytes.NewReader(recording.Bytes()))
+	if err != nil {
+		return nil, synth.RecordingInfo{}, err
+	}
+	if len(info.Services) == 0 {
+		return nil, synth.RecordingInfo{}, fmt.Errorf("no spans found to replay")
+	}
+	return recording.Bytes(), info, nil
+}
+
+// checkEndpointForReplay reports an unreachable collector with hints for
+// the replay command.
+func checkEndpointForReplay(opts runOptions, path string) error {
+	cfg, err := resolveOTLPConfig(opts, "traces")
+	if err != nil {
+		return err
+	}
+	host, err := dialEndpoint(cfg.endpoint, cfg.protocol)
+	if err != nil {
+		return fmt.Errorf("cannot reach OTLP collector at %s\n\n"+
+			"To emit spans as JSON to the terminal, use --stdout:\n"+
+			"  motel replay --stdout %s\n\n"+
+			"To send to a specific collector, use --endpoint:\n"+
+			"  motel replay --endpoint collector.example.com:4318 %s", host, path, path)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrewh/motel/pkg/synth"
+	"github.com/andrewh/motel/pkg/synth/traceimport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// captureStdout runs args through the root command and returns what it
+// wrote to os.Stdout. Callers must not run in parallel.
+func captureStdout(t *testing.T, args ...string) []byte {
+	t.Helper()
+
+	origStdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = out.ReadFrom(r)
+	}()
+
+	cmd := rootCmd()
+	cmd.SetArgs(args)
+	runErr := cmd.Execute()
+
+	w.Close()
+	os.Stdout = origStdout
+	<-done
+	require.NoError(t, runErr)
+	return out.Bytes()
+}
+
+func countStdoutSpans(t *testing.T, data []byte) int {
+	t.Helper()
+	n := 0
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var span map[string]any
+		require.NoError(t, dec.Decode(&span))
+		n++
+	}
+	return n
+}
+
+func TestReplayCapture(t *testing.T) {
+	// Not parallel: swaps os.Stdout, which the stdouttrace exporter writes to.
+	topoPath := writeTestConfig(t, validConfig)
+	captured := captureStdout(t, "run", "--stdout", "--duration", "100ms", topoPath)
+	captureSpans := countStdoutSpans(t, captured)
+	require.Positive(t, captureSpans)
+
+	recording, info, err := loadCapture(bytes.NewReader(captured), traceimport.FormatAuto, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"gateway", "backend"}, info.Services)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { require.NoError(t, tp.Shutdown(context.Background())) })
+	tracers, err := tracerSourceForServices(info.Services, map[string]*sdktrace.TracerProvider{"gateway": tp, "backend": tp}, nil)
+	require.NoError(t, err)
+
+	stats, err := synth.ReplayRecordingFrom(t.Context(), bytes.NewReader(recording), tracers, nil, synth.ReplayOptions{Start: info.Start})
+	require.NoError(t, err)
+	assert.Len(t, exporter.GetSpans(), captureSpans)
+	assert.Equal(t, int64(captureSpans), stats.Spans)
+}
+
+func TestReplayCommand(t *testing.T) {
+	// Not parallel: swaps os.Stdout, which the stdouttrace exporter writes to.
+	topoPath := writeTestConfig(t, validConfig)
+	captured := captureStdout(t, "run", "--stdout", "--duration", "100ms", topoPath)
+	capturePath := filepath.Join(t.TempDir(), "capture.jsonl")
+	require.NoError(t, os.WriteFile(capturePath, captured, 0o600))
+
+	replayed := captureStdout(t, "replay", "--stdout", "--stats-file", filepath.Join(t.TempDir(), "stats.json"), capturePath)
+	assert.Equal(t, countStdoutSpans(t, captured), countStdoutSpans(t, replayed))
+}
+
+func TestReplayCommandErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+		root := rootCmd()
+		root.SetArgs([]string{"replay", "--stdout", filepath.Join(t.TempDir(), "missing.jsonl")})
+		err := root.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "opening input")
+	})
+
+	t.Run("no spans", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "empty.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte(`{"resourceSpans":[]}`), 0o600))
+		root := rootCmd()
+		root.SetArgs([]string{"replay", "--stdout", path})
+		err := root.Execute()
+		require.Error(t, err)
+	})
+}
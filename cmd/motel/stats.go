@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/andrewh/motel/pkg/synth"
+)
+
+// Values for run --stats-format: how the final statistics are encoded.
+const (
+	statsFormatCompact = "compact"
+	statsFormatPretty  = "pretty"
+)
+
+// writeStats writes the final statistics of a run as JSON to path: stderr
+// when path is empty, stdout when it is "-", and otherwise the named file.
+// Pretty output is indented; compact output is a single line.
+func writeStats(stats *synth.Stats, path, format string) error {
+	var w io.Writer = os.Stderr
+	switch path {
+	case "":
+	case "-":
+		w = os.Stdout
+	default:
+		f, err := os.Create(path) //nolint:gosec // user-supplied output path is expected
+		if err != nil {
+			return fmt.Errorf("creating stats file: %w", err)
+		}
+		defer f.Close() //nolint:errcheck // best-effort close on write
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	if format == statsFormatPretty {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(stats)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/andrewh/motel/pkg/synth"
+)
+
+// watchInterval is how often --watch polls the topology file for changes.
+const watchInterval = 500 * time.Millisecond
+
+// watchGenerate generates from the topology at configPath and restarts the
+// run whenever the file changes. A changed topology that fails to load,
+// validate, or build is reported and the current run carries on. The
+// observers of a changed topology are built only once the current run has
+// stopped and closed its own, so the two never share an output file. When a
+// run ends, it waits for the next change until interrupted.
+func watchGenerate(ctx context.Context, configPath string, opts runOptions) error {
+	if strings.HasPrefix(configPath, "http://") || strings.HasPrefix(configPath, "https://") {
+		return fmt.Errorf("--watch requires a local topology file, not a URL")
+	}
+	if configPath == synth.StdinSource {
+		return fmt.Errorf("--watch requires a local topology file, not stdin")
+	}
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return err
+	}
+	plan, observers, err := loadWatchedPlan(configPath, opts)
+	if err != nil {
+		return err
+	}
+	if plan.Observers, err = synth.BuildObservers(observers, plan.Topology); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		running := plan != nil
+		if running {
+			go func() { done <- generate(runCtx, configPath, plan, opts) }()
+		}
+
+		var next *synth.Generation
+		var nextObservers []synth.ObserverConfig
+		for next == nil {
+			select {
+			case <-ctx.Done():
+				cancel()
+				if running {
+					return <-done
+				}
+				return nil
+			case err := <-done:
+				running = false
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "watch: run failed: %v\n", err)
+				}
+			case <-ticker.C:
+				current, err := os.Stat(configPath)
+				if err != nil || (current.ModTime().Equal(info.ModTime()) && current.Size() == info.Size()) {
+					continue
+				}
+				info = current
+				p, o, err := loadWatchedPlan(configPath, opts)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "watch: %s: %v; keeping the current topology\n", configPath, err)
+					continue
+				}
+				next, nextObservers = p, o
+			}
+		}
+
+		cancel()
+		if running {
+			if err := <-done; err != nil {
+				fmt.Fprintf(os.Stderr, "watch: run failed: %v\n", err)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "watch: %s changed; restarting\n", configPath)
+		plan = next
+		if plan.Observers, err = synth.BuildObservers(nextObservers, plan.Topology); err != nil {
+			fmt.Fprintf(os.Stderr, "watch: %s: %v; waiting for the next change\n", configPath, err)
+			plan = nil
+		}
+	}
+}
+
+// loadWatchedPlan loads, validates, and builds the topology at configPath.
+// It leaves the observers unbuilt and returns their configs, so the caller
+// can build them once the previous run has closed its own.
+func loadWatchedPlan(configPath string, opts runOptions) (*synth.Generation, []synth.ObserverConfig, error) {
+	cfg, err := synth.LoadConfig(configPath, opts.loadOptions...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := synth.ValidateConfig(cfg); err != nil {
+		return nil, nil, err
+	}
+	if cfg.Mode == synth.ModeReplay {
+		return nil, nil, fmt.Errorf("--watch is not supported with mode: replay")
+	}
+	observers := cfg.Observers
+	cfg.Observers = nil
+	plan, err := prepareRun(cfg, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan, observers, nil
+}
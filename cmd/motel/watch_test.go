@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunWatchReloadsTopology(t *testing.T) {
+	// Not parallel: swaps os.Stdout, which the stdouttrace exporter writes to.
+	path := writeTestConfig(t, `
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 1ms
+traffic:
+  rate: 100/s
+`)
+
+	origStdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = out.ReadFrom(r)
+	}()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	runErr := make(chan error, 1)
+	go func() {
+		root := rootCmd()
+		root.SetArgs([]string{"run", "--stdout", "--watch", "--duration", "1h", path})
+		runErr <- root.ExecuteContext(ctx)
+	}()
+
+	time.Sleep(4 * watchInterval)
+	require.NoError(t, os.WriteFile(path, []byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 1ms
+        calls:
+          - payments.charge
+  payments:
+    operations:
+      charge:
+        duration: 1ms
+traffic:
+  rate: 100/s
+`), 0o600))
+	time.Sleep(4 * watchInterval)
+	cancel()
+	err = <-runErr
+
+	w.Close()
+	os.Stdout = origStdout
+	<-done
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), `"request"`, "the first topology runs")
+	assert.Contains(t, out.String(), `"charge"`, "the run restarts with the new service")
+}
+
+func TestRunWatchClosesObserversBeforeReload(t *testing.T) {
+	// Not parallel: swaps os.Stdout, which the stdouttrace exporter writes to.
+	// The first topology runs longer and faster than the second, so its count
+	// has more digits. If it were written after the second run reopened the
+	// file, the second, shorter count would leave part of it behind.
+	countPath := filepath.Join(t.TempDir(), "spans.txt")
+	topology := func(rate string) string {
+		return `
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 1ms
+traffic:
+  rate: ` + rate + `
+observers:
+  - type: span_count
+    options:
+      path: ` + countPath + "\n"
+	}
+	path := writeTestConfig(t, topology("2000/s"))
+
+	origStdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(io.Discard, r)
+	}()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	runErr := make(chan error, 1)
+	go func() {
+		root := rootCmd()
+		root.SetArgs([]string{"run", "--stdout", "--watch", "--duration", "1h", path})
+		runErr <- root.ExecuteContext(ctx)
+	}()
+
+	time.Sleep(6 * watchInterval)
+	require.NoError(t, os.WriteFile(path, []byte(topology("10/s")), 0o600))
+	time.Sleep(4 * watchInterval)
+	cancel()
+	err = <-runErr
+
+	w.Close()
+	os.Stdout = origStdout
+	<-done
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(countPath)
+	require.NoError(t, err)
+	assert.Regexp(t, `^[1-9][0-9]?\n$`, string(data), "only the second run's count is written")
+}
+
+func TestRunWatchKeepsRunningOnInvalidChange(t *testing.T) {
+	// Not parallel: swaps os.Stderr, where watch reports errors.
+	path := writeTestConfig(t, validConfig)
+
+	origStderr := os.Stderr
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stderr = w
+
+	var errOut bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = errOut.ReadFrom(r)
+	}()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	runErr := make(chan error, 1)
+	go func() {
+		root := rootCmd()
+		root.SetArgs([]string{"run", "--stdout", "--watch", "--signals", "metrics", "--duration", "1h", path})
+		runErr <- root.ExecuteContext(ctx)
+	}()
+
+	time.Sleep(2 * watchInterval)
+	require.NoError(t, os.WriteFile(path, []byte("version: 1\nservices: {}\n"), 0o600))
+	time.Sleep(4 * watchInterval)
+	cancel()
+	err = <-runErr
+
+	w.Close()
+	os.Stderr = origStderr
+	<-done
+	require.NoError(t, err)
+
+	assert.Contains(t, errOut.String(), "keeping the current topology")
+	assert.NotContains(t, errOut.String(), "restarting")
+}
+
+func TestRunWatchRejectsURL(t *testing.T) {
+	t.Parallel()
+
+	root := rootCmd()
+	root.SetArgs([]string{"run", "--stdout", "--watch", "https://example.com/topology.yaml"})
+	err := root.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--watch requires a local topology file")
+}
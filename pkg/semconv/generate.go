@@ -56,6 +56,36 @@ func GeneratorsFor(group *Group) map[string]synth.AttributeGenerator {
 	return result
 }
 
+// RequiredGeneratorsFor creates AttributeGenerators for the attributes a
+// group marks with requirement_level required, including those inherited
+// through extends. A group's own declaration of an attribute takes precedence
+// over the groups it extends. Deprecated and unsupported attributes are
+// skipped as in GeneratorsFor.
+func RequiredGeneratorsFor(reg *Registry, group *Group) map[string]synth.AttributeGenerator {
+	result := make(map[string]synth.AttributeGenerator)
+	seen := make(map[string]bool)
+	visited := make(map[*Group]bool)
+	for g := group; g != nil && !visited[g]; g = reg.Group(g.Extends) {
+		visited[g] = true
+		for i := range g.Attributes {
+			attr := &g.Attributes[i]
+			if attr.ID == "" || seen[attr.ID] {
+				continue
+			}
+			seen[attr.ID] = true
+			if attr.RequirementLevel.Level != "required" || attr.Deprecated != nil {
+				continue
+			}
+			gen, err := GeneratorFor(attr)
+			if err != nil {
+				continue
+			}
+			result[attr.ID] = gen
+		}
+	}
+	return result
+}
+
 func generatorForEnum(attr *Attribute) (synth.AttributeGenerator, error) {
 	values := enumValues(attr)
 	if len(values) > 0 {
@@ -300,6 +300,47 @@ func TestGeneratorsFor_EmptyGroup(t *testing.T) {
 	assert.Empty(t, gens)
 }
 
+func TestRequiredGeneratorsFor_FollowsExtends(t *testing.T) {
+	t.Parallel()
+	reg := buildRegistry([]Group{
+		{
+			ID: "attributes.test.common",
+			Attributes: []Attribute{
+				{ID: "test.method", Type: AttributeType{Value: "string"}, RequirementLevel: RequirementLevel{Level: "required"}},
+				{ID: "test.route", Type: AttributeType{Value: "string"}, RequirementLevel: RequirementLevel{Level: "required"}},
+			},
+		},
+		{
+			ID:      "span.test.server",
+			Extends: "attributes.test.common",
+			Attributes: []Attribute{
+				{ID: "test.status", Type: AttributeType{Value: "int"}, RequirementLevel: RequirementLevel{Level: "required"}},
+				{ID: "test.size", Type: AttributeType{Value: "int"}, RequirementLevel: RequirementLevel{Level: "opt_in"}},
+				{ID: "test.route", Type: AttributeType{Value: "string"}, RequirementLevel: RequirementLevel{Level: "recommended"}},
+				{ID: "test.old", Type: AttributeType{Value: "string"}, RequirementLevel: RequirementLevel{Level: "required"}, Deprecated: "gone"},
+			},
+		},
+	})
+
+	gens := RequiredGeneratorsFor(reg, reg.Group("span.test.server"))
+	assert.Len(t, gens, 2)
+	assert.Contains(t, gens, "test.method", "required in the extended group")
+	assert.Contains(t, gens, "test.status")
+	assert.NotContains(t, gens, "test.size", "opt_in is not required")
+	assert.NotContains(t, gens, "test.route", "own group's level overrides the extended group")
+	assert.NotContains(t, gens, "test.old", "deprecated attributes are skipped")
+}
+
+func TestRequiredGeneratorsFor_ExtendsCycle(t *testing.T) {
+	t.Parallel()
+	reg := buildRegistry([]Group{
+		{ID: "a", Extends: "b", Attributes: []Attribute{{ID: "x.a", Type: AttributeType{Value: "string"}, RequirementLevel: RequirementLevel{Level: "required"}}}},
+		{ID: "b", Extends: "a", Attributes: []Attribute{{ID: "x.b", Type: AttributeType{Value: "string"}, RequirementLevel: RequirementLevel{Level: "required"}}}},
+	})
+	gens := RequiredGeneratorsFor(reg, reg.Group("a"))
+	assert.Len(t, gens, 2)
+}
+
 // --- Phase 4: Embedded Smoke Tests ---
 
 func TestGeneratorFor_RealHTTPMethod(t *testing.T) {
@@ -0,0 +1,53 @@
+// Root arrivals: the independent arrival processes that start traces
+// Roots with their own traffic get a process each; the rest share the engine's traffic
+package synth
+
+import (
+	"slices"
+	"time"
+)
+
+// arrivalStream is one arrival process of the run loop: the roots it starts
+// traces for, and when its next trace is due.
+type arrivalStream struct {
+	// traffic is the stream's own pattern, or nil for the stream that
+	// follows the engine's traffic and the scenarios' traffic overrides.
+	traffic TrafficPattern
+	roots   []*Operation
+	due     time.Time
+}
+
+// newArrivalStreams returns the arrival streams of topo: one shared by the
+// roots without traffic of their own, when there are any, followed by one
+// per root with its own traffic. Every stream is due immediately.
+func newArrivalStreams(topo *Topology) []*arrivalStream {
+	var shared []*Operation
+	var streams []*arrivalStream
+	for _, root := range topo.Roots {
+		if root.Traffic == nil {
+			shared = append(shared, root)
+			continue
+		}
+		streams = append(streams, &arrivalStream{traffic: root.Traffic, roots: []*Operation{root}})
+	}
+	if len(shared) > 0 {
+		streams = slices.Insert(streams, 0, &arrivalStream{roots: shared})
+	}
+	return streams
+}
+
+// nextArrival returns the stream due soonest, the earliest listed on a tie.
+func nextArrival(streams []*arrivalStream) *arrivalStream {
+	return slices.MinFunc(streams, func(a, b *arrivalStream) int {
+		return a.due.Compare(b.due)
+	})
+}
+
+// pattern returns the traffic pattern the stream follows, given the engine's
+// current one.
+func (s *arrivalStream) pattern(shared TrafficPattern) TrafficPattern {
+	if s.traffic != nil {
+		return s.traffic
+	}
+	return shared
+}
@@ -1,10 +1,12 @@
 // Per-operation attribute value generators for wide span emission
-// Supports static, weighted, sequence, boolean, range, and normal distribution values
+// Supports static, weighted, sequence, boolean, range, normal, Pareto, file, and regex values
 package synth
 
 import (
+	"cmp"
 	"fmt"
 	"maps"
+	"math"
 	"math/rand/v2"
 	"slices"
 	"strconv"
@@ -18,14 +20,31 @@ type DistributionConfig struct {
 	StdDev float64 `yaml:"stddev"`
 }
 
+// ParetoConfig defines parameters for a Pareto distribution generator:
+// the minimum value (scale, xm) and the tail index (shape, alpha).
+type ParetoConfig struct {
+	Scale float64 `yaml:"scale"`
+	Shape float64 `yaml:"shape"`
+}
+
 // AttributeValueConfig defines how an attribute value is generated from YAML.
 type AttributeValueConfig struct {
 	Value        any                 `yaml:"value,omitempty"`
 	Values       map[any]int         `yaml:"values,omitempty"`
 	Sequence     string              `yaml:"sequence,omitempty"`
+	Start        *int64              `yaml:"start,omitempty"`
+	Step         int64               `yaml:"step,omitempty"`
+	Width        int                 `yaml:"width,omitempty"`
 	Probability  *float64            `yaml:"probability,omitempty"`
 	Range        []int64             `yaml:"range,omitempty"`
 	Distribution *DistributionConfig `yaml:"distribution,omitempty"`
+	Pareto       *ParetoConfig       `yaml:"pareto,omitempty"`
+	File         string              `yaml:"file,omitempty"`
+	Regex        string              `yaml:"regex,omitempty"`
+
+	// source is the topology that named File, which a relative File is
+	// resolved against once vars are interpolated; empty once resolved.
+	source string
 }
 
 // Attribute pairs a key with its value generator.
@@ -126,15 +145,32 @@ func (w *WeightedChoice) Generate(rng *rand.Rand) any {
 }
 
 // SequenceValue produces incrementing values by replacing {n} in a pattern.
+// The sequence starts at Start when HasStart is set, otherwise at 1, and
+// advances by Step, or by one when Step is zero. A positive Width zero-pads
+// the number to that many digits.
 type SequenceValue struct {
-	Pattern string
-	counter atomic.Int64
+	Pattern  string
+	Start    int64
+	HasStart bool
+	Step     int64
+	Width    int
+	counter  atomic.Int64
 }
 
 // Generate returns the next value in the sequence, replacing {n} with an incrementing counter.
+// It is safe for concurrent use.
 func (s *SequenceValue) Generate(_ *rand.Rand) any {
-	n := s.counter.Add(1)
-	return strings.ReplaceAll(s.Pattern, "{n}", strconv.FormatInt(n, 10))
+	i := s.counter.Add(1) - 1
+	n := int64(1)
+	if s.HasStart {
+		n = s.Start
+	}
+	n += i * cmp.Or(s.Step, 1)
+	num := strconv.FormatInt(n, 10)
+	if s.Width > 0 {
+		num = fmt.Sprintf("%0*d", s.Width, n)
+	}
+	return strings.ReplaceAll(s.Pattern, "{n}", num)
 }
 
 // BoolValue generates a boolean based on a probability threshold.
@@ -170,6 +206,50 @@ func (n *NormalValue) Generate(rng *rand.Rand) any {
 	return n.Mean + rng.NormFloat64()*n.StdDev
 }
 
+// ParetoValue generates a Pareto distributed float64, for heavy-tailed
+// quantities such as payload sizes.
+type ParetoValue struct {
+	Scale float64
+	Shape float64
+}
+
+// Generate returns a Pareto distributed float64 of at least Scale, sampled
+// by inverting the CDF.
+func (p *ParetoValue) Generate(rng *rand.Rand) any {
+	// 1-u is in (0, 1], so the result is finite and at least Scale.
+	return p.Scale / math.Pow(1-rng.Float64(), 1/p.Shape)
+}
+
+// FileValue picks a random line from a newline-delimited values file.
+type FileValue struct {
+	Lines []string
+}
+
+// Generate returns a uniformly random line, or "" when the file had none.
+func (f *FileValue) Generate(rng *rand.Rand) any {
+	if len(f.Lines) == 0 {
+		return ""
+	}
+	return f.Lines[rng.IntN(len(f.Lines))]
+}
+
+// newFileValue reads the values file at path, a local file or URL. Blank
+// lines are skipped, so an empty file yields a generator that emits "".
+func newFileValue(path string) (*FileValue, error) {
+	data, err := readSource(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading values file: %w", err)
+	}
+	var lines []string
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return &FileValue{Lines: lines}, nil
+}
+
 // IsStaticAttributeConfig reports whether cfg produces a deterministic value
 // that is the same on every Generate call (i.e. only the value: field is set).
 // Used to validate that span-derived updowncounter attributes are consistent
@@ -180,7 +260,33 @@ func IsStaticAttributeConfig(cfg AttributeValueConfig) bool {
 		cfg.Sequence == "" &&
 		cfg.Probability == nil &&
 		len(cfg.Range) == 0 &&
-		cfg.Distribution == nil
+		cfg.Distribution == nil &&
+		cfg.Pareto == nil &&
+		cfg.File == "" &&
+		cfg.Regex == ""
+}
+
+// AttributeValueKind reports the type of value cfg generates: "string",
+// "integer", "float", or "boolean". It returns "" when the type cannot be
+// determined, such as for an unsupported static value.
+func AttributeValueKind(cfg AttributeValueConfig) string {
+	switch {
+	case cfg.Value != nil:
+		return valueKeyKind(cfg.Value)
+	case len(cfg.Values) > 0:
+		for k := range cfg.Values {
+			return valueKeyKind(k)
+		}
+	case cfg.Sequence != "", cfg.File != "", cfg.Regex != "":
+		return "string"
+	case cfg.Probability != nil:
+		return "boolean"
+	case len(cfg.Range) > 0:
+		return "integer"
+	case cfg.Distribution != nil, cfg.Pareto != nil:
+		return "float"
+	}
+	return ""
 }
 
 // NewAttributeGenerator creates an AttributeGenerator from a config entry.
@@ -205,8 +311,20 @@ func NewAttributeGenerator(cfg AttributeValueConfig) (AttributeGenerator, error)
 	if cfg.Distribution != nil {
 		set++
 	}
+	if cfg.Pareto != nil {
+		set++
+	}
+	if cfg.File != "" {
+		set++
+	}
+	if cfg.Regex != "" {
+		set++
+	}
 	if set != 1 {
-		return nil, fmt.Errorf("exactly one of value, values, sequence, probability, range, or distribution must be set")
+		return nil, fmt.Errorf("exactly one of value, values, sequence, probability, range, distribution, pareto, file, or regex must be set")
+	}
+	if cfg.Sequence == "" && (cfg.Start != nil || cfg.Step != 0 || cfg.Width != 0) {
+		return nil, fmt.Errorf("start, step, and width apply only to sequence")
 	}
 
 	if cfg.Value != nil {
@@ -214,7 +332,14 @@ func NewAttributeGenerator(cfg AttributeValueConfig) (AttributeGenerator, error)
 	}
 
 	if cfg.Sequence != "" {
-		return &SequenceValue{Pattern: cfg.Sequence}, nil
+		if cfg.Width < 0 {
+			return nil, fmt.Errorf("sequence width must not be negative, got %d", cfg.Width)
+		}
+		seq := &SequenceValue{Pattern: cfg.Sequence, Step: cfg.Step, Width: cfg.Width}
+		if cfg.Start != nil {
+			seq.Start, seq.HasStart = *cfg.Start, true
+		}
+		return seq, nil
 	}
 
 	if cfg.Probability != nil {
@@ -242,13 +367,69 @@ func NewAttributeGenerator(cfg AttributeValueConfig) (AttributeGenerator, error)
 		return &NormalValue{Mean: cfg.Distribution.Mean, StdDev: cfg.Distribution.StdDev}, nil
 	}
 
+	if cfg.Pareto != nil {
+		if cfg.Pareto.Scale <= 0 {
+			return nil, fmt.Errorf("pareto scale must be positive, got %f", cfg.Pareto.Scale)
+		}
+		if cfg.Pareto.Shape <= 0 {
+			return nil, fmt.Errorf("pareto shape must be positive, got %f", cfg.Pareto.Shape)
+		}
+		return &ParetoValue{Scale: cfg.Pareto.Scale, Shape: cfg.Pareto.Shape}, nil
+	}
+
+	if cfg.File != "" {
+		return newFileValue(cfg.File)
+	}
+
+	if cfg.Regex != "" {
+		return NewRegexValue(cfg.Regex)
+	}
+
 	return newWeightedChoice(cfg.Values)
 }
 
+// checkValueKeyTypes requires every weighted choice key to be a string,
+// integer, float, or boolean, and all keys to share one type so the emitted
+// attribute has a consistent type. Quote numeric keys to emit strings.
+func checkValueKeyTypes(values map[any]int) error {
+	kinds := make(map[string]bool)
+	for k := range values {
+		kind := valueKeyKind(k)
+		if kind == "" {
+			return fmt.Errorf("values key %v has unsupported type %T; use strings, integers, floats, or booleans", k, k)
+		}
+		kinds[kind] = true
+	}
+	if len(kinds) > 1 {
+		return fmt.Errorf("values keys must all have the same type, got %s (quote numeric keys to use strings)", strings.Join(slices.Sorted(maps.Keys(kinds)), " and "))
+	}
+	return nil
+}
+
+// valueKeyKind names the YAML type of a weighted choice key, or returns ""
+// for types that cannot become an attribute value.
+func valueKeyKind(k any) string {
+	switch k.(type) {
+	case string:
+		return "string"
+	case int, int64:
+		return "integer"
+	case float64:
+		return "float"
+	case bool:
+		return "boolean"
+	default:
+		return ""
+	}
+}
+
 func newWeightedChoice(values map[any]int) (*WeightedChoice, error) {
 	if len(values) == 0 {
 		return nil, fmt.Errorf("values must have at least one entry")
 	}
+	if err := checkValueKeyTypes(values); err != nil {
+		return nil, err
+	}
 
 	// Sort keys for deterministic ordering
 	type entry struct {
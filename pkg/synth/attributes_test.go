@@ -5,10 +5,13 @@ package synth
 import (
 	"math"
 	"math/rand/v2"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestStaticValue(t *testing.T) {
@@ -101,6 +104,66 @@ func TestSequenceValueMultiplePlaceholders(t *testing.T) {
 	assert.Equal(t, "req-2-trace-2", gen.Generate(rng))
 }
 
+func TestSequenceValueStartStepWidth(t *testing.T) {
+	t.Parallel()
+
+	var cfg AttributeValueConfig
+	require.NoError(t, yaml.Unmarshal([]byte(`{sequence: "ORD-{n}", start: 1000, step: 5, width: 8}`), &cfg))
+	gen, err := NewAttributeGenerator(cfg)
+	require.NoError(t, err)
+	rng := rand.New(rand.NewPCG(42, 0)) //nolint:gosec // deterministic seed for testing
+
+	assert.Equal(t, "ORD-00001000", gen.Generate(rng))
+	assert.Equal(t, "ORD-00001005", gen.Generate(rng))
+	assert.Equal(t, "ORD-00001010", gen.Generate(rng))
+
+	t.Run("explicit zero start", func(t *testing.T) {
+		t.Parallel()
+		gen := &SequenceValue{Pattern: "{n}", HasStart: true}
+		assert.Equal(t, "0", gen.Generate(rng))
+		assert.Equal(t, "1", gen.Generate(rng))
+	})
+
+	t.Run("negative step counts down", func(t *testing.T) {
+		t.Parallel()
+		gen := &SequenceValue{Pattern: "slot-{n}", Start: 3, HasStart: true, Step: -1, Width: 2}
+		assert.Equal(t, "slot-03", gen.Generate(rng))
+		assert.Equal(t, "slot-02", gen.Generate(rng))
+	})
+
+	t.Run("width wider than the number is not truncated", func(t *testing.T) {
+		t.Parallel()
+		gen := &SequenceValue{Pattern: "{n}", Start: 12345, HasStart: true, Width: 3}
+		assert.Equal(t, "12345", gen.Generate(rng))
+	})
+}
+
+func TestSequenceValueConcurrent(t *testing.T) {
+	t.Parallel()
+
+	gen := &SequenceValue{Pattern: "{n}", Start: 100, HasStart: true, Step: 2}
+	const workers, perWorker = 8, 100
+
+	var mu sync.Mutex
+	seen := make(map[any]bool, workers*perWorker)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for range perWorker {
+				v := gen.Generate(nil)
+				mu.Lock()
+				seen[v] = true
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	assert.Len(t, seen, workers*perWorker, "every value is handed out once")
+	assert.True(t, seen["100"])
+	assert.True(t, seen["1698"])
+}
+
 func TestNewAttributeGenerator(t *testing.T) {
 	t.Parallel()
 
@@ -146,6 +209,38 @@ func TestNewAttributeGenerator(t *testing.T) {
 		assert.IsType(t, &SequenceValue{}, gen)
 	})
 
+	t.Run("sequence with negative width is error", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewAttributeGenerator(AttributeValueConfig{Sequence: "user-{n}", Width: -1})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sequence width must not be negative, got -1")
+	})
+
+	t.Run("sequence options without sequence is error", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewAttributeGenerator(AttributeValueConfig{Value: "x", Step: 2})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "start, step, and width apply only to sequence")
+	})
+
+	t.Run("weighted values with mixed key types is error", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewAttributeGenerator(AttributeValueConfig{
+			Values: map[any]int{200: 95, "error": 5},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "values keys must all have the same type, got integer and string")
+	})
+
+	t.Run("weighted values with unsupported key type is error", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewAttributeGenerator(AttributeValueConfig{
+			Values: map[any]int{nil: 1},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported type")
+	})
+
 	t.Run("no fields set is error", func(t *testing.T) {
 		t.Parallel()
 		_, err := NewAttributeGenerator(AttributeValueConfig{})
@@ -218,6 +313,15 @@ func TestNewAttributeGenerator(t *testing.T) {
 		assert.IsType(t, &NormalValue{}, gen)
 	})
 
+	t.Run("pareto", func(t *testing.T) {
+		t.Parallel()
+		gen, err := NewAttributeGenerator(AttributeValueConfig{
+			Pareto: &ParetoConfig{Scale: 512, Shape: 1.5},
+		})
+		require.NoError(t, err)
+		assert.IsType(t, &ParetoValue{}, gen)
+	})
+
 	t.Run("probability out of range", func(t *testing.T) {
 		t.Parallel()
 		p := 1.5
@@ -254,6 +358,24 @@ func TestNewAttributeGenerator(t *testing.T) {
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "stddev")
 	})
+
+	t.Run("pareto non-positive scale", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewAttributeGenerator(AttributeValueConfig{
+			Pareto: &ParetoConfig{Scale: 0, Shape: 1.5},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pareto scale must be positive")
+	})
+
+	t.Run("pareto non-positive shape", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewAttributeGenerator(AttributeValueConfig{
+			Pareto: &ParetoConfig{Scale: 512, Shape: -1},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pareto shape must be positive")
+	})
 }
 
 func TestBoolValue(t *testing.T) {
@@ -341,6 +463,91 @@ func TestNormalValue(t *testing.T) {
 		"expected mean near 4096, got %f", avg)
 }
 
+func TestFileValue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("values come from the file relative to the config", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := LoadConfig(filepath.Join("testdata", "values", "topology.yaml"))
+		require.NoError(t, err)
+		require.NoError(t, ValidateConfig(cfg))
+		topo, err := BuildTopology(cfg)
+		require.NoError(t, err)
+
+		gen := topo.Services["users"].Operations["login"].Attributes.Get("user.name")
+		require.IsType(t, &FileValue{}, gen)
+		rng := rand.New(rand.NewPCG(42, 0)) //nolint:gosec // deterministic seed for testing
+		seen := make(map[any]bool)
+		for range 300 {
+			v := gen.Generate(rng)
+			assert.Contains(t, []any{"alice", "bob", "carol"}, v)
+			seen[v] = true
+		}
+		assert.Len(t, seen, 3, "every line should be picked")
+	})
+
+	t.Run("missing file fails validation", func(t *testing.T) {
+		t.Parallel()
+		dir := writeConfigFiles(t, map[string]string{
+			"topology.yaml": `
+version: 1
+services:
+  users:
+    operations:
+      login:
+        duration: 10ms
+        attributes:
+          user.name: {file: missing.txt}
+traffic:
+  rate: 10/s
+`,
+		})
+		cfg, err := LoadConfig(filepath.Join(dir, "topology.yaml"))
+		require.NoError(t, err)
+		err = ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `attribute "user.name"`)
+		assert.Contains(t, err.Error(), "missing.txt")
+	})
+
+	t.Run("empty file emits empty strings", func(t *testing.T) {
+		t.Parallel()
+		dir := writeConfigFiles(t, map[string]string{"empty.txt": "\n"})
+		gen, err := NewAttributeGenerator(AttributeValueConfig{File: filepath.Join(dir, "empty.txt")})
+		require.NoError(t, err)
+		rng := rand.New(rand.NewPCG(42, 0)) //nolint:gosec // deterministic seed for testing
+		assert.Equal(t, "", gen.Generate(rng))
+	})
+}
+
+func TestAttributeValueKind(t *testing.T) {
+	t.Parallel()
+	p := 0.5
+	tests := []struct {
+		name string
+		cfg  AttributeValueConfig
+		want string
+	}{
+		{"static string", AttributeValueConfig{Value: "x"}, "string"},
+		{"static int", AttributeValueConfig{Value: 200}, "integer"},
+		{"integer values", AttributeValueConfig{Values: map[any]int{200: 1, 500: 1}}, "integer"},
+		{"quoted values", AttributeValueConfig{Values: map[any]int{"200": 1}}, "string"},
+		{"sequence", AttributeValueConfig{Sequence: "id-{n}"}, "string"},
+		{"probability", AttributeValueConfig{Probability: &p}, "boolean"},
+		{"range", AttributeValueConfig{Range: []int64{1, 2}}, "integer"},
+		{"distribution", AttributeValueConfig{Distribution: &DistributionConfig{Mean: 1}}, "float"},
+		{"pareto", AttributeValueConfig{Pareto: &ParetoConfig{Scale: 1, Shape: 1}}, "float"},
+		{"regex", AttributeValueConfig{Regex: "a+"}, "string"},
+		{"unsupported static", AttributeValueConfig{Value: []any{1}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, AttributeValueKind(tt.cfg))
+		})
+	}
+}
+
 func TestTypedAttribute(t *testing.T) {
 	t.Parallel()
 
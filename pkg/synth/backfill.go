@@ -0,0 +1,72 @@
+// Backfill: a run over a simulated clock, generating a long window of traffic in little wall-clock time
+// Enabled by Engine.Backfill; the traffic pattern is sampled across the whole window
+package synth
+
+import (
+	"context"
+	"time"
+)
+
+// backfillIdleStep is how far a backfill's simulated clock advances a
+// stream while its traffic rate is zero.
+const backfillIdleStep = time.Second
+
+// runBackfill simulates Duration of traffic as fast as traces can be
+// generated. Instead of sleeping, each arrival stream's next trace is due
+// one inter-arrival interval of simulated time later, and the run jumps to
+// whichever is due first, so traces spread across the whole window at the
+// rate the traffic pattern has at each point of it.
+//
+// The returned Stats describe the simulated window: ElapsedMs is its
+// length, and the rates are per second of simulated time.
+func (e *Engine) runBackfill(ctx context.Context) (*Stats, error) {
+	stats := e.newRunStats()
+	startTime := time.Now()
+	end := e.runDuration()
+	var lastActive []Scenario
+	streams := newArrivalStreams(e.Topology)
+	for _, stream := range streams {
+		stream.due = startTime
+	}
+
+	var elapsed time.Duration
+	finish := func() (*Stats, error) {
+		e.finaliseStats(&stats, time.Now().Add(-elapsed))
+		return &stats, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return finish()
+		default:
+		}
+
+		stream := nextArrival(streams)
+		now := stream.due
+		if now.Sub(startTime) > end {
+			elapsed = end
+			return finish()
+		}
+		elapsed = now.Sub(startTime)
+
+		scenarios := e.resolveScenarios(elapsed, &lastActive)
+		pattern := stream.pattern(scenarios.traffic)
+		rate := pattern.Rate(elapsed) * scenarios.multiplier
+		e.trafficPeak = trafficPeak(pattern, elapsed)
+		if rate <= 0 {
+			stream.due = now.Add(backfillIdleStep)
+			continue
+		}
+
+		root := stream.roots[e.Rng.IntN(len(stream.roots))]
+		if !scenarios.drained(root) {
+			e.walkRoot(ctx, root, now, elapsed, scenarios, &stats)
+			if e.MaxTraces > 0 && stats.Traces >= int64(e.MaxTraces) {
+				return finish()
+			}
+		}
+
+		stream.due = now.Add(time.Duration(float64(time.Second) / rate))
+	}
+}
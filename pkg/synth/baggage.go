@@ -9,6 +9,9 @@
 package synth
 
 import (
+	"fmt"
+	"maps"
+	"math/rand/v2"
 	"sort"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -37,6 +40,21 @@ func mergeDeclaredBaggage(service, operation map[string]string) map[string]strin
 	return merged
 }
 
+// declaredBaggage returns the baggage op sets when its span starts: its
+// static baggage plus a freshly generated value for each generated_baggage
+// key, drawn from rng in key order.
+func declaredBaggage(op *Operation, rng *rand.Rand) map[string]string {
+	if len(op.GeneratedBaggage) == 0 {
+		return op.Baggage
+	}
+	declared := make(map[string]string, len(op.Baggage)+len(op.GeneratedBaggage))
+	maps.Copy(declared, op.Baggage)
+	for _, a := range op.GeneratedBaggage {
+		declared[a.Key] = fmt.Sprint(a.Gen.Generate(rng))
+	}
+	return declared
+}
+
 // overlayBaggageMap overlays an operation's declared baggage onto the baggage
 // inherited from its parent, returning the combined set visible while the span is
 // active. Declared entries win on key conflicts. Returns nil when the result is
@@ -296,3 +296,111 @@ func baggageAttrsFromPlan(p SpanPlan) map[string]string {
 	}
 	return out
 }
+
+// TestEngineGeneratedBaggage asserts that generated_baggage on a root draws a
+// fresh value per trace and that the value reaches a grandchild span, in both
+// the batch and realtime paths.
+func TestEngineGeneratedBaggage(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      checkout:
+        duration: 1ms
+        generated_baggage:
+          session.id:
+            sequence: "session-{n}"
+        calls: [payments.charge]
+  payments:
+    operations:
+      charge:
+        duration: 1ms
+        calls: [ledger.record]
+  ledger:
+    baggage_as_attributes: true
+    operations:
+      record:
+        duration: 1ms
+traffic:
+  rate: 1000/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 3
+			engine.Realtime = realtime
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			sessions := map[string]bool{}
+			for _, s := range exporter.GetSpans() {
+				if s.Name != "record" {
+					continue
+				}
+				id := baggageAttrs(s)["session.id"]
+				assert.Regexp(t, `^session-\d+$`, id)
+				sessions[id] = true
+			}
+			assert.Len(t, sessions, 3, "each trace generates its own session.id")
+		})
+	}
+}
+
+func TestValidateGeneratedBaggage(t *testing.T) {
+	t.Parallel()
+
+	base := func(op OperationConfig) *Config {
+		op.Name, op.Duration = "op", "10ms"
+		return &Config{
+			Services: []ServiceConfig{{Name: "svc", Operations: []OperationConfig{op}}},
+			Traffic:  TrafficConfig{Rate: "10/s"},
+		}
+	}
+	seq := AttributeValueConfig{Sequence: "s-{n}"}
+
+	tests := []struct {
+		name    string
+		op      OperationConfig
+		wantErr string
+	}{
+		{"valid", OperationConfig{GeneratedBaggage: map[string]AttributeValueConfig{"session.id": seq}}, ""},
+		{"empty key", OperationConfig{GeneratedBaggage: map[string]AttributeValueConfig{"": seq}}, "generated_baggage key must not be empty"},
+		{"invalid key", OperationConfig{GeneratedBaggage: map[string]AttributeValueConfig{"bad key": seq}}, "invalid generated_baggage key"},
+		{"invalid generator", OperationConfig{GeneratedBaggage: map[string]AttributeValueConfig{"session.id": {}}}, `generated_baggage "session.id"`},
+		{
+			"key also static",
+			OperationConfig{
+				Baggage:          map[string]string{"session.id": "fixed"},
+				GeneratedBaggage: map[string]AttributeValueConfig{"session.id": seq},
+			},
+			"set in both baggage and generated_baggage",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateConfig(base(tt.op))
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
@@ -106,6 +107,50 @@ func BenchmarkWalkTrace(b *testing.B) {
 	b.ReportMetric(float64(stats.Spans), "spans/trace")
 }
 
+// BenchmarkSpanAttributeSlice compares allocating a fresh attribute slice per
+// span, as walkTrace used to, with the pooled, pre-sized slice it uses now.
+func BenchmarkSpanAttributeSlice(b *testing.B) {
+	topo, err := BuildTopology(benchmarkTopology())
+	if err != nil {
+		b.Fatal(err)
+	}
+	op := topo.Roots[0]
+	rng := rand.New(rand.NewPCG(42, 0)) //nolint:gosec // deterministic seed for benchmarking
+
+	fill := func(dst []attribute.KeyValue) []attribute.KeyValue {
+		for k, v := range op.Service.Attributes {
+			dst = append(dst, attribute.String(k, v))
+		}
+		for _, a := range op.Attributes {
+			dst = append(dst, typedAttribute(a.Key, a.Gen.Generate(rng)))
+		}
+		return dst
+	}
+
+	b.Run("make", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			attrs := make([]attribute.KeyValue, 0, len(op.Service.Attributes)+len(op.Attributes))
+			attrs = fill(attrs)
+			sinkAttrs = attrs
+		}
+	})
+
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			buf := getSpanAttrs(spanAttrCapacity(op, op.Attributes, nil))
+			attrs := fill(*buf)
+			sinkAttrs = attrs
+			*buf = attrs
+			putSpanAttrs(buf)
+		}
+	})
+}
+
+// sinkAttrs keeps benchmark results live so the compiler cannot elide them.
+var sinkAttrs []attribute.KeyValue
+
 func BenchmarkAttributeGeneration(b *testing.B) {
 	rng := rand.New(rand.NewPCG(42, 0)) //nolint:gosec // deterministic seed for benchmarking
 
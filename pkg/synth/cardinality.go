@@ -0,0 +1,133 @@
+// Attribute cardinality estimation: how many distinct values a generator can produce
+// Used by Check to flag high-cardinality metric dimensions
+package synth
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"math/bits"
+	"regexp/syntax"
+	"slices"
+)
+
+// UnboundedCardinality is the estimate for generators whose distinct values
+// grow without a practical limit, such as sequences and continuous
+// distributions. It compares greater than any finite limit.
+const UnboundedCardinality = math.MaxInt
+
+// Cardinality estimates how many distinct values gen can produce, returning
+// UnboundedCardinality when there is no practical limit. Regex estimates
+// count every expansion, so overlapping alternatives are counted twice.
+func Cardinality(gen AttributeGenerator) int {
+	switch g := gen.(type) {
+	case *StaticValue:
+		return 1
+	case *WeightedChoice:
+		return len(g.Choices)
+	case *BoolValue:
+		if g.Probability <= 0 || g.Probability >= 1 {
+			return 1
+		}
+		return 2
+	case *RangeValue:
+		span := uint64(g.Max) - uint64(g.Min) //nolint:gosec // deliberate uint64 cast for overflow-safe range arithmetic
+		if span >= math.MaxInt {
+			return UnboundedCardinality
+		}
+		return int(span) + 1 //nolint:gosec // span is below math.MaxInt
+	case *FileValue:
+		distinct := make(map[string]struct{}, len(g.Lines))
+		for _, line := range g.Lines {
+			distinct[line] = struct{}{}
+		}
+		return max(len(distinct), 1)
+	case *RegexValue:
+		return g.root.cardinality()
+	default:
+		return UnboundedCardinality
+	}
+}
+
+// cardinality counts the strings n expands to, saturating at
+// UnboundedCardinality.
+func (n *regexNode) cardinality() int {
+	switch n.op {
+	case syntax.OpCharClass:
+		count := 0
+		for i := 0; i+1 < len(n.ranges); i += 2 {
+			count += int(n.ranges[i+1]-n.ranges[i]) + 1
+		}
+		return count
+	case syntax.OpConcat:
+		count := 1
+		for _, sub := range n.subs {
+			count = cardinalityMul(count, sub.cardinality())
+		}
+		return count
+	case syntax.OpAlternate:
+		count := 0
+		for _, sub := range n.subs {
+			count = cardinalityAdd(count, sub.cardinality())
+		}
+		return count
+	case syntax.OpRepeat:
+		hi := n.max
+		if hi < 0 {
+			hi = n.min + maxRegexRepeat
+		}
+		sub := n.subs[0].cardinality()
+		count, term := 0, 1
+		for k := range hi + 1 {
+			if k >= n.min {
+				count = cardinalityAdd(count, term)
+			}
+			term = cardinalityMul(term, sub)
+		}
+		return count
+	default:
+		return 1
+	}
+}
+
+func cardinalityAdd(a, b int) int {
+	if a > UnboundedCardinality-b {
+		return UnboundedCardinality
+	}
+	return a + b
+}
+
+func cardinalityMul(a, b int) int {
+	hi, lo := bits.Mul64(uint64(a), uint64(b)) //nolint:gosec // counts are never negative
+	if hi != 0 || lo > math.MaxInt {
+		return UnboundedCardinality
+	}
+	return int(lo) //nolint:gosec // lo is at most math.MaxInt
+}
+
+// MaxCardinality returns the highest estimated cardinality among the
+// attributes recorded as metric dimensions, on both service and operation
+// metrics, and names the metric and attribute that produce it. Ties go to
+// the first in service, operation, metric, and attribute order.
+func MaxCardinality(topo *Topology) (int, string) {
+	worst, worstRef := 0, ""
+	consider := func(scope string, metrics []MetricDefinition) {
+		for _, md := range metrics {
+			for _, attr := range md.Attributes {
+				if c := Cardinality(attr.Gen); c > worst {
+					worst = c
+					worstRef = fmt.Sprintf("%s metric %s attribute %s", scope, md.Name, attr.Key)
+				}
+			}
+		}
+	}
+	for _, svcName := range slices.Sorted(maps.Keys(topo.Services)) {
+		svc := topo.Services[svcName]
+		consider(svc.Name, svc.Metrics)
+		for _, opName := range slices.Sorted(maps.Keys(svc.Operations)) {
+			op := svc.Operations[opName]
+			consider(op.Ref, op.Metrics)
+		}
+	}
+	return worst, worstRef
+}
@@ -0,0 +1,121 @@
+// Tests for attribute cardinality estimation and the max-cardinality check
+package synth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardinality(t *testing.T) {
+	t.Parallel()
+
+	half, always := 0.5, 1.0
+	tests := []struct {
+		name string
+		cfg  AttributeValueConfig
+		want int
+	}{
+		{"static", AttributeValueConfig{Value: "GET"}, 1},
+		{"weighted choice", AttributeValueConfig{Values: map[any]int{"GET": 3, "POST": 2, "DELETE": 1}}, 3},
+		{"bool", AttributeValueConfig{Probability: &half}, 2},
+		{"bool always true", AttributeValueConfig{Probability: &always}, 1},
+		{"range", AttributeValueConfig{Range: []int64{200, 599}}, 400},
+		{"regex", AttributeValueConfig{Regex: `(GET|POST) /[a-c]{2}`}, 18},
+		{"regex optional", AttributeValueConfig{Regex: `v[0-9]?`}, 11},
+		{"uuid regex", AttributeValueConfig{Regex: `[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`}, UnboundedCardinality},
+		{"sequence", AttributeValueConfig{Sequence: "req-{n}"}, UnboundedCardinality},
+		{"distribution", AttributeValueConfig{Distribution: &DistributionConfig{Mean: 10, StdDev: 2}}, UnboundedCardinality},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			gen, err := NewAttributeGenerator(tt.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Cardinality(gen))
+		})
+	}
+}
+
+func TestCardinalityFullRange(t *testing.T) {
+	t.Parallel()
+
+	gen := &RangeValue{Min: -1 << 63, Max: 1<<63 - 1}
+	assert.Equal(t, UnboundedCardinality, Cardinality(gen))
+}
+
+func TestCardinalityFileValueCountsDistinctLines(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 2, Cardinality(&FileValue{Lines: []string{"a", "b", "a"}}))
+	assert.Equal(t, 1, Cardinality(&FileValue{}), "an empty file always emits the empty string")
+}
+
+const cardinalityConfig = `
+version: 1
+services:
+  gateway:
+    metrics:
+      - name: http.server.requests
+        type: counter
+        attributes:
+          http.request.method:
+            values: {GET: 3, POST: 1}
+    operations:
+      request:
+        duration: 10ms
+        metrics:
+          - name: requests.by.user
+            type: counter
+            attributes:
+              user.id:
+                range: [1, 500]
+traffic:
+  rate: 10/s
+`
+
+func TestMaxCardinality(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(cardinalityConfig))
+	require.NoError(t, err)
+	topo, err := BuildTopology(cfg)
+	require.NoError(t, err)
+
+	cardinality, ref := MaxCardinality(topo)
+	assert.Equal(t, 500, cardinality)
+	assert.Equal(t, "gateway.request metric requests.by.user attribute user.id", ref)
+}
+
+func TestCheck_MaxCardinality(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(cardinalityConfig))
+	require.NoError(t, err)
+	topo, err := BuildTopology(cfg)
+	require.NoError(t, err)
+
+	find := func(results []CheckResult) *CheckResult {
+		for i := range results {
+			if results[i].Name == CheckNameMaxCardinality {
+				return &results[i]
+			}
+		}
+		return nil
+	}
+
+	assert.Nil(t, find(Check(topo, CheckOptions{MaxDepth: 10, MaxFanOut: 100, MaxSpans: 10000})),
+		"max-cardinality is skipped without a limit")
+
+	r := find(Check(topo, CheckOptions{MaxDepth: 10, MaxFanOut: 100, MaxSpans: 10000, MaxCardinality: 100}))
+	require.NotNil(t, r)
+	assert.False(t, r.Pass)
+	assert.Equal(t, 500, r.Actual)
+	assert.Equal(t, 100, r.Limit)
+	assert.Contains(t, r.Ref, "user.id")
+
+	r = find(Check(topo, CheckOptions{MaxDepth: 10, MaxFanOut: 100, MaxSpans: 10000, MaxCardinality: 500}))
+	require.NotNil(t, r)
+	assert.True(t, r.Pass)
+}
@@ -30,6 +30,14 @@ const (
 	CheckNameP99Spans  = "p99-spans"
 )
 
+// CheckNameMaxLatency names the critical-path latency check, which runs only
+// when CheckOptions.MaxLatency is set.
+const CheckNameMaxLatency = "max-latency"
+
+// CheckNameMaxCardinality names the metric dimension cardinality check,
+// which runs only when CheckOptions.MaxCardinality is set.
+const CheckNameMaxCardinality = "max-cardinality"
+
 // CheckResult holds the outcome of a single structural check.
 // Scenarios names the scenario combination that produced the worst case;
 // empty means the baseline topology (no scenarios active).
@@ -44,6 +52,8 @@ type CheckResult struct {
 	Ref          string
 	Scenarios    []string
 	Distribution *DistributionSummary
+	Latency      time.Duration // max-latency only, in place of Actual
+	LatencyLimit time.Duration // max-latency only, in place of Limit
 }
 
 // DistributionSummary holds percentile statistics for a metric.
@@ -99,6 +109,8 @@ type CheckOptions struct {
 	MaxDepth         int
 	MaxFanOut        int
 	MaxSpans         int
+	MaxLatency       time.Duration // zero skips the max-latency check
+	MaxCardinality   int           // zero skips the max-cardinality check
 	MaxSpansPerTrace int
 	Samples          int
 	Seed             uint64
@@ -116,6 +128,15 @@ type SampleResults struct {
 	Distribution SampleDistribution
 }
 
+// maxCount returns the most times call can fire for one parent span: the
+// bound of its count distribution, or its fixed count.
+func (c Call) maxCount() int {
+	if c.CountDist != nil {
+		return c.CountDist.Bound
+	}
+	return max(c.Count, 1)
+}
+
 // maxSpansCap prevents overflow in worst-case span multiplication.
 const maxSpansCap = math.MaxInt32
 
@@ -147,7 +168,7 @@ func maxDepthWith(topo *Topology, overrides map[string]Override) (int, []string)
 
 		best := result{depth: 0, path: []string{op.Ref}}
 
-		for _, call := range effectiveCalls(op, overrides) {
+		for _, call := range slices.Concat(effectiveCalls(op, overrides), op.OneOf) {
 			if visited[call.Operation] {
 				continue
 			}
@@ -179,7 +200,8 @@ func maxDepthWith(topo *Topology, overrides map[string]Override) (int, []string)
 
 // MaxFanOut returns the worst-case direct children per span and which
 // operation produces it. For each operation, it sums
-// max(call.Count, 1) * (1 + call.Retries) across all calls.
+// call.maxCount() * (1 + call.Retries) across all calls, counting a one_of
+// group as its largest branch.
 func MaxFanOut(topo *Topology) (int, string) {
 	return maxFanOutWith(topo, nil)
 }
@@ -193,10 +215,15 @@ func maxFanOutWith(topo *Topology, overrides map[string]Override) (int, string)
 		for _, op := range svc.Operations {
 			fan := 0
 			for _, call := range effectiveCalls(op, overrides) {
-				count := max(call.Count, 1)
+				count := call.maxCount()
 				attempts := 1 + call.Retries
 				fan += count * attempts
 			}
+			branch := 0
+			for _, call := range op.OneOf {
+				branch = max(branch, call.maxCount()*(1+call.Retries))
+			}
+			fan += branch
 			if fan > maxFan {
 				maxFan = fan
 				worstRef = op.Ref
@@ -209,7 +236,8 @@ func maxFanOutWith(topo *Topology, overrides map[string]Override) (int, string)
 
 // MaxSpans returns the worst-case total spans per trace via DFS from each root.
 // It multiplies fan-out at each level. Both on-error and on-success paths are
-// included (conservative — real traces cannot take both).
+// included (conservative — real traces cannot take both), and a one_of group
+// counts as its largest branch.
 // Returns the max and which root produces it.
 func MaxSpans(topo *Topology) (int, string) {
 	return maxSpansWith(topo, nil)
@@ -232,25 +260,34 @@ func maxSpansWith(topo *Topology, overrides map[string]Override) (int, string) {
 
 		total := 1 // the operation's own span
 
-		for _, call := range effectiveCalls(op, overrides) {
+		// subtree returns the worst-case spans beneath one call, capped at
+		// maxSpansCap, or zero when the callee is already on the path.
+		subtree := func(call Call) int {
 			if visited[call.Operation] {
-				continue
+				return 0
 			}
 			visited[call.Operation] = true
 			childSpans := dfs(call.Operation, visited)
 			delete(visited, call.Operation)
 
-			count := max(call.Count, 1)
+			count := call.maxCount()
 			attempts := 1 + call.Retries
 			fanForCall := count * attempts
 
-			// Guard the multiplication against overflow before comparing.
-			if childSpans > 0 && fanForCall > (maxSpansCap-total)/childSpans {
-				total = maxSpansCap
-				break
+			// Guard the multiplication against overflow.
+			if childSpans > 0 && fanForCall > maxSpansCap/childSpans {
+				return maxSpansCap
 			}
-			total += fanForCall * childSpans
+			return fanForCall * childSpans
 		}
+		branch := 0
+		for _, call := range op.OneOf {
+			branch = max(branch, subtree(call))
+		}
+		for _, call := range effectiveCalls(op, overrides) {
+			total = min(total+subtree(call), maxSpansCap)
+		}
+		total = min(total+branch, maxSpansCap)
 
 		if total > maxSpansCap {
 			total = maxSpansCap
@@ -273,6 +310,140 @@ func maxSpansWith(topo *Topology, overrides map[string]Override) (int, string) {
 	return maxTotal, worstRoot
 }
 
+// MaxLatency returns the worst-case critical-path latency of a trace and the
+// operation refs along its slowest chain of calls. Each operation contributes
+// the 99th percentile of its duration. Sequential calls add up and parallel
+// calls take the slowest branch, offset by any call_jitter. A call counts
+// every retry as failing, capped at its timeout, with the full backoff
+// between attempts. A one_of group counts as its slowest branch. Async calls
+// are left out because the caller does not wait for them. A wait-critical
+// operation overlaps the second half of its own duration with its calls, and
+// a return-early operation counts only its own duration.
+func MaxLatency(topo *Topology) (time.Duration, []string) {
+	return maxLatencyWith(topo, nil)
+}
+
+// maxLatencyWith computes MaxLatency with scenario duration and call
+// overrides applied. Memoisation is safe for the same reason as in
+// maxDepthWith: the topology is acyclic.
+func maxLatencyWith(topo *Topology, overrides map[string]Override) (time.Duration, []string) {
+	type result struct {
+		latency time.Duration
+		path    []string
+	}
+
+	memo := make(map[*Operation]result)
+
+	var dfs func(op *Operation) result
+	dfs = func(op *Operation) result {
+		if r, ok := memo[op]; ok {
+			return r
+		}
+
+		duration := op.Duration
+		if ov, ok := overrides[op.Ref]; ok && ov.Duration.Mean > 0 {
+			duration = ov.Duration
+		}
+		if op.Timing == timingReturnEarly {
+			r := result{latency: duration.p99(), path: []string{op.Ref}}
+			memo[op] = r
+			return r
+		}
+		var calls time.Duration
+		var slowest result
+		sequential := op.CallStyle == "sequential"
+		// wait returns how long the caller spends on call and the path
+		// beneath it.
+		wait := func(call Call) result {
+			child := dfs(call.Operation)
+			latency := callLatency(call, child.latency)
+			if sequential {
+				latency = saturatingMul(latency, call.maxCount())
+			} else if op.CallJitter != (Distribution{}) {
+				latency = saturatingAdd(latency, op.CallJitter.p99())
+			}
+			return result{latency: latency, path: child.path}
+		}
+		var branch result
+		for _, call := range op.OneOf {
+			if call.Async {
+				continue
+			}
+			if w := wait(call); w.latency > branch.latency || branch.path == nil {
+				branch = w
+			}
+		}
+		waits := make([]result, 0, len(op.Calls)+1)
+		for _, call := range effectiveCalls(op, overrides) {
+			if !call.Async {
+				waits = append(waits, wait(call))
+			}
+		}
+		if branch.path != nil {
+			waits = append(waits, branch)
+		}
+		for _, w := range waits {
+			if sequential {
+				calls = saturatingAdd(calls, w.latency)
+			} else {
+				calls = max(calls, w.latency)
+			}
+			if w.latency > slowest.latency || slowest.path == nil {
+				slowest = w
+			}
+		}
+
+		latency := saturatingAdd(duration.p99(), calls)
+		if op.Timing == timingWaitCritical {
+			latency = max(duration.p99(), saturatingAdd(duration.p99()/2, calls))
+		}
+		r := result{
+			latency: latency,
+			path:    append([]string{op.Ref}, slowest.path...),
+		}
+		memo[op] = r
+		return r
+	}
+
+	var worst result
+	for _, root := range topo.Roots {
+		if r := dfs(root); r.latency > worst.latency || worst.path == nil {
+			worst = r
+		}
+	}
+	return worst.latency, worst.path
+}
+
+// callLatency returns the worst-case time a caller waits on call when one
+// attempt of the callee takes attempt: every attempt runs to its timeout
+// (or completes) and fails, with the full backoff, capped at maxRetryGap,
+// before each retry.
+func callLatency(call Call, attempt time.Duration) time.Duration {
+	if call.Timeout > 0 {
+		attempt = min(attempt, call.Timeout)
+	}
+	total := attempt
+	for n := range call.Retries {
+		gap := time.Duration(min(float64(call.RetryBackoff)*math.Pow(max(call.RetryBackoffMultiplier, 1), float64(n)), float64(maxRetryGap)))
+		total = saturatingAdd(total, saturatingAdd(gap, attempt))
+	}
+	return total
+}
+
+func saturatingAdd(a, b time.Duration) time.Duration {
+	if a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	return a + b
+}
+
+func saturatingMul(d time.Duration, n int) time.Duration {
+	if n > 0 && d > math.MaxInt64/time.Duration(n) {
+		return math.MaxInt64
+	}
+	return d * time.Duration(n)
+}
+
 // SampleTraces runs the engine n times with an in-memory exporter and measures
 // empirical depth, fan-out, and span count. Observed span counts are bounded by
 // maxSpansPerTrace (or DefaultMaxSpansPerTrace when 0), so for topologies whose
@@ -470,6 +641,22 @@ type setEvaluation struct {
 	sampled   SampleResults
 }
 
+// maxLatencyResult evaluates the max-latency check over every scenario set,
+// reporting the slowest (the earlier set, baseline first, on ties).
+func maxLatencyResult(topo *Topology, sets []ScenarioSet, limit time.Duration) CheckResult {
+	var worst CheckResult
+	for i, set := range sets {
+		latency, path := maxLatencyWith(topo, set.Overrides)
+		if i == 0 || latency > worst.Latency {
+			worst = CheckResult{Latency: latency, Path: path, Scenarios: set.Names}
+		}
+	}
+	worst.Name = CheckNameMaxLatency
+	worst.Pass = worst.Latency <= limit
+	worst.LatencyLimit = limit
+	return worst
+}
+
 func appendPercentileCheckResults(results []CheckResult, evals []setEvaluation, thresholds CheckThresholds) []CheckResult {
 	type spec struct {
 		name       string
@@ -599,7 +786,7 @@ func Check(topo *Topology, opts CheckOptions) []CheckResult {
 	maxFanOutLimit := checkLimit(opts.MaxFanOut, opts.Assertions.MaxFanOut)
 	maxSpansLimit := checkLimit(opts.MaxSpans, opts.Assertions.MaxSpans)
 
-	results := make([]CheckResult, 0, 3+opts.Assertions.percentileCount())
+	results := make([]CheckResult, 0, 5+opts.Assertions.percentileCount())
 
 	depthResult := CheckResult{
 		Name:       CheckNameMaxDepth,
@@ -648,6 +835,21 @@ func Check(topo *Topology, opts CheckOptions) []CheckResult {
 	}
 	results = append(results, spansResult)
 
+	if opts.MaxLatency > 0 {
+		results = append(results, maxLatencyResult(topo, sets, opts.MaxLatency))
+	}
+
+	if opts.MaxCardinality > 0 {
+		cardinality, ref := MaxCardinality(topo)
+		results = append(results, CheckResult{
+			Name:   CheckNameMaxCardinality,
+			Pass:   cardinality <= opts.MaxCardinality,
+			Limit:  opts.MaxCardinality,
+			Actual: cardinality,
+			Ref:    ref,
+		})
+	}
+
 	if opts.Samples > 0 && opts.Assertions.HasPercentile() {
 		results = appendPercentileCheckResults(results, evals, opts.Assertions)
 	}
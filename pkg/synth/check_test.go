@@ -3,6 +3,7 @@ package synth
 import (
 	"fmt"
 	"math"
+	"slices"
 	"testing"
 	"time"
 
@@ -136,6 +137,214 @@ func TestMaxSpans_WithRetries(t *testing.T) {
 	}
 }
 
+// latencyTopology wires ops into one service with root as the only root.
+func latencyTopology(root *Operation, ops ...*Operation) *Topology {
+	s := &Service{Name: "s", Operations: make(map[string]*Operation)}
+	for _, op := range append([]*Operation{root}, ops...) {
+		op.Service = s
+		s.Operations[op.Name] = op
+	}
+	return &Topology{
+		Services: map[string]*Service{"s": s},
+		Roots:    []*Operation{root},
+	}
+}
+
+func fixedDuration(d time.Duration) Distribution {
+	return Distribution{Mean: d}
+}
+
+func TestMaxLatency_LinearChain(t *testing.T) {
+	// A(10ms) → B(20ms) → C(30ms): 60ms
+	opC := &Operation{Name: "C", Ref: "s.C", Duration: fixedDuration(30 * time.Millisecond)}
+	opB := &Operation{Name: "B", Ref: "s.B", Duration: fixedDuration(20 * time.Millisecond), Calls: []Call{{Operation: opC}}}
+	opA := &Operation{Name: "A", Ref: "s.A", Duration: fixedDuration(10 * time.Millisecond), Calls: []Call{{Operation: opB}}}
+
+	latency, path := MaxLatency(latencyTopology(opA, opB, opC))
+	if latency != 60*time.Millisecond {
+		t.Fatalf("expected 60ms, got %s", latency)
+	}
+	if fmt.Sprint(path) != "[s.A s.B s.C]" {
+		t.Fatalf("expected path [s.A s.B s.C], got %v", path)
+	}
+}
+
+func TestMaxLatency_ParallelDiamond(t *testing.T) {
+	// A(10ms) → {B(20ms), C(50ms)} → D(5ms): 10 + max(25, 55) = 65ms
+	opD := &Operation{Name: "D", Ref: "s.D", Duration: fixedDuration(5 * time.Millisecond)}
+	opB := &Operation{Name: "B", Ref: "s.B", Duration: fixedDuration(20 * time.Millisecond), Calls: []Call{{Operation: opD}}}
+	opC := &Operation{Name: "C", Ref: "s.C", Duration: fixedDuration(50 * time.Millisecond), Calls: []Call{{Operation: opD}}}
+	opA := &Operation{Name: "A", Ref: "s.A", Duration: fixedDuration(10 * time.Millisecond), Calls: []Call{{Operation: opB}, {Operation: opC}}}
+
+	latency, path := MaxLatency(latencyTopology(opA, opB, opC, opD))
+	if latency != 65*time.Millisecond {
+		t.Fatalf("expected 65ms, got %s", latency)
+	}
+	if fmt.Sprint(path) != "[s.A s.C s.D]" {
+		t.Fatalf("expected path through the slower branch, got %v", path)
+	}
+
+	// The same calls made one after another add up: 10 + 25 + 55 = 90ms.
+	opA.CallStyle = "sequential"
+	latency, _ = MaxLatency(latencyTopology(opA, opB, opC, opD))
+	if latency != 90*time.Millisecond {
+		t.Fatalf("expected 90ms for sequential calls, got %s", latency)
+	}
+}
+
+func TestMaxLatency_RetriesTimeoutsAndAsync(t *testing.T) {
+	// Each attempt at B is cut off at its 30ms timeout; the two retries wait
+	// 10ms then 20ms first: 10 + 30 + (10+30) + (20+30) = 130ms. The async
+	// call to C is not waited on.
+	opC := &Operation{Name: "C", Ref: "s.C", Duration: fixedDuration(time.Second)}
+	opB := &Operation{Name: "B", Ref: "s.B", Duration: fixedDuration(100 * time.Millisecond)}
+	opA := &Operation{
+		Name: "A", Ref: "s.A", Duration: fixedDuration(10 * time.Millisecond),
+		Calls: []Call{
+			{Operation: opB, Timeout: 30 * time.Millisecond, Retries: 2, RetryBackoff: 10 * time.Millisecond, RetryBackoffMultiplier: 2},
+			{Operation: opC, Async: true},
+		},
+	}
+
+	latency, _ := MaxLatency(latencyTopology(opA, opB, opC))
+	if latency != 130*time.Millisecond {
+		t.Fatalf("expected 130ms, got %s", latency)
+	}
+}
+
+func TestMaxLatency_RetryGapCapped(t *testing.T) {
+	// Nine 20ms attempts at B, and retry gaps of 1s, 1000s, then six capped
+	// at maxRetryGap instead of overflowing.
+	opB := &Operation{Name: "B", Ref: "s.B", Duration: fixedDuration(20 * time.Millisecond)}
+	opA := &Operation{
+		Name: "A", Ref: "s.A", Duration: fixedDuration(10 * time.Millisecond),
+		Calls: []Call{{Operation: opB, Retries: 8, RetryBackoff: time.Second, RetryBackoffMultiplier: 1000}},
+	}
+
+	want := 10*time.Millisecond + 9*20*time.Millisecond + 1001*time.Second + 6*maxRetryGap
+	latency, _ := MaxLatency(latencyTopology(opA, opB))
+	if latency != want {
+		t.Fatalf("expected %s, got %s", want, latency)
+	}
+}
+
+func TestMaxLatency_Timing(t *testing.T) {
+	// A(10ms) calls B(100ms) and, async, C(1s).
+	opC := &Operation{Name: "C", Ref: "s.C", Duration: fixedDuration(time.Second)}
+	opB := &Operation{Name: "B", Ref: "s.B", Duration: fixedDuration(100 * time.Millisecond)}
+	opA := &Operation{
+		Name: "A", Ref: "s.A", Duration: fixedDuration(10 * time.Millisecond),
+		Calls: []Call{{Operation: opB}, {Operation: opC, Async: true}},
+	}
+
+	for _, tt := range []struct {
+		timing string
+		want   time.Duration
+	}{
+		{"", 110 * time.Millisecond},
+		{"wait-all", 110 * time.Millisecond},
+		{"wait-critical", 105 * time.Millisecond},
+		{"return-early", 10 * time.Millisecond},
+	} {
+		opA.Timing = tt.timing
+		latency, _ := MaxLatency(latencyTopology(opA, opB, opC))
+		if latency != tt.want {
+			t.Fatalf("timing %q: expected %s, got %s", tt.timing, tt.want, latency)
+		}
+	}
+}
+
+func TestMaxLatency_UsesP99(t *testing.T) {
+	op := &Operation{Name: "A", Ref: "s.A", Duration: Distribution{Mean: 10 * time.Millisecond, StdDev: time.Millisecond}}
+	latency, _ := MaxLatency(latencyTopology(op))
+	stdDev := float64(time.Millisecond)
+	want := 10*time.Millisecond + time.Duration(z99*stdDev)
+	if latency != want {
+		t.Fatalf("expected %s, got %s", want, latency)
+	}
+
+	dist, err := NewPercentileDistribution(20*time.Millisecond, 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op.Duration = dist
+	latency, _ = MaxLatency(latencyTopology(op))
+	if latency != 200*time.Millisecond {
+		t.Fatalf("expected the p99 target 200ms, got %s", latency)
+	}
+}
+
+func TestCheck_MaxLatency(t *testing.T) {
+	opB := &Operation{Name: "B", Ref: "s.B", Duration: fixedDuration(40 * time.Millisecond)}
+	opA := &Operation{Name: "A", Ref: "s.A", Duration: fixedDuration(20 * time.Millisecond), Calls: []Call{{Operation: opB}}}
+	topo := latencyTopology(opA, opB)
+
+	find := func(results []CheckResult) *CheckResult {
+		for i := range results {
+			if results[i].Name == CheckNameMaxLatency {
+				return &results[i]
+			}
+		}
+		return nil
+	}
+
+	if r := find(Check(topo, CheckOptions{MaxDepth: 10, MaxFanOut: 100, MaxSpans: 10000})); r != nil {
+		t.Fatalf("max-latency should be skipped without a limit, got %+v", r)
+	}
+
+	r := find(Check(topo, CheckOptions{MaxDepth: 10, MaxFanOut: 100, MaxSpans: 10000, MaxLatency: 50 * time.Millisecond}))
+	if r == nil {
+		t.Fatal("expected a max-latency result")
+	}
+	if r.Pass || r.Latency != 60*time.Millisecond || r.LatencyLimit != 50*time.Millisecond {
+		t.Fatalf("expected a failing 60ms result against a 50ms limit, got %+v", r)
+	}
+}
+
+func TestMaxSpans_CountDistributionUsesBound(t *testing.T) {
+	dist, err := ParseCountDistribution("uniform(min=1, max=4)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opB := &Operation{Name: "B", Ref: "s.B"}
+	opA := &Operation{Name: "A", Ref: "s.A", Calls: []Call{{Operation: opB, CountDist: dist, Retries: 1}}}
+	topo := latencyTopology(opA, opB)
+
+	if spans, _ := MaxSpans(topo); spans != 9 {
+		t.Fatalf("expected 1 + 4 calls x 2 attempts = 9 spans, got %d", spans)
+	}
+	if fanOut, _ := MaxFanOut(topo); fanOut != 8 {
+		t.Fatalf("expected fan-out 8, got %d", fanOut)
+	}
+}
+
+func TestCheck_OneOfCountsLargestBranch(t *testing.T) {
+	// A calls C once, then one of B (count 3, 30ms) or C (count 1, 10ms)
+	opB := &Operation{Name: "B", Ref: "s.B", Duration: fixedDuration(30 * time.Millisecond)}
+	opC := &Operation{Name: "C", Ref: "s.C", Duration: fixedDuration(10 * time.Millisecond)}
+	opA := &Operation{Name: "A", Ref: "s.A", Duration: fixedDuration(5 * time.Millisecond), CallStyle: "sequential",
+		Calls: []Call{{Operation: opC}},
+		OneOf: []Call{{Operation: opB, Count: 3, Weight: 1}, {Operation: opC, Weight: 9}}}
+	topo := latencyTopology(opA, opB, opC)
+
+	if spans, _ := MaxSpans(topo); spans != 5 {
+		t.Fatalf("expected 1 + 1 + 3 = 5 spans, got %d", spans)
+	}
+	if fanOut, _ := MaxFanOut(topo); fanOut != 4 {
+		t.Fatalf("expected fan-out 4, got %d", fanOut)
+	}
+	if depth, _ := MaxDepth(topo); depth != 1 {
+		t.Fatalf("expected depth 1, got %d", depth)
+	}
+	latency, path := MaxLatency(topo)
+	if latency != 105*time.Millisecond {
+		t.Fatalf("expected 5ms + 10ms + 3x30ms = 105ms, got %v", latency)
+	}
+	if want := []string{"s.A", "s.B"}; !slices.Equal(path, want) {
+		t.Fatalf("expected path %v, got %v", want, path)
+	}
+}
+
 func TestMaxDepth_SingleNode(t *testing.T) {
 	s := &Service{Name: "s", Operations: make(map[string]*Operation)}
 	op := &Operation{Service: s, Name: "op", Ref: "s.op"}
@@ -3,12 +3,16 @@
 package synth
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"reflect"
 	"slices"
 	"strconv"
 	"strings"
@@ -20,6 +24,9 @@ import (
 
 const maxSourceBytes = 10 << 20 // 10 MB
 
+// StdinSource is the source name that reads a topology from standard input.
+const StdinSource = "-"
+
 // CurrentVersion is the supported schema version for synth topology configs.
 const CurrentVersion = 1
 
@@ -86,78 +93,253 @@ const ModeReplay = "replay"
 
 // Config is the top-level YAML configuration for a synthetic topology.
 type Config struct {
-	Version   int              `yaml:"version"`
-	Mode      string           `yaml:"mode,omitempty"`
-	Recording string           `yaml:"recording,omitempty"`
-	Services  []ServiceConfig  `yaml:"-"`
-	Traffic   TrafficConfig    `yaml:"traffic"`
-	Scenarios []ScenarioConfig `yaml:"scenarios,omitempty"`
+	Version      int              `yaml:"version"`
+	Mode         string           `yaml:"mode,omitempty"`
+	Recording    string           `yaml:"recording,omitempty"`
+	ScopeName    string           `yaml:"scope_name,omitempty"`
+	ScopeVersion string           `yaml:"scope_version,omitempty"`
+	Services     []ServiceConfig  `yaml:"-"`
+	Traffic      TrafficConfig    `yaml:"traffic"`
+	Scenarios    []ScenarioConfig `yaml:"scenarios,omitempty"`
+	// TraceAttributes are generated once per trace at the root and attached
+	// to every span in that trace.
+	TraceAttributes map[string]AttributeValueConfig `yaml:"trace_attributes,omitempty"`
+	// TraceState holds W3C tracestate entries set on the span context of
+	// every trace's root span and inherited by its descendants.
+	TraceState map[string]string `yaml:"tracestate,omitempty"`
+	// Observers are span observers built for each run from the registered
+	// observer types (see RegisterObserver).
+	Observers []ObserverConfig `yaml:"observers,omitempty"`
 }
 
 // rawConfig mirrors Config but uses a map for services to match the YAML structure.
+// Include lists further files merged in by LoadConfig. Templates holds
+// reusable operation fields applied by resolveTemplates. Vars holds values
+// substituted for {{ vars.name }} references by interpolateVars.
 type rawConfig struct {
-	Version   *int                        `yaml:"version"`
-	Mode      string                      `yaml:"mode,omitempty"`
-	Recording string                      `yaml:"recording,omitempty"`
-	Services  map[string]rawServiceConfig `yaml:"services"`
-	Traffic   TrafficConfig               `yaml:"traffic"`
-	Scenarios []ScenarioConfig            `yaml:"scenarios,omitempty"`
+	Include      []string                      `yaml:"include,omitempty"`
+	Version      *int                          `yaml:"version"`
+	Vars         map[string]any                `yaml:"vars,omitempty"`
+	Mode         string                        `yaml:"mode,omitempty"`
+	Recording    string                        `yaml:"recording,omitempty"`
+	ScopeName    string                        `yaml:"scope_name,omitempty"`
+	ScopeVersion string                        `yaml:"scope_version,omitempty"`
+	Templates    map[string]rawOperationConfig `yaml:"templates,omitempty"`
+	Services     map[string]rawServiceConfig   `yaml:"services"`
+	Traffic      TrafficConfig                 `yaml:"traffic"`
+	Scenarios    []ScenarioConfig              `yaml:"scenarios,omitempty"`
+	// TraceAttributes mirrors Config.TraceAttributes.
+	TraceAttributes map[string]AttributeValueConfig `yaml:"trace_attributes,omitempty"`
+	// TraceState mirrors Config.TraceState.
+	TraceState map[string]string `yaml:"tracestate,omitempty"`
+	// Observers mirrors Config.Observers.
+	Observers []ObserverConfig `yaml:"observers,omitempty"`
 }
 
 // rawServiceConfig is the YAML representation of a service before normalisation.
 type rawServiceConfig struct {
-	ResourceAttributes  map[string]string             `yaml:"resource_attributes,omitempty"`
-	Attributes          map[string]string             `yaml:"attributes,omitempty"`
-	Baggage             map[string]string             `yaml:"baggage,omitempty"`
-	BaggageAsAttributes *bool                         `yaml:"baggage_as_attributes,omitempty"`
-	Metrics             []MetricConfig                `yaml:"metrics,omitempty"`
-	Logs                []LogConfig                   `yaml:"logs,omitempty"`
-	Operations          map[string]rawOperationConfig `yaml:"operations"`
+	ResourceAttributes  map[string]string               `yaml:"resource_attributes,omitempty"`
+	Attributes          map[string]string               `yaml:"attributes,omitempty"`
+	DefaultAttributes   map[string]AttributeValueConfig `yaml:"default_attributes,omitempty"`
+	Baggage             map[string]string               `yaml:"baggage,omitempty"`
+	BaggageAsAttributes *bool                           `yaml:"baggage_as_attributes,omitempty"`
+	TraceState          map[string]string               `yaml:"tracestate,omitempty"`
+	Regions             map[string]int                  `yaml:"regions,omitempty"`
+	Metrics             []MetricConfig                  `yaml:"metrics,omitempty"`
+	Logs                []LogConfig                     `yaml:"logs,omitempty"`
+	SLO                 *SLOConfig                      `yaml:"slo,omitempty"`
+	Flap                *FlapConfig                     `yaml:"flap,omitempty"`
+	Operations          map[string]rawOperationConfig   `yaml:"operations"`
+	ScopeName           string                          `yaml:"scope_name,omitempty"`
+	ScopeVersion        string                          `yaml:"scope_version,omitempty"`
 }
 
 // CallConfig describes a downstream call in the YAML DSL.
 // Supports both simple string form ("service.op") and rich mapping form.
 type CallConfig struct {
-	Target       string  `yaml:"target"`
-	Probability  float64 `yaml:"probability,omitempty"`
-	Condition    string  `yaml:"condition,omitempty"`
-	Count        int     `yaml:"count,omitempty"`
-	Timeout      string  `yaml:"timeout,omitempty"`
-	Retries      int     `yaml:"retries,omitempty"`
-	RetryBackoff string  `yaml:"retry_backoff,omitempty"`
-	Async        bool    `yaml:"async,omitempty"`
-	Producer     bool    `yaml:"producer,omitempty"`
+	Target                 string  `yaml:"target"`
+	Probability            float64 `yaml:"probability,omitempty"`
+	Condition              string  `yaml:"condition,omitempty"`
+	Count                  int     `yaml:"count,omitempty"`
+	CountDistribution      string  `yaml:"count_distribution,omitempty"`
+	ErrorRate              string  `yaml:"error_rate,omitempty"`
+	Timeout                string  `yaml:"timeout,omitempty"`
+	Retries                int     `yaml:"retries,omitempty"`
+	RetryBackoff           string  `yaml:"retry_backoff,omitempty"`
+	RetryBackoffMultiplier float64 `yaml:"retry_backoff_multiplier,omitempty"`
+	RetryJitter            float64 `yaml:"retry_jitter,omitempty"`
+	HedgeAfter             string  `yaml:"hedge_after,omitempty"`
+	Async                  bool    `yaml:"async,omitempty"`
+	Producer               bool    `yaml:"producer,omitempty"`
+	Weight                 int     `yaml:"weight,omitempty"`
+	// Disabled leaves the call out of the topology, as if it were not listed.
+	Disabled bool `yaml:"disabled,omitempty"`
+	// Attributes are set on the callee's span only when it is reached
+	// through this call, winning over the callee's own attributes.
+	Attributes map[string]AttributeValueConfig `yaml:"attributes,omitempty"`
+	// ProbabilityDecay scales Probability once per level of trace depth. It
+	// is set by the mapping form, probability: {base: 0.8, decay: 0.5};
+	// zero leaves the probability the same at every depth.
+	ProbabilityDecay float64 `yaml:"-"`
+}
+
+// probabilityCurveConfig is the mapping form of a call's probability.
+type probabilityCurveConfig struct {
+	Base  *float64 `yaml:"base"`
+	Decay *float64 `yaml:"decay"`
 }
 
 // UnmarshalYAML handles both scalar string and mapping forms for call config.
-func (c *CallConfig) UnmarshalYAML(unmarshal func(any) error) error {
-	var scalar string
-	if err := unmarshal(&scalar); err == nil {
-		c.Target = scalar
+// probability may be a number or a {base, decay} curve.
+func (c *CallConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		c.Target = value.Value
 		return nil
 	}
 
+	// Decode a probability curve separately, leaving the rest of the mapping
+	// to the plain decode below. Merge keys are expanded first so a curve
+	// inherited through <<: *anchor is found too.
+	value = expandMergeKeys(value)
+	var curve *probabilityCurveConfig
+	mapping := *value
+	if value.Kind == yaml.MappingNode {
+		mapping.Content = make([]*yaml.Node, 0, len(value.Content))
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			key, val := value.Content[i], value.Content[i+1]
+			if key.Value != "probability" || val.Kind != yaml.MappingNode {
+				mapping.Content = append(mapping.Content, key, val)
+				continue
+			}
+			curve = &probabilityCurveConfig{}
+			if err := val.Decode(curve); err != nil {
+				return fmt.Errorf("call: probability: %w", err)
+			}
+			if curve.Base == nil || curve.Decay == nil {
+				return fmt.Errorf("call: probability mapping needs both base and decay, e.g. {base: 0.8, decay: 0.5}")
+			}
+			if *curve.Decay <= 0 {
+				return fmt.Errorf("call: probability decay must be greater than 0, got %v", *curve.Decay)
+			}
+		}
+	}
+
 	type plain CallConfig
 	var p plain
-	if err := unmarshal(&p); err != nil {
+	if err := mapping.Decode(&p); err != nil {
 		return fmt.Errorf("call: expected string or mapping with target: %w", err)
 	}
 	*c = CallConfig(p)
+	if curve != nil {
+		c.Probability = *curve.Base
+		c.ProbabilityDecay = *curve.Decay
+	}
 	return nil
 }
 
+// expandMergeKeys returns a copy of a mapping node with its YAML merge keys
+// (<<: *anchor, or <<: [*a, *b]) replaced by the entries they contribute, for
+// unmarshalers that inspect keys before decoding. As in a plain decode, the
+// mapping's own keys win over merged ones, and earlier merged mappings win
+// over later ones. Other nodes are returned unchanged.
+func expandMergeKeys(value *yaml.Node) *yaml.Node {
+	if value.Kind != yaml.MappingNode {
+		return value
+	}
+	var own, sources []*yaml.Node
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key, val := value.Content[i], value.Content[i+1]
+		if key.Kind != yaml.ScalarNode || key.ShortTag() != "!!merge" {
+			own = append(own, key, val)
+			continue
+		}
+		if val.Kind == yaml.SequenceNode {
+			sources = append(sources, val.Content...)
+		} else {
+			sources = append(sources, val)
+		}
+	}
+	if len(sources) == 0 {
+		return value
+	}
+
+	seen := make(map[string]bool, len(own)/2)
+	for i := 0; i < len(own); i += 2 {
+		seen[own[i].Value] = true
+	}
+	content := own
+	for _, src := range sources {
+		if src.Kind == yaml.AliasNode {
+			src = src.Alias
+		}
+		if src.Kind != yaml.MappingNode {
+			return value // leave the invalid merge for the decode to reject
+		}
+		src = expandMergeKeys(src)
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			if key := src.Content[i]; !seen[key.Value] {
+				seen[key.Value] = true
+				content = append(content, key, src.Content[i+1])
+			}
+		}
+	}
+	expanded := *value
+	expanded.Content = content
+	return &expanded
+}
+
 // BackpressureConfig describes backpressure behaviour for an operation.
+// The default model multiplies duration and adds error rate past a latency
+// threshold; QueueModel "mm1" instead adds an M/M/1 queue wait that grows
+// with the number of requests in flight.
 type BackpressureConfig struct {
-	LatencyThreshold   string  `yaml:"latency_threshold"`
+	LatencyThreshold   string  `yaml:"latency_threshold,omitempty"`
 	DurationMultiplier float64 `yaml:"duration_multiplier,omitempty"`
 	ErrorRateAdd       string  `yaml:"error_rate_add,omitempty"`
+	QueueModel         string  `yaml:"queue_model,omitempty"`
 }
 
 // CircuitBreakerConfig describes circuit breaker behaviour for an operation.
+// HalfOpenMaxProbes caps concurrent probe requests while half-open; nil
+// leaves probes uncapped.
 type CircuitBreakerConfig struct {
-	FailureThreshold int    `yaml:"failure_threshold"`
-	Window           string `yaml:"window"`
-	Cooldown         string `yaml:"cooldown"`
+	FailureThreshold  int    `yaml:"failure_threshold"`
+	Window            string `yaml:"window"`
+	Cooldown          string `yaml:"cooldown"`
+	HalfOpenMaxProbes *int   `yaml:"half_open_max_probes,omitempty"`
+}
+
+// RateLimitConfig caps the traffic an operation accepts. Requests beyond
+// MaxRate or MaxConcurrency are rejected without calling downstream.
+type RateLimitConfig struct {
+	MaxRate        string `yaml:"max_rate,omitempty"`
+	MaxConcurrency int    `yaml:"max_concurrency,omitempty"`
+}
+
+// ColdStartConfig adds Latency to the first Invocations requests an
+// operation serves, modelling warmup such as JIT compilation or connection
+// setup.
+type ColdStartConfig struct {
+	Latency     string `yaml:"latency"`
+	Invocations int    `yaml:"invocations"`
+}
+
+// CacheConfig describes a cache in front of an operation's downstream calls.
+// On a hit the calls listed in SkipCalls are not made.
+type CacheConfig struct {
+	HitRate   float64  `yaml:"hit_rate"`
+	SkipCalls []string `yaml:"skip_calls,omitempty"`
+}
+
+// ErrorTypeConfig describes a named category of an operation's own errors.
+// When the operation errors, one category is chosen by weight and its
+// message and attributes are applied to the span. A nil Weight defaults to
+// 1, and Message to the operation's ErrorMessage or else the category name.
+type ErrorTypeConfig struct {
+	Name       string                          `yaml:"name"`
+	Weight     *int                            `yaml:"weight,omitempty"`
+	Message    string                          `yaml:"message,omitempty"`
+	Attributes map[string]AttributeValueConfig `yaml:"attributes,omitempty"`
 }
 
 // EventConfig describes a span event emitted during an operation.
@@ -224,67 +406,186 @@ func (lc *LinkConfig) UnmarshalYAML(value *yaml.Node) error {
 
 // rawOperationConfig is the YAML representation of an operation before normalisation.
 type rawOperationConfig struct {
+	Template            string                          `yaml:"template,omitempty"`
 	Domain              string                          `yaml:"domain,omitempty"`
-	Duration            string                          `yaml:"duration"`
+	Domains             []string                        `yaml:"domains,omitempty"`
+	Duration            durationConfig                  `yaml:"duration"`
 	ErrorRate           string                          `yaml:"error_rate,omitempty"`
+	ErrorRateAtPeak     float64                         `yaml:"error_rate_at_peak,omitempty"`
+	ErrorTypes          []ErrorTypeConfig               `yaml:"error_types,omitempty"`
+	ErrorMessage        string                          `yaml:"error_message,omitempty"`
+	SwallowErrors       bool                            `yaml:"swallow_errors,omitempty"`
+	Disabled            bool                            `yaml:"disabled,omitempty"`
+	SpanName            string                          `yaml:"span_name,omitempty"`
 	Calls               []CallConfig                    `yaml:"calls,omitempty"`
+	OneOf               []CallConfig                    `yaml:"one_of,omitempty"`
 	CallStyle           string                          `yaml:"call_style,omitempty"`
+	CallJitter          durationConfig                  `yaml:"call_jitter,omitempty"`
+	Timing              string                          `yaml:"timing,omitempty"`
+	SlowThreshold       string                          `yaml:"slow_threshold,omitempty"`
+	SlowStatus          string                          `yaml:"slow_status,omitempty"`
 	Attributes          map[string]AttributeValueConfig `yaml:"attributes,omitempty"`
 	Baggage             map[string]string               `yaml:"baggage,omitempty"`
 	BaggageAsAttributes *bool                           `yaml:"baggage_as_attributes,omitempty"`
+	GeneratedBaggage    map[string]AttributeValueConfig `yaml:"generated_baggage,omitempty"`
 	Events              []EventConfig                   `yaml:"events,omitempty"`
 	Links               []LinkConfig                    `yaml:"links,omitempty"`
 	Metrics             []MetricConfig                  `yaml:"metrics,omitempty"`
 	Logs                []LogConfig                     `yaml:"logs,omitempty"`
+	Concurrency         int                             `yaml:"concurrency,omitempty"`
 	QueueDepth          int                             `yaml:"queue_depth,omitempty"`
 	Backpressure        *BackpressureConfig             `yaml:"backpressure,omitempty"`
 	CircuitBreaker      *CircuitBreakerConfig           `yaml:"circuit_breaker,omitempty"`
+	RateLimit           *RateLimitConfig                `yaml:"rate_limit,omitempty"`
+	ColdStart           *ColdStartConfig                `yaml:"cold_start,omitempty"`
+	Cache               *CacheConfig                    `yaml:"cache,omitempty"`
+	Traffic             *TrafficConfig                  `yaml:"traffic,omitempty"`
+}
+
+// durationConfig is an operation's duration in the YAML DSL: a distribution
+// string, or a mapping of percentile targets such as {p50: 20ms, p99: 200ms},
+// which is normalised to the string form "p50=20ms p99=200ms". The mapping
+// may also give min and max clamps, normalised to a trailing "[min=..., max=...]".
+type durationConfig string
+
+// UnmarshalYAML handles both the scalar and the percentile mapping forms.
+func (d *durationConfig) UnmarshalYAML(unmarshal func(any) error) error {
+	var scalar string
+	if err := unmarshal(&scalar); err == nil {
+		*d = durationConfig(scalar)
+		return nil
+	}
+
+	var targets map[string]string
+	if err := unmarshal(&targets); err != nil {
+		return fmt.Errorf("duration: expected a string or a mapping of percentiles, e.g. {p50: 20ms, p99: 200ms}: %w", err)
+	}
+	var parts, clamps []string
+	for _, k := range sortedKeys(targets) {
+		if k == "min" || k == "max" {
+			clamps = append(clamps, k+"="+targets[k])
+			continue
+		}
+		parts = append(parts, k+"="+targets[k])
+	}
+	s := strings.Join(parts, " ")
+	if len(clamps) > 0 {
+		// sortedKeys puts max before min; the DSL reads min first.
+		slices.Reverse(clamps)
+		s += " [" + strings.Join(clamps, ", ") + "]"
+	}
+	*d = durationConfig(s)
+	return nil
 }
 
 // ServiceConfig describes a service in the topology.
+// DefaultAttributes are span attribute generators applied to every operation
+// of the service; an operation's own attributes win on key conflicts.
+// Regions weights the regions the service is deployed in; each trace picks
+// one per service and records it as cloud.region on the service's spans.
 type ServiceConfig struct {
 	Name                string
 	ResourceAttributes  map[string]string
 	Attributes          map[string]string
+	DefaultAttributes   map[string]AttributeValueConfig
 	Baggage             map[string]string
 	BaggageAsAttributes *bool
+	TraceState          map[string]string
+	Regions             map[string]int
 	Metrics             []MetricConfig
 	Logs                []LogConfig
+	SLO                 *SLOConfig
+	Flap                *FlapConfig
 	Operations          []OperationConfig
+	ScopeName           string
+	ScopeVersion        string
+}
+
+// SLOConfig declares a service's availability objective. Target is the
+// fraction of spans that should succeed, such as 0.999, measured over
+// Window (default 5m).
+type SLOConfig struct {
+	Target float64 `yaml:"target"`
+	Window string  `yaml:"window,omitempty"`
 }
 
 // OperationConfig describes an operation within a service.
 type OperationConfig struct {
 	Name                string
 	Domain              string
+	Domains             []string
 	Duration            string
 	ErrorRate           string
+	ErrorRateAtPeak     float64
+	ErrorTypes          []ErrorTypeConfig
+	ErrorMessage        string
+	SwallowErrors       bool
+	SpanName            string
+	Disabled            bool
 	Calls               []CallConfig
+	OneOf               []CallConfig
 	CallStyle           string
+	CallJitter          string
+	Timing              string
+	SlowThreshold       string
+	SlowStatus          string
 	Attributes          map[string]AttributeValueConfig
 	Baggage             map[string]string
 	BaggageAsAttributes *bool
+	GeneratedBaggage    map[string]AttributeValueConfig
 	Events              []EventConfig
 	Links               []LinkConfig
 	Metrics             []MetricConfig
 	Logs                []LogConfig
+	Concurrency         int
 	QueueDepth          int
 	Backpressure        *BackpressureConfig
 	CircuitBreaker      *CircuitBreakerConfig
+	RateLimit           *RateLimitConfig
+	ColdStart           *ColdStartConfig
+	Cache               *CacheConfig
+	Traffic             *TrafficConfig
 }
 
-// TrafficConfig describes the traffic generation pattern.
+// DomainNames returns the semconv domains the operation inherits attributes
+// from, in merge order: the singular domain first, then each of domains.
+func (o OperationConfig) DomainNames() []string {
+	if o.Domain == "" {
+		return o.Domains
+	}
+	return append([]string{o.Domain}, o.Domains...)
+}
+
+// TrafficConfig describes the traffic generation pattern. Overlays are
+// independent traffic sources whose rates add to the base pattern's.
 type TrafficConfig struct {
-	Rate             string          `yaml:"rate"`
-	Pattern          string          `yaml:"pattern,omitempty"`
-	BurstMultiplier  float64         `yaml:"burst_multiplier,omitempty"`
-	BurstInterval    string          `yaml:"burst_interval,omitempty"`
-	BurstDuration    string          `yaml:"burst_duration,omitempty"`
-	PeakMultiplier   float64         `yaml:"peak_multiplier,omitempty"`
-	TroughMultiplier float64         `yaml:"trough_multiplier,omitempty"`
-	Period           string          `yaml:"period,omitempty"`
-	Segments         []SegmentConfig `yaml:"segments,omitempty"`
-	Overlay          *TrafficConfig  `yaml:"overlay,omitempty"`
+	Rate                string          `yaml:"rate"`
+	Pattern             string          `yaml:"pattern,omitempty"`
+	BurstMultiplier     float64         `yaml:"burst_multiplier,omitempty"`
+	BurstInterval       string          `yaml:"burst_interval,omitempty"`
+	BurstDuration       string          `yaml:"burst_duration,omitempty"`
+	BurstIntervalJitter string          `yaml:"burst_interval_jitter,omitempty"`
+	PeakMultiplier      float64         `yaml:"peak_multiplier,omitempty"`
+	TroughMultiplier    float64         `yaml:"trough_multiplier,omitempty"`
+	Period              string          `yaml:"period,omitempty"`
+	Segments            []SegmentConfig `yaml:"segments,omitempty"`
+	Overlays            []TrafficConfig `yaml:"overlays,omitempty"`
+}
+
+// UnmarshalYAML decodes a traffic config, rejecting the single overlay
+// mapping that overlays replaced rather than silently ignoring it.
+func (tc *TrafficConfig) UnmarshalYAML(unmarshal func(any) error) error {
+	type plain TrafficConfig
+	if err := unmarshal((*plain)(tc)); err != nil {
+		return err
+	}
+	var keys map[string]any
+	if err := unmarshal(&keys); err == nil {
+		if _, ok := keys["overlay"]; ok {
+			return fmt.Errorf("traffic: overlay has been replaced by overlays, a list of traffic configs whose rates add to the base rate")
+		}
+	}
+	return nil
 }
 
 // SegmentConfig describes a time-bounded rate segment in a custom traffic pattern.
@@ -301,6 +602,49 @@ type ScenarioConfig struct {
 	Priority int                       `yaml:"priority,omitempty"`
 	Override map[string]OverrideConfig `yaml:"override,omitempty"`
 	Traffic  *TrafficConfig            `yaml:"traffic,omitempty"`
+	// TraceAttributes are added to the top-level trace_attributes for traces
+	// started while the scenario is active, replacing any with the same key.
+	TraceAttributes map[string]AttributeValueConfig `yaml:"trace_attributes,omitempty"`
+	// Labels are fixed attributes added to every span emitted while the
+	// scenario is active, such as an incident ID to correlate with.
+	Labels map[string]string `yaml:"labels,omitempty"`
+	// Match applies overrides to every operation of a service or semconv
+	// domain. Entries in Override for the same operation win field by field.
+	Match []MatchOverrideConfig `yaml:"match,omitempty"`
+	// Spike multiplies the traffic rate from At for the spike's duration,
+	// which replaces Duration as the scenario's window.
+	Spike *SpikeConfig `yaml:"spike,omitempty"`
+	// Drain takes the named services out of traffic for the window, as for
+	// planned maintenance: their roots start no traces and they emit no
+	// spans. Calls to them are skipped, or fail at once when DrainMode is
+	// "error".
+	Drain     []string `yaml:"drain,omitempty"`
+	DrainMode string   `yaml:"drain_mode,omitempty"`
+}
+
+// SpikeConfig is a one-time traffic spike: the rate, whether from the base
+// pattern or a scenario's traffic override, is multiplied by Multiplier for
+// Duration and then returns to baseline.
+type SpikeConfig struct {
+	Multiplier float64 `yaml:"multiplier"`
+	Duration   string  `yaml:"duration"`
+}
+
+// MatchOverrideConfig selects operations by Service or Domain (exactly one
+// must be set) and applies the inline override fields to each of them.
+// Call changes and metrics need an explicit service.operation override.
+type MatchOverrideConfig struct {
+	Service        string `yaml:"service,omitempty"`
+	Domain         string `yaml:"domain,omitempty"`
+	OverrideConfig `yaml:",inline"`
+}
+
+// selector describes the match for error messages, e.g. `service "db"`.
+func (m MatchOverrideConfig) selector() string {
+	if m.Service != "" {
+		return fmt.Sprintf("service %q", m.Service)
+	}
+	return fmt.Sprintf("domain %q", m.Domain)
 }
 
 // OverrideConfig holds per-operation or per-service overrides within a scenario.
@@ -314,6 +658,9 @@ type OverrideConfig struct {
 	RemoveCalls []RemoveCallConfig              `yaml:"remove_calls,omitempty"`
 	Metrics     map[string]MetricOverrideConfig `yaml:"metrics,omitempty"`
 	Logs        *LogOverrideConfig              `yaml:"logs,omitempty"`
+	// ErrorRateMultiply scales the operation's base error rate, clamped to
+	// at most 100%. An absolute error_rate from any active scenario wins.
+	ErrorRateMultiply float64 `yaml:"error_rate_multiply,omitempty"`
 }
 
 // LogOverrideConfig modifies topology log output during a scenario window.
@@ -354,14 +701,55 @@ func (r *RemoveCallConfig) UnmarshalYAML(unmarshal func(any) error) error {
 	return nil
 }
 
-// readSource fetches topology YAML from a URL or reads it from a local file.
-// URL fetches have a 10-second timeout and a 10 MB response body limit.
+// FetchOptions limits how sources given as URLs are fetched.
+type FetchOptions struct {
+	Timeout      time.Duration // for the whole request, including redirects
+	MaxBytes     int64         // response body limit
+	MaxRedirects int           // redirects followed before giving up
+}
+
+// DefaultFetchOptions returns the limits used unless LoadConfig is given
+// options: a 10-second timeout, a 10 MB response body limit, and up to 3
+// redirects.
+func DefaultFetchOptions() FetchOptions {
+	return FetchOptions{Timeout: 10 * time.Second, MaxBytes: maxSourceBytes, MaxRedirects: 3}
+}
+
+// LoadOption adjusts how LoadConfig fetches a topology and its includes.
+type LoadOption func(*FetchOptions)
+
+// WithFetchTimeout sets the timeout for each URL fetch.
+func WithFetchTimeout(d time.Duration) LoadOption {
+	return func(o *FetchOptions) { o.Timeout = d }
+}
+
+// WithFetchMaxBytes sets the response body limit for each URL fetch.
+func WithFetchMaxBytes(n int64) LoadOption {
+	return func(o *FetchOptions) { o.MaxBytes = n }
+}
+
+// WithFetchMaxRedirects sets how many redirects a URL fetch follows.
+func WithFetchMaxRedirects(n int) LoadOption {
+	return func(o *FetchOptions) { o.MaxRedirects = n }
+}
+
+// readSource fetches topology YAML from a URL or reads it from a local file
+// or stdin, with the default fetch limits.
 func readSource(source string) ([]byte, error) {
-	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+	return fetchSource(source, DefaultFetchOptions())
+}
+
+// fetchSource fetches source from a URL within the limits of fetch, or reads
+// it from a local file or, for StdinSource, from stdin.
+func fetchSource(source string, fetch FetchOptions) ([]byte, error) {
+	if source == StdinSource {
+		return readStdin(os.Stdin)
+	}
+	if isURL(source) {
 		client := &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: fetch.Timeout,
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if len(via) >= 3 {
+				if len(via) > fetch.MaxRedirects {
 					return fmt.Errorf("too many redirects")
 				}
 				return nil
@@ -369,35 +757,51 @@ func readSource(source string) ([]byte, error) {
 		}
 		resp, err := client.Get(source) //nolint:gosec // user-supplied URL is expected
 		if err != nil {
-			return nil, fmt.Errorf("fetching %s: %w", source, unwrapHTTPError(err))
+			return nil, fmt.Errorf("fetching %s: %w", source, unwrapHTTPError(err, fetch.Timeout))
 		}
 		defer resp.Body.Close()
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			return nil, fmt.Errorf("fetching %s: HTTP %d", source, resp.StatusCode)
 		}
-		data, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceBytes+1))
+		data, err := io.ReadAll(io.LimitReader(resp.Body, fetch.MaxBytes+1))
 		if err != nil {
 			return nil, fmt.Errorf("reading URL body: %w", err)
 		}
-		if int64(len(data)) > maxSourceBytes {
-			return nil, fmt.Errorf("fetching %s: response body exceeds %d bytes", source, maxSourceBytes)
+		if int64(len(data)) > fetch.MaxBytes {
+			return nil, fmt.Errorf("fetching %s: response body exceeds %d bytes", source, fetch.MaxBytes)
 		}
 		return data, nil
 	}
 	return os.ReadFile(source) //nolint:gosec // user-supplied config path is expected
 }
 
+// readStdin reads a topology piped to stdin, up to maxSourceBytes.
+func readStdin(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSourceBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading stdin: %w", err)
+	}
+	if len(data) > maxSourceBytes {
+		return nil, fmt.Errorf("stdin exceeds %d bytes", maxSourceBytes)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("stdin is empty; pipe a topology in, e.g. cat topology.yaml | motel validate -")
+	}
+	return data, nil
+}
+
 // unwrapHTTPError extracts a human-readable error from nested http/url/net errors.
 // Go's http.Client wraps errors as *url.Error → *net.OpError → syscall error,
 // producing messages like: Get "http://...": dial tcp [::1]:1: connect: connection refused.
-// This unwraps to the innermost message (e.g. "connection refused").
-func unwrapHTTPError(err error) error {
+// This unwraps to the innermost message (e.g. "connection refused"), and
+// reports a timeout as the timeout that was exceeded.
+func unwrapHTTPError(err error, timeout time.Duration) error {
 	ue, ok := err.(*url.Error) //nolint:errorlint // deliberate type switch through layers
 	if !ok {
 		return err
 	}
 	if ue.Timeout() {
-		return fmt.Errorf("timed out after 10s")
+		return fmt.Errorf("timed out after %s", timeout)
 	}
 	err = ue.Err
 	if oe, ok := err.(*net.OpError); ok { //nolint:errorlint // deliberate type switch through layers
@@ -408,24 +812,56 @@ func unwrapHTTPError(err error) error {
 
 // ParseConfig parses YAML topology data and normalizes it into a Config.
 func ParseConfig(data []byte) (*Config, error) {
+	raw, err := parseRawConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw.Include) > 0 {
+		return nil, fmt.Errorf("include is only supported when loading a config from a file or URL")
+	}
+	return configFromRaw(raw)
+}
+
+// parseRawConfig unmarshals YAML into a rawConfig without normalising it.
+func parseRawConfig(data []byte) (*rawConfig, error) {
 	var raw rawConfig
 	if err := yaml.Unmarshal(data, &raw); err != nil {
 		return nil, fmt.Errorf("parsing config: %w", err)
 	}
+	return &raw, nil
+}
 
+// configFromRaw checks the schema version, interpolates vars, applies
+// operation templates, and converts map-based services into the ordered
+// Config representation.
+func configFromRaw(raw *rawConfig) (*Config, error) {
 	if raw.Version == nil {
 		return nil, fmt.Errorf("missing required field: version (e.g. 'version: 1')")
 	}
 	if *raw.Version != CurrentVersion {
 		return nil, fmt.Errorf("unsupported config version %d (supported: %d)", *raw.Version, CurrentVersion)
 	}
+	if err := interpolateVars(raw); err != nil {
+		return nil, err
+	}
+	if err := resolveAttributeFiles(reflect.ValueOf(raw).Elem()); err != nil {
+		return nil, err
+	}
+	if err := resolveTemplates(raw); err != nil {
+		return nil, err
+	}
 
 	cfg := &Config{
-		Version:   *raw.Version,
-		Mode:      raw.Mode,
-		Recording: raw.Recording,
-		Traffic:   raw.Traffic,
-		Scenarios: raw.Scenarios,
+		Version:         *raw.Version,
+		Mode:            raw.Mode,
+		Recording:       raw.Recording,
+		ScopeName:       raw.ScopeName,
+		ScopeVersion:    raw.ScopeVersion,
+		Traffic:         raw.Traffic,
+		Scenarios:       raw.Scenarios,
+		TraceAttributes: raw.TraceAttributes,
+		TraceState:      raw.TraceState,
+		Observers:       raw.Observers,
 	}
 
 	// Convert map-based services into ordered slice (sorted for determinism)
@@ -441,10 +877,17 @@ func ParseConfig(data []byte) (*Config, error) {
 			Name:                name,
 			ResourceAttributes:  rawSvc.ResourceAttributes,
 			Attributes:          rawSvc.Attributes,
+			DefaultAttributes:   rawSvc.DefaultAttributes,
 			Baggage:             rawSvc.Baggage,
 			BaggageAsAttributes: rawSvc.BaggageAsAttributes,
+			TraceState:          rawSvc.TraceState,
+			Regions:             rawSvc.Regions,
 			Metrics:             rawSvc.Metrics,
 			Logs:                rawSvc.Logs,
+			SLO:                 rawSvc.SLO,
+			Flap:                rawSvc.Flap,
+			ScopeName:           rawSvc.ScopeName,
+			ScopeVersion:        rawSvc.ScopeVersion,
 		}
 
 		opNames := make([]string, 0, len(rawSvc.Operations))
@@ -458,20 +901,38 @@ func ParseConfig(data []byte) (*Config, error) {
 			svc.Operations = append(svc.Operations, OperationConfig{
 				Name:                opName,
 				Domain:              rawOp.Domain,
-				Duration:            rawOp.Duration,
+				Domains:             rawOp.Domains,
+				Duration:            string(rawOp.Duration),
 				ErrorRate:           rawOp.ErrorRate,
+				ErrorRateAtPeak:     rawOp.ErrorRateAtPeak,
+				ErrorTypes:          rawOp.ErrorTypes,
+				ErrorMessage:        rawOp.ErrorMessage,
+				SwallowErrors:       rawOp.SwallowErrors,
+				Disabled:            rawOp.Disabled,
+				SpanName:            rawOp.SpanName,
 				Calls:               rawOp.Calls,
+				OneOf:               rawOp.OneOf,
 				CallStyle:           rawOp.CallStyle,
+				CallJitter:          string(rawOp.CallJitter),
+				Timing:              rawOp.Timing,
+				SlowThreshold:       rawOp.SlowThreshold,
+				SlowStatus:          rawOp.SlowStatus,
 				Attributes:          rawOp.Attributes,
 				Baggage:             rawOp.Baggage,
 				BaggageAsAttributes: rawOp.BaggageAsAttributes,
+				GeneratedBaggage:    rawOp.GeneratedBaggage,
 				Events:              rawOp.Events,
 				Links:               rawOp.Links,
 				Metrics:             rawOp.Metrics,
 				Logs:                rawOp.Logs,
+				Concurrency:         rawOp.Concurrency,
 				QueueDepth:          rawOp.QueueDepth,
 				Backpressure:        rawOp.Backpressure,
 				CircuitBreaker:      rawOp.CircuitBreaker,
+				RateLimit:           rawOp.RateLimit,
+				ColdStart:           rawOp.ColdStart,
+				Cache:               rawOp.Cache,
+				Traffic:             rawOp.Traffic,
 			})
 		}
 		cfg.Services = append(cfg.Services, svc)
@@ -490,17 +951,30 @@ func validateReplayConfig(cfg *Config) error {
 	return nil
 }
 
-// LoadConfig reads and parses a YAML topology from a file path or URL.
-func LoadConfig(source string) (*Config, error) {
-	data, err := readSource(source)
+// LoadConfig reads and parses a YAML topology from a file path, a URL, or
+// stdin when source is StdinSource.
+// ${VAR} and ${VAR:-default} references are expanded from the process
+// environment before parsing, and files listed under include are merged in
+// (see loadRawConfig); a topology read from stdin includes files relative to
+// the working directory. URL fetches use DefaultFetchOptions unless adjusted
+// by opts.
+func LoadConfig(source string, opts ...LoadOption) (*Config, error) {
+	fetch := DefaultFetchOptions()
+	for _, opt := range opts {
+		opt(&fetch)
+	}
+	raw, err := loadRawConfig(source, nil, fetch)
 	if err != nil {
-		return nil, fmt.Errorf("reading config: %w", err)
+		return nil, err
 	}
-
-	return ParseConfig(data)
+	return configFromRaw(raw)
 }
 
-// ValidateConfig checks a configuration for structural correctness.
+// ValidateConfig checks a configuration for structural correctness. It
+// reports every problem it finds rather than stopping at the first: each
+// service, operation, scenario, and the traffic section are checked in turn,
+// and their errors are returned joined with errors.Join. Within one of these,
+// checking stops at the first error.
 func ValidateConfig(cfg *Config) error {
 	if cfg.Mode == ModeReplay {
 		return validateReplayConfig(cfg)
@@ -508,308 +982,544 @@ func ValidateConfig(cfg *Config) error {
 	if cfg.Mode != "" {
 		return fmt.Errorf("unknown mode %q (supported: %q)", cfg.Mode, ModeReplay)
 	}
+	var errs []error
 	if len(cfg.Services) == 0 {
-		return fmt.Errorf("at least one service is required under 'services:')")
+		errs = append(errs, fmt.Errorf("at least one service is required under 'services:')"))
 	}
 	if cfg.Traffic.Rate == "" {
-		return fmt.Errorf("traffic section with rate is required, e.g.\n\n  traffic:\n    rate: 10/s")
+		errs = append(errs, fmt.Errorf("traffic section with rate is required, e.g.\n\n  traffic:\n    rate: 10/s"))
 	}
 
 	// Build lookups for reference validation:
 	// knownOps: all defined operations
 	// knownServices: all defined services (for service-scope metric overrides)
 	// opCalls: which targets each operation calls (for remove_calls validation)
+	// called: every operation some operation calls (for root-only settings)
 	// metricsByScope: metric definitions keyed by scope ref (service name or "service.operation")
 	knownOps := make(map[string]bool)
 	knownServices := make(map[string]bool)
 	opCalls := make(map[string]map[string]bool)
+	called := make(map[string]bool)
 	metricsByScope := make(map[string]map[string]MetricConfig)
 	for _, svc := range cfg.Services {
-		if len(svc.Operations) == 0 {
-			return fmt.Errorf("service %q must have at least one operation, e.g.\n  operations:\n    GET /users:\n      duration: 50ms", svc.Name)
-		}
-		for k := range svc.ResourceAttributes {
-			if k == "" {
-				return fmt.Errorf("service %q: resource_attributes key must not be empty", svc.Name)
-			}
-			if reservedResourceAttribute[k] {
-				return fmt.Errorf("service %q: resource_attributes must not contain reserved key %q (set automatically)", svc.Name, k)
-			}
-		}
-		if err := validateBaggage(svc.Baggage, fmt.Sprintf("service %q", svc.Name)); err != nil {
-			return err
-		}
 		knownServices[svc.Name] = true
-		metricNames := make(map[string]bool)
-		for i, mc := range svc.Metrics {
-			if err := validateMetricConfig(mc, fmt.Sprintf("service %q: metric[%d]", svc.Name, i)); err != nil {
-				return err
-			}
-			if metricNames[mc.Name] {
-				return fmt.Errorf("service %q: duplicate metric name %q", svc.Name, mc.Name)
-			}
-			metricNames[mc.Name] = true
+		for _, mc := range svc.Metrics {
 			if metricsByScope[svc.Name] == nil {
 				metricsByScope[svc.Name] = make(map[string]MetricConfig)
 			}
 			metricsByScope[svc.Name][mc.Name] = mc
 		}
-		for i, lc := range svc.Logs {
-			if err := validateLogConfig(lc, fmt.Sprintf("service %q: log[%d]", svc.Name, i)); err != nil {
-				return err
-			}
-		}
 		for _, op := range svc.Operations {
-			opRef := svc.Name + "." + op.Name
-			for i, lc := range op.Logs {
-				if err := validateLogConfig(lc, fmt.Sprintf("service %q operation %q: log[%d]", svc.Name, op.Name, i)); err != nil {
-					return err
-				}
-			}
-			for i, mc := range op.Metrics {
-				if err := validateMetricConfig(mc, fmt.Sprintf("service %q operation %q: metric[%d]", svc.Name, op.Name, i)); err != nil {
-					return err
-				}
-				if metricNames[mc.Name] {
-					return fmt.Errorf("service %q operation %q: duplicate metric name %q (already defined at service or operation level)", svc.Name, op.Name, mc.Name)
-				}
-				metricNames[mc.Name] = true
-				if metricsByScope[opRef] == nil {
-					metricsByScope[opRef] = make(map[string]MetricConfig)
+			ref := svc.Name + "." + op.Name
+			for _, mc := range op.Metrics {
+				if metricsByScope[ref] == nil {
+					metricsByScope[ref] = make(map[string]MetricConfig)
 				}
-				metricsByScope[opRef][mc.Name] = mc
+				metricsByScope[ref][mc.Name] = mc
 			}
-			ref := opRef
 			knownOps[ref] = true
 			targets := make(map[string]bool, len(op.Calls))
 			for _, call := range op.Calls {
 				targets[call.Target] = true
+				if !op.Disabled && !call.Disabled {
+					called[call.Target] = true
+				}
+			}
+			for _, call := range op.OneOf {
+				if !op.Disabled && !call.Disabled {
+					called[call.Target] = true
+				}
 			}
 			opCalls[ref] = targets
 		}
 	}
 
-	// Validate each operation
 	for _, svc := range cfg.Services {
+		if err := validateServiceConfig(svc); err != nil {
+			errs = append(errs, err)
+		}
+		if err := validateTraceState(svc.TraceState, cfg.TraceState, fmt.Sprintf("service %q", svc.Name)); err != nil {
+			errs = append(errs, err)
+		}
 		for _, op := range svc.Operations {
-			if _, err := ParseDistribution(op.Duration); err != nil {
-				return fmt.Errorf("service %q operation %q: invalid duration: %w", svc.Name, op.Name, err)
+			if err := validateOperationConfig(svc, op, knownOps, opCalls); err != nil {
+				errs = append(errs, err)
+			} else if op.Traffic != nil && called[svc.Name+"."+op.Name] {
+				errs = append(errs, fmt.Errorf("service %q operation %q: traffic applies only to root operations, and this operation is called by another", svc.Name, op.Name))
 			}
+		}
+	}
 
-			if op.ErrorRate != "" {
-				if _, err := parseErrorRate(op.ErrorRate); err != nil {
-					return fmt.Errorf("service %q operation %q: invalid error_rate: %w", svc.Name, op.Name, err)
-				}
-			}
+	if cfg.Traffic.Rate != "" {
+		if err := validateTrafficConfig(cfg.Traffic, false); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
-			if op.CallStyle != "" && op.CallStyle != "parallel" && op.CallStyle != "sequential" {
-				return fmt.Errorf("service %q operation %q: call_style must be \"parallel\" or \"sequential\", got %q", svc.Name, op.Name, op.CallStyle)
-			}
+	for attrName, attrCfg := range cfg.TraceAttributes {
+		if _, err := NewAttributeGenerator(attrCfg); err != nil {
+			errs = append(errs, fmt.Errorf("trace_attributes: attribute %q: %w", attrName, err))
+		}
+	}
+	if err := validateTraceState(cfg.TraceState, nil, "top level"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateObserverConfigs(cfg.Observers); err != nil {
+		errs = append(errs, err)
+	}
 
-			for attrName, attrCfg := range op.Attributes {
-				if _, err := NewAttributeGenerator(attrCfg); err != nil {
-					return fmt.Errorf("service %q operation %q: attribute %q: %w", svc.Name, op.Name, attrName, err)
-				}
-			}
+	for _, sc := range cfg.Scenarios {
+		if err := validateScenarioConfig(sc, cfg, knownOps, knownServices, opCalls, metricsByScope); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, validateDisabledReferences(cfg)...)
+
+	return errors.Join(errs...)
+}
 
-			if err := validateBaggage(op.Baggage, fmt.Sprintf("service %q operation %q", svc.Name, op.Name)); err != nil {
+// validateServiceConfig checks a service's own settings and the metric and
+// log definitions of the service and its operations, returning the first
+// problem found.
+func validateServiceConfig(svc ServiceConfig) error {
+	if len(svc.Operations) == 0 {
+		return fmt.Errorf("service %q must have at least one operation, e.g.\n  operations:\n    GET /users:\n      duration: 50ms", svc.Name)
+	}
+	for k := range svc.ResourceAttributes {
+		if k == "" {
+			return fmt.Errorf("service %q: resource_attributes key must not be empty", svc.Name)
+		}
+		if reservedResourceAttribute[k] {
+			return fmt.Errorf("service %q: resource_attributes must not contain reserved key %q (set automatically)", svc.Name, k)
+		}
+	}
+	for attrName, attrCfg := range svc.DefaultAttributes {
+		if _, err := NewAttributeGenerator(attrCfg); err != nil {
+			return fmt.Errorf("service %q: default_attributes %q: %w", svc.Name, attrName, err)
+		}
+	}
+	if err := validateBaggage(svc.Baggage, fmt.Sprintf("service %q", svc.Name)); err != nil {
+		return err
+	}
+	if err := validateSLO(svc.SLO); err != nil {
+		return fmt.Errorf("service %q: slo: %w", svc.Name, err)
+	}
+	if err := validateFlap(svc.Flap); err != nil {
+		return fmt.Errorf("service %q: flap: %w", svc.Name, err)
+	}
+	if err := validateRegions(svc.Regions); err != nil {
+		return fmt.Errorf("service %q: %w", svc.Name, err)
+	}
+	metricNames := make(map[string]bool)
+	for i, mc := range svc.Metrics {
+		if err := validateMetricConfig(mc, fmt.Sprintf("service %q: metric[%d]", svc.Name, i)); err != nil {
+			return err
+		}
+		if metricNames[mc.Name] {
+			return fmt.Errorf("service %q: duplicate metric name %q", svc.Name, mc.Name)
+		}
+		metricNames[mc.Name] = true
+	}
+	for i, lc := range svc.Logs {
+		if err := validateLogConfig(lc, fmt.Sprintf("service %q: log[%d]", svc.Name, i)); err != nil {
+			return err
+		}
+	}
+	for _, op := range svc.Operations {
+		for i, lc := range op.Logs {
+			if err := validateLogConfig(lc, fmt.Sprintf("service %q operation %q: log[%d]", svc.Name, op.Name, i)); err != nil {
 				return err
 			}
-
-			for i, evt := range op.Events {
-				if evt.Name == "" {
-					return fmt.Errorf("service %q operation %q: event[%d]: name is required", svc.Name, op.Name, i)
-				}
-				if evt.Delay != "" {
-					d, err := time.ParseDuration(evt.Delay)
-					if err != nil {
-						return fmt.Errorf("service %q operation %q: event %q: invalid delay: %w", svc.Name, op.Name, evt.Name, err)
-					}
-					if d < 0 {
-						return fmt.Errorf("service %q operation %q: event %q: delay must not be negative", svc.Name, op.Name, evt.Name)
-					}
-				}
-				for attrName, attrCfg := range evt.Attributes {
-					if _, err := NewAttributeGenerator(attrCfg); err != nil {
-						return fmt.Errorf("service %q operation %q: event %q: attribute %q: %w", svc.Name, op.Name, evt.Name, attrName, err)
-					}
-				}
+		}
+		for i, mc := range op.Metrics {
+			if err := validateMetricConfig(mc, fmt.Sprintf("service %q operation %q: metric[%d]", svc.Name, op.Name, i)); err != nil {
+				return err
 			}
-
-			if op.QueueDepth < 0 {
-				return fmt.Errorf("service %q operation %q: queue_depth must not be negative", svc.Name, op.Name)
+			if metricNames[mc.Name] {
+				return fmt.Errorf("service %q operation %q: duplicate metric name %q (already defined at service or operation level)", svc.Name, op.Name, mc.Name)
 			}
+			metricNames[mc.Name] = true
+		}
+	}
+	return nil
+}
 
-			if bp := op.Backpressure; bp != nil {
-				if bp.LatencyThreshold == "" {
-					return fmt.Errorf("service %q operation %q: backpressure requires latency_threshold", svc.Name, op.Name)
-				}
-				if _, err := time.ParseDuration(bp.LatencyThreshold); err != nil {
-					return fmt.Errorf("service %q operation %q: backpressure: invalid latency_threshold: %w", svc.Name, op.Name, err)
-				}
-				if bp.DurationMultiplier < 0 {
-					return fmt.Errorf("service %q operation %q: backpressure: duration_multiplier must not be negative", svc.Name, op.Name)
-				}
-				if bp.ErrorRateAdd != "" {
-					if _, err := parseErrorRate(bp.ErrorRateAdd); err != nil {
-						return fmt.Errorf("service %q operation %q: backpressure: invalid error_rate_add: %w", svc.Name, op.Name, err)
-					}
-				}
+// validateOperationConfig checks one operation and its calls, returning the
+// first problem found.
+func validateOperationConfig(svc ServiceConfig, op OperationConfig, knownOps map[string]bool, opCalls map[string]map[string]bool) error {
+	if _, err := ParseDistribution(op.Duration); err != nil {
+		return fmt.Errorf("service %q operation %q: invalid duration: %w", svc.Name, op.Name, err)
+	}
+
+	if op.ErrorRate != "" {
+		if _, err := parseErrorRate(op.ErrorRate); err != nil {
+			return fmt.Errorf("service %q operation %q: invalid error_rate: %w", svc.Name, op.Name, err)
+		}
+	}
+	if op.ErrorRateAtPeak < 0 {
+		return fmt.Errorf("service %q operation %q: error_rate_at_peak must not be negative", svc.Name, op.Name)
+	}
+	if op.SpanName != "" && strings.TrimSpace(op.SpanName) == "" {
+		return fmt.Errorf("service %q operation %q: span_name must not be blank", svc.Name, op.Name)
+	}
+	if op.Traffic != nil {
+		if op.Traffic.Rate == "" {
+			return fmt.Errorf("service %q operation %q: traffic requires rate", svc.Name, op.Name)
+		}
+		if err := validateTrafficConfig(*op.Traffic, false); err != nil {
+			return fmt.Errorf("service %q operation %q: traffic: %w", svc.Name, op.Name, err)
+		}
+	}
+
+	if op.CallStyle != "" && op.CallStyle != "parallel" && op.CallStyle != "sequential" {
+		return fmt.Errorf("service %q operation %q: call_style must be \"parallel\" or \"sequential\", got %q", svc.Name, op.Name, op.CallStyle)
+	}
+	if op.CallJitter != "" {
+		if _, err := ParseDistribution(op.CallJitter); err != nil {
+			return fmt.Errorf("service %q operation %q: invalid call_jitter: %w", svc.Name, op.Name, err)
+		}
+		if op.CallStyle == "sequential" {
+			return fmt.Errorf("service %q operation %q: call_jitter applies only to parallel calls", svc.Name, op.Name)
+		}
+	}
+	if err := validateSlowStatus(op.SlowThreshold, op.SlowStatus); err != nil {
+		return fmt.Errorf("service %q operation %q: %w", svc.Name, op.Name, err)
+	}
+	if err := validateTiming(op.Timing); err != nil {
+		return fmt.Errorf("service %q operation %q: %w", svc.Name, op.Name, err)
+	}
+
+	for attrName, attrCfg := range op.Attributes {
+		if _, err := NewAttributeGenerator(attrCfg); err != nil {
+			return fmt.Errorf("service %q operation %q: attribute %q: %w", svc.Name, op.Name, attrName, err)
+		}
+	}
+
+	if err := validateBaggage(op.Baggage, fmt.Sprintf("service %q operation %q", svc.Name, op.Name)); err != nil {
+		return err
+	}
+	if err := validateGeneratedBaggage(op.GeneratedBaggage, op.Baggage, fmt.Sprintf("service %q operation %q", svc.Name, op.Name)); err != nil {
+		return err
+	}
+
+	if err := validateErrorTypes(op.ErrorTypes, fmt.Sprintf("service %q operation %q", svc.Name, op.Name)); err != nil {
+		return err
+	}
+
+	for i, evt := range op.Events {
+		if evt.Name == "" {
+			return fmt.Errorf("service %q operation %q: event[%d]: name is required", svc.Name, op.Name, i)
+		}
+		if evt.Delay != "" {
+			d, err := time.ParseDuration(evt.Delay)
+			if err != nil {
+				return fmt.Errorf("service %q operation %q: event %q: invalid delay: %w", svc.Name, op.Name, evt.Name, err)
+			}
+			if d < 0 {
+				return fmt.Errorf("service %q operation %q: event %q: delay must not be negative", svc.Name, op.Name, evt.Name)
+			}
+		}
+		for attrName, attrCfg := range evt.Attributes {
+			if _, err := NewAttributeGenerator(attrCfg); err != nil {
+				return fmt.Errorf("service %q operation %q: event %q: attribute %q: %w", svc.Name, op.Name, evt.Name, attrName, err)
 			}
+		}
+	}
 
-			if cb := op.CircuitBreaker; cb != nil {
-				if cb.FailureThreshold <= 0 {
-					return fmt.Errorf("service %q operation %q: circuit_breaker: failure_threshold must be positive", svc.Name, op.Name)
-				}
-				if cb.Window == "" {
-					return fmt.Errorf("service %q operation %q: circuit_breaker requires window", svc.Name, op.Name)
-				}
-				if _, err := time.ParseDuration(cb.Window); err != nil {
-					return fmt.Errorf("service %q operation %q: circuit_breaker: invalid window: %w", svc.Name, op.Name, err)
-				}
-				if cb.Cooldown == "" {
-					return fmt.Errorf("service %q operation %q: circuit_breaker requires cooldown", svc.Name, op.Name)
-				}
-				if _, err := time.ParseDuration(cb.Cooldown); err != nil {
-					return fmt.Errorf("service %q operation %q: circuit_breaker: invalid cooldown: %w", svc.Name, op.Name, err)
+	if op.QueueDepth < 0 {
+		return fmt.Errorf("service %q operation %q: queue_depth must not be negative", svc.Name, op.Name)
+	}
+	if op.Concurrency < 0 {
+		return fmt.Errorf("service %q operation %q: concurrency must not be negative", svc.Name, op.Name)
+	}
+
+	if bp := op.Backpressure; bp != nil {
+		switch bp.QueueModel {
+		case "":
+			if bp.LatencyThreshold == "" {
+				return fmt.Errorf("service %q operation %q: backpressure requires latency_threshold", svc.Name, op.Name)
+			}
+			if _, err := time.ParseDuration(bp.LatencyThreshold); err != nil {
+				return fmt.Errorf("service %q operation %q: backpressure: invalid latency_threshold: %w", svc.Name, op.Name, err)
+			}
+			if bp.DurationMultiplier < 0 {
+				return fmt.Errorf("service %q operation %q: backpressure: duration_multiplier must not be negative", svc.Name, op.Name)
+			}
+			if bp.ErrorRateAdd != "" {
+				if _, err := parseErrorRate(bp.ErrorRateAdd); err != nil {
+					return fmt.Errorf("service %q operation %q: backpressure: invalid error_rate_add: %w", svc.Name, op.Name, err)
 				}
 			}
+		case QueueModelMM1:
+			if bp.LatencyThreshold != "" || bp.DurationMultiplier != 0 || bp.ErrorRateAdd != "" {
+				return fmt.Errorf("service %q operation %q: backpressure: queue_model %s cannot be combined with latency_threshold, duration_multiplier, or error_rate_add", svc.Name, op.Name, bp.QueueModel)
+			}
+			if op.Concurrency > 0 {
+				return fmt.Errorf("service %q operation %q: backpressure: queue_model %s cannot be combined with concurrency, which queues requests itself", svc.Name, op.Name, bp.QueueModel)
+			}
+		default:
+			return fmt.Errorf("service %q operation %q: backpressure: unknown queue_model %q (expected %q)", svc.Name, op.Name, bp.QueueModel, QueueModelMM1)
+		}
+	}
 
-			ref := svc.Name + "." + op.Name
-			seenLinks := make(map[string]bool, len(op.Links))
-			for _, link := range op.Links {
-				if link.Ref == "" {
-					return fmt.Errorf("service %q operation %q: link must have a non-empty ref", svc.Name, op.Name)
-				}
-				if !strings.Contains(link.Ref, ".") {
-					return fmt.Errorf("service %q operation %q: link %q must be in service.operation format", svc.Name, op.Name, link.Ref)
-				}
-				if !knownOps[link.Ref] {
-					return fmt.Errorf("service %q operation %q: link %q references unknown operation", svc.Name, op.Name, link.Ref)
-				}
-				if link.Ref == ref {
-					return fmt.Errorf("service %q operation %q: link must not reference itself", svc.Name, op.Name)
-				}
-				if seenLinks[link.Ref] {
-					return fmt.Errorf("service %q operation %q: duplicate link %q", svc.Name, op.Name, link.Ref)
-				}
-				for attrName, attrCfg := range link.Attributes {
-					if _, err := NewAttributeGenerator(attrCfg); err != nil {
-						return fmt.Errorf("service %q operation %q link %q: attribute %q: %w", svc.Name, op.Name, link.Ref, attrName, err)
-					}
-				}
-				seenLinks[link.Ref] = true
+	if cb := op.CircuitBreaker; cb != nil {
+		if cb.FailureThreshold <= 0 {
+			return fmt.Errorf("service %q operation %q: circuit_breaker: failure_threshold must be positive", svc.Name, op.Name)
+		}
+		if cb.Window == "" {
+			return fmt.Errorf("service %q operation %q: circuit_breaker requires window", svc.Name, op.Name)
+		}
+		if _, err := time.ParseDuration(cb.Window); err != nil {
+			return fmt.Errorf("service %q operation %q: circuit_breaker: invalid window: %w", svc.Name, op.Name, err)
+		}
+		if cb.Cooldown == "" {
+			return fmt.Errorf("service %q operation %q: circuit_breaker requires cooldown", svc.Name, op.Name)
+		}
+		if _, err := time.ParseDuration(cb.Cooldown); err != nil {
+			return fmt.Errorf("service %q operation %q: circuit_breaker: invalid cooldown: %w", svc.Name, op.Name, err)
+		}
+		if cb.HalfOpenMaxProbes != nil && *cb.HalfOpenMaxProbes <= 0 {
+			return fmt.Errorf("service %q operation %q: circuit_breaker: half_open_max_probes must be positive", svc.Name, op.Name)
+		}
+	}
+
+	if rl := op.RateLimit; rl != nil {
+		if rl.MaxRate == "" && rl.MaxConcurrency == 0 {
+			return fmt.Errorf("service %q operation %q: rate_limit requires max_rate or max_concurrency", svc.Name, op.Name)
+		}
+		if rl.MaxRate != "" {
+			if _, err := ParseRate(rl.MaxRate); err != nil {
+				return fmt.Errorf("service %q operation %q: rate_limit: invalid max_rate: %w", svc.Name, op.Name, err)
 			}
+		}
+		if rl.MaxConcurrency < 0 {
+			return fmt.Errorf("service %q operation %q: rate_limit: max_concurrency must not be negative", svc.Name, op.Name)
+		}
+	}
 
-			for _, call := range op.Calls {
-				if !strings.Contains(call.Target, ".") {
-					return fmt.Errorf("service %q operation %q: call %q must be in service.operation format", svc.Name, op.Name, call.Target)
-				}
-				if !knownOps[call.Target] {
-					return fmt.Errorf("service %q operation %q: call %q references unknown operation", svc.Name, op.Name, call.Target)
-				}
-				if call.Probability < 0 || call.Probability > 1 {
-					return fmt.Errorf("service %q operation %q: call %q probability must be between 0 and 1", svc.Name, op.Name, call.Target)
-				}
-				if call.Condition != "" && call.Condition != "on-error" && call.Condition != "on-success" {
-					return fmt.Errorf("service %q operation %q: call %q condition must be \"on-error\" or \"on-success\", got %q", svc.Name, op.Name, call.Target, call.Condition)
-				}
-				if call.Count < 0 {
-					return fmt.Errorf("service %q operation %q: call %q count must not be negative", svc.Name, op.Name, call.Target)
-				}
-				if call.Timeout != "" {
-					d, err := time.ParseDuration(call.Timeout)
-					if err != nil {
-						return fmt.Errorf("service %q operation %q: call %q invalid timeout: %w", svc.Name, op.Name, call.Target, err)
-					}
-					if d <= 0 {
-						return fmt.Errorf("service %q operation %q: call %q timeout must be positive", svc.Name, op.Name, call.Target)
-					}
-				}
-				if call.Retries < 0 {
-					return fmt.Errorf("service %q operation %q: call %q retries must not be negative", svc.Name, op.Name, call.Target)
-				}
-				if call.RetryBackoff != "" {
-					d, err := time.ParseDuration(call.RetryBackoff)
-					if err != nil {
-						return fmt.Errorf("service %q operation %q: call %q invalid retry_backoff: %w", svc.Name, op.Name, call.Target, err)
-					}
-					if d < 0 {
-						return fmt.Errorf("service %q operation %q: call %q retry_backoff must not be negative", svc.Name, op.Name, call.Target)
-					}
-				}
-				if call.RetryBackoff != "" && call.Retries == 0 {
-					return fmt.Errorf("service %q operation %q: call %q retry_backoff requires retries > 0", svc.Name, op.Name, call.Target)
-				}
-				if call.Async && call.Retries > 0 {
-					return fmt.Errorf("service %q operation %q: call %q: async calls cannot have retries", svc.Name, op.Name, call.Target)
-				}
-				if call.Async && call.Timeout != "" {
-					return fmt.Errorf("service %q operation %q: call %q: async calls cannot have a timeout", svc.Name, op.Name, call.Target)
-				}
-				if call.Producer && call.Async {
-					return fmt.Errorf("service %q operation %q: call %q: a call cannot be both producer and async", svc.Name, op.Name, call.Target)
-				}
+	if cs := op.ColdStart; cs != nil {
+		if cs.Latency == "" {
+			return fmt.Errorf("service %q operation %q: cold_start requires latency", svc.Name, op.Name)
+		}
+		latency, err := time.ParseDuration(cs.Latency)
+		if err != nil {
+			return fmt.Errorf("service %q operation %q: cold_start: invalid latency: %w", svc.Name, op.Name, err)
+		}
+		if latency <= 0 {
+			return fmt.Errorf("service %q operation %q: cold_start: latency must be positive", svc.Name, op.Name)
+		}
+		if cs.Invocations <= 0 {
+			return fmt.Errorf("service %q operation %q: cold_start: invocations must be positive", svc.Name, op.Name)
+		}
+	}
+
+	ref := svc.Name + "." + op.Name
+	if c := op.Cache; c != nil {
+		if c.HitRate < 0 || c.HitRate > 1 {
+			return fmt.Errorf("service %q operation %q: cache: hit_rate must be between 0 and 1", svc.Name, op.Name)
+		}
+		for _, target := range c.SkipCalls {
+			if !opCalls[ref][target] {
+				return fmt.Errorf("service %q operation %q: cache: skip_calls target %q is not called by this operation", svc.Name, op.Name, target)
 			}
 		}
 	}
 
-	if err := validateTrafficConfig(cfg.Traffic, false); err != nil {
-		return err
+	seenLinks := make(map[string]bool, len(op.Links))
+	for _, link := range op.Links {
+		if link.Ref == "" {
+			return fmt.Errorf("service %q operation %q: link must have a non-empty ref", svc.Name, op.Name)
+		}
+		if !strings.Contains(link.Ref, ".") {
+			return fmt.Errorf("service %q operation %q: link %q must be in service.operation format", svc.Name, op.Name, link.Ref)
+		}
+		if !knownOps[link.Ref] {
+			return fmt.Errorf("service %q operation %q: link %q references unknown operation", svc.Name, op.Name, link.Ref)
+		}
+		if link.Ref == ref {
+			return fmt.Errorf("service %q operation %q: link must not reference itself", svc.Name, op.Name)
+		}
+		if seenLinks[link.Ref] {
+			return fmt.Errorf("service %q operation %q: duplicate link %q", svc.Name, op.Name, link.Ref)
+		}
+		for attrName, attrCfg := range link.Attributes {
+			if _, err := NewAttributeGenerator(attrCfg); err != nil {
+				return fmt.Errorf("service %q operation %q link %q: attribute %q: %w", svc.Name, op.Name, link.Ref, attrName, err)
+			}
+		}
+		seenLinks[link.Ref] = true
 	}
 
-	// Validate scenarios
-	for _, sc := range cfg.Scenarios {
-		if _, err := ParseOffset(sc.At); err != nil {
-			return fmt.Errorf("scenario %q: invalid at: %w", sc.Name, err)
-		}
-		if dur, err := time.ParseDuration(sc.Duration); err != nil {
-			return fmt.Errorf("scenario %q: invalid duration: %w", sc.Name, err)
-		} else if dur <= 0 {
-			return fmt.Errorf("scenario %q: duration must be positive, got %q", sc.Name, sc.Duration)
-		}
-		for ref, override := range sc.Override {
-			if !knownOps[ref] {
-				if !knownServices[ref] {
-					return fmt.Errorf("scenario %q: override %q references unknown operation or service", sc.Name, ref)
-				}
-				if override.Duration != "" || override.ErrorRate != "" || len(override.Attributes) > 0 ||
-					len(override.AddCalls) > 0 || len(override.RemoveCalls) > 0 {
-					return fmt.Errorf("scenario %q: override %q: service-level overrides support only metrics and logs (use %s.<operation> for operation overrides)", sc.Name, ref, ref)
-				}
+	for _, call := range op.Calls {
+		if !strings.Contains(call.Target, ".") {
+			return fmt.Errorf("service %q operation %q: call %q must be in service.operation format", svc.Name, op.Name, call.Target)
+		}
+		if !knownOps[call.Target] {
+			return fmt.Errorf("service %q operation %q: call %q references unknown operation", svc.Name, op.Name, call.Target)
+		}
+		if call.Probability < 0 || call.Probability > 1 {
+			return fmt.Errorf("service %q operation %q: call %q probability must be between 0 and 1", svc.Name, op.Name, call.Target)
+		}
+		if call.ProbabilityDecay < 0 || call.ProbabilityDecay > 1 {
+			return fmt.Errorf("service %q operation %q: call %q probability decay must be between 0 and 1", svc.Name, op.Name, call.Target)
+		}
+		if call.Condition != "" && call.Condition != "on-error" && call.Condition != "on-success" {
+			return fmt.Errorf("service %q operation %q: call %q condition must be \"on-error\" or \"on-success\", got %q", svc.Name, op.Name, call.Target, call.Condition)
+		}
+		if call.Count < 0 {
+			return fmt.Errorf("service %q operation %q: call %q count must not be negative", svc.Name, op.Name, call.Target)
+		}
+		if err := validateCountDistribution(call); err != nil {
+			return fmt.Errorf("service %q operation %q: call %q %w", svc.Name, op.Name, call.Target, err)
+		}
+		if call.ErrorRate != "" {
+			if _, err := parseErrorRate(call.ErrorRate); err != nil {
+				return fmt.Errorf("service %q operation %q: call %q: %w", svc.Name, op.Name, call.Target, err)
 			}
-			if err := validateMetricOverrides(sc.Name, ref, override.Metrics, metricsByScope[ref]); err != nil {
-				return err
+		}
+		if call.Timeout != "" {
+			d, err := time.ParseDuration(call.Timeout)
+			if err != nil {
+				return fmt.Errorf("service %q operation %q: call %q invalid timeout: %w", svc.Name, op.Name, call.Target, err)
 			}
-			if err := validateLogOverrides(sc.Name, ref, override.Logs); err != nil {
-				return err
+			if d <= 0 {
+				return fmt.Errorf("service %q operation %q: call %q timeout must be positive", svc.Name, op.Name, call.Target)
 			}
-			if override.Duration != "" {
-				if _, err := ParseDistribution(override.Duration); err != nil {
-					return fmt.Errorf("scenario %q: override %q: invalid duration: %w", sc.Name, ref, err)
-				}
+		}
+		if call.Retries < 0 {
+			return fmt.Errorf("service %q operation %q: call %q retries must not be negative", svc.Name, op.Name, call.Target)
+		}
+		if call.RetryBackoff != "" {
+			d, err := time.ParseDuration(call.RetryBackoff)
+			if err != nil {
+				return fmt.Errorf("service %q operation %q: call %q invalid retry_backoff: %w", svc.Name, op.Name, call.Target, err)
 			}
-			if override.ErrorRate != "" {
-				if _, err := parseErrorRate(override.ErrorRate); err != nil {
-					return fmt.Errorf("scenario %q: override %q: invalid error_rate: %w", sc.Name, ref, err)
-				}
+			if d < 0 {
+				return fmt.Errorf("service %q operation %q: call %q retry_backoff must not be negative", svc.Name, op.Name, call.Target)
 			}
-			for attrName, attrCfg := range override.Attributes {
-				if _, err := NewAttributeGenerator(attrCfg); err != nil {
-					return fmt.Errorf("scenario %q: override %q: attribute %q: %w", sc.Name, ref, attrName, err)
-				}
+		}
+		if call.RetryBackoff != "" && call.Retries == 0 {
+			return fmt.Errorf("service %q operation %q: call %q retry_backoff requires retries > 0", svc.Name, op.Name, call.Target)
+		}
+		if call.RetryBackoffMultiplier != 0 && call.RetryBackoffMultiplier < 1 {
+			return fmt.Errorf("service %q operation %q: call %q retry_backoff_multiplier must be >= 1, got %g", svc.Name, op.Name, call.Target, call.RetryBackoffMultiplier)
+		}
+		if call.RetryJitter < 0 || call.RetryJitter > 1 {
+			return fmt.Errorf("service %q operation %q: call %q retry_jitter must be between 0 and 1, got %g", svc.Name, op.Name, call.Target, call.RetryJitter)
+		}
+		if (call.RetryBackoffMultiplier != 0 || call.RetryJitter != 0) && call.Retries == 0 {
+			return fmt.Errorf("service %q operation %q: call %q retry_backoff_multiplier and retry_jitter require retries > 0", svc.Name, op.Name, call.Target)
+		}
+		if call.HedgeAfter != "" {
+			d, err := time.ParseDuration(call.HedgeAfter)
+			if err != nil {
+				return fmt.Errorf("service %q operation %q: call %q invalid hedge_after: %w", svc.Name, op.Name, call.Target, err)
 			}
-			if err := validateCallChanges(sc.Name, ref, override, knownOps, opCalls[ref]); err != nil {
-				return err
+			if d <= 0 {
+				return fmt.Errorf("service %q operation %q: call %q hedge_after must be positive", svc.Name, op.Name, call.Target)
 			}
 		}
-		if sc.Traffic != nil {
-			if err := validateTrafficConfig(*sc.Traffic, false); err != nil {
-				return fmt.Errorf("scenario %q: traffic: %w", sc.Name, err)
+		if call.Async && call.Retries > 0 {
+			return fmt.Errorf("service %q operation %q: call %q: async calls cannot have retries", svc.Name, op.Name, call.Target)
+		}
+		if call.Async && call.Timeout != "" {
+			return fmt.Errorf("service %q operation %q: call %q: async calls cannot have a timeout", svc.Name, op.Name, call.Target)
+		}
+		if call.Async && call.HedgeAfter != "" {
+			return fmt.Errorf("service %q operation %q: call %q: async calls cannot be hedged", svc.Name, op.Name, call.Target)
+		}
+		if call.Producer && call.Async {
+			return fmt.Errorf("service %q operation %q: call %q: a call cannot be both producer and async", svc.Name, op.Name, call.Target)
+		}
+		if call.Weight != 0 {
+			return fmt.Errorf("service %q operation %q: call %q: weight applies only to one_of calls", svc.Name, op.Name, call.Target)
+		}
+		for attrName, attrCfg := range call.Attributes {
+			if _, err := NewAttributeGenerator(attrCfg); err != nil {
+				return fmt.Errorf("service %q operation %q: call %q: attribute %q: %w", svc.Name, op.Name, call.Target, attrName, err)
 			}
 		}
 	}
+	if err := validateOneOf(op.OneOf, knownOps); err != nil {
+		return fmt.Errorf("service %q operation %q: one_of: %w", svc.Name, op.Name, err)
+	}
+	return nil
+}
 
+// validateScenarioConfig checks one scenario, returning the first problem
+// found.
+func validateScenarioConfig(sc ScenarioConfig, cfg *Config, knownOps, knownServices map[string]bool, opCalls map[string]map[string]bool, metricsByScope map[string]map[string]MetricConfig) error {
+	if _, err := ParseOffset(sc.At); err != nil {
+		return fmt.Errorf("scenario %q: invalid at: %w", sc.Name, err)
+	}
+	if _, err := scenarioDuration(sc); err != nil {
+		return err
+	}
+	for attrName, attrCfg := range sc.TraceAttributes {
+		if _, err := NewAttributeGenerator(attrCfg); err != nil {
+			return fmt.Errorf("scenario %q: trace_attributes: attribute %q: %w", sc.Name, attrName, err)
+		}
+	}
+	if _, ok := sc.Labels[""]; ok {
+		return fmt.Errorf("scenario %q: labels: label name must not be empty", sc.Name)
+	}
+	if err := validateDrain(sc.Drain, sc.DrainMode, knownServices); err != nil {
+		return fmt.Errorf("scenario %q: %w", sc.Name, err)
+	}
+	for ref, override := range sc.Override {
+		if !knownOps[ref] {
+			if !knownServices[ref] {
+				return fmt.Errorf("scenario %q: override %q references unknown operation or service", sc.Name, ref)
+			}
+			if override.Duration != "" || override.ErrorRate != "" || override.ErrorRateMultiply != 0 || len(override.Attributes) > 0 ||
+				len(override.AddCalls) > 0 || len(override.RemoveCalls) > 0 {
+				return fmt.Errorf("scenario %q: override %q: service-level overrides support only metrics and logs (use %s.<operation> for operation overrides)", sc.Name, ref, ref)
+			}
+		}
+		if err := validateMetricOverrides(sc.Name, ref, override.Metrics, metricsByScope[ref]); err != nil {
+			return err
+		}
+		if err := validateLogOverrides(sc.Name, ref, override.Logs); err != nil {
+			return err
+		}
+		if override.Duration != "" {
+			if _, err := ParseDistribution(override.Duration); err != nil {
+				return fmt.Errorf("scenario %q: override %q: invalid duration: %w", sc.Name, ref, err)
+			}
+		}
+		if override.ErrorRate != "" {
+			if _, err := parseErrorRate(override.ErrorRate); err != nil {
+				return fmt.Errorf("scenario %q: override %q: invalid error_rate: %w", sc.Name, ref, err)
+			}
+		}
+		if override.ErrorRateMultiply < 0 {
+			return fmt.Errorf("scenario %q: override %q: error_rate_multiply must not be negative, got %v", sc.Name, ref, override.ErrorRateMultiply)
+		}
+		for attrName, attrCfg := range override.Attributes {
+			if _, err := NewAttributeGenerator(attrCfg); err != nil {
+				return fmt.Errorf("scenario %q: override %q: attribute %q: %w", sc.Name, ref, attrName, err)
+			}
+		}
+		if err := validateCallChanges(sc.Name, ref, override, knownOps, opCalls[ref]); err != nil {
+			return err
+		}
+	}
+	if err := validateScenarioMatches(sc, cfg, knownServices); err != nil {
+		return err
+	}
+	if sc.Traffic != nil {
+		if err := validateTrafficConfig(*sc.Traffic, false); err != nil {
+			return fmt.Errorf("scenario %q: traffic: %w", sc.Name, err)
+		}
+	}
+	if sc.Spike != nil {
+		if sc.Traffic != nil {
+			return fmt.Errorf("scenario %q: spike and traffic cannot be combined; a spike multiplies whatever traffic is active", sc.Name)
+		}
+		if sc.Spike.Multiplier <= 1 {
+			return fmt.Errorf("scenario %q: spike: multiplier must be greater than 1, got %v", sc.Name, sc.Spike.Multiplier)
+		}
+	}
 	return nil
 }
 
@@ -819,12 +1529,12 @@ func validateTrafficConfig(tc TrafficConfig, isOverlay bool) error {
 		pattern = "uniform"
 	}
 
-	hasBurstyFields := tc.BurstMultiplier != 0 || tc.BurstInterval != "" || tc.BurstDuration != ""
+	hasBurstyFields := tc.BurstMultiplier != 0 || tc.BurstInterval != "" || tc.BurstDuration != "" || tc.BurstIntervalJitter != ""
 	hasDiurnalFields := tc.PeakMultiplier != 0 || tc.TroughMultiplier != 0 || tc.Period != ""
 	hasSegments := len(tc.Segments) > 0
 
 	if hasBurstyFields && pattern != "bursty" {
-		return fmt.Errorf("burst_multiplier, burst_interval, burst_duration are only valid with pattern \"bursty\"")
+		return fmt.Errorf("burst_multiplier, burst_interval, burst_duration, burst_interval_jitter are only valid with pattern \"bursty\"")
 	}
 	if hasDiurnalFields && pattern != "diurnal" {
 		return fmt.Errorf("peak_multiplier, trough_multiplier, period are only valid with pattern \"diurnal\"")
@@ -837,12 +1547,12 @@ func validateTrafficConfig(tc TrafficConfig, isOverlay bool) error {
 		return err
 	}
 
-	if tc.Overlay != nil {
-		if isOverlay {
-			return fmt.Errorf("nested overlay is not supported")
-		}
-		if err := validateTrafficConfig(*tc.Overlay, true); err != nil {
-			return fmt.Errorf("overlay: %w", err)
+	if len(tc.Overlays) > 0 && isOverlay {
+		return fmt.Errorf("nested overlays are not supported")
+	}
+	for i, overlay := range tc.Overlays {
+		if err := validateTrafficConfig(overlay, true); err != nil {
+			return fmt.Errorf("overlays[%d]: %w", i, err)
 		}
 	}
 
@@ -856,6 +1566,9 @@ func validateCallChanges(scenarioName, ref string, override OverrideConfig, know
 		if err := validateCallConfig(call, knownOps); err != nil {
 			return fmt.Errorf("%s: add_calls: %w", prefix, err)
 		}
+		if call.Weight != 0 {
+			return fmt.Errorf("%s: add_calls: target %q: weight applies only to one_of calls", prefix, call.Target)
+		}
 	}
 	for _, rc := range override.RemoveCalls {
 		if !strings.Contains(rc.Target, ".") {
@@ -871,6 +1584,119 @@ func validateCallChanges(scenarioName, ref string, override OverrideConfig, know
 	return nil
 }
 
+// validateScenarioMatches checks that each match in sc has exactly one
+// selector that matches at least one operation, and that its override
+// fields are valid for every operation it could select.
+func validateScenarioMatches(sc ScenarioConfig, cfg *Config, knownServices map[string]bool) error {
+	for i, m := range sc.Match {
+		prefix := fmt.Sprintf("scenario %q: match %d", sc.Name, i+1)
+		if (m.Service == "") == (m.Domain == "") {
+			return fmt.Errorf("%s: exactly one of service or domain must be set", prefix)
+		}
+		if m.Service != "" && !knownServices[m.Service] {
+			return fmt.Errorf("%s: service %q references unknown service", prefix, m.Service)
+		}
+		matched := false
+		for _, svc := range cfg.Services {
+			for _, op := range svc.Operations {
+				if !op.Disabled && (m.Service == svc.Name || (m.Domain != "" && slices.Contains(op.DomainNames(), m.Domain))) {
+					matched = true
+				}
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: %s matches no operations", prefix, m.selector())
+		}
+		ov := m.OverrideConfig
+		if len(ov.AddCalls) > 0 || len(ov.RemoveCalls) > 0 || len(ov.Metrics) > 0 {
+			return fmt.Errorf("%s: add_calls, remove_calls, and metrics need an explicit service.operation override", prefix)
+		}
+		if ov.Duration != "" {
+			if _, err := ParseDistribution(ov.Duration); err != nil {
+				return fmt.Errorf("%s: invalid duration: %w", prefix, err)
+			}
+		}
+		if ov.ErrorRate != "" {
+			if _, err := parseErrorRate(ov.ErrorRate); err != nil {
+				return fmt.Errorf("%s: invalid error_rate: %w", prefix, err)
+			}
+		}
+		if ov.ErrorRateMultiply < 0 {
+			return fmt.Errorf("%s: error_rate_multiply must not be negative, got %v", prefix, ov.ErrorRateMultiply)
+		}
+		for attrName, attrCfg := range ov.Attributes {
+			if _, err := NewAttributeGenerator(attrCfg); err != nil {
+				return fmt.Errorf("%s: attribute %q: %w", prefix, attrName, err)
+			}
+		}
+		if err := validateLogOverrides(sc.Name, fmt.Sprintf("match %d", i+1), ov.Logs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCountDistribution checks a call's count_distribution, which
+// replaces a fixed count.
+func validateCountDistribution(call CallConfig) error {
+	if call.CountDistribution == "" {
+		return nil
+	}
+	if call.Count != 0 {
+		return fmt.Errorf("count and count_distribution are mutually exclusive")
+	}
+	if _, err := ParseCountDistribution(call.CountDistribution); err != nil {
+		return fmt.Errorf("invalid count_distribution: %w", err)
+	}
+	return nil
+}
+
+// validateSLO checks a service's SLO target and window.
+func validateSLO(slo *SLOConfig) error {
+	if slo == nil {
+		return nil
+	}
+	if slo.Target <= 0 || slo.Target >= 1 {
+		return fmt.Errorf("target must be greater than 0 and less than 1, e.g. 0.999, got %v", slo.Target)
+	}
+	if slo.Window != "" {
+		d, err := time.ParseDuration(slo.Window)
+		if err != nil {
+			return fmt.Errorf("invalid window: %w", err)
+		}
+		if d < time.Second {
+			return fmt.Errorf("window must be at least 1s, got %s", slo.Window)
+		}
+	}
+	return nil
+}
+
+// validateOneOf checks an operation's one_of group. Exactly one member fires
+// per span, chosen by weight, so members cannot set their own probability or
+// condition, and at least one weight must be positive.
+func validateOneOf(calls []CallConfig, knownOps map[string]bool) error {
+	if len(calls) == 0 {
+		return nil
+	}
+	total := 0
+	for _, call := range calls {
+		if err := validateCallConfig(call, knownOps); err != nil {
+			return err
+		}
+		if call.Weight < 0 {
+			return fmt.Errorf("target %q weight must not be negative, got %d", call.Target, call.Weight)
+		}
+		if call.Probability != 0 || call.ProbabilityDecay != 0 || call.Condition != "" {
+			return fmt.Errorf("target %q: one_of calls are chosen by weight and cannot set probability or condition", call.Target)
+		}
+		total += call.Weight
+	}
+	if total == 0 {
+		return fmt.Errorf("at least one call needs a positive weight")
+	}
+	return nil
+}
+
 // validateCallConfig checks a single CallConfig for structural correctness.
 func validateCallConfig(call CallConfig, knownOps map[string]bool) error {
 	if !strings.Contains(call.Target, ".") {
@@ -882,12 +1708,23 @@ func validateCallConfig(call CallConfig, knownOps map[string]bool) error {
 	if call.Probability < 0 || call.Probability > 1 {
 		return fmt.Errorf("target %q probability must be between 0 and 1", call.Target)
 	}
+	if call.ProbabilityDecay < 0 || call.ProbabilityDecay > 1 {
+		return fmt.Errorf("target %q probability decay must be between 0 and 1", call.Target)
+	}
 	if call.Condition != "" && call.Condition != "on-error" && call.Condition != "on-success" {
 		return fmt.Errorf("target %q condition must be \"on-error\" or \"on-success\", got %q", call.Target, call.Condition)
 	}
 	if call.Count < 0 {
 		return fmt.Errorf("target %q count must not be negative", call.Target)
 	}
+	if err := validateCountDistribution(call); err != nil {
+		return fmt.Errorf("target %q %w", call.Target, err)
+	}
+	if call.ErrorRate != "" {
+		if _, err := parseErrorRate(call.ErrorRate); err != nil {
+			return fmt.Errorf("target %q: %w", call.Target, err)
+		}
+	}
 	if call.Timeout != "" {
 		d, err := time.ParseDuration(call.Timeout)
 		if err != nil {
@@ -912,12 +1749,38 @@ func validateCallConfig(call CallConfig, knownOps map[string]bool) error {
 	if call.RetryBackoff != "" && call.Retries == 0 {
 		return fmt.Errorf("target %q retry_backoff requires retries > 0", call.Target)
 	}
+	if call.RetryBackoffMultiplier != 0 && call.RetryBackoffMultiplier < 1 {
+		return fmt.Errorf("target %q retry_backoff_multiplier must be >= 1, got %g", call.Target, call.RetryBackoffMultiplier)
+	}
+	if call.RetryJitter < 0 || call.RetryJitter > 1 {
+		return fmt.Errorf("target %q retry_jitter must be between 0 and 1, got %g", call.Target, call.RetryJitter)
+	}
+	if (call.RetryBackoffMultiplier != 0 || call.RetryJitter != 0) && call.Retries == 0 {
+		return fmt.Errorf("target %q retry_backoff_multiplier and retry_jitter require retries > 0", call.Target)
+	}
+	if call.HedgeAfter != "" {
+		d, err := time.ParseDuration(call.HedgeAfter)
+		if err != nil {
+			return fmt.Errorf("target %q invalid hedge_after: %w", call.Target, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("target %q hedge_after must be positive", call.Target)
+		}
+	}
 	if call.Async && call.Retries > 0 {
 		return fmt.Errorf("target %q: async calls cannot have retries", call.Target)
 	}
 	if call.Async && call.Timeout != "" {
 		return fmt.Errorf("target %q: async calls cannot have a timeout", call.Target)
 	}
+	if call.Async && call.HedgeAfter != "" {
+		return fmt.Errorf("target %q: async calls cannot be hedged", call.Target)
+	}
+	for attrName, attrCfg := range call.Attributes {
+		if _, err := NewAttributeGenerator(attrCfg); err != nil {
+			return fmt.Errorf("target %q: attribute %q: %w", call.Target, attrName, err)
+		}
+	}
 	if call.Producer && call.Async {
 		return fmt.Errorf("target %q: a call cannot be both producer and async", call.Target)
 	}
@@ -1076,6 +1939,31 @@ func validateLogConfig(lc LogConfig, prefix string) error {
 	return nil
 }
 
+// validateErrorTypes checks an operation's error categories: names must be
+// present and unique, weights non-negative, and attributes valid. prefix
+// identifies the scope in error messages.
+func validateErrorTypes(types []ErrorTypeConfig, prefix string) error {
+	seen := make(map[string]bool, len(types))
+	for i, et := range types {
+		if et.Name == "" {
+			return fmt.Errorf("%s: error_types[%d]: name is required", prefix, i)
+		}
+		if seen[et.Name] {
+			return fmt.Errorf("%s: duplicate error type %q", prefix, et.Name)
+		}
+		seen[et.Name] = true
+		if et.Weight != nil && *et.Weight <= 0 {
+			return fmt.Errorf("%s: error type %q: weight must be positive, got %d", prefix, et.Name, *et.Weight)
+		}
+		for attrName, attrCfg := range et.Attributes {
+			if _, err := NewAttributeGenerator(attrCfg); err != nil {
+				return fmt.Errorf("%s: error type %q: attribute %q: %w", prefix, et.Name, attrName, err)
+			}
+		}
+	}
+	return nil
+}
+
 // validateBaggage checks baggage keys and values for a service or operation.
 // Keys must be valid W3C baggage tokens (RFC 7230) so they survive propagation
 // across the simulated service boundary; values must be valid UTF-8. prefix
@@ -1097,6 +1985,28 @@ func validateBaggage(bag map[string]string, prefix string) error {
 	return nil
 }
 
+// validateGeneratedBaggage checks an operation's generated_baggage: keys
+// follow the same rules as static baggage and must not repeat a key of the
+// operation's static baggage, and each value must be a valid attribute
+// generator.
+func validateGeneratedBaggage(gens map[string]AttributeValueConfig, static map[string]string, prefix string) error {
+	for _, k := range slices.Sorted(maps.Keys(gens)) {
+		if k == "" {
+			return fmt.Errorf("%s: generated_baggage key must not be empty", prefix)
+		}
+		if _, err := baggage.NewMember(k, "x"); err != nil {
+			return fmt.Errorf("%s: invalid generated_baggage key %q (must be a valid W3C baggage token): %w", prefix, k, err)
+		}
+		if _, ok := static[k]; ok {
+			return fmt.Errorf("%s: baggage key %q is set in both baggage and generated_baggage", prefix, k)
+		}
+		if _, err := NewAttributeGenerator(gens[k]); err != nil {
+			return fmt.Errorf("%s: generated_baggage %q: %w", prefix, k, err)
+		}
+	}
+	return nil
+}
+
 // parseErrorRate parses a percentage string like "0.1%" or "15%" into a float64 (0.0 to 1.0).
 func parseErrorRate(s string) (float64, error) {
 	s = strings.TrimSpace(s)
@@ -5,6 +5,8 @@ package synth
 import (
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,6 +25,63 @@ func writeTestConfig(t *testing.T, content string) string {
 func TestParseConfig(t *testing.T) {
 	t.Parallel()
 
+	t.Run("percentile duration mapping", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      GET /users:
+        duration: {p99: 200ms, p50: 20ms}
+traffic:
+  rate: 10/s
+`))
+		require.NoError(t, err)
+		require.NoError(t, ValidateConfig(cfg))
+		assert.Equal(t, "p50=20ms p99=200ms", cfg.Services[0].Operations[0].Duration)
+
+		topo, err := BuildTopology(cfg)
+		require.NoError(t, err)
+		dur := topo.Services["gateway"].Operations["GET /users"].Duration
+		assert.Equal(t, KindPercentile, dur.Kind)
+		assert.Equal(t, 20*time.Millisecond, dur.P50)
+		assert.Equal(t, 200*time.Millisecond, dur.P99)
+	})
+
+	t.Run("percentile duration mapping with clamps", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      GET /users:
+        duration: {p50: 20ms, p99: 2s, max: 500ms, min: 5ms}
+traffic:
+  rate: 10/s
+`))
+		require.NoError(t, err)
+		require.NoError(t, ValidateConfig(cfg))
+		assert.Equal(t, "p50=20ms p99=2s [min=5ms, max=500ms]", cfg.Services[0].Operations[0].Duration)
+	})
+
+	t.Run("rejects a duration that is neither string nor mapping", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      GET /users:
+        duration: [20ms, 200ms]
+traffic:
+  rate: 10/s
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expected a string or a mapping of percentiles")
+	})
+
 	t.Run("normalizes service and operation maps", func(t *testing.T) {
 		t.Parallel()
 		cfg, err := ParseConfig([]byte(`
@@ -55,6 +114,56 @@ traffic:
 		assert.Equal(t, []CallConfig{{Target: "user-service.list"}}, cfg.Services[0].Operations[0].Calls)
 	})
 
+	t.Run("probability curve", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      list:
+        duration: 10ms
+        calls:
+          - target: db.query
+            probability: {base: 0.8, decay: 0.5}
+            count: 2
+          - target: db.query
+            probability: 0.3
+  db:
+    operations:
+      query:
+        duration: 5ms
+traffic:
+  rate: 10/s
+`))
+		require.NoError(t, err)
+		calls := cfg.Services[0].Operations[0].Calls
+		require.Len(t, calls, 2)
+		assert.Equal(t, CallConfig{Target: "db.query", Probability: 0.8, ProbabilityDecay: 0.5, Count: 2}, calls[0])
+		assert.Equal(t, CallConfig{Target: "db.query", Probability: 0.3}, calls[1])
+	})
+
+	t.Run("probability curve needs base and decay", func(t *testing.T) {
+		t.Parallel()
+		for _, curve := range []string{"{base: 0.8}", "{decay: 0.5}", "{base: 0.8, decay: 0}"} {
+			_, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      list:
+        duration: 10ms
+        calls:
+          - target: api.list
+            probability: ` + curve + `
+traffic:
+  rate: 10/s
+`))
+			require.Error(t, err, curve)
+			assert.Contains(t, err.Error(), "probability", curve)
+		}
+	})
+
 	t.Run("requires version", func(t *testing.T) {
 		t.Parallel()
 		_, err := ParseConfig([]byte(`
@@ -70,6 +179,91 @@ traffic:
 		assert.Contains(t, err.Error(), "missing required field: version")
 	})
 
+	t.Run("anchored operation reused across services", func(t *testing.T) {
+		t.Parallel()
+		src := []byte(`
+version: 1
+x-handler: &handler
+  duration: 20ms +/- 5ms
+  error_rate: 1%
+  attributes:
+    http.route: {value: /items}
+  calls:
+    - target: db.query
+      probability: {base: 0.9, decay: 0.5}
+services:
+  web:
+    operations:
+      handle: *handler
+  mobile:
+    operations:
+      handle:
+        <<: *handler
+  db:
+    operations:
+      query: {duration: 5ms}
+traffic:
+  rate: 10/s
+`)
+		cfg, err := ParseConfig(src)
+		require.NoError(t, err)
+		require.NoError(t, ValidateConfig(cfg))
+
+		names := make([]string, len(cfg.Services))
+		ops := make(map[string]OperationConfig)
+		for i, svc := range cfg.Services {
+			names[i] = svc.Name
+			for _, op := range svc.Operations {
+				ops[svc.Name+"."+op.Name] = op
+			}
+		}
+		assert.Equal(t, []string{"db", "mobile", "web"}, names, "services are ordered by name")
+		assert.Equal(t, ops["web.handle"], ops["mobile.handle"])
+		assert.Equal(t, 0.9, ops["mobile.handle"].Calls[0].Probability)
+
+		again, err := ParseConfig(src)
+		require.NoError(t, err)
+		assert.Equal(t, cfg, again, "parsing is deterministic")
+	})
+
+	t.Run("merged call keys", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := ParseConfig([]byte(`
+version: 1
+x-call: &call
+  target: db.query
+  retries: 2
+  probability: {base: 0.9, decay: 0.5}
+services:
+  web:
+    operations:
+      handle:
+        duration: 10ms
+        calls:
+          - <<: *call
+            retries: 1
+          - <<: *call
+            probability: 0.5
+  db:
+    operations:
+      query: {duration: 5ms}
+traffic:
+  rate: 10/s
+`))
+		require.NoError(t, err)
+		calls := cfg.Services[1].Operations[0].Calls
+		require.Len(t, calls, 2)
+
+		assert.Equal(t, "db.query", calls[0].Target)
+		assert.Equal(t, 1, calls[0].Retries, "the call's own keys win")
+		assert.Equal(t, 0.9, calls[0].Probability, "a merged probability curve is kept")
+		assert.Equal(t, 0.5, calls[0].ProbabilityDecay)
+
+		assert.Equal(t, 2, calls[1].Retries)
+		assert.Equal(t, 0.5, calls[1].Probability, "the call's own probability replaces the merged curve")
+		assert.Zero(t, calls[1].ProbabilityDecay)
+	})
+
 	t.Run("reports invalid YAML", func(t *testing.T) {
 		t.Parallel()
 		_, err := ParseConfig([]byte(`{{{invalid yaml`))
@@ -278,6 +472,27 @@ traffic:
 		assert.Equal(t, "/api/v1/users", op.Attributes["http.route"].Value)
 	})
 
+	t.Run("domains list parsed", func(t *testing.T) {
+		t.Parallel()
+		path := writeTestConfig(t, `
+version: 1
+services:
+  gateway:
+    operations:
+      GET /users:
+        domain: http
+        domains: [url, user_agent]
+        duration: 30ms
+traffic:
+  rate: 100/s
+`)
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err)
+		op := cfg.Services[0].Operations[0]
+		assert.Equal(t, []string{"url", "user_agent"}, op.Domains)
+		assert.Equal(t, []string{"http", "url", "user_agent"}, op.DomainNames())
+	})
+
 	t.Run("domain field optional", func(t *testing.T) {
 		t.Parallel()
 		path := writeTestConfig(t, `
@@ -629,6 +844,144 @@ func TestValidateConfig(t *testing.T) {
 		assert.Contains(t, err.Error(), "call_style")
 	})
 
+	t.Run("invalid call_jitter", func(t *testing.T) {
+		t.Parallel()
+		cfg := &Config{
+			Services: []ServiceConfig{{
+				Name: "svc",
+				Operations: []OperationConfig{{
+					Name:       "op",
+					Duration:   "10ms",
+					CallJitter: "soon",
+				}},
+			}},
+			Traffic: TrafficConfig{Rate: "100/s"},
+		}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid call_jitter")
+	})
+
+	t.Run("call_jitter with sequential call_style", func(t *testing.T) {
+		t.Parallel()
+		cfg := &Config{
+			Services: []ServiceConfig{{
+				Name: "svc",
+				Operations: []OperationConfig{{
+					Name:       "op",
+					Duration:   "10ms",
+					CallStyle:  "sequential",
+					CallJitter: "1ms",
+				}},
+			}},
+			Traffic: TrafficConfig{Rate: "100/s"},
+		}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "call_jitter applies only to parallel calls")
+	})
+
+	for _, tt := range []struct {
+		name    string
+		call    CallConfig
+		wantErr string
+	}{
+		{"count_distribution with count", CallConfig{Target: "svc.leaf", Count: 2, CountDistribution: "poisson(mean=3)"}, `call "svc.leaf" count and count_distribution are mutually exclusive`},
+		{"invalid count_distribution", CallConfig{Target: "svc.leaf", CountDistribution: "poisson(mean=-1)"}, `call "svc.leaf" invalid count_distribution: poisson mean must be greater than 0`},
+		{"probability decay above one", CallConfig{Target: "svc.leaf", Probability: 0.5, ProbabilityDecay: 1.5}, `call "svc.leaf" probability decay must be between 0 and 1`},
+		{"weight outside one_of", CallConfig{Target: "svc.leaf", Weight: 2}, `call "svc.leaf": weight applies only to one_of calls`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{
+				Services: []ServiceConfig{{
+					Name: "svc",
+					Operations: []OperationConfig{
+						{Name: "op", Duration: "10ms", Calls: []CallConfig{tt.call}},
+						{Name: "leaf", Duration: "10ms"},
+					},
+				}},
+				Traffic: TrafficConfig{Rate: "100/s"},
+			}
+			err := ValidateConfig(cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	for _, tt := range []struct {
+		name    string
+		oneOf   []CallConfig
+		wantErr string
+	}{
+		{"one_of negative weight", []CallConfig{{Target: "svc.leaf", Weight: -1}, {Target: "svc.other", Weight: 2}}, `one_of: target "svc.leaf" weight must not be negative`},
+		{"one_of all zero weights", []CallConfig{{Target: "svc.leaf"}, {Target: "svc.other"}}, "one_of: at least one call needs a positive weight"},
+		{"one_of with probability", []CallConfig{{Target: "svc.leaf", Weight: 1, Probability: 0.5}}, `one_of: target "svc.leaf": one_of calls are chosen by weight and cannot set probability or condition`},
+		{"one_of with condition", []CallConfig{{Target: "svc.leaf", Weight: 1, Condition: "on-error"}}, "cannot set probability or condition"},
+		{"one_of unknown target", []CallConfig{{Target: "svc.missing", Weight: 1}}, `one_of: target "svc.missing" references unknown operation`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{
+				Services: []ServiceConfig{{
+					Name: "svc",
+					Operations: []OperationConfig{
+						{Name: "op", Duration: "10ms", OneOf: tt.oneOf},
+						{Name: "leaf", Duration: "10ms"},
+						{Name: "other", Duration: "10ms"},
+					},
+				}},
+				Traffic: TrafficConfig{Rate: "100/s"},
+			}
+			err := ValidateConfig(cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	for _, tt := range []struct {
+		name    string
+		slo     SLOConfig
+		wantErr string
+	}{
+		{"slo target zero", SLOConfig{}, "slo: target must be greater than 0 and less than 1"},
+		{"slo target one", SLOConfig{Target: 1}, "slo: target must be greater than 0 and less than 1"},
+		{"slo target percentage", SLOConfig{Target: 99.9}, "slo: target must be greater than 0 and less than 1"},
+		{"slo invalid window", SLOConfig{Target: 0.99, Window: "soon"}, "slo: invalid window"},
+		{"slo short window", SLOConfig{Target: 0.99, Window: "10ms"}, "slo: window must be at least 1s"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{
+				Services: []ServiceConfig{{
+					Name:       "svc",
+					SLO:        &tt.slo,
+					Operations: []OperationConfig{{Name: "op", Duration: "10ms"}},
+				}},
+				Traffic: TrafficConfig{Rate: "100/s"},
+			}
+			err := ValidateConfig(cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("one_of with a zero weight branch", func(t *testing.T) {
+		t.Parallel()
+		cfg := &Config{
+			Services: []ServiceConfig{{
+				Name: "svc",
+				Operations: []OperationConfig{
+					{Name: "op", Duration: "10ms", OneOf: []CallConfig{{Target: "svc.leaf", Weight: 3}, {Target: "svc.other"}}},
+					{Name: "leaf", Duration: "10ms"},
+					{Name: "other", Duration: "10ms"},
+				},
+			}},
+			Traffic: TrafficConfig{Rate: "100/s"},
+		}
+		require.NoError(t, ValidateConfig(cfg))
+	})
+
 	t.Run("valid operation attributes", func(t *testing.T) {
 		t.Parallel()
 		cfg := &Config{
@@ -1033,16 +1386,13 @@ func TestValidateConfig(t *testing.T) {
 		assert.Contains(t, err.Error(), "segments")
 	})
 
-	t.Run("overlay validated recursively", func(t *testing.T) {
+	t.Run("overlays validated recursively", func(t *testing.T) {
 		t.Parallel()
 		cfg := validBaseConfig()
 		cfg.Traffic.Pattern = "diurnal"
-		cfg.Traffic.Overlay = &TrafficConfig{
-			Rate:            "100/s",
-			Pattern:         "bursty",
-			BurstMultiplier: 3,
-			BurstInterval:   "2m",
-			BurstDuration:   "15s",
+		cfg.Traffic.Overlays = []TrafficConfig{
+			{Rate: "100/s", Pattern: "bursty", BurstMultiplier: 3, BurstInterval: "2m", BurstDuration: "15s"},
+			{Rate: "20/s", Pattern: "bursty", BurstInterval: "7m"},
 		}
 		require.NoError(t, ValidateConfig(cfg))
 	})
@@ -1050,32 +1400,47 @@ func TestValidateConfig(t *testing.T) {
 	t.Run("overlay with invalid config", func(t *testing.T) {
 		t.Parallel()
 		cfg := validBaseConfig()
-		cfg.Traffic.Overlay = &TrafficConfig{
-			Rate:          "100/s",
-			Pattern:       "bursty",
-			BurstInterval: "bad",
+		cfg.Traffic.Overlays = []TrafficConfig{
+			{Rate: "100/s"},
+			{Rate: "100/s", Pattern: "bursty", BurstInterval: "bad"},
 		}
 		err := ValidateConfig(cfg)
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "overlay")
+		assert.Contains(t, err.Error(), "overlays[1]")
 	})
 
 	t.Run("nested overlay rejected", func(t *testing.T) {
 		t.Parallel()
 		cfg := validBaseConfig()
-		cfg.Traffic.Overlay = &TrafficConfig{
-			Rate:    "100/s",
-			Pattern: "uniform",
-			Overlay: &TrafficConfig{
-				Rate:    "100/s",
-				Pattern: "bursty",
-			},
-		}
+		cfg.Traffic.Overlays = []TrafficConfig{{
+			Rate:     "100/s",
+			Pattern:  "uniform",
+			Overlays: []TrafficConfig{{Rate: "100/s", Pattern: "bursty"}},
+		}}
 		err := ValidateConfig(cfg)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "nested")
 	})
 
+	t.Run("single overlay mapping rejected", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      GET /users:
+        duration: 10ms
+traffic:
+  rate: 10/s
+  overlay:
+    rate: 10/s
+    pattern: bursty
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "replaced by overlays")
+	})
+
 	t.Run("valid call timeout", func(t *testing.T) {
 		t.Parallel()
 		cfg := twoServiceConfig()
@@ -1163,12 +1528,89 @@ func TestValidateConfig(t *testing.T) {
 		assert.Contains(t, err.Error(), "retry_backoff requires retries > 0")
 	})
 
+	t.Run("invalid call error_rate rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := twoServiceConfig()
+		cfg.Services[0].Operations[0].Calls[0].ErrorRate = "half"
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `call "other.op": invalid error_rate`)
+	})
+
+	t.Run("retry_backoff_multiplier below 1 rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := twoServiceConfig()
+		cfg.Services[0].Operations[0].Calls[0].Retries = 2
+		cfg.Services[0].Operations[0].Calls[0].RetryBackoffMultiplier = 0.5
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "retry_backoff_multiplier must be >= 1")
+	})
+
+	t.Run("retry_jitter out of range rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := twoServiceConfig()
+		cfg.Services[0].Operations[0].Calls[0].Retries = 2
+		cfg.Services[0].Operations[0].Calls[0].RetryJitter = 1.5
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "retry_jitter must be between 0 and 1")
+	})
+
+	t.Run("retry_jitter without retries rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := twoServiceConfig()
+		cfg.Services[0].Operations[0].Calls[0].RetryJitter = 0.5
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "require retries > 0")
+	})
+
+	t.Run("invalid hedge_after rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := twoServiceConfig()
+		cfg.Services[0].Operations[0].Calls[0].HedgeAfter = "soon"
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid hedge_after")
+	})
+
+	t.Run("zero hedge_after rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := twoServiceConfig()
+		cfg.Services[0].Operations[0].Calls[0].HedgeAfter = "0s"
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "hedge_after must be positive")
+	})
+
+	t.Run("async hedge_after rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := twoServiceConfig()
+		cfg.Services[0].Operations[0].Calls[0].Async = true
+		cfg.Services[0].Operations[0].Calls[0].HedgeAfter = "50ms"
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "async calls cannot be hedged")
+	})
+
 	t.Run("call without timeout or retries unchanged", func(t *testing.T) {
 		t.Parallel()
 		cfg := twoServiceConfig()
 		require.NoError(t, ValidateConfig(cfg))
 	})
 
+	t.Run("invalid default_attributes rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].DefaultAttributes = map[string]AttributeValueConfig{
+			"tier": {Range: []int64{5}},
+		}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `default_attributes "tier"`)
+	})
+
 	t.Run("negative queue_depth rejected", func(t *testing.T) {
 		t.Parallel()
 		cfg := validBaseConfig()
@@ -1192,15 +1634,117 @@ func TestValidateConfig(t *testing.T) {
 		require.NoError(t, ValidateConfig(cfg))
 	})
 
-	t.Run("backpressure missing latency_threshold rejected", func(t *testing.T) {
+	t.Run("negative concurrency rejected", func(t *testing.T) {
 		t.Parallel()
 		cfg := validBaseConfig()
-		cfg.Services[0].Operations[0].Backpressure = &BackpressureConfig{
-			DurationMultiplier: 2.0,
-		}
+		cfg.Services[0].Operations[0].Concurrency = -1
 		err := ValidateConfig(cfg)
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "backpressure requires latency_threshold")
+		assert.Contains(t, err.Error(), "concurrency must not be negative")
+	})
+
+	t.Run("concurrency with mm1 rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].Concurrency = 4
+		cfg.Services[0].Operations[0].Backpressure = &BackpressureConfig{QueueModel: "mm1"}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be combined with concurrency")
+	})
+
+	t.Run("negative error_rate_at_peak rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].ErrorRateAtPeak = -2
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error_rate_at_peak must not be negative")
+	})
+
+	t.Run("blank span_name rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].SpanName = "  "
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "span_name must not be blank")
+	})
+
+	t.Run("root traffic accepted", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].Traffic = &TrafficConfig{Rate: "1/s"}
+		require.NoError(t, ValidateConfig(cfg))
+	})
+
+	t.Run("root traffic invalid", func(t *testing.T) {
+		t.Parallel()
+		for _, tc := range []struct {
+			traffic TrafficConfig
+			want    string
+		}{
+			{TrafficConfig{}, "traffic requires rate"},
+			{TrafficConfig{Rate: "fast"}, "traffic: "},
+			{TrafficConfig{Rate: "1/s", Period: "1m"}, "only valid with pattern \"diurnal\""},
+		} {
+			cfg := validBaseConfig()
+			cfg.Services[0].Operations[0].Traffic = &tc.traffic
+			err := ValidateConfig(cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+		}
+	})
+
+	t.Run("traffic on called operation rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations = append(cfg.Services[0].Operations, OperationConfig{
+			Name:     "child",
+			Duration: "1ms",
+			Traffic:  &TrafficConfig{Rate: "1/s"},
+		})
+		cfg.Services[0].Operations[0].Calls = []CallConfig{{Target: "svc.child"}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `operation "child": traffic applies only to root operations`)
+	})
+
+	t.Run("cold_start accepted", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].ColdStart = &ColdStartConfig{Latency: "200ms", Invocations: 5}
+		require.NoError(t, ValidateConfig(cfg))
+	})
+
+	t.Run("cold_start invalid", func(t *testing.T) {
+		t.Parallel()
+		for _, tc := range []struct {
+			cs   ColdStartConfig
+			want string
+		}{
+			{ColdStartConfig{Invocations: 5}, "cold_start requires latency"},
+			{ColdStartConfig{Latency: "soon", Invocations: 5}, "cold_start: invalid latency"},
+			{ColdStartConfig{Latency: "0s", Invocations: 5}, "cold_start: latency must be positive"},
+			{ColdStartConfig{Latency: "200ms"}, "cold_start: invocations must be positive"},
+		} {
+			cfg := validBaseConfig()
+			cfg.Services[0].Operations[0].ColdStart = &tc.cs
+			err := ValidateConfig(cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+		}
+	})
+
+	t.Run("backpressure missing latency_threshold rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].Backpressure = &BackpressureConfig{
+			DurationMultiplier: 2.0,
+		}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "backpressure requires latency_threshold")
 	})
 
 	t.Run("backpressure invalid latency_threshold rejected", func(t *testing.T) {
@@ -1249,6 +1793,34 @@ func TestValidateConfig(t *testing.T) {
 		require.NoError(t, ValidateConfig(cfg))
 	})
 
+	t.Run("backpressure mm1 without latency_threshold accepted", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].Backpressure = &BackpressureConfig{QueueModel: "mm1"}
+		require.NoError(t, ValidateConfig(cfg))
+	})
+
+	t.Run("backpressure mm1 with multiplier rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].Backpressure = &BackpressureConfig{
+			QueueModel:         "mm1",
+			DurationMultiplier: 2.0,
+		}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "queue_model mm1 cannot be combined")
+	})
+
+	t.Run("backpressure unknown queue_model rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].Backpressure = &BackpressureConfig{QueueModel: "mg1"}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown queue_model "mg1"`)
+	})
+
 	t.Run("circuit_breaker missing failure_threshold rejected", func(t *testing.T) {
 		t.Parallel()
 		cfg := validBaseConfig()
@@ -1311,6 +1883,80 @@ func TestValidateConfig(t *testing.T) {
 		assert.Contains(t, err.Error(), "invalid cooldown")
 	})
 
+	t.Run("circuit_breaker zero half_open_max_probes rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		zero := 0
+		cfg.Services[0].Operations[0].CircuitBreaker = &CircuitBreakerConfig{
+			FailureThreshold:  5,
+			Window:            "1m",
+			Cooldown:          "30s",
+			HalfOpenMaxProbes: &zero,
+		}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "half_open_max_probes must be positive")
+	})
+
+	t.Run("cache hit_rate out of range rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := twoServiceConfig()
+		cfg.Services[0].Operations[0].Cache = &CacheConfig{HitRate: 1.5}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "hit_rate must be between 0 and 1")
+	})
+
+	t.Run("cache skip_calls target not called rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := twoServiceConfig()
+		cfg.Services[0].Operations[0].Cache = &CacheConfig{HitRate: 0.5, SkipCalls: []string{"svc.op"}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `skip_calls target "svc.op" is not called by this operation`)
+	})
+
+	t.Run("valid cache accepted", func(t *testing.T) {
+		t.Parallel()
+		cfg := twoServiceConfig()
+		cfg.Services[0].Operations[0].Cache = &CacheConfig{HitRate: 0.8, SkipCalls: []string{"other.op"}}
+		require.NoError(t, ValidateConfig(cfg))
+	})
+
+	t.Run("rate_limit without limits rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].RateLimit = &RateLimitConfig{}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rate_limit requires max_rate or max_concurrency")
+	})
+
+	t.Run("rate_limit invalid max_rate rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].RateLimit = &RateLimitConfig{MaxRate: "fast"}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rate_limit: invalid max_rate")
+	})
+
+	t.Run("rate_limit negative max_concurrency rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].RateLimit = &RateLimitConfig{MaxConcurrency: -1}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "max_concurrency must not be negative")
+	})
+
+	t.Run("valid rate_limit accepted", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		cfg.Services[0].Operations[0].RateLimit = &RateLimitConfig{MaxRate: "50/s", MaxConcurrency: 4}
+		require.NoError(t, ValidateConfig(cfg))
+	})
+
 	t.Run("valid circuit_breaker accepted", func(t *testing.T) {
 		t.Parallel()
 		cfg := validBaseConfig()
@@ -1321,6 +1967,19 @@ func TestValidateConfig(t *testing.T) {
 		}
 		require.NoError(t, ValidateConfig(cfg))
 	})
+
+	t.Run("valid half_open_max_probes accepted", func(t *testing.T) {
+		t.Parallel()
+		cfg := validBaseConfig()
+		probes := 3
+		cfg.Services[0].Operations[0].CircuitBreaker = &CircuitBreakerConfig{
+			FailureThreshold:  5,
+			Window:            "1m",
+			Cooldown:          "30s",
+			HalfOpenMaxProbes: &probes,
+		}
+		require.NoError(t, ValidateConfig(cfg))
+	})
 }
 
 func twoServiceConfig() *Config {
@@ -1442,6 +2101,43 @@ scenarios:
 	})
 }
 
+func TestValidateConfigReportsEveryError(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: fast
+  backend:
+    operations:
+      work:
+        duration: 5ms
+        calls:
+          - backend.missing
+traffic:
+  rate: lots
+scenarios:
+  - name: outage
+    at: +1m
+    duration: -5m
+`))
+	require.NoError(t, err)
+
+	err = ValidateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `service "gateway" operation "request": invalid duration`)
+	assert.Contains(t, err.Error(), `service "backend" operation "work": call "backend.missing" references unknown operation`)
+	assert.Contains(t, err.Error(), "invalid traffic rate")
+	assert.Contains(t, err.Error(), `scenario "outage": duration must be positive`)
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	require.True(t, ok, "errors are joined")
+	assert.Len(t, joined.Unwrap(), 4)
+}
+
 func TestValidateConfigLinks(t *testing.T) {
 	t.Parallel()
 
@@ -2262,6 +2958,539 @@ traffic:
 	})
 }
 
+func TestValidateConfigErrorTypes(t *testing.T) {
+	t.Parallel()
+
+	negative, zero := -1, 0
+	tests := []struct {
+		name    string
+		types   []ErrorTypeConfig
+		wantErr string
+	}{
+		{"missing name", []ErrorTypeConfig{{}}, "error_types[0]: name is required"},
+		{"duplicate name", []ErrorTypeConfig{{Name: "timeout"}, {Name: "timeout"}}, `duplicate error type "timeout"`},
+		{"negative weight", []ErrorTypeConfig{{Name: "timeout", Weight: &negative}}, `error type "timeout": weight must be positive, got -1`},
+		{"zero weight", []ErrorTypeConfig{{Name: "timeout", Weight: &zero}}, `error type "timeout": weight must be positive, got 0`},
+		{
+			"invalid attribute",
+			[]ErrorTypeConfig{{Name: "timeout", Attributes: map[string]AttributeValueConfig{"k": {}}}},
+			`error type "timeout": attribute "k"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{
+				Version: 1,
+				Services: []ServiceConfig{{
+					Name: "api",
+					Operations: []OperationConfig{{
+						Name:       "handle",
+						Duration:   "10ms",
+						ErrorRate:  "1%",
+						ErrorTypes: tt.types,
+					}},
+				}},
+				Traffic: TrafficConfig{Rate: "10/s"},
+			}
+			err := ValidateConfig(cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	t.Run("parses from YAML", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      handle:
+        duration: 10ms
+        error_rate: 5%
+        error_types:
+          - name: timeout
+            weight: 3
+            message: upstream timed out
+            attributes:
+              error.type:
+                value: timeout
+          - name: unavailable
+traffic:
+  rate: 10/s
+`))
+		require.NoError(t, err)
+		require.NoError(t, ValidateConfig(cfg))
+		types := cfg.Services[0].Operations[0].ErrorTypes
+		require.Len(t, types, 2)
+		assert.Equal(t, "timeout", types[0].Name)
+		require.NotNil(t, types[0].Weight)
+		assert.Equal(t, 3, *types[0].Weight)
+		assert.Nil(t, types[1].Weight, "an unset weight is left for the default")
+		assert.Equal(t, "upstream timed out", types[0].Message)
+		assert.Equal(t, "timeout", types[0].Attributes["error.type"].Value)
+
+		topo, err := BuildTopology(cfg)
+		require.NoError(t, err)
+		resolved := topo.Services["api"].Operations["handle"].ErrorTypes
+		require.Len(t, resolved, 2)
+		assert.Equal(t, 3, resolved[0].Weight)
+		assert.Equal(t, 1, resolved[1].Weight, "an unset weight defaults to 1")
+	})
+}
+
+func TestValidateConfigTraceState(t *testing.T) {
+	t.Parallel()
+
+	many := make(map[string]string, 30)
+	for i := range 30 {
+		many["k"+strconv.Itoa(i)] = "v"
+	}
+	tests := []struct {
+		name    string
+		top     map[string]string
+		svc     map[string]string
+		wantErr string
+	}{
+		{name: "valid", top: map[string]string{"vendor": "a", "tenant@sys": "b"}, svc: map[string]string{"vendor": "c"}},
+		{name: "uppercase key", top: map[string]string{"Vendor": "a"}, wantErr: `top level: tracestate entry "Vendor"="a"`},
+		{name: "comma in value", svc: map[string]string{"vendor": "a,b"}, wantErr: `service "api": tracestate entry "vendor"="a,b"`},
+		{name: "empty value", svc: map[string]string{"vendor": ""}, wantErr: `service "api": tracestate entry "vendor"=""`},
+		{name: "too many merged entries", top: many, svc: map[string]string{"x": "1", "y": "2", "z": "3", "k0": "4"}, wantErr: `service "api": tracestate has 33 entries, more than the W3C limit of 32`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{
+				Version:    1,
+				TraceState: tt.top,
+				Services: []ServiceConfig{{
+					Name:       "api",
+					TraceState: tt.svc,
+					Operations: []OperationConfig{{Name: "handle", Duration: "10ms"}},
+				}},
+				Traffic: TrafficConfig{Rate: "10/s"},
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateConfigSlowStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		threshold string
+		status    string
+		wantErr   string
+	}{
+		{name: "threshold only", threshold: "100ms"},
+		{name: "attribute", threshold: "100ms", status: "attribute"},
+		{name: "error", threshold: "100ms", status: "error"},
+		{name: "unset", threshold: "100ms", status: "unset"},
+		{name: "status without threshold", status: "error", wantErr: "slow_status requires slow_threshold"},
+		{name: "bad threshold", threshold: "soon", wantErr: "invalid slow_threshold"},
+		{name: "non-positive threshold", threshold: "0s", wantErr: "slow_threshold must be positive"},
+		{name: "unknown status", threshold: "100ms", status: "warn", wantErr: `slow_status must be "unset", "error", or "attribute"; got "warn"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{
+				Version: 1,
+				Services: []ServiceConfig{{
+					Name: "api",
+					Operations: []OperationConfig{{
+						Name:          "handle",
+						Duration:      "10ms",
+						SlowThreshold: tt.threshold,
+						SlowStatus:    tt.status,
+					}},
+				}},
+				Traffic: TrafficConfig{Rate: "10/s"},
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `service "api" operation "handle": `+tt.wantErr)
+		})
+	}
+}
+
+func TestValidateConfigCallAttributes(t *testing.T) {
+	t.Parallel()
+
+	bad := map[string]AttributeValueConfig{"caller.path": {}}
+	base := func() *Config {
+		return &Config{
+			Version: 1,
+			Services: []ServiceConfig{
+				{Name: "api", Operations: []OperationConfig{{Name: "handle", Duration: "10ms"}}},
+				{Name: "db", Operations: []OperationConfig{{Name: "query", Duration: "5ms"}}},
+			},
+			Traffic: TrafficConfig{Rate: "10/s"},
+		}
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Services[0].Operations[0].Calls = []CallConfig{{
+			Target:     "db.query",
+			Attributes: map[string]AttributeValueConfig{"caller.path": {Value: "checkout"}},
+		}}
+		assert.NoError(t, ValidateConfig(cfg))
+	})
+
+	t.Run("call", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Services[0].Operations[0].Calls = []CallConfig{{Target: "db.query", Attributes: bad}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `service "api" operation "handle": call "db.query": attribute "caller.path"`)
+	})
+
+	t.Run("one_of", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Services[0].Operations[0].OneOf = []CallConfig{{Target: "db.query", Weight: 1, Attributes: bad}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `one_of: target "db.query": attribute "caller.path"`)
+	})
+
+	t.Run("add_calls", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Scenarios = []ScenarioConfig{{
+			Name: "fanout", At: "+0s", Duration: "1m",
+			Override: map[string]OverrideConfig{"api.handle": {AddCalls: []CallConfig{{Target: "db.query", Attributes: bad}}}},
+		}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `add_calls: target "db.query": attribute "caller.path"`)
+	})
+}
+
+func TestValidateConfigTiming(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		timing  string
+		wantErr string
+	}{
+		{name: "default"},
+		{name: "wait-all", timing: "wait-all"},
+		{name: "wait-critical", timing: "wait-critical"},
+		{name: "return-early", timing: "return-early"},
+		{name: "unknown", timing: "async", wantErr: `timing must be "wait-all", "wait-critical", or "return-early"; got "async"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{
+				Version: 1,
+				Services: []ServiceConfig{{
+					Name:       "api",
+					Operations: []OperationConfig{{Name: "handle", Duration: "10ms", Timing: tt.timing}},
+				}},
+				Traffic: TrafficConfig{Rate: "10/s"},
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `service "api" operation "handle": `+tt.wantErr)
+		})
+	}
+}
+
+func TestValidateConfigFlap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		flap    FlapConfig
+		wantErr string
+	}{
+		{name: "valid", flap: FlapConfig{UpDuration: "5m", DownDuration: "30s"}},
+		{name: "jitter", flap: FlapConfig{UpDuration: "5m", DownDuration: "30s", Jitter: "10s"}},
+		{name: "missing up", flap: FlapConfig{DownDuration: "30s"}, wantErr: "invalid up_duration"},
+		{name: "zero down", flap: FlapConfig{UpDuration: "5m", DownDuration: "0s"}, wantErr: "down_duration must be positive, got 0s"},
+		{name: "negative jitter", flap: FlapConfig{UpDuration: "5m", DownDuration: "30s", Jitter: "-1s"}, wantErr: "jitter must not be negative"},
+		{name: "jitter too large", flap: FlapConfig{UpDuration: "5m", DownDuration: "30s", Jitter: "30s"}, wantErr: "jitter must be less than up_duration and down_duration, got 30s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{
+				Version: 1,
+				Services: []ServiceConfig{{
+					Name:       "db",
+					Flap:       &tt.flap,
+					Operations: []OperationConfig{{Name: "query", Duration: "10ms"}},
+				}},
+				Traffic: TrafficConfig{Rate: "10/s"},
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `service "db": flap: `+tt.wantErr)
+		})
+	}
+}
+
+func TestValidateConfigDisabled(t *testing.T) {
+	t.Parallel()
+
+	base := func() *Config {
+		return &Config{
+			Version: 1,
+			Services: []ServiceConfig{
+				{Name: "api", Operations: []OperationConfig{
+					{Name: "handle", Duration: "10ms", Calls: []CallConfig{{Target: "db.query"}}},
+				}},
+				{Name: "db", Operations: []OperationConfig{
+					{Name: "query", Duration: "5ms", Disabled: true},
+				}},
+			},
+			Traffic: TrafficConfig{Rate: "10/s"},
+		}
+	}
+
+	t.Run("calls to a disabled operation are skipped", func(t *testing.T) {
+		t.Parallel()
+		assert.NoError(t, ValidateConfig(base()))
+	})
+
+	t.Run("link to a disabled operation", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Services[0].Operations[0].Links = []LinkConfig{{Ref: "db.query"}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `service "api" operation "handle": link "db.query" references a disabled operation`)
+	})
+
+	t.Run("link from a disabled operation", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Services[0].Operations = append(cfg.Services[0].Operations,
+			OperationConfig{Name: "batch", Duration: "1ms", Disabled: true, Links: []LinkConfig{{Ref: "db.query"}}})
+		assert.NoError(t, ValidateConfig(cfg))
+	})
+
+	t.Run("scenario override of a disabled operation", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Scenarios = []ScenarioConfig{{
+			Name: "slow", At: "+0s", Duration: "1m",
+			Override: map[string]OverrideConfig{"db.query": {Duration: "1s"}},
+		}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `scenario "slow": override "db.query" references a disabled operation`)
+	})
+
+	t.Run("scenario override of a fully disabled service", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Scenarios = []ScenarioConfig{{
+			Name: "quiet", At: "+0s", Duration: "1m",
+			Override: map[string]OverrideConfig{"db": {Logs: &LogOverrideConfig{Disable: true}}},
+		}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `scenario "quiet": override "db" references a service whose operations are all disabled`)
+	})
+
+	t.Run("scenario adding a call to a disabled operation", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Scenarios = []ScenarioConfig{{
+			Name: "fanout", At: "+0s", Duration: "1m",
+			Override: map[string]OverrideConfig{"api.handle": {AddCalls: []CallConfig{{Target: "db.query"}}}},
+		}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `add_calls "db.query" references a disabled operation`)
+	})
+
+	t.Run("one_of left without weight", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Services[0].Operations[0].Calls = nil
+		cfg.Services[0].Operations[0].OneOf = []CallConfig{{Target: "db.query", Weight: 1}, {Target: "api.fallback"}}
+		cfg.Services[0].Operations = append(cfg.Services[0].Operations, OperationConfig{Name: "fallback", Duration: "1ms"})
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "one_of: every call with a positive weight is disabled")
+	})
+
+	t.Run("scenario draining a fully disabled service", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Scenarios = []ScenarioConfig{{Name: "maintenance", At: "+0s", Duration: "1m", Drain: []string{"db"}}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `scenario "maintenance": drain "db" references a service whose operations are all disabled`)
+	})
+
+	t.Run("every operation disabled", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Services[0].Operations[0].Disabled = true
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "every operation is disabled")
+	})
+}
+
+func TestValidateConfigScenarioDrain(t *testing.T) {
+	t.Parallel()
+
+	base := func(drain []string, mode string) *Config {
+		return &Config{
+			Version: 1,
+			Services: []ServiceConfig{
+				{Name: "api", Operations: []OperationConfig{
+					{Name: "handle", Duration: "10ms", Calls: []CallConfig{{Target: "db.query"}}},
+				}},
+				{Name: "db", Operations: []OperationConfig{{Name: "query", Duration: "5ms"}}},
+			},
+			Traffic: TrafficConfig{Rate: "10/s"},
+			Scenarios: []ScenarioConfig{{
+				Name: "maintenance", At: "+1m", Duration: "5m",
+				Drain: drain, DrainMode: mode,
+			}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		drain   []string
+		mode    string
+		wantErr string
+	}{
+		{name: "default mode", drain: []string{"db"}},
+		{name: "skip mode", drain: []string{"db"}, mode: "skip"},
+		{name: "error mode", drain: []string{"db"}, mode: "error"},
+		{name: "unknown service", drain: []string{"cache"}, wantErr: `scenario "maintenance": drain: "cache" references unknown service`},
+		{name: "unknown mode", drain: []string{"db"}, mode: "reject", wantErr: `drain_mode must be "skip" or "error"; got "reject"`},
+		{name: "mode without drain", mode: "error", wantErr: `scenario "maintenance": drain_mode requires drain`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateConfig(base(tt.drain, tt.mode))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateConfigTraceAttributes(t *testing.T) {
+	t.Parallel()
+
+	base := func() *Config {
+		return &Config{
+			Version: 1,
+			Services: []ServiceConfig{{
+				Name:       "api",
+				Operations: []OperationConfig{{Name: "handle", Duration: "10ms"}},
+			}},
+			Traffic: TrafficConfig{Rate: "10/s"},
+		}
+	}
+
+	t.Run("invalid top-level generator", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.TraceAttributes = map[string]AttributeValueConfig{"tenant.id": {}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `trace_attributes: attribute "tenant.id"`)
+	})
+
+	t.Run("invalid scenario generator", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Scenarios = []ScenarioConfig{{
+			Name:            "surge",
+			At:              "+0s",
+			Duration:        "1m",
+			TraceAttributes: map[string]AttributeValueConfig{"tenant.id": {}},
+		}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `scenario "surge": trace_attributes: attribute "tenant.id"`)
+	})
+
+	t.Run("empty scenario label name", func(t *testing.T) {
+		t.Parallel()
+		cfg := base()
+		cfg.Scenarios = []ScenarioConfig{{
+			Name:     "surge",
+			At:       "+0s",
+			Duration: "1m",
+			Labels:   map[string]string{"": "INC-123"},
+		}}
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `scenario "surge": labels: label name must not be empty`)
+	})
+
+	t.Run("parses from YAML", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := ParseConfig([]byte(`
+version: 1
+trace_attributes:
+  tenant.id:
+    values: {acme: 3, globex: 1}
+services:
+  api:
+    operations:
+      handle:
+        duration: 10ms
+traffic:
+  rate: 10/s
+scenarios:
+  - name: surge
+    at: +0s
+    duration: 1m
+    trace_attributes:
+      session.id:
+        sequence: "session-{n}"
+`))
+		require.NoError(t, err)
+		require.NoError(t, ValidateConfig(cfg))
+		assert.Equal(t, 3, cfg.TraceAttributes["tenant.id"].Values["acme"])
+		assert.Equal(t, "session-{n}", cfg.Scenarios[0].TraceAttributes["session.id"].Sequence)
+	})
+}
+
 func TestValidateConfigMetrics(t *testing.T) {
 	t.Parallel()
 
@@ -2662,6 +3891,57 @@ func TestValidateConfigMetricOverrides(t *testing.T) {
 		assert.Contains(t, err.Error(), "service-level overrides support only metrics")
 	})
 
+	t.Run("match", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			name    string
+			match   MatchOverrideConfig
+			wantErr string
+		}{
+			{"service", MatchOverrideConfig{Service: "gateway", OverrideConfig: OverrideConfig{Duration: "1s"}}, ""},
+			{"no selector", MatchOverrideConfig{OverrideConfig: OverrideConfig{Duration: "1s"}}, "match 1: exactly one of service or domain must be set"},
+			{"both selectors", MatchOverrideConfig{Service: "gateway", Domain: "http"}, "match 1: exactly one of service or domain must be set"},
+			{"unknown service", MatchOverrideConfig{Service: "nosuch"}, `match 1: service "nosuch" references unknown service`},
+			{"unmatched domain", MatchOverrideConfig{Domain: "db"}, `match 1: domain "db" matches no operations`},
+			{"call changes", MatchOverrideConfig{Service: "gateway", OverrideConfig: OverrideConfig{RemoveCalls: []RemoveCallConfig{{Target: "gateway.handle"}}}}, "need an explicit service.operation override"},
+			{"invalid duration", MatchOverrideConfig{Service: "gateway", OverrideConfig: OverrideConfig{Duration: "soon"}}, "match 1: invalid duration"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+				cfg := configWithScenario(nil)
+				cfg.Scenarios[0].Match = []MatchOverrideConfig{tt.match}
+				err := ValidateConfig(cfg)
+				if tt.wantErr == "" {
+					require.NoError(t, err)
+					return
+				}
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("negative error_rate_multiply rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := configWithScenario(map[string]OverrideConfig{
+			"gateway.handle": {ErrorRateMultiply: -2},
+		})
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `override "gateway.handle": error_rate_multiply must not be negative, got -2`)
+	})
+
+	t.Run("service-scope error_rate_multiply rejected", func(t *testing.T) {
+		t.Parallel()
+		cfg := configWithScenario(map[string]OverrideConfig{
+			"gateway": {ErrorRateMultiply: 3},
+		})
+		err := ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "service-level overrides support only metrics")
+	})
+
 	t.Run("metric not defined at scope rejected", func(t *testing.T) {
 		t.Parallel()
 		cfg := configWithScenario(map[string]OverrideConfig{
@@ -2985,3 +4265,110 @@ func TestValidateConfigMetricInterval(t *testing.T) {
 		assert.Contains(t, err.Error(), "interval must be positive")
 	})
 }
+
+func TestValidateConfigRegions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		regions map[string]int
+		wantErr string
+	}{
+		{name: "weighted", regions: map[string]int{"us-east-1": 3, "eu-west-1": 1}},
+		{name: "zero weight", regions: map[string]int{"us-east-1": 0}, wantErr: `regions: weight for "us-east-1" must be positive, got 0`},
+		{name: "negative weight", regions: map[string]int{"us-east-1": -1}, wantErr: `regions: weight for "us-east-1" must be positive, got -1`},
+		{name: "empty name", regions: map[string]int{"": 1}, wantErr: "regions: region name must not be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{
+				Version: 1,
+				Services: []ServiceConfig{{
+					Name:       "api",
+					Regions:    tt.regions,
+					Operations: []OperationConfig{{Name: "handle", Duration: "10ms"}},
+				}},
+				Traffic: TrafficConfig{Rate: "10/s"},
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `service "api": `+tt.wantErr)
+		})
+	}
+}
+
+func TestValidateConfigSpike(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		duration string
+		spike    SpikeConfig
+		traffic  *TrafficConfig
+		wantErr  string
+	}{
+		{name: "valid", spike: SpikeConfig{Multiplier: 5, Duration: "30s"}},
+		{name: "multiplier one", spike: SpikeConfig{Multiplier: 1, Duration: "30s"}, wantErr: "spike: multiplier must be greater than 1, got 1"},
+		{name: "missing multiplier", spike: SpikeConfig{Duration: "30s"}, wantErr: "spike: multiplier must be greater than 1, got 0"},
+		{name: "missing duration", spike: SpikeConfig{Multiplier: 5}, wantErr: "invalid spike: duration"},
+		{name: "non-positive duration", spike: SpikeConfig{Multiplier: 5, Duration: "0s"}, wantErr: `spike: duration must be positive, got "0s"`},
+		{name: "scenario duration", duration: "1m", spike: SpikeConfig{Multiplier: 5, Duration: "30s"}, wantErr: "a spike sets its own duration"},
+		{name: "with traffic", spike: SpikeConfig{Multiplier: 5, Duration: "30s"}, traffic: &TrafficConfig{Rate: "100/s"}, wantErr: "spike and traffic cannot be combined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{
+				Version: 1,
+				Services: []ServiceConfig{{
+					Name:       "api",
+					Operations: []OperationConfig{{Name: "handle", Duration: "10ms"}},
+				}},
+				Traffic: TrafficConfig{Rate: "10/s"},
+				Scenarios: []ScenarioConfig{{
+					Name:     "rush",
+					At:       "+1m",
+					Duration: tt.duration,
+					Spike:    &tt.spike,
+					Traffic:  tt.traffic,
+				}},
+			}
+			err := ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `scenario "rush": `+tt.wantErr)
+		})
+	}
+}
+
+func TestReadStdin(t *testing.T) {
+	t.Parallel()
+
+	data, err := readStdin(strings.NewReader("version: 1\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "version: 1\n", string(data))
+
+	_, err = readStdin(strings.NewReader(" \n\t"))
+	assert.ErrorContains(t, err, "stdin is empty")
+
+	_, err = readStdin(strings.NewReader(strings.Repeat("x", maxSourceBytes+1)))
+	assert.ErrorContains(t, err, "stdin exceeds "+strconv.Itoa(maxSourceBytes)+" bytes")
+}
+
+func TestLoadConfigRejectsStdinInclude(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "topology.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("version: 1\ninclude: [\"-\"]\n"), 0o600))
+	_, err := LoadConfig(path)
+	assert.ErrorContains(t, err, "stdin can only be the top-level topology")
+}
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -75,6 +76,58 @@ traffic:
 		assert.Contains(t, err.Error(), "exceeds")
 	})
 
+	t.Run("honours fetch timeout option", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			fmt.Fprint(w, validYAML)
+		}))
+		defer srv.Close()
+
+		_, err := LoadConfig(srv.URL+"/slow.yaml", WithFetchTimeout(50*time.Millisecond))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timed out after 50ms")
+	})
+
+	t.Run("honours fetch max bytes option", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, validYAML)
+		}))
+		defer srv.Close()
+
+		_, err := LoadConfig(srv.URL+"/topology.yaml", WithFetchMaxBytes(64))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "response body exceeds 64 bytes")
+
+		cfg, err := LoadConfig(srv.URL+"/topology.yaml", WithFetchMaxBytes(int64(len(validYAML))))
+		require.NoError(t, err)
+		assert.Equal(t, 1, cfg.Version)
+	})
+
+	t.Run("honours fetch max redirects option", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/final" {
+				fmt.Fprint(w, validYAML)
+				return
+			}
+			http.Redirect(w, r, "/final", http.StatusFound)
+		}))
+		defer srv.Close()
+
+		_, err := LoadConfig(srv.URL+"/start", WithFetchMaxRedirects(0))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "redirect")
+
+		cfg, err := LoadConfig(srv.URL+"/start", WithFetchMaxRedirects(1))
+		require.NoError(t, err)
+		assert.Equal(t, 1, cfg.Version)
+	})
+
 	t.Run("follows redirects up to limit", func(t *testing.T) {
 		t.Parallel()
 		hops := 0
@@ -116,7 +169,7 @@ func TestUnwrapHTTPError(t *testing.T) {
 		t.Parallel()
 		inner := errors.New("something broke")
 		err := &url.Error{Op: "Get", URL: "http://example.com", Err: inner}
-		got := unwrapHTTPError(err)
+		got := unwrapHTTPError(err, 10*time.Second)
 		assert.Equal(t, "something broke", got.Error())
 	})
 
@@ -125,7 +178,7 @@ func TestUnwrapHTTPError(t *testing.T) {
 		syscallErr := errors.New("connection refused")
 		opErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscallErr}
 		err := &url.Error{Op: "Get", URL: "http://example.com", Err: opErr}
-		got := unwrapHTTPError(err)
+		got := unwrapHTTPError(err, 10*time.Second)
 		assert.Equal(t, "connection refused", got.Error())
 	})
 
@@ -136,14 +189,14 @@ func TestUnwrapHTTPError(t *testing.T) {
 			URL: "http://example.com",
 			Err: &timeoutError{msg: "context deadline exceeded"},
 		}
-		got := unwrapHTTPError(err)
+		got := unwrapHTTPError(err, 10*time.Second)
 		assert.Equal(t, "timed out after 10s", got.Error())
 	})
 
 	t.Run("passes through non-url.Error", func(t *testing.T) {
 		t.Parallel()
 		err := errors.New("plain error")
-		got := unwrapHTTPError(err)
+		got := unwrapHTTPError(err, 10*time.Second)
 		assert.Equal(t, "plain error", got.Error())
 	})
 }
@@ -0,0 +1,127 @@
+// Call count distributions: how many times a call fires per parent span
+// Supports "poisson(mean=3)" and "uniform(min=1, max=5)", bounded for static analysis
+package synth
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// countBoundQuantile is the quantile of a Poisson count distribution taken as
+// its practical upper bound. Samples above it are clamped, so static span
+// analysis can rely on the bound.
+const countBoundQuantile = 0.999
+
+// maxPoissonMean caps the mean of a Poisson count distribution, keeping its
+// sampling table small.
+const maxPoissonMean = 1000
+
+// CountDistribution samples how many times a call fires for one parent span.
+// Bound is the most a sample can be; check's MaxSpans and MaxFanOut use it in
+// place of a fixed count.
+type CountDistribution struct {
+	Mean  float64
+	Min   int
+	Bound int
+	// cdf holds the cumulative probability of each count from Min to Bound.
+	cdf []float64
+}
+
+// ParseCountDistribution parses a count_distribution string:
+//   - "poisson(mean=3)": Poisson distributed, clamped at its 99.9th percentile
+//   - "uniform(min=1, max=5)": uniformly distributed between min and max inclusive
+func ParseCountDistribution(s string) (*CountDistribution, error) {
+	name, args, err := parseCountArgs(s)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "poisson":
+		mean, ok := args["mean"]
+		if !ok || len(args) != 1 {
+			return nil, fmt.Errorf("poisson takes one argument, mean, e.g. poisson(mean=3)")
+		}
+		if mean <= 0 || mean > maxPoissonMean {
+			return nil, fmt.Errorf("poisson mean must be greater than 0 and at most %d, got %g", maxPoissonMean, mean)
+		}
+		return newPoissonCount(mean), nil
+	case "uniform":
+		lo, okMin := args["min"]
+		hi, okMax := args["max"]
+		if !okMin || !okMax || len(args) != 2 {
+			return nil, fmt.Errorf("uniform takes two arguments, min and max, e.g. uniform(min=1, max=5)")
+		}
+		if lo != math.Trunc(lo) || hi != math.Trunc(hi) {
+			return nil, fmt.Errorf("uniform min and max must be whole numbers")
+		}
+		if lo < 0 || hi < lo {
+			return nil, fmt.Errorf("uniform needs 0 <= min <= max, got min=%g max=%g", lo, hi)
+		}
+		if hi > maxSpansCap {
+			return nil, fmt.Errorf("uniform max must be at most %d, got %g", maxSpansCap, hi)
+		}
+		return newUniformCount(int(lo), int(hi)), nil
+	default:
+		return nil, fmt.Errorf("unknown count distribution %q (use poisson or uniform)", name)
+	}
+}
+
+// parseCountArgs splits "name(k=v, k=v)" into its name and numeric arguments.
+func parseCountArgs(s string) (string, map[string]float64, error) {
+	s = strings.TrimSpace(s)
+	open := strings.IndexByte(s, '(')
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return "", nil, fmt.Errorf("expected name(key=value, ...), e.g. poisson(mean=3), got %q", s)
+	}
+	name := strings.TrimSpace(s[:open])
+	args := make(map[string]float64)
+	for part := range strings.SplitSeq(s[open+1:len(s)-1], ",") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return "", nil, fmt.Errorf("argument %q must be key=value", strings.TrimSpace(part))
+		}
+		key = strings.TrimSpace(key)
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("argument %q: invalid number %q", key, strings.TrimSpace(value))
+		}
+		if _, dup := args[key]; dup {
+			return "", nil, fmt.Errorf("argument %q given twice", key)
+		}
+		args[key] = v
+	}
+	return name, args, nil
+}
+
+// newPoissonCount tabulates the Poisson CDF up to its countBoundQuantile.
+func newPoissonCount(mean float64) *CountDistribution {
+	d := &CountDistribution{Mean: mean}
+	cum := 0.0
+	for k := 0; ; k++ {
+		lgamma, _ := math.Lgamma(float64(k) + 1)
+		cum += math.Exp(float64(k)*math.Log(mean) - mean - lgamma)
+		d.cdf = append(d.cdf, cum)
+		if cum >= countBoundQuantile {
+			d.Bound = k
+			break
+		}
+	}
+	return d
+}
+
+func newUniformCount(lo, hi int) *CountDistribution {
+	return &CountDistribution{Mean: float64(lo+hi) / 2, Min: lo, Bound: hi}
+}
+
+// Sample returns a count between Min and Bound inclusive.
+func (d *CountDistribution) Sample(rng *rand.Rand) int {
+	if d.cdf == nil {
+		return d.Min + rng.IntN(d.Bound-d.Min+1)
+	}
+	i, _ := slices.BinarySearch(d.cdf, rng.Float64())
+	return d.Min + min(i, len(d.cdf)-1)
+}
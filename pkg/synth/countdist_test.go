@@ -0,0 +1,73 @@
+// Tests for call count distributions
+package synth
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCountDistribution(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input     string
+		wantMin   int
+		wantBound int
+		wantErr   string
+	}{
+		{input: "poisson(mean=3)", wantBound: 10},
+		{input: " poisson( mean = 0.5 ) ", wantBound: 4},
+		{input: "uniform(min=2, max=6)", wantMin: 2, wantBound: 6},
+		{input: "uniform(min=0,max=0)", wantBound: 0},
+		{input: "poisson", wantErr: "expected name(key=value, ...)"},
+		{input: "poisson(mean=0)", wantErr: "poisson mean must be greater than 0"},
+		{input: "poisson(mean=5000)", wantErr: "at most 1000"},
+		{input: "poisson(lambda=3)", wantErr: "poisson takes one argument, mean"},
+		{input: "poisson(mean=three)", wantErr: `invalid number "three"`},
+		{input: "poisson(mean=1, mean=2)", wantErr: `argument "mean" given twice`},
+		{input: "uniform(min=5, max=2)", wantErr: "0 <= min <= max"},
+		{input: "uniform(min=1.5, max=2)", wantErr: "whole numbers"},
+		{input: "uniform(max=2)", wantErr: "uniform takes two arguments"},
+		{input: "zipf(s=2)", wantErr: `unknown count distribution "zipf"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			d, err := ParseCountDistribution(tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMin, d.Min)
+			assert.Equal(t, tt.wantBound, d.Bound)
+		})
+	}
+}
+
+func TestCountDistributionSample(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{"poisson(mean=3)", "poisson(mean=250)", "uniform(min=1, max=5)"} {
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+			d, err := ParseCountDistribution(input)
+			require.NoError(t, err)
+
+			rng := rand.New(rand.NewPCG(1, 2)) //nolint:gosec // deterministic seed for testing
+			const n = 20000
+			sum := 0
+			for range n {
+				c := d.Sample(rng)
+				require.GreaterOrEqual(t, c, d.Min)
+				require.LessOrEqual(t, c, d.Bound, "samples never exceed the static bound")
+				sum += c
+			}
+			assert.InEpsilon(t, d.Mean, float64(sum)/n, 0.03)
+		})
+	}
+}
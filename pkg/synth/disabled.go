@@ -0,0 +1,120 @@
+// Disabled operations and calls: entries switched off with disabled: true and left out of the topology
+// Lets a variant of a topology be tried by toggling entries rather than deleting them
+package synth
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// disabledOperations returns the refs of cfg's disabled operations, and the
+// names of services whose operations are all disabled.
+func disabledOperations(cfg *Config) (ops, services map[string]bool) {
+	ops = make(map[string]bool)
+	services = make(map[string]bool)
+	for _, svc := range cfg.Services {
+		all := len(svc.Operations) > 0
+		for _, op := range svc.Operations {
+			if op.Disabled {
+				ops[svc.Name+"."+op.Name] = true
+			} else {
+				all = false
+			}
+		}
+		if all {
+			services[svc.Name] = true
+		}
+	}
+	return ops, services
+}
+
+// enabledConfig returns cfg without its disabled operations and calls, as
+// BuildTopology sees it. Calls and one_of entries targeting a disabled
+// operation are dropped as if they were disabled themselves, and so is a
+// service whose operations are all disabled. cfg itself is not modified.
+func enabledConfig(cfg *Config) *Config {
+	disabledOps, _ := disabledOperations(cfg)
+	enabled := *cfg
+	enabled.Services = make([]ServiceConfig, 0, len(cfg.Services))
+	for _, svc := range cfg.Services {
+		ops := make([]OperationConfig, 0, len(svc.Operations))
+		for _, op := range svc.Operations {
+			if op.Disabled {
+				continue
+			}
+			op.Calls = enabledCalls(op.Calls, disabledOps)
+			op.OneOf = enabledCalls(op.OneOf, disabledOps)
+			ops = append(ops, op)
+		}
+		if len(ops) == 0 {
+			continue
+		}
+		svc.Operations = ops
+		enabled.Services = append(enabled.Services, svc)
+	}
+	return &enabled
+}
+
+// enabledCalls returns calls without those that are disabled or target a
+// disabled operation.
+func enabledCalls(calls []CallConfig, disabledOps map[string]bool) []CallConfig {
+	if len(calls) == 0 {
+		return calls
+	}
+	return slices.DeleteFunc(slices.Clone(calls), func(call CallConfig) bool {
+		return call.Disabled || disabledOps[call.Target]
+	})
+}
+
+// validateDisabledReferences reports references to disabled operations that
+// cannot simply be skipped the way a call can: links from enabled
+// operations, scenario overrides of disabled operations or of services whose
+// operations are all disabled, or that add calls to one, and drains of such
+// services. It also
+// rejects a one_of left with no positive weight, and a config with every
+// operation disabled.
+func validateDisabledReferences(cfg *Config) []error {
+	disabledOps, disabledServices := disabledOperations(cfg)
+	var errs []error
+	if len(cfg.Services) > 0 && len(disabledServices) == len(cfg.Services) {
+		errs = append(errs, fmt.Errorf("every operation is disabled; at least one must be enabled to generate traces"))
+	}
+	for _, svc := range cfg.Services {
+		for _, op := range svc.Operations {
+			if op.Disabled {
+				continue
+			}
+			if remaining := enabledCalls(op.OneOf, disabledOps); len(remaining) > 0 && len(remaining) < len(op.OneOf) &&
+				!slices.ContainsFunc(remaining, func(call CallConfig) bool { return call.Weight > 0 }) {
+				errs = append(errs, fmt.Errorf("service %q operation %q: one_of: every call with a positive weight is disabled", svc.Name, op.Name))
+			}
+			for _, link := range op.Links {
+				if disabledOps[link.Ref] {
+					errs = append(errs, fmt.Errorf("service %q operation %q: link %q references a disabled operation; disable this operation too or remove the link", svc.Name, op.Name, link.Ref))
+				}
+			}
+		}
+	}
+	for _, sc := range cfg.Scenarios {
+		for _, ref := range slices.Sorted(maps.Keys(sc.Override)) {
+			switch {
+			case disabledOps[ref]:
+				errs = append(errs, fmt.Errorf("scenario %q: override %q references a disabled operation", sc.Name, ref))
+			case disabledServices[ref]:
+				errs = append(errs, fmt.Errorf("scenario %q: override %q references a service whose operations are all disabled", sc.Name, ref))
+			}
+			for _, call := range sc.Override[ref].AddCalls {
+				if disabledOps[call.Target] {
+					errs = append(errs, fmt.Errorf("scenario %q: override %q: add_calls %q references a disabled operation", sc.Name, ref, call.Target))
+				}
+			}
+		}
+		for _, name := range sc.Drain {
+			if disabledServices[name] {
+				errs = append(errs, fmt.Errorf("scenario %q: drain %q references a service whose operations are all disabled", sc.Name, name))
+			}
+		}
+	}
+	return errs
+}
@@ -1,19 +1,72 @@
 // Duration distribution parsing and sampling for synthetic telemetry
-// Supports the "30ms +/- 10ms" DSL format with normal distribution sampling
+// Supports the "30ms +/- 10ms" DSL format with normal distribution sampling,
+// and "p50=20ms p99=200ms" percentile targets fitted to a log-normal, either
+// optionally clamped by a trailing "[min=5ms, max=500ms]"
 package synth
 
 import (
 	"fmt"
+	"math"
 	"math/rand/v2"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// Distribution represents a duration with optional variance, sampled as a normal distribution.
+// z99 is the 99th percentile of the standard normal distribution.
+const z99 = 2.3263478740408408
+
+// DistributionKind identifies how a Distribution is sampled.
+type DistributionKind int
+
+const (
+	// KindNormal samples a normal distribution with Mean and StdDev.
+	KindNormal DistributionKind = iota
+	// KindPercentile samples a log-normal distribution fitted so that its
+	// median is P50 and its 99th percentile is P99.
+	KindPercentile
+)
+
+// Distribution represents a duration with optional variance. A KindNormal
+// distribution is sampled as a normal distribution; a KindPercentile one as
+// a log-normal through its P50 and P99 targets, with Mean and StdDev set to
+// the log-normal's own mean and standard deviation. Min and Max, when
+// positive, clamp every sample; zero leaves that side unbounded.
 type Distribution struct {
 	Mean   time.Duration
 	StdDev time.Duration
+	Kind   DistributionKind
+	P50    time.Duration
+	P99    time.Duration
+	Min    time.Duration
+	Max    time.Duration
+}
+
+// NewPercentileDistribution returns the log-normal distribution whose median
+// is p50 and whose 99th percentile is p99. Equal targets give a fixed
+// duration.
+func NewPercentileDistribution(p50, p99 time.Duration) (Distribution, error) {
+	if p50 <= 0 {
+		return Distribution{}, fmt.Errorf("p50 must be positive")
+	}
+	if p99 < p50 {
+		return Distribution{}, fmt.Errorf("p99 (%s) must not be less than p50 (%s)", p99, p50)
+	}
+	sigma := logSigma(p50, p99)
+	mean := float64(p50) * math.Exp(sigma*sigma/2)
+	return Distribution{
+		Mean:   time.Duration(mean),
+		StdDev: time.Duration(mean * math.Sqrt(math.Expm1(sigma*sigma))),
+		Kind:   KindPercentile,
+		P50:    p50,
+		P99:    p99,
+	}, nil
+}
+
+// logSigma returns the standard deviation of the logarithm of a log-normal
+// distribution with the given median and 99th percentile.
+func logSigma(p50, p99 time.Duration) float64 {
+	return math.Log(float64(p99)/float64(p50)) / z99
 }
 
 // ParseDistribution parses a duration distribution string.
@@ -21,11 +74,32 @@ type Distribution struct {
 //   - "30ms +/- 10ms" (mean with standard deviation)
 //   - "30ms ± 10ms"   (unicode variant)
 //   - "50ms"           (fixed duration, zero variance)
+//   - "p50=20ms p99=200ms" (percentile targets, see NewPercentileDistribution)
+//
+// Any of these may end in "[min=5ms, max=500ms]" to clamp samples to a
+// range; either bound may be given alone.
 func ParseDistribution(s string) (Distribution, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
 		return Distribution{}, fmt.Errorf("duration is required (e.g. '50ms', '1s +/- 200ms')")
 	}
+	if strings.HasSuffix(s, "]") {
+		open := strings.LastIndexByte(s, '[')
+		if open < 0 {
+			return Distribution{}, fmt.Errorf("duration clamp must be enclosed in brackets, e.g. '50ms +/- 30ms [min=5ms, max=500ms]'")
+		}
+		d, err := ParseDistribution(s[:open])
+		if err != nil {
+			return Distribution{}, err
+		}
+		if d.Min, d.Max, err = parseDurationClamp(s[open+1 : len(s)-1]); err != nil {
+			return Distribution{}, err
+		}
+		return d, nil
+	}
+	if strings.HasPrefix(s, "p") {
+		return parsePercentileDistribution(s)
+	}
 
 	// Try splitting on "+/-" or "±"
 	var meanStr, stddevStr string
@@ -66,8 +140,122 @@ func ParseDistribution(s string) (Distribution, error) {
 	return Distribution{Mean: mean, StdDev: stddev}, nil
 }
 
-// Sample returns a duration drawn from a normal distribution, clamped to minimum zero.
+// parsePercentileDistribution parses percentile targets such as
+// "p50=20ms p99=200ms". Targets may also be separated by commas.
+func parsePercentileDistribution(s string) (Distribution, error) {
+	var p50, p99 time.Duration
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	for _, f := range fields {
+		name, value, ok := strings.Cut(f, "=")
+		if !ok {
+			return Distribution{}, fmt.Errorf("invalid percentile target %q, expected e.g. p50=20ms", f)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return Distribution{}, fmt.Errorf("invalid %s duration: %w", name, err)
+		}
+		switch name {
+		case "p50":
+			p50 = d
+		case "p99":
+			p99 = d
+		default:
+			return Distribution{}, fmt.Errorf("unsupported percentile %q (supported: p50, p99)", name)
+		}
+	}
+	if p50 == 0 || p99 == 0 {
+		return Distribution{}, fmt.Errorf("percentile durations need both p50 and p99, e.g. 'p50=20ms p99=200ms'")
+	}
+	return NewPercentileDistribution(p50, p99)
+}
+
+// parseDurationClamp parses the "min=5ms, max=500ms" inside a duration's
+// clamp brackets.
+func parseDurationClamp(s string) (lo, hi time.Duration, err error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("duration clamp needs min, max, or both, e.g. [min=5ms, max=500ms]")
+	}
+	seen := make(map[string]bool, 2)
+	for _, f := range fields {
+		name, value, ok := strings.Cut(f, "=")
+		if !ok {
+			return 0, 0, fmt.Errorf("invalid duration clamp %q, expected e.g. max=500ms", f)
+		}
+		if seen[name] {
+			return 0, 0, fmt.Errorf("duration clamp %s given twice", name)
+		}
+		seen[name] = true
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid clamp %s duration: %w", name, err)
+		}
+		if d <= 0 {
+			return 0, 0, fmt.Errorf("clamp %s must be positive", name)
+		}
+		switch name {
+		case "min":
+			lo = d
+		case "max":
+			hi = d
+		default:
+			return 0, 0, fmt.Errorf("unsupported duration clamp %q (supported: min, max)", name)
+		}
+	}
+	if hi > 0 && lo > hi {
+		return 0, 0, fmt.Errorf("clamp max (%s) must not be less than min (%s)", hi, lo)
+	}
+	return lo, hi, nil
+}
+
+// clamp limits v to the distribution's Min and Max, where set.
+func (d Distribution) clamp(v time.Duration) time.Duration {
+	if d.Min > 0 && v < d.Min {
+		return d.Min
+	}
+	if d.Max > 0 && v > d.Max {
+		return d.Max
+	}
+	return v
+}
+
+// slowedBy returns d with its latency multiplied by f: the mean of a normal
+// distribution, leaving its spread unchanged, or both targets of a
+// percentile distribution, keeping its shape. Clamps stay where they are,
+// so a slowed sample still never exceeds Max.
+func (d Distribution) slowedBy(f float64) Distribution {
+	scale := func(v time.Duration) time.Duration { return time.Duration(float64(v) * f) }
+	d.Mean = scale(d.Mean)
+	if d.Kind == KindPercentile {
+		d.StdDev, d.P50, d.P99 = scale(d.StdDev), scale(d.P50), scale(d.P99)
+	}
+	return d
+}
+
+// p99 returns the duration's 99th percentile: the target itself for a
+// percentile distribution, or the mean plus z99 standard deviations, either
+// clamped.
+func (d Distribution) p99() time.Duration {
+	if d.Kind == KindPercentile {
+		return d.clamp(d.P99)
+	}
+	return d.clamp(d.Mean + time.Duration(z99*float64(d.StdDev)))
+}
+
+// Sample returns a duration drawn from the distribution: log-normal for
+// KindPercentile, otherwise normal clamped to minimum zero. The result is
+// then clamped to Min and Max, where set.
 func (d Distribution) Sample(rng *rand.Rand) time.Duration {
+	return d.clamp(d.sample(rng))
+}
+
+func (d Distribution) sample(rng *rand.Rand) time.Duration {
+	if d.Kind == KindPercentile {
+		if d.P99 == d.P50 {
+			return d.P50
+		}
+		return time.Duration(float64(d.P50) * math.Exp(logSigma(d.P50, d.P99)*rng.NormFloat64()))
+	}
 	if d.StdDev == 0 {
 		return d.Mean
 	}
@@ -146,8 +334,24 @@ func (d FloatDistribution) String() string {
 
 // String returns the distribution in DSL format.
 func (d Distribution) String() string {
-	if d.StdDev == 0 {
-		return d.Mean.String()
+	var s string
+	switch {
+	case d.Kind == KindPercentile:
+		s = fmt.Sprintf("p50=%s p99=%s", d.P50, d.P99)
+	case d.StdDev == 0:
+		s = d.Mean.String()
+	default:
+		s = fmt.Sprintf("%s +/- %s", d.Mean, d.StdDev)
+	}
+	var clamps []string
+	if d.Min > 0 {
+		clamps = append(clamps, "min="+d.Min.String())
+	}
+	if d.Max > 0 {
+		clamps = append(clamps, "max="+d.Max.String())
+	}
+	if len(clamps) == 0 {
+		return s
 	}
-	return fmt.Sprintf("%s +/- %s", d.Mean, d.StdDev)
+	return s + " [" + strings.Join(clamps, ", ") + "]"
 }
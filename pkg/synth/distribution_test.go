@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
 )
 
 func TestParseDistribution(t *testing.T) {
@@ -284,3 +285,168 @@ func TestDistributionString(t *testing.T) {
 	d2 := Distribution{Mean: 50 * time.Millisecond, StdDev: 0}
 	assert.Equal(t, "50ms", d2.String())
 }
+
+func TestParsePercentileDistribution(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		p50     time.Duration
+		p99     time.Duration
+		wantErr string
+	}{
+		{name: "space separated", input: "p50=20ms p99=200ms", p50: 20 * time.Millisecond, p99: 200 * time.Millisecond},
+		{name: "comma separated", input: "p50=20ms, p99=200ms", p50: 20 * time.Millisecond, p99: 200 * time.Millisecond},
+		{name: "either order", input: "p99=1s p50=100ms", p50: 100 * time.Millisecond, p99: time.Second},
+		{name: "equal targets", input: "p50=5ms p99=5ms", p50: 5 * time.Millisecond, p99: 5 * time.Millisecond},
+		{name: "missing p99", input: "p50=20ms", wantErr: "need both p50 and p99"},
+		{name: "unsupported percentile", input: "p50=20ms p90=100ms", wantErr: `unsupported percentile "p90"`},
+		{name: "missing value", input: "p50 p99=200ms", wantErr: "invalid percentile target"},
+		{name: "bad duration", input: "p50=abc p99=200ms", wantErr: "invalid p50 duration"},
+		{name: "p99 below p50", input: "p50=200ms p99=20ms", wantErr: "must not be less than p50"},
+		{name: "non-positive p50", input: "p50=-1ms p99=20ms", wantErr: "p50 must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d, err := ParseDistribution(tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, KindPercentile, d.Kind)
+			assert.Equal(t, tt.p50, d.P50)
+			assert.Equal(t, tt.p99, d.P99)
+			assert.GreaterOrEqual(t, d.Mean, d.P50, "a log-normal's mean is at least its median")
+		})
+	}
+}
+
+func TestPercentileDistributionString(t *testing.T) {
+	t.Parallel()
+
+	d, err := NewPercentileDistribution(20*time.Millisecond, 200*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "p50=20ms p99=200ms", d.String())
+}
+
+func TestParseDistributionClamp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		min     time.Duration
+		max     time.Duration
+		wantErr string
+	}{
+		{name: "both bounds", input: "50ms +/- 30ms [min=5ms, max=500ms]", min: 5 * time.Millisecond, max: 500 * time.Millisecond},
+		{name: "max only", input: "50ms +/- 30ms [max=200ms]", max: 200 * time.Millisecond},
+		{name: "min only", input: "50ms [min=1ms]", min: time.Millisecond},
+		{name: "percentile", input: "p50=20ms p99=2s [max=1s]", max: time.Second},
+		{name: "space separated", input: "50ms +/- 30ms [min=5ms max=500ms]", min: 5 * time.Millisecond, max: 500 * time.Millisecond},
+		{name: "empty brackets", input: "50ms []", wantErr: "needs min, max, or both"},
+		{name: "unsupported bound", input: "50ms [p99=1s]", wantErr: `unsupported duration clamp "p99"`},
+		{name: "missing value", input: "50ms [max]", wantErr: "invalid duration clamp"},
+		{name: "bad duration", input: "50ms [max=abc]", wantErr: "invalid clamp max duration"},
+		{name: "non-positive bound", input: "50ms [min=0s]", wantErr: "clamp min must be positive"},
+		{name: "repeated bound", input: "50ms [max=1s, max=2s]", wantErr: "given twice"},
+		{name: "max below min", input: "50ms [min=100ms, max=10ms]", wantErr: "must not be less than min"},
+		{name: "unopened bracket", input: "50ms max=1s]", wantErr: "enclosed in brackets"},
+		{name: "bad distribution", input: "abc [max=1s]", wantErr: "invalid mean duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d, err := ParseDistribution(tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.min, d.Min)
+			assert.Equal(t, tt.max, d.Max)
+		})
+	}
+}
+
+func TestDistributionClampString(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{
+		"50ms +/- 30ms [min=5ms, max=500ms]",
+		"50ms [max=200ms]",
+		"p50=20ms p99=2s [min=1ms]",
+	} {
+		d, err := ParseDistribution(s)
+		require.NoError(t, err)
+		assert.Equal(t, s, d.String())
+		again, err := ParseDistribution(d.String())
+		require.NoError(t, err)
+		assert.Equal(t, d, again)
+	}
+}
+
+func TestDistributionClampP99(t *testing.T) {
+	t.Parallel()
+
+	d, err := ParseDistribution("p50=20ms p99=2s [max=500ms]")
+	require.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, d.p99())
+}
+
+func TestProperty_DistributionClamp_BoundsSamples(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		ms := func(label string, lo, hi int) time.Duration {
+			return time.Duration(rapid.IntRange(lo, hi).Draw(t, label)) * time.Millisecond
+		}
+		var d Distribution
+		if rapid.Bool().Draw(t, "percentile") {
+			p50 := ms("p50", 1, 1000)
+			var err error
+			if d, err = NewPercentileDistribution(p50, p50+ms("spread", 0, 10000)); err != nil {
+				t.Fatalf("NewPercentileDistribution: %v", err)
+			}
+		} else {
+			d = Distribution{Mean: ms("mean", 1, 1000), StdDev: ms("stddev", 0, 2000)}
+		}
+		if rapid.Bool().Draw(t, "hasMin") {
+			d.Min = ms("min", 1, 500)
+		}
+		if rapid.Bool().Draw(t, "hasMax") {
+			d.Max = max(d.Min, 1) + ms("max", 0, 2000)
+		}
+
+		parsed, err := ParseDistribution(d.String())
+		if err != nil {
+			t.Fatalf("ParseDistribution(%q): %v", d.String(), err)
+		}
+		rng := rand.New(rand.NewPCG(rapid.Uint64().Draw(t, "seed"), 0)) //nolint:gosec // deterministic seed for testing
+		for range 200 {
+			v := parsed.Sample(rng)
+			if d.Min > 0 && v < d.Min {
+				t.Fatalf("sample %s below min %s for %q", v, d.Min, d.String())
+			}
+			if d.Max > 0 && v > d.Max {
+				t.Fatalf("sample %s above max %s for %q", v, d.Max, d.String())
+			}
+		}
+	})
+}
+
+func TestDistributionSlowedBy(t *testing.T) {
+	t.Parallel()
+
+	normal := Distribution{Mean: 30 * time.Millisecond, StdDev: 10 * time.Millisecond}
+	assert.Equal(t, Distribution{Mean: 60 * time.Millisecond, StdDev: 10 * time.Millisecond}, normal.slowedBy(2))
+
+	pct, err := NewPercentileDistribution(20*time.Millisecond, 200*time.Millisecond)
+	require.NoError(t, err)
+	slowed := pct.slowedBy(2)
+	assert.Equal(t, "p50=40ms p99=400ms", slowed.String())
+	assert.Equal(t, 2*pct.Mean, slowed.Mean)
+}
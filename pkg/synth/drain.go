@@ -0,0 +1,86 @@
+// Scenario drains: services taken out of traffic for a window, as for planned maintenance
+// Expanded into per-operation overrides so roots start no traces and callers skip or fail their calls
+package synth
+
+import "fmt"
+
+// Drain mode constants for a scenario's drain_mode field.
+const (
+	drainModeSkip  = "skip"
+	drainModeError = "error"
+)
+
+// validateDrain checks a scenario's drain and drain_mode.
+func validateDrain(services []string, mode string, knownServices map[string]bool) error {
+	if len(services) == 0 {
+		if mode != "" {
+			return fmt.Errorf("drain_mode requires drain")
+		}
+		return nil
+	}
+	for _, name := range services {
+		if !knownServices[name] {
+			return fmt.Errorf("drain: %q references unknown service", name)
+		}
+	}
+	switch mode {
+	case "", drainModeSkip, drainModeError:
+		return nil
+	default:
+		return fmt.Errorf("drain_mode must be %q or %q; got %q", drainModeSkip, drainModeError, mode)
+	}
+}
+
+// drainOverrides expands a scenario's drain into operation overrides. Every
+// operation of a drained service is marked Drained. In skip mode, calls from
+// other operations to a drained one are also removed; in error mode they are
+// kept, so they reach the drained operation and fail there.
+func drainOverrides(services []string, mode string, topo *Topology) map[string]Override {
+	overrides := make(map[string]Override)
+	if len(services) == 0 {
+		return overrides
+	}
+	drained := make(map[string]bool)
+	for _, name := range services {
+		svc, ok := topo.Services[name]
+		if !ok {
+			// Every operation of the service is disabled, so there is
+			// nothing to drain.
+			continue
+		}
+		for _, op := range svc.Operations {
+			drained[op.Ref] = true
+			overrides[op.Ref] = Override{Drained: true, DrainErrors: mode == drainModeError}
+		}
+	}
+	if mode == drainModeError {
+		return overrides
+	}
+	for _, svc := range topo.Services {
+		for _, op := range svc.Operations {
+			if drained[op.Ref] {
+				continue
+			}
+			var removed map[string]bool
+			for _, call := range op.Calls {
+				if !drained[call.Operation.Ref] {
+					continue
+				}
+				if removed == nil {
+					removed = make(map[string]bool)
+				}
+				removed[call.Operation.Ref] = true
+			}
+			if removed != nil {
+				overrides[op.Ref] = Override{RemoveCalls: removed}
+			}
+		}
+	}
+	return overrides
+}
+
+// drained reports whether root belongs to a service drained by an active
+// scenario, in which case its arrival starts no trace.
+func (s scenarioState) drained(root *Operation) bool {
+	return s.overrides[root.Ref].Drained
+}
@@ -4,6 +4,7 @@ package synth
 import (
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"sync/atomic"
@@ -46,6 +47,12 @@ func emitTrace(ctx context.Context, plans []SpanPlan, baseSimTime time.Time, bas
 
 	events := buildEvents(plans)
 	live := make([]liveSpan, len(plans))
+	// started keeps the span context of every started span, so retry
+	// attempts can link to attempts that have already ended.
+	var started []trace.SpanContext
+	if slices.ContainsFunc(plans, func(p SpanPlan) bool { return p.PreviousAttempt > 0 }) {
+		started = make([]trace.SpanContext, len(plans))
+	}
 
 	timer := time.NewTimer(0)
 	defer timer.Stop()
@@ -94,9 +101,17 @@ func emitTrace(ctx context.Context, plans []SpanPlan, baseSimTime time.Time, bas
 					startOpts = append(startOpts, trace.WithLinks(links...))
 				}
 			}
+			if plan.PreviousAttempt > 0 {
+				if sc := started[plan.PreviousAttempt]; sc.IsValid() {
+					startOpts = append(startOpts, trace.WithLinks(retryLink(sc, plan.RetryAttempt-1)))
+				}
+			}
 
 			tracer := tracers(plan.Service)
-			spanCtx, span := tracer.Start(parentCtx, plan.Operation, startOpts...)
+			spanCtx, span := tracer.Start(parentCtx, cmp.Or(plan.Name, plan.Operation), startOpts...)
+			if started != nil {
+				started[ev.Index] = span.SpanContext()
+			}
 			if registry != nil && !plan.Rejected {
 				registry.store(plan.Ref, span.SpanContext())
 			}
@@ -162,8 +177,9 @@ func finishSpan(span trace.Span, plan *SpanPlan, plans []SpanPlan, observers []S
 			span.SetStatus(codes.Error, plan.RejectionReason)
 			span.RecordError(fmt.Errorf("rejected: %s", plan.RejectionReason), trace.WithTimestamp(plan.EndTime))
 		} else {
-			span.SetStatus(codes.Error, "synthetic error")
-			span.RecordError(fmt.Errorf("synthetic error"), trace.WithTimestamp(plan.EndTime))
+			msg := cmp.Or(plan.ErrorMessage, syntheticErrorMessage)
+			span.SetStatus(codes.Error, msg)
+			span.RecordError(errors.New(msg), trace.WithTimestamp(plan.EndTime))
 		}
 		rstats.Errors.Add(1)
 	}
@@ -4,17 +4,24 @@
 package synth
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"math/rand/v2"
 	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // DefaultMaxSpansPerTrace is the safety bound for span generation per trace.
@@ -22,6 +29,10 @@ const DefaultMaxSpansPerTrace = 10_000
 
 const zeroRateIdleInterval = 10 * time.Millisecond
 
+// maxRetryGap caps the wait before a retry, so a steep backoff multiplier
+// cannot push retries years past the trace or overflow a time.Duration.
+const maxRetryGap = time.Hour
+
 // spanContextRegistry stores the most recent span context for each operation ref.
 // Used to attach cross-trace span links from consumer operations to producer operations.
 // Concurrent Store calls produce last-writer-wins semantics — "most recent" is
@@ -33,7 +44,7 @@ type spanContextRegistry struct {
 }
 
 func (r *spanContextRegistry) store(ref string, sc trace.SpanContext) {
-	if !r.targets[ref] {
+	if !r.targets[ref] || !sc.IsValid() {
 		return
 	}
 	r.mu.Lock()
@@ -81,22 +92,37 @@ type Engine struct {
 	Tracers           TracerSource
 	Rng               *rand.Rand
 	Duration          time.Duration
-	Observers         []SpanObserver
+	Observers         []SpanObserver // notified of every span; append any SpanObserver to derive signals or check invariants
 	MaxSpansPerTrace  int
 	State             *SimulationState
 	LabelScenarios    bool
+	LinkRetries       bool // link each retry attempt's span to the previous attempt's span
 	TimeOffset        time.Duration
 	Realtime          bool
+	Backfill          bool // simulate Duration on a simulated clock, without sleeping between traces
 	MaxInFlightTraces int
 	MaxTraces         int
+	UntilScenariosEnd bool          // stop once every scenario window has ended, if that is sooner than Duration
+	SampleRatio       float64       // fraction of traces emitted, simulating a head sampler; zero emits all
+	Warmup            time.Duration // traces started before this much elapsed time are emitted but not counted in Stats
+	CollectPerOp      bool          // fill Stats.ByOperation with a per-operation breakdown
+	MaxTraceDuration  time.Duration // spans still running this long after the root started are cut off; zero means no cap
 	linkRegistry      *spanContextRegistry
 	choiceDecisions   choiceDecisions
+	live              liveStats
+	activeTraceAttrs  Attributes           // trace attributes added by the active scenarios
+	activeLabels      []attribute.KeyValue // span attributes from the labels of the active scenarios
+	trafficPeak       float64              // how close the current traffic is to its peak, from 0 to 1
 }
 
 // Stats holds counters collected during a simulation run.
 // Errors counts all spans in an error state, including those errored by cascading
 // (a child failure marks its parent as errored too). ErrorRate is Errors/Spans.
 // TraceErrorRate counts only traces where the root span errored.
+// Sampled counts traces that were generated but dropped by head sampling; they
+// are still included in Traces and Spans.
+// Traces started during the engine's Warmup are excluded from every counter,
+// and TracesPerSec and SpansPerSec are rates over the time after Warmup.
 type Stats struct {
 	Traces              int64   `json:"traces"`
 	Spans               int64   `json:"spans"`
@@ -104,17 +130,108 @@ type Stats struct {
 	FailedTraces        int64   `json:"failed_traces"`
 	Timeouts            int64   `json:"timeouts"`
 	Retries             int64   `json:"retries"`
+	Hedges              int64   `json:"hedges"`
 	SpansBounded        int64   `json:"spans_bounded"`
+	TraceDurationCapped int64   `json:"trace_duration_capped"`
+	Sampled             int64   `json:"sampled"`
 	QueueRejections     int64   `json:"queue_rejections"`
 	CircuitBreakerTrips int64   `json:"circuit_breaker_trips"`
+	RateLimitRejections int64   `json:"rate_limit_rejections"`
+	FlapRejections      int64   `json:"flap_rejections"`
 	ElapsedMs           int64   `json:"elapsed_ms"`
 	TracesPerSec        float64 `json:"traces_per_second"`
 	SpansPerSec         float64 `json:"spans_per_second"`
 	ErrorRate           float64 `json:"error_rate"`
 	TraceErrorRate      float64 `json:"trace_error_rate"`
+	// ScenarioTraces counts, for each scenario, the traces started while it
+	// was active. Scenarios that never activated are present with zero.
+	ScenarioTraces map[string]int64 `json:"scenario_traces,omitempty"`
+	// ByOperation breaks the counters down by operation ref when the engine's
+	// CollectPerOp is set.
+	ByOperation map[string]OpStats `json:"by_operation,omitempty"`
+
+	byOp map[string]*opAccumulator
+	// traceCapped records that the trace being generated hit the engine's
+	// MaxTraceDuration; the run loop counts it and clears it.
+	traceCapped bool
+}
+
+// newRunStats returns the zeroed Stats a run counts into.
+func (e *Engine) newRunStats() Stats {
+	stats := Stats{ScenarioTraces: newScenarioTraces(e.Scenarios)}
+	if e.CollectPerOp {
+		stats.byOp = make(map[string]*opAccumulator)
+	}
+	return stats
+}
+
+// newScenarioTraces returns a zeroed ScenarioTraces map for scenarios, or
+// nil when there are none.
+func newScenarioTraces(scenarios []Scenario) map[string]int64 {
+	if len(scenarios) == 0 {
+		return nil
+	}
+	counts := make(map[string]int64, len(scenarios))
+	for _, sc := range scenarios {
+		counts[sc.Name] = 0
+	}
+	return counts
+}
+
+// countScenarioTraces records a trace against each active scenario. Warmup
+// stats have no ScenarioTraces map, so nothing is recorded for them.
+func countScenarioTraces(stats *Stats, active []Scenario) {
+	if stats.ScenarioTraces == nil {
+		return
+	}
+	for _, sc := range active {
+		stats.ScenarioTraces[sc.Name]++
+	}
+}
+
+// liveStats publishes the headline counters while Run is in progress, so
+// they can be read from another goroutine without racing the simulation.
+type liveStats struct {
+	traces atomic.Int64
+	spans  atomic.Int64
+	errors atomic.Int64
+}
+
+// publish stores the counters in stats, plus the spans and errors rstats has
+// counted but not yet merged into stats, if rstats is not nil.
+func (l *liveStats) publish(stats *Stats, rstats *realtimeStats) {
+	spans, errs := stats.Spans, stats.Errors
+	if rstats != nil {
+		spans += rstats.Spans.Load()
+		errs += rstats.Errors.Load()
+	}
+	l.traces.Store(stats.Traces)
+	l.spans.Store(spans)
+	l.errors.Store(errs)
+}
+
+// Progress returns the traces, spans, and errors counted so far. It is safe
+// to call from another goroutine while Run is in progress.
+func (e *Engine) Progress() Stats {
+	return Stats{
+		Traces: e.live.traces.Load(),
+		Spans:  e.live.spans.Load(),
+		Errors: e.live.errors.Load(),
+	}
+}
+
+// runDuration returns how long Run generates for: Duration, cut short to the
+// end of the last scenario window when UntilScenariosEnd is set.
+func (e *Engine) runDuration() time.Duration {
+	if e.UntilScenariosEnd && len(e.Scenarios) > 0 {
+		return min(e.Duration, ScenariosEnd(e.Scenarios))
+	}
+	return e.Duration
 }
 
 // Run executes the main simulation loop with rate-controlled trace generation.
+// Roots with traffic of their own start traces at their own rate; the others
+// share the engine's Traffic.
 func (e *Engine) Run(ctx context.Context) (*Stats, error) {
 	if len(e.Topology.Roots) == 0 {
 		return nil, fmt.Errorf("no root operations to generate traces from")
@@ -122,14 +239,21 @@ func (e *Engine) Run(ctx context.Context) (*Stats, error) {
 
 	e.linkRegistry = newSpanContextRegistry(e.Topology)
 
+	if e.Realtime && e.Backfill {
+		return nil, fmt.Errorf("realtime and backfill cannot be combined")
+	}
 	if e.Realtime {
 		return e.runRealtime(ctx)
 	}
+	if e.Backfill {
+		return e.runBackfill(ctx)
+	}
 
-	var stats Stats
+	stats := e.newRunStats()
 	startTime := time.Now()
-	deadline := startTime.Add(e.Duration)
+	deadline := startTime.Add(e.runDuration())
 	var lastActive []Scenario
+	streams := newArrivalStreams(e.Topology)
 
 	for {
 		select {
@@ -148,76 +272,121 @@ func (e *Engine) Run(ctx context.Context) (*Stats, error) {
 		elapsed := now.Sub(startTime)
 
 		// Resolve active scenario overrides (including traffic)
-		var overrides map[string]Override
-		var scenarioNames []string
-		trafficPattern := e.Traffic
-		if len(e.Scenarios) > 0 {
-			active := ActiveScenarios(e.Scenarios, elapsed)
-			if len(active) > 0 {
-				overrides = ResolveOverrides(active)
-				if tp := ResolveTraffic(active); tp != nil {
-					trafficPattern = tp
-				}
-				if e.LabelScenarios {
-					scenarioNames = make([]string, len(active))
-					for i, s := range active {
-						scenarioNames[i] = s.Name
-					}
-				}
-			}
-			// Scenario contents are static, so the merged overrides only
-			// change when the active set does — notify observers on
-			// transitions rather than every iteration.
-			if !activeScenariosEqual(active, lastActive) {
-				notifyOverrides(e.Observers, overrides)
-				lastActive = active
-			}
-		}
+		scenarios := e.resolveScenarios(elapsed, &lastActive)
 
-		rate := trafficPattern.Rate(elapsed)
+		stream := nextArrival(streams)
+		pattern := stream.pattern(scenarios.traffic)
+		rate := pattern.Rate(elapsed) * scenarios.multiplier
+		e.trafficPeak = trafficPeak(pattern, elapsed)
 		if rate <= 0 {
-			if waitZeroRate(ctx) {
+			stream.due = now.Add(zeroRateIdleInterval)
+			if waitArrival(ctx, streams) {
 				e.finaliseStats(&stats, startTime)
 				return &stats, nil
 			}
 			continue
 		}
 
-		// Pick a random root operation
-		root := e.Topology.Roots[e.Rng.IntN(len(e.Topology.Roots))]
-
-		// Walk the trace tree with a per-trace span counter.
-		// Shift span start times by TimeOffset so exported timestamps appear
-		// in the past or future, while scenario timing uses real elapsed time.
-		spanStart := now.Add(e.TimeOffset)
-		spanLimit := e.maxSpansPerTrace()
-		spanCount := 0
-		_, rootErr := e.walkTrace(ctx, root, nil, spanStart, elapsed, overrides, scenarioNames, &stats, &spanCount, spanLimit, false, false)
-		stats.Traces++
-		if rootErr {
-			stats.FailedTraces++
-		}
-		if spanCount >= spanLimit {
-			stats.SpansBounded++
+		// Pick a random root operation of the stream that is due; an
+		// arrival at a drained root starts no trace.
+		root := stream.roots[e.Rng.IntN(len(stream.roots))]
+		if !scenarios.drained(root) {
+			e.walkRoot(ctx, root, now, elapsed, scenarios, &stats)
+			if e.MaxTraces > 0 && stats.Traces >= int64(e.MaxTraces) {
+				e.finaliseStats(&stats, startTime)
+				return &stats, nil
+			}
 		}
-		if e.MaxTraces > 0 && stats.Traces >= int64(e.MaxTraces) {
+
+		// Schedule the stream's next arrival after the inter-arrival
+		// interval, then sleep until whichever stream is due first
+		stream.due = time.Now().Add(time.Duration(float64(time.Second) / rate))
+		if waitArrival(ctx, streams) {
 			e.finaliseStats(&stats, startTime)
 			return &stats, nil
 		}
+	}
+}
 
-		// Sleep for the inter-arrival interval
-		interval := time.Duration(float64(time.Second) / rate)
-		select {
-		case <-ctx.Done():
-			e.finaliseStats(&stats, startTime)
-			return &stats, nil
-		case <-time.After(interval):
+// scenarioState is what the scenarios active at one point of a run change.
+type scenarioState struct {
+	active     []Scenario
+	overrides  map[string]Override
+	names      []string // active scenario names, when the engine labels spans with them
+	traffic    TrafficPattern
+	multiplier float64
+}
+
+// resolveScenarios returns the scenarios active at elapsed and their merged
+// overrides and traffic. lastActive is the previously active set, which it
+// updates.
+func (e *Engine) resolveScenarios(elapsed time.Duration, lastActive *[]Scenario) scenarioState {
+	state := scenarioState{traffic: e.Traffic, multiplier: 1}
+	if len(e.Scenarios) == 0 {
+		return state
+	}
+	state.active = ActiveScenarios(e.Scenarios, elapsed)
+	if len(state.active) > 0 {
+		state.overrides = ResolveOverrides(state.active)
+		if tp := ResolveTraffic(state.active); tp != nil {
+			state.traffic = tp
 		}
+		state.multiplier = ResolveTrafficMultiplier(state.active)
+		if e.LabelScenarios {
+			state.names = make([]string, len(state.active))
+			for i, s := range state.active {
+				state.names[i] = s.Name
+			}
+		}
+	}
+	// Scenario contents are static, so the merged overrides only change
+	// when the active set does — notify observers on transitions rather
+	// than every iteration.
+	if !activeScenariosEqual(state.active, *lastActive) {
+		notifyOverrides(e.Observers, state.overrides)
+		notifyScenarioStarts(e.Observers, state.active, *lastActive)
+		e.activeTraceAttrs = ResolveTraceAttributes(state.active)
+		e.activeLabels = ResolveLabels(state.active)
+		*lastActive = state.active
 	}
+	return state
 }
 
-func waitZeroRate(ctx context.Context) bool {
-	timer := time.NewTimer(zeroRateIdleInterval)
+// walkRoot generates one trace from root, started at now, and counts it in
+// stats unless it started during the warmup.
+func (e *Engine) walkRoot(ctx context.Context, root *Operation, now time.Time, elapsed time.Duration, scenarios scenarioState, stats *Stats) {
+	// Walk the trace tree with a per-trace span counter.
+	// Shift span start times by TimeOffset so exported timestamps appear
+	// in the past or future, while scenario timing uses the run's elapsed time.
+	spanStart := now.Add(e.TimeOffset)
+	spanLimit := e.maxSpansPerTrace()
+	spanCount := 0
+	counted := e.countedStats(stats, elapsed)
+	traceCtx := ctx
+	if !e.sampleTrace() {
+		traceCtx = withTraceDropped(ctx)
+		counted.Sampled++
+	}
+	_, rootErr := e.walkTrace(traceCtx, root, nil, spanStart, elapsed, scenarios.overrides, scenarios.names, counted, &spanCount, spanLimit, false, false)
+	counted.Traces++
+	if rootErr {
+		counted.FailedTraces++
+	}
+	if spanCount >= spanLimit {
+		counted.SpansBounded++
+	}
+	if counted.traceCapped {
+		counted.TraceDurationCapped++
+		counted.traceCapped = false
+	}
+	countScenarioTraces(counted, scenarios.active)
+	e.live.publish(stats, nil)
+}
+
+// waitArrival blocks until the next stream is due, reporting whether ctx
+// was cancelled first.
+func waitArrival(ctx context.Context, streams []*arrivalStream) bool {
+	timer := time.NewTimer(time.Until(nextArrival(streams).due))
 	defer timer.Stop()
 
 	select {
@@ -228,10 +397,19 @@ func waitZeroRate(ctx context.Context) bool {
 	}
 }
 
+// countedStats returns the stats a trace started at elapsed counts towards:
+// stats itself, or a discarded scratch Stats while warming up.
+func (e *Engine) countedStats(stats *Stats, elapsed time.Duration) *Stats {
+	if elapsed < e.Warmup {
+		return &Stats{}
+	}
+	return stats
+}
+
 func (e *Engine) finaliseStats(stats *Stats, startTime time.Time) {
 	elapsed := time.Since(startTime)
 	stats.ElapsedMs = elapsed.Milliseconds()
-	secs := elapsed.Seconds()
+	secs := (elapsed - e.Warmup).Seconds()
 	if secs > 0 {
 		stats.TracesPerSec = float64(stats.Traces) / secs
 		stats.SpansPerSec = float64(stats.Spans) / secs
@@ -242,6 +420,8 @@ func (e *Engine) finaliseStats(stats *Stats, startTime time.Time) {
 	if stats.Traces > 0 {
 		stats.TraceErrorRate = float64(stats.FailedTraces) / float64(stats.Traces)
 	}
+	stats.finaliseOperations()
+	e.live.publish(stats, nil)
 }
 
 func (e *Engine) maxInFlightTraces() int {
@@ -258,10 +438,15 @@ func (e *Engine) maxInFlightTraces() int {
 // during planning, not emission. This means the state sees each trace as
 // completing instantly rather than over its wall-clock duration. For a synthetic
 // data generator this is an acceptable trade-off that keeps the state serial.
+//
+// Dispatches are paced against each arrival stream's schedule, advanced by
+// the stream's inter-arrival interval, so planning time does not erode the
+// emitted rate. A dispatch that falls behind schedule resets it to the
+// current time rather than bursting to catch up.
 func (e *Engine) runRealtime(ctx context.Context) (*Stats, error) {
-	var stats Stats
+	stats := e.newRunStats()
 	startTime := time.Now()
-	deadline := startTime.Add(e.Duration)
+	deadline := startTime.Add(e.runDuration())
 
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, e.maxInFlightTraces())
@@ -272,6 +457,7 @@ func (e *Engine) runRealtime(ctx context.Context) (*Stats, error) {
 	intervalTimer := time.NewTimer(0)
 	defer intervalTimer.Stop()
 	<-intervalTimer.C
+	streams := newArrivalStreams(e.Topology)
 
 	for {
 		select {
@@ -293,35 +479,15 @@ func (e *Engine) runRealtime(ctx context.Context) (*Stats, error) {
 
 		elapsed := now.Sub(startTime)
 
-		var overrides map[string]Override
-		var scenarioNames []string
-		trafficPattern := e.Traffic
-		if len(e.Scenarios) > 0 {
-			active := ActiveScenarios(e.Scenarios, elapsed)
-			if len(active) > 0 {
-				overrides = ResolveOverrides(active)
-				if tp := ResolveTraffic(active); tp != nil {
-					trafficPattern = tp
-				}
-				if e.LabelScenarios {
-					scenarioNames = make([]string, len(active))
-					for i, s := range active {
-						scenarioNames[i] = s.Name
-					}
-				}
-			}
-			// Scenario contents are static, so the merged overrides only
-			// change when the active set does — notify observers on
-			// transitions rather than every iteration.
-			if !activeScenariosEqual(active, lastActive) {
-				notifyOverrides(e.Observers, overrides)
-				lastActive = active
-			}
-		}
+		scenarios := e.resolveScenarios(elapsed, &lastActive)
 
-		rate := trafficPattern.Rate(elapsed)
+		stream := nextArrival(streams)
+		pattern := stream.pattern(scenarios.traffic)
+		rate := pattern.Rate(elapsed) * scenarios.multiplier
+		e.trafficPeak = trafficPeak(pattern, elapsed)
 		if rate <= 0 {
-			if waitZeroRate(ctx) {
+			stream.due = now.Add(zeroRateIdleInterval)
+			if waitArrival(ctx, streams) {
 				wg.Wait()
 				e.mergeRealtimeStats(&stats, &rstats)
 				e.finaliseStats(&stats, startTime)
@@ -330,49 +496,76 @@ func (e *Engine) runRealtime(ctx context.Context) (*Stats, error) {
 			continue
 		}
 
-		// Block until a slot is available (semaphore).
-		select {
-		case sem <- struct{}{}:
-		case <-ctx.Done():
-			wg.Wait()
-			e.mergeRealtimeStats(&stats, &rstats)
-			e.finaliseStats(&stats, startTime)
-			return &stats, nil
-		}
-
-		root := e.Topology.Roots[e.Rng.IntN(len(e.Topology.Roots))]
-
-		spanStart := now
-		spanLimit := e.maxSpansPerTrace()
-		spanCount := 0
+		// An arrival at a drained root starts no trace.
+		root := stream.roots[e.Rng.IntN(len(stream.roots))]
+		if !scenarios.drained(root) {
+			// Block until a slot is available (semaphore).
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				wg.Wait()
+				e.mergeRealtimeStats(&stats, &rstats)
+				e.finaliseStats(&stats, startTime)
+				return &stats, nil
+			}
 
-		// planTrace does not count Spans or Errors — those are counted
-		// atomically during emission. It does count Timeouts, Retries,
-		// QueueRejections, and CircuitBreakerTrips which are plan-phase
-		// decisions.
-		var plans []SpanPlan
-		_, rootErr := e.planTrace(root, nil, -1, spanStart, elapsed, overrides, scenarioNames, &stats, &plans, &spanCount, spanLimit, false, false)
-		stats.Traces++
-		if rootErr {
-			stats.FailedTraces++
-		}
-		if spanCount >= spanLimit {
-			stats.SpansBounded++
-		}
-		wg.Go(func() {
-			defer func() { <-sem }()
-			emitTrace(ctx, plans, spanStart, now, e.Tracers, e.Observers, &rstats, e.linkRegistry)
-		})
+			counted := e.countedStats(&stats, elapsed)
+			traceStats := &rstats
+			if counted != &stats {
+				traceStats = &realtimeStats{}
+			}
+			tracers := e.Tracers
+			if !e.sampleTrace() {
+				tracers = droppedTracers
+				counted.Sampled++
+			}
 
-		if e.MaxTraces > 0 && stats.Traces >= int64(e.MaxTraces) {
-			wg.Wait()
-			e.mergeRealtimeStats(&stats, &rstats)
-			e.finaliseStats(&stats, startTime)
-			return &stats, nil
+			spanStart := now
+			spanLimit := e.maxSpansPerTrace()
+			spanCount := 0
+
+			// planTrace does not count Spans or Errors — those are counted
+			// atomically during emission. It does count Timeouts, Retries,
+			// Hedges, QueueRejections, CircuitBreakerTrips,
+			// RateLimitRejections, and FlapRejections which are plan-phase
+			// decisions.
+			var plans []SpanPlan
+			_, rootErr := e.planTrace(root, nil, -1, spanStart, elapsed, scenarios.overrides, scenarios.names, counted, &plans, &spanCount, spanLimit, false, false)
+			counted.Traces++
+			if rootErr {
+				counted.FailedTraces++
+			}
+			if spanCount >= spanLimit {
+				counted.SpansBounded++
+			}
+			if counted.traceCapped {
+				counted.TraceDurationCapped++
+				counted.traceCapped = false
+			}
+			countScenarioTraces(counted, scenarios.active)
+			wg.Go(func() {
+				defer func() { <-sem }()
+				emitTrace(withRootTraceState(ctx, root), plans, spanStart, now, tracers, e.Observers, traceStats, e.linkRegistry)
+			})
+			e.live.publish(&stats, &rstats)
+
+			if e.MaxTraces > 0 && stats.Traces >= int64(e.MaxTraces) {
+				wg.Wait()
+				e.mergeRealtimeStats(&stats, &rstats)
+				e.finaliseStats(&stats, startTime)
+				return &stats, nil
+			}
 		}
 
 		interval := time.Duration(float64(time.Second) / rate)
-		intervalTimer.Reset(interval)
+		if stream.due.IsZero() {
+			stream.due = now
+		}
+		stream.due = stream.due.Add(interval)
+		if current := time.Now(); stream.due.Before(current) {
+			stream.due = current
+		}
+		intervalTimer.Reset(time.Until(nextArrival(streams).due))
 		select {
 		case <-ctx.Done():
 			wg.Wait()
@@ -389,6 +582,83 @@ func (e *Engine) mergeRealtimeStats(stats *Stats, rstats *realtimeStats) {
 	stats.Errors += rstats.Errors.Load()
 }
 
+// droppedTraceKey marks a context whose trace was dropped by head sampling.
+type droppedTraceKey struct{}
+
+// droppedTracers supplies no-op tracers for traces dropped by head sampling.
+// Every span in a dropped trace must come from it so no partial trace leaks.
+var droppedTracers TracerSource = func(string) trace.Tracer {
+	return noop.NewTracerProvider().Tracer("")
+}
+
+func withTraceDropped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, droppedTraceKey{}, true)
+}
+
+// traceAttrsKey carries the trace attributes generated at a trace's root
+// down to its descendant spans.
+type traceAttrsKey struct{}
+
+func withTraceAttributes(ctx context.Context, attrs []attribute.KeyValue) context.Context {
+	return context.WithValue(ctx, traceAttrsKey{}, attrs)
+}
+
+func traceAttributesFromContext(ctx context.Context) []attribute.KeyValue {
+	attrs, _ := ctx.Value(traceAttrsKey{}).([]attribute.KeyValue)
+	return attrs
+}
+
+// traceDeadlineKey carries the time by which a trace must end when the
+// engine's MaxTraceDuration is set.
+type traceDeadlineKey struct{}
+
+func withTraceDeadline(ctx context.Context, deadline time.Time) context.Context {
+	return context.WithValue(ctx, traceDeadlineKey{}, deadline)
+}
+
+func traceDeadlineFromContext(ctx context.Context) (time.Time, bool) {
+	deadline, ok := ctx.Value(traceDeadlineKey{}).(time.Time)
+	return deadline, ok
+}
+
+// traceDepthKey carries the depth of the span whose calls are being walked,
+// the root span being depth 0.
+type traceDepthKey struct{}
+
+func withTraceDepth(ctx context.Context, depth int) context.Context {
+	return context.WithValue(ctx, traceDepthKey{}, depth)
+}
+
+func traceDepthFromContext(ctx context.Context) int {
+	depth, _ := ctx.Value(traceDepthKey{}).(int)
+	return depth
+}
+
+// rootTraceAttributes generates the attributes shared by every span of a
+// new trace: the topology's trace attributes overlaid with those of the
+// active scenarios.
+func (e *Engine) rootTraceAttributes() []attribute.KeyValue {
+	return attributeKeyValues(e.Topology.TraceAttributes.Merge(e.activeTraceAttrs), e.Rng)
+}
+
+// sampleTrace makes the head-sampling decision for the next trace. It consumes
+// randomness only when SampleRatio is strictly between zero and one.
+func (e *Engine) sampleTrace() bool {
+	if e.SampleRatio <= 0 || e.SampleRatio >= 1 {
+		return true
+	}
+	return e.Rng.Float64() < e.SampleRatio
+}
+
+// tracer returns the tracer for a service, or a no-op tracer when the trace
+// being walked was dropped by head sampling.
+func (e *Engine) tracer(ctx context.Context, service string) trace.Tracer {
+	if dropped, _ := ctx.Value(droppedTraceKey{}).(bool); dropped {
+		return droppedTracers(service)
+	}
+	return e.Tracers(service)
+}
+
 func (e *Engine) maxSpansPerTrace() int {
 	if e.MaxSpansPerTrace > 0 {
 		return e.MaxSpansPerTrace
@@ -407,12 +677,38 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 	if *spanCount >= spanLimit {
 		return startTime, false
 	}
+	deadline, capped := traceDeadlineFromContext(ctx)
+	if capped && !startTime.Before(deadline) {
+		// The trace has used up MaxTraceDuration, so a call still pending
+		// is cut off before its span starts.
+		stats.traceCapped = true
+		return startTime, true
+	}
+	if ov := overrides[op.Ref]; ov.Drained {
+		// A drained operation emits no span: the call to it fails at once,
+		// or is skipped as if it had been removed.
+		return startTime, ov.DrainErrors
+	}
 	*spanCount++
-	tracer := e.Tracers(op.Service.Name)
+
+	// Trace attributes and service regions are generated once at the root
+	// and inherited through the context by every descendant span.
+	traceAttrs := traceAttributesFromContext(ctx)
+	depth := traceDepthFromContext(ctx)
+	if parent == nil {
+		traceAttrs = e.rootTraceAttributes()
+		ctx = withTraceAttributes(withRootTraceState(ctx, op), traceAttrs)
+		ctx = withTraceRegions(ctx, newTraceRegions(e.Topology))
+		if e.MaxTraceDuration > 0 {
+			deadline, capped = startTime.Add(e.MaxTraceDuration), true
+			ctx = withTraceDeadline(ctx, deadline)
+		}
+	}
+	tracer := e.tracer(ctx, op.Service.Name)
 
 	// Determine effective duration, error rate, and attributes (apply overrides if active)
 	duration := op.Duration
-	errorRate := effectiveErrorRate(op, overrides)
+	errorRate := e.peakErrorRate(op, effectiveErrorRate(op, overrides))
 	opAttrs := op.Attributes
 	if ov, ok := overrides[op.Ref]; ok {
 		if ov.Duration.Mean > 0 {
@@ -423,13 +719,23 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 		}
 	}
 
-	// Consult simulation state for queue depth, circuit breaker, backpressure
+	// A flapping service in a down window rejects every request.
+	if op.Service.Flap != nil && op.Service.Flap.down(elapsed) {
+		stats.FlapRejections++
+		notifyPlanEvent(e.Observers, PlanEvent{Kind: PlanEventUnavailable, Service: op.Service.Name, Operation: op.Name, Timestamp: startTime})
+		stats.recordOperation(op.Ref, rejectionDuration, true)
+		return e.emitRejectionSpan(ctx, op, parent, startTime, ReasonUnavailable, scenarioNames, stats, isAsync, isProducer)
+	}
+
+	// Consult simulation state for queue depth, circuit breaker, backpressure,
+	// cold start
 	var opState *OperationState
+	var startDelay time.Duration
 	if e.State != nil {
 		opState = e.State.Get(op.Ref)
 	}
 	if opState != nil {
-		durationMult, errAdd, rejected, reason := opState.Admit(elapsed, e.Rng)
+		durationMult, errAdd, rejected, reason := opState.Admit(startTime, elapsed, e.Rng)
 		if rejected {
 			switch reason {
 			case ReasonQueueFull:
@@ -438,14 +744,18 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 			case ReasonCircuitOpen:
 				stats.CircuitBreakerTrips++
 				notifyPlanEvent(e.Observers, PlanEvent{Kind: PlanEventCircuitBreakerTrip, Service: op.Service.Name, Operation: op.Name, Timestamp: startTime})
+			case ReasonRateLimited:
+				stats.RateLimitRejections++
+				notifyPlanEvent(e.Observers, PlanEvent{Kind: PlanEventRateLimitRejection, Service: op.Service.Name, Operation: op.Name, Timestamp: startTime})
 			}
+			stats.recordOperation(op.Ref, rejectionDuration, true)
 			return e.emitRejectionSpan(ctx, op, parent, startTime, reason, scenarioNames, stats, isAsync, isProducer)
 		}
 		if durationMult > 1.0 {
-			duration.Mean = time.Duration(float64(duration.Mean) * durationMult)
+			duration = duration.slowedBy(durationMult)
 		}
 		errorRate = min(errorRate+errAdd, 1.0)
-		opState.Enter()
+		startDelay = opState.QueueWait(duration.Mean, e.Rng) + opState.ColdStart()
 	}
 
 	// Determine span kind: SERVER for roots, PRODUCER for producer callees,
@@ -457,8 +767,9 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 	// inherited from the parent context, then propagate the combined set to
 	// descendant spans via the context. baggageAsAttributes surfaces it below.
 	inheritedBaggage := baggageToMap(baggage.FromContext(ctx))
-	mergedBaggage := overlayBaggageMap(inheritedBaggage, op.Baggage)
-	if len(op.Baggage) > 0 {
+	declared := declaredBaggage(op, e.Rng)
+	mergedBaggage := overlayBaggageMap(inheritedBaggage, declared)
+	if len(declared) > 0 {
 		ctx = baggage.ContextWithBaggage(ctx, buildBaggage(mergedBaggage))
 	}
 
@@ -469,6 +780,7 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 	if e.LabelScenarios {
 		startAttrs = append(startAttrs, attribute.StringSlice("synth.scenarios", scenarioNames))
 	}
+	startAttrs = append(startAttrs, e.activeLabels...)
 
 	startOpts := []trace.SpanStartOption{
 		trace.WithTimestamp(startTime),
@@ -489,8 +801,16 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 			startOpts = append(startOpts, trace.WithLinks(links...))
 		}
 	}
+	retry := retryAttemptFromContext(ctx)
+	if link, ok := retry.link(); ok {
+		startOpts = append(startOpts, trace.WithLinks(link))
+	}
 
 	ctx, span := tracer.Start(ctx, op.Name, startOpts...)
+	if retry != nil {
+		retry.span = span.SpanContext()
+		ctx = withRetryAttempt(ctx, nil)
+	}
 
 	if e.linkRegistry != nil {
 		e.linkRegistry.store(op.Ref, span.SpanContext())
@@ -498,18 +818,30 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 
 	notifySpanStart(e.Observers, op.Service.Name, op.Name)
 
-	// Collect attributes for both the span and observers
-	spanAttrs := make([]attribute.KeyValue, 0, len(op.Service.Attributes)+len(opAttrs))
+	// Collect attributes for both the span and observers. The slice comes
+	// from a pool and is returned once the span and observers are done.
+	attrBuf := getSpanAttrs(len(traceAttrs) + spanAttrCapacity(op, opAttrs, mergedBaggage))
+	spanAttrs := append(*attrBuf, traceAttrs...)
 	for k, v := range op.Service.Attributes {
 		spanAttrs = append(spanAttrs, attribute.String(k, v))
 	}
+	if region, ok := traceRegionsFromContext(ctx).region(op.Service, e.Rng); ok {
+		spanAttrs = append(spanAttrs, region)
+	}
 	for _, a := range opAttrs {
 		spanAttrs = append(spanAttrs, typedAttribute(a.Key, a.Gen.Generate(e.Rng)))
 	}
 	if op.BaggageAsAttributes {
 		spanAttrs = append(spanAttrs, baggageAttributesFromMap(mergedBaggage)...)
 	}
+	cacheHit := e.rollCacheHit(op)
+	if op.Cache != nil {
+		spanAttrs = append(spanAttrs, attribute.Bool(cacheHitAttribute, cacheHit))
+	}
 	span.SetAttributes(spanAttrs...)
+	if op.SpanName != "" {
+		span.SetName(spanName(op, spanAttrs))
+	}
 
 	for _, evt := range op.Events {
 		evtOpts := []trace.EventOption{
@@ -533,13 +865,26 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 			ownError = e.Rng.Float64() < errorRate
 		}
 	}
+	var errType *ErrorType
+	if ownError {
+		if errType = e.pickErrorType(op); errType != nil {
+			errAttrs := attributeKeyValues(errType.Attributes, e.Rng)
+			span.SetAttributes(errAttrs...)
+			spanAttrs = append(spanAttrs, errAttrs...)
+		}
+	}
 
 	// Sample own processing duration
 	ownDuration := duration.Sample(e.Rng)
 
-	// Pre-call work: half the own duration before calling downstream
+	// Pre-call work: any queue wait or cold start, then half the own
+	// duration before calling downstream
 	preCallDuration := ownDuration / 2
-	childStartTime := startTime.Add(preCallDuration)
+	childStartTime := startTime.Add(startDelay + preCallDuration)
+	// Post-call work: the rest of the own duration. A return-early
+	// operation is done by returnBy whatever its calls do.
+	postCallDuration := ownDuration - preCallDuration
+	returnBy := childStartTime.Add(postCallDuration)
 
 	// Build effective call list (base calls + scenario adds - removes)
 	baseCalls := effectiveCalls(op, overrides)
@@ -547,6 +892,9 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 	// Filter calls by condition and probability (uses own error state, not cascaded)
 	activeCalls := make([]activeCall, 0, len(baseCalls))
 	for i, call := range baseCalls {
+		if cacheHit && op.Cache.SkipCalls[call.Operation.Ref] {
+			continue
+		}
 		if call.Condition == "on-error" && !ownError {
 			continue
 		}
@@ -559,7 +907,7 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 				fire, ok = e.forcedChoice(choiceKindCallProbability, op.Ref, call.Operation.Ref, i)
 			}
 			if !ok {
-				fire = e.Rng.Float64() < call.Probability
+				fire = e.Rng.Float64() < call.probabilityAt(depth)
 			}
 			if !fire {
 				continue
@@ -567,38 +915,55 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 		}
 		activeCalls = append(activeCalls, activeCall{Call: call, ChoiceIndex: i})
 	}
+	// The one_of branch is not a swarm choice point; index -1 never matches
+	// a call's retry choice.
+	if call, ok := e.pickOneOf(op); ok && (!cacheHit || !op.Cache.SkipCalls[call.Operation.Ref]) {
+		activeCalls = append(activeCalls, activeCall{Call: call, ChoiceIndex: -1})
+	}
 
 	// Walk downstream calls (parallel or sequential) with fan-out
+	ctx = withTraceDepth(ctx, depth+1)
 	latestChildEnd := childStartTime
 	anyChildFailed := false
+	failedChild := "" // ref of the first failed call, named in a cascaded error's message
+	// childTimedOut records whether failedChild failed by timing out.
+	childTimedOut := false
 	if op.CallStyle == "sequential" {
 		nextStart := childStartTime
 		for _, active := range activeCalls {
-			count := max(active.Call.Count, 1)
+			count := e.callCount(active.Call)
 			for range count {
-				perceivedEnd, failed := e.executeCall(ctx, active, op, nextStart, elapsed, overrides, scenarioNames, stats, spanCount, spanLimit)
+				perceivedEnd, failed, timedOut := e.executeCall(ctx, active, op, nextStart, elapsed, overrides, scenarioNames, stats, spanCount, spanLimit)
 				if active.Call.Async {
 					continue
 				}
-				if failed {
+				nextStart = perceivedEnd
+				if !op.awaits(perceivedEnd, returnBy) {
+					continue
+				}
+				if failed && !anyChildFailed {
 					anyChildFailed = true
+					failedChild = active.Call.Operation.Ref
+					childTimedOut = timedOut
 				}
 				if perceivedEnd.After(latestChildEnd) {
 					latestChildEnd = perceivedEnd
 				}
-				nextStart = perceivedEnd
 			}
 		}
 	} else {
 		for _, active := range activeCalls {
-			count := max(active.Call.Count, 1)
+			count := e.callCount(active.Call)
 			for range count {
-				perceivedEnd, failed := e.executeCall(ctx, active, op, childStartTime, elapsed, overrides, scenarioNames, stats, spanCount, spanLimit)
-				if active.Call.Async {
+				callStart := childStartTime.Add(e.callJitter(op))
+				perceivedEnd, failed, timedOut := e.executeCall(ctx, active, op, callStart, elapsed, overrides, scenarioNames, stats, spanCount, spanLimit)
+				if active.Call.Async || !op.awaits(perceivedEnd, returnBy) {
 					continue
 				}
-				if failed {
+				if failed && !anyChildFailed {
 					anyChildFailed = true
+					failedChild = active.Call.Operation.Ref
+					childTimedOut = timedOut
 				}
 				if perceivedEnd.After(latestChildEnd) {
 					latestChildEnd = perceivedEnd
@@ -607,24 +972,58 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 		}
 	}
 
-	// End time: max(child_end) + post-call overhead (remaining half of own duration)
-	postCallDuration := ownDuration - preCallDuration
-	endTime := latestChildEnd.Add(postCallDuration)
+	// End time: max(child_end) + post-call overhead (remaining half of own
+	// duration), or as the operation's timing says otherwise
+	endTime := op.spanEnd(latestChildEnd, postCallDuration, returnBy)
 
-	// Cascade child failures to parent
-	isError := ownError || anyChildFailed
+	// A span still running at the trace deadline is cut off there.
+	truncated := capped && endTime.After(deadline)
+	if truncated {
+		endTime = deadline
+		stats.traceCapped = true
+	}
 
+	slow := op.isSlow(endTime.Sub(startTime))
+	if slow && op.SlowStatus == slowStatusAttribute {
+		span.SetAttributes(slowAttribute)
+		spanAttrs = append(spanAttrs, slowAttribute)
+	}
+	slowError := slow && op.SlowStatus == slowStatusError
+
+	// Cascade child failures to parent, unless it swallows them
+	if op.SwallowErrors {
+		anyChildFailed, childTimedOut = false, false
+	}
+	isError := ownError || anyChildFailed || truncated || slowError
+
+	deadlineExceeded := truncated || (!ownError && childTimedOut)
+	if code, ok := e.httpStatusAttribute(op, opAttrs, errType, isError, deadlineExceeded); ok {
+		span.SetAttributes(code)
+		spanAttrs = append(spanAttrs, code)
+	}
 	if isError {
-		span.SetStatus(codes.Error, "synthetic error")
-		span.RecordError(fmt.Errorf("synthetic error"), trace.WithTimestamp(endTime))
+		if code, ok := e.grpcStatusAttribute(op, opAttrs, errType, deadlineExceeded); ok {
+			span.SetAttributes(code)
+			spanAttrs = append(spanAttrs, code)
+		}
+		msg := errorStatusMessage(op, errType, ownError, failedChild, spanAttrs)
+		switch {
+		case truncated:
+			msg = traceDeadlineMessage
+		case !ownError && !anyChildFailed:
+			msg = slowErrorMessage(op, endTime.Sub(startTime))
+		}
+		span.SetStatus(codes.Error, msg)
+		span.RecordError(errors.New(msg), trace.WithTimestamp(endTime))
 		stats.Errors++
 	}
 
 	stats.Spans++
+	stats.recordOperation(op.Ref, endTime.Sub(startTime), isError)
 	span.End(trace.WithTimestamp(endTime))
 
 	if opState != nil {
-		opState.Exit(elapsed, endTime.Sub(startTime), isError)
+		opState.Exit(elapsed, endTime, endTime.Sub(startTime), isError)
 	}
 
 	if len(e.Observers) > 0 {
@@ -644,9 +1043,134 @@ func (e *Engine) walkTrace(ctx context.Context, op, parent *Operation, startTime
 		}
 	}
 
+	*attrBuf = spanAttrs
+	putSpanAttrs(attrBuf)
+
 	return endTime, isError
 }
 
+// callJitter samples how long after the parent's call point a parallel call
+// starts, so siblings stagger instead of starting together. Samples are never
+// negative, so a child never starts before its parent, and the parent's end
+// already follows its latest child. It consumes randomness only when the
+// operation sets call_jitter.
+func (e *Engine) callJitter(op *Operation) time.Duration {
+	if op.CallJitter == (Distribution{}) {
+		return 0
+	}
+	return op.CallJitter.Sample(e.Rng)
+}
+
+// traceDeadlineMessage is the status description of a span cut off at the
+// engine's MaxTraceDuration.
+const traceDeadlineMessage = "deadline exceeded: trace duration cap reached"
+
+// syntheticErrorMessage is the status description of an operation's own
+// errors when neither its error_message nor an error type sets one.
+const syntheticErrorMessage = "synthetic error"
+
+// errorStatusMessage returns the status description of an errored span. An
+// operation's own error uses the message of its error type, then the
+// operation's error_message, then the error type's name. A span that errored
+// only because a call failed names the first failed call. {key} placeholders
+// resolve against the span's attributes and service.name and operation.name.
+func errorStatusMessage(op *Operation, et *ErrorType, ownError bool, failedChild string, attrs []attribute.KeyValue) string {
+	if !ownError {
+		return fmt.Sprintf("call to %s failed", failedChild)
+	}
+	msg := syntheticErrorMessage
+	switch {
+	case et != nil && et.Message != "":
+		msg = et.Message
+	case op.ErrorMessage != "":
+		msg = op.ErrorMessage
+	case et != nil:
+		msg = et.Name
+	}
+	return interpolateMessage(msg, attrs, op)
+}
+
+// spanName returns the name of op's span: its span_name with placeholders
+// resolved against the span's attributes as in error messages, or the
+// operation name when it has none or it resolves to nothing.
+func spanName(op *Operation, attrs []attribute.KeyValue) string {
+	return cmp.Or(interpolateMessage(op.SpanName, attrs, op), op.Name)
+}
+
+// interpolateMessage replaces {key} placeholders in an error message, as
+// interpolateBody does for log bodies. Unresolved placeholders are left as
+// literal text.
+func interpolateMessage(msg string, attrs []attribute.KeyValue, op *Operation) string {
+	if !strings.Contains(msg, "{") {
+		return msg
+	}
+	return placeholderPattern.ReplaceAllStringFunc(msg, func(match string) string {
+		key := match[1 : len(match)-1]
+		for _, kv := range attrs {
+			if string(kv.Key) == key {
+				return kv.Value.Emit()
+			}
+		}
+		switch key {
+		case "service.name":
+			return op.Service.Name
+		case "operation.name":
+			return op.Name
+		}
+		return match
+	})
+}
+
+// pickErrorType chooses one of op's error types by weight, or returns nil
+// when it has none. Randomness is consumed only when there is a choice.
+func (e *Engine) pickErrorType(op *Operation) *ErrorType {
+	switch len(op.ErrorTypes) {
+	case 0:
+		return nil
+	case 1:
+		return &op.ErrorTypes[0]
+	}
+	total := 0
+	for _, et := range op.ErrorTypes {
+		total += et.Weight
+	}
+	r := e.Rng.IntN(total)
+	for i := range op.ErrorTypes {
+		r -= op.ErrorTypes[i].Weight
+		if r < 0 {
+			return &op.ErrorTypes[i]
+		}
+	}
+	return &op.ErrorTypes[len(op.ErrorTypes)-1]
+}
+
+// pickOneOf chooses the call from op's one_of group by weight, returning
+// false when op has no group. Randomness is consumed only when there is a
+// choice.
+func (e *Engine) pickOneOf(op *Operation) (Call, bool) {
+	switch len(op.OneOf) {
+	case 0:
+		return Call{}, false
+	case 1:
+		return op.OneOf[0], op.OneOf[0].Weight > 0
+	}
+	total := 0
+	for _, call := range op.OneOf {
+		total += call.Weight
+	}
+	if total <= 0 {
+		return Call{}, false
+	}
+	r := e.Rng.IntN(total)
+	for _, call := range op.OneOf {
+		r -= call.Weight
+		if r < 0 {
+			return call, true
+		}
+	}
+	return op.OneOf[len(op.OneOf)-1], true
+}
+
 // parentNames returns the service and operation names of a parent operation,
 // or empty strings when parent is nil (root spans).
 func parentNames(parent *Operation) (string, string) {
@@ -660,7 +1184,7 @@ func parentNames(parent *Operation) (string, string) {
 // The caller (walkTrace) has already counted this span against the trace's
 // span limit, so spanCount is not incremented here.
 func (e *Engine) emitRejectionSpan(ctx context.Context, op, parent *Operation, startTime time.Time, reason string, scenarioNames []string, stats *Stats, isAsync, isProducer bool) (time.Time, bool) {
-	tracer := e.Tracers(op.Service.Name)
+	tracer := e.tracer(ctx, op.Service.Name)
 	endTime := startTime.Add(rejectionDuration)
 
 	kind := spanKindFor(e.Topology, op, parent, isAsync, isProducer)
@@ -674,12 +1198,22 @@ func (e *Engine) emitRejectionSpan(ctx context.Context, op, parent *Operation, s
 	if e.LabelScenarios {
 		rejAttrs = append(rejAttrs, attribute.StringSlice("synth.scenarios", scenarioNames))
 	}
+	rejAttrs = append(rejAttrs, e.activeLabels...)
+	rejAttrs = append(rejAttrs, traceAttributesFromContext(ctx)...)
 
-	_, span := tracer.Start(ctx, op.Name,
+	startOpts := []trace.SpanStartOption{
 		trace.WithTimestamp(startTime),
 		trace.WithSpanKind(kind),
 		trace.WithAttributes(rejAttrs...),
-	)
+	}
+	retry := retryAttemptFromContext(ctx)
+	if link, ok := retry.link(); ok {
+		startOpts = append(startOpts, trace.WithLinks(link))
+	}
+	_, span := tracer.Start(ctx, op.Name, startOpts...)
+	if retry != nil {
+		retry.span = span.SpanContext()
+	}
 	span.SetStatus(codes.Error, reason)
 	span.RecordError(fmt.Errorf("rejected: %s", reason), trace.WithTimestamp(endTime))
 	span.End(trace.WithTimestamp(endTime))
@@ -717,42 +1251,121 @@ type activeCall struct {
 
 // executeCall runs a single downstream call, applying timeout capping and retries.
 // parent is the calling operation.
-func (e *Engine) executeCall(ctx context.Context, active activeCall, parent *Operation, callStart time.Time, elapsed time.Duration, overrides map[string]Override, scenarioNames []string, stats *Stats, spanCount *int, spanLimit int) (time.Time, bool) {
+func (e *Engine) executeCall(ctx context.Context, active activeCall, parent *Operation, callStart time.Time, elapsed time.Duration, overrides map[string]Override, scenarioNames []string, stats *Stats, spanCount *int, spanLimit int) (time.Time, bool, bool) {
 	call := active.Call
 	maxAttempts := 1 + call.Retries
 	attemptStart := callStart
+	overrides = callOverrides(call, overrides)
+	var previous trace.SpanContext
 
 	for attempt := range maxAttempts {
-		childEnd, childErr := e.walkTrace(ctx, call.Operation, parent, attemptStart, elapsed, overrides, scenarioNames, stats, spanCount, spanLimit, call.Async, call.Producer)
+		attemptCtx := ctx
+		var retry *retryAttempt
+		if e.LinkRetries && call.Retries > 0 {
+			retry = &retryAttempt{number: attempt + 1, previous: previous}
+			attemptCtx = withRetryAttempt(ctx, retry)
+		}
+		childEnd, childErr := e.walkTrace(attemptCtx, call.Operation, parent, attemptStart, elapsed, overrides, scenarioNames, stats, spanCount, spanLimit, call.Async, call.Producer)
+
+		// Hedge: if the attempt is still running at hedge_after, a second
+		// attempt starts in parallel and whichever finishes first wins.
+		if call.HedgeAfter > 0 && childEnd.Sub(attemptStart) > call.HedgeAfter && *spanCount < spanLimit {
+			hedgeStart := attemptStart.Add(call.HedgeAfter)
+			stats.Hedges++
+			notifyPlanEvent(e.Observers, PlanEvent{Kind: PlanEventHedge, Service: call.Operation.Service.Name, Operation: call.Operation.Name, Timestamp: hedgeStart})
+			hedgeEnd, hedgeErr := e.walkTrace(ctx, call.Operation, parent, hedgeStart, elapsed, overrides, scenarioNames, stats, spanCount, spanLimit, call.Async, call.Producer)
+			if hedgeEnd.Before(childEnd) {
+				childEnd, childErr = hedgeEnd, hedgeErr
+			}
+		}
 		perceivedEnd := childEnd
 		failed := childErr
 
-		if call.Timeout > 0 && childEnd.Sub(attemptStart) > call.Timeout {
+		timedOut := call.Timeout > 0 && childEnd.Sub(attemptStart) > call.Timeout
+		if timedOut {
 			perceivedEnd = attemptStart.Add(call.Timeout)
 			failed = true
 			stats.Timeouts++
+			stats.recordTimeout(call.Operation.Ref)
 			notifyPlanEvent(e.Observers, PlanEvent{Kind: PlanEventTimeout, Service: call.Operation.Service.Name, Operation: call.Operation.Name, Timestamp: perceivedEnd})
 		}
 
 		if attempt < maxAttempts-1 {
 			if retry, ok := e.forcedChoice(choiceKindRetryActivation, parent.Ref, call.Operation.Ref, active.ChoiceIndex); ok {
 				if !retry {
-					return perceivedEnd, failed
+					return perceivedEnd, failed, timedOut
 				}
 				failed = true
 			}
 		}
 
 		if !failed || attempt == maxAttempts-1 {
-			return perceivedEnd, failed
+			return perceivedEnd, failed, timedOut
 		}
 
 		stats.Retries++
 		notifyPlanEvent(e.Observers, PlanEvent{Kind: PlanEventRetry, Service: call.Operation.Service.Name, Operation: call.Operation.Name, Timestamp: perceivedEnd})
-		attemptStart = perceivedEnd.Add(call.RetryBackoff)
+		attemptStart = perceivedEnd.Add(e.retryGap(call, attempt))
+		if retry != nil {
+			previous = retry.span
+		}
+	}
+
+	return callStart, true, false // unreachable: loop always returns on final iteration
+}
+
+// callCount returns how many times call fires for one parent span: a sample
+// from its count distribution, or its fixed count.
+func (e *Engine) callCount(call Call) int {
+	if call.CountDist != nil {
+		return call.CountDist.Sample(e.Rng)
+	}
+	return max(call.Count, 1)
+}
+
+// callOverrides returns the overrides to apply beneath call. A per-call
+// error rate or attributes become an override on the callee, which the
+// acyclic topology guarantees only reaches the callee's own spans. An active
+// scenario that sets the callee's error rate takes precedence; one that
+// multiplies it scales the per-call rate. Scenario attributes likewise win
+// over the call's.
+func callOverrides(call Call, overrides map[string]Override) map[string]Override {
+	if !call.HasErrorRate && len(call.Attributes) == 0 {
+		return overrides
 	}
+	ov := overrides[call.Operation.Ref]
+	if call.HasErrorRate && !ov.HasErrorRate {
+		ov.ErrorRate, ov.HasErrorRate = ov.errorRate(call.ErrorRate), true
+	}
+	ov.Attributes = call.Attributes.Merge(ov.Attributes)
+	merged := maps.Clone(overrides)
+	if merged == nil {
+		merged = make(map[string]Override, 1)
+	}
+	merged[call.Operation.Ref] = ov
+	return merged
+}
 
-	return callStart, true // unreachable: loop always returns on final iteration
+// peakErrorRate scales an operation's error rate by its ErrorRateAtPeak in
+// proportion to how close traffic is to its peak.
+func (e *Engine) peakErrorRate(op *Operation, rate float64) float64 {
+	if op.ErrorRateAtPeak == 0 {
+		return rate
+	}
+	return min(rate*(1+(op.ErrorRateAtPeak-1)*e.trafficPeak), 1)
+}
+
+// retryGap returns the wait after failed attempt n (counting from 0) before
+// the call is retried. Jitter draws from Rng only when the call has both a
+// backoff and jitter configured, so other calls keep their random sequence.
+// The backoff is capped at maxRetryGap before jitter is applied.
+func (e *Engine) retryGap(call Call, attempt int) time.Duration {
+	gap := float64(call.RetryBackoff) * math.Pow(max(call.RetryBackoffMultiplier, 1), float64(attempt))
+	gap = min(gap, float64(maxRetryGap))
+	if gap > 0 && call.RetryJitter > 0 {
+		gap -= gap * call.RetryJitter * e.Rng.Float64()
+	}
+	return time.Duration(gap)
 }
 
 // activeScenariosEqual reports whether two active scenario sets are the same.
@@ -812,6 +1425,63 @@ func effectiveCalls(op *Operation, overrides map[string]Override) []Call {
 	return calls
 }
 
+// spanAttrPool recycles the attribute slices walkTrace builds for each span.
+// The SDK copies attributes in SetAttributes and observers receive their own
+// copy, so a slice can be reused as soon as its span has been emitted.
+var spanAttrPool = sync.Pool{
+	New: func() any {
+		s := make([]attribute.KeyValue, 0, 16)
+		return &s
+	},
+}
+
+// getSpanAttrs returns an empty pooled slice with capacity for at least n
+// attributes.
+func getSpanAttrs(n int) *[]attribute.KeyValue {
+	p := spanAttrPool.Get().(*[]attribute.KeyValue)
+	if cap(*p) < n {
+		*p = make([]attribute.KeyValue, 0, n)
+	}
+	return p
+}
+
+// putSpanAttrs clears p so pooled slices do not pin attribute values, and
+// returns it to the pool.
+func putSpanAttrs(p *[]attribute.KeyValue) {
+	clear(*p)
+	*p = (*p)[:0]
+	spanAttrPool.Put(p)
+}
+
+// spanAttrCapacity is the number of attributes walkTrace sets on op's span.
+// opAttrs is passed separately because scenario overrides can change it.
+func spanAttrCapacity(op *Operation, opAttrs Attributes, mergedBaggage map[string]string) int {
+	n := len(op.Service.Attributes) + len(opAttrs)
+	if op.Service.Regions != nil {
+		n++
+	}
+	if op.BaggageAsAttributes {
+		n += len(mergedBaggage)
+	}
+	if op.Cache != nil {
+		n++
+	}
+	return n
+}
+
+// cacheHitAttribute records whether an operation's cache served the request.
+const cacheHitAttribute = "cache.hit"
+
+// rollCacheHit decides whether op's cache serves the current request. It
+// returns false without consuming randomness when op has no cache block or a
+// zero hit rate, so topologies without caches keep their RNG sequence.
+func (e *Engine) rollCacheHit(op *Operation) bool {
+	if op.Cache == nil || op.Cache.HitRate <= 0 {
+		return false
+	}
+	return e.Rng.Float64() < op.Cache.HitRate
+}
+
 // typedAttribute creates a KeyValue with the appropriate OTel type for the value.
 func typedAttribute(key string, value any) attribute.KeyValue {
 	switch v := value.(type) {
@@ -4,8 +4,10 @@ package synth
 
 import (
 	"context"
+	"maps"
 	"math/rand/v2"
 	"slices"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -255,6 +257,112 @@ func TestEngineScenarioOverrides(t *testing.T) {
 	assert.Greater(t, spanDuration, 500*time.Millisecond, "should use overridden duration")
 }
 
+func TestEngineScenarioErrorRateMultiply(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  svc:
+    operations:
+      op:
+        duration: 1ms
+        error_rate: 10%
+traffic:
+  rate: 10000/s
+scenarios:
+  - name: error spike
+    at: 0s
+    duration: 1h
+    override:
+      svc.op:
+        error_rate_multiply: 3
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, _, _ := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 3000
+			engine.Realtime = realtime
+
+			stats, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.Equal(t, int64(3000), stats.Spans)
+			assert.InDelta(t, 0.30, stats.ErrorRate, 0.03, "the 10%% base rate is tripled")
+		})
+	}
+}
+
+func TestEngineScenarioMatchService(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      checkout:
+        duration: 10ms
+        calls: [db.query, db.insert]
+  db:
+    operations:
+      query:
+        duration: 5ms
+      insert:
+        duration: 5ms
+traffic:
+  rate: 100/s
+scenarios:
+  - name: database degradation
+    at: 0s
+    duration: 1h
+    match:
+      - service: db
+        duration: 200ms
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 2
+			engine.Realtime = realtime
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			seen := map[string]bool{}
+			for _, span := range exporter.GetSpans() {
+				if span.Name == "checkout" {
+					continue
+				}
+				seen[span.Name] = true
+				assert.GreaterOrEqual(t, span.EndTime.Sub(span.StartTime), 200*time.Millisecond,
+					"db.%s is slowed by the service match", span.Name)
+			}
+			assert.Equal(t, map[string]bool{"query": true, "insert": true}, seen)
+		})
+	}
+}
+
 func TestEngineScenarioAttributeOverrides(t *testing.T) {
 	t.Parallel()
 
@@ -321,6 +429,60 @@ func TestEngineScenarioAttributeOverrides(t *testing.T) {
 	assert.Equal(t, "added", attrMap["extra"], "new attribute from scenario should be present")
 }
 
+func TestEnginePooledSpanAttributesAcrossOverrides(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{{
+			Name:       "svc",
+			Attributes: map[string]string{"env": "test"},
+			Operations: []OperationConfig{{
+				Name:     "op",
+				Duration: "1ms",
+				Attributes: map[string]AttributeValueConfig{
+					"status": {Value: "200"},
+				},
+			}},
+		}},
+		Traffic: TrafficConfig{Rate: "100/s"},
+	}
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	gens := map[string]AttributeGenerator{"status": &StaticValue{Value: "503"}}
+	for i := range 20 {
+		gens["extra."+strconv.Itoa(i)] = &StaticValue{Value: "x"}
+	}
+	overrides := map[string]Override{"svc.op": {Attributes: NewAttributes(gens)}}
+
+	// Alternate between a large overridden attribute set and the small base
+	// set so pooled slices are reused at both sizes.
+	for i := range 10 {
+		var ov map[string]Override
+		if i%2 == 0 {
+			ov = overrides
+		}
+		engine.walkTrace(context.Background(), engine.Topology.Roots[0], nil, time.Now(), 0, ov, nil, &Stats{}, new(int), DefaultMaxSpansPerTrace, false, false)
+	}
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 10)
+	for i, s := range spans {
+		attrs := make(map[string]string)
+		for _, attr := range s.Attributes {
+			attrs[string(attr.Key)] = attr.Value.AsString()
+		}
+		assert.Equal(t, "test", attrs["env"])
+		if i%2 == 0 {
+			assert.Len(t, s.Attributes, 24, "span %d", i)
+			assert.Equal(t, "503", attrs["status"])
+		} else {
+			assert.Len(t, s.Attributes, 4, "span %d should not carry attributes from a pooled slice", i)
+			assert.Equal(t, "200", attrs["status"])
+		}
+	}
+}
+
 func TestEngineScenarioTrafficOverride(t *testing.T) {
 	t.Parallel()
 
@@ -503,6 +665,103 @@ func TestEngineOperationAttributes(t *testing.T) {
 	assert.Equal(t, "200", attrMap["status"])
 }
 
+func TestEngineServiceDefaultAttributes(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{
+				Name: "api",
+				DefaultAttributes: map[string]AttributeValueConfig{
+					"deployment.tier": {Value: "edge"},
+					"http.route":      {Value: "/default"},
+				},
+				Operations: []OperationConfig{
+					{
+						Name:     "list",
+						Duration: "10ms",
+						Calls:    []CallConfig{{Target: "api.get"}},
+					},
+					{
+						Name:     "get",
+						Duration: "5ms",
+						Calls:    []CallConfig{{Target: "db.query"}},
+						Attributes: map[string]AttributeValueConfig{
+							"http.route": {Value: "/users/{id}"},
+						},
+					},
+				},
+			},
+			{
+				Name:       "db",
+				Operations: []OperationConfig{{Name: "query", Duration: "1ms"}},
+			},
+		},
+		Traffic: TrafficConfig{Rate: "100/s"},
+	}
+	require.NoError(t, ValidateConfig(cfg))
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	engine.walkTrace(context.Background(), engine.Topology.Roots[0], nil, time.Now(), 0, nil, nil, &Stats{}, new(int), DefaultMaxSpansPerTrace, false, false)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	routes := make(map[string]string)
+	for _, s := range spans {
+		attrs := make(map[string]string)
+		for _, attr := range s.Attributes {
+			attrs[string(attr.Key)] = attr.Value.AsString()
+		}
+		if s.Name == "query" {
+			assert.NotContains(t, attrs, "deployment.tier", "defaults apply only to their own service")
+			continue
+		}
+		assert.Equal(t, "edge", attrs["deployment.tier"], "span %s should carry the service default", s.Name)
+		routes[s.Name] = attrs["http.route"]
+	}
+	assert.Equal(t, "/default", routes["list"])
+	assert.Equal(t, "/users/{id}", routes["get"], "operation attribute should replace the default")
+}
+
+func TestEngineTypedWeightedChoice(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      get:
+        duration: 5ms
+        attributes:
+          http.response.status_code:
+            values: { 200: 95, 500: 5 }
+          cache.warm:
+            values: { true: 3, false: 1 }
+          http.route:
+            values: { "200": 1 }
+traffic:
+  rate: 10/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	engine.walkTrace(context.Background(), engine.Topology.Roots[0], nil, time.Now(), 0, nil, nil, &Stats{}, new(int), DefaultMaxSpansPerTrace, false, false)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	types := make(map[string]attribute.Type)
+	for _, attr := range spans[0].Attributes {
+		types[string(attr.Key)] = attr.Value.Type()
+	}
+	assert.Equal(t, attribute.INT64, types["http.response.status_code"], "integer keys emit int attributes")
+	assert.Equal(t, attribute.BOOL, types["cache.warm"], "boolean keys emit bool attributes")
+	assert.Equal(t, attribute.STRING, types["http.route"], "quoted keys emit string attributes")
+}
+
 func TestEngineSequentialCallStyle(t *testing.T) {
 	t.Parallel()
 
@@ -604,6 +863,108 @@ func TestEngineParallelCallStyle(t *testing.T) {
 		"parallel: both children should start at the same time")
 }
 
+func TestEngineCallJitter(t *testing.T) {
+	t.Parallel()
+
+	newConfig := func(jitter string) *Config {
+		return &Config{
+			Services: []ServiceConfig{
+				{
+					Name: "parent",
+					Operations: []OperationConfig{{
+						Name:       "entry",
+						Duration:   "10ms",
+						CallJitter: jitter,
+						Calls:      []CallConfig{{Target: "child.a"}, {Target: "child.b"}, {Target: "child.c"}},
+					}},
+				},
+				{
+					Name: "child",
+					Operations: []OperationConfig{
+						{Name: "a", Duration: "20ms"},
+						{Name: "b", Duration: "20ms"},
+						{Name: "c", Duration: "20ms"},
+					},
+				},
+			},
+			Traffic: TrafficConfig{Rate: "100/s"},
+		}
+	}
+
+	t.Run("jitter staggers siblings within the parent", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig("2ms +/- 500us")
+		require.NoError(t, ValidateConfig(cfg))
+		engine, exporter, tp := newTestEngine(t, cfg)
+
+		now := time.Now()
+		engine.walkTrace(context.Background(), engine.Topology.Roots[0], nil, now, 0, nil, nil, &Stats{}, new(int), DefaultMaxSpansPerTrace, false, false)
+		require.NoError(t, tp.ForceFlush(context.Background()))
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 4)
+		var parent tracetest.SpanStub
+		starts := map[time.Time]bool{}
+		for _, s := range spans {
+			if s.Name == "entry" {
+				parent = s
+				continue
+			}
+			starts[s.StartTime] = true
+		}
+		assert.Len(t, starts, 3, "each sibling starts at its own time")
+		callPoint := now.Add(5 * time.Millisecond)
+		for _, s := range spans {
+			if s.Name == "entry" {
+				continue
+			}
+			assert.False(t, s.StartTime.Before(callPoint), "child %s starts no earlier than the call point", s.Name)
+			assert.Less(t, s.StartTime.Sub(callPoint), 10*time.Millisecond, "child %s starts close to the call point", s.Name)
+			assert.False(t, s.EndTime.After(parent.EndTime), "child %s ends within its parent", s.Name)
+		}
+	})
+
+	t.Run("plan mirrors the jittered start times", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig("2ms +/- 500us")
+		batch, exporter, tp := newTestEngine(t, cfg)
+		batch.Rng = rand.New(rand.NewPCG(7, 0)) //nolint:gosec // deterministic seed for testing
+		planner, _, _ := newTestEngine(t, cfg)
+		planner.Rng = rand.New(rand.NewPCG(7, 0)) //nolint:gosec // deterministic seed for testing
+
+		now := time.Now()
+		batch.walkTrace(context.Background(), batch.Topology.Roots[0], nil, now, 0, nil, nil, &Stats{}, new(int), DefaultMaxSpansPerTrace, false, false)
+		require.NoError(t, tp.ForceFlush(context.Background()))
+		var plans []SpanPlan
+		planner.planTrace(planner.Topology.Roots[0], nil, -1, now, 0, nil, nil, &Stats{}, &plans, new(int), DefaultMaxSpansPerTrace, false, false)
+
+		emitted := map[string]time.Time{}
+		for _, s := range exporter.GetSpans() {
+			emitted[s.Name] = s.StartTime
+		}
+		require.Len(t, plans, 4)
+		for _, p := range plans {
+			assert.Equal(t, emitted[p.Operation], p.StartTime, "operation %s", p.Operation)
+		}
+	})
+
+	t.Run("no jitter starts siblings together", func(t *testing.T) {
+		t.Parallel()
+		engine, exporter, tp := newTestEngine(t, newConfig(""))
+
+		engine.walkTrace(context.Background(), engine.Topology.Roots[0], nil, time.Now(), 0, nil, nil, &Stats{}, new(int), DefaultMaxSpansPerTrace, false, false)
+		require.NoError(t, tp.ForceFlush(context.Background()))
+
+		starts := map[time.Time]bool{}
+		for _, s := range exporter.GetSpans() {
+			if s.Name != "entry" {
+				starts[s.StartTime] = true
+			}
+		}
+		assert.Len(t, starts, 1)
+	})
+}
+
 func TestEngineRunStats(t *testing.T) {
 	t.Parallel()
 
@@ -1271,6 +1632,77 @@ func TestEngineRetryOnError(t *testing.T) {
 	assert.Equal(t, int64(2), stats.Retries)
 }
 
+func TestEngineLinkRetries(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{
+				Name: "parent",
+				Operations: []OperationConfig{{
+					Name:     "entry",
+					Duration: "10ms",
+					Calls:    []CallConfig{{Target: "child.failing", Retries: 2, RetryBackoff: "5ms"}},
+				}},
+			},
+			{
+				Name: "child",
+				Operations: []OperationConfig{
+					{
+						Name:      "failing",
+						Duration:  "10ms",
+						ErrorRate: "100%",
+						Calls:     []CallConfig{{Target: "child.leaf"}},
+					},
+					{Name: "leaf", Duration: "2ms"},
+				},
+			},
+		},
+		Traffic: TrafficConfig{Rate: "100/s"},
+	}
+
+	for _, realtime := range []bool{false, true} {
+		for _, linkRetries := range []bool{false, true} {
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 1
+			engine.Realtime = realtime
+			engine.LinkRetries = linkRetries
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			var attempts []tracetest.SpanStub
+			for _, s := range exporter.GetSpans() {
+				switch s.Name {
+				case "failing":
+					attempts = append(attempts, s)
+				case "leaf", "entry":
+					assert.Empty(t, s.Links, "realtime=%v: only retry attempts are linked", realtime)
+				}
+			}
+			require.Len(t, attempts, 3)
+			slices.SortFunc(attempts, func(a, b tracetest.SpanStub) int {
+				return a.StartTime.Compare(b.StartTime)
+			})
+
+			if !linkRetries {
+				for _, a := range attempts {
+					assert.Empty(t, a.Links, "realtime=%v: retries are not linked by default", realtime)
+				}
+				continue
+			}
+			assert.Empty(t, attempts[0].Links, "realtime=%v: the first attempt has nothing to link to", realtime)
+			for i := 1; i < len(attempts); i++ {
+				require.Len(t, attempts[i].Links, 1, "realtime=%v attempt %d", realtime, i+1)
+				link := attempts[i].Links[0]
+				assert.Equal(t, attempts[i-1].SpanContext.SpanID(), link.SpanContext.SpanID(), "realtime=%v attempt %d", realtime, i+1)
+				assert.Contains(t, link.Attributes, attribute.Int(retryAttemptAttribute, i), "realtime=%v attempt %d", realtime, i+1)
+			}
+		}
+	}
+}
+
 func TestEngineRetrySuccess(t *testing.T) {
 	t.Parallel()
 
@@ -1371,9 +1803,76 @@ func TestEngineRetryBackoff(t *testing.T) {
 		"retry should respect backoff (gap=%v)", gap)
 }
 
-func TestEngineRetryWithTimeout(t *testing.T) {
+func TestEngineRetryBackoffMultiplier(t *testing.T) {
+	t.Parallel()
+
+	for _, jitter := range []float64{0, 0.5} {
+		t.Run("jitter="+strconv.FormatFloat(jitter, 'g', -1, 64), func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &Config{
+				Services: []ServiceConfig{
+					{
+						Name: "parent",
+						Operations: []OperationConfig{{
+							Name:     "entry",
+							Duration: "10ms",
+							Calls: []CallConfig{{
+								Target: "child.failing", Retries: 2, RetryBackoff: "10ms",
+								RetryBackoffMultiplier: 3, RetryJitter: jitter,
+							}},
+						}},
+					},
+					{
+						Name: "child",
+						Operations: []OperationConfig{{
+							Name:      "failing",
+							Duration:  "20ms",
+							ErrorRate: "100%",
+						}},
+					},
+				},
+				Traffic: TrafficConfig{Rate: "100/s"},
+			}
+
+			for _, realtime := range []bool{false, true} {
+				engine, exporter, tp := newTestEngine(t, cfg)
+				engine.Duration = time.Minute
+				engine.MaxTraces = 1
+				engine.Realtime = realtime
+				_, err := engine.Run(t.Context())
+				require.NoError(t, err)
+				require.NoError(t, tp.ForceFlush(context.Background()))
+
+				var children []tracetest.SpanStub
+				for _, s := range exporter.GetSpans() {
+					if s.Name == "failing" {
+						children = append(children, s)
+					}
+				}
+				require.Len(t, children, 3)
+				slices.SortFunc(children, func(a, b tracetest.SpanStub) int {
+					return a.StartTime.Compare(b.StartTime)
+				})
+
+				first := children[1].StartTime.Sub(children[0].EndTime)
+				second := children[2].StartTime.Sub(children[1].EndTime)
+				if jitter == 0 {
+					assert.Equal(t, 10*time.Millisecond, first, "realtime=%v", realtime)
+					assert.Equal(t, 30*time.Millisecond, second, "realtime=%v", realtime)
+				} else {
+					assert.True(t, first > 5*time.Millisecond && first <= 10*time.Millisecond, "realtime=%v first gap %v", realtime, first)
+					assert.True(t, second > 15*time.Millisecond && second <= 30*time.Millisecond, "realtime=%v second gap %v", realtime, second)
+				}
+			}
+		})
+	}
+}
+
+func TestEngineRetryGapCapped(t *testing.T) {
 	t.Parallel()
 
+	// 1s * 1000^7 overflows a time.Duration without the cap.
 	cfg := &Config{
 		Services: []ServiceConfig{
 			{
@@ -1381,35 +1880,52 @@ func TestEngineRetryWithTimeout(t *testing.T) {
 				Operations: []OperationConfig{{
 					Name:     "entry",
 					Duration: "10ms",
-					Calls:    []CallConfig{{Target: "child.slow", Timeout: "50ms", Retries: 1, RetryBackoff: "10ms"}},
+					Calls: []CallConfig{{
+						Target: "child.failing", Retries: 8, RetryBackoff: "1s",
+						RetryBackoffMultiplier: 1000,
+					}},
 				}},
 			},
 			{
 				Name: "child",
 				Operations: []OperationConfig{{
-					Name:     "slow",
-					Duration: "200ms",
+					Name:      "failing",
+					Duration:  "20ms",
+					ErrorRate: "100%",
 				}},
 			},
 		},
 		Traffic: TrafficConfig{Rate: "100/s"},
 	}
+	require.NoError(t, ValidateConfig(cfg))
 
 	engine, exporter, tp := newTestEngine(t, cfg)
-	rootOp := engine.Topology.Roots[0]
-	var stats Stats
-	engine.walkTrace(context.Background(), rootOp, nil, time.Now(), 0, nil, nil, &stats, new(int), DefaultMaxSpansPerTrace, false, false)
+	engine.Duration = time.Minute
+	engine.MaxTraces = 1
+	_, err := engine.Run(t.Context())
+	require.NoError(t, err)
 	require.NoError(t, tp.ForceFlush(context.Background()))
 
-	spans := exporter.GetSpans()
-	// parent + 2 child attempts (both time out)
-	assert.Len(t, spans, 3)
+	var children []tracetest.SpanStub
+	for _, s := range exporter.GetSpans() {
+		if s.Name == "failing" {
+			children = append(children, s)
+		}
+	}
+	require.Len(t, children, 9)
+	slices.SortFunc(children, func(a, b tracetest.SpanStub) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
 
-	assert.Equal(t, int64(2), stats.Timeouts, "each attempt should time out")
-	assert.Equal(t, int64(1), stats.Retries, "should retry once")
+	assert.Equal(t, time.Second, children[1].StartTime.Sub(children[0].EndTime))
+	assert.Equal(t, 1000*time.Second, children[2].StartTime.Sub(children[1].EndTime))
+	for i := 3; i < len(children); i++ {
+		gap := children[i].StartTime.Sub(children[i-1].EndTime)
+		assert.Equal(t, maxRetryGap, gap, "gap before retry %d", i)
+	}
 }
 
-func TestEngineRetryStats(t *testing.T) {
+func TestEngineRetryWithTimeout(t *testing.T) {
 	t.Parallel()
 
 	cfg := &Config{
@@ -1419,33 +1935,35 @@ func TestEngineRetryStats(t *testing.T) {
 				Operations: []OperationConfig{{
 					Name:     "entry",
 					Duration: "10ms",
-					Calls:    []CallConfig{{Target: "child.failing", Retries: 3, RetryBackoff: "1ms"}},
+					Calls:    []CallConfig{{Target: "child.slow", Timeout: "50ms", Retries: 1, RetryBackoff: "10ms"}},
 				}},
 			},
 			{
 				Name: "child",
 				Operations: []OperationConfig{{
-					Name:      "failing",
-					Duration:  "5ms",
-					ErrorRate: "100%",
+					Name:     "slow",
+					Duration: "200ms",
 				}},
 			},
 		},
 		Traffic: TrafficConfig{Rate: "100/s"},
 	}
 
-	engine, _, _ := newTestEngine(t, cfg)
+	engine, exporter, tp := newTestEngine(t, cfg)
 	rootOp := engine.Topology.Roots[0]
 	var stats Stats
 	engine.walkTrace(context.Background(), rootOp, nil, time.Now(), 0, nil, nil, &stats, new(int), DefaultMaxSpansPerTrace, false, false)
+	require.NoError(t, tp.ForceFlush(context.Background()))
 
-	assert.Equal(t, int64(3), stats.Retries, "should retry 3 times")
-	// 1 parent + 4 child = 5 spans, all errored (child 100%, parent cascaded)
-	assert.Equal(t, int64(5), stats.Spans)
-	assert.Equal(t, int64(5), stats.Errors)
+	spans := exporter.GetSpans()
+	// parent + 2 child attempts (both time out)
+	assert.Len(t, spans, 3)
+
+	assert.Equal(t, int64(2), stats.Timeouts, "each attempt should time out")
+	assert.Equal(t, int64(1), stats.Retries, "should retry once")
 }
 
-func TestEngineNoRetryWithoutConfig(t *testing.T) {
+func TestEngineHedgeOnSlowChild(t *testing.T) {
 	t.Parallel()
 
 	cfg := &Config{
@@ -1455,15 +1973,14 @@ func TestEngineNoRetryWithoutConfig(t *testing.T) {
 				Operations: []OperationConfig{{
 					Name:     "entry",
 					Duration: "10ms",
-					Calls:    []CallConfig{{Target: "child.failing"}},
+					Calls:    []CallConfig{{Target: "child.slow", HedgeAfter: "50ms"}},
 				}},
 			},
 			{
 				Name: "child",
 				Operations: []OperationConfig{{
-					Name:      "failing",
-					Duration:  "5ms",
-					ErrorRate: "100%",
+					Name:     "slow",
+					Duration: "200ms",
 				}},
 			},
 		},
@@ -1473,27 +1990,193 @@ func TestEngineNoRetryWithoutConfig(t *testing.T) {
 	engine, exporter, tp := newTestEngine(t, cfg)
 	rootOp := engine.Topology.Roots[0]
 	var stats Stats
-	engine.walkTrace(context.Background(), rootOp, nil, time.Now(), 0, nil, nil, &stats, new(int), DefaultMaxSpansPerTrace, false, false)
+	start := time.Now()
+	end, _ := engine.walkTrace(context.Background(), rootOp, nil, start, 0, nil, nil, &stats, new(int), DefaultMaxSpansPerTrace, false, false)
 	require.NoError(t, tp.ForceFlush(context.Background()))
 
 	spans := exporter.GetSpans()
-	// No retries configured: 1 parent + 1 child = 2
-	assert.Len(t, spans, 2)
-	assert.Equal(t, int64(0), stats.Retries)
+	// parent + original attempt + hedge
+	require.Len(t, spans, 3)
+	assert.Equal(t, int64(1), stats.Hedges)
+
+	var childStarts []time.Time
+	for _, s := range spans {
+		if s.Name == "slow" {
+			childStarts = append(childStarts, s.StartTime)
+		}
+	}
+	require.Len(t, childStarts, 2)
+	assert.Equal(t, 50*time.Millisecond, childStarts[1].Sub(childStarts[0]).Abs(), "hedge should start hedge_after into the original attempt")
+
+	// The original attempt finishes first (200ms vs 50ms+200ms), so the
+	// parent waits only for it.
+	assert.Equal(t, 210*time.Millisecond, end.Sub(start))
 }
 
-func TestEngineSpanBound(t *testing.T) {
+func TestEngineHedgeFasterAttemptWins(t *testing.T) {
 	t.Parallel()
 
-	// Deep topology: parent calls child, child calls grandchild, each with count=5
 	cfg := &Config{
 		Services: []ServiceConfig{
 			{
 				Name: "parent",
 				Operations: []OperationConfig{{
 					Name:     "entry",
-					Duration: "1ms",
-					Calls:    []CallConfig{{Target: "child.work", Count: 5}},
+					Duration: "10ms",
+					Calls:    []CallConfig{{Target: "child.jittery", HedgeAfter: "20ms"}},
+				}},
+			},
+			{
+				Name: "child",
+				Operations: []OperationConfig{{
+					Name:     "jittery",
+					Duration: "100ms +/- 80ms",
+				}},
+			},
+		},
+		Traffic: TrafficConfig{Rate: "100/s"},
+	}
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	rootOp := engine.Topology.Roots[0]
+	var stats Stats
+	for range 200 {
+		exporter.Reset()
+		start := time.Now()
+		end, _ := engine.walkTrace(context.Background(), rootOp, nil, start, 0, nil, nil, &stats, new(int), DefaultMaxSpansPerTrace, false, false)
+		require.NoError(t, tp.ForceFlush(context.Background()))
+
+		firstEnd := time.Time{}
+		for _, s := range exporter.GetSpans() {
+			if s.Name == "jittery" && (firstEnd.IsZero() || s.EndTime.Before(firstEnd)) {
+				firstEnd = s.EndTime
+			}
+		}
+		require.False(t, firstEnd.IsZero())
+		assert.Equal(t, firstEnd.Add(5*time.Millisecond), end, "parent should resume when the first attempt finishes")
+	}
+	assert.Positive(t, stats.Hedges)
+}
+
+func TestEngineNoHedgeForFastChild(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{
+				Name: "parent",
+				Operations: []OperationConfig{{
+					Name:     "entry",
+					Duration: "10ms",
+					Calls:    []CallConfig{{Target: "child.fast", HedgeAfter: "50ms"}},
+				}},
+			},
+			{
+				Name: "child",
+				Operations: []OperationConfig{{
+					Name:     "fast",
+					Duration: "10ms",
+				}},
+			},
+		},
+		Traffic: TrafficConfig{Rate: "100/s"},
+	}
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	rootOp := engine.Topology.Roots[0]
+	var stats Stats
+	engine.walkTrace(context.Background(), rootOp, nil, time.Now(), 0, nil, nil, &stats, new(int), DefaultMaxSpansPerTrace, false, false)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	assert.Len(t, exporter.GetSpans(), 2)
+	assert.Zero(t, stats.Hedges)
+}
+
+func TestEngineRetryStats(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{
+				Name: "parent",
+				Operations: []OperationConfig{{
+					Name:     "entry",
+					Duration: "10ms",
+					Calls:    []CallConfig{{Target: "child.failing", Retries: 3, RetryBackoff: "1ms"}},
+				}},
+			},
+			{
+				Name: "child",
+				Operations: []OperationConfig{{
+					Name:      "failing",
+					Duration:  "5ms",
+					ErrorRate: "100%",
+				}},
+			},
+		},
+		Traffic: TrafficConfig{Rate: "100/s"},
+	}
+
+	engine, _, _ := newTestEngine(t, cfg)
+	rootOp := engine.Topology.Roots[0]
+	var stats Stats
+	engine.walkTrace(context.Background(), rootOp, nil, time.Now(), 0, nil, nil, &stats, new(int), DefaultMaxSpansPerTrace, false, false)
+
+	assert.Equal(t, int64(3), stats.Retries, "should retry 3 times")
+	// 1 parent + 4 child = 5 spans, all errored (child 100%, parent cascaded)
+	assert.Equal(t, int64(5), stats.Spans)
+	assert.Equal(t, int64(5), stats.Errors)
+}
+
+func TestEngineNoRetryWithoutConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{
+				Name: "parent",
+				Operations: []OperationConfig{{
+					Name:     "entry",
+					Duration: "10ms",
+					Calls:    []CallConfig{{Target: "child.failing"}},
+				}},
+			},
+			{
+				Name: "child",
+				Operations: []OperationConfig{{
+					Name:      "failing",
+					Duration:  "5ms",
+					ErrorRate: "100%",
+				}},
+			},
+		},
+		Traffic: TrafficConfig{Rate: "100/s"},
+	}
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	rootOp := engine.Topology.Roots[0]
+	var stats Stats
+	engine.walkTrace(context.Background(), rootOp, nil, time.Now(), 0, nil, nil, &stats, new(int), DefaultMaxSpansPerTrace, false, false)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	spans := exporter.GetSpans()
+	// No retries configured: 1 parent + 1 child = 2
+	assert.Len(t, spans, 2)
+	assert.Equal(t, int64(0), stats.Retries)
+}
+
+func TestEngineSpanBound(t *testing.T) {
+	t.Parallel()
+
+	// Deep topology: parent calls child, child calls grandchild, each with count=5
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{
+				Name: "parent",
+				Operations: []OperationConfig{{
+					Name:     "entry",
+					Duration: "1ms",
+					Calls:    []CallConfig{{Target: "child.work", Count: 5}},
 				}},
 			},
 			{
@@ -2948,6 +3631,49 @@ func TestEngineRunRealtimeCancellation(t *testing.T) {
 	assert.Less(t, stats.ElapsedMs, int64(5000), "should not run for the full duration")
 }
 
+func TestEngineRunRealtimePacing(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{{
+			Name: "svc",
+			Operations: []OperationConfig{{
+				Name:     "op",
+				Duration: "1ms",
+			}},
+		}},
+		Traffic: TrafficConfig{Rate: "100/s"},
+	}
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	engine.Realtime = true
+	engine.Duration = 500 * time.Millisecond
+
+	stats, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	// 500ms at 100/s is 50 traces; allow slack for scheduler jitter.
+	assert.InDelta(t, 50, stats.Traces, 10)
+
+	starts := make([]time.Time, 0, len(exporter.GetSpans()))
+	for _, s := range exporter.GetSpans() {
+		starts = append(starts, s.StartTime)
+	}
+	slices.SortFunc(starts, func(a, b time.Time) int { return a.Compare(b) })
+	require.NotEmpty(t, starts)
+
+	// Paced emission spreads traces across the run instead of bursting them.
+	assert.Greater(t, starts[len(starts)-1].Sub(starts[0]), 400*time.Millisecond)
+	var closePairs int
+	for i := 1; i < len(starts); i++ {
+		if starts[i].Sub(starts[i-1]) < 2*time.Millisecond {
+			closePairs++
+		}
+	}
+	assert.Less(t, closePairs, len(starts)/4, "traces should be spaced about 10ms apart")
+}
+
 func TestEngineSpanLinks(t *testing.T) {
 	t.Parallel()
 
@@ -3197,3 +3923,2158 @@ func TestSeededRunsFullyDeterministic(t *testing.T) {
 	second := runOnce()
 	require.Equal(t, first, second, "seeded runs must produce identical spans, including attribute values")
 }
+
+func TestEngineCacheHitSkipsCalls(t *testing.T) {
+	t.Parallel()
+
+	makeConfig := func(hitRate float64) *Config {
+		return &Config{
+			Services: []ServiceConfig{
+				{
+					Name: "api",
+					Operations: []OperationConfig{{
+						Name:     "get",
+						Duration: "10ms",
+						Calls:    []CallConfig{{Target: "db.query"}, {Target: "audit.log"}},
+						Cache:    &CacheConfig{HitRate: hitRate, SkipCalls: []string{"db.query"}},
+					}},
+				},
+				{
+					Name:       "db",
+					Operations: []OperationConfig{{Name: "query", Duration: "20ms"}},
+				},
+				{
+					Name:       "audit",
+					Operations: []OperationConfig{{Name: "log", Duration: "1ms"}},
+				},
+			},
+			Traffic: TrafficConfig{Rate: "100/s"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		hitRate float64
+		wantHit bool
+	}{
+		{name: "always hit skips call", hitRate: 1.0, wantHit: true},
+		{name: "never hit makes call", hitRate: 0.0, wantHit: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := makeConfig(tt.hitRate)
+			require.NoError(t, ValidateConfig(cfg))
+			engine, exporter, tp := newTestEngine(t, cfg)
+			rootOp := engine.Topology.Services["api"].Operations["get"]
+
+			for range 50 {
+				exporter.Reset()
+				engine.walkTrace(context.Background(), rootOp, nil, time.Now(), 0, nil, nil, &Stats{}, new(int), DefaultMaxSpansPerTrace, false, false)
+				require.NoError(t, tp.ForceFlush(context.Background()))
+
+				names := make(map[string]bool)
+				var root tracetest.SpanStub
+				for _, s := range exporter.GetSpans() {
+					names[s.Name] = true
+					if s.Name == "get" {
+						root = s
+					}
+				}
+				assert.Equal(t, !tt.wantHit, names["query"], "skipped call presence")
+				assert.True(t, names["log"], "calls not listed in skip_calls are always made")
+				assert.Contains(t, root.Attributes, attribute.Bool(cacheHitAttribute, tt.wantHit))
+			}
+		})
+	}
+}
+
+func TestEngineSampleRatio(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{
+				Name: "gateway",
+				Operations: []OperationConfig{{
+					Name:     "GET /users",
+					Duration: "1ms",
+					Calls:    []CallConfig{{Target: "backend.list"}},
+				}},
+			},
+			{
+				Name:       "backend",
+				Operations: []OperationConfig{{Name: "list", Duration: "1ms"}},
+			},
+		},
+		Traffic: TrafficConfig{Rate: "10000/s"},
+	}
+
+	const (
+		traces = 1000
+		ratio  = 0.3
+	)
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = traces
+			engine.SampleRatio = ratio
+			engine.Realtime = realtime
+
+			stats, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			assert.Equal(t, int64(traces), stats.Traces)
+			assert.InDelta(t, (1-ratio)*traces, float64(stats.Sampled), 0.1*traces)
+
+			spans := exporter.GetSpans()
+			kept := stats.Traces - stats.Sampled
+			assert.Len(t, spans, int(2*kept), "only sampled-in traces are exported")
+
+			perTrace := make(map[trace.TraceID]int)
+			for _, s := range spans {
+				perTrace[s.SpanContext.TraceID()]++
+			}
+			for tid, n := range perTrace {
+				assert.Equal(t, 2, n, "trace %s must be exported whole", tid)
+			}
+		})
+	}
+}
+
+func TestEngineWarmup(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{
+				Name: "gateway",
+				Operations: []OperationConfig{{
+					Name:     "GET /users",
+					Duration: "1ms",
+					Calls:    []CallConfig{{Target: "backend.list"}},
+				}},
+			},
+			{
+				Name:       "backend",
+				Operations: []OperationConfig{{Name: "list", Duration: "1ms"}},
+			},
+		},
+		Traffic: TrafficConfig{Rate: "1000/s"},
+	}
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = 300 * time.Millisecond
+			engine.Warmup = 150 * time.Millisecond
+			engine.Realtime = realtime
+
+			stats, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			emitted := make(map[trace.TraceID]bool)
+			for _, s := range exporter.GetSpans() {
+				emitted[s.SpanContext.TraceID()] = true
+			}
+
+			assert.Positive(t, stats.Traces)
+			assert.Less(t, stats.Traces, int64(len(emitted)), "warmup traces are emitted but not counted")
+			assert.Equal(t, 2*stats.Traces, stats.Spans, "only post-warmup spans are counted")
+		})
+	}
+}
+
+func TestEngineProgress(t *testing.T) {
+	t.Parallel()
+
+	cfg := validBaseConfig()
+	cfg.Traffic.Rate = "1000/s"
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, _, _ := newTestEngine(t, cfg)
+			engine.Duration = 200 * time.Millisecond
+			engine.Realtime = realtime
+
+			done := make(chan struct{})
+			var last Stats
+			go func() {
+				defer close(done)
+				for {
+					select {
+					case <-t.Context().Done():
+						return
+					default:
+					}
+					p := engine.Progress()
+					assert.GreaterOrEqual(t, p.Traces, last.Traces, "progress never goes backwards")
+					last = p
+					if p.Traces > 0 {
+						return
+					}
+					time.Sleep(time.Millisecond)
+				}
+			}()
+
+			stats, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			<-done
+
+			p := engine.Progress()
+			assert.Positive(t, last.Traces, "progress is visible while running")
+			assert.Equal(t, stats.Traces, p.Traces)
+			assert.Equal(t, stats.Spans, p.Spans)
+			assert.Equal(t, stats.Errors, p.Errors)
+		})
+	}
+}
+
+func TestEngineScenarioTraces(t *testing.T) {
+	t.Parallel()
+
+	cfg := validBaseConfig()
+	cfg.Traffic.Rate = "1000/s"
+	cfg.Scenarios = []ScenarioConfig{
+		{Name: "early", At: "0s", Duration: "100ms"},
+		{Name: "never", At: "1h", Duration: "1m"},
+	}
+
+	engine, _, _ := newTestEngine(t, cfg)
+	engine.Duration = 300 * time.Millisecond
+
+	stats, err := engine.Run(t.Context())
+	require.NoError(t, err)
+
+	require.Contains(t, stats.ScenarioTraces, "never")
+	assert.Zero(t, stats.ScenarioTraces["never"])
+	assert.Positive(t, stats.ScenarioTraces["early"])
+	assert.Less(t, stats.ScenarioTraces["early"], stats.Traces)
+}
+
+func TestEngineCollectPerOp(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{
+				Name: "gateway",
+				Operations: []OperationConfig{{
+					Name:     "GET /users",
+					Duration: "10ms",
+					Calls:    []CallConfig{{Target: "backend.list", Timeout: "30ms"}},
+				}},
+			},
+			{
+				Name:       "backend",
+				Operations: []OperationConfig{{Name: "list", Duration: "20ms +/- 10ms", ErrorRate: "50%"}},
+			},
+		},
+		Traffic: TrafficConfig{Rate: "10000/s"},
+	}
+
+	const traces = 2000
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, _, _ := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = traces
+			engine.CollectPerOp = true
+			engine.Realtime = realtime
+
+			stats, err := engine.Run(t.Context())
+			require.NoError(t, err)
+
+			require.Len(t, stats.ByOperation, 2)
+			backend := stats.ByOperation["backend.list"]
+			assert.Equal(t, int64(traces), backend.Count)
+			assert.InDelta(t, 0.5, float64(backend.Errors)/float64(backend.Count), 0.05)
+			assert.Positive(t, backend.Timeouts, "calls slower than 30ms time out")
+			assert.Equal(t, stats.Timeouts, backend.Timeouts)
+			assert.Zero(t, stats.ByOperation["gateway.GET /users"].Timeouts)
+			assert.InDelta(t, 20, backend.P50Ms, 2)
+			assert.LessOrEqual(t, backend.P50Ms, backend.P90Ms)
+			assert.LessOrEqual(t, backend.P90Ms, backend.P99Ms)
+		})
+	}
+}
+
+func TestEngineCollectPerOpDisabled(t *testing.T) {
+	t.Parallel()
+
+	engine, _, _ := newTestEngine(t, validBaseConfig())
+	engine.Duration = time.Minute
+	engine.MaxTraces = 10
+
+	stats, err := engine.Run(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, stats.ByOperation)
+}
+
+func TestEngineErrorTypes(t *testing.T) {
+	t.Parallel()
+
+	weight := 3
+	cfg := &Config{
+		Services: []ServiceConfig{{
+			Name: "api",
+			Operations: []OperationConfig{{
+				Name:      "handle",
+				Duration:  "1ms",
+				ErrorRate: "50%",
+				ErrorTypes: []ErrorTypeConfig{
+					{
+						Name:       "timeout",
+						Weight:     &weight,
+						Message:    "upstream timed out",
+						Attributes: map[string]AttributeValueConfig{"error.type": {Value: "timeout"}},
+					},
+					{
+						Name:       "unavailable",
+						Attributes: map[string]AttributeValueConfig{"error.type": {Value: "503"}},
+					},
+				},
+			}},
+		}},
+		Traffic: TrafficConfig{Rate: "10000/s"},
+	}
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 2000
+			engine.Realtime = realtime
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			counts := make(map[string]int)
+			errored := 0
+			for _, s := range exporter.GetSpans() {
+				errType := ""
+				for _, attr := range s.Attributes {
+					if attr.Key == "error.type" {
+						errType = attr.Value.Emit()
+					}
+				}
+				if s.Status.Code != codes.Error {
+					assert.Empty(t, errType, "successful spans carry no error type attributes")
+					continue
+				}
+				errored++
+				counts[errType]++
+				switch errType {
+				case "timeout":
+					assert.Equal(t, "upstream timed out", s.Status.Description)
+				case "503":
+					assert.Equal(t, "unavailable", s.Status.Description, "message defaults to the type name")
+				default:
+					t.Fatalf("errored span has unexpected error.type %q", errType)
+				}
+			}
+			require.Positive(t, errored)
+			assert.InDelta(t, 0.75, float64(counts["timeout"])/float64(errored), 0.05)
+		})
+	}
+}
+
+func TestEngineErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{
+				Name: "gateway",
+				Operations: []OperationConfig{{
+					Name:     "checkout",
+					Duration: "1ms",
+					Calls:    []CallConfig{{Target: "payments.charge"}},
+				}},
+			},
+			{
+				Name: "payments",
+				Operations: []OperationConfig{{
+					Name:         "charge",
+					Duration:     "1ms",
+					ErrorRate:    "100%",
+					ErrorMessage: "card declined for {user.tier} user in {service.name}",
+					Attributes:   map[string]AttributeValueConfig{"user.tier": {Value: "gold"}},
+				}},
+			},
+		},
+		Traffic: TrafficConfig{Rate: "10000/s"},
+	}
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 1
+			engine.Realtime = realtime
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 2)
+			status := make(map[string]sdktrace.Status)
+			for _, s := range spans {
+				status[s.Name] = s.Status
+			}
+			assert.Equal(t, sdktrace.Status{Code: codes.Error, Description: "card declined for gold user in payments"}, status["charge"])
+			assert.Equal(t, sdktrace.Status{Code: codes.Error, Description: "call to payments.charge failed"}, status["checkout"],
+				"a cascaded error names the failing call")
+		})
+	}
+}
+
+func TestEngineCallErrorRate(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{
+				Name: "web",
+				Operations: []OperationConfig{{
+					Name:     "browse",
+					Duration: "1ms",
+					Calls:    []CallConfig{{Target: "db.query", ErrorRate: "100%"}},
+				}},
+			},
+			{
+				Name: "app",
+				Operations: []OperationConfig{{
+					Name:     "sync",
+					Duration: "1ms",
+					Calls:    []CallConfig{{Target: "db.query"}},
+				}},
+			},
+			{
+				Name: "db",
+				Operations: []OperationConfig{{
+					Name:     "query",
+					Duration: "1ms",
+				}},
+			},
+		},
+		Traffic: TrafficConfig{Rate: "10000/s"},
+	}
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 50
+			engine.Realtime = realtime
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			spans := exporter.GetSpans()
+			names := make(map[trace.SpanID]string, len(spans))
+			for _, s := range spans {
+				names[s.SpanContext.SpanID()] = s.Name
+			}
+			queries := make(map[string]int)
+			for _, s := range spans {
+				if s.Name != "query" {
+					continue
+				}
+				caller := names[s.Parent.SpanID()]
+				queries[caller]++
+				if caller == "browse" {
+					assert.Equal(t, codes.Error, s.Status.Code, "queries from web.browse always fail")
+				} else {
+					assert.NotEqual(t, codes.Error, s.Status.Code, "queries from app.sync use the callee's own error rate")
+				}
+			}
+			assert.Positive(t, queries["browse"])
+			assert.Positive(t, queries["sync"])
+		})
+	}
+}
+
+func TestEngineSampleRatioDefaultEmitsAll(t *testing.T) {
+	t.Parallel()
+
+	cfg := validBaseConfig()
+	cfg.Traffic.Rate = "10000/s"
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	engine.Duration = time.Minute
+	engine.MaxTraces = 100
+
+	stats, err := engine.Run(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	assert.Zero(t, stats.Sampled)
+	assert.Len(t, exporter.GetSpans(), 100)
+}
+
+func TestEngineTraceAttributes(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+trace_attributes:
+  tenant.id:
+    sequence: "tenant-{n}"
+services:
+  gateway:
+    operations:
+      checkout:
+        duration: 1ms
+        calls: [payments.charge, inventory.reserve]
+  payments:
+    operations:
+      charge:
+        duration: 1ms
+        calls: [ledger.record]
+  inventory:
+    operations:
+      reserve:
+        duration: 1ms
+  ledger:
+    operations:
+      record:
+        duration: 1ms
+traffic:
+  rate: 1000/s
+scenarios:
+  - name: promo
+    at: +0s
+    duration: 1h
+    trace_attributes:
+      campaign:
+        value: spring
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 3
+			engine.Realtime = realtime
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			tenants := map[trace.TraceID]map[string]bool{}
+			for _, s := range exporter.GetSpans() {
+				attrs := map[attribute.Key]string{}
+				for _, kv := range s.Attributes {
+					attrs[kv.Key] = kv.Value.Emit()
+				}
+				assert.Equal(t, "spring", attrs["campaign"], "scenario trace attributes are added")
+				traceID := s.SpanContext.TraceID()
+				if tenants[traceID] == nil {
+					tenants[traceID] = map[string]bool{}
+				}
+				tenants[traceID][attrs["tenant.id"]] = true
+			}
+
+			require.Len(t, tenants, 3)
+			seen := map[string]bool{}
+			for traceID, values := range tenants {
+				require.Len(t, values, 1, "every span in trace %s shares one tenant.id", traceID)
+				for v := range values {
+					assert.Regexp(t, `^tenant-\d+$`, v)
+					seen[v] = true
+				}
+			}
+			assert.Len(t, seen, 3, "each trace generates its own tenant.id")
+		})
+	}
+}
+
+func TestEngineCountDistribution(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      list:
+        duration: 1ms
+        calls:
+          - target: db.fetch
+            count_distribution: poisson(mean=3)
+  db:
+    operations:
+      fetch:
+        duration: 1ms
+traffic:
+  rate: 10000/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 2000
+			engine.Realtime = realtime
+
+			stats, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			bound, _ := MaxSpans(engine.Topology)
+			perTrace := map[trace.TraceID]int{}
+			for _, span := range exporter.GetSpans() {
+				perTrace[span.SpanContext.TraceID()]++
+			}
+			for _, n := range perTrace {
+				require.LessOrEqual(t, n, bound, "a trace never exceeds the static span bound")
+			}
+			children := float64(stats.Spans-stats.Traces) / float64(stats.Traces)
+			assert.InDelta(t, 3.0, children, 0.15, "the call fires poisson(mean=3) times on average")
+		})
+	}
+}
+
+func TestEngineProbabilityDecay(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  a:
+    operations:
+      root:
+        duration: 1ms
+        calls:
+          - target: b.first
+            probability: {base: 0.8, decay: 0.5}
+  b:
+    operations:
+      first:
+        duration: 1ms
+        calls:
+          - target: c.second
+            probability: {base: 0.8, decay: 0.5}
+  c:
+    operations:
+      second:
+        duration: 1ms
+        calls:
+          - target: d.third
+            probability: {base: 0.8, decay: 0.5}
+  d:
+    operations:
+      third:
+        duration: 1ms
+traffic:
+  rate: 10000/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 5000
+			engine.Realtime = realtime
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			spans := map[string]float64{}
+			for _, span := range exporter.GetSpans() {
+				spans[span.Name]++
+			}
+			fromRoot := spans["first"] / spans["root"]
+			fromDepth1 := spans["second"] / spans["first"]
+			fromDepth2 := spans["third"] / spans["second"]
+			assert.InDelta(t, 0.8, fromRoot, 0.03, "depth 0 fires at the base probability")
+			assert.InDelta(t, 0.4, fromDepth1, 0.04, "depth 1 fires at base*decay")
+			assert.InDelta(t, 0.2, fromDepth2, 0.05, "depth 2 fires at base*decay^2")
+			assert.Greater(t, fromRoot, fromDepth1)
+			assert.Greater(t, fromDepth1, fromDepth2)
+		})
+	}
+}
+
+func TestEngineOneOf(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      get:
+        duration: 1ms
+        calls:
+          - auth.check
+        one_of:
+          - target: cache.get
+            weight: 3
+          - target: db.query
+            weight: 1
+  auth:
+    operations:
+      check:
+        duration: 1ms
+  cache:
+    operations:
+      get:
+        duration: 1ms
+  db:
+    operations:
+      query:
+        duration: 1ms
+traffic:
+  rate: 10000/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 2000
+			engine.Realtime = realtime
+
+			stats, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			grouped := map[trace.TraceID]int{}
+			cacheHits := 0
+			for _, span := range exporter.GetSpans() {
+				switch span.Name {
+				case "get":
+					if span.Parent.IsValid() {
+						grouped[span.SpanContext.TraceID()]++
+						cacheHits++
+					}
+				case "query":
+					grouped[span.SpanContext.TraceID()]++
+				}
+			}
+			require.Len(t, grouped, int(stats.Traces))
+			for id, n := range grouped {
+				require.Equal(t, 1, n, "trace %s has exactly one one_of call", id)
+			}
+			assert.InDelta(t, 0.75, float64(cacheHits)/float64(stats.Traces), 0.04, "cache.get is chosen 3 times in 4")
+		})
+	}
+}
+
+func TestEngineGRPCStatusCodes(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      timeout:
+        duration: 1ms
+        calls:
+          - target: slow.op
+            timeout: 10ms
+      fails:
+        duration: 1ms
+        error_rate: 100%
+      typed:
+        duration: 1ms
+        error_rate: 100%
+        error_types:
+          - name: unimplemented
+            attributes:
+              rpc.grpc.status_code:
+                value: 12
+      explicit:
+        duration: 1ms
+        error_rate: 100%
+        attributes:
+          rpc.grpc.status_code:
+            value: 7
+      plain:
+        duration: 1ms
+        error_rate: 100%
+  slow:
+    operations:
+      op:
+        duration: 50ms
+traffic:
+  rate: 10000/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			for _, opName := range []string{"timeout", "fails", "typed", "explicit"} {
+				engine.Topology.Services["api"].Operations[opName].Domains = []string{"rpc"}
+			}
+			engine.Duration = time.Minute
+			engine.MaxTraces = 400
+			engine.Realtime = realtime
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			byOp := map[string]map[int64]int{}
+			for _, span := range exporter.GetSpans() {
+				if span.Parent.IsValid() {
+					continue
+				}
+				require.Equal(t, codes.Error, span.Status.Code, span.Name)
+				if byOp[span.Name] == nil {
+					byOp[span.Name] = map[int64]int{}
+				}
+				for _, kv := range span.Attributes {
+					if kv.Key == "rpc.grpc.status_code" {
+						byOp[span.Name][kv.Value.AsInt64()]++
+					}
+				}
+			}
+			require.Len(t, byOp, 5)
+			assert.Len(t, byOp["timeout"], 1, "timeouts are always DEADLINE_EXCEEDED")
+			assert.Positive(t, byOp["timeout"][4])
+			assert.Len(t, byOp["typed"], 1, "an error type's code is kept")
+			assert.Positive(t, byOp["typed"][12])
+			assert.Equal(t, []int64{7}, slices.Collect(maps.Keys(byOp["explicit"])), "a configured code is kept")
+			assert.Positive(t, byOp["fails"][14], "UNAVAILABLE is the most common drawn code")
+			assert.NotContains(t, byOp["fails"], int64(0))
+			assert.Empty(t, byOp["plain"], "only rpc operations get a gRPC status code")
+		})
+	}
+}
+
+func TestEngineHTTPStatusCodes(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  web:
+    operations:
+      ok:
+        duration: 1ms
+      timeout:
+        duration: 1ms
+        calls:
+          - target: slow.op
+            timeout: 10ms
+      fails:
+        duration: 1ms
+        error_rate: 100%
+      explicit:
+        duration: 1ms
+        error_rate: 100%
+        attributes:
+          http.response.status_code:
+            value: 429
+  slow:
+    operations:
+      op:
+        duration: 50ms
+traffic:
+  rate: 10000/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			for _, op := range engine.Topology.Services["web"].Operations {
+				op.Domains = []string{"http"}
+			}
+			engine.Duration = time.Minute
+			engine.MaxTraces = 400
+			engine.Realtime = realtime
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			byOp := map[string]map[int64]int{}
+			for _, span := range exporter.GetSpans() {
+				if byOp[span.Name] == nil {
+					byOp[span.Name] = map[int64]int{}
+				}
+				for _, kv := range span.Attributes {
+					if kv.Key == "http.response.status_code" {
+						byOp[span.Name][kv.Value.AsInt64()]++
+					}
+				}
+			}
+			require.Len(t, byOp, 5)
+			assert.Equal(t, []int64{200}, slices.Collect(maps.Keys(byOp["ok"])), "successful spans are 200")
+			assert.Equal(t, []int64{504}, slices.Collect(maps.Keys(byOp["timeout"])), "timed-out spans are 504")
+			assert.Equal(t, []int64{429}, slices.Collect(maps.Keys(byOp["explicit"])), "a configured code is kept")
+			for code := range byOp["fails"] {
+				assert.Contains(t, []int64{500, 502, 503}, code)
+			}
+			assert.Positive(t, byOp["fails"][500])
+			assert.Empty(t, byOp["op"], "operations outside the http domain get no derived code")
+		})
+	}
+}
+
+func TestEngineTraceState(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+tracestate:
+  vendor: shared
+  routing: default
+services:
+  gateway:
+    tracestate:
+      routing: canary
+    operations:
+      request:
+        duration: 5ms
+        calls:
+          - backend.work
+  backend:
+    operations:
+      work:
+        duration: 1ms
+traffic:
+  rate: 10000/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 3
+			engine.Realtime = realtime
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 6)
+			for _, span := range spans {
+				ts := span.SpanContext.TraceState()
+				assert.Equal(t, "routing=canary,vendor=shared", ts.String(), span.Name)
+				if span.Name == "request" {
+					assert.False(t, span.Parent.IsValid(), "the root span keeps no parent")
+				}
+			}
+		})
+	}
+}
+
+func TestEngineMaxTraceDuration(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  a:
+    operations:
+      entry:
+        duration: 100ms
+        calls: [b.step]
+  b:
+    operations:
+      step:
+        duration: 100ms
+        calls: [c.step]
+  c:
+    operations:
+      step:
+        duration: 100ms
+        calls: [d.step]
+  d:
+    operations:
+      step:
+        duration: 100ms
+traffic:
+  rate: 1000/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	const maxDuration = 150 * time.Millisecond
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 2
+			engine.Realtime = realtime
+			engine.MaxTraceDuration = maxDuration
+
+			stats, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+			assert.Equal(t, int64(2), stats.TraceDurationCapped)
+
+			spans := exporter.GetSpans()
+			roots := map[trace.TraceID]time.Time{}
+			for _, s := range spans {
+				if !s.Parent.IsValid() {
+					roots[s.SpanContext.TraceID()] = s.StartTime
+				}
+			}
+			require.Len(t, roots, 2)
+			truncated := 0
+			for _, s := range spans {
+				rootStart := roots[s.SpanContext.TraceID()]
+				assert.LessOrEqual(t, s.EndTime.Sub(rootStart), maxDuration, "span %s fits under the cap", s.Name)
+				for _, kv := range s.Attributes {
+					if kv.Key == "synth.service" {
+						assert.NotEqual(t, "d", kv.Value.AsString(), "the call pending at the cap is cut off")
+					}
+				}
+				if s.Status.Description == traceDeadlineMessage {
+					truncated++
+				}
+			}
+			assert.Len(t, spans, 6, "each trace keeps entry, b.step, and c.step")
+			assert.Equal(t, 6, truncated, "every span still running at the cap ends with a timeout error")
+		})
+	}
+
+	t.Run("no cap", func(t *testing.T) {
+		t.Parallel()
+
+		engine, exporter, tp := newTestEngine(t, cfg)
+		engine.Duration = time.Minute
+		engine.MaxTraces = 1
+
+		stats, err := engine.Run(t.Context())
+		require.NoError(t, err)
+		require.NoError(t, tp.ForceFlush(context.Background()))
+		assert.Zero(t, stats.TraceDurationCapped)
+		assert.Len(t, exporter.GetSpans(), 4)
+	})
+}
+
+func TestEngineUntilScenariosEnd(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 1ms
+traffic:
+  rate: 100/s
+scenarios:
+  - name: incident
+    at: +50ms
+    duration: 150ms
+    override:
+      gateway.request:
+        error_rate: 100%
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, _, _ := newTestEngine(t, cfg)
+			engine.Duration = 10 * time.Second
+			engine.Realtime = realtime
+			engine.UntilScenariosEnd = true
+
+			start := time.Now()
+			stats, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			elapsed := time.Since(start)
+
+			assert.GreaterOrEqual(t, elapsed, 200*time.Millisecond, "the run covers the whole scenario window")
+			assert.Less(t, elapsed, 2*time.Second, "the run stops near the scenario's end, not after --duration")
+			assert.Positive(t, stats.ScenarioTraces["incident"])
+		})
+	}
+}
+
+func TestEngineSlowStatus(t *testing.T) {
+	t.Parallel()
+
+	for _, status := range []string{slowStatusAttribute, slowStatusUnset, slowStatusError} {
+		cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 5ms
+        calls:
+          - backend.query
+  backend:
+    operations:
+      query:
+        duration: 50ms
+        slow_threshold: 20ms
+        slow_status: ` + status + `
+traffic:
+  rate: 10000/s
+`))
+		require.NoError(t, err)
+		require.NoError(t, ValidateConfig(cfg))
+
+		for _, realtime := range []bool{false, true} {
+			name := status + "/batch"
+			if realtime {
+				name = status + "/realtime"
+			}
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				engine, exporter, tp := newTestEngine(t, cfg)
+				engine.Duration = time.Minute
+				engine.MaxTraces = 2
+				engine.Realtime = realtime
+
+				stats, err := engine.Run(t.Context())
+				require.NoError(t, err)
+				require.NoError(t, tp.ForceFlush(context.Background()))
+
+				spans := exporter.GetSpans()
+				require.Len(t, spans, 4)
+				for _, s := range spans {
+					slowAttr := false
+					for _, kv := range s.Attributes {
+						if kv.Key == "synth.slow" {
+							slowAttr = kv.Value.AsBool()
+						}
+					}
+					if s.Name != "query" {
+						assert.False(t, slowAttr)
+						continue
+					}
+					assert.Equal(t, status == slowStatusAttribute, slowAttr, "synth.slow attribute")
+					if status == slowStatusError {
+						assert.Equal(t, codes.Error, s.Status.Code)
+						assert.Equal(t, "slow operation: 50ms exceeded slow_threshold 20ms", s.Status.Description)
+					} else {
+						assert.Equal(t, codes.Unset, s.Status.Code, "a slow span is not an error unless slow_status is error")
+					}
+				}
+				if status == slowStatusError {
+					assert.Equal(t, int64(4), stats.Errors, "slow errors cascade to the caller")
+				} else {
+					assert.Zero(t, stats.Errors)
+				}
+			})
+		}
+	}
+}
+
+func TestEngineRegions(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    regions:
+      us-east-1: 3
+      eu-west-1: 1
+    operations:
+      checkout:
+        duration: 1ms
+        calls:
+          - target: payments.charge
+            count: 3
+  payments:
+    regions:
+      us-east-1: 1
+      eu-west-1: 1
+    operations:
+      charge:
+        duration: 1ms
+        calls: [ledger.record]
+  ledger:
+    operations:
+      record:
+        duration: 1ms
+traffic:
+  rate: 10000/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			const traces = 2000
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = traces
+			engine.Realtime = realtime
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			regions := map[string]map[trace.TraceID]map[string]bool{"gateway": {}, "payments": {}}
+			for _, s := range exporter.GetSpans() {
+				svc, region := "", ""
+				for _, kv := range s.Attributes {
+					switch kv.Key {
+					case "synth.service":
+						svc = kv.Value.AsString()
+					case regionAttribute:
+						region = kv.Value.AsString()
+					}
+				}
+				if svc == "ledger" {
+					assert.Empty(t, region, "services without regions carry no cloud.region")
+					continue
+				}
+				traceID := s.SpanContext.TraceID()
+				if regions[svc][traceID] == nil {
+					regions[svc][traceID] = map[string]bool{}
+				}
+				regions[svc][traceID][region] = true
+			}
+
+			for svc, byTrace := range regions {
+				require.Len(t, byTrace, traces, "every trace has %s spans", svc)
+				for traceID, values := range byTrace {
+					require.Len(t, values, 1, "every %s span in trace %s shares one region", svc, traceID)
+				}
+			}
+
+			east := 0
+			for _, values := range regions["gateway"] {
+				if values["us-east-1"] {
+					east++
+				}
+			}
+			assert.InDelta(t, 0.75, float64(east)/traces, 0.05, "gateway regions follow their 3:1 weights")
+		})
+	}
+}
+
+func TestEngineSpikeScenario(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      handle:
+        duration: 1ms
+traffic:
+  rate: 200/s
+scenarios:
+  - name: rush
+    at: +300ms
+    spike:
+      multiplier: 10
+      duration: 300ms
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	engine.Duration = 900 * time.Millisecond
+
+	start := time.Now()
+	_, err = engine.Run(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	// Count traces in equal windows clear of the spike's edges.
+	var before, during, after int
+	for _, s := range exporter.GetSpans() {
+		switch offset := s.StartTime.Sub(start); {
+		case offset >= 50*time.Millisecond && offset < 250*time.Millisecond:
+			before++
+		case offset >= 350*time.Millisecond && offset < 550*time.Millisecond:
+			during++
+		case offset >= 650*time.Millisecond && offset < 850*time.Millisecond:
+			after++
+		}
+	}
+	require.Positive(t, before)
+	assert.Greater(t, during, 4*before, "traffic jumps during the spike")
+	assert.Less(t, after, 2*before, "traffic returns to baseline after the spike")
+	assert.Less(t, after, during/4, "traffic returns to baseline after the spike")
+}
+
+func TestEngineErrorRateAtPeak(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      handle:
+        duration: 1ms
+        error_rate: 10%
+        error_rate_at_peak: 5
+traffic:
+  rate: 1000/s
+  pattern: diurnal
+  period: 400ms
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	engine.Duration = 400 * time.Millisecond
+
+	start := time.Now()
+	_, err = engine.Run(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	// The diurnal period starts at its trough and peaks halfway through.
+	var trough, troughErrs, peak, peakErrs int
+	for _, s := range exporter.GetSpans() {
+		isErr := 0
+		if s.Status.Code == codes.Error {
+			isErr = 1
+		}
+		switch offset := s.StartTime.Sub(start); {
+		case offset < 60*time.Millisecond || offset >= 340*time.Millisecond:
+			trough++
+			troughErrs += isErr
+		case offset >= 140*time.Millisecond && offset < 260*time.Millisecond:
+			peak++
+			peakErrs += isErr
+		}
+	}
+	require.Positive(t, trough)
+	require.Positive(t, peak)
+	troughRate := float64(troughErrs) / float64(trough)
+	peakRate := float64(peakErrs) / float64(peak)
+	assert.Greater(t, peakRate, 2*troughRate, "errors rise with traffic: trough %.2f, peak %.2f", troughRate, peakRate)
+}
+
+func TestEngineRootTraffic(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      request:
+        duration: 1ms
+      health:
+        duration: 1ms
+        traffic:
+          rate: 20/s
+traffic:
+  rate: 200/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Realtime = realtime
+			engine.Duration = 500 * time.Millisecond
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			counts := make(map[string]int)
+			for _, s := range exporter.GetSpans() {
+				counts[s.Name]++
+			}
+			// 500ms at 200/s and 20/s; allow slack for scheduler jitter.
+			assert.InDelta(t, 100, counts["request"], 25, "request follows the topology's traffic")
+			assert.InDelta(t, 10, counts["health"], 3, "health follows its own traffic")
+		})
+	}
+}
+
+func TestEngineSpanName(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      get-user:
+        duration: 1ms
+        span_name: "GET /users/{user.id}"
+        attributes:
+          user.id:
+            sequence: u{n}
+        calls:
+          - db.query
+  db:
+    operations:
+      query:
+        duration: 1ms
+traffic:
+  rate: 1000/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Realtime = realtime
+			engine.MaxTraces = 5
+			engine.Duration = time.Minute
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			var roots int
+			for _, s := range exporter.GetSpans() {
+				if !s.Parent.IsValid() {
+					roots++
+					var userID string
+					for _, kv := range s.Attributes {
+						if kv.Key == "user.id" {
+							userID = kv.Value.Emit()
+						}
+					}
+					require.NotEmpty(t, userID)
+					assert.Equal(t, "GET /users/"+userID, s.Name)
+					continue
+				}
+				assert.Equal(t, "query", s.Name, "operations without span_name keep their name")
+			}
+			assert.Equal(t, 5, roots)
+		})
+	}
+}
+
+func TestEngineSwallowErrors(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 1ms
+        calls:
+          - recommendations.list
+  recommendations:
+    operations:
+      list:
+        duration: 1ms
+        swallow_errors: true
+        calls:
+          - model.score
+  model:
+    operations:
+      score:
+        duration: 1ms
+        error_rate: 100%
+traffic:
+  rate: 1000/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Realtime = realtime
+			engine.MaxTraces = 10
+			engine.Duration = time.Minute
+
+			stats, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 30)
+			for _, s := range spans {
+				if s.Name == "score" {
+					assert.Equal(t, codes.Error, s.Status.Code)
+					continue
+				}
+				assert.NotEqual(t, codes.Error, s.Status.Code, "%s does not inherit the swallowed error", s.Name)
+			}
+			assert.Equal(t, int64(10), stats.Errors)
+			assert.Zero(t, stats.FailedTraces)
+		})
+	}
+}
+
+func TestEngineBackfill(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      handle:
+        duration: 10ms
+traffic:
+  rate: 1/m
+  pattern: diurnal
+  period: 24h
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	engine.Backfill = true
+	engine.Duration = 24 * time.Hour
+	engine.TimeOffset = -24 * time.Hour
+
+	began := time.Now()
+	stats, err := engine.Run(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+	assert.Less(t, time.Since(began), 10*time.Second, "a day is simulated without sleeping")
+	assert.Equal(t, (24 * time.Hour).Milliseconds(), stats.ElapsedMs)
+
+	spans := exporter.GetSpans()
+	require.NotEmpty(t, spans)
+	// 24h averaging 1/m is about 1440 traces.
+	assert.InDelta(t, 1440, len(spans), 150)
+
+	starts := make([]time.Time, len(spans))
+	for i, s := range spans {
+		starts[i] = s.StartTime
+	}
+	slices.SortFunc(starts, time.Time.Compare)
+	first, last := starts[0], starts[len(starts)-1]
+	assert.WithinDuration(t, began.Add(-24*time.Hour), first, time.Minute, "the window starts a day ago")
+	assert.Greater(t, last.Sub(first), 23*time.Hour, "timestamps cover the whole day")
+
+	// The diurnal pattern starts at its trough and peaks at 12h.
+	var trough, peak int
+	for _, start := range starts {
+		switch hour := start.Sub(first) / time.Hour; {
+		case hour < 2 || hour >= 22:
+			trough++
+		case hour >= 10 && hour < 14:
+			peak++
+		}
+	}
+	assert.Greater(t, peak, 2*trough, "traces follow the diurnal curve: trough %d, peak %d", trough, peak)
+}
+
+func TestEngineScenarioLabels(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      handle:
+        duration: 10ms
+traffic:
+  rate: 1/s
+scenarios:
+  - name: outage
+    at: +5m
+    duration: 2m
+    labels:
+      incident.id: INC-123
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	engine.Backfill = true
+	engine.Duration = 10 * time.Minute
+	began := time.Now()
+	_, err = engine.Run(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	var labelled, unlabelled int
+	for _, s := range exporter.GetSpans() {
+		at := s.StartTime.Sub(began)
+		// began is read just before the run's own start, so leave a margin
+		// around the window's edges.
+		if (at-5*time.Minute).Abs() < time.Second || (at-7*time.Minute).Abs() < time.Second {
+			continue
+		}
+		during := at > 5*time.Minute && at < 7*time.Minute
+		var label string
+		for _, attr := range s.Attributes {
+			if attr.Key == "incident.id" {
+				label = attr.Value.AsString()
+			}
+		}
+		if during {
+			labelled++
+			assert.Equal(t, "INC-123", label, "span at %s is in the scenario window", at)
+		} else {
+			unlabelled++
+			assert.Empty(t, label, "span at %s is outside the scenario window", at)
+		}
+	}
+	assert.Positive(t, labelled)
+	assert.Positive(t, unlabelled)
+}
+
+func TestEngineTiming(t *testing.T) {
+	t.Parallel()
+
+	// The same child set under each timing: a 2ms cache lookup, a 100ms
+	// database query that always fails, and an async 300ms job. The calls
+	// start halfway through handle's own 10ms.
+	tests := []struct {
+		name    string
+		timing  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "default", want: 110 * time.Millisecond, wantErr: true},
+		{name: "wait-all", timing: "wait-all", want: 110 * time.Millisecond, wantErr: true},
+		{name: "wait-critical", timing: "wait-critical", want: 105 * time.Millisecond, wantErr: true},
+		{name: "return-early", timing: "return-early", want: 10 * time.Millisecond, wantErr: false},
+	}
+	for _, tt := range tests {
+		cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      handle:
+        duration: 10ms
+        timing: "` + tt.timing + `"
+        calls:
+          - cache.get
+          - db.query
+          - target: worker.process
+            async: true
+  cache:
+    operations:
+      get:
+        duration: 2ms
+  db:
+    operations:
+      query:
+        duration: 100ms
+        error_rate: 100%
+  worker:
+    operations:
+      process:
+        duration: 300ms
+traffic:
+  rate: 100/s
+`))
+		require.NoError(t, err)
+		require.NoError(t, ValidateConfig(cfg))
+
+		for _, realtime := range []bool{false, true} {
+			name := tt.name + "/batch"
+			if realtime {
+				name = tt.name + "/realtime"
+			}
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				engine, exporter, tp := newTestEngine(t, cfg)
+				engine.Duration = time.Minute
+				engine.MaxTraces = 1
+				engine.Realtime = realtime
+
+				_, err := engine.Run(t.Context())
+				require.NoError(t, err)
+				require.NoError(t, tp.ForceFlush(context.Background()))
+
+				spans := exporter.GetSpans()
+				require.Len(t, spans, 4)
+				for _, span := range spans {
+					if span.Name != "handle" {
+						continue
+					}
+					assert.Equal(t, tt.want, span.EndTime.Sub(span.StartTime))
+					assert.Equal(t, tt.wantErr, span.Status.Code == codes.Error)
+				}
+			})
+		}
+	}
+}
+
+func TestEngineCallAttributes(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  checkout:
+    operations:
+      submit:
+        duration: 1ms
+        calls:
+          - target: users.get
+            attributes:
+              caller.path:
+                value: checkout
+              users.tier:
+                value: premium
+  admin:
+    operations:
+      list:
+        duration: 1ms
+        calls: [users.get]
+  users:
+    operations:
+      get:
+        duration: 1ms
+        attributes:
+          users.tier:
+            value: standard
+traffic:
+  rate: 100/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Duration = time.Minute
+			engine.MaxTraces = 50
+			engine.Realtime = realtime
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			spans := exporter.GetSpans()
+			callers := make(map[trace.SpanID]string)
+			for _, s := range spans {
+				callers[s.SpanContext.SpanID()] = s.Name
+			}
+			seen := make(map[string]bool)
+			for _, s := range spans {
+				if s.Name != "get" {
+					continue
+				}
+				attrs := make(map[string]string)
+				for _, attr := range s.Attributes {
+					attrs[string(attr.Key)] = attr.Value.Emit()
+				}
+				caller := callers[s.Parent.SpanID()]
+				seen[caller] = true
+				switch caller {
+				case "submit":
+					assert.Equal(t, "checkout", attrs["caller.path"])
+					assert.Equal(t, "premium", attrs["users.tier"], "the call's value wins over the callee's")
+				case "list":
+					assert.NotContains(t, attrs, "caller.path", "only the annotated call sets it")
+					assert.Equal(t, "standard", attrs["users.tier"])
+				default:
+					t.Fatalf("users.get called from unexpected %q", caller)
+				}
+			}
+			assert.Equal(t, map[string]bool{"submit": true, "list": true}, seen)
+		})
+	}
+}
+
+func TestEngineScenarioDrain(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []string{"skip", "error"} {
+		t.Run(mode, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 1ms
+        calls:
+          - db.query
+  db:
+    operations:
+      query:
+        duration: 1ms
+      backup:
+        duration: 1ms
+traffic:
+  rate: 2/s
+scenarios:
+  - name: maintenance
+    at: +5m
+    duration: 2m
+    drain: [db]
+    drain_mode: ` + mode + `
+`))
+			require.NoError(t, err)
+			require.NoError(t, ValidateConfig(cfg))
+
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Backfill = true
+			engine.Duration = 10 * time.Minute
+			began := time.Now()
+			_, err = engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			var dbBefore, dbAfter, gatewayDuring int
+			for _, s := range exporter.GetSpans() {
+				at := s.StartTime.Sub(began)
+				// began is read just before the run's own start, so leave a
+				// margin around the window's edges.
+				if (at-5*time.Minute).Abs() < time.Second || (at-7*time.Minute).Abs() < time.Second {
+					continue
+				}
+				during := at > 5*time.Minute && at < 7*time.Minute
+				switch s.Name {
+				case "query", "backup":
+					assert.False(t, during, "db span %s at %s is in the drain window", s.Name, at)
+					if at < 5*time.Minute {
+						dbBefore++
+					} else {
+						dbAfter++
+					}
+				case "request":
+					if during {
+						gatewayDuring++
+						assert.Equal(t, mode == "error", s.Status.Code == codes.Error, "gateway span at %s", at)
+					}
+				}
+			}
+			assert.Positive(t, dbBefore)
+			assert.Positive(t, dbAfter, "db resumes after the drain window")
+			assert.Positive(t, gatewayDuring, "gateway keeps serving during the drain window")
+		})
+	}
+}
+
+func TestEngineDisabled(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 1ms
+        calls:
+          - backend.list
+          - audit.record
+          - target: cache.get
+            disabled: true
+  backend:
+    operations:
+      list:
+        duration: 1ms
+  audit:
+    operations:
+      record:
+        duration: 1ms
+        disabled: true
+  cache:
+    operations:
+      get:
+        duration: 1ms
+  admin:
+    operations:
+      report:
+        duration: 1ms
+        disabled: true
+traffic:
+  rate: 1000/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	for _, realtime := range []bool{false, true} {
+		name := "batch"
+		if realtime {
+			name = "realtime"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			engine, exporter, tp := newTestEngine(t, cfg)
+			engine.Realtime = realtime
+			engine.MaxTraces = 10
+			engine.Duration = time.Minute
+
+			_, err := engine.Run(t.Context())
+			require.NoError(t, err)
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			// cache.get is no longer called, so it starts traces of its own.
+			counts := make(map[string]int)
+			for _, s := range exporter.GetSpans() {
+				counts[s.Name]++
+			}
+			assert.NotContains(t, counts, "record", "a disabled operation emits no spans")
+			assert.NotContains(t, counts, "report", "a disabled root starts no traces")
+			assert.Equal(t, counts["request"], counts["list"], "callers still make their enabled calls")
+			assert.Equal(t, 10, counts["request"]+counts["get"])
+		})
+	}
+}
+
+func TestEngineServiceFlap(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 1ms
+        calls:
+          - db.query
+  db:
+    flap:
+      up_duration: 2m
+      down_duration: 1m
+      jitter: 10s
+    operations:
+      query:
+        duration: 1ms
+traffic:
+  rate: 5/s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	engine.Backfill = true
+	engine.Duration = 15 * time.Minute
+	began := time.Now()
+	stats, err := engine.Run(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	// Error density of the gateway's requests in 15s buckets: all or nothing
+	// while the window is unambiguous, alternating as db flaps.
+	const bucket = 15 * time.Second
+	total := make([]int, engine.Duration/bucket+1)
+	failed := make([]int, len(total))
+	for _, s := range exporter.GetSpans() {
+		if s.Name == "query" && s.Status.Code == codes.Error {
+			assert.Contains(t, s.Attributes, attribute.String("synth.rejection_reason", ReasonUnavailable))
+		}
+		if s.Name != "request" {
+			continue
+		}
+		i := max(s.StartTime.Sub(began), 0) / bucket
+		total[i]++
+		if s.Status.Code == codes.Error {
+			failed[i]++
+		}
+	}
+	var up, down, changes int
+	last := -1
+	for i := range total {
+		if total[i] == 0 {
+			continue
+		}
+		state := -1
+		switch failed[i] {
+		case 0:
+			state, up = 0, up+1
+		case total[i]:
+			state, down = 1, down+1
+		}
+		if state >= 0 && last >= 0 && state != last {
+			changes++
+		}
+		if state >= 0 {
+			last = state
+		}
+	}
+	assert.Positive(t, up, "buckets with no errors while db is up")
+	assert.Positive(t, down, "buckets with only errors while db is down")
+	assert.GreaterOrEqual(t, changes, 8, "error density oscillates with each flap")
+	assert.Positive(t, stats.FlapRejections)
+	assert.Equal(t, stats.FlapRejections, stats.FailedTraces, "every failure is a flap rejection")
+}
+
+func TestResolvedFlapDown(t *testing.T) {
+	t.Parallel()
+
+	flap := newResolvedFlap(&FlapConfig{UpDuration: "2m", DownDuration: "1m"}, "db")
+	for _, tc := range []struct {
+		at   time.Duration
+		want bool
+	}{
+		{0, false},
+		{119 * time.Second, false},
+		{2 * time.Minute, true},
+		{179 * time.Second, true},
+		{3 * time.Minute, false},
+		{5 * time.Minute, true},
+	} {
+		assert.Equal(t, tc.want, flap.down(tc.at), "at %s", tc.at)
+	}
+
+	jittered := newResolvedFlap(&FlapConfig{UpDuration: "2m", DownDuration: "1m", Jitter: "20s"}, "db")
+	again := newResolvedFlap(&FlapConfig{UpDuration: "2m", DownDuration: "1m", Jitter: "20s"}, "db")
+	var downFor time.Duration
+	for at := time.Duration(0); at < time.Hour; at += time.Second {
+		got := jittered.down(at)
+		assert.Equal(t, got, again.down(at), "the jittered schedule is the same on every run, at %s", at)
+		if got {
+			downFor += time.Second
+		}
+	}
+	assert.InDelta(t, 20*time.Minute, downFor, float64(3*time.Minute), "down a third of the time on average")
+	assert.False(t, jittered.down(99*time.Second), "the first window is up for at least 2m less the jitter")
+}
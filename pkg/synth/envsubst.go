@@ -0,0 +1,96 @@
+// Environment variable substitution for topology YAML
+// Expands ${VAR} and ${VAR:-default} references outside comments before the config is parsed
+package synth
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// envRefPattern matches $${ (an escaped literal "${") or a ${NAME} reference
+// with an optional :-default. Defaults cannot contain "}".
+var envRefPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv replaces ${VAR} and ${VAR:-default} references in data with
+// values from lookup. As in the shell, the default is used when the variable
+// is unset or empty. "$${" produces a literal "${". References to unset
+// variables without a default are an error naming every missing variable.
+// YAML comments are copied as they are, so a reference in one is ignored.
+func expandEnv(data []byte, lookup func(string) (string, bool)) ([]byte, error) {
+	missing := make(map[string]bool)
+	expand := func(match []byte) []byte {
+		if string(match) == "$${" {
+			return []byte("${")
+		}
+		sub := envRefPattern.FindSubmatch(match)
+		name := string(sub[1])
+		hasDefault := sub[2] != nil
+		if v, ok := lookup(name); ok && (v != "" || !hasDefault) {
+			return []byte(v)
+		}
+		if hasDefault {
+			return sub[3]
+		}
+		missing[name] = true
+		return match
+	}
+	out := make([]byte, 0, len(data))
+	for line := range bytes.Lines(data) {
+		i := commentStart(line)
+		out = append(out, envRefPattern.ReplaceAllFunc(line[:i], expand)...)
+		out = append(out, line[i:]...)
+	}
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("undefined environment variable(s): %s (use ${VAR:-default} to provide a default)", strings.Join(names, ", "))
+	}
+	return out, nil
+}
+
+// commentStart returns the index at which a YAML comment begins in line, or
+// len(line) when it has none: a "#" at the start of the line or after
+// whitespace, outside any quoted scalar. A quote only opens a scalar at the
+// start of the line or after whitespace or a flow indicator, so apostrophes
+// inside plain scalars are not mistaken for one.
+//
+// Each line is scanned on its own, so a " #" inside a block scalar or a
+// quoted scalar that spans lines is taken for a comment and references after
+// it are left unexpanded. Tokenizing with yaml.v3 would track scalars across
+// lines, but the file would then have to parse before it is expanded.
+func commentStart(line []byte) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		var prev byte
+		if i > 0 {
+			prev = line[i-1]
+		}
+		switch {
+		// An escaped quote never closes a quoted scalar.
+		case quote == '"' && c == '\\', quote == '\'' && c == '\'' && i+1 < len(line) && line[i+1] == '\'':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[{,", prev) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || prev == ' ' || prev == '\t'):
+			return i
+		}
+	}
+	return len(line)
+}
+
+// expandProcessEnv expands references using the process environment.
+func expandProcessEnv(data []byte) ([]byte, error) {
+	return expandEnv(data, os.LookupEnv)
+}
@@ -0,0 +1,123 @@
+// Tests for environment variable substitution in topology YAML
+package synth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"HOST":  "collector",
+		"EMPTY": "",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain reference", "host: ${HOST}", "host: collector"},
+		{"embedded reference", "url: http://${HOST}:4318", "url: http://collector:4318"},
+		{"default unused when set", "host: ${HOST:-localhost}", "host: collector"},
+		{"default when unset", "rate: ${RATE:-10/s}", "rate: 10/s"},
+		{"default when empty", "host: ${EMPTY:-localhost}", "host: localhost"},
+		{"empty without default", "host: ${EMPTY}", "host: "},
+		{"empty default", "host: ${UNSET:-}", "host: "},
+		{"escaped literal", "text: $${HOST}", "text: ${HOST}"},
+		{"bare dollar untouched", "price: $5 and $HOST", "price: $5 and $HOST"},
+		{"invalid name untouched", "x: ${1ABC}", "x: ${1ABC}"},
+		{"comment line untouched", "# set ${UNSET} first\nhost: ${HOST}", "# set ${UNSET} first\nhost: collector"},
+		{"trailing comment untouched", "host: ${HOST} # or ${UNSET}", "host: collector # or ${UNSET}"},
+		{"hash in quoted value", `msg: "a # ${HOST}" # ${UNSET}`, `msg: "a # collector" # ${UNSET}`},
+		{"hash in single quoted value", "msg: 'it''s # ${HOST}'", "msg: 'it''s # collector'"},
+		{"escaped quote in quoted value", `msg: "say \" # ${HOST}"`, `msg: "say \" # collector"`},
+		{"apostrophe in plain value", "msg: it's ${HOST} # ${UNSET}", "msg: it's collector # ${UNSET}"},
+		{"hash without space is not a comment", "url: http://${HOST}/#${HOST}", "url: http://collector/#collector"},
+		// Lines are scanned on their own, so a " #" in a block scalar reads as
+		// a comment.
+		{"hash in block scalar", "msg: |\n  a # ${HOST}\n  ${HOST}", "msg: |\n  a # ${HOST}\n  collector"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := expandEnv([]byte(tt.in), lookup)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("missing variables rejected", func(t *testing.T) {
+		t.Parallel()
+		_, err := expandEnv([]byte("a: ${ZETA}\nb: ${ALPHA}\nc: ${ZETA}"), lookup)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "undefined environment variable(s): ALPHA, ZETA")
+	})
+}
+
+func TestLoadConfigExpandsEnv(t *testing.T) {
+	t.Setenv("MOTEL_TEST_SERVICE", "checkout")
+	t.Setenv("MOTEL_TEST_RATE", "25/s")
+
+	path := writeTestConfig(t, `
+version: 1
+services:
+  ${MOTEL_TEST_SERVICE}:
+    operations:
+      pay:
+        duration: ${MOTEL_TEST_DURATION:-40ms}
+traffic:
+  rate: ${MOTEL_TEST_RATE}
+`)
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	require.Len(t, cfg.Services, 1)
+	assert.Equal(t, "checkout", cfg.Services[0].Name)
+	assert.Equal(t, "40ms", cfg.Services[0].Operations[0].Duration)
+	assert.Equal(t, "25/s", cfg.Traffic.Rate)
+}
+
+func TestLoadConfigIgnoresEnvInComments(t *testing.T) {
+	t.Setenv("MOTEL_TEST_COMMENT_RATE", "25/s")
+
+	path := writeTestConfig(t, `
+# Set ${MOTEL_TEST_UNSET_IN_COMMENT} before running.
+version: 1
+services:
+  svc:
+    operations:
+      op:
+        duration: 10ms # ${MOTEL_TEST_UNSET_IN_COMMENT} is not read
+traffic:
+  rate: ${MOTEL_TEST_COMMENT_RATE}
+`)
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "25/s", cfg.Traffic.Rate)
+}
+
+func TestLoadConfigMissingEnv(t *testing.T) {
+	t.Parallel()
+
+	path := writeTestConfig(t, `
+version: 1
+services:
+  svc:
+    operations:
+      op:
+        duration: 10ms
+traffic:
+  rate: ${MOTEL_TEST_UNSET_RATE}
+`)
+	_, err := LoadConfig(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MOTEL_TEST_UNSET_RATE")
+}
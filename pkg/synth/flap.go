@@ -0,0 +1,119 @@
+// Service flapping: an unstable dependency that alternates between up and down windows
+// While a service is down, every one of its operations rejects with an error span
+package synth
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ReasonUnavailable is the rejection reason of a span whose service is in a
+// down window of its flap.
+const ReasonUnavailable = "unavailable"
+
+// flapScheduleSeed seeds the RNG that jitters flap windows. It is mixed with
+// the service name, so services with the same flap do not go down in step,
+// and each service's jittered schedule is the same on every run.
+const flapScheduleSeed = 0x666c6170
+
+// FlapConfig makes a service alternate between up and down windows, starting
+// up. With Jitter, each window's length is drawn uniformly from its duration
+// plus or minus the jitter.
+type FlapConfig struct {
+	UpDuration   string `yaml:"up_duration"`
+	DownDuration string `yaml:"down_duration"`
+	Jitter       string `yaml:"jitter,omitempty"`
+}
+
+// validateFlap checks a service's flap durations and jitter.
+func validateFlap(flap *FlapConfig) error {
+	if flap == nil {
+		return nil
+	}
+	up, err := time.ParseDuration(flap.UpDuration)
+	if err != nil {
+		return fmt.Errorf("invalid up_duration: %w", err)
+	}
+	if up <= 0 {
+		return fmt.Errorf("up_duration must be positive, got %s", flap.UpDuration)
+	}
+	down, err := time.ParseDuration(flap.DownDuration)
+	if err != nil {
+		return fmt.Errorf("invalid down_duration: %w", err)
+	}
+	if down <= 0 {
+		return fmt.Errorf("down_duration must be positive, got %s", flap.DownDuration)
+	}
+	if flap.Jitter != "" {
+		jitter, err := time.ParseDuration(flap.Jitter)
+		if err != nil {
+			return fmt.Errorf("invalid jitter: %w", err)
+		}
+		if jitter < 0 {
+			return fmt.Errorf("jitter must not be negative, got %s", flap.Jitter)
+		}
+		if jitter >= min(up, down) {
+			return fmt.Errorf("jitter must be less than up_duration and down_duration, got %s", flap.Jitter)
+		}
+	}
+	return nil
+}
+
+// ResolvedFlap holds a service's parsed flap and the schedule of its windows.
+type ResolvedFlap struct {
+	Up     time.Duration
+	Down   time.Duration
+	Jitter time.Duration
+
+	// mu guards the jittered schedule, which down extends as elapsed grows.
+	mu  sync.Mutex
+	rng *rand.Rand
+	// changes holds the times the service changes state: it goes down at
+	// even indices and comes back up at odd ones.
+	changes []time.Duration
+}
+
+// newResolvedFlap parses a validated flap for the named service.
+func newResolvedFlap(cfg *FlapConfig, service string) *ResolvedFlap {
+	flap := &ResolvedFlap{}
+	flap.Up, _ = time.ParseDuration(cfg.UpDuration)
+	flap.Down, _ = time.ParseDuration(cfg.DownDuration)
+	if cfg.Jitter != "" {
+		flap.Jitter, _ = time.ParseDuration(cfg.Jitter)
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(service))
+	flap.rng = rand.New(rand.NewPCG(flapScheduleSeed, h.Sum64())) //nolint:gosec // synthetic data, not security-sensitive
+	return flap
+}
+
+// down reports whether the service is in a down window at elapsed.
+func (f *ResolvedFlap) down(elapsed time.Duration) bool {
+	if f.Jitter == 0 {
+		return elapsed%(f.Up+f.Down) >= f.Up
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.changes) == 0 || f.changes[len(f.changes)-1] <= elapsed {
+		last, window := time.Duration(0), f.Up
+		if n := len(f.changes); n > 0 {
+			last = f.changes[n-1]
+			if n%2 == 1 {
+				window = f.Down
+			}
+		}
+		offset := time.Duration((2*f.rng.Float64() - 1) * float64(f.Jitter))
+		f.changes = append(f.changes, last+window+offset)
+	}
+	i, found := slices.BinarySearch(f.changes, elapsed)
+	if found {
+		i++
+	}
+	// i changes have happened by elapsed; after an odd number the service
+	// is down.
+	return i%2 == 1
+}
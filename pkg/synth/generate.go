@@ -1,25 +1,29 @@
 // Public trace-generation API
 // Emits a topology's traces through a caller-provided TracerProvider so
-// pipeline tests and other embedders can drive generation without the engine.
+// pipeline tests and other embedders can drive generation without the CLI,
+// either back-to-back (GenerateTraces) or as a paced engine run (Generate).
 package synth
 
 import (
 	"context"
 	"fmt"
 	"math/rand/v2"
+	"slices"
 	"time"
 
 	"go.opentelemetry.io/otel/trace"
 )
 
-// GenerateOptions configures GenerateTraces.
+// GenerateOptions configures GenerateTraces and Generate.
 type GenerateOptions struct {
-	// Traces is the number of traces to generate. Zero generates nothing.
+	// Traces is the number of traces to generate. Zero generates nothing
+	// with GenerateTraces; Generate then runs for the whole Duration.
 	Traces int
 
-	// Seed makes generation reproducible: trace i derives its RNG from
-	// Seed+i, so the same seed replays the same root choices and span
-	// structure. Zero picks a random seed.
+	// Seed makes generation reproducible. For GenerateTraces, trace i
+	// derives its RNG from Seed+i, so the same seed replays the same root
+	// choices and span structure; Generate seeds its engine's RNG with it.
+	// Zero picks a random seed.
 	Seed uint64
 
 	// MaxSpansPerTrace bounds the spans emitted per trace.
@@ -31,6 +35,151 @@ type GenerateOptions struct {
 	// channel alongside the TracerProvider: OTLP emission is unaffected, and
 	// a nil or empty slice preserves the exporter-only behavior exactly.
 	Observers []SpanObserver
+
+	// The options below apply only to Generate.
+
+	// TracerProvider receives every service's spans, through a tracer named
+	// after the service. Set Tracers instead to choose each service's tracer,
+	// for example from providers whose resources carry its service.name.
+	TracerProvider trace.TracerProvider
+	Tracers        TracerSource
+
+	// Resolvers supply the attributes of the semconv domains operations
+	// name, as for BuildTopology.
+	Resolvers []DomainResolver
+
+	// Duration is how long to generate traffic for. It must be positive.
+	Duration time.Duration
+
+	// Rng, when set, drives the simulation in place of one seeded from Seed.
+	Rng *rand.Rand
+
+	// The remaining fields set the Engine fields of the same names.
+	Realtime          bool
+	Backfill          bool
+	MaxTraceDuration  time.Duration
+	SampleRatio       float64
+	Warmup            time.Duration
+	LabelScenarios    bool
+	LinkRetries       bool
+	TimeOffset        time.Duration
+	CollectPerOp      bool
+	UntilScenariosEnd bool
+}
+
+// Generation is a config built for a run: its topology, traffic pattern,
+// scenarios, and the observers its observers: section declares. Callers may
+// narrow it, for example with SelectServices or SelectRoot, before creating
+// its engine, and must Close it once the run ends.
+type Generation struct {
+	Topology  *Topology
+	Traffic   TrafficPattern
+	Scenarios []Scenario
+	Observers []SpanObserver
+}
+
+// NewGeneration builds the topology, traffic pattern, scenarios, and
+// observers of cfg, which must already have passed ValidateConfig.
+func NewGeneration(cfg *Config, resolvers ...DomainResolver) (*Generation, error) {
+	topo, err := BuildTopology(cfg, resolvers...)
+	if err != nil {
+		return nil, err
+	}
+	traffic, err := NewTrafficPattern(cfg.Traffic)
+	if err != nil {
+		return nil, err
+	}
+	scenarios, err := BuildScenarios(cfg.Scenarios, topo)
+	if err != nil {
+		return nil, err
+	}
+	observers, err := BuildObservers(cfg.Observers, topo)
+	if err != nil {
+		return nil, err
+	}
+	return &Generation{Topology: topo, Traffic: traffic, Scenarios: scenarios, Observers: observers}, nil
+}
+
+// Close closes the config-declared observers that implement io.Closer,
+// letting them flush what they collected, and returns the first error.
+func (g *Generation) Close() error {
+	return closeObservers(g.Observers)
+}
+
+// NewEngine returns an engine that runs g with opts, ready for Run. The
+// engine notifies opts.Observers followed by g.Observers. Callers that need
+// the engine itself, for example to watch its Progress, use this in place of
+// Generate.
+func (g *Generation) NewEngine(opts GenerateOptions) (*Engine, error) {
+	tracers := opts.Tracers
+	if tracers == nil {
+		tracers = TracerProviderSource(opts.TracerProvider)
+	}
+	if tracers == nil {
+		return nil, fmt.Errorf("no tracer provider: set TracerProvider or Tracers")
+	}
+	if opts.Duration <= 0 {
+		return nil, fmt.Errorf("duration must be positive, got %s", opts.Duration)
+	}
+	rng := opts.Rng
+	if rng == nil {
+		seed := opts.Seed
+		if seed == 0 {
+			seed = rand.Uint64() //nolint:gosec // not security-sensitive
+		}
+		rng = rand.New(rand.NewPCG(seed, 0)) //nolint:gosec // not security-sensitive
+	}
+	return &Engine{
+		Topology:          g.Topology,
+		Traffic:           g.Traffic,
+		Scenarios:         g.Scenarios,
+		Tracers:           tracers,
+		Rng:               rng,
+		Duration:          opts.Duration,
+		Observers:         append(slices.Clone(opts.Observers), g.Observers...),
+		MaxSpansPerTrace:  opts.MaxSpansPerTrace,
+		MaxTraceDuration:  opts.MaxTraceDuration,
+		State:             NewSimulationState(g.Topology),
+		LabelScenarios:    opts.LabelScenarios,
+		LinkRetries:       opts.LinkRetries,
+		TimeOffset:        opts.TimeOffset,
+		Realtime:          opts.Realtime,
+		Backfill:          opts.Backfill,
+		SampleRatio:       opts.SampleRatio,
+		Warmup:            opts.Warmup,
+		CollectPerOp:      opts.CollectPerOp,
+		MaxTraces:         opts.Traces,
+		UntilScenariosEnd: opts.UntilScenariosEnd,
+	}, nil
+}
+
+// Generate validates cfg, builds it, and runs the engine over it for
+// opts.Duration, pacing traces by the config's traffic pattern and applying
+// its scenarios. Spans go to opts.Tracers or opts.TracerProvider; the
+// caller owns the provider and flushes and shuts it down afterwards. The
+// config's observers are closed when the run ends or ctx is cancelled,
+// before Generate returns.
+func Generate(ctx context.Context, cfg *Config, opts GenerateOptions) (*Stats, error) {
+	if err := ValidateConfig(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Mode == ModeReplay {
+		return nil, fmt.Errorf("generate does not support mode: replay")
+	}
+	g, err := NewGeneration(cfg, opts.Resolvers...)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := g.NewEngine(opts)
+	if err != nil {
+		g.Close() //nolint:errcheck,gosec // reporting the engine error instead
+		return nil, err
+	}
+	stats, err := engine.Run(ctx)
+	if closeErr := g.Close(); err == nil && closeErr != nil {
+		return nil, fmt.Errorf("closing observers: %w", closeErr)
+	}
+	return stats, err
 }
 
 // TracerProviderSource adapts a trace.TracerProvider into a TracerSource that
@@ -260,3 +260,69 @@ func TestGenerateTraces_CancelledContext(t *testing.T) {
 		t.Fatalf("expected no traces after immediate cancellation, got %d", stats.Traces)
 	}
 }
+
+const generateTestConfig = `
+version: 1
+services:
+  gateway:
+    operations:
+      handle:
+        duration: 5ms
+        calls: [backend.read]
+  backend:
+    operations:
+      read:
+        duration: 2ms
+traffic:
+  rate: 100/s
+`
+
+func TestGenerate_EmitsSpans(t *testing.T) {
+	cfg, err := ParseConfig([]byte(generateTestConfig))
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	exporter, tp := newCapturingProvider(t)
+
+	const n = 5
+	stats, err := Generate(context.Background(), cfg, GenerateOptions{
+		TracerProvider: tp,
+		Duration:       time.Minute,
+		Traces:         n,
+		Seed:           42,
+	})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if stats.Traces != n {
+		t.Fatalf("expected %d traces, got %d", n, stats.Traces)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2*n {
+		t.Fatalf("expected %d exported spans, got %d", 2*n, len(spans))
+	}
+	for _, s := range spans {
+		if s.Name == "handle" && s.InstrumentationScope.Name != "gateway" {
+			t.Fatalf("span %q has scope %q, want gateway", s.Name, s.InstrumentationScope.Name)
+		}
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	cfg, err := ParseConfig([]byte(generateTestConfig))
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	_, tp := newCapturingProvider(t)
+
+	if _, err := Generate(context.Background(), cfg, GenerateOptions{Duration: time.Second}); err == nil {
+		t.Fatal("expected error without a tracer provider")
+	}
+	if _, err := Generate(context.Background(), cfg, GenerateOptions{TracerProvider: tp}); err == nil {
+		t.Fatal("expected error for zero duration")
+	}
+	if _, err := Generate(context.Background(), &Config{}, GenerateOptions{TracerProvider: tp, Duration: time.Second}); err == nil {
+		t.Fatal("expected error for an invalid config")
+	}
+}
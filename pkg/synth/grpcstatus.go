@@ -0,0 +1,83 @@
+// gRPC status codes for errored spans of rpc-domain operations
+// Timeouts map to DEADLINE_EXCEEDED; other errors draw a weighted code
+package synth
+
+import (
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// grpcStatusCodeAttribute is the span attribute carrying a gRPC status code.
+const grpcStatusCodeAttribute = "rpc.grpc.status_code"
+
+// rpcDomain is the semconv domain whose errored spans get a gRPC status code.
+const rpcDomain = "rpc"
+
+// grpcDeadlineExceeded is the status code of an error caused by a timeout.
+const grpcDeadlineExceeded = 4
+
+// grpcErrorCodes are the status codes drawn for rpc errors not caused by a
+// timeout, weighted towards the codes production services return most.
+var grpcErrorCodes = []struct {
+	code   int
+	weight int
+}{
+	{14, 50}, // UNAVAILABLE
+	{4, 15},  // DEADLINE_EXCEEDED
+	{13, 15}, // INTERNAL
+	{8, 10},  // RESOURCE_EXHAUSTED
+	{2, 5},   // UNKNOWN
+	{10, 5},  // ABORTED
+}
+
+// derivesGRPCStatus reports whether the engine sets an errored span's
+// rpc.grpc.status_code for op: the operation is in the rpc domain and
+// nothing the user configured sets the attribute.
+func derivesGRPCStatus(op *Operation, opAttrs Attributes, errType *ErrorType) bool {
+	if !slices.Contains(op.Domains, rpcDomain) || opAttrs.Get(grpcStatusCodeAttribute) != nil {
+		return false
+	}
+	return errType == nil || errType.Attributes.Get(grpcStatusCodeAttribute) == nil
+}
+
+// GRPCStatusCodes returns the rpc.grpc.status_code values derived for errored
+// spans of op, most common first. It returns nil when op is not in the rpc
+// domain or sets the attribute itself. Error types that set the attribute
+// add their own codes.
+func GRPCStatusCodes(op *Operation) []int {
+	if !derivesGRPCStatus(op, op.Attributes, nil) {
+		return nil
+	}
+	codes := make([]int, 0, len(grpcErrorCodes))
+	for _, c := range grpcErrorCodes {
+		codes = append(codes, c.code)
+	}
+	return codes
+}
+
+// grpcStatusAttribute returns the rpc.grpc.status_code attribute for an
+// errored span of an rpc-domain operation, and false when the code is not
+// derived. A chosen error type or operation attribute that sets the
+// attribute keeps its code; an error caused by a timeout is
+// DEADLINE_EXCEEDED; otherwise a code is drawn from grpcErrorCodes.
+func (e *Engine) grpcStatusAttribute(op *Operation, opAttrs Attributes, errType *ErrorType, deadlineExceeded bool) (attribute.KeyValue, bool) {
+	if !derivesGRPCStatus(op, opAttrs, errType) {
+		return attribute.KeyValue{}, false
+	}
+	if deadlineExceeded {
+		return attribute.Int(grpcStatusCodeAttribute, grpcDeadlineExceeded), true
+	}
+	total := 0
+	for _, c := range grpcErrorCodes {
+		total += c.weight
+	}
+	r := e.Rng.IntN(total)
+	for _, c := range grpcErrorCodes {
+		r -= c.weight
+		if r < 0 {
+			return attribute.Int(grpcStatusCodeAttribute, c.code), true
+		}
+	}
+	return attribute.Int(grpcStatusCodeAttribute, grpcErrorCodes[0].code), true
+}
@@ -0,0 +1,84 @@
+// HTTP status codes derived from the outcome of http-domain operations
+// Success is 200, timeouts are 504, and other errors draw a weighted 5xx code
+package synth
+
+import (
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// httpStatusCodeAttribute is the span attribute carrying an HTTP response
+// status code.
+const httpStatusCodeAttribute = "http.response.status_code"
+
+// httpDomain is the semconv domain whose spans get a derived status code.
+const httpDomain = "http"
+
+const (
+	httpStatusOK             = 200
+	httpStatusGatewayTimeout = 504
+)
+
+// httpErrorCodes are the status codes drawn for http errors not caused by a
+// timeout.
+var httpErrorCodes = []struct {
+	code   int
+	weight int
+}{
+	{500, 60}, // Internal Server Error
+	{503, 25}, // Service Unavailable
+	{502, 15}, // Bad Gateway
+}
+
+// derivesHTTPStatus reports whether the engine sets op's
+// http.response.status_code from the span outcome: the operation is in the
+// http domain and nothing the user configured sets the attribute.
+func derivesHTTPStatus(op *Operation, opAttrs Attributes, errType *ErrorType) bool {
+	if !slices.Contains(op.Domains, httpDomain) || opAttrs.Get(httpStatusCodeAttribute) != nil {
+		return false
+	}
+	return errType == nil || errType.Attributes.Get(httpStatusCodeAttribute) == nil
+}
+
+// HTTPStatusCodes returns the http.response.status_code values derived for
+// spans of op: success, then timeout, then the other error codes. It returns
+// nil when op is not in the http domain or sets the attribute itself.
+func HTTPStatusCodes(op *Operation) []int {
+	if !derivesHTTPStatus(op, op.Attributes, nil) {
+		return nil
+	}
+	codes := []int{httpStatusOK, httpStatusGatewayTimeout}
+	for _, c := range httpErrorCodes {
+		codes = append(codes, c.code)
+	}
+	return codes
+}
+
+// httpStatusAttribute returns the http.response.status_code attribute for a
+// span of an http-domain operation, and false when the code is not derived.
+// A successful span is 200, an error caused by a timeout is 504, and any
+// other error draws a code from httpErrorCodes.
+func (e *Engine) httpStatusAttribute(op *Operation, opAttrs Attributes, errType *ErrorType, isError, deadlineExceeded bool) (attribute.KeyValue, bool) {
+	if !derivesHTTPStatus(op, opAttrs, errType) {
+		return attribute.KeyValue{}, false
+	}
+	switch {
+	case !isError:
+		return attribute.Int(httpStatusCodeAttribute, httpStatusOK), true
+	case deadlineExceeded:
+		return attribute.Int(httpStatusCodeAttribute, httpStatusGatewayTimeout), true
+	}
+	total := 0
+	for _, c := range httpErrorCodes {
+		total += c.weight
+	}
+	r := e.Rng.IntN(total)
+	for _, c := range httpErrorCodes {
+		r -= c.weight
+		if r < 0 {
+			return attribute.Int(httpStatusCodeAttribute, c.code), true
+		}
+	}
+	return attribute.Int(httpStatusCodeAttribute, httpErrorCodes[0].code), true
+}
@@ -0,0 +1,55 @@
+package synth
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSeededIDGenerator(t *testing.T) {
+	t.Parallel()
+
+	newGen := func(seed uint64) *SeededIDGenerator {
+		return NewSeededIDGenerator(rand.New(rand.NewPCG(seed, 0))) //nolint:gosec // deterministic seed for testing
+	}
+
+	t.Run("same seed yields same IDs", func(t *testing.T) {
+		t.Parallel()
+		a, b := newGen(7), newGen(7)
+		for range 100 {
+			aTrace, aSpan := a.NewIDs(t.Context())
+			bTrace, bSpan := b.NewIDs(t.Context())
+			assert.Equal(t, aTrace, bTrace)
+			assert.Equal(t, aSpan, bSpan)
+			assert.Equal(t, a.NewSpanID(t.Context(), aTrace), b.NewSpanID(t.Context(), bTrace))
+		}
+	})
+
+	t.Run("different seeds diverge", func(t *testing.T) {
+		t.Parallel()
+		aTrace, _ := newGen(7).NewIDs(t.Context())
+		bTrace, _ := newGen(8).NewIDs(t.Context())
+		assert.NotEqual(t, aTrace, bTrace)
+	})
+
+	t.Run("IDs are valid and unique", func(t *testing.T) {
+		t.Parallel()
+		g := newGen(7)
+		traces := make(map[trace.TraceID]bool)
+		spans := make(map[trace.SpanID]bool)
+		for range 10_000 {
+			tid, sid := g.NewIDs(t.Context())
+			child := g.NewSpanID(t.Context(), tid)
+			for _, s := range []trace.SpanID{sid, child} {
+				assert.True(t, s.IsValid())
+				assert.False(t, spans[s], "span ID %s repeated", s)
+				spans[s] = true
+			}
+			assert.True(t, tid.IsValid())
+			assert.False(t, traces[tid], "trace ID %s repeated", tid)
+			traces[tid] = true
+		}
+	})
+}
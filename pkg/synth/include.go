@@ -0,0 +1,292 @@
+// Config includes: merging several topology files into one
+// Resolves include and values file paths, detects cycles, and merges raw configs
+package synth
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// loadRawConfig reads source, expands environment references, and merges in
+// every file listed under include. Includes are merged in order, later ones
+// overriding earlier ones, and the including file overrides all of them.
+// stack holds the sources currently being loaded, for cycle detection, and
+// fetch limits every URL fetch.
+func loadRawConfig(source string, stack []string, fetch FetchOptions) (*rawConfig, error) {
+	if slices.Contains(stack, source) {
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(append(stack, source), " -> "))
+	}
+	stack = append(stack, source)
+
+	data, err := fetchSource(source, fetch)
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	data, err = expandProcessEnv(data)
+	if err != nil {
+		return nil, fmt.Errorf("expanding config: %w", err)
+	}
+	raw, err := parseRawConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	markAttributeFiles(reflect.ValueOf(raw).Elem(), source)
+	if len(raw.Include) == 0 {
+		return raw, nil
+	}
+
+	merged := &rawConfig{}
+	for _, pattern := range raw.Include {
+		paths, err := resolveInclude(source, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: include %q: %w", source, pattern, err)
+		}
+		// Files matched by one glob have no meaningful precedence between
+		// them, so a service defined in more than one of them is ambiguous.
+		definedIn := make(map[string]string)
+		for _, path := range paths {
+			inc, err := loadRawConfig(path, stack, fetch)
+			if err != nil {
+				return nil, err
+			}
+			if len(paths) > 1 {
+				for name := range inc.Services {
+					if prev, ok := definedIn[name]; ok {
+						return nil, fmt.Errorf("%s: include %q: service %q is defined in both %s and %s; include them separately to set precedence", source, pattern, name, prev, path)
+					}
+					definedIn[name] = path
+				}
+			}
+			mergeRawConfig(merged, inc)
+		}
+	}
+	raw.Include = nil
+	mergeRawConfig(merged, raw)
+	return merged, nil
+}
+
+// resolveInclude turns an include entry into the sources it names. Relative
+// paths resolve against the including source; globs are expanded for local
+// files only.
+func resolveInclude(source, pattern string) ([]string, error) {
+	if pattern == StdinSource {
+		return nil, fmt.Errorf("stdin can only be the top-level topology")
+	}
+	hasGlob := strings.ContainsAny(pattern, "*?[")
+	if hasGlob && !isURL(pattern) && isURL(source) {
+		return nil, fmt.Errorf("globs are not supported when including from a URL")
+	}
+	path, err := resolveRelative(source, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if !hasGlob || isURL(path) {
+		return []string{path}, nil
+	}
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match")
+	}
+	slices.Sort(matches)
+	return matches, nil
+}
+
+// resolveRelative resolves ref against the source that names it: relative
+// paths are taken from the source's directory, or its URL when remote.
+func resolveRelative(source, ref string) (string, error) {
+	if isURL(ref) {
+		return ref, nil
+	}
+	if isURL(source) {
+		base, err := url.Parse(source)
+		if err != nil {
+			return "", err
+		}
+		u, err := url.Parse(ref)
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(u).String(), nil
+	}
+	if filepath.IsAbs(ref) {
+		return ref, nil
+	}
+	return filepath.Join(filepath.Dir(source), ref), nil
+}
+
+// markAttributeFiles records source on every attribute value config under v
+// that names a values file. The path itself is resolved later, by
+// resolveAttributeFiles, once vars in it have been interpolated.
+func markAttributeFiles(v reflect.Value, source string) {
+	_ = walkAttributeValues(v, func(cfg *AttributeValueConfig) error {
+		if cfg.File != "" {
+			cfg.source = source
+		}
+		return nil
+	})
+}
+
+// resolveAttributeFiles rewrites the file: path of every attribute value
+// config under v to be relative to the source that named it, so values files
+// named in an included topology are found next to it.
+func resolveAttributeFiles(v reflect.Value) error {
+	return walkAttributeValues(v, func(cfg *AttributeValueConfig) error {
+		if cfg.File == "" || cfg.source == "" {
+			return nil
+		}
+		path, err := resolveRelative(cfg.source, cfg.File)
+		if err != nil {
+			return fmt.Errorf("%s: values file %q: %w", cfg.source, cfg.File, err)
+		}
+		cfg.File, cfg.source = path, ""
+		return nil
+	})
+}
+
+// walkAttributeValues calls fn on every attribute value config under v. Map
+// values are not addressable, so each is copied, visited, and stored back.
+func walkAttributeValues(v reflect.Value, fn func(*AttributeValueConfig) error) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return walkAttributeValues(v.Elem(), fn)
+		}
+	case reflect.Struct:
+		if cfg, ok := v.Addr().Interface().(*AttributeValueConfig); ok {
+			return fn(cfg)
+		}
+		for i := range v.NumField() {
+			if field := v.Field(i); field.CanSet() {
+				if err := walkAttributeValues(field, fn); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			if err := walkAttributeValues(v.Index(i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			if err := walkAttributeValues(elem, fn); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// mergeRawConfig merges src into dst, with src taking precedence. Services
+// merge field by field: operations and attribute maps merge by key, other
+// fields are replaced when src sets them. Vars, templates, trace attributes,
+// and scenarios merge by name, observers accumulate, and traffic is replaced
+// wholesale when src defines any.
+func mergeRawConfig(dst, src *rawConfig) {
+	if src.Version != nil {
+		dst.Version = src.Version
+	}
+	if src.Mode != "" {
+		dst.Mode = src.Mode
+	}
+	if src.Recording != "" {
+		dst.Recording = src.Recording
+	}
+	if src.ScopeName != "" {
+		dst.ScopeName = src.ScopeName
+	}
+	if src.ScopeVersion != "" {
+		dst.ScopeVersion = src.ScopeVersion
+	}
+	if !reflect.ValueOf(src.Traffic).IsZero() {
+		dst.Traffic = src.Traffic
+	}
+	dst.Vars = mergeMap(dst.Vars, src.Vars)
+	dst.Templates = mergeMap(dst.Templates, src.Templates)
+	dst.TraceAttributes = mergeMap(dst.TraceAttributes, src.TraceAttributes)
+	dst.TraceState = mergeMap(dst.TraceState, src.TraceState)
+	dst.Observers = append(dst.Observers, src.Observers...)
+
+	for name, svc := range src.Services {
+		existing, ok := dst.Services[name]
+		if !ok {
+			if dst.Services == nil {
+				dst.Services = make(map[string]rawServiceConfig)
+			}
+			dst.Services[name] = svc
+			continue
+		}
+		dst.Services[name] = mergeRawService(existing, svc)
+	}
+
+	for _, sc := range src.Scenarios {
+		i := slices.IndexFunc(dst.Scenarios, func(d ScenarioConfig) bool { return d.Name == sc.Name })
+		if i >= 0 {
+			dst.Scenarios[i] = sc
+		} else {
+			dst.Scenarios = append(dst.Scenarios, sc)
+		}
+	}
+}
+
+func mergeRawService(dst, src rawServiceConfig) rawServiceConfig {
+	dst.ResourceAttributes = mergeMap(dst.ResourceAttributes, src.ResourceAttributes)
+	dst.Attributes = mergeMap(dst.Attributes, src.Attributes)
+	dst.DefaultAttributes = mergeMap(dst.DefaultAttributes, src.DefaultAttributes)
+	dst.Baggage = mergeMap(dst.Baggage, src.Baggage)
+	dst.TraceState = mergeMap(dst.TraceState, src.TraceState)
+	dst.Operations = mergeMap(dst.Operations, src.Operations)
+	if src.BaggageAsAttributes != nil {
+		dst.BaggageAsAttributes = src.BaggageAsAttributes
+	}
+	if src.Regions != nil {
+		dst.Regions = src.Regions
+	}
+	if src.ScopeName != "" {
+		dst.ScopeName = src.ScopeName
+	}
+	if src.ScopeVersion != "" {
+		dst.ScopeVersion = src.ScopeVersion
+	}
+	if len(src.Metrics) > 0 {
+		dst.Metrics = src.Metrics
+	}
+	if len(src.Logs) > 0 {
+		dst.Logs = src.Logs
+	}
+	if src.SLO != nil {
+		dst.SLO = src.SLO
+	}
+	if src.Flap != nil {
+		dst.Flap = src.Flap
+	}
+	return dst
+}
+
+// mergeMap returns a copy of dst overlaid with src.
+func mergeMap[V any](dst, src map[string]V) map[string]V {
+	if len(src) == 0 {
+		return dst
+	}
+	out := make(map[string]V, len(dst)+len(src))
+	maps.Copy(out, dst)
+	maps.Copy(out, src)
+	return out
+}
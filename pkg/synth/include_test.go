@@ -0,0 +1,359 @@
+// Tests for merging topology files via include
+package synth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFiles writes files (relative path -> content) under a temp dir
+// and returns the dir.
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return dir
+}
+
+func findService(cfg *Config, name string) *ServiceConfig {
+	for i := range cfg.Services {
+		if cfg.Services[i].Name == name {
+			return &cfg.Services[i]
+		}
+	}
+	return nil
+}
+
+func findOperation(svc *ServiceConfig, name string) *OperationConfig {
+	for i := range svc.Operations {
+		if svc.Operations[i].Name == name {
+			return &svc.Operations[i]
+		}
+	}
+	return nil
+}
+
+func TestLoadConfigInclude(t *testing.T) {
+	t.Parallel()
+
+	t.Run("services from included files and globs appear", func(t *testing.T) {
+		t.Parallel()
+		dir := writeConfigFiles(t, map[string]string{
+			"main.yaml": `
+version: 1
+include:
+  - base.yaml
+  - services/*.yaml
+traffic:
+  rate: 50/s
+`,
+			"base.yaml": `
+services:
+  gateway:
+    operations:
+      GET /:
+        duration: 10ms
+        calls: [users.list, orders.list]
+traffic:
+  rate: 10/s
+`,
+			"services/users.yaml": `
+services:
+  users:
+    operations:
+      list:
+        duration: 5ms
+`,
+			"services/orders.yaml": `
+services:
+  orders:
+    operations:
+      list:
+        duration: 7ms
+scenarios:
+  - name: slow orders
+    at: +1m
+    duration: 1m
+    override:
+      orders.list:
+        duration: 100ms
+`,
+		})
+
+		cfg, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+		require.NoError(t, err)
+		require.NoError(t, ValidateConfig(cfg))
+
+		names := make([]string, 0, len(cfg.Services))
+		for _, svc := range cfg.Services {
+			names = append(names, svc.Name)
+		}
+		assert.Equal(t, []string{"gateway", "orders", "users"}, names)
+		assert.Equal(t, "50/s", cfg.Traffic.Rate, "including file should override traffic")
+		require.Len(t, cfg.Scenarios, 1)
+		assert.Equal(t, "slow orders", cfg.Scenarios[0].Name)
+	})
+
+	t.Run("operation override takes effect", func(t *testing.T) {
+		t.Parallel()
+		dir := writeConfigFiles(t, map[string]string{
+			"main.yaml": `
+version: 1
+include: [base.yaml, overrides/prod.yaml]
+`,
+			"base.yaml": `
+services:
+  db:
+    attributes:
+      db.system: postgresql
+    operations:
+      query:
+        duration: 5ms
+      write:
+        duration: 8ms
+traffic:
+  rate: 10/s
+`,
+			"overrides/prod.yaml": `
+services:
+  db:
+    operations:
+      query:
+        duration: 50ms
+        error_rate: 1%
+`,
+		})
+
+		cfg, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+		require.NoError(t, err)
+
+		db := findService(cfg, "db")
+		require.NotNil(t, db)
+		assert.Equal(t, "postgresql", db.Attributes["db.system"], "unset fields keep earlier values")
+		query := findOperation(db, "query")
+		require.NotNil(t, query)
+		assert.Equal(t, "50ms", query.Duration)
+		assert.Equal(t, "1%", query.ErrorRate)
+		write := findOperation(db, "write")
+		require.NotNil(t, write, "operations not overridden are kept")
+		assert.Equal(t, "8ms", write.Duration)
+	})
+
+	t.Run("service slo from an override takes effect", func(t *testing.T) {
+		t.Parallel()
+		dir := writeConfigFiles(t, map[string]string{
+			"main.yaml": `
+version: 1
+include: [base.yaml, overrides/prod.yaml]
+`,
+			"base.yaml": `
+services:
+  db:
+    operations:
+      query:
+        duration: 5ms
+traffic:
+  rate: 10/s
+`,
+			"overrides/prod.yaml": `
+services:
+  db:
+    slo:
+      target: 0.999
+      window: 10m
+    operations:
+      query:
+        error_rate: 1%
+`,
+		})
+
+		cfg, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+		require.NoError(t, err)
+
+		db := findService(cfg, "db")
+		require.NotNil(t, db)
+		assert.Equal(t, &SLOConfig{Target: 0.999, Window: "10m"}, db.SLO)
+	})
+
+	t.Run("service flap from an override takes effect", func(t *testing.T) {
+		t.Parallel()
+		dir := writeConfigFiles(t, map[string]string{
+			"main.yaml": `
+version: 1
+include: [base.yaml, overrides/chaos.yaml]
+`,
+			"base.yaml": `
+services:
+  payments:
+    operations:
+      charge:
+        duration: 40ms
+traffic:
+  rate: 10/s
+`,
+			"overrides/chaos.yaml": `
+services:
+  payments:
+    flap:
+      up_duration: 5m
+      down_duration: 30s
+      jitter: 10s
+`,
+		})
+
+		cfg, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+		require.NoError(t, err)
+		require.NoError(t, ValidateConfig(cfg))
+
+		payments := findService(cfg, "payments")
+		require.NotNil(t, payments)
+		assert.Equal(t, &FlapConfig{UpDuration: "5m", DownDuration: "30s", Jitter: "10s"}, payments.Flap)
+		assert.NotNil(t, findOperation(payments, "charge"), "operations from the base file are kept")
+	})
+
+	t.Run("ambiguous glob merge rejected", func(t *testing.T) {
+		t.Parallel()
+		dir := writeConfigFiles(t, map[string]string{
+			"main.yaml": `
+version: 1
+include: [services/*.yaml]
+traffic:
+  rate: 10/s
+`,
+			"services/a.yaml": `
+services:
+  users:
+    operations:
+      list:
+        duration: 5ms
+`,
+			"services/b.yaml": `
+services:
+  users:
+    operations:
+      get:
+        duration: 5ms
+`,
+		})
+
+		_, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `service "users" is defined in both`)
+	})
+
+	t.Run("include cycle rejected", func(t *testing.T) {
+		t.Parallel()
+		dir := writeConfigFiles(t, map[string]string{
+			"a.yaml": "version: 1\ninclude: [b.yaml]\n",
+			"b.yaml": "include: [a.yaml]\n",
+		})
+
+		_, err := LoadConfig(filepath.Join(dir, "a.yaml"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "include cycle")
+	})
+
+	t.Run("glob matching nothing rejected", func(t *testing.T) {
+		t.Parallel()
+		dir := writeConfigFiles(t, map[string]string{
+			"main.yaml": "version: 1\ninclude: [missing/*.yaml]\n",
+		})
+
+		_, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no files match")
+	})
+
+	t.Run("missing include file rejected", func(t *testing.T) {
+		t.Parallel()
+		dir := writeConfigFiles(t, map[string]string{
+			"main.yaml": "version: 1\ninclude: [nope.yaml]\n",
+		})
+
+		_, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "nope.yaml")
+	})
+}
+
+func TestLoadConfigIncludeResolvesValuesFiles(t *testing.T) {
+	t.Parallel()
+	dir := writeConfigFiles(t, map[string]string{
+		"main.yaml": `
+version: 1
+include: [services/users.yaml]
+traffic:
+  rate: 10/s
+`,
+		"services/users.yaml": `
+services:
+  users:
+    operations:
+      login:
+        duration: 10ms
+        attributes:
+          user.name: {file: names.txt}
+`,
+		"services/names.txt": "alice\n",
+	})
+
+	cfg, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+	op := findOperation(findService(cfg, "users"), "login")
+	require.NotNil(t, op)
+	assert.Equal(t, filepath.Join(dir, "services", "names.txt"), op.Attributes["user.name"].File)
+}
+
+func TestLoadConfigResolvesValuesFilesAfterVars(t *testing.T) {
+	t.Parallel()
+	data := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(data, "names.txt"), []byte("alice\n"), 0o600))
+	dir := writeConfigFiles(t, map[string]string{
+		"main.yaml": `
+version: 1
+include: [services/users.yaml]
+vars:
+  data: ` + data + `
+  lists: lists
+traffic:
+  rate: 10/s
+`,
+		"services/users.yaml": `
+services:
+  users:
+    operations:
+      login:
+        duration: 10ms
+        attributes:
+          user.name: {file: "{{ vars.data }}/names.txt"}
+          user.role: {file: "{{ vars.lists }}/roles.txt"}
+`,
+		"services/lists/roles.txt": "admin\n",
+	})
+
+	cfg, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+	op := findOperation(findService(cfg, "users"), "login")
+	require.NotNil(t, op)
+	assert.Equal(t, filepath.Join(data, "names.txt"), op.Attributes["user.name"].File, "an absolute path from a var is kept")
+	assert.Equal(t, filepath.Join(dir, "services", "lists", "roles.txt"), op.Attributes["user.role"].File,
+		"a relative path from a var resolves against the file that names it")
+}
+
+func TestParseConfigRejectsInclude(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseConfig([]byte("version: 1\ninclude: [base.yaml]\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "include is only supported")
+}
@@ -0,0 +1,158 @@
+// Log body formats: structured renderings of log record bodies for exercising log parsers
+// JSON, logfmt, or a Go template over the record's time, level, message, span IDs, and attributes
+package synth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Log body format names accepted by ParseLogBodyFormat. Any other value
+// containing "{{" is parsed as a Go template.
+const (
+	LogFormatPlain  = "plain"
+	LogFormatJSON   = "json"
+	LogFormatLogfmt = "logfmt"
+)
+
+// LogBodyFields is what a formatted log body is rendered from, and the data
+// a Go template log format is executed with. TraceID and SpanID are empty
+// when the record has no span.
+type LogBodyFields struct {
+	Time       time.Time
+	Level      string
+	Message    string
+	Service    string
+	Operation  string
+	TraceID    string
+	SpanID     string
+	Attributes map[string]any
+}
+
+// LogBodyFormat renders log record bodies in a structured format. The nil
+// format leaves bodies as plain messages.
+type LogBodyFormat struct {
+	render func(LogBodyFields) (string, error)
+}
+
+// ParseLogBodyFormat returns the format named by format: plain (or empty),
+// json, logfmt, or a Go template such as "{{.Level}} {{.Message}}". A
+// template is checked by executing it once against empty fields.
+func ParseLogBodyFormat(format string) (*LogBodyFormat, error) {
+	switch format {
+	case "", LogFormatPlain:
+		return nil, nil
+	case LogFormatJSON:
+		return &LogBodyFormat{render: renderJSONBody}, nil
+	case LogFormatLogfmt:
+		return &LogBodyFormat{render: renderLogfmtBody}, nil
+	}
+	if !strings.Contains(format, "{{") {
+		return nil, fmt.Errorf("log format must be %s, %s, %s, or a Go template, got %q", LogFormatPlain, LogFormatJSON, LogFormatLogfmt, format)
+	}
+	tmpl, err := template.New("log").Option("missingkey=zero").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("log format template: %w", err)
+	}
+	render := func(fields LogBodyFields) (string, error) {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, fields); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	if _, err := render(LogBodyFields{}); err != nil {
+		return nil, fmt.Errorf("log format template: %w", err)
+	}
+	return &LogBodyFormat{render: render}, nil
+}
+
+// body returns the record body for fields: the plain message for the nil
+// format, or when rendering fails.
+func (f *LogBodyFormat) body(fields LogBodyFields) string {
+	if f == nil {
+		return fields.Message
+	}
+	body, err := f.render(fields)
+	if err != nil {
+		return fields.Message
+	}
+	return body
+}
+
+// logBodyField is one key and value of a structured log body.
+type logBodyField struct {
+	key   string
+	value any
+}
+
+// orderedFields lists fields in the order structured formats write them:
+// the fixed fields first, then the attributes sorted by key. Empty span IDs
+// are left out.
+func (fields LogBodyFields) orderedFields() []logBodyField {
+	out := []logBodyField{
+		{"time", fields.Time.UTC().Format(time.RFC3339Nano)},
+		{"level", fields.Level},
+		{"msg", fields.Message},
+		{"service.name", fields.Service},
+		{"operation.name", fields.Operation},
+	}
+	if fields.TraceID != "" {
+		out = append(out, logBodyField{"trace_id", fields.TraceID}, logBodyField{"span_id", fields.SpanID})
+	}
+	for _, k := range slices.Sorted(maps.Keys(fields.Attributes)) {
+		out = append(out, logBodyField{k, fields.Attributes[k]})
+	}
+	return out
+}
+
+// renderJSONBody writes fields as a single-line JSON object, keeping the
+// order of orderedFields.
+func renderJSONBody(fields LogBodyFields) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range fields.orderedFields() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return "", err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return "", err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.String(), nil
+}
+
+// renderLogfmtBody writes fields as logfmt key=value pairs, quoting values
+// that are empty or contain spaces, quotes, or equals signs.
+func renderLogfmtBody(fields LogBodyFields) (string, error) {
+	var b strings.Builder
+	for i, f := range fields.orderedFields() {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		value := fmt.Sprint(f.value)
+		if value == "" || strings.ContainsAny(value, " =\"\t\n\\") {
+			value = strconv.Quote(value)
+		}
+		b.WriteString(f.key)
+		b.WriteByte('=')
+		b.WriteString(value)
+	}
+	return b.String(), nil
+}
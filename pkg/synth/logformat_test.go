@@ -0,0 +1,68 @@
+// Tests for log body formats: parsing format names and templates, and the
+// JSON, logfmt, and template renderings
+package synth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogBodyFields() LogBodyFields {
+	return LogBodyFields{
+		Time:       time.Date(2024, 5, 1, 12, 0, 0, 500_000_000, time.UTC),
+		Level:      "WARN",
+		Message:    "slow query",
+		Service:    "db",
+		Operation:  "query",
+		TraceID:    "0102030405060708090a0b0c0d0e0f10",
+		SpanID:     "1112131415161718",
+		Attributes: map[string]any{"db.rows": int64(7), "db.table": "orders"},
+	}
+}
+
+func TestParseLogBodyFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"", LogFormatPlain} {
+		format, err := ParseLogBodyFormat(name)
+		require.NoError(t, err)
+		assert.Nil(t, format)
+		assert.Equal(t, "slow query", format.body(testLogBodyFields()), "plain bodies are the message")
+	}
+
+	_, err := ParseLogBodyFormat("yaml")
+	assert.ErrorContains(t, err, `log format must be plain, json, logfmt, or a Go template, got "yaml"`)
+	_, err = ParseLogBodyFormat("{{.Level")
+	assert.ErrorContains(t, err, "log format template:")
+	_, err = ParseLogBodyFormat("{{.Severity}}")
+	assert.ErrorContains(t, err, "log format template:", "unknown fields are caught up front")
+}
+
+func TestLogBodyFormats(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		format string
+		want   string
+	}{
+		{LogFormatJSON, `{"time":"2024-05-01T12:00:00.5Z","level":"WARN","msg":"slow query","service.name":"db","operation.name":"query","trace_id":"0102030405060708090a0b0c0d0e0f10","span_id":"1112131415161718","db.rows":7,"db.table":"orders"}`},
+		{LogFormatLogfmt, `time=2024-05-01T12:00:00.5Z level=WARN msg="slow query" service.name=db operation.name=query trace_id=0102030405060708090a0b0c0d0e0f10 span_id=1112131415161718 db.rows=7 db.table=orders`},
+		{`{{.Level}} [{{.TraceID}}] {{.Message}} rows={{index .Attributes "db.rows"}}`, `WARN [0102030405060708090a0b0c0d0e0f10] slow query rows=7`},
+	} {
+		format, err := ParseLogBodyFormat(tc.format)
+		require.NoError(t, err, tc.format)
+		assert.Equal(t, tc.want, format.body(testLogBodyFields()), tc.format)
+	}
+}
+
+func TestLogBodyFormatLogfmtQuoting(t *testing.T) {
+	t.Parallel()
+
+	format, err := ParseLogBodyFormat(LogFormatLogfmt)
+	require.NoError(t, err)
+	fields := LogBodyFields{Level: "INFO", Message: `say "hi" a=b`, Service: "svc", Operation: ""}
+	assert.Equal(t, `time=0001-01-01T00:00:00Z level=INFO msg="say \"hi\" a=b" service.name=svc operation.name=""`, format.body(fields))
+}
@@ -53,6 +53,11 @@ type LogObserver struct {
 	serviceNames  map[string]bool // for disambiguating override refs containing dots
 	rng           *rand.Rand
 	mu            sync.Mutex
+	format        *LogBodyFormat // nil leaves bodies as plain messages
+
+	// opSlowThresholds holds operation slow_threshold values keyed by
+	// operation ref; they replace slowThreshold for those operations.
+	opSlowThresholds map[string]time.Duration
 
 	overrideMu   sync.RWMutex
 	addTemplates map[string][]logTemplate // scenario-added templates keyed by service
@@ -63,6 +68,7 @@ type LogObserver struct {
 // Each logger should come from a LoggerProvider whose resource has the correct service.name.
 // Services that define no topology logs emit derived ERROR logs for error spans
 // and WARN logs for spans exceeding slowThreshold (0 disables slow detection).
+// An operation's own slow_threshold takes precedence over slowThreshold.
 // A nil topo disables topology logs entirely; a nil rng creates a new source.
 func NewLogObserver(loggers map[string]log.Logger, topo *Topology, slowThreshold time.Duration, rng *rand.Rand) (*LogObserver, error) {
 	if rng == nil {
@@ -71,9 +77,15 @@ func NewLogObserver(loggers map[string]log.Logger, topo *Topology, slowThreshold
 
 	templates := make(map[string][]logTemplate)
 	serviceNames := make(map[string]bool)
+	opSlowThresholds := make(map[string]time.Duration)
 	if topo != nil {
-		for svcName := range topo.Services {
+		for svcName, svc := range topo.Services {
 			serviceNames[svcName] = true
+			for _, op := range svc.Operations {
+				if op.SlowThreshold > 0 {
+					opSlowThresholds[op.Ref] = op.SlowThreshold
+				}
+			}
 		}
 		for svcName, svc := range topo.Services {
 			var tpls []logTemplate
@@ -102,14 +114,51 @@ func NewLogObserver(loggers map[string]log.Logger, topo *Topology, slowThreshold
 	}
 
 	return &LogObserver{
-		loggers:       loggers,
-		slowThreshold: slowThreshold,
-		templates:     templates,
-		serviceNames:  serviceNames,
-		rng:           rng,
+		loggers:          loggers,
+		slowThreshold:    slowThreshold,
+		opSlowThresholds: opSlowThresholds,
+		templates:        templates,
+		serviceNames:     serviceNames,
+		rng:              rng,
 	}, nil
 }
 
+// SetBodyFormat renders the bodies of records emitted from then on in
+// format; nil restores plain message bodies.
+func (l *LogObserver) SetBodyFormat(format *LogBodyFormat) {
+	l.format = format
+}
+
+// body returns the body of a record for the span: message itself, or
+// message formatted with the record's time, level, span IDs, and attrs.
+func (l *LogObserver) body(message string, timestamp time.Time, level string, info SpanInfo, attrs map[string]any) string {
+	if l.format == nil {
+		return message
+	}
+	fields := LogBodyFields{
+		Time:       timestamp,
+		Level:      level,
+		Message:    message,
+		Service:    info.Service,
+		Operation:  info.Operation,
+		Attributes: attrs,
+	}
+	if info.SpanContext.IsValid() {
+		fields.TraceID = info.SpanContext.TraceID().String()
+		fields.SpanID = info.SpanContext.SpanID().String()
+	}
+	return l.format.body(fields)
+}
+
+// slowThresholdFor returns the slow threshold for the span's operation: its
+// own slow_threshold when set, otherwise the observer's.
+func (l *LogObserver) slowThresholdFor(info SpanInfo) time.Duration {
+	if d, ok := l.opSlowThresholds[info.Service+"."+info.Operation]; ok {
+		return d
+	}
+	return l.slowThreshold
+}
+
 // SetOverrides replaces the active scenario log overrides. The engine calls
 // this as scenario windows open and close; a nil map clears all overrides.
 // Added log definitions are pre-built into templates here so the per-span
@@ -225,7 +274,7 @@ func (l *LogObserver) emitTemplate(ctx context.Context, logger log.Logger, tpl *
 			return
 		}
 	case logConditionSlow:
-		if l.slowThreshold <= 0 || info.Duration <= l.slowThreshold {
+		if threshold := l.slowThresholdFor(info); threshold <= 0 || info.Duration <= threshold {
 			return
 		}
 	}
@@ -258,7 +307,7 @@ func (l *LogObserver) emitTemplate(ctx context.Context, logger log.Logger, tpl *
 	rec.SetTimestamp(timestamp)
 	rec.SetSeverity(tpl.severity)
 	rec.SetSeverityText(tpl.severityText)
-	rec.SetBody(log.StringValue(interpolateBody(tpl.body, attrValues, info)))
+	rec.SetBody(log.StringValue(l.body(interpolateBody(tpl.body, attrValues, info), timestamp, tpl.severityText, info, attrValues)))
 	rec.AddAttributes(attrs...)
 	logger.Emit(ctx, rec)
 }
@@ -275,20 +324,19 @@ func (l *LogObserver) emitDerived(ctx context.Context, logger log.Logger, info S
 		rec.SetTimestamp(info.Timestamp)
 		rec.SetSeverity(log.SeverityError)
 		rec.SetSeverityText(logSeverityError)
-		rec.SetBody(log.StringValue(fmt.Sprintf("error in %s %s", info.Service, info.Operation)))
+		rec.SetBody(log.StringValue(l.body(fmt.Sprintf("error in %s %s", info.Service, info.Operation), info.Timestamp, logSeverityError, info, nil)))
 		rec.AddAttributes(attrs...)
 		logger.Emit(ctx, rec)
 	}
 
-	if l.slowThreshold > 0 && info.Duration > l.slowThreshold {
+	if threshold := l.slowThresholdFor(info); threshold > 0 && info.Duration > threshold {
 		var rec log.Record
 		rec.SetTimestamp(info.Timestamp)
 		rec.SetSeverity(log.SeverityWarn)
 		rec.SetSeverityText(logSeverityWarn)
-		rec.SetBody(log.StringValue(fmt.Sprintf(
-			"slow operation %s %s: %s (threshold %s)",
-			info.Service, info.Operation, info.Duration, l.slowThreshold,
-		)))
+		message := fmt.Sprintf("slow operation %s %s: %s (threshold %s)",
+			info.Service, info.Operation, info.Duration, threshold)
+		rec.SetBody(log.StringValue(l.body(message, info.Timestamp, logSeverityWarn, info, nil)))
 		rec.AddAttributes(attrs...)
 		logger.Emit(ctx, rec)
 	}
@@ -5,6 +5,7 @@ package synth
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
 	"testing"
 	"time"
@@ -460,6 +461,51 @@ func TestLogObserverTraceCorrelation(t *testing.T) {
 	assert.Equal(t, spanID, records[0].SpanID(), "log record should carry the span's span ID")
 }
 
+func TestLogObserverBodyFormatJSON(t *testing.T) {
+	t.Parallel()
+
+	gen, err := NewAttributeGenerator(AttributeValueConfig{Range: []int64{42, 42}})
+	require.NoError(t, err)
+	def := alwaysLog("INFO", "processed order")
+	def.Attributes = NewAttributes(map[string]AttributeGenerator{"order.items": gen})
+	topo := testLogTopology("svc", []LogDefinition{def}, "op", nil)
+	obs, exporter := newTestLogObserver(t, topo, 0, "svc", "plain")
+	format, err := ParseLogBodyFormat(LogFormatJSON)
+	require.NoError(t, err)
+	obs.SetBodyFormat(format)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10},
+		SpanID:     trace.SpanID{0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	obs.Observe(SpanInfo{Service: "svc", Operation: "op", Timestamp: ts, SpanContext: sc})
+	obs.Observe(SpanInfo{Service: "plain", Operation: "get", Timestamp: ts, IsError: true})
+
+	records := exporter.get()
+	require.Len(t, records, 2)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal([]byte(records[0].Body().AsString()), &body), records[0].Body().AsString())
+	assert.Equal(t, map[string]any{
+		"time":           "2024-05-01T12:00:00Z",
+		"level":          "INFO",
+		"msg":            "processed order",
+		"service.name":   "svc",
+		"operation.name": "op",
+		"trace_id":       "0102030405060708090a0b0c0d0e0f10",
+		"span_id":        "1112131415161718",
+		"order.items":    float64(42),
+	}, body)
+
+	// Derived logs are formatted too; a span without a context has no IDs.
+	body = nil
+	require.NoError(t, json.Unmarshal([]byte(records[1].Body().AsString()), &body), records[1].Body().AsString())
+	assert.Equal(t, "ERROR", body["level"])
+	assert.Equal(t, "error in plain get", body["msg"])
+	assert.NotContains(t, body, "trace_id")
+}
+
 func TestLogObserverDerivedTraceCorrelation(t *testing.T) {
 	t.Parallel()
 
@@ -712,3 +758,22 @@ func TestLogObserverTopologyOtherServiceKeepsDerived(t *testing.T) {
 	assert.Equal(t, otellog.SeverityError, records[0].Severity())
 	assert.Contains(t, records[0].Body().AsString(), "error in backend query")
 }
+
+func TestLogObserverOperationSlowThreshold(t *testing.T) {
+	t.Parallel()
+
+	topo := testLogTopology("backend", nil, "query", nil)
+	topo.Services["backend"].Operations["query"].SlowThreshold = 20 * time.Millisecond
+	other := &Operation{Service: topo.Services["backend"], Name: "scan", Ref: "backend.scan"}
+	topo.Services["backend"].Operations["scan"] = other
+
+	obs, exporter := newTestLogObserver(t, topo, time.Second, "backend")
+
+	obs.Observe(SpanInfo{Service: "backend", Operation: "query", Duration: 50 * time.Millisecond})
+	obs.Observe(SpanInfo{Service: "backend", Operation: "scan", Duration: 50 * time.Millisecond})
+
+	records := exporter.get()
+	require.Len(t, records, 1, "only the operation with its own lower threshold is slow")
+	assert.Equal(t, otellog.SeverityWarn, records[0].Severity())
+	assert.Equal(t, "slow operation backend query: 50ms (threshold 20ms)", records[0].Body().AsString())
+}
@@ -39,11 +39,27 @@ type MetricObserver struct {
 	intervals []metricInstrument // emitted on their own timers, not per span
 	rng       *rand.Rand
 	mu        sync.Mutex
+	// burn tracks error budget burn for each service with an SLO.
+	burn map[string]*burnWindow
 
 	overrideMu sync.RWMutex
 	overrides  map[string]Override // active scenario overrides, set by the engine
+
+	// scenarioActivations counts scenario windows opening; nil until
+	// EnableScenarioActivations is called.
+	scenarioActivations metric.Int64Counter
 }
 
+// ScenarioActivationsMetric is the counter a MetricObserver increments each
+// time a scenario window becomes active, once EnableScenarioActivations has
+// been called. Each data point carries the scenario's name in the
+// ScenarioNameAttribute attribute.
+const ScenarioActivationsMetric = "synth.scenario.activations"
+
+// ScenarioNameAttribute names the scenario a ScenarioActivationsMetric data
+// point counts.
+const ScenarioNameAttribute = "synth.scenario"
+
 // NewMetricObserver creates a MetricObserver from topology metric definitions.
 // Each meter should carry a resource with the correct service.name for its service.
 func NewMetricObserver(meters map[string]metric.Meter, topo *Topology, rng *rand.Rand) (*MetricObserver, error) {
@@ -93,11 +109,54 @@ func NewMetricObserver(meters map[string]metric.Meter, topo *Topology, rng *rand
 		if len(instruments) > 0 {
 			m.services[svcName] = instruments
 		}
+
+		if svc.SLO != nil {
+			w := newBurnWindow(svc.SLO)
+			_, err := meter.Float64ObservableGauge(SLOBurnRateMetric,
+				metric.WithUnit("1"),
+				metric.WithDescription("Error rate over the SLO window as a multiple of the error budget"),
+				metric.WithFloat64Callback(func(_ context.Context, obs metric.Float64Observer) error {
+					obs.Observe(w.burnRate())
+					return nil
+				}),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if m.burn == nil {
+				m.burn = make(map[string]*burnWindow)
+			}
+			m.burn[svcName] = w
+		}
 	}
 
 	return m, nil
 }
 
+// EnableScenarioActivations creates the ScenarioActivationsMetric counter on
+// meter, so that scenario windows opening are counted from then on. Scenarios
+// are not tied to a service, so the caller chooses the meter.
+func (m *MetricObserver) EnableScenarioActivations(meter metric.Meter) error {
+	counter, err := meter.Int64Counter(ScenarioActivationsMetric,
+		metric.WithUnit("{activation}"),
+		metric.WithDescription("Number of times a scenario window became active"),
+	)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", ScenarioActivationsMetric, err)
+	}
+	m.scenarioActivations = counter
+	return nil
+}
+
+// ObserveScenarioStart counts a scenario window becoming active, when
+// scenario activations are enabled.
+func (m *MetricObserver) ObserveScenarioStart(name string) {
+	if m.scenarioActivations == nil {
+		return
+	}
+	m.scenarioActivations.Add(context.Background(), 1, metric.WithAttributes(attribute.String(ScenarioNameAttribute, name)))
+}
+
 // SetOverrides replaces the active scenario overrides. The engine calls this
 // as scenario windows open and close; a nil map clears all overrides.
 func (m *MetricObserver) SetOverrides(overrides map[string]Override) {
@@ -353,6 +412,10 @@ func buildMetricAttrs(attrGens Attributes, operation string, rng *rand.Rand) met
 // PeriodicReader. The Metrics API does not support caller-supplied timestamps;
 // time offsets are applied at export time by NewTimeOffsetMetricExporter.
 func (m *MetricObserver) Observe(info SpanInfo) {
+	if w := m.burn[info.Service]; w != nil {
+		w.record(info.Timestamp.Add(info.Duration), info.IsError)
+	}
+
 	instruments := m.services[info.Service]
 	if len(instruments) == 0 {
 		return
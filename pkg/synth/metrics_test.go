@@ -869,3 +869,166 @@ func TestMetricObserverStartNoIntervals(t *testing.T) {
 	stop := obs.Start()
 	stop() // must not panic or block
 }
+
+func burnRate(t *testing.T, reader *sdkmetric.ManualReader) float64 {
+	t.Helper()
+	m := findMetric(collectMetrics(t, reader), SLOBurnRateMetric)
+	require.NotNil(t, m, "burn rate gauge must be present")
+	gauge, ok := m.Data.(metricdata.Gauge[float64])
+	require.True(t, ok)
+	require.Len(t, gauge.DataPoints, 1)
+	return gauge.DataPoints[0].Value
+}
+
+func TestMetricObserverSLOBurnRateWindow(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = mp.Shutdown(context.Background()) })
+
+	topo := testTopology("svc", nil, "op", nil)
+	topo.Services["svc"].SLO = &ResolvedSLO{Target: 0.9, Window: time.Minute}
+	obs, err := NewMetricObserver(testMeters(mp, "svc"), topo, testRng())
+	require.NoError(t, err)
+
+	assert.Zero(t, burnRate(t, reader), "no spans, no burn")
+
+	start := time.Unix(1_700_000_000, 0)
+	for i := range 100 {
+		obs.Observe(SpanInfo{Service: "svc", Operation: "op", Timestamp: start, IsError: i < 20})
+	}
+	assert.InDelta(t, 2.0, burnRate(t, reader), 1e-9, "20% errors against a 10% budget")
+
+	// Once the window has slid past the errors, only the new spans count.
+	later := start.Add(2 * time.Minute)
+	for range 10 {
+		obs.Observe(SpanInfo{Service: "svc", Operation: "op", Timestamp: later})
+	}
+	assert.Zero(t, burnRate(t, reader))
+
+	// A straggler from before the window is ignored.
+	obs.Observe(SpanInfo{Service: "svc", Operation: "op", Timestamp: start, IsError: true})
+	assert.Zero(t, burnRate(t, reader))
+}
+
+func TestMetricObserverSLOBurnRateRisesWithScenario(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    slo:
+      target: 0.99
+      window: 10s
+    operations:
+      get:
+        duration: 1ms
+        error_rate: 1%
+traffic:
+  rate: 500/s
+scenarios:
+  - name: error spike
+    at: +30s
+    duration: 1h
+    override:
+      api.get:
+        error_rate: 20%
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	engine, _, _ := newTestEngine(t, cfg)
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = mp.Shutdown(context.Background()) })
+	obs, err := NewMetricObserver(testMeters(mp, "api"), engine.Topology, testRng())
+	require.NoError(t, err)
+	engine.Observers = []SpanObserver{obs}
+
+	// Walk traces every 2ms of simulated time rather than pacing a real run.
+	base := time.Unix(1_700_000_000, 0)
+	walk := func(from, to time.Duration) {
+		for elapsed := from; elapsed < to; elapsed += 2 * time.Millisecond {
+			overrides := ResolveOverrides(ActiveScenarios(engine.Scenarios, elapsed))
+			engine.walkTrace(context.Background(), engine.Topology.Roots[0], nil, base.Add(elapsed), elapsed, overrides, nil, &Stats{}, new(int), DefaultMaxSpansPerTrace, false, false)
+		}
+	}
+
+	walk(0, 30*time.Second)
+	before := burnRate(t, reader)
+	walk(30*time.Second, time.Minute)
+	during := burnRate(t, reader)
+
+	assert.InDelta(t, 1.0, before, 0.5, "a 1%% error rate spends a 99%% SLO's budget at rate 1")
+	assert.InDelta(t, 20.0, during, 3.0, "a 20%% error rate burns the budget 20 times over")
+	assert.Greater(t, during, before)
+}
+
+func TestMetricObserverScenarioActivations(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      get:
+        duration: 1ms
+traffic:
+  rate: 1/s
+scenarios:
+  - name: outage
+    at: +1m
+    duration: 1m
+  - name: outage
+    at: +5m
+    duration: 1m
+  - name: deploy
+    at: +3m
+    duration: 30s
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+
+	engine, _, _ := newTestEngine(t, cfg)
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = mp.Shutdown(context.Background()) })
+	obs, err := NewMetricObserver(testMeters(mp, "api"), engine.Topology, testRng())
+	require.NoError(t, err)
+	require.NoError(t, obs.EnableScenarioActivations(mp.Meter("motel")))
+	engine.Observers = []SpanObserver{obs}
+	engine.Backfill = true
+	engine.Duration = 10 * time.Minute
+
+	_, err = engine.Run(t.Context())
+	require.NoError(t, err)
+
+	m := findMetric(collectMetrics(t, reader), ScenarioActivationsMetric)
+	require.NotNil(t, m, "scenario activations counter must be present")
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	assert.True(t, sum.IsMonotonic)
+	got := make(map[string]int64)
+	for _, dp := range sum.DataPoints {
+		name, _ := dp.Attributes.Value(ScenarioNameAttribute)
+		got[name.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{"outage": 2, "deploy": 1}, got, "each scenario window counts once as it opens")
+}
+
+func TestMetricObserverScenarioActivationsDisabled(t *testing.T) {
+	t.Parallel()
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	t.Cleanup(func() { _ = mp.Shutdown(context.Background()) })
+	topo := testTopology("api", nil, "get", nil)
+	obs, err := NewMetricObserver(testMeters(mp, "api"), topo, testRng())
+	require.NoError(t, err)
+
+	obs.ObserveScenarioStart("outage")
+	assert.Nil(t, findMetric(collectMetrics(t, reader), ScenarioActivationsMetric), "the counter is opt-in")
+}
@@ -3,6 +3,7 @@
 package synth
 
 import (
+	"slices"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -26,7 +27,11 @@ type SpanInfo struct {
 	SpanContext     trace.SpanContext
 }
 
-// SpanObserver receives span metadata after each span is emitted.
+// SpanObserver receives span metadata after each span is emitted. Library
+// code adds its own observers to Engine.Observers or GenerateOptions.Observers,
+// or registers a type with RegisterObserver so configs can declare it. An
+// observer may also implement SpanStartObserver, PlanEventObserver, or
+// OverrideObserver to receive more of the run.
 type SpanObserver interface {
 	Observe(info SpanInfo)
 }
@@ -50,14 +55,17 @@ func notifySpanStart(observers []SpanObserver, service, operation string) {
 const (
 	PlanEventTimeout            = "timeout"
 	PlanEventRetry              = "retry"
+	PlanEventHedge              = "hedge"
 	PlanEventQueueRejection     = "queue_rejection"
 	PlanEventCircuitBreakerTrip = "circuit_breaker_trip"
+	PlanEventRateLimitRejection = "rate_limit_rejection"
+	PlanEventUnavailable        = "unavailable_rejection"
 )
 
 // PlanEvent describes a plan-phase decision made during trace generation.
-// These decisions (timeouts, retries, queue rejections, circuit breaker
-// trips) are simulation ground truth that does not appear as distinct
-// records in the emitted telemetry.
+// These decisions (timeouts, retries, hedges, queue rejections, circuit breaker
+// trips, rate limit rejections, flap rejections) are simulation ground truth that does not
+// appear as distinct records in the emitted telemetry.
 type PlanEvent struct {
 	Kind      string
 	Service   string
@@ -98,6 +106,29 @@ func notifyOverrides(observers []SpanObserver, overrides map[string]Override) {
 	}
 }
 
+// ScenarioObserver receives notification when a scenario window becomes
+// active. Observers that report scenario activity (e.g. the scenario
+// activations counter) implement this.
+type ScenarioObserver interface {
+	ObserveScenarioStart(name string)
+}
+
+// notifyScenarioStarts dispatches ObserveScenarioStart to all observers that
+// implement ScenarioObserver, once for each scenario in active that was not
+// in previous.
+func notifyScenarioStarts(observers []SpanObserver, active, previous []Scenario) {
+	for _, sc := range active {
+		if slices.ContainsFunc(previous, func(p Scenario) bool { return p.Name == sc.Name && p.Start == sc.Start && p.End == sc.End }) {
+			continue
+		}
+		for _, obs := range observers {
+			if so, ok := obs.(ScenarioObserver); ok {
+				so.ObserveScenarioStart(sc.Name)
+			}
+		}
+	}
+}
+
 // newSpanInfo constructs a SpanInfo from its component fields.
 // parentService and parentOperation are empty for root spans.
 func newSpanInfo(service, operation, parentService, parentOperation string, timestamp time.Time, duration time.Duration, isError bool, kind trace.SpanKind, attrs []attribute.KeyValue, scenarios []string, spanCtx trace.SpanContext) SpanInfo {
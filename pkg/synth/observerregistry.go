@@ -0,0 +1,118 @@
+// Observer registry: SpanObservers built from the observers: section of a config
+// Library users register their own observer types with RegisterObserver
+package synth
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ObserverConfig declares a span observer for a run. Type names a registered
+// observer; Options are passed to its factory.
+type ObserverConfig struct {
+	Type    string            `yaml:"type"`
+	Options map[string]string `yaml:"options,omitempty"`
+}
+
+// ObserverFactory builds a SpanObserver from the options of an observers:
+// entry. Observers that hold resources, such as an open file, implement
+// io.Closer; Generation.Close closes them once the run ends.
+type ObserverFactory func(options map[string]string, topo *Topology) (SpanObserver, error)
+
+var (
+	observerRegistryMu sync.RWMutex
+	observerRegistry   = map[string]ObserverFactory{
+		SpanCountObserverType: newSpanCountObserverFromOptions,
+	}
+)
+
+// RegisterObserver makes an observer type available to the observers:
+// section of a config. It must be called before the config is validated,
+// typically from an init function, and returns an error if name is empty
+// or already registered.
+//
+// Code that builds its own Engine does not need the registry: it can append
+// any SpanObserver to Engine.Observers or GenerateOptions.Observers directly.
+func RegisterObserver(name string, factory ObserverFactory) error {
+	if name == "" {
+		return fmt.Errorf("observer type name must not be empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("observer type %q: factory must not be nil", name)
+	}
+	observerRegistryMu.Lock()
+	defer observerRegistryMu.Unlock()
+	if _, ok := observerRegistry[name]; ok {
+		return fmt.Errorf("observer type %q is already registered", name)
+	}
+	observerRegistry[name] = factory
+	return nil
+}
+
+// lookupObserver returns the factory registered for name.
+func lookupObserver(name string) (ObserverFactory, bool) {
+	observerRegistryMu.RLock()
+	defer observerRegistryMu.RUnlock()
+	factory, ok := observerRegistry[name]
+	return factory, ok
+}
+
+// registeredObservers returns the registered observer type names, sorted.
+func registeredObservers() []string {
+	observerRegistryMu.RLock()
+	defer observerRegistryMu.RUnlock()
+	return slices.Sorted(maps.Keys(observerRegistry))
+}
+
+// validateObserverConfigs checks that every observers: entry names a
+// registered type. Options are checked by the factory when the observer is
+// built.
+func validateObserverConfigs(cfgs []ObserverConfig) error {
+	for i, oc := range cfgs {
+		if oc.Type == "" {
+			return fmt.Errorf("observers[%d]: type is required", i)
+		}
+		if _, ok := lookupObserver(oc.Type); !ok {
+			return fmt.Errorf("observers[%d]: unknown observer type %q (registered: %s)", i, oc.Type, strings.Join(registeredObservers(), ", "))
+		}
+	}
+	return nil
+}
+
+// BuildObservers builds the observers declared in cfgs for topo. If one
+// fails, those already built are closed before the error is returned.
+func BuildObservers(cfgs []ObserverConfig, topo *Topology) ([]SpanObserver, error) {
+	observers := make([]SpanObserver, 0, len(cfgs))
+	for i, oc := range cfgs {
+		factory, ok := lookupObserver(oc.Type)
+		if !ok {
+			closeObservers(observers) //nolint:errcheck // reporting the build error instead
+			return nil, fmt.Errorf("observers[%d]: unknown observer type %q", i, oc.Type)
+		}
+		obs, err := factory(oc.Options, topo)
+		if err != nil {
+			closeObservers(observers) //nolint:errcheck // reporting the build error instead
+			return nil, fmt.Errorf("observers[%d]: %s: %w", i, oc.Type, err)
+		}
+		observers = append(observers, obs)
+	}
+	return observers, nil
+}
+
+// closeObservers closes every observer that implements io.Closer and
+// returns the first error.
+func closeObservers(observers []SpanObserver) error {
+	var first error
+	for _, obs := range observers {
+		if c, ok := obs.(io.Closer); ok {
+			if err := c.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
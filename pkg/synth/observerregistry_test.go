@@ -0,0 +1,119 @@
+// Tests for the observer registry and the span count observer
+package synth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func observerTestConfig(t *testing.T, observers string) *Config {
+	t.Helper()
+	cfg, err := ParseConfig([]byte(generateTestConfig + observers))
+	require.NoError(t, err)
+	return cfg
+}
+
+// countingObserver counts observed spans in memory.
+type countingObserver struct {
+	count atomic.Int64
+}
+
+func (c *countingObserver) Observe(SpanInfo) {
+	c.count.Add(1)
+}
+
+// registerTestObserver registers factory as name for the duration of t.
+func registerTestObserver(t *testing.T, name string, factory ObserverFactory) {
+	t.Helper()
+	require.NoError(t, RegisterObserver(name, factory))
+	t.Cleanup(func() {
+		observerRegistryMu.Lock()
+		defer observerRegistryMu.Unlock()
+		delete(observerRegistry, name)
+	})
+}
+
+func TestRegisterObserver(t *testing.T) {
+	var built []*countingObserver
+	registerTestObserver(t, "test_counting", func(map[string]string, *Topology) (SpanObserver, error) {
+		obs := &countingObserver{}
+		built = append(built, obs)
+		return obs, nil
+	})
+
+	cfg := observerTestConfig(t, "observers:\n  - type: test_counting\n")
+	_, tp := newCapturingProvider(t)
+	stats, err := Generate(context.Background(), cfg, GenerateOptions{
+		TracerProvider: tp,
+		Duration:       time.Minute,
+		Traces:         10,
+		Seed:           42,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, built, 1)
+	assert.Positive(t, stats.Spans)
+	assert.Equal(t, stats.Spans, built[0].count.Load())
+}
+
+func TestRegisterObserverErrors(t *testing.T) {
+	factory := func(map[string]string, *Topology) (SpanObserver, error) { return &recordingObserver{}, nil }
+
+	assert.ErrorContains(t, RegisterObserver("", factory), "must not be empty")
+	assert.ErrorContains(t, RegisterObserver("test_nil_factory", nil), "must not be nil")
+	assert.ErrorContains(t, RegisterObserver(SpanCountObserverType, factory), "already registered")
+}
+
+func TestValidateConfigObservers(t *testing.T) {
+	t.Parallel()
+
+	err := ValidateConfig(observerTestConfig(t, "observers:\n  - type: nope\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `observers[0]: unknown observer type "nope"`)
+	assert.Contains(t, err.Error(), SpanCountObserverType)
+
+	err = ValidateConfig(observerTestConfig(t, "observers:\n  - options: {path: x}\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "observers[0]: type is required")
+}
+
+func TestSpanCountObserver(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "spans.txt")
+	cfg := observerTestConfig(t, "observers:\n  - type: span_count\n    options: {path: "+path+"}\n")
+	_, tp := newCapturingProvider(t)
+	stats, err := Generate(context.Background(), cfg, GenerateOptions{
+		TracerProvider: tp,
+		Duration:       time.Minute,
+		Traces:         7,
+		Seed:           1,
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path) //nolint:gosec // test-controlled path
+	require.NoError(t, err)
+	count, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, stats.Spans, count)
+}
+
+func TestSpanCountObserverOptions(t *testing.T) {
+	t.Parallel()
+
+	topo := generateTestChain()
+	_, err := BuildObservers([]ObserverConfig{{Type: SpanCountObserverType}}, topo)
+	assert.ErrorContains(t, err, "observers[0]: span_count: takes one option, path")
+
+	_, err = BuildObservers([]ObserverConfig{{Type: SpanCountObserverType, Options: map[string]string{"path": "x", "extra": "y"}}}, topo)
+	assert.ErrorContains(t, err, "takes one option, path")
+}
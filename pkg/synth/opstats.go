@@ -0,0 +1,116 @@
+// Per-operation statistics: span counts, errors, timeouts, and duration percentiles
+// Collected during a run when Engine.CollectPerOp is set
+package synth
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+// opBucketGrowth is the ratio between the upper bounds of consecutive
+// duration histogram buckets, so reported percentiles are within 5% of the
+// exact value while memory stays bounded however long the run.
+const opBucketGrowth = 1.05
+
+// OpStats holds the counters and duration percentiles for one operation.
+// Errors counts spans in an error state, including cascaded errors, and
+// Timeouts counts calls to the operation that timed out.
+type OpStats struct {
+	Count    int64   `json:"count"`
+	Errors   int64   `json:"errors"`
+	Timeouts int64   `json:"timeouts"`
+	P50Ms    float64 `json:"p50_ms"`
+	P90Ms    float64 `json:"p90_ms"`
+	P99Ms    float64 `json:"p99_ms"`
+}
+
+// opAccumulator collects an operation's stats during a run, with span
+// durations counted in exponential histogram buckets.
+type opAccumulator struct {
+	OpStats
+	buckets map[int]int64
+}
+
+// recordOperation counts a span of the operation ref. It does nothing
+// unless per-operation stats are being collected into stats.
+func (s *Stats) recordOperation(ref string, d time.Duration, isError bool) {
+	if s.byOp == nil {
+		return
+	}
+	acc := s.operation(ref)
+	acc.Count++
+	if isError {
+		acc.Errors++
+	}
+	acc.buckets[durationBucket(d)]++
+}
+
+// recordTimeout counts a timed-out call to the operation ref.
+func (s *Stats) recordTimeout(ref string) {
+	if s.byOp == nil {
+		return
+	}
+	s.operation(ref).Timeouts++
+}
+
+func (s *Stats) operation(ref string) *opAccumulator {
+	acc, ok := s.byOp[ref]
+	if !ok {
+		acc = &opAccumulator{buckets: make(map[int]int64)}
+		s.byOp[ref] = acc
+	}
+	return acc
+}
+
+// finaliseOperations fills ByOperation from the accumulated stats.
+func (s *Stats) finaliseOperations() {
+	if s.byOp == nil {
+		return
+	}
+	s.ByOperation = make(map[string]OpStats, len(s.byOp))
+	for ref, acc := range s.byOp {
+		op := acc.OpStats
+		op.P50Ms = acc.percentileMs(0.50)
+		op.P90Ms = acc.percentileMs(0.90)
+		op.P99Ms = acc.percentileMs(0.99)
+		s.ByOperation[ref] = op
+	}
+}
+
+// percentileMs returns the upper bound, in milliseconds, of the bucket that
+// holds the q-th quantile of the recorded durations.
+func (a *opAccumulator) percentileMs(q float64) float64 {
+	total := int64(0)
+	for _, n := range a.buckets {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(q * float64(total)))
+	seen := int64(0)
+	for _, b := range slices.Sorted(maps.Keys(a.buckets)) {
+		seen += a.buckets[b]
+		if seen >= rank {
+			return bucketUpperBound(b).Seconds() * 1000
+		}
+	}
+	return 0
+}
+
+// durationBucket returns the histogram bucket for d. Bucket 0 holds
+// durations up to a microsecond; bucket i holds those up to
+// opBucketGrowth^i microseconds.
+func durationBucket(d time.Duration) int {
+	us := float64(d) / float64(time.Microsecond)
+	if us <= 1 {
+		return 0
+	}
+	return int(math.Ceil(math.Log(us) / math.Log(opBucketGrowth)))
+}
+
+func bucketUpperBound(bucket int) time.Duration {
+	return time.Duration(math.Pow(opBucketGrowth, float64(bucket)) * float64(time.Microsecond))
+}
@@ -0,0 +1,44 @@
+package synth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationPercentiles(t *testing.T) {
+	t.Parallel()
+
+	stats := Stats{byOp: make(map[string]*opAccumulator)}
+	for i := 1; i <= 100; i++ {
+		stats.recordOperation("svc.op", time.Duration(i)*time.Millisecond, i%4 == 0)
+	}
+	stats.recordTimeout("svc.op")
+	stats.finaliseOperations()
+
+	op := stats.ByOperation["svc.op"]
+	assert.Equal(t, int64(100), op.Count)
+	assert.Equal(t, int64(25), op.Errors)
+	assert.Equal(t, int64(1), op.Timeouts)
+	for _, tc := range []struct {
+		got, want float64
+	}{
+		{op.P50Ms, 50},
+		{op.P90Ms, 90},
+		{op.P99Ms, 99},
+	} {
+		assert.GreaterOrEqual(t, tc.got, tc.want)
+		assert.LessOrEqual(t, tc.got, tc.want*opBucketGrowth)
+	}
+}
+
+func TestOperationStatsNotCollected(t *testing.T) {
+	t.Parallel()
+
+	var stats Stats
+	stats.recordOperation("svc.op", time.Millisecond, true)
+	stats.recordTimeout("svc.op")
+	stats.finaliseOperations()
+	assert.Nil(t, stats.ByOperation)
+}
@@ -1,15 +1,11 @@
 package synth
 
 import (
-	"context"
 	"math/rand/v2"
-	"sync"
 	"testing"
 	"time"
 
 	"github.com/andrewh/motel/pkg/pipelinetest"
-	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	"go.opentelemetry.io/otel/trace"
 	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
 	"pgregory.net/rapid"
 )
@@ -192,7 +188,7 @@ func sampleWithSeed(t *testing.T, genSeed, idSeed uint64) (sent, kept map[string
 	sink, collector := startPipeline(t, headSamplerPipeline)
 	topo := loadTopology(t, passthroughTopology)
 
-	stubs := generateAndCapture(t, topo, collector.OTLPEndpoint, 30, genSeed, newSeededIDGenerator(idSeed))
+	stubs := generateAndCapture(t, topo, collector.OTLPEndpoint, 30, genSeed, NewSeededIDGenerator(rand.New(rand.NewPCG(idSeed, 0)))) //nolint:gosec // deterministic seed for testing
 	sent = make(map[string]struct{}, len(stubs))
 	for _, s := range stubs {
 		tid := s.SpanContext.TraceID()
@@ -215,29 +211,3 @@ func equalKeys(a, b map[string]struct{}) bool {
 	}
 	return true
 }
-
-// seededIDGenerator is a deterministic replacement for the SDK's random
-// trace/span ID generator. Two generators built from the same seed hand out
-// the same ID sequence, so two generation runs with the same topology seed
-// produce byte-identical span identities — the precondition for testing
-// sampling consistency.
-type seededIDGenerator struct {
-	mu  sync.Mutex
-	rng *rand.Rand
-}
-
-func newSeededIDGenerator(seed uint64) sdktrace.IDGenerator {
-	return &seededIDGenerator{rng: rand.New(rand.NewPCG(seed, 0))} //nolint:gosec // not security-sensitive
-}
-
-func (g *seededIDGenerator) NewIDs(_ context.Context) (trace.TraceID, trace.SpanID) {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-	return randomTraceID(g.rng.Uint64), randomSpanID(g.rng.Uint64)
-}
-
-func (g *seededIDGenerator) NewSpanID(_ context.Context, _ trace.TraceID) trace.SpanID {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-	return randomSpanID(g.rng.Uint64)
-}
@@ -23,6 +23,7 @@ type SpanPlan struct {
 	SpanID          trace.SpanID
 	Service         string
 	Operation       string
+	Name            string // span name; empty means Operation
 	Ref             string
 	Kind            trace.SpanKind
 	StartTime       time.Time
@@ -39,6 +40,23 @@ type SpanPlan struct {
 	// Children read their parent's Baggage to inherit; emitTrace places it on
 	// the context so it propagates as real OTel baggage.
 	Baggage map[string]string
+	// ErrorMessage is the status description when IsError is set and the
+	// span was not rejected; empty means syntheticErrorMessage.
+	ErrorMessage string
+	// TraceAttrs are the trace attributes generated at the root plan and
+	// shared by every span in the trace; children read their parent's.
+	TraceAttrs []attribute.KeyValue
+	// Depth is the span's distance from the trace root, which is depth 0.
+	Depth int
+	// RetryAttempt is this span's attempt number, counting from 1, when it
+	// retries a call and the engine links retries; PreviousAttempt is then
+	// the Index of the previous attempt's span, which emitTrace links to.
+	// Both are zero otherwise: the trace root at index 0 is never a retry.
+	RetryAttempt    int
+	PreviousAttempt int
+	// regions holds the trace's picked service regions, shared from the
+	// root plan by every descendant.
+	regions traceRegions
 }
 
 // planTrace recursively plans spans for an operation and its downstream calls.
@@ -52,12 +70,34 @@ func (e *Engine) planTrace(op, parent *Operation, parentIndex int, startTime tim
 	if *spanCount >= spanLimit {
 		return startTime, false
 	}
+	deadline, capped := e.planDeadline(*plans, startTime)
+	if capped && !startTime.Before(deadline) {
+		stats.traceCapped = true
+		return startTime, true
+	}
+	if ov := overrides[op.Ref]; ov.Drained {
+		return startTime, ov.DrainErrors
+	}
 	*spanCount++
 
 	index := len(*plans)
 
+	// Trace attributes and service regions are generated once at the root
+	// plan and inherited from the parent plan by every descendant.
+	var traceAttrs []attribute.KeyValue
+	var regions traceRegions
+	depth := 0
+	if parentIndex >= 0 {
+		traceAttrs = (*plans)[parentIndex].TraceAttrs
+		regions = (*plans)[parentIndex].regions
+		depth = (*plans)[parentIndex].Depth + 1
+	} else {
+		traceAttrs = e.rootTraceAttributes()
+		regions = newTraceRegions(e.Topology)
+	}
+
 	duration := op.Duration
-	errorRate := effectiveErrorRate(op, overrides)
+	errorRate := e.peakErrorRate(op, effectiveErrorRate(op, overrides))
 	opAttrs := op.Attributes
 	if ov, ok := overrides[op.Ref]; ok {
 		if ov.Duration.Mean > 0 {
@@ -68,12 +108,20 @@ func (e *Engine) planTrace(op, parent *Operation, parentIndex int, startTime tim
 		}
 	}
 
+	if op.Service.Flap != nil && op.Service.Flap.down(elapsed) {
+		stats.FlapRejections++
+		notifyPlanEvent(e.Observers, PlanEvent{Kind: PlanEventUnavailable, Service: op.Service.Name, Operation: op.Name, Timestamp: startTime})
+		stats.recordOperation(op.Ref, rejectionDuration, true)
+		return e.planRejectionSpan(op, parent, parentIndex, startTime, ReasonUnavailable, scenarioNames, traceAttrs, plans, isAsync, isProducer)
+	}
+
 	var opState *OperationState
+	var startDelay time.Duration
 	if e.State != nil {
 		opState = e.State.Get(op.Ref)
 	}
 	if opState != nil {
-		durationMult, errAdd, rejected, reason := opState.Admit(elapsed, e.Rng)
+		durationMult, errAdd, rejected, reason := opState.Admit(startTime, elapsed, e.Rng)
 		if rejected {
 			switch reason {
 			case ReasonQueueFull:
@@ -82,14 +130,18 @@ func (e *Engine) planTrace(op, parent *Operation, parentIndex int, startTime tim
 			case ReasonCircuitOpen:
 				stats.CircuitBreakerTrips++
 				notifyPlanEvent(e.Observers, PlanEvent{Kind: PlanEventCircuitBreakerTrip, Service: op.Service.Name, Operation: op.Name, Timestamp: startTime})
+			case ReasonRateLimited:
+				stats.RateLimitRejections++
+				notifyPlanEvent(e.Observers, PlanEvent{Kind: PlanEventRateLimitRejection, Service: op.Service.Name, Operation: op.Name, Timestamp: startTime})
 			}
-			return e.planRejectionSpan(op, parent, parentIndex, startTime, reason, scenarioNames, plans, isAsync, isProducer)
+			stats.recordOperation(op.Ref, rejectionDuration, true)
+			return e.planRejectionSpan(op, parent, parentIndex, startTime, reason, scenarioNames, traceAttrs, plans, isAsync, isProducer)
 		}
 		if durationMult > 1.0 {
-			duration.Mean = time.Duration(float64(duration.Mean) * durationMult)
+			duration = duration.slowedBy(durationMult)
 		}
 		errorRate = min(errorRate+errAdd, 1.0)
-		opState.Enter()
+		startDelay = opState.QueueWait(duration.Mean, e.Rng) + opState.ColdStart()
 	}
 
 	kind := spanKindFor(e.Topology, op, parent, isAsync, isProducer)
@@ -101,7 +153,7 @@ func (e *Engine) planTrace(op, parent *Operation, parentIndex int, startTime tim
 	if parentIndex >= 0 {
 		inheritedBaggage = (*plans)[parentIndex].Baggage
 	}
-	mergedBaggage := overlayBaggageMap(inheritedBaggage, op.Baggage)
+	mergedBaggage := overlayBaggageMap(inheritedBaggage, declaredBaggage(op, e.Rng))
 
 	startAttrs := []attribute.KeyValue{
 		attribute.String("synth.service", op.Service.Name),
@@ -110,17 +162,27 @@ func (e *Engine) planTrace(op, parent *Operation, parentIndex int, startTime tim
 	if e.LabelScenarios {
 		startAttrs = append(startAttrs, attribute.StringSlice("synth.scenarios", scenarioNames))
 	}
+	startAttrs = append(startAttrs, e.activeLabels...)
 
-	spanAttrs := make([]attribute.KeyValue, 0, len(op.Service.Attributes)+len(opAttrs))
+	spanAttrs := make([]attribute.KeyValue, 0, len(traceAttrs)+len(op.Service.Attributes)+len(opAttrs))
+	spanAttrs = append(spanAttrs, traceAttrs...)
 	for k, v := range op.Service.Attributes {
 		spanAttrs = append(spanAttrs, attribute.String(k, v))
 	}
+	if region, ok := regions.region(op.Service, e.Rng); ok {
+		spanAttrs = append(spanAttrs, region)
+	}
 	for _, a := range opAttrs {
 		spanAttrs = append(spanAttrs, typedAttribute(a.Key, a.Gen.Generate(e.Rng)))
 	}
 	if op.BaggageAsAttributes {
 		spanAttrs = append(spanAttrs, baggageAttributesFromMap(mergedBaggage)...)
 	}
+	cacheHit := e.rollCacheHit(op)
+	if op.Cache != nil {
+		spanAttrs = append(spanAttrs, attribute.Bool(cacheHitAttribute, cacheHit))
+	}
+	name := spanName(op, spanAttrs)
 
 	ownError := false
 	if errorRate > 0 {
@@ -130,9 +192,17 @@ func (e *Engine) planTrace(op, parent *Operation, parentIndex int, startTime tim
 			ownError = e.Rng.Float64() < errorRate
 		}
 	}
+	var errType *ErrorType
+	if ownError {
+		if errType = e.pickErrorType(op); errType != nil {
+			spanAttrs = append(spanAttrs, attributeKeyValues(errType.Attributes, e.Rng)...)
+		}
+	}
 	ownDuration := duration.Sample(e.Rng)
 	preCallDuration := ownDuration / 2
-	childStartTime := startTime.Add(preCallDuration)
+	childStartTime := startTime.Add(startDelay + preCallDuration)
+	postCallDuration := ownDuration - preCallDuration
+	returnBy := childStartTime.Add(postCallDuration)
 
 	var linkRefs []LinkRef
 	for _, linked := range op.Links {
@@ -148,6 +218,7 @@ func (e *Engine) planTrace(op, parent *Operation, parentIndex int, startTime tim
 		ParentIndex: parentIndex,
 		Service:     op.Service.Name,
 		Operation:   op.Name,
+		Name:        name,
 		Ref:         op.Ref,
 		Kind:        kind,
 		StartTime:   startTime,
@@ -156,6 +227,9 @@ func (e *Engine) planTrace(op, parent *Operation, parentIndex int, startTime tim
 		Scenarios:   scenarioNames,
 		LinkRefs:    linkRefs,
 		Baggage:     mergedBaggage,
+		TraceAttrs:  traceAttrs,
+		Depth:       depth,
+		regions:     regions,
 	}
 	*plans = append(*plans, plan)
 
@@ -163,6 +237,9 @@ func (e *Engine) planTrace(op, parent *Operation, parentIndex int, startTime tim
 
 	activeCalls := make([]activeCall, 0, len(baseCalls))
 	for i, call := range baseCalls {
+		if cacheHit && op.Cache.SkipCalls[call.Operation.Ref] {
+			continue
+		}
 		if call.Condition == "on-error" && !ownError {
 			continue
 		}
@@ -175,7 +252,7 @@ func (e *Engine) planTrace(op, parent *Operation, parentIndex int, startTime tim
 				fire, ok = e.forcedChoice(choiceKindCallProbability, op.Ref, call.Operation.Ref, i)
 			}
 			if !ok {
-				fire = e.Rng.Float64() < call.Probability
+				fire = e.Rng.Float64() < call.probabilityAt(depth)
 			}
 			if !fire {
 				continue
@@ -183,37 +260,53 @@ func (e *Engine) planTrace(op, parent *Operation, parentIndex int, startTime tim
 		}
 		activeCalls = append(activeCalls, activeCall{Call: call, ChoiceIndex: i})
 	}
+	// The one_of branch is not a swarm choice point; index -1 never matches
+	// a call's retry choice.
+	if call, ok := e.pickOneOf(op); ok && (!cacheHit || !op.Cache.SkipCalls[call.Operation.Ref]) {
+		activeCalls = append(activeCalls, activeCall{Call: call, ChoiceIndex: -1})
+	}
 
 	latestChildEnd := childStartTime
 	anyChildFailed := false
+	failedChild := "" // ref of the first failed call, named in a cascaded error's message
+	// childTimedOut records whether failedChild failed by timing out.
+	childTimedOut := false
 	if op.CallStyle == "sequential" {
 		nextStart := childStartTime
 		for _, active := range activeCalls {
-			count := max(active.Call.Count, 1)
+			count := e.callCount(active.Call)
 			for range count {
-				perceivedEnd, failed := e.executePlanCall(active, op, index, nextStart, elapsed, overrides, scenarioNames, stats, plans, spanCount, spanLimit)
+				perceivedEnd, failed, timedOut := e.executePlanCall(active, op, index, nextStart, elapsed, overrides, scenarioNames, stats, plans, spanCount, spanLimit)
 				if active.Call.Async {
 					continue
 				}
-				if failed {
+				nextStart = perceivedEnd
+				if !op.awaits(perceivedEnd, returnBy) {
+					continue
+				}
+				if failed && !anyChildFailed {
 					anyChildFailed = true
+					failedChild = active.Call.Operation.Ref
+					childTimedOut = timedOut
 				}
 				if perceivedEnd.After(latestChildEnd) {
 					latestChildEnd = perceivedEnd
 				}
-				nextStart = perceivedEnd
 			}
 		}
 	} else {
 		for _, active := range activeCalls {
-			count := max(active.Call.Count, 1)
+			count := e.callCount(active.Call)
 			for range count {
-				perceivedEnd, failed := e.executePlanCall(active, op, index, childStartTime, elapsed, overrides, scenarioNames, stats, plans, spanCount, spanLimit)
-				if active.Call.Async {
+				callStart := childStartTime.Add(e.callJitter(op))
+				perceivedEnd, failed, timedOut := e.executePlanCall(active, op, index, callStart, elapsed, overrides, scenarioNames, stats, plans, spanCount, spanLimit)
+				if active.Call.Async || !op.awaits(perceivedEnd, returnBy) {
 					continue
 				}
-				if failed {
+				if failed && !anyChildFailed {
 					anyChildFailed = true
+					failedChild = active.Call.Operation.Ref
+					childTimedOut = timedOut
 				}
 				if perceivedEnd.After(latestChildEnd) {
 					latestChildEnd = perceivedEnd
@@ -222,26 +315,73 @@ func (e *Engine) planTrace(op, parent *Operation, parentIndex int, startTime tim
 		}
 	}
 
-	postCallDuration := ownDuration - preCallDuration
-	endTime := latestChildEnd.Add(postCallDuration)
+	endTime := op.spanEnd(latestChildEnd, postCallDuration, returnBy)
+
+	truncated := capped && endTime.After(deadline)
+	if truncated {
+		endTime = deadline
+		stats.traceCapped = true
+	}
+
+	slow := op.isSlow(endTime.Sub(startTime))
+	if slow && op.SlowStatus == slowStatusAttribute {
+		spanAttrs = append(spanAttrs, slowAttribute)
+		(*plans)[index].Attrs = spanAttrs
+	}
+	slowError := slow && op.SlowStatus == slowStatusError
 
-	isError := ownError || anyChildFailed
+	if op.SwallowErrors {
+		anyChildFailed, childTimedOut = false, false
+	}
+	isError := ownError || anyChildFailed || truncated || slowError
 
 	// Fill in the deferred fields now that children are resolved.
 	(*plans)[index].EndTime = endTime
 	(*plans)[index].IsError = isError
+	deadlineExceeded := truncated || (!ownError && childTimedOut)
+	if code, ok := e.httpStatusAttribute(op, opAttrs, errType, isError, deadlineExceeded); ok {
+		spanAttrs = append(spanAttrs, code)
+		(*plans)[index].Attrs = spanAttrs
+	}
+	if isError {
+		if code, ok := e.grpcStatusAttribute(op, opAttrs, errType, deadlineExceeded); ok {
+			spanAttrs = append(spanAttrs, code)
+			(*plans)[index].Attrs = spanAttrs
+		}
+		(*plans)[index].ErrorMessage = errorStatusMessage(op, errType, ownError, failedChild, spanAttrs)
+		switch {
+		case truncated:
+			(*plans)[index].ErrorMessage = traceDeadlineMessage
+		case !ownError && !anyChildFailed:
+			(*plans)[index].ErrorMessage = slowErrorMessage(op, endTime.Sub(startTime))
+		}
+	}
+	stats.recordOperation(op.Ref, endTime.Sub(startTime), isError)
 
 	if opState != nil {
-		opState.Exit(elapsed, endTime.Sub(startTime), isError)
+		opState.Exit(elapsed, endTime, endTime.Sub(startTime), isError)
 	}
 
 	return endTime, isError
 }
 
+// planDeadline returns the time by which the trace being planned must end,
+// and whether the engine's MaxTraceDuration applies. The root plan is always
+// first in plans; startTime stands in for it while planning the root itself.
+func (e *Engine) planDeadline(plans []SpanPlan, startTime time.Time) (time.Time, bool) {
+	if e.MaxTraceDuration <= 0 {
+		return time.Time{}, false
+	}
+	if len(plans) > 0 {
+		startTime = plans[0].StartTime
+	}
+	return startTime.Add(e.MaxTraceDuration), true
+}
+
 // planRejectionSpan mirrors emitRejectionSpan but appends to plans.
 // The caller (planTrace) has already counted this span against the trace's
 // span limit, so spanCount is not incremented here.
-func (e *Engine) planRejectionSpan(op, parent *Operation, parentIndex int, startTime time.Time, reason string, scenarioNames []string, plans *[]SpanPlan, isAsync, isProducer bool) (time.Time, bool) {
+func (e *Engine) planRejectionSpan(op, parent *Operation, parentIndex int, startTime time.Time, reason string, scenarioNames []string, traceAttrs []attribute.KeyValue, plans *[]SpanPlan, isAsync, isProducer bool) (time.Time, bool) {
 	endTime := startTime.Add(rejectionDuration)
 
 	kind := spanKindFor(e.Topology, op, parent, isAsync, isProducer)
@@ -255,6 +395,8 @@ func (e *Engine) planRejectionSpan(op, parent *Operation, parentIndex int, start
 	if e.LabelScenarios {
 		rejAttrs = append(rejAttrs, attribute.StringSlice("synth.scenarios", scenarioNames))
 	}
+	rejAttrs = append(rejAttrs, e.activeLabels...)
+	rejAttrs = append(rejAttrs, traceAttrs...)
 
 	*plans = append(*plans, SpanPlan{
 		Index:           len(*plans),
@@ -276,40 +418,64 @@ func (e *Engine) planRejectionSpan(op, parent *Operation, parentIndex int, start
 }
 
 // executePlanCall mirrors executeCall but delegates to planTrace.
-func (e *Engine) executePlanCall(active activeCall, parent *Operation, parentIndex int, callStart time.Time, elapsed time.Duration, overrides map[string]Override, scenarioNames []string, stats *Stats, plans *[]SpanPlan, spanCount *int, spanLimit int) (time.Time, bool) {
+func (e *Engine) executePlanCall(active activeCall, parent *Operation, parentIndex int, callStart time.Time, elapsed time.Duration, overrides map[string]Override, scenarioNames []string, stats *Stats, plans *[]SpanPlan, spanCount *int, spanLimit int) (time.Time, bool, bool) {
 	call := active.Call
 	maxAttempts := 1 + call.Retries
 	attemptStart := callStart
+	overrides = callOverrides(call, overrides)
+	previous := 0
 
 	for attempt := range maxAttempts {
+		index := len(*plans)
 		childEnd, childErr := e.planTrace(call.Operation, parent, parentIndex, attemptStart, elapsed, overrides, scenarioNames, stats, plans, spanCount, spanLimit, call.Async, call.Producer)
+		planned := len(*plans) > index
+		if planned && e.LinkRetries && call.Retries > 0 && previous > 0 {
+			(*plans)[index].RetryAttempt = attempt + 1
+			(*plans)[index].PreviousAttempt = previous
+		}
+
+		if call.HedgeAfter > 0 && childEnd.Sub(attemptStart) > call.HedgeAfter && *spanCount < spanLimit {
+			hedgeStart := attemptStart.Add(call.HedgeAfter)
+			stats.Hedges++
+			notifyPlanEvent(e.Observers, PlanEvent{Kind: PlanEventHedge, Service: call.Operation.Service.Name, Operation: call.Operation.Name, Timestamp: hedgeStart})
+			hedgeEnd, hedgeErr := e.planTrace(call.Operation, parent, parentIndex, hedgeStart, elapsed, overrides, scenarioNames, stats, plans, spanCount, spanLimit, call.Async, call.Producer)
+			if hedgeEnd.Before(childEnd) {
+				childEnd, childErr = hedgeEnd, hedgeErr
+			}
+		}
 		perceivedEnd := childEnd
 		failed := childErr
 
-		if call.Timeout > 0 && childEnd.Sub(attemptStart) > call.Timeout {
+		timedOut := call.Timeout > 0 && childEnd.Sub(attemptStart) > call.Timeout
+		if timedOut {
 			perceivedEnd = attemptStart.Add(call.Timeout)
 			failed = true
 			stats.Timeouts++
+			stats.recordTimeout(call.Operation.Ref)
 			notifyPlanEvent(e.Observers, PlanEvent{Kind: PlanEventTimeout, Service: call.Operation.Service.Name, Operation: call.Operation.Name, Timestamp: perceivedEnd})
 		}
 
 		if attempt < maxAttempts-1 {
 			if retry, ok := e.forcedChoice(choiceKindRetryActivation, parent.Ref, call.Operation.Ref, active.ChoiceIndex); ok {
 				if !retry {
-					return perceivedEnd, failed
+					return perceivedEnd, failed, timedOut
 				}
 				failed = true
 			}
 		}
 
 		if !failed || attempt == maxAttempts-1 {
-			return perceivedEnd, failed
+			return perceivedEnd, failed, timedOut
 		}
 
 		stats.Retries++
 		notifyPlanEvent(e.Observers, PlanEvent{Kind: PlanEventRetry, Service: call.Operation.Service.Name, Operation: call.Operation.Name, Timestamp: perceivedEnd})
-		attemptStart = perceivedEnd.Add(call.RetryBackoff)
+		attemptStart = perceivedEnd.Add(e.retryGap(call, attempt))
+		previous = 0
+		if planned {
+			previous = index
+		}
 	}
 
-	return callStart, true
+	return callStart, true, false
 }
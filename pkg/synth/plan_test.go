@@ -228,20 +228,18 @@ func TestPlanTraceRejection(t *testing.T) {
 
 	rootOp := engine.Topology.Roots[0]
 
-	// First call succeeds and fills the queue
+	// First call succeeds and fills the queue until its span ends
+	start := time.Now()
 	var stats1 Stats
 	var plans1 []SpanPlan
 	sc1 := 0
-	engine.planTrace(rootOp, nil, -1, time.Now(), 0, nil, nil, &stats1, &plans1, &sc1, DefaultMaxSpansPerTrace, false, false)
-
-	// Manually bump active requests to trigger queue full
-	opState := engine.State.Get(rootOp.Ref)
-	opState.ActiveRequests = 1
+	engine.planTrace(rootOp, nil, -1, start, 0, nil, nil, &stats1, &plans1, &sc1, DefaultMaxSpansPerTrace, false, false)
 
+	// A second request arriving while the first is in flight is rejected
 	var stats2 Stats
 	var plans2 []SpanPlan
 	sc2 := 0
-	engine.planTrace(rootOp, nil, -1, time.Now(), time.Second, nil, nil, &stats2, &plans2, &sc2, DefaultMaxSpansPerTrace, false, false)
+	engine.planTrace(rootOp, nil, -1, start.Add(time.Millisecond), time.Millisecond, nil, nil, &stats2, &plans2, &sc2, DefaultMaxSpansPerTrace, false, false)
 
 	require.Len(t, plans2, 1)
 	assert.True(t, plans2[0].Rejected)
@@ -481,10 +479,12 @@ func TestRejectionSpanCountedOnce(t *testing.T) {
 		t.Parallel()
 		engine, exporter, tp := newTestEngine(t, twoTierQueueConfig())
 		engine.State = NewSimulationState(engine.Topology)
-		engine.State.Get("backend.handle").Enter() // queue now full
+		start := time.Now()
+		// A request in flight for the whole trace fills the queue.
+		engine.State.Get("backend.handle").Exit(0, start.Add(time.Second), time.Second, false)
 
 		spanCount := 0
-		engine.walkTrace(context.Background(), engine.Topology.Roots[0], nil, time.Now(), 0, nil, nil, &Stats{}, &spanCount, DefaultMaxSpansPerTrace, false, false)
+		engine.walkTrace(context.Background(), engine.Topology.Roots[0], nil, start, 0, nil, nil, &Stats{}, &spanCount, DefaultMaxSpansPerTrace, false, false)
 		require.NoError(t, tp.ForceFlush(context.Background()))
 
 		assert.Equal(t, 2, spanCount, "root span plus one rejected span")
@@ -495,11 +495,12 @@ func TestRejectionSpanCountedOnce(t *testing.T) {
 		t.Parallel()
 		engine, _, _ := newTestEngine(t, twoTierQueueConfig())
 		engine.State = NewSimulationState(engine.Topology)
-		engine.State.Get("backend.handle").Enter()
+		start := time.Now()
+		engine.State.Get("backend.handle").Exit(0, start.Add(time.Second), time.Second, false)
 
 		spanCount := 0
 		var plans []SpanPlan
-		engine.planTrace(engine.Topology.Roots[0], nil, -1, time.Now(), 0, nil, nil, &Stats{}, &plans, &spanCount, DefaultMaxSpansPerTrace, false, false)
+		engine.planTrace(engine.Topology.Roots[0], nil, -1, start, 0, nil, nil, &Stats{}, &plans, &spanCount, DefaultMaxSpansPerTrace, false, false)
 
 		assert.Equal(t, 2, spanCount, "root span plus one rejected span")
 		assert.Len(t, plans, 2, "span count matches planned spans")
@@ -6,7 +6,11 @@ package synth
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -34,7 +38,8 @@ var genRateString = rapid.StringMatching(`[1-9][0-9]{0,3}/[smh]`)
 var genErrorRateString = rapid.StringMatching(`[1-9][0-9]?%`)
 
 // genSimpleConfig generates a valid Config with 1-4 services, each with 1-3 operations,
-// and a DAG of calls between them (no cycles).
+// and a DAG of calls between them (no cycles). Some operations name their spans
+// with a span_name template, and some swallow their calls' errors.
 func genSimpleConfig(t *rapid.T) *Config {
 	type svcOp struct{ svc, op string }
 
@@ -60,6 +65,11 @@ func genSimpleConfig(t *rapid.T) *Config {
 			if hasErr {
 				ops[j].ErrorRate = genErrorRateString.Draw(t, fmt.Sprintf("errRate%d_%d", i, j))
 			}
+			ops[j].SwallowErrors = rapid.Bool().Draw(t, fmt.Sprintf("swallow%d_%d", i, j))
+			if rapid.Bool().Draw(t, fmt.Sprintf("hasSpanName%d_%d", i, j)) {
+				ops[j].SpanName = "{operation.name} /items/{item.id}"
+				ops[j].Attributes = map[string]AttributeValueConfig{"item.id": {Sequence: "item-{n}"}}
+			}
 			allOps = append(allOps, svcOp{svcName, opName})
 		}
 		svcs[i] = ServiceConfig{Name: svcName, Operations: ops}
@@ -149,29 +159,60 @@ func TestProperty_Engine_SpansMatchTopology(t *testing.T) {
 		cfg := genSimpleConfig(t)
 		topo, spans, _ := walkOnce(t, cfg)
 
-		knownOps := make(map[string]bool)
+		// Each service's known span names, as patterns accepting whatever
+		// a span_name template's placeholders resolve to.
+		knownNames := make(map[string][]*regexp.Regexp)
 		for _, svc := range topo.Services {
 			for _, op := range svc.Operations {
-				knownOps[op.Ref] = true
+				knownNames[svc.Name] = append(knownNames[svc.Name], spanNamePattern(op))
 			}
 		}
 
 		for _, span := range spans {
 			svcName := ""
-			opName := span.Name
 			for _, attr := range span.Attributes {
 				if string(attr.Key) == "synth.service" {
 					svcName = attr.Value.AsString()
 				}
 			}
-			ref := svcName + "." + opName
-			if !knownOps[ref] {
-				t.Fatalf("span %q not in topology (known: %v)", ref, knownOps)
+			known := slices.ContainsFunc(knownNames[svcName], func(re *regexp.Regexp) bool {
+				return re.MatchString(span.Name)
+			})
+			if !known {
+				t.Fatalf("span %q of service %q not in topology (known: %v)", span.Name, svcName, knownNames)
 			}
 		}
 	})
 }
 
+// spanNamePattern matches the names of op's spans: its name, or its
+// span_name template with any text in place of each placeholder.
+func spanNamePattern(op *Operation) *regexp.Regexp {
+	if op.SpanName == "" {
+		return regexp.MustCompile("^" + regexp.QuoteMeta(op.Name) + "$")
+	}
+	literals := placeholderPattern.Split(op.SpanName, -1)
+	for i, lit := range literals {
+		literals[i] = regexp.QuoteMeta(lit)
+	}
+	return regexp.MustCompile("^" + strings.Join(literals, ".*") + "$")
+}
+
+// spanOperation returns the operation a span was generated for, from its
+// synth.service and synth.operation attributes.
+func spanOperation(topo *Topology, s tracetest.SpanStub) *Operation {
+	svcName, opName := "", ""
+	for _, attr := range s.Attributes {
+		switch string(attr.Key) {
+		case "synth.service":
+			svcName = attr.Value.AsString()
+		case "synth.operation":
+			opName = attr.Value.AsString()
+		}
+	}
+	return topo.Services[svcName].Operations[opName]
+}
+
 // --- Call graph correctness ---
 
 func TestProperty_Engine_CallGraphMatchesTopology(t *testing.T) {
@@ -197,14 +238,10 @@ func TestProperty_Engine_CallGraphMatchesTopology(t *testing.T) {
 			spanByID[s.SpanContext.SpanID()] = s
 		}
 
+		// Span names may come from span_name templates, so the operation
+		// is read from its attributes.
 		refOf := func(s tracetest.SpanStub) string {
-			svcName := ""
-			for _, attr := range s.Attributes {
-				if string(attr.Key) == "synth.service" {
-					svcName = attr.Value.AsString()
-				}
-			}
-			return svcName + "." + s.Name
+			return spanOperation(topo, s).Ref
 		}
 
 		for _, s := range spans {
@@ -303,12 +340,7 @@ func TestProperty_Engine_ErrorsBounded(t *testing.T) {
 func TestProperty_Engine_ErrorCascadesToParent(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
 		cfg := genSimpleConfig(t)
-		_, spans, _ := walkOnce(t, cfg)
-
-		spanByID := make(map[trace.SpanID]tracetest.SpanStub)
-		for _, s := range spans {
-			spanByID[s.SpanContext.SpanID()] = s
-		}
+		topo, spans, _ := walkOnce(t, cfg)
 
 		// Build children map
 		childrenOf := make(map[trace.SpanID][]tracetest.SpanStub)
@@ -320,7 +352,7 @@ func TestProperty_Engine_ErrorCascadesToParent(t *testing.T) {
 
 		for _, parent := range spans {
 			children := childrenOf[parent.SpanContext.SpanID()]
-			if len(children) == 0 {
+			if len(children) == 0 || spanOperation(topo, parent).SwallowErrors {
 				continue
 			}
 			anyChildErrored := false
@@ -969,6 +1001,91 @@ func TestProperty_NormalValue_MeanConverges(t *testing.T) {
 	})
 }
 
+func TestProperty_ParetoValue_AtLeastScale(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		scale := rapid.Float64Range(0.1, 10000).Draw(t, "scale")
+		shape := rapid.Float64Range(0.1, 10).Draw(t, "shape")
+		gen := &ParetoValue{Scale: scale, Shape: shape}
+		seed := genSeed(t)
+		rng := rand.New(rand.NewPCG(seed, 0)) //nolint:gosec // not used for security
+
+		for range 500 {
+			got := gen.Generate(rng).(float64)
+			if got < scale || math.IsInf(got, 0) || math.IsNaN(got) {
+				t.Fatalf("ParetoValue(scale=%f, shape=%f) returned %f", scale, shape, got)
+			}
+		}
+	})
+}
+
+func TestProperty_ParetoValue_MeanConverges(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		scale := rapid.Float64Range(1, 1000).Draw(t, "scale")
+		// Shapes near 1 have infinite variance and the sample mean converges
+		// too slowly to test; 3 and above keeps the check stable.
+		shape := rapid.Float64Range(3, 10).Draw(t, "shape")
+		gen := &ParetoValue{Scale: scale, Shape: shape}
+		seed := genSeed(t)
+		rng := rand.New(rand.NewPCG(seed, 0)) //nolint:gosec // not used for security
+
+		sum := 0.0
+		n := 20000
+		for range n {
+			sum += gen.Generate(rng).(float64)
+		}
+		sampleMean := sum / float64(n)
+		mean := scale * shape / (shape - 1)
+
+		tolerance := mean * 0.05 // 5% of the analytic mean
+		if math.Abs(sampleMean-mean) > tolerance {
+			t.Fatalf("ParetoValue mean did not converge: expected ~%f, got %f (tolerance %f)",
+				mean, sampleMean, tolerance)
+		}
+	})
+}
+
+// genRegexPattern builds a pattern from the subset RegexValue expands:
+// literals, classes, bounded and unbounded quantifiers, groups, alternation.
+func genRegexPattern(t *rapid.T, depth int) string {
+	atom := rapid.SampledFrom([]string{
+		"a", "Z", "-", `\.`, "[0-9]", "[a-f]", "[^a-z]", `\d`, `\w`, `\s`, ".",
+	}).Draw(t, "atom")
+	if depth > 0 && rapid.Bool().Draw(t, "group") {
+		n := rapid.IntRange(1, 3).Draw(t, "alternatives")
+		alts := make([]string, n)
+		for i := range alts {
+			alts[i] = genRegexPattern(t, depth-1)
+		}
+		atom = "(" + strings.Join(alts, "|") + ")"
+	}
+	quant := rapid.SampledFrom([]string{"", "?", "*", "+", "{3}", "{1,4}", "{2,}"}).Draw(t, "quantifier")
+	pattern := atom + quant
+	if depth > 0 && rapid.Bool().Draw(t, "concat") {
+		pattern += genRegexPattern(t, depth-1)
+	}
+	return pattern
+}
+
+func TestProperty_RegexValue_MatchesPattern(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		pattern := genRegexPattern(t, 3)
+		gen, err := NewRegexValue(pattern)
+		if err != nil {
+			t.Fatalf("NewRegexValue(%q): %v", pattern, err)
+		}
+		re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+		seed := genSeed(t)
+		rng := rand.New(rand.NewPCG(seed, 0)) //nolint:gosec // not used for security
+
+		for range 50 {
+			got := gen.Generate(rng).(string)
+			if !re.MatchString(got) {
+				t.Fatalf("RegexValue(%q) returned %q, which does not match", pattern, got)
+			}
+		}
+	})
+}
+
 // --- Distribution sampling ---
 
 func TestProperty_Distribution_SampleNonNegative(t *testing.T) {
@@ -1070,6 +1187,57 @@ func TestProperty_ParseDistribution_RoundTrip(t *testing.T) {
 	})
 }
 
+func TestProperty_PercentileDistribution_HitsTargets(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		p50Ms := rapid.IntRange(1, 500).Draw(t, "p50Ms")
+		ratio := rapid.IntRange(1, 10).Draw(t, "ratio")
+		p50 := time.Duration(p50Ms) * time.Millisecond
+		p99 := p50 * time.Duration(ratio)
+		dist, err := NewPercentileDistribution(p50, p99)
+		if err != nil {
+			t.Fatalf("NewPercentileDistribution(%v, %v): %v", p50, p99, err)
+		}
+
+		seed := genSeed(t)
+		rng := rand.New(rand.NewPCG(seed, 0)) //nolint:gosec // not used for security
+
+		samples := make([]time.Duration, 10000)
+		for i := range samples {
+			samples[i] = dist.Sample(rng)
+		}
+		slices.Sort(samples)
+		gotP50 := samples[len(samples)/2]
+		gotP99 := samples[len(samples)*99/100]
+
+		if math.Abs(float64(gotP50-p50)) > 0.05*float64(p50) {
+			t.Fatalf("empirical p50 %v is not within 5%% of target %v", gotP50, p50)
+		}
+		if math.Abs(float64(gotP99-p99)) > 0.15*float64(p99) {
+			t.Fatalf("empirical p99 %v is not within 15%% of target %v", gotP99, p99)
+		}
+	})
+}
+
+func TestProperty_PercentileDistribution_RoundTrip(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		p50Ms := rapid.IntRange(1, 10000).Draw(t, "p50Ms")
+		extraMs := rapid.IntRange(0, 10000).Draw(t, "extraMs")
+		dist, err := NewPercentileDistribution(time.Duration(p50Ms)*time.Millisecond, time.Duration(p50Ms+extraMs)*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s := dist.String()
+		parsed, err := ParseDistribution(s)
+		if err != nil {
+			t.Fatalf("ParseDistribution(%q): %v", s, err)
+		}
+		if parsed != dist {
+			t.Fatalf("round-trip: %+v != %+v (string was %q)", parsed, dist, s)
+		}
+	})
+}
+
 // --- Topology building ---
 
 func TestProperty_BuildTopology_RefsCorrect(t *testing.T) {
@@ -1572,8 +1740,11 @@ func TestProperty_CustomPattern_SegmentBoundaries(t *testing.T) {
 // --- Circuit breaker state machine ---
 
 // circuitBreakerModel is a simplified model of the expected circuit breaker behaviour.
-// rapid drives random sequences of actions (request success, request failure, time advance)
-// and we check the real OperationState against this model after every action.
+// rapid drives random sequences of actions (requests that succeed or fail
+// after a drawn latency, time advance) and we check the real OperationState
+// against this model after every action. Requests stay in flight on the
+// simulated clock until their latency has passed, so the half-open probe cap
+// is exercised.
 type circuitBreakerModel struct {
 	state     *OperationState
 	rng       *rand.Rand
@@ -1584,6 +1755,16 @@ type circuitBreakerModel struct {
 	threshold int
 	window    time.Duration
 	cooldown  time.Duration
+	maxProbes int // 0 = uncapped
+	flights   []modelFlight
+	probes    int // probes in flight at the latest admission
+	inFlight  int // requests in flight at the latest admission
+}
+
+// modelFlight is a request the model has seen finish on the simulated clock.
+type modelFlight struct {
+	end           time.Duration
+	probe, failed bool
 }
 
 func (m *circuitBreakerModel) pruneFailures() {
@@ -1597,16 +1778,120 @@ func (m *circuitBreakerModel) pruneFailures() {
 	m.failures = pruned
 }
 
+// settle drops requests that have ended and, while half-open, lets the
+// earliest probe to have ended decide the circuit.
+func (m *circuitBreakerModel) settle() {
+	var decider *modelFlight
+	var pending []modelFlight
+	for i, f := range m.flights {
+		if f.end > m.elapsed {
+			pending = append(pending, f)
+			continue
+		}
+		if f.probe && (decider == nil || f.end < decider.end) {
+			decider = &m.flights[i]
+		}
+	}
+	if decider != nil {
+		for i := range pending {
+			pending[i].probe = false
+		}
+		if decider.failed {
+			m.model = CircuitOpen
+			m.openedAt = m.elapsed
+		} else {
+			m.model = CircuitClosed
+			m.failures = m.failures[:0]
+		}
+	}
+	m.flights = pending
+}
+
+// admit calls Admit on the real state and checks the decision against the
+// model, returning whether the request was admitted.
+func (m *circuitBreakerModel) admit(t *rapid.T) bool {
+	_, _, rejected, reason := m.state.Admit(simAt(m.elapsed), m.elapsed, m.rng)
+
+	m.settle()
+	if m.model == CircuitOpen {
+		if m.elapsed-m.openedAt >= m.cooldown {
+			// Should transition to HalfOpen and admit a probe
+			m.model = CircuitHalfOpen
+		} else {
+			// Should reject
+			if !rejected {
+				t.Fatal("model says Open, should reject")
+			}
+			if reason != ReasonCircuitOpen {
+				t.Fatalf("expected circuit_open reason, got %q", reason)
+			}
+			return false
+		}
+	}
+
+	m.inFlight, m.probes = len(m.flights), 0
+	for _, f := range m.flights {
+		if f.probe {
+			m.probes++
+		}
+	}
+
+	if m.model == CircuitHalfOpen && m.maxProbes > 0 && m.probes >= m.maxProbes {
+		if !rejected {
+			t.Fatalf("model says HalfOpen with %d/%d probes, should reject", m.probes, m.maxProbes)
+		}
+		if reason != ReasonCircuitOpen {
+			t.Fatalf("expected circuit_open reason, got %q", reason)
+		}
+		return false
+	}
+
+	if rejected {
+		t.Fatalf("model says %v, should not reject (reason=%q)", m.model, reason)
+	}
+	return true
+}
+
+// request admits a request and, if admitted, finishes it after latency,
+// applying the model's transition.
+func (m *circuitBreakerModel) request(t *rapid.T, latency time.Duration, failed bool) {
+	if !m.admit(t) {
+		return
+	}
+	m.state.Exit(m.elapsed, simAt(m.elapsed+latency), latency, failed)
+
+	// Model: a request finishing while HalfOpen is a probe, settled once
+	// its end has passed
+	probe := m.model == CircuitHalfOpen
+	m.flights = append(m.flights, modelFlight{end: m.elapsed + latency, probe: probe, failed: failed})
+	if probe {
+		return
+	}
+
+	// Model: accumulate failure, prune window, check threshold
+	m.pruneFailures()
+	if failed && len(m.failures) < m.threshold {
+		m.failures = append(m.failures, m.elapsed)
+	}
+
+	if len(m.failures) >= m.threshold && m.model == CircuitClosed {
+		m.model = CircuitOpen
+		m.openedAt = m.elapsed
+	}
+}
+
 func TestProperty_CircuitBreaker_StateMachine(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
 		threshold := rapid.IntRange(1, 5).Draw(t, "threshold")
 		windowMs := rapid.IntRange(100, 5000).Draw(t, "windowMs")
 		cooldownMs := rapid.IntRange(50, 2000).Draw(t, "cooldownMs")
+		maxProbes := rapid.IntRange(0, 3).Draw(t, "maxProbes")
 
 		os := &OperationState{
-			FailureThreshold: threshold,
-			WindowDuration:   time.Duration(windowMs) * time.Millisecond,
-			Cooldown:         time.Duration(cooldownMs) * time.Millisecond,
+			FailureThreshold:  threshold,
+			WindowDuration:    time.Duration(windowMs) * time.Millisecond,
+			Cooldown:          time.Duration(cooldownMs) * time.Millisecond,
+			HalfOpenMaxProbes: maxProbes,
 		}
 
 		seed := rapid.Uint64().Draw(t, "seed")
@@ -1618,6 +1903,7 @@ func TestProperty_CircuitBreaker_StateMachine(t *testing.T) {
 			threshold: threshold,
 			window:    time.Duration(windowMs) * time.Millisecond,
 			cooldown:  time.Duration(cooldownMs) * time.Millisecond,
+			maxProbes: maxProbes,
 			model:     CircuitClosed,
 		}
 
@@ -1627,82 +1913,12 @@ func TestProperty_CircuitBreaker_StateMachine(t *testing.T) {
 				m.elapsed += advance
 			},
 			"successRequest": func(t *rapid.T) {
-				_, _, rejected, reason := m.state.Admit(m.elapsed, m.rng)
-
-				if m.model == CircuitOpen {
-					if m.elapsed-m.openedAt >= m.cooldown {
-						// Should transition to HalfOpen and admit
-						m.model = CircuitHalfOpen
-					} else {
-						// Should reject
-						if !rejected {
-							t.Fatal("model says Open, should reject")
-						}
-						if reason != ReasonCircuitOpen {
-							t.Fatalf("expected circuit_open reason, got %q", reason)
-						}
-						return
-					}
-				}
-
-				if rejected {
-					t.Fatalf("model says %v, should not reject (reason=%q)", m.model, reason)
-				}
-
-				m.state.Enter()
-				m.state.Exit(m.elapsed, time.Millisecond, false)
-
-				// Model: success in HalfOpen closes the circuit
-				if m.model == CircuitHalfOpen {
-					m.model = CircuitClosed
-					m.failures = m.failures[:0]
-				}
-
-				// Prune model's failure window
-				m.pruneFailures()
+				latency := time.Duration(rapid.IntRange(1, 2000).Draw(t, "latencyMs")) * time.Millisecond
+				m.request(t, latency, false)
 			},
 			"failRequest": func(t *rapid.T) {
-				_, _, rejected, reason := m.state.Admit(m.elapsed, m.rng)
-
-				if m.model == CircuitOpen {
-					if m.elapsed-m.openedAt >= m.cooldown {
-						m.model = CircuitHalfOpen
-					} else {
-						if !rejected {
-							t.Fatal("model says Open, should reject")
-						}
-						if reason != ReasonCircuitOpen {
-							t.Fatalf("expected circuit_open reason, got %q", reason)
-						}
-						return
-					}
-				}
-
-				if rejected {
-					t.Fatalf("model says %v, should not reject (reason=%q)", m.model, reason)
-				}
-
-				m.state.Enter()
-				m.state.Exit(m.elapsed, time.Millisecond, true)
-
-				// Model: failure in HalfOpen reopens the circuit
-				if m.model == CircuitHalfOpen {
-					m.model = CircuitOpen
-					m.openedAt = m.elapsed
-					m.pruneFailures()
-					return
-				}
-
-				// Model: accumulate failure, prune window, check threshold
-				m.pruneFailures()
-				if len(m.failures) < m.threshold {
-					m.failures = append(m.failures, m.elapsed)
-				}
-
-				if len(m.failures) >= m.threshold && m.model == CircuitClosed {
-					m.model = CircuitOpen
-					m.openedAt = m.elapsed
-				}
+				latency := time.Duration(rapid.IntRange(1, 2000).Draw(t, "latencyMs")) * time.Millisecond
+				m.request(t, latency, true)
 			},
 			"": func(t *rapid.T) {
 				// Invariant checks after every action
@@ -1713,16 +1929,28 @@ func TestProperty_CircuitBreaker_StateMachine(t *testing.T) {
 
 				// Open circuit must reject
 				if m.model == CircuitOpen && m.elapsed-m.openedAt < m.cooldown {
-					_, _, rejected, _ := m.state.Admit(m.elapsed, m.rng)
+					_, _, rejected, _ := m.state.Admit(simAt(m.elapsed), m.elapsed, m.rng)
+					m.settle()
 					if !rejected {
 						t.Fatal("Open circuit within cooldown should reject")
 					}
 				}
 
-				// Active requests should never be negative
+				// Half-open probes never exceed the cap
+				if m.maxProbes > 0 && m.state.HalfOpenProbes > m.maxProbes {
+					t.Fatalf("half-open probes %d exceed cap %d", m.state.HalfOpenProbes, m.maxProbes)
+				}
+				if m.state.HalfOpenProbes != m.probes {
+					t.Fatalf("probe count mismatch: real=%d, model=%d", m.state.HalfOpenProbes, m.probes)
+				}
+
+				// Active requests should never be negative and match the model
 				if m.state.ActiveRequests < 0 {
 					t.Fatalf("negative active requests: %d", m.state.ActiveRequests)
 				}
+				if m.state.ActiveRequests != m.inFlight {
+					t.Fatalf("active requests mismatch: real=%d, model=%d", m.state.ActiveRequests, m.inFlight)
+				}
 			},
 		})
 	})
@@ -4,6 +4,8 @@ package synth
 
 import (
 	"fmt"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -70,3 +72,37 @@ func (r Rate) Count() int {
 func (r Rate) Period() time.Duration {
 	return r.period
 }
+
+// PerSecond returns the rate in requests per second.
+func (r Rate) PerSecond() float64 {
+	return float64(r.count) / r.period.Seconds()
+}
+
+// rateUnits are the units a rate string can be written in, finest first.
+var rateUnits = []struct {
+	name   string
+	period time.Duration
+}{
+	{"s", time.Second},
+	{"m", time.Minute},
+	{"h", time.Hour},
+}
+
+// formatRate writes perSecond as a rate string: exactly in the finest unit
+// that gives a whole count, or otherwise rounded in the coarsest unit whose
+// count fits MaxRateCount. Rates below 1/h are written as 1/h.
+func formatRate(perSecond float64) (string, error) {
+	for _, u := range rateUnits {
+		// Allow for floating-point error in a scaled rate that is whole.
+		count := perSecond * u.period.Seconds()
+		if whole := math.Round(count); whole >= 1 && whole <= MaxRateCount && math.Abs(count-whole) < 1e-9 {
+			return fmt.Sprintf("%d/%s", int(whole), u.name), nil
+		}
+	}
+	for _, u := range slices.Backward(rateUnits) {
+		if count := math.Round(perSecond * u.period.Seconds()); count <= MaxRateCount {
+			return fmt.Sprintf("%d/%s", max(int(count), 1), u.name), nil
+		}
+	}
+	return "", fmt.Errorf("rate of %g/s exceeds %d/s", perSecond, MaxRateCount)
+}
@@ -0,0 +1,162 @@
+// Regex attribute values: random strings matching a regular expression
+// Expands literals, character classes, bounded quantifiers, and alternation
+package synth
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRegexRepeat bounds the extra repetitions generated for an unbounded
+// quantifier such as * or +, keeping generated values short.
+const maxRegexRepeat = 8
+
+// RegexValue generates strings that match a regular expression.
+type RegexValue struct {
+	Pattern string
+	root    *regexNode
+}
+
+// regexNode is a parsed regex reduced to the constructs RegexValue expands.
+type regexNode struct {
+	op       syntax.Op
+	literal  string
+	ranges   []rune // lo, hi pairs for a character class
+	subs     []*regexNode
+	min, max int // repeat bounds; max is -1 when unbounded
+}
+
+// NewRegexValue parses pattern and rejects constructs that cannot be
+// expanded, such as word boundaries, so invalid patterns fail validation.
+func NewRegexValue(pattern string) (*RegexValue, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("regex %q: %w", pattern, err)
+	}
+	root, err := compileRegexNode(re)
+	if err != nil {
+		return nil, fmt.Errorf("regex %q: %w", pattern, err)
+	}
+	return &RegexValue{Pattern: pattern, root: root}, nil
+}
+
+// Generate returns a random string matching the pattern.
+func (r *RegexValue) Generate(rng *rand.Rand) any {
+	var b strings.Builder
+	r.root.expand(&b, rng)
+	return b.String()
+}
+
+func compileRegexNode(re *syntax.Regexp) (*regexNode, error) {
+	node := &regexNode{op: re.Op}
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		// Anchors match the empty string at the ends of generated values.
+		node.op = syntax.OpEmptyMatch
+		return node, nil
+	case syntax.OpLiteral:
+		node.literal = string(re.Rune)
+		return node, nil
+	case syntax.OpCharClass:
+		node.ranges = printableRanges(re.Rune)
+		if len(node.ranges) == 0 {
+			return nil, fmt.Errorf("character class matches no characters")
+		}
+		return node, nil
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		node.op = syntax.OpCharClass
+		node.ranges = []rune{' ', '~'}
+		return node, nil
+	case syntax.OpCapture:
+		return compileRegexNode(re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		node.op = syntax.OpRepeat
+		switch re.Op {
+		case syntax.OpStar:
+			node.min, node.max = 0, -1
+		case syntax.OpPlus:
+			node.min, node.max = 1, -1
+		case syntax.OpQuest:
+			node.min, node.max = 0, 1
+		default:
+			node.min, node.max = re.Min, re.Max
+		}
+	case syntax.OpConcat, syntax.OpAlternate:
+	default:
+		return nil, fmt.Errorf("unsupported construct %s; use literals, character classes, quantifiers, groups, and alternation", re.Op)
+	}
+	for _, sub := range re.Sub {
+		n, err := compileRegexNode(sub)
+		if err != nil {
+			return nil, err
+		}
+		node.subs = append(node.subs, n)
+	}
+	return node, nil
+}
+
+// printableRanges narrows a character class to printable ASCII when it
+// includes any, so classes like [^0-9] produce readable values. Classes
+// with no printable ASCII keep their valid, non-surrogate runes.
+func printableRanges(ranges []rune) []rune {
+	if r := intersectRanges(ranges, ' ', '~'); len(r) > 0 {
+		return r
+	}
+	out := intersectRanges(ranges, 0, 0xD7FF)
+	return append(out, intersectRanges(ranges, 0xE000, utf8.MaxRune)...)
+}
+
+func intersectRanges(ranges []rune, lo, hi rune) []rune {
+	var out []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		l, h := max(ranges[i], lo), min(ranges[i+1], hi)
+		if l <= h {
+			out = append(out, l, h)
+		}
+	}
+	return out
+}
+
+func (n *regexNode) expand(b *strings.Builder, rng *rand.Rand) {
+	switch n.op {
+	case syntax.OpLiteral:
+		b.WriteString(n.literal)
+	case syntax.OpCharClass:
+		b.WriteRune(pickRune(n.ranges, rng))
+	case syntax.OpConcat:
+		for _, sub := range n.subs {
+			sub.expand(b, rng)
+		}
+	case syntax.OpAlternate:
+		n.subs[rng.IntN(len(n.subs))].expand(b, rng)
+	case syntax.OpRepeat:
+		hi := n.max
+		if hi < 0 {
+			hi = n.min + maxRegexRepeat
+		}
+		count := n.min + rng.IntN(hi-n.min+1)
+		for range count {
+			n.subs[0].expand(b, rng)
+		}
+	}
+}
+
+// pickRune chooses uniformly among all runes in the lo, hi range pairs.
+func pickRune(ranges []rune, rng *rand.Rand) rune {
+	total := 0
+	for i := 0; i < len(ranges); i += 2 {
+		total += int(ranges[i+1]-ranges[i]) + 1
+	}
+	k := rng.IntN(total)
+	for i := 0; i < len(ranges); i += 2 {
+		size := int(ranges[i+1]-ranges[i]) + 1
+		if k < size {
+			return ranges[i] + rune(k)
+		}
+		k -= size
+	}
+	return ranges[len(ranges)-2]
+}
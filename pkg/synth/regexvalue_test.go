@@ -0,0 +1,83 @@
+// Tests for regex attribute value generation
+package synth
+
+import (
+	"math/rand/v2"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		pattern string
+	}{
+		{"order id", `ORD-[0-9]{6}`},
+		{"alternation", `(GET|POST|DELETE) /api/v[12]/items`},
+		{"quantifiers", `[a-z]+-\d*-x?`},
+		{"bounded range", `[A-F0-9]{2,4}`},
+		{"negated class", `[^0-9]{5}`},
+		{"any char", `id:.{3}`},
+		{"anchored", `^user-\w{4}$`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			gen, err := NewRegexValue(tt.pattern)
+			require.NoError(t, err)
+			re := regexp.MustCompile(`^(?:` + tt.pattern + `)$`)
+			rng := rand.New(rand.NewPCG(42, 0)) //nolint:gosec // deterministic seed for testing
+			for range 200 {
+				v := gen.Generate(rng).(string)
+				assert.Regexp(t, re, v)
+			}
+		})
+	}
+}
+
+func TestRegexValueRejectsUnsupported(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+	}{
+		{"word boundary", `\bword\b`, "unsupported construct"},
+		{"invalid syntax", `ORD-[0-9`, "missing closing ]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewAttributeGenerator(AttributeValueConfig{Regex: tt.pattern})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestValidateConfigRejectsUnsupportedRegex(t *testing.T) {
+	t.Parallel()
+	cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  orders:
+    operations:
+      create:
+        duration: 10ms
+        attributes:
+          order.id: {regex: '\bORD\b'}
+traffic:
+  rate: 10/s
+`))
+	require.NoError(t, err)
+	err = ValidateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `attribute "order.id"`)
+	assert.Contains(t, err.Error(), "unsupported construct")
+}
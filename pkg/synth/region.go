@@ -0,0 +1,90 @@
+// Service regions: the weighted cloud.region each trace's instance of a service runs in
+// Picked once per service per trace so every span of that service agrees
+package synth
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// regionAttribute is the span attribute naming the region a service's span
+// ran in. It is a span attribute rather than a resource attribute because
+// the region changes from trace to trace under one resource.
+const regionAttribute = "cloud.region"
+
+// validateRegions checks a service's regions block: region names must not be
+// empty and weights must be positive.
+func validateRegions(regions map[string]int) error {
+	for _, name := range slices.Sorted(maps.Keys(regions)) {
+		if name == "" {
+			return fmt.Errorf("regions: region name must not be empty")
+		}
+		if regions[name] <= 0 {
+			return fmt.Errorf("regions: weight for %q must be positive, got %d", name, regions[name])
+		}
+	}
+	return nil
+}
+
+// buildRegions returns the weighted choice among a service's regions, or
+// nil when it declares none.
+func buildRegions(regions map[string]int) (*WeightedChoice, error) {
+	if len(regions) == 0 {
+		return nil, nil
+	}
+	values := make(map[any]int, len(regions))
+	for name, weight := range regions {
+		values[name] = weight
+	}
+	return newWeightedChoice(values)
+}
+
+// traceRegions records the region picked for each regional service in one
+// trace. It is shared by every span of the trace, so a service's spans all
+// report the region first picked for it.
+type traceRegions map[string]attribute.KeyValue
+
+// newTraceRegions returns an empty traceRegions for a new trace, or nil when
+// no service in topo declares regions.
+func newTraceRegions(topo *Topology) traceRegions {
+	if !topo.regional {
+		return nil
+	}
+	return make(traceRegions)
+}
+
+// region returns the region attribute of svc in this trace, picking the
+// region by weight the first time the service appears. It reports false
+// without consuming randomness when svc declares no regions.
+func (r traceRegions) region(svc *Service, rng *rand.Rand) (attribute.KeyValue, bool) {
+	if r == nil || svc.Regions == nil {
+		return attribute.KeyValue{}, false
+	}
+	kv, ok := r[svc.Name]
+	if !ok {
+		kv = attribute.String(regionAttribute, fmt.Sprint(svc.Regions.Generate(rng)))
+		r[svc.Name] = kv
+	}
+	return kv, true
+}
+
+// traceRegionsKey carries a trace's traceRegions from its root span down to
+// its descendants.
+type traceRegionsKey struct{}
+
+func withTraceRegions(ctx context.Context, regions traceRegions) context.Context {
+	if regions == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, traceRegionsKey{}, regions)
+}
+
+func traceRegionsFromContext(ctx context.Context) traceRegions {
+	regions, _ := ctx.Value(traceRegionsKey{}).(traceRegions)
+	return regions
+}
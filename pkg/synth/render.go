@@ -0,0 +1,129 @@
+// Config rendering: a loaded Config written back out as a single YAML topology
+// Includes, templates, vars, and anchors are already expanded, so the output shows what runs
+package synth
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalConfig encodes cfg as a YAML topology that ParseConfig reads back
+// into an equal Config. Services and operations are written as the maps of
+// the DSL, with keys in sorted order, so the output is deterministic.
+func MarshalConfig(cfg *Config) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(rawFromConfig(cfg)); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// rawFromConfig reverses configFromRaw, turning the ordered services and
+// operations back into maps keyed by name.
+func rawFromConfig(cfg *Config) *rawConfig {
+	version := cfg.Version
+	raw := &rawConfig{
+		Version:         &version,
+		Mode:            cfg.Mode,
+		Recording:       cfg.Recording,
+		ScopeName:       cfg.ScopeName,
+		ScopeVersion:    cfg.ScopeVersion,
+		Services:        make(map[string]rawServiceConfig, len(cfg.Services)),
+		Traffic:         cfg.Traffic,
+		Scenarios:       cfg.Scenarios,
+		TraceAttributes: cfg.TraceAttributes,
+		TraceState:      cfg.TraceState,
+		Observers:       cfg.Observers,
+	}
+	for _, svc := range cfg.Services {
+		rawSvc := rawServiceConfig{
+			ResourceAttributes:  svc.ResourceAttributes,
+			Attributes:          svc.Attributes,
+			DefaultAttributes:   svc.DefaultAttributes,
+			Baggage:             svc.Baggage,
+			BaggageAsAttributes: svc.BaggageAsAttributes,
+			TraceState:          svc.TraceState,
+			Regions:             svc.Regions,
+			Metrics:             svc.Metrics,
+			Logs:                svc.Logs,
+			SLO:                 svc.SLO,
+			Flap:                svc.Flap,
+			Operations:          make(map[string]rawOperationConfig, len(svc.Operations)),
+			ScopeName:           svc.ScopeName,
+			ScopeVersion:        svc.ScopeVersion,
+		}
+		for _, op := range svc.Operations {
+			rawSvc.Operations[op.Name] = rawOperationConfig{
+				Domain:              op.Domain,
+				Domains:             op.Domains,
+				Duration:            durationConfig(op.Duration),
+				ErrorRate:           op.ErrorRate,
+				ErrorRateAtPeak:     op.ErrorRateAtPeak,
+				ErrorTypes:          op.ErrorTypes,
+				ErrorMessage:        op.ErrorMessage,
+				SwallowErrors:       op.SwallowErrors,
+				Disabled:            op.Disabled,
+				SpanName:            op.SpanName,
+				Calls:               op.Calls,
+				OneOf:               op.OneOf,
+				CallStyle:           op.CallStyle,
+				CallJitter:          durationConfig(op.CallJitter),
+				Timing:              op.Timing,
+				SlowThreshold:       op.SlowThreshold,
+				SlowStatus:          op.SlowStatus,
+				Attributes:          op.Attributes,
+				Baggage:             op.Baggage,
+				BaggageAsAttributes: op.BaggageAsAttributes,
+				GeneratedBaggage:    op.GeneratedBaggage,
+				Events:              op.Events,
+				Links:               op.Links,
+				Metrics:             op.Metrics,
+				Logs:                op.Logs,
+				Concurrency:         op.Concurrency,
+				QueueDepth:          op.QueueDepth,
+				Backpressure:        op.Backpressure,
+				CircuitBreaker:      op.CircuitBreaker,
+				RateLimit:           op.RateLimit,
+				ColdStart:           op.ColdStart,
+				Cache:               op.Cache,
+				Traffic:             op.Traffic,
+			}
+		}
+		raw.Services[svc.Name] = rawSvc
+	}
+	return raw
+}
+
+// MarshalYAML writes a call in the mapping form, with its probability as a
+// {base, decay} curve when it decays with depth.
+func (c CallConfig) MarshalYAML() (any, error) {
+	type plain CallConfig
+	var node yaml.Node
+	if err := node.Encode(plain(c)); err != nil {
+		return nil, err
+	}
+	if c.ProbabilityDecay == 0 {
+		return &node, nil
+	}
+
+	var curve yaml.Node
+	if err := curve.Encode(probabilityCurveConfig{Base: &c.Probability, Decay: &c.ProbabilityDecay}); err != nil {
+		return nil, err
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "probability" {
+			node.Content[i+1] = &curve
+			return &node, nil
+		}
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "probability"}
+	node.Content = append(node.Content, key, &curve)
+	return &node, nil
+}
@@ -0,0 +1,90 @@
+// Tests for rendering a loaded config back to YAML
+package synth
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalConfigInlinesTemplates(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(`
+version: 1
+vars:
+  route: /users
+templates:
+  http-endpoint:
+    domain: http
+    duration: 20ms +/- 5ms
+    attributes:
+      http.route:
+        value: "{{ vars.route }}"
+services:
+  api:
+    operations:
+      GET /users:
+        template: http-endpoint
+        calls:
+          - target: db.query
+            probability: {base: 0.9, decay: 0.5}
+      POST /users:
+        template: http-endpoint
+        duration: 80ms
+  db:
+    operations:
+      query:
+        duration: {p50: 2ms, p99: 20ms}
+traffic:
+  rate: 10/s
+`))
+	require.NoError(t, err)
+
+	data, err := MarshalConfig(cfg)
+	require.NoError(t, err)
+	out := string(data)
+	assert.NotContains(t, out, "templates:")
+	assert.NotContains(t, out, "template:")
+	assert.NotContains(t, out, "vars")
+	assert.Contains(t, out, "domain: http")
+	assert.Contains(t, out, "value: /users")
+
+	again, err := ParseConfig(data)
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(again))
+	assert.Equal(t, cfg, again)
+
+	api := again.Services[0]
+	require.Len(t, api.Operations, 2)
+	for _, op := range api.Operations {
+		assert.Equal(t, "http", op.Domain, op.Name)
+		assert.Equal(t, "/users", op.Attributes["http.route"].Value, op.Name)
+	}
+	assert.Equal(t, "80ms", api.Operations[1].Duration)
+}
+
+func TestMarshalConfigRoundTripsExamples(t *testing.T) {
+	t.Parallel()
+
+	paths, err := filepath.Glob("../../docs/examples/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			t.Parallel()
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Skipf("not a loadable topology: %v", err)
+			}
+			data, err := MarshalConfig(cfg)
+			require.NoError(t, err)
+			again, err := ParseConfig(data)
+			require.NoError(t, err, strings.SplitN(string(data), "\n", 40))
+			assert.Equal(t, cfg, again)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Retry links: span links from each retry attempt to the attempt before it
+// Enabled by Engine.LinkRetries so a failed call's attempts can be followed in order
+package synth
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// retryAttemptAttribute is the link attribute carrying the attempt number,
+// counting from 1, of the attempt the link points to.
+const retryAttemptAttribute = "synth.retry.attempt"
+
+// retryAttempt carries one attempt of a retried call into walkTrace: the
+// span context of the previous attempt to link to, and, once the attempt's
+// span starts, its own span context for the next attempt to link to.
+type retryAttempt struct {
+	number   int
+	previous trace.SpanContext
+	span     trace.SpanContext
+}
+
+// link returns the link from this attempt to the previous one. It reports
+// false for the first attempt, or when the previous attempt emitted no span.
+func (a *retryAttempt) link() (trace.Link, bool) {
+	if a == nil || !a.previous.IsValid() {
+		return trace.Link{}, false
+	}
+	return retryLink(a.previous, a.number-1), true
+}
+
+// retryLink returns a link to the span of attempt number previous.
+func retryLink(sc trace.SpanContext, previous int) trace.Link {
+	return trace.Link{
+		SpanContext: sc,
+		Attributes:  []attribute.KeyValue{attribute.Int(retryAttemptAttribute, previous)},
+	}
+}
+
+// retryAttemptKey carries a retryAttempt from executeCall to the span of the
+// attempt's callee. walkTrace clears it before descending, so the callee's
+// own calls are not mistaken for retries.
+type retryAttemptKey struct{}
+
+func withRetryAttempt(ctx context.Context, attempt *retryAttempt) context.Context {
+	return context.WithValue(ctx, retryAttemptKey{}, attempt)
+}
+
+func retryAttemptFromContext(ctx context.Context) *retryAttempt {
+	attempt, _ := ctx.Value(retryAttemptKey{}).(*retryAttempt)
+	return attempt
+}
@@ -9,6 +9,8 @@ import (
 	"slices"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Scenario is a resolved, time-windowed set of operation overrides.
@@ -19,6 +21,14 @@ type Scenario struct {
 	Priority  int
 	Overrides map[string]Override
 	Traffic   TrafficPattern
+	// TrafficMultiplier scales the traffic rate while the scenario is
+	// active, as a spike does; zero leaves it unscaled.
+	TrafficMultiplier float64
+	// TraceAttributes are added to the topology's trace attributes for
+	// traces started while the scenario is active.
+	TraceAttributes Attributes
+	// Labels are added to every span emitted while the scenario is active.
+	Labels map[string]string
 }
 
 // Override holds resolved per-operation or per-service overrides within a scenario.
@@ -32,6 +42,28 @@ type Override struct {
 	Metrics      map[string]FloatDistribution
 	AddLogs      []LogDefinition
 	DisableLogs  bool
+	// ErrorRateMultiply scales the operation's base error rate when no
+	// absolute ErrorRate is set; zero leaves it unscaled.
+	ErrorRateMultiply float64
+	// Drained marks an operation of a service drained by the scenario: it
+	// emits no span, and a call that reaches it fails at once when
+	// DrainErrors is set, or is skipped otherwise.
+	Drained     bool
+	DrainErrors bool
+}
+
+// errorRate returns the error rate under the override for an operation whose
+// own rate is base: an absolute ErrorRate wins, otherwise ErrorRateMultiply
+// scales base, clamped to [0, 1].
+func (o Override) errorRate(base float64) float64 {
+	switch {
+	case o.HasErrorRate:
+		return o.ErrorRate
+	case o.ErrorRateMultiply > 0:
+		return min(base*o.ErrorRateMultiply, 1)
+	default:
+		return base
+	}
 }
 
 // ParseOffset parses a time offset string like "+5m" or "30s" into a duration.
@@ -51,6 +83,26 @@ func ParseOffset(s string) (time.Duration, error) {
 	return d, nil
 }
 
+// scenarioDuration returns the length of a scenario's window: its spike's
+// duration for a spike, otherwise its duration.
+func scenarioDuration(cfg ScenarioConfig) (time.Duration, error) {
+	field, value := "duration", cfg.Duration
+	if cfg.Spike != nil {
+		if cfg.Duration != "" {
+			return 0, fmt.Errorf("scenario %q: a spike sets its own duration; remove the scenario's duration", cfg.Name)
+		}
+		field, value = "spike: duration", cfg.Spike.Duration
+	}
+	dur, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("scenario %q: invalid %s: %w", cfg.Name, field, err)
+	}
+	if dur <= 0 {
+		return 0, fmt.Errorf("scenario %q: %s must be positive, got %q", cfg.Name, field, value)
+	}
+	return dur, nil
+}
+
 // BuildScenarios converts scenario configs into resolved Scenarios.
 // The topology is required to resolve add_calls targets to *Operation pointers.
 func BuildScenarios(cfgs []ScenarioConfig, topo *Topology) ([]Scenario, error) {
@@ -60,93 +112,35 @@ func BuildScenarios(cfgs []ScenarioConfig, topo *Topology) ([]Scenario, error) {
 		if err != nil {
 			return nil, fmt.Errorf("scenario %q: invalid at: %w", cfg.Name, err)
 		}
-		dur, err := time.ParseDuration(cfg.Duration)
+		dur, err := scenarioDuration(cfg)
 		if err != nil {
-			return nil, fmt.Errorf("scenario %q: invalid duration: %w", cfg.Name, err)
-		}
-		if dur <= 0 {
-			return nil, fmt.Errorf("scenario %q: duration must be positive, got %q", cfg.Name, cfg.Duration)
+			return nil, err
 		}
 
-		overrides := make(map[string]Override, len(cfg.Override))
-		for ref, ov := range cfg.Override {
-			var o Override
-			if ov.Duration != "" {
-				o.Duration, err = ParseDistribution(ov.Duration)
-				if err != nil {
-					return nil, fmt.Errorf("scenario %q override %q: %w", cfg.Name, ref, err)
-				}
-			}
-			if ov.ErrorRate != "" {
-				o.ErrorRate, err = parseErrorRate(ov.ErrorRate)
-				if err != nil {
-					return nil, fmt.Errorf("scenario %q override %q: %w", cfg.Name, ref, err)
-				}
-				o.HasErrorRate = true
-			}
-			if len(ov.Attributes) > 0 {
-				gens := make(map[string]AttributeGenerator, len(ov.Attributes))
-				for attrName, attrCfg := range ov.Attributes {
-					gen, genErr := NewAttributeGenerator(attrCfg)
-					if genErr != nil {
-						return nil, fmt.Errorf("scenario %q override %q: attribute %q: %w", cfg.Name, ref, attrName, genErr)
-					}
-					gens[attrName] = gen
-				}
-				o.Attributes = NewAttributes(gens)
+		// Drain overrides come first, so that match and explicit overrides
+		// merge over them.
+		overrides := drainOverrides(cfg.Drain, cfg.DrainMode, topo)
+		for i, m := range cfg.Match {
+			refs := matchOperations(topo, m)
+			if len(refs) == 0 {
+				return nil, fmt.Errorf("scenario %q: match %d: %s matches no operations", cfg.Name, i+1, m.selector())
 			}
-			for _, callCfg := range ov.AddCalls {
-				_, targetOp, resolveErr := resolveRef(topo, callCfg.Target)
-				if resolveErr != nil {
-					return nil, fmt.Errorf("scenario %q override %q: add_calls: %w", cfg.Name, ref, resolveErr)
-				}
-				call := Call{
-					Operation:   targetOp,
-					Probability: callCfg.Probability,
-					Condition:   callCfg.Condition,
-					Count:       callCfg.Count,
-					Retries:     callCfg.Retries,
-					Async:       callCfg.Async,
-					Producer:    callCfg.Producer,
-				}
-				if callCfg.Timeout != "" {
-					call.Timeout, err = time.ParseDuration(callCfg.Timeout)
-					if err != nil {
-						return nil, fmt.Errorf("scenario %q override %q: add_calls: target %q: invalid timeout: %w", cfg.Name, ref, callCfg.Target, err)
-					}
-				}
-				if callCfg.RetryBackoff != "" {
-					call.RetryBackoff, err = time.ParseDuration(callCfg.RetryBackoff)
-					if err != nil {
-						return nil, fmt.Errorf("scenario %q override %q: add_calls: target %q: invalid retry_backoff: %w", cfg.Name, ref, callCfg.Target, err)
-					}
-				}
-				o.AddCalls = append(o.AddCalls, call)
+			o, err := buildOverride(cfg.Name, fmt.Sprintf("match %d", i+1), m.OverrideConfig, topo)
+			if err != nil {
+				return nil, err
 			}
-			if len(ov.RemoveCalls) > 0 {
-				o.RemoveCalls = make(map[string]bool, len(ov.RemoveCalls))
-				for _, rc := range ov.RemoveCalls {
-					o.RemoveCalls[rc.Target] = true
-				}
+			for _, ref := range refs {
+				overrides[ref] = mergeOverride(overrides[ref], o)
 			}
-			if len(ov.Metrics) > 0 {
-				o.Metrics = make(map[string]FloatDistribution, len(ov.Metrics))
-				for name, mo := range ov.Metrics {
-					dist, distErr := ParseFloatDistribution(mo.Value)
-					if distErr != nil {
-						return nil, fmt.Errorf("scenario %q override %q: metric %q: %w", cfg.Name, ref, name, distErr)
-					}
-					o.Metrics[name] = dist
-				}
+		}
+		// Explicit refs are applied over any match, field by field.
+		for ref, ov := range cfg.Override {
+			o, err := buildOverride(cfg.Name, ref, ov, topo)
+			if err != nil {
+				return nil, err
 			}
-			if ov.Logs != nil {
-				o.DisableLogs = ov.Logs.Disable
-				if len(ov.Logs.Add) > 0 {
-					o.AddLogs, err = resolveLogs(ov.Logs.Add, fmt.Sprintf("scenario %q override %q: logs", cfg.Name, ref))
-					if err != nil {
-						return nil, err
-					}
-				}
+			if matched, ok := overrides[ref]; ok {
+				o = mergeOverride(matched, o)
 			}
 			overrides[ref] = o
 		}
@@ -157,6 +151,7 @@ func BuildScenarios(cfgs []ScenarioConfig, topo *Topology) ([]Scenario, error) {
 			End:       start + dur,
 			Priority:  cfg.Priority,
 			Overrides: overrides,
+			Labels:    cfg.Labels,
 		}
 
 		if cfg.Traffic != nil {
@@ -165,6 +160,21 @@ func BuildScenarios(cfgs []ScenarioConfig, topo *Topology) ([]Scenario, error) {
 				return nil, fmt.Errorf("scenario %q: traffic: %w", cfg.Name, err)
 			}
 		}
+		if cfg.Spike != nil {
+			scenario.TrafficMultiplier = cfg.Spike.Multiplier
+		}
+
+		if len(cfg.TraceAttributes) > 0 {
+			gens := make(map[string]AttributeGenerator, len(cfg.TraceAttributes))
+			for attrName, attrCfg := range cfg.TraceAttributes {
+				gen, genErr := NewAttributeGenerator(attrCfg)
+				if genErr != nil {
+					return nil, fmt.Errorf("scenario %q: trace_attributes: attribute %q: %w", cfg.Name, attrName, genErr)
+				}
+				gens[attrName] = gen
+			}
+			scenario.TraceAttributes = NewAttributes(gens)
+		}
 
 		if err := validateScenarioCycles(scenario, topo); err != nil {
 			return nil, err
@@ -175,6 +185,142 @@ func BuildScenarios(cfgs []ScenarioConfig, topo *Topology) ([]Scenario, error) {
 	return scenarios, nil
 }
 
+// buildOverride resolves one override config. ref names the override in
+// errors: an operation or service ref, or "match N" for a scenario match.
+func buildOverride(scenario, ref string, ov OverrideConfig, topo *Topology) (Override, error) {
+	var o Override
+	var err error
+	if ov.Duration != "" {
+		o.Duration, err = ParseDistribution(ov.Duration)
+		if err != nil {
+			return Override{}, fmt.Errorf("scenario %q override %q: %w", scenario, ref, err)
+		}
+	}
+	if ov.ErrorRate != "" {
+		o.ErrorRate, err = parseErrorRate(ov.ErrorRate)
+		if err != nil {
+			return Override{}, fmt.Errorf("scenario %q override %q: %w", scenario, ref, err)
+		}
+		o.HasErrorRate = true
+	}
+	o.ErrorRateMultiply = ov.ErrorRateMultiply
+	if len(ov.Attributes) > 0 {
+		gens := make(map[string]AttributeGenerator, len(ov.Attributes))
+		for attrName, attrCfg := range ov.Attributes {
+			gen, genErr := NewAttributeGenerator(attrCfg)
+			if genErr != nil {
+				return Override{}, fmt.Errorf("scenario %q override %q: attribute %q: %w", scenario, ref, attrName, genErr)
+			}
+			gens[attrName] = gen
+		}
+		o.Attributes = NewAttributes(gens)
+	}
+	for _, callCfg := range ov.AddCalls {
+		_, targetOp, resolveErr := resolveRef(topo, callCfg.Target)
+		if resolveErr != nil {
+			return Override{}, fmt.Errorf("scenario %q override %q: add_calls: %w", scenario, ref, resolveErr)
+		}
+		call := Call{
+			Operation:              targetOp,
+			Probability:            callCfg.Probability,
+			ProbabilityDecay:       callCfg.ProbabilityDecay,
+			Condition:              callCfg.Condition,
+			Count:                  callCfg.Count,
+			Retries:                callCfg.Retries,
+			RetryBackoffMultiplier: max(callCfg.RetryBackoffMultiplier, 1),
+			RetryJitter:            callCfg.RetryJitter,
+			Async:                  callCfg.Async,
+			Producer:               callCfg.Producer,
+		}
+		if callCfg.ErrorRate != "" {
+			call.ErrorRate, err = parseErrorRate(callCfg.ErrorRate)
+			if err != nil {
+				return Override{}, fmt.Errorf("scenario %q override %q: add_calls: target %q: %w", scenario, ref, callCfg.Target, err)
+			}
+			call.HasErrorRate = true
+		}
+		if callCfg.CountDistribution != "" {
+			call.CountDist, err = ParseCountDistribution(callCfg.CountDistribution)
+			if err != nil {
+				return Override{}, fmt.Errorf("scenario %q override %q: add_calls: target %q: invalid count_distribution: %w", scenario, ref, callCfg.Target, err)
+			}
+		}
+		if callCfg.Timeout != "" {
+			call.Timeout, err = time.ParseDuration(callCfg.Timeout)
+			if err != nil {
+				return Override{}, fmt.Errorf("scenario %q override %q: add_calls: target %q: invalid timeout: %w", scenario, ref, callCfg.Target, err)
+			}
+		}
+		if callCfg.RetryBackoff != "" {
+			call.RetryBackoff, err = time.ParseDuration(callCfg.RetryBackoff)
+			if err != nil {
+				return Override{}, fmt.Errorf("scenario %q override %q: add_calls: target %q: invalid retry_backoff: %w", scenario, ref, callCfg.Target, err)
+			}
+		}
+		if callCfg.HedgeAfter != "" {
+			call.HedgeAfter, err = time.ParseDuration(callCfg.HedgeAfter)
+			if err != nil {
+				return Override{}, fmt.Errorf("scenario %q override %q: add_calls: target %q: invalid hedge_after: %w", scenario, ref, callCfg.Target, err)
+			}
+		}
+		if len(callCfg.Attributes) > 0 {
+			gens := make(map[string]AttributeGenerator, len(callCfg.Attributes))
+			for attrName, attrCfg := range callCfg.Attributes {
+				gen, genErr := NewAttributeGenerator(attrCfg)
+				if genErr != nil {
+					return Override{}, fmt.Errorf("scenario %q override %q: add_calls: target %q: attribute %q: %w", scenario, ref, callCfg.Target, attrName, genErr)
+				}
+				gens[attrName] = gen
+			}
+			call.Attributes = NewAttributes(gens)
+		}
+		o.AddCalls = append(o.AddCalls, call)
+	}
+	if len(ov.RemoveCalls) > 0 {
+		o.RemoveCalls = make(map[string]bool, len(ov.RemoveCalls))
+		for _, rc := range ov.RemoveCalls {
+			o.RemoveCalls[rc.Target] = true
+		}
+	}
+	if len(ov.Metrics) > 0 {
+		o.Metrics = make(map[string]FloatDistribution, len(ov.Metrics))
+		for name, mo := range ov.Metrics {
+			dist, distErr := ParseFloatDistribution(mo.Value)
+			if distErr != nil {
+				return Override{}, fmt.Errorf("scenario %q override %q: metric %q: %w", scenario, ref, name, distErr)
+			}
+			o.Metrics[name] = dist
+		}
+	}
+	if ov.Logs != nil {
+		o.DisableLogs = ov.Logs.Disable
+		if len(ov.Logs.Add) > 0 {
+			o.AddLogs, err = resolveLogs(ov.Logs.Add, fmt.Sprintf("scenario %q override %q: logs", scenario, ref))
+			if err != nil {
+				return Override{}, err
+			}
+		}
+	}
+	return o, nil
+}
+
+// matchOperations returns the refs of the operations selected by m, sorted.
+func matchOperations(topo *Topology, m MatchOverrideConfig) []string {
+	var refs []string
+	for _, svc := range topo.Services {
+		if m.Service != "" && svc.Name != m.Service {
+			continue
+		}
+		for _, op := range svc.Operations {
+			if m.Domain == "" || slices.Contains(op.Domains, m.Domain) {
+				refs = append(refs, op.Ref)
+			}
+		}
+	}
+	slices.Sort(refs)
+	return refs
+}
+
 // HasCallChanges returns true if the override modifies the call graph.
 func (o Override) HasCallChanges() bool {
 	return len(o.AddCalls) > 0 || len(o.RemoveCalls) > 0
@@ -188,7 +334,7 @@ func validateScenarioCycles(sc Scenario, topo *Topology) error {
 	// Start with base topology edges
 	for _, svc := range topo.Services {
 		for _, op := range svc.Operations {
-			for _, call := range op.Calls {
+			for _, call := range slices.Concat(op.Calls, op.OneOf) {
 				adj[op.Ref] = append(adj[op.Ref], call.Operation.Ref)
 			}
 		}
@@ -258,6 +404,16 @@ func validateScenarioCycles(sc Scenario, topo *Topology) error {
 	return nil
 }
 
+// ScenariosEnd returns the elapsed time at which the last of scenarios ends,
+// or zero when there are none.
+func ScenariosEnd(scenarios []Scenario) time.Duration {
+	var latest time.Duration
+	for _, sc := range scenarios {
+		latest = max(latest, sc.End)
+	}
+	return latest
+}
+
 // ActiveScenarios returns scenarios whose activation window contains the given elapsed time.
 // Results are stable-sorted by priority (ascending) so higher-priority scenarios are
 // processed last in ResolveOverrides and their values win.
@@ -289,43 +445,98 @@ func ResolveOverrides(active []Scenario) map[string]Override {
 				merged[ref] = ov
 				continue
 			}
-			if ov.Duration.Mean > 0 {
-				existing.Duration = ov.Duration
-			}
-			if ov.HasErrorRate {
-				existing.ErrorRate = ov.ErrorRate
-				existing.HasErrorRate = true
-			}
-			if len(ov.Attributes) > 0 {
-				existing.Attributes = existing.Attributes.Merge(ov.Attributes)
-			}
-			if len(ov.AddCalls) > 0 {
-				existing.AddCalls = append(slices.Clone(existing.AddCalls), ov.AddCalls...)
-			}
-			if len(ov.RemoveCalls) > 0 {
-				if existing.RemoveCalls == nil {
-					existing.RemoveCalls = make(map[string]bool, len(ov.RemoveCalls))
-				}
-				maps.Copy(existing.RemoveCalls, ov.RemoveCalls)
-			}
-			if len(ov.Metrics) > 0 {
-				newMetrics := make(map[string]FloatDistribution, len(existing.Metrics)+len(ov.Metrics))
-				maps.Copy(newMetrics, existing.Metrics)
-				maps.Copy(newMetrics, ov.Metrics)
-				existing.Metrics = newMetrics
-			}
-			if len(ov.AddLogs) > 0 {
-				existing.AddLogs = append(slices.Clone(existing.AddLogs), ov.AddLogs...)
-			}
-			if ov.DisableLogs {
-				existing.DisableLogs = true
-			}
-			merged[ref] = existing
+			merged[ref] = mergeOverride(existing, ov)
 		}
 	}
 	return merged
 }
 
+// mergeOverride applies ov over existing: scalar fields ov sets replace
+// existing ones, attributes and metrics merge per key, and added calls and
+// logs accumulate.
+func mergeOverride(existing, ov Override) Override {
+	if ov.Duration.Mean > 0 {
+		existing.Duration = ov.Duration
+	}
+	if ov.HasErrorRate {
+		existing.ErrorRate = ov.ErrorRate
+		existing.HasErrorRate = true
+	}
+	if ov.ErrorRateMultiply > 0 {
+		existing.ErrorRateMultiply = ov.ErrorRateMultiply
+	}
+	if len(ov.Attributes) > 0 {
+		existing.Attributes = existing.Attributes.Merge(ov.Attributes)
+	}
+	if len(ov.AddCalls) > 0 {
+		existing.AddCalls = append(slices.Clone(existing.AddCalls), ov.AddCalls...)
+	}
+	if len(ov.RemoveCalls) > 0 {
+		if existing.RemoveCalls == nil {
+			existing.RemoveCalls = make(map[string]bool, len(ov.RemoveCalls))
+		}
+		maps.Copy(existing.RemoveCalls, ov.RemoveCalls)
+	}
+	if len(ov.Metrics) > 0 {
+		newMetrics := make(map[string]FloatDistribution, len(existing.Metrics)+len(ov.Metrics))
+		maps.Copy(newMetrics, existing.Metrics)
+		maps.Copy(newMetrics, ov.Metrics)
+		existing.Metrics = newMetrics
+	}
+	if len(ov.AddLogs) > 0 {
+		existing.AddLogs = append(slices.Clone(existing.AddLogs), ov.AddLogs...)
+	}
+	if ov.DisableLogs {
+		existing.DisableLogs = true
+	}
+	if ov.Drained {
+		existing.Drained = true
+		existing.DrainErrors = ov.DrainErrors
+	}
+	return existing
+}
+
+// ResolveTraceAttributes merges the trace attributes of the active scenarios
+// per key, with higher-priority scenarios winning. Expects active to be sorted
+// ascending by priority (as returned by ActiveScenarios).
+func ResolveTraceAttributes(active []Scenario) Attributes {
+	var merged Attributes
+	for _, sc := range active {
+		merged = merged.Merge(sc.TraceAttributes)
+	}
+	return merged
+}
+
+// ResolveLabels merges the labels of the active scenarios per key, with
+// higher-priority scenarios winning, and returns them as span attributes
+// sorted by key. Expects active to be sorted ascending by priority.
+func ResolveLabels(active []Scenario) []attribute.KeyValue {
+	merged := make(map[string]string)
+	for _, sc := range active {
+		maps.Copy(merged, sc.Labels)
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	labels := make([]attribute.KeyValue, 0, len(merged))
+	for _, k := range slices.Sorted(maps.Keys(merged)) {
+		labels = append(labels, attribute.String(k, merged[k]))
+	}
+	return labels
+}
+
+// ResolveTrafficMultiplier returns the product of the traffic multipliers
+// of the active scenarios, or 1 when none spike the traffic.
+func ResolveTrafficMultiplier(active []Scenario) float64 {
+	multiplier := 1.0
+	for _, sc := range active {
+		if sc.TrafficMultiplier > 0 {
+			multiplier *= sc.TrafficMultiplier
+		}
+	}
+	return multiplier
+}
+
 // ResolveTraffic returns the traffic pattern from the highest-priority active scenario
 // that has a traffic override, or nil if none do. Expects active to be sorted ascending
 // by priority (as returned by ActiveScenarios).
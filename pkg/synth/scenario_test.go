@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // minimalTopo returns a topology with a single "svc.op" operation for scenario tests.
@@ -256,6 +257,164 @@ func TestResolveOverridesWithPriority(t *testing.T) {
 	assert.InDelta(t, 0.1, overrides["svc.op"].ErrorRate, 0.001)
 }
 
+func TestOverrideErrorRate(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 0.1, Override{}.errorRate(0.1), 1e-9, "no override keeps the base rate")
+	assert.InDelta(t, 0.3, Override{ErrorRateMultiply: 3}.errorRate(0.1), 1e-9)
+	assert.InDelta(t, 1.0, Override{ErrorRateMultiply: 3}.errorRate(0.5), 1e-9, "the multiplied rate is clamped to 100%")
+	assert.InDelta(t, 0.05, Override{ErrorRateMultiply: 0.5}.errorRate(0.1), 1e-9)
+	assert.InDelta(t, 0.2, Override{ErrorRate: 0.2, HasErrorRate: true, ErrorRateMultiply: 3}.errorRate(0.1), 1e-9,
+		"an absolute error rate wins over a multiplier")
+}
+
+func TestResolveOverridesErrorRateMultiply(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []Scenario{
+		{Priority: 1, Overrides: map[string]Override{"svc.op": {ErrorRateMultiply: 2}}},
+		{Priority: 10, Overrides: map[string]Override{"svc.op": {ErrorRateMultiply: 5}}},
+	}
+	overrides := ResolveOverrides(scenarios)
+	assert.InDelta(t, 5.0, overrides["svc.op"].ErrorRateMultiply, 1e-9, "the higher-priority multiplier wins")
+
+	scenarios[1].Overrides["svc.op"] = Override{ErrorRate: 0.5, HasErrorRate: true}
+	overrides = ResolveOverrides(scenarios)
+	assert.InDelta(t, 0.5, overrides["svc.op"].errorRate(0.1), 1e-9, "an absolute rate from any scenario wins")
+}
+
+const matchScenarioConfig = `
+version: 1
+services:
+  api:
+    operations:
+      checkout:
+        duration: 10ms
+        domain: http
+        calls: [db.query, db.insert]
+  db:
+    operations:
+      query:
+        duration: 5ms
+      insert:
+        duration: 5ms
+traffic:
+  rate: 10/s
+scenarios:
+  - name: database degradation
+    at: 0s
+    duration: 1m
+    match:
+      - service: db
+        duration: 500ms
+        error_rate: 5%
+      - domain: http
+        error_rate_multiply: 2
+    override:
+      db.insert:
+        error_rate: 50%
+`
+
+func TestBuildScenariosMatch(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig([]byte(matchScenarioConfig))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+	resolver := func(domain string) map[string]AttributeGenerator {
+		if domain == "http" {
+			return map[string]AttributeGenerator{"http.request.method": &StaticValue{Value: "GET"}}
+		}
+		return nil
+	}
+	topo, err := BuildTopology(cfg, resolver)
+	require.NoError(t, err)
+
+	scenarios, err := BuildScenarios(cfg.Scenarios, topo)
+	require.NoError(t, err)
+	require.Len(t, scenarios, 1)
+	overrides := scenarios[0].Overrides
+
+	for _, ref := range []string{"db.query", "db.insert"} {
+		require.Contains(t, overrides, ref, "service: db selects every db operation")
+		assert.Equal(t, 500*time.Millisecond, overrides[ref].Duration.Mean)
+	}
+	assert.InDelta(t, 0.05, overrides["db.query"].ErrorRate, 1e-9)
+	assert.InDelta(t, 0.5, overrides["db.insert"].ErrorRate, 1e-9, "an explicit ref wins over the match")
+
+	require.Contains(t, overrides, "api.checkout", "domain: http selects operations in that domain")
+	assert.InDelta(t, 2.0, overrides["api.checkout"].ErrorRateMultiply, 1e-9)
+	assert.Zero(t, overrides["api.checkout"].Duration.Mean)
+}
+
+func TestBuildScenariosDrain(t *testing.T) {
+	t.Parallel()
+
+	build := func(t *testing.T, mode string) map[string]Override {
+		t.Helper()
+		cfg, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      checkout:
+        duration: 10ms
+        calls: [db.query, cache.get]
+  cache:
+    operations:
+      get:
+        duration: 1ms
+  db:
+    operations:
+      query:
+        duration: 5ms
+      backup:
+        duration: 1s
+traffic:
+  rate: 10/s
+scenarios:
+  - name: maintenance
+    at: +1m
+    duration: 5m
+    drain: [db]
+    drain_mode: ` + mode + `
+    override:
+      api.checkout:
+        duration: 50ms
+`))
+		require.NoError(t, err)
+		require.NoError(t, ValidateConfig(cfg))
+		topo, err := BuildTopology(cfg, nil)
+		require.NoError(t, err)
+		scenarios, err := BuildScenarios(cfg.Scenarios, topo)
+		require.NoError(t, err)
+		require.Len(t, scenarios, 1)
+		return scenarios[0].Overrides
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		t.Parallel()
+		overrides := build(t, "skip")
+		for _, ref := range []string{"db.query", "db.backup"} {
+			assert.True(t, overrides[ref].Drained, ref)
+			assert.False(t, overrides[ref].DrainErrors, ref)
+		}
+		assert.Equal(t, map[string]bool{"db.query": true}, overrides["api.checkout"].RemoveCalls)
+		assert.Equal(t, 50*time.Millisecond, overrides["api.checkout"].Duration.Mean, "explicit overrides merge over the drain")
+		assert.NotContains(t, overrides, "cache.get")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		overrides := build(t, "error")
+		for _, ref := range []string{"db.query", "db.backup"} {
+			assert.True(t, overrides[ref].Drained, ref)
+			assert.True(t, overrides[ref].DrainErrors, ref)
+		}
+		assert.Empty(t, overrides["api.checkout"].RemoveCalls, "calls are kept so they fail at the drained operation")
+	})
+}
+
 func TestBuildScenariosWithAttributes(t *testing.T) {
 	t.Parallel()
 
@@ -544,6 +703,49 @@ func TestResolveTraffic(t *testing.T) {
 	})
 }
 
+func TestBuildScenariosSpike(t *testing.T) {
+	t.Parallel()
+
+	scenarios, err := BuildScenarios([]ScenarioConfig{{
+		Name:  "rush",
+		At:    "+1m",
+		Spike: &SpikeConfig{Multiplier: 4, Duration: "30s"},
+	}}, minimalTopo())
+	require.NoError(t, err)
+	require.Len(t, scenarios, 1)
+	assert.Equal(t, time.Minute, scenarios[0].Start)
+	assert.Equal(t, time.Minute+30*time.Second, scenarios[0].End, "the spike's duration sets the window")
+	assert.InDelta(t, 4.0, scenarios[0].TrafficMultiplier, 1e-9)
+	assert.Nil(t, scenarios[0].Traffic)
+}
+
+func TestResolveTrafficMultiplier(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 1.0, ResolveTrafficMultiplier(nil), 1e-9)
+	assert.InDelta(t, 1.0, ResolveTrafficMultiplier([]Scenario{{Name: "latency"}}), 1e-9)
+	assert.InDelta(t, 6.0, ResolveTrafficMultiplier([]Scenario{
+		{Name: "a", TrafficMultiplier: 2},
+		{Name: "latency"},
+		{Name: "b", TrafficMultiplier: 3},
+	}), 1e-9, "overlapping spikes compound")
+}
+
+func TestResolveLabels(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, ResolveLabels(nil))
+	assert.Nil(t, ResolveLabels([]Scenario{{Name: "latency"}}))
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("incident.id", "INC-2"),
+		attribute.String("incident.severity", "sev1"),
+		attribute.String("team", "payments"),
+	}, ResolveLabels([]Scenario{
+		{Name: "low", Labels: map[string]string{"incident.id": "INC-1", "team": "payments"}},
+		{Name: "high", Labels: map[string]string{"incident.id": "INC-2", "incident.severity": "sev1"}},
+	}), "the later, higher-priority scenario wins per key")
+}
+
 func callTopoForTests() *Topology {
 	svcA := &Service{Name: "a", Operations: make(map[string]*Operation)}
 	svcB := &Service{Name: "b", Operations: make(map[string]*Operation)}
@@ -959,3 +1161,65 @@ func TestResolveOverridesMergesLogs(t *testing.T) {
 		"original scenario should not be mutated")
 	assert.False(t, scenarios[0].Overrides["svc"].DisableLogs)
 }
+
+func TestScenarioWarnings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("conflicting overlap names both scenarios", func(t *testing.T) {
+		t.Parallel()
+		warnings := ScenarioWarnings([]Scenario{
+			{Name: "slow db", Start: 0, End: 2 * time.Minute, Priority: 1, Overrides: map[string]Override{
+				"db.query": {Duration: Distribution{Mean: 500 * time.Millisecond}, HasErrorRate: true, ErrorRate: 0.1},
+			}},
+			{Name: "db outage", Start: time.Minute, End: 3 * time.Minute, Priority: 2, Overrides: map[string]Override{
+				"db.query": {HasErrorRate: true, ErrorRate: 1},
+			}},
+		})
+		require.Len(t, warnings, 1)
+		assert.Equal(t, `scenarios "slow db" and "db outage" overlap from +1m0s to +2m0s and set different error_rate for db.query; "db outage" wins by priority`, warnings[0])
+	})
+
+	t.Run("equal priority", func(t *testing.T) {
+		t.Parallel()
+		warnings := ScenarioWarnings([]Scenario{
+			{Name: "a", Start: 0, End: time.Minute, Overrides: map[string]Override{
+				"db.query": {Duration: Distribution{Mean: time.Second}, ErrorRateMultiply: 2},
+			}},
+			{Name: "b", Start: 0, End: time.Minute, Overrides: map[string]Override{
+				"db.query": {Duration: Distribution{Mean: 2 * time.Second}, ErrorRateMultiply: 3},
+			}},
+		})
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0], "set different duration and error_rate_multiply for db.query")
+		assert.Contains(t, warnings[0], `"b" wins by being defined later`)
+	})
+
+	t.Run("overlap without conflict", func(t *testing.T) {
+		t.Parallel()
+		assert.Empty(t, ScenarioWarnings([]Scenario{
+			{Name: "a", Start: 0, End: time.Minute, Overrides: map[string]Override{
+				"db.query": {Duration: Distribution{Mean: time.Second}},
+				"api.get":  {HasErrorRate: true, ErrorRate: 0.5},
+			}},
+			{Name: "b", Start: 0, End: time.Minute, Overrides: map[string]Override{
+				"db.query": {HasErrorRate: true, ErrorRate: 0.5},
+				"api.get":  {HasErrorRate: true, ErrorRate: 0.5},
+			}},
+		}))
+	})
+
+	t.Run("gap", func(t *testing.T) {
+		t.Parallel()
+		warnings := ScenarioWarnings([]Scenario{
+			{Name: "late", Start: 5 * time.Minute, End: 6 * time.Minute},
+			{Name: "early", Start: time.Minute, End: 2 * time.Minute},
+			{Name: "touching", Start: 6 * time.Minute, End: 7 * time.Minute},
+		})
+		assert.Equal(t, []string{"no scenario is active from +2m0s to +5m0s"}, warnings)
+	})
+
+	t.Run("no scenarios", func(t *testing.T) {
+		t.Parallel()
+		assert.Empty(t, ScenarioWarnings(nil))
+	})
+}
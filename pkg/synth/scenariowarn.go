@@ -0,0 +1,120 @@
+// Scenario timeline warnings: overlapping windows whose overrides conflict, and gaps between windows
+// Walks the timeline at each scenario boundary with ActiveScenarios, so overlaps are judged as the engine sees them
+package synth
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ScenarioWarnings reports two kinds of scenario timeline that are valid
+// but often unintended: overlapping scenarios that override the same field
+// of the same operation with different values, so that priority decides the
+// outcome, and gaps between the first scenario's start and the last one's
+// end during which no scenario is active. Warnings are in timeline order.
+func ScenarioWarnings(scenarios []Scenario) []string {
+	if len(scenarios) == 0 {
+		return nil
+	}
+
+	// The active set only changes at a scenario boundary, so sampling each
+	// boundary covers the whole timeline.
+	var boundaries []time.Duration
+	for _, sc := range scenarios {
+		boundaries = append(boundaries, sc.Start, sc.End)
+	}
+	slices.Sort(boundaries)
+	boundaries = slices.Compact(boundaries)
+
+	var warnings []string
+	seen := make(map[string]bool)
+	for i, at := range boundaries[:len(boundaries)-1] {
+		active := ActiveScenarios(scenarios, at)
+		if len(active) == 0 {
+			warnings = append(warnings, fmt.Sprintf("no scenario is active from %s to %s",
+				formatOffset(at), formatOffset(boundaries[i+1])))
+			continue
+		}
+		// active is sorted ascending by priority, so the later of each
+		// pair wins.
+		for j, lower := range active {
+			for _, winner := range active[j+1:] {
+				for _, ref := range slices.Sorted(maps.Keys(winner.Overrides)) {
+					ov, ok := lower.Overrides[ref]
+					if !ok {
+						continue
+					}
+					fields := conflictingOverrideFields(ov, winner.Overrides[ref])
+					if len(fields) == 0 {
+						continue
+					}
+					key := fmt.Sprintf("%s\x00%d\x00%s\x00%d\x00%s", lower.Name, lower.Start, winner.Name, winner.Start, ref)
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+					warnings = append(warnings, fmt.Sprintf(
+						"scenarios %q and %q overlap from %s to %s and set different %s for %s; %q wins by %s",
+						lower.Name, winner.Name,
+						formatOffset(max(lower.Start, winner.Start)), formatOffset(min(lower.End, winner.End)),
+						joinFields(fields), ref, winner.Name, winReason(lower, winner)))
+				}
+			}
+		}
+	}
+	return warnings
+}
+
+// conflictingOverrideFields returns the override fields that a and b both
+// set to different values, so that only one of them can take effect.
+func conflictingOverrideFields(a, b Override) []string {
+	var fields []string
+	if a.Duration.Mean > 0 && b.Duration.Mean > 0 && a.Duration != b.Duration {
+		fields = append(fields, "duration")
+	}
+	if a.HasErrorRate && b.HasErrorRate && a.ErrorRate != b.ErrorRate {
+		fields = append(fields, "error_rate")
+	}
+	if a.ErrorRateMultiply > 0 && b.ErrorRateMultiply > 0 && a.ErrorRateMultiply != b.ErrorRateMultiply {
+		fields = append(fields, "error_rate_multiply")
+	}
+	for _, attr := range a.Attributes {
+		if b.Attributes.Get(attr.Key) != nil {
+			fields = append(fields, "attributes")
+			break
+		}
+	}
+	for name := range a.Metrics {
+		if _, ok := b.Metrics[name]; ok {
+			fields = append(fields, "metrics")
+			break
+		}
+	}
+	return fields
+}
+
+// joinFields lists override field names for a warning: "duration",
+// "duration and error_rate", or "duration, error_rate and metrics".
+func joinFields(fields []string) string {
+	if len(fields) == 1 {
+		return fields[0]
+	}
+	last := len(fields) - 1
+	return strings.Join(fields[:last], ", ") + " and " + fields[last]
+}
+
+// winReason explains why winner's overrides take effect over lower's.
+func winReason(lower, winner Scenario) string {
+	if winner.Priority > lower.Priority {
+		return "priority"
+	}
+	return "being defined later"
+}
+
+// formatOffset writes a scenario offset the way at: is written, e.g. +1m30s.
+func formatOffset(d time.Duration) string {
+	return "+" + d.String()
+}
@@ -0,0 +1,93 @@
+// Service selection: restricting a topology to a subset of its services
+// Prunes unselected services and the calls and links into them, then recomputes roots
+package synth
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// SelectServices restricts topo, in place, to the named services. Calls and
+// links into other services are dropped, including calls that scenarios add,
+// and roots are recomputed so that entry points of the selected subgraph
+// start traces. It returns a warning for each dropped call and an error if a
+// name is not a service in topo.
+func SelectServices(topo *Topology, scenarios []Scenario, names []string) ([]string, error) {
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := topo.Services[name]; !ok {
+			return nil, fmt.Errorf("unknown service %q (available: %s)", name, strings.Join(slices.Sorted(maps.Keys(topo.Services)), ", "))
+		}
+		keep[name] = true
+	}
+
+	var warnings []string
+	kept := func(op *Operation, calls []Call, scope string) []Call {
+		out := calls[:0:0]
+		for _, call := range calls {
+			if keep[call.Operation.Service.Name] {
+				out = append(out, call)
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("%s%s: call to %s skipped; service %q is not selected",
+				scope, op.Ref, call.Operation.Ref, call.Operation.Service.Name))
+		}
+		return out
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(topo.Services)) {
+		if !keep[name] {
+			delete(topo.Services, name)
+			continue
+		}
+		svc := topo.Services[name]
+		for _, opName := range slices.Sorted(maps.Keys(svc.Operations)) {
+			op := svc.Operations[opName]
+			op.Calls = kept(op, op.Calls, "")
+			op.OneOf = kept(op, op.OneOf, "")
+			op.Links = slices.DeleteFunc(op.Links, func(l Link) bool {
+				return !keep[l.Operation.Service.Name]
+			})
+		}
+	}
+
+	for i := range scenarios {
+		for _, ref := range slices.Sorted(maps.Keys(scenarios[i].Overrides)) {
+			ov := scenarios[i].Overrides[ref]
+			if len(ov.AddCalls) == 0 {
+				continue
+			}
+			_, op, err := resolveRef(topo, ref)
+			if err != nil {
+				// The operation belongs to an unselected service.
+				continue
+			}
+			ov.AddCalls = kept(op, ov.AddCalls, fmt.Sprintf("scenario %q: ", scenarios[i].Name))
+			scenarios[i].Overrides[ref] = ov
+		}
+	}
+
+	topo.Roots = findRoots(topo)
+	return warnings, nil
+}
+
+// SelectRoot restricts topo, in place, to starting every trace at the
+// operation ref, which must be one of its roots.
+func SelectRoot(topo *Topology, ref string) error {
+	_, op, err := resolveRef(topo, ref)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(topo.Roots, op) {
+		roots := make([]string, len(topo.Roots))
+		for i, root := range topo.Roots {
+			roots[i] = root.Ref
+		}
+		slices.Sort(roots)
+		return fmt.Errorf("%q is not a root operation (roots: %s)", ref, strings.Join(roots, ", "))
+	}
+	topo.Roots = []*Operation{op}
+	return nil
+}
@@ -0,0 +1,139 @@
+package synth
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const selectTestConfig = `
+version: 1
+services:
+  gateway:
+    operations:
+      request:
+        duration: 10ms
+        calls:
+          - backend.query
+  backend:
+    operations:
+      query:
+        duration: 5ms
+        calls:
+          - db.select
+        links:
+          - queue.enqueue
+  db:
+    operations:
+      select:
+        duration: 1ms
+  queue:
+    operations:
+      enqueue:
+        duration: 1ms
+traffic:
+  rate: 10/s
+scenarios:
+  - name: slow db
+    at: "+1m"
+    duration: "1m"
+    override:
+      backend.query:
+        add_calls:
+          - target: queue.enqueue
+`
+
+func buildSelectTest(t *testing.T) (*Topology, []Scenario) {
+	t.Helper()
+	cfg, err := ParseConfig([]byte(selectTestConfig))
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(cfg))
+	topo, err := BuildTopology(cfg)
+	require.NoError(t, err)
+	scenarios, err := BuildScenarios(cfg.Scenarios, topo)
+	require.NoError(t, err)
+	return topo, scenarios
+}
+
+func TestSelectServices(t *testing.T) {
+	t.Parallel()
+
+	t.Run("downstream service becomes root", func(t *testing.T) {
+		t.Parallel()
+		topo, scenarios := buildSelectTest(t)
+
+		warnings, err := SelectServices(topo, scenarios, []string{"backend", "db"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"backend", "db"}, slices.Sorted(maps.Keys(topo.Services)))
+		require.Len(t, topo.Roots, 1)
+		assert.Equal(t, "backend.query", topo.Roots[0].Ref)
+
+		query := topo.Services["backend"].Operations["query"]
+		require.Len(t, query.Calls, 1)
+		assert.Equal(t, "db.select", query.Calls[0].Operation.Ref)
+		assert.Empty(t, query.Links)
+
+		assert.Empty(t, scenarios[0].Overrides["backend.query"].AddCalls)
+		assert.Equal(t, []string{
+			`scenario "slow db": backend.query: call to queue.enqueue skipped; service "queue" is not selected`,
+		}, warnings)
+	})
+
+	t.Run("dropped calls are reported", func(t *testing.T) {
+		t.Parallel()
+		topo, scenarios := buildSelectTest(t)
+
+		warnings, err := SelectServices(topo, scenarios, []string{"gateway"})
+		require.NoError(t, err)
+
+		assert.Empty(t, topo.Services["gateway"].Operations["request"].Calls)
+		assert.Equal(t, []string{
+			`gateway.request: call to backend.query skipped; service "backend" is not selected`,
+		}, warnings)
+	})
+
+	t.Run("unknown service", func(t *testing.T) {
+		t.Parallel()
+		topo, scenarios := buildSelectTest(t)
+
+		_, err := SelectServices(topo, scenarios, []string{"payments"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown service "payments" (available: backend, db, gateway, queue)`)
+	})
+}
+
+func TestSelectRoot(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keeps only the named root", func(t *testing.T) {
+		t.Parallel()
+		topo, _ := buildSelectTest(t)
+		require.Len(t, topo.Roots, 2)
+
+		require.NoError(t, SelectRoot(topo, "queue.enqueue"))
+		require.Len(t, topo.Roots, 1)
+		assert.Equal(t, "queue.enqueue", topo.Roots[0].Ref)
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		t.Parallel()
+		topo, _ := buildSelectTest(t)
+
+		err := SelectRoot(topo, "gateway.missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `operation "missing" not found in service "gateway"`)
+	})
+
+	t.Run("operation that is called is not a root", func(t *testing.T) {
+		t.Parallel()
+		topo, _ := buildSelectTest(t)
+
+		err := SelectRoot(topo, "backend.query")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `"backend.query" is not a root operation (roots: gateway.request, queue.enqueue)`)
+	})
+}
@@ -0,0 +1,85 @@
+// Error budget burn rate: a derived SLO gauge for services that declare an slo
+// Tracks errors over a sliding window of span time and reports them against the budget
+package synth
+
+import (
+	"sync"
+	"time"
+)
+
+// SLOBurnRateMetric is the gauge a MetricObserver reports for each service
+// with an SLO: the error rate over the SLO window divided by the error
+// budget, 1 - target. A burn rate of 1 spends the budget exactly over the
+// window; alerting rules typically page well above it.
+const SLOBurnRateMetric = "slo.error_budget.burn_rate"
+
+// defaultSLOWindow is the burn rate window when an slo sets none.
+const defaultSLOWindow = 5 * time.Minute
+
+// sloBuckets is how many slices the SLO window is divided into; the window
+// slides forward one slice at a time.
+const sloBuckets = 60
+
+// burnWindow counts a service's spans and errors over a sliding window that
+// ends at the latest span seen. Windowing by span end time rather than the
+// wall clock keeps the rate meaningful when traces are generated faster than
+// real time.
+type burnWindow struct {
+	mu      sync.Mutex
+	budget  float64
+	width   time.Duration
+	latest  int64
+	buckets [sloBuckets]burnBucket
+}
+
+// burnBucket holds the counts for one slice of the window.
+type burnBucket struct {
+	slot   int64
+	total  int64
+	errors int64
+}
+
+func newBurnWindow(slo *ResolvedSLO) *burnWindow {
+	return &burnWindow{
+		budget: 1 - slo.Target,
+		width:  max(slo.Window/sloBuckets, time.Nanosecond),
+	}
+}
+
+// record counts one span ending at end. Spans that end before the current
+// window are ignored.
+func (w *burnWindow) record(end time.Time, isError bool) {
+	slot := end.UnixNano() / int64(w.width)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if slot <= w.latest-sloBuckets {
+		return
+	}
+	w.latest = max(w.latest, slot)
+	b := &w.buckets[(slot%sloBuckets+sloBuckets)%sloBuckets]
+	if b.slot != slot {
+		*b = burnBucket{slot: slot}
+	}
+	b.total++
+	if isError {
+		b.errors++
+	}
+}
+
+// burnRate returns the error rate over the window as a multiple of the
+// error budget, or zero before any span is recorded.
+func (w *burnWindow) burnRate() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var total, errors int64
+	for _, b := range w.buckets {
+		if b.total > 0 && b.slot > w.latest-sloBuckets {
+			total += b.total
+			errors += b.errors
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(errors) / float64(total) / w.budget
+}
@@ -0,0 +1,62 @@
+// Per-operation slow spans: a latency threshold and what crossing it does
+// The span can be errored, tagged with synth.slow, or left with its status unset
+package synth
+
+import (
+	"cmp"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Slow status constants for an operation's slow_status field.
+const (
+	slowStatusUnset     = "unset"
+	slowStatusError     = "error"
+	slowStatusAttribute = "attribute"
+)
+
+// slowAttribute marks a span that exceeded its operation's slow_threshold
+// when the operation's slow_status is "attribute".
+var slowAttribute = attribute.Bool("synth.slow", true)
+
+// validateSlowStatus checks an operation's slow_threshold and slow_status.
+func validateSlowStatus(threshold, status string) error {
+	if threshold == "" {
+		if status != "" {
+			return fmt.Errorf("slow_status requires slow_threshold")
+		}
+		return nil
+	}
+	d, err := time.ParseDuration(threshold)
+	if err != nil {
+		return fmt.Errorf("invalid slow_threshold: %w", err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("slow_threshold must be positive, got %s", threshold)
+	}
+	switch status {
+	case "", slowStatusUnset, slowStatusError, slowStatusAttribute:
+		return nil
+	default:
+		return fmt.Errorf("slow_status must be %q, %q, or %q; got %q", slowStatusUnset, slowStatusError, slowStatusAttribute, status)
+	}
+}
+
+// EffectiveSlowStatus returns what a span of op that exceeds its
+// slow_threshold does: "error", "attribute", or "unset", the default.
+func (op *Operation) EffectiveSlowStatus() string {
+	return cmp.Or(op.SlowStatus, slowStatusUnset)
+}
+
+// isSlow reports whether a span of op lasting d exceeds its slow_threshold.
+func (op *Operation) isSlow(d time.Duration) bool {
+	return op.SlowThreshold > 0 && d > op.SlowThreshold
+}
+
+// slowErrorMessage is the status description of a span errored only for
+// being slow.
+func slowErrorMessage(op *Operation, d time.Duration) string {
+	return fmt.Sprintf("slow operation: %s exceeded slow_threshold %s", d, op.SlowThreshold)
+}
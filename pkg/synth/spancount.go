@@ -0,0 +1,57 @@
+// Span count observer: the reference implementation of a config-driven observer
+// Counts the spans a run emits and writes the total to a file when it ends
+package synth
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// SpanCountObserverType is the observers: type of the span count observer.
+// Its one option, path, names the file the count is written to.
+const SpanCountObserverType = "span_count"
+
+// SpanCountObserver counts observed spans. Close writes the count, followed
+// by a newline, to its file.
+type SpanCountObserver struct {
+	count atomic.Int64
+	file  *os.File
+}
+
+// NewSpanCountObserver creates path, truncating it if it exists, and returns
+// an observer that writes its span count there on Close.
+func NewSpanCountObserver(path string) (*SpanCountObserver, error) {
+	f, err := os.Create(path) //nolint:gosec // user-supplied output path is expected
+	if err != nil {
+		return nil, err
+	}
+	return &SpanCountObserver{file: f}, nil
+}
+
+func newSpanCountObserverFromOptions(options map[string]string, _ *Topology) (SpanObserver, error) {
+	path := options["path"]
+	if path == "" || len(options) != 1 {
+		return nil, fmt.Errorf("takes one option, path, e.g. options: {path: spans.txt}")
+	}
+	return NewSpanCountObserver(path)
+}
+
+// Observe counts one span.
+func (o *SpanCountObserver) Observe(SpanInfo) {
+	o.count.Add(1)
+}
+
+// Count returns the number of spans observed so far.
+func (o *SpanCountObserver) Count() int64 {
+	return o.count.Load()
+}
+
+// Close writes the span count to the observer's file and closes it.
+func (o *SpanCountObserver) Close() error {
+	if _, err := fmt.Fprintf(o.file, "%d\n", o.Count()); err != nil {
+		o.file.Close() //nolint:errcheck,gosec // reporting the write error instead
+		return err
+	}
+	return o.file.Close()
+}
@@ -1,5 +1,5 @@
 // Per-operation runtime state for cross-trace simulation effects
-// Tracks queue depth, circuit breaker status, and backpressure for each operation
+// Tracks queue depth, circuit breaker status, rate limits, and backpressure for each operation
 package synth
 
 import (
@@ -11,6 +11,10 @@ import (
 const (
 	ReasonQueueFull   = "queue_full"
 	ReasonCircuitOpen = "circuit_open"
+	ReasonRateLimited = "rate_limited"
+
+	// QueueModelMM1 selects M/M/1 queue wait modelling for backpressure.
+	QueueModelMM1 = "mm1"
 
 	// Backpressure tuning constants.
 	backpressureAlpha         = 0.3
@@ -25,12 +29,12 @@ type CircuitState int
 const (
 	CircuitClosed   CircuitState = iota // Allows all requests through.
 	CircuitOpen                         // Rejects all requests.
-	CircuitHalfOpen                     // Allows a probe request to test recovery.
+	CircuitHalfOpen                     // Allows probe requests to test recovery.
 )
 
 // SimulationState tracks cross-trace state for operations during a run.
-// Only operations with queue_depth, backpressure, or circuit_breaker config
-// get an entry — unconfigured operations are unaffected.
+// Only operations with concurrency, queue_depth, backpressure,
+// circuit_breaker, rate_limit, or cold_start config get an entry — unconfigured operations are unaffected.
 //
 // State persists for the entire simulation, including across scenario boundaries.
 // After a scenario ends, effects like open circuit breakers and backpressure
@@ -43,15 +47,27 @@ type SimulationState struct {
 
 // OperationState holds runtime state for a single operation across traces.
 // Not safe for concurrent use. The engine calls all methods from a single goroutine.
+//
+// The engine generates each trace in one pass, so a request has been fully
+// simulated before the next one arrives. Requests in flight are therefore
+// counted on the simulated clock: Exit records each request's span, and
+// Admit counts those that overlap the new request's start.
 type OperationState struct {
+	// ActiveRequests is the number of requests in flight at the latest
+	// admission, queued ones included.
 	ActiveRequests int
+	Concurrency    int
 	MaxQueueDepth  int
+	// inFlight holds the spans of requests that may still be running when
+	// a later request arrives.
+	inFlight []flight
 
 	BackpressureThreshold time.Duration
 	DurationMultiplier    float64
 	ErrorRateAdd          float64
 	RecentLatency         time.Duration
 	BackpressureActive    bool
+	QueueModel            string
 
 	FailureWindow    []failureRecord
 	Circuit          CircuitState
@@ -59,33 +75,68 @@ type OperationState struct {
 	Cooldown         time.Duration
 	FailureThreshold int
 	WindowDuration   time.Duration
+	// HalfOpenMaxProbes caps probes in flight while half-open (0 = no cap);
+	// HalfOpenProbes counts those in flight at the latest admission.
+	HalfOpenMaxProbes int
+	HalfOpenProbes    int
+
+	MaxConcurrency int
+	MaxRateCount   int
+	MaxRatePeriod  time.Duration
+	RecentAdmits   []time.Duration
+
+	// ColdStartLatency is added to each of the next ColdStartRemaining
+	// requests the operation serves.
+	ColdStartLatency   time.Duration
+	ColdStartRemaining int
 }
 
 type failureRecord struct {
 	At time.Duration
 }
 
+// flight is the simulated span of an admitted request.
+type flight struct {
+	start, end time.Time
+	// probe marks a request admitted while the circuit was half-open, and
+	// failed records whether it failed.
+	probe, failed bool
+}
+
 // NewSimulationState builds state from topology operations that have
-// queue depth, backpressure, or circuit breaker configuration.
+// concurrency, queue depth, backpressure, circuit breaker, rate limit, or
+// cold start configuration.
 func NewSimulationState(topo *Topology) *SimulationState {
 	s := &SimulationState{
 		operations: make(map[string]*OperationState),
 	}
 	for _, svc := range topo.Services {
 		for _, op := range svc.Operations {
-			if op.QueueDepth == 0 && op.Backpressure == nil && op.CircuitBreaker == nil {
+			if op.Concurrency == 0 && op.QueueDepth == 0 && op.Backpressure == nil && op.CircuitBreaker == nil && op.RateLimit == nil && op.ColdStart == nil {
 				continue
 			}
 			ref := svc.Name + "." + op.Name
 			os := &OperationState{
+				Concurrency:   op.Concurrency,
 				MaxQueueDepth: op.QueueDepth,
 			}
 			if op.CircuitBreaker != nil {
 				os.FailureThreshold = op.CircuitBreaker.FailureThreshold
 				os.WindowDuration = op.CircuitBreaker.Window
 				os.Cooldown = op.CircuitBreaker.Cooldown
+				os.HalfOpenMaxProbes = op.CircuitBreaker.HalfOpenMaxProbes
+			}
+			if op.RateLimit != nil {
+				os.MaxConcurrency = op.RateLimit.MaxConcurrency
+				os.MaxRateCount = op.RateLimit.MaxRate.count
+				os.MaxRatePeriod = op.RateLimit.MaxRate.period
+			}
+			if op.ColdStart != nil {
+				os.ColdStartLatency = op.ColdStart.Latency
+				os.ColdStartRemaining = op.ColdStart.Invocations
 			}
 			if op.Backpressure != nil {
+				os.QueueModel = op.Backpressure.QueueModel
 				os.BackpressureThreshold = op.Backpressure.LatencyThreshold
 				os.DurationMultiplier = op.Backpressure.DurationMultiplier
 				os.ErrorRateAdd = op.Backpressure.ErrorRateAdd
@@ -104,13 +155,16 @@ func (s *SimulationState) Get(ref string) *OperationState {
 	return s.operations[ref]
 }
 
-// Admit checks operation state and returns adjustments for the current request.
+// Admit checks operation state and returns adjustments for a request whose
+// span starts at start, elapsed into the run.
 // Mutates circuit breaker state (e.g. Open→HalfOpen transition on cooldown expiry).
 // Returns the adjusted duration multiplier, additional error rate, and whether
 // the request should be rejected outright.
-func (os *OperationState) Admit(elapsed time.Duration, rng *rand.Rand) (durationMult float64, errorRateAdd float64, rejected bool, reason string) {
+func (os *OperationState) Admit(start time.Time, elapsed time.Duration, rng *rand.Rand) (durationMult float64, errorRateAdd float64, rejected bool, reason string) {
 	durationMult = 1.0
 
+	os.settle(start, elapsed)
+
 	if os.Circuit == CircuitOpen {
 		if elapsed-os.OpenedAt >= os.Cooldown {
 			os.Circuit = CircuitHalfOpen
@@ -119,10 +173,51 @@ func (os *OperationState) Admit(elapsed time.Duration, rng *rand.Rand) (duration
 		}
 	}
 
-	if os.MaxQueueDepth > 0 && os.ActiveRequests >= os.MaxQueueDepth {
+	os.ActiveRequests, os.HalfOpenProbes = 0, 0
+	for _, f := range os.inFlight {
+		if f.start.After(start) {
+			continue
+		}
+		os.ActiveRequests++
+		if f.probe {
+			os.HalfOpenProbes++
+		}
+	}
+
+	// While half-open, probes beyond the cap are rejected as if the circuit
+	// were still open, until an in-flight probe resolves it.
+	if os.Circuit == CircuitHalfOpen && os.HalfOpenMaxProbes > 0 && os.HalfOpenProbes >= os.HalfOpenMaxProbes {
+		return 0, 0, true, ReasonCircuitOpen
+	}
+
+	// With a concurrency, requests beyond it queue for a free slot and only
+	// a full queue rejects; without one, the queue depth caps requests in
+	// flight.
+	if capacity := os.Concurrency + os.MaxQueueDepth; capacity > 0 && os.ActiveRequests >= capacity {
 		return 0, 0, true, ReasonQueueFull
 	}
 
+	if os.MaxConcurrency > 0 && os.ActiveRequests >= os.MaxConcurrency {
+		return 0, 0, true, ReasonRateLimited
+	}
+
+	if os.MaxRateCount > 0 {
+		// Sliding window of admission times: only admitted requests count
+		// towards the limit, so a rejected burst does not extend the outage.
+		cutoff := elapsed - os.MaxRatePeriod
+		pruned := os.RecentAdmits[:0]
+		for _, at := range os.RecentAdmits {
+			if at > cutoff {
+				pruned = append(pruned, at)
+			}
+		}
+		os.RecentAdmits = pruned
+		if len(os.RecentAdmits) >= os.MaxRateCount {
+			return 0, 0, true, ReasonRateLimited
+		}
+		os.RecentAdmits = append(os.RecentAdmits, elapsed)
+	}
+
 	if os.BackpressureActive {
 		durationMult = os.DurationMultiplier
 		if durationMult <= 0 {
@@ -137,17 +232,83 @@ func (os *OperationState) Admit(elapsed time.Duration, rng *rand.Rand) (duration
 	return durationMult, errorRateAdd, false, ""
 }
 
-// Enter increments the active request count.
-func (os *OperationState) Enter() {
-	os.ActiveRequests++
+// settle forgets requests that ended by start. While the circuit is
+// half-open, the earliest of those probes to end decides it, which also
+// releases every outstanding probe slot.
+func (os *OperationState) settle(start time.Time, elapsed time.Duration) {
+	var decider flight
+	pending := os.inFlight[:0]
+	for _, f := range os.inFlight {
+		if f.end.After(start) {
+			pending = append(pending, f)
+			continue
+		}
+		if f.probe && (!decider.probe || f.end.Before(decider.end)) {
+			decider = f
+		}
+	}
+	if decider.probe {
+		for i := range pending {
+			pending[i].probe = false
+		}
+		if decider.failed {
+			os.Circuit = CircuitOpen
+			os.OpenedAt = elapsed
+		} else {
+			os.Circuit = CircuitClosed
+			os.FailureWindow = os.FailureWindow[:0]
+		}
+	}
+	os.inFlight = pending
+}
+
+// QueueWait returns how long a new request waits before service starts.
+//
+// When every concurrency slot is busy, the request takes a queue slot and
+// waits for the requests ahead of it: one slot frees every serviceTime /
+// Concurrency on average, so the nth request in the queue waits n such
+// intervals.
+//
+// Under the M/M/1 queue model, each request already in flight holds the
+// server for an exponentially distributed time with mean serviceTime, so the
+// wait is the sum of one such draw per active request and grows with load.
+// Randomness is consumed only for the M/M/1 model.
+func (os *OperationState) QueueWait(serviceTime time.Duration, rng *rand.Rand) time.Duration {
+	if serviceTime <= 0 {
+		return 0
+	}
+	if os.Concurrency > 0 && os.ActiveRequests >= os.Concurrency {
+		position := os.ActiveRequests - os.Concurrency + 1
+		return time.Duration(position) * serviceTime / time.Duration(os.Concurrency)
+	}
+	if os.QueueModel != QueueModelMM1 {
+		return 0
+	}
+	var wait float64
+	for range os.ActiveRequests {
+		wait += rng.ExpFloat64()
+	}
+	return time.Duration(wait * float64(serviceTime))
 }
 
-// Exit decrements the active request count and records the outcome.
-func (os *OperationState) Exit(elapsed time.Duration, latency time.Duration, failed bool) {
-	os.ActiveRequests--
-	if os.ActiveRequests < 0 {
-		os.ActiveRequests = 0
+// ColdStart returns the warmup latency added to an admitted request, using
+// up one of the operation's cold invocations, or zero once they are spent.
+func (os *OperationState) ColdStart() time.Duration {
+	if os.ColdStartRemaining <= 0 {
+		return 0
 	}
+	os.ColdStartRemaining--
+	return os.ColdStartLatency
+}
+
+// Exit records the outcome of an admitted request whose span ended at end
+// after latency, elapsed into the run. The request counts as in flight for
+// later requests that start before end.
+func (os *OperationState) Exit(elapsed time.Duration, end time.Time, latency time.Duration, failed bool) {
+	// A request admitted while half-open is a probe: the earliest probe to
+	// end decides the circuit, which Admit settles once that end has passed.
+	probe := os.Circuit == CircuitHalfOpen
+	os.inFlight = append(os.inFlight, flight{start: end.Add(-latency), end: end, probe: probe, failed: failed})
 
 	if os.BackpressureThreshold > 0 {
 		if os.RecentLatency == 0 {
@@ -161,6 +322,10 @@ func (os *OperationState) Exit(elapsed time.Duration, latency time.Duration, fai
 		os.BackpressureActive = os.RecentLatency > os.BackpressureThreshold
 	}
 
+	if probe {
+		return
+	}
+
 	if os.WindowDuration > 0 {
 		cutoff := elapsed - os.WindowDuration
 		pruned := os.FailureWindow[:0]
@@ -181,14 +346,4 @@ func (os *OperationState) Exit(elapsed time.Duration, latency time.Duration, fai
 		os.Circuit = CircuitOpen
 		os.OpenedAt = elapsed
 	}
-
-	if os.Circuit == CircuitHalfOpen {
-		if failed {
-			os.Circuit = CircuitOpen
-			os.OpenedAt = elapsed
-		} else {
-			os.Circuit = CircuitClosed
-			os.FailureWindow = os.FailureWindow[:0]
-		}
-	}
 }
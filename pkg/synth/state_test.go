@@ -5,24 +5,34 @@ package synth
 import (
 	"context"
 	"math/rand/v2"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
+// simEpoch is the simulated start of the runs in these tests.
+var simEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// simAt returns the simulated time elapsed into a run.
+func simAt(elapsed time.Duration) time.Time {
+	return simEpoch.Add(elapsed)
+}
+
 func TestQueueDepthRejectsAtCapacity(t *testing.T) {
 	t.Parallel()
 
 	os := &OperationState{MaxQueueDepth: 2}
 	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
 
-	os.Enter()
-	os.Enter()
+	os.Exit(0, simAt(10*time.Millisecond), 10*time.Millisecond, false)
+	os.Exit(0, simAt(10*time.Millisecond), 10*time.Millisecond, false)
 
-	_, _, rejected, reason := os.Admit(0, rng)
+	_, _, rejected, reason := os.Admit(simAt(5*time.Millisecond), 0, rng)
 	assert.True(t, rejected)
 	assert.Equal(t, ReasonQueueFull, reason)
 }
@@ -33,35 +43,80 @@ func TestQueueDepthAllowsBelowCapacity(t *testing.T) {
 	os := &OperationState{MaxQueueDepth: 2}
 	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
 
-	os.Enter()
+	os.Exit(0, simAt(10*time.Millisecond), 10*time.Millisecond, false)
 
-	_, _, rejected, _ := os.Admit(0, rng)
+	_, _, rejected, _ := os.Admit(simAt(5*time.Millisecond), 0, rng)
 	assert.False(t, rejected)
 }
 
-func TestQueueDepthExitDecrementsCount(t *testing.T) {
+func TestQueueDepthFreesSlotWhenRequestEnds(t *testing.T) {
 	t.Parallel()
 
 	os := &OperationState{MaxQueueDepth: 1}
 	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
 
-	os.Enter()
+	os.Exit(0, simAt(10*time.Millisecond), 10*time.Millisecond, false)
+
+	_, _, rejected, _ := os.Admit(simAt(9*time.Millisecond), 0, rng)
+	assert.True(t, rejected, "should reject while the request is in flight")
+
+	_, _, rejected, _ = os.Admit(simAt(10*time.Millisecond), 0, rng)
+	assert.False(t, rejected, "should allow once the request has ended")
+	assert.Zero(t, os.ActiveRequests)
+}
+
+func TestQueueDepthIgnoresLaterRequests(t *testing.T) {
+	t.Parallel()
 
-	_, _, rejected, _ := os.Admit(0, rng)
-	assert.True(t, rejected, "should reject when at capacity")
+	os := &OperationState{MaxQueueDepth: 1}
+	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
 
-	os.Exit(0, 10*time.Millisecond, false)
+	// A request deeper in an earlier trace starts after this one arrives.
+	os.Exit(0, simAt(30*time.Millisecond), 10*time.Millisecond, false)
 
-	_, _, rejected, _ = os.Admit(0, rng)
-	assert.False(t, rejected, "should allow after exit frees a slot")
+	_, _, rejected, _ := os.Admit(simAt(5*time.Millisecond), 0, rng)
+	assert.False(t, rejected, "a request that has not started yet is not in flight")
 }
 
-func TestQueueDepthActiveRequestsFloor(t *testing.T) {
+func TestConcurrencyQueuesBeforeRejecting(t *testing.T) {
 	t.Parallel()
 
-	os := &OperationState{MaxQueueDepth: 5}
-	os.Exit(0, time.Millisecond, false)
-	assert.Equal(t, 0, os.ActiveRequests, "active requests should not go below zero")
+	os := &OperationState{Concurrency: 2, MaxQueueDepth: 2}
+	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
+
+	for i := range 4 {
+		_, _, rejected, _ := os.Admit(simAt(0), 0, rng)
+		require.False(t, rejected, "request %d should run or queue", i)
+		assert.Equal(t, i, os.ActiveRequests)
+		os.Exit(0, simAt(time.Duration(10+i)*time.Millisecond), time.Duration(10+i)*time.Millisecond, false)
+	}
+
+	_, _, rejected, reason := os.Admit(simAt(0), 0, rng)
+	assert.True(t, rejected, "should reject once every queue slot is taken")
+	assert.Equal(t, ReasonQueueFull, reason)
+
+	_, _, rejected, _ = os.Admit(simAt(10*time.Millisecond), 0, rng)
+	assert.False(t, rejected, "should queue again once the first request ends")
+}
+
+func TestQueueWaitConcurrencySlots(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
+	serviceTime := 10 * time.Millisecond
+	for _, tc := range []struct {
+		active int
+		want   time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 5 * time.Millisecond},
+		{3, 10 * time.Millisecond},
+		{5, 20 * time.Millisecond},
+	} {
+		os := &OperationState{Concurrency: 2, MaxQueueDepth: 4, ActiveRequests: tc.active}
+		assert.Equal(t, tc.want, os.QueueWait(serviceTime, rng), "active=%d", tc.active)
+	}
 }
 
 func TestCircuitBreakerOpensOnThreshold(t *testing.T) {
@@ -77,12 +132,12 @@ func TestCircuitBreakerOpensOnThreshold(t *testing.T) {
 	assert.Equal(t, CircuitClosed, os.Circuit)
 
 	for i := range os.FailureThreshold {
-		os.Exit(time.Duration(i)*100*time.Millisecond, 10*time.Millisecond, true)
+		os.Exit(time.Duration(i)*100*time.Millisecond, simAt(time.Duration(i)*100*time.Millisecond+10*time.Millisecond), 10*time.Millisecond, true)
 	}
 
 	assert.Equal(t, CircuitOpen, os.Circuit)
 
-	_, _, rejected, reason := os.Admit(200*time.Millisecond, rng)
+	_, _, rejected, reason := os.Admit(simAt(200*time.Millisecond), 200*time.Millisecond, rng)
 	assert.True(t, rejected)
 	assert.Equal(t, ReasonCircuitOpen, reason)
 }
@@ -97,14 +152,50 @@ func TestCircuitBreakerHalfOpenAfterCooldown(t *testing.T) {
 	}
 	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
 
-	os.Exit(0, time.Millisecond, true)
+	os.Exit(0, simAt(time.Millisecond), time.Millisecond, true)
 	assert.Equal(t, CircuitOpen, os.Circuit)
 
-	_, _, rejected, _ := os.Admit(200*time.Millisecond, rng)
+	_, _, rejected, _ := os.Admit(simAt(200*time.Millisecond), 200*time.Millisecond, rng)
 	assert.False(t, rejected, "should allow probe after cooldown")
 	assert.Equal(t, CircuitHalfOpen, os.Circuit)
 }
 
+func TestCircuitBreakerHalfOpenProbeLimit(t *testing.T) {
+	t.Parallel()
+
+	os := &OperationState{
+		FailureThreshold:  1,
+		WindowDuration:    time.Minute,
+		Cooldown:          100 * time.Millisecond,
+		HalfOpenMaxProbes: 2,
+	}
+	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
+
+	os.Exit(0, simAt(time.Millisecond), time.Millisecond, true)
+	require.Equal(t, CircuitOpen, os.Circuit)
+
+	// Probes take 50ms, so both are still in flight at 220ms.
+	for i := range 2 {
+		at := time.Duration(200+10*i) * time.Millisecond
+		_, _, rejected, _ := os.Admit(simAt(at), at, rng)
+		require.False(t, rejected, "probe %d should be admitted", i)
+		os.Exit(at, simAt(at+50*time.Millisecond), 50*time.Millisecond, false)
+	}
+	assert.Equal(t, CircuitHalfOpen, os.Circuit)
+
+	_, _, rejected, reason := os.Admit(simAt(220*time.Millisecond), 220*time.Millisecond, rng)
+	assert.True(t, rejected, "probes beyond the cap should be rejected")
+	assert.Equal(t, ReasonCircuitOpen, reason)
+	assert.Equal(t, 2, os.HalfOpenProbes)
+
+	// Once the first probe has succeeded, the circuit is closed and admits
+	// traffic again.
+	_, _, rejected, _ = os.Admit(simAt(250*time.Millisecond), 250*time.Millisecond, rng)
+	assert.False(t, rejected)
+	assert.Equal(t, CircuitClosed, os.Circuit)
+	assert.Zero(t, os.HalfOpenProbes)
+}
+
 func TestCircuitBreakerClosesOnHalfOpenSuccess(t *testing.T) {
 	t.Parallel()
 
@@ -113,9 +204,15 @@ func TestCircuitBreakerClosesOnHalfOpenSuccess(t *testing.T) {
 		WindowDuration:   time.Minute,
 		Cooldown:         100 * time.Millisecond,
 		Circuit:          CircuitHalfOpen,
+		FailureWindow:    []failureRecord{{At: 0}},
 	}
+	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
+
+	os.Exit(time.Second, simAt(time.Second+10*time.Millisecond), 10*time.Millisecond, false)
+	assert.Equal(t, CircuitHalfOpen, os.Circuit, "the probe decides once it has ended")
 
-	os.Exit(time.Second, 10*time.Millisecond, false)
+	_, _, rejected, _ := os.Admit(simAt(time.Second+10*time.Millisecond), time.Second+10*time.Millisecond, rng)
+	assert.False(t, rejected)
 	assert.Equal(t, CircuitClosed, os.Circuit)
 	assert.Empty(t, os.FailureWindow, "failure window should be cleared on close")
 }
@@ -129,11 +226,37 @@ func TestCircuitBreakerReopensOnHalfOpenFailure(t *testing.T) {
 		Cooldown:         100 * time.Millisecond,
 		Circuit:          CircuitHalfOpen,
 	}
+	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
 
-	os.Exit(time.Second, 10*time.Millisecond, true)
+	os.Exit(time.Second, simAt(time.Second+10*time.Millisecond), 10*time.Millisecond, true)
+	_, _, rejected, reason := os.Admit(simAt(time.Second+20*time.Millisecond), time.Second+20*time.Millisecond, rng)
+	assert.True(t, rejected)
+	assert.Equal(t, ReasonCircuitOpen, reason)
 	assert.Equal(t, CircuitOpen, os.Circuit)
 }
 
+func TestCircuitBreakerEarliestProbeDecides(t *testing.T) {
+	t.Parallel()
+
+	os := &OperationState{
+		FailureThreshold: 1,
+		WindowDuration:   time.Minute,
+		Cooldown:         100 * time.Millisecond,
+		Circuit:          CircuitHalfOpen,
+	}
+	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
+
+	// The slow probe is generated first but the fast one ends first.
+	os.Exit(0, simAt(100*time.Millisecond), 100*time.Millisecond, false)
+	_, _, rejected, _ := os.Admit(simAt(10*time.Millisecond), 10*time.Millisecond, rng)
+	require.False(t, rejected)
+	os.Exit(10*time.Millisecond, simAt(20*time.Millisecond), 10*time.Millisecond, true)
+
+	_, _, rejected, _ = os.Admit(simAt(30*time.Millisecond), 30*time.Millisecond, rng)
+	assert.True(t, rejected)
+	assert.Equal(t, CircuitOpen, os.Circuit, "the failed probe ended first")
+}
+
 func TestCircuitBreakerWindowPruning(t *testing.T) {
 	t.Parallel()
 
@@ -143,12 +266,12 @@ func TestCircuitBreakerWindowPruning(t *testing.T) {
 		Cooldown:         time.Second,
 	}
 
-	os.Exit(0, time.Millisecond, true)
-	os.Exit(10*time.Millisecond, time.Millisecond, true)
+	os.Exit(0, simAt(time.Millisecond), time.Millisecond, true)
+	os.Exit(10*time.Millisecond, simAt(10*time.Millisecond+time.Millisecond), time.Millisecond, true)
 	assert.Len(t, os.FailureWindow, 2)
 
 	// Failures at elapsed=200ms should prune old ones (window=100ms, cutoff=100ms)
-	os.Exit(200*time.Millisecond, time.Millisecond, true)
+	os.Exit(200*time.Millisecond, simAt(200*time.Millisecond+time.Millisecond), time.Millisecond, true)
 	assert.Len(t, os.FailureWindow, 1, "old failures outside window should be pruned")
 	assert.Equal(t, CircuitClosed, os.Circuit, "should not open: only 1 failure in window")
 }
@@ -163,10 +286,10 @@ func TestBackpressureActivatesOnHighLatency(t *testing.T) {
 	}
 	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
 
-	os.Exit(0, 100*time.Millisecond, false)
+	os.Exit(0, simAt(100*time.Millisecond), 100*time.Millisecond, false)
 	assert.True(t, os.BackpressureActive)
 
-	mult, errAdd, rejected, _ := os.Admit(0, rng)
+	mult, errAdd, rejected, _ := os.Admit(simAt(0), 0, rng)
 	assert.False(t, rejected)
 	assert.Equal(t, 3.0, mult)
 	assert.Equal(t, 0.1, errAdd)
@@ -182,10 +305,10 @@ func TestBackpressureInactiveOnLowLatency(t *testing.T) {
 	}
 	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
 
-	os.Exit(0, 10*time.Millisecond, false)
+	os.Exit(0, simAt(10*time.Millisecond), 10*time.Millisecond, false)
 	assert.False(t, os.BackpressureActive)
 
-	mult, errAdd, rejected, _ := os.Admit(0, rng)
+	mult, errAdd, rejected, _ := os.Admit(simAt(0), 0, rng)
 	assert.False(t, rejected)
 	assert.Equal(t, 1.0, mult)
 	assert.Equal(t, float64(0), errAdd)
@@ -200,12 +323,12 @@ func TestBackpressureEWMASmoothing(t *testing.T) {
 	}
 
 	// First sample: 100ms (above threshold)
-	os.Exit(0, 100*time.Millisecond, false)
+	os.Exit(0, simAt(100*time.Millisecond), 100*time.Millisecond, false)
 	assert.True(t, os.BackpressureActive)
 
 	// Multiple low-latency samples should bring EWMA below threshold
 	for range 20 {
-		os.Exit(0, 10*time.Millisecond, false)
+		os.Exit(0, simAt(10*time.Millisecond), 10*time.Millisecond, false)
 	}
 	assert.False(t, os.BackpressureActive, "EWMA should drop below threshold after many low-latency samples")
 }
@@ -220,7 +343,7 @@ func TestBackpressureMultiplierCapped(t *testing.T) {
 	}
 	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
 
-	mult, _, _, _ := os.Admit(0, rng)
+	mult, _, _, _ := os.Admit(simAt(0), 0, rng)
 	assert.Equal(t, maxBackpressureMultiplier, mult)
 }
 
@@ -234,7 +357,7 @@ func TestBackpressureZeroMultiplierDefaultsToOne(t *testing.T) {
 	}
 	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
 
-	mult, _, _, _ := os.Admit(0, rng)
+	mult, _, _, _ := os.Admit(simAt(0), 0, rng)
 	assert.Equal(t, 1.0, mult)
 }
 
@@ -272,9 +395,6 @@ func TestNewSimulationStateNilSafe(t *testing.T) {
 func TestEngineQueueDepthRejection(t *testing.T) {
 	t.Parallel()
 
-	// Queue depth rejection works at the OperationState level, not through
-	// the engine's sequential walk (which processes one span at a time).
-	// Test the rejection span path directly by pre-filling the queue.
 	cfg := &Config{
 		Services: []ServiceConfig{{
 			Name: "svc",
@@ -290,14 +410,16 @@ func TestEngineQueueDepthRejection(t *testing.T) {
 	engine, exporter, tp := newTestEngine(t, cfg)
 	engine.State = NewSimulationState(engine.Topology)
 
-	// Pre-fill the queue so the next request is rejected
-	opState := engine.State.Get("svc.op")
-	require.NotNil(t, opState)
-	opState.Enter()
-
+	// The first trace is still in flight when the second starts, so it fills
+	// the queue and the second is rejected.
 	rootOp := engine.Topology.Roots[0]
+	start := time.Now()
+	engine.walkTrace(context.Background(), rootOp, nil, start, 0, nil, nil, &Stats{}, new(int), DefaultMaxSpansPerTrace, false, false)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+	exporter.Reset()
+
 	var stats Stats
-	engine.walkTrace(context.Background(), rootOp, nil, time.Now(), 0, nil, nil, &stats, new(int), DefaultMaxSpansPerTrace, false, false)
+	engine.walkTrace(context.Background(), rootOp, nil, start.Add(time.Millisecond), time.Millisecond, nil, nil, &stats, new(int), DefaultMaxSpansPerTrace, false, false)
 	require.NoError(t, tp.ForceFlush(context.Background()))
 
 	spans := exporter.GetSpans()
@@ -375,6 +497,104 @@ func TestEngineCircuitBreakerIntegration(t *testing.T) {
 	assert.Equal(t, "circuit_open", attrMap["synth.rejection_reason"])
 }
 
+func TestRateLimitMaxConcurrencyRejects(t *testing.T) {
+	t.Parallel()
+
+	os := &OperationState{MaxConcurrency: 1}
+	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
+
+	_, _, rejected, _ := os.Admit(simAt(0), 0, rng)
+	require.False(t, rejected)
+	os.Exit(0, simAt(10*time.Millisecond), 10*time.Millisecond, false)
+
+	_, _, rejected, reason := os.Admit(simAt(time.Millisecond), time.Millisecond, rng)
+	assert.True(t, rejected)
+	assert.Equal(t, ReasonRateLimited, reason)
+
+	_, _, rejected, _ = os.Admit(simAt(10*time.Millisecond), 10*time.Millisecond, rng)
+	assert.False(t, rejected, "slot should free up once the request ends")
+}
+
+func TestRateLimitMaxRateSlidingWindow(t *testing.T) {
+	t.Parallel()
+
+	os := &OperationState{MaxRateCount: 2, MaxRatePeriod: time.Second}
+	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
+
+	for _, at := range []time.Duration{0, 100 * time.Millisecond} {
+		_, _, rejected, _ := os.Admit(simAt(at), at, rng)
+		require.False(t, rejected, "request at %v should be admitted", at)
+	}
+
+	_, _, rejected, reason := os.Admit(simAt(500*time.Millisecond), 500*time.Millisecond, rng)
+	assert.True(t, rejected)
+	assert.Equal(t, ReasonRateLimited, reason)
+
+	// The first admission leaves the window; the rejected request did not
+	// consume a slot.
+	_, _, rejected, _ = os.Admit(simAt(time.Second), time.Second, rng)
+	assert.False(t, rejected)
+	_, _, rejected, _ = os.Admit(simAt(time.Second), time.Second, rng)
+	assert.True(t, rejected)
+}
+
+func TestEngineRateLimitRejection(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{
+				Name: "gateway",
+				Operations: []OperationConfig{{
+					Name:      "route",
+					Duration:  "1ms",
+					Calls:     []CallConfig{{Target: "backend.query"}},
+					RateLimit: &RateLimitConfig{MaxRate: "3/s"},
+				}},
+			},
+			{
+				Name:       "backend",
+				Operations: []OperationConfig{{Name: "query", Duration: "1ms"}},
+			},
+		},
+		Traffic: TrafficConfig{Rate: "100/s"},
+	}
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	engine.State = NewSimulationState(engine.Topology)
+
+	rootOp := engine.Topology.Roots[0]
+	var stats Stats
+	for i := range 10 {
+		engine.walkTrace(context.Background(), rootOp, nil, time.Now(), time.Duration(i)*10*time.Millisecond, nil, nil, &stats, new(int), DefaultMaxSpansPerTrace, false, false)
+	}
+	require.NoError(t, tp.ForceFlush(context.Background()))
+
+	assert.Equal(t, int64(7), stats.RateLimitRejections)
+
+	var gateway, backend, rejected int
+	for _, s := range exporter.GetSpans() {
+		switch s.Name {
+		case "route":
+			gateway++
+			attrs := make(map[string]string)
+			for _, attr := range s.Attributes {
+				attrs[string(attr.Key)] = attr.Value.Emit()
+			}
+			if attrs["synth.rejected"] == "true" {
+				rejected++
+				assert.Equal(t, ReasonRateLimited, attrs["synth.rejection_reason"])
+				assert.Equal(t, codes.Error, s.Status.Code)
+			}
+		case "query":
+			backend++
+		}
+	}
+	assert.Equal(t, 10, gateway)
+	assert.Equal(t, 7, rejected)
+	assert.Equal(t, 3, backend, "rejected requests should not call downstream")
+}
+
 func TestEngineBackpressureIntegration(t *testing.T) {
 	t.Parallel()
 
@@ -421,6 +641,293 @@ func TestEngineBackpressureIntegration(t *testing.T) {
 		"backpressure should amplify duration (first=%v, second=%v)", firstDuration, secondDuration)
 }
 
+func TestQueueWaitMM1GrowsWithConcurrency(t *testing.T) {
+	t.Parallel()
+
+	const samples = 2000
+	serviceTime := 10 * time.Millisecond
+	meanWait := func(active int) time.Duration {
+		os := &OperationState{QueueModel: QueueModelMM1, ActiveRequests: active}
+		rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
+		var total time.Duration
+		for range samples {
+			total += os.QueueWait(serviceTime, rng)
+		}
+		return total / samples
+	}
+
+	assert.Zero(t, meanWait(0), "an idle server has no queue wait")
+	prev := time.Duration(0)
+	for _, active := range []int{1, 4, 16} {
+		wait := meanWait(active)
+		assert.Greater(t, wait, prev, "wait should grow with concurrency")
+		assert.InDelta(t, float64(time.Duration(active)*serviceTime), float64(wait), 0.1*float64(time.Duration(active)*serviceTime),
+			"mean wait should be close to active requests x service time")
+		prev = wait
+	}
+}
+
+func TestQueueWaitMM1CountsOverlappingRequests(t *testing.T) {
+	t.Parallel()
+
+	os := &OperationState{QueueModel: QueueModelMM1}
+	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
+
+	// One request ended before the new one starts and one is still running.
+	os.Exit(0, simAt(10*time.Millisecond), 10*time.Millisecond, false)
+	os.Exit(5*time.Millisecond, simAt(25*time.Millisecond), 10*time.Millisecond, false)
+
+	_, _, rejected, _ := os.Admit(simAt(20*time.Millisecond), 20*time.Millisecond, rng)
+	require.False(t, rejected)
+	assert.Equal(t, 1, os.ActiveRequests)
+	assert.Positive(t, os.QueueWait(10*time.Millisecond, rng))
+
+	_, _, rejected, _ = os.Admit(simAt(30*time.Millisecond), 30*time.Millisecond, rng)
+	require.False(t, rejected)
+	assert.Zero(t, os.ActiveRequests)
+	assert.Zero(t, os.QueueWait(10*time.Millisecond, rng), "a request arriving at an idle server does not wait")
+}
+
+func TestQueueWaitDefaultModelIsZero(t *testing.T) {
+	t.Parallel()
+
+	os := &OperationState{ActiveRequests: 5}
+	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
+	assert.Zero(t, os.QueueWait(10*time.Millisecond, rng))
+}
+
+// backfillRun runs cfg on a simulated clock for d and returns the run's stats
+// and spans, which overlap in simulated time as they would in a live run.
+func backfillRun(t *testing.T, cfg *Config, d time.Duration) (*Stats, []tracetest.SpanStub) {
+	t.Helper()
+
+	engine, exporter, tp := newTestEngine(t, cfg)
+	engine.State = NewSimulationState(engine.Topology)
+	engine.Backfill = true
+	engine.Duration = d
+	stats, err := engine.Run(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, tp.ForceFlush(context.Background()))
+	return stats, exporter.GetSpans()
+}
+
+// isRejected reports whether s is a rejection span.
+func isRejected(s tracetest.SpanStub) bool {
+	for _, attr := range s.Attributes {
+		if attr.Key == "synth.rejected" {
+			return attr.Value.AsBool()
+		}
+	}
+	return false
+}
+
+// maxInFlight returns the most admitted spans in flight at the start of any
+// admitted span, that span included.
+func maxInFlight(spans []tracetest.SpanStub) int {
+	var admitted []tracetest.SpanStub
+	for _, s := range spans {
+		if !isRejected(s) {
+			admitted = append(admitted, s)
+		}
+	}
+	most := 0
+	for _, s := range admitted {
+		n := 0
+		for _, o := range admitted {
+			if !o.StartTime.After(s.StartTime) && o.EndTime.After(s.StartTime) {
+				n++
+			}
+		}
+		most = max(most, n)
+	}
+	return most
+}
+
+func meanDuration(spans []tracetest.SpanStub) time.Duration {
+	var total time.Duration
+	for _, s := range spans {
+		total += s.EndTime.Sub(s.StartTime)
+	}
+	return total / time.Duration(len(spans))
+}
+
+func TestEngineMM1LatencyRisesWithConcurrency(t *testing.T) {
+	t.Parallel()
+
+	// The service rate is 100/s, so only the heavier rate overlaps requests
+	// and queues them; the lighter rates leave the server idle between them.
+	run := func(rate string) time.Duration {
+		cfg := &Config{
+			Services: []ServiceConfig{{
+				Name: "svc",
+				Operations: []OperationConfig{{
+					Name:         "op",
+					Duration:     "10ms",
+					Backpressure: &BackpressureConfig{QueueModel: QueueModelMM1},
+				}},
+			}},
+			Traffic: TrafficConfig{Rate: rate},
+		}
+		_, spans := backfillRun(t, cfg, 10*time.Second)
+		require.NotEmpty(t, spans)
+		return meanDuration(spans)
+	}
+
+	idle := run("1/s")
+	spaced := run("50/s")
+	heavy := run("200/s")
+	assert.Equal(t, 10*time.Millisecond, idle)
+	assert.Equal(t, idle, spaced, "requests that do not overlap should not wait")
+	assert.Greater(t, heavy, 2*idle, "requests above the service rate should queue")
+}
+
+func TestEngineConcurrencyQueueing(t *testing.T) {
+	t.Parallel()
+
+	cfg := func(rate string) *Config {
+		return &Config{
+			Services: []ServiceConfig{{
+				Name: "svc",
+				Operations: []OperationConfig{{
+					Name:        "op",
+					Duration:    "100ms",
+					Concurrency: 1,
+					QueueDepth:  1,
+				}},
+			}},
+			Traffic: TrafficConfig{Rate: rate},
+		}
+	}
+
+	// Traces spaced wider than the service time never overlap.
+	stats, spans := backfillRun(t, cfg("5/s"), 10*time.Second)
+	require.NotEmpty(t, spans)
+	assert.Zero(t, stats.QueueRejections)
+	for _, s := range spans {
+		assert.Equal(t, 100*time.Millisecond, s.EndTime.Sub(s.StartTime), "a free concurrency slot should not wait")
+	}
+
+	stats, spans = backfillRun(t, cfg("50/s"), 10*time.Second)
+	assert.Positive(t, stats.QueueRejections, "requests beyond the slot and the queue should be rejected")
+	var queued int
+	for _, s := range spans {
+		if isRejected(s) {
+			attrs := make(map[string]string)
+			for _, attr := range s.Attributes {
+				attrs[string(attr.Key)] = attr.Value.Emit()
+			}
+			assert.Equal(t, ReasonQueueFull, attrs["synth.rejection_reason"])
+			continue
+		}
+		d := s.EndTime.Sub(s.StartTime)
+		assert.Contains(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, d)
+		if d == 200*time.Millisecond {
+			queued++
+		}
+	}
+	assert.Positive(t, queued, "a request arriving while the slot is busy should wait for it")
+	assert.Equal(t, 2, maxInFlight(spans), "one request runs and one waits")
+}
+
+func TestEngineRateLimitMaxConcurrency(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{{
+			Name: "svc",
+			Operations: []OperationConfig{{
+				Name:      "op",
+				Duration:  "100ms",
+				RateLimit: &RateLimitConfig{MaxConcurrency: 2},
+			}},
+		}},
+		Traffic: TrafficConfig{Rate: "50/s"},
+	}
+
+	stats, spans := backfillRun(t, cfg, 10*time.Second)
+	assert.Positive(t, stats.RateLimitRejections, "requests beyond the limit should be rejected")
+	assert.Equal(t, 2, maxInFlight(spans))
+}
+
+func TestEngineHalfOpenProbeLimit(t *testing.T) {
+	t.Parallel()
+
+	// Every request fails, so each probe reopens the circuit and the
+	// circuit never closes.
+	run := func(maxProbes *int) int {
+		cfg := &Config{
+			Services: []ServiceConfig{{
+				Name: "svc",
+				Operations: []OperationConfig{{
+					Name:      "op",
+					Duration:  "500ms",
+					ErrorRate: "100%",
+					CircuitBreaker: &CircuitBreakerConfig{
+						FailureThreshold:  1,
+						Window:            "1m",
+						Cooldown:          "1s",
+						HalfOpenMaxProbes: maxProbes,
+					},
+				}},
+			}},
+			Traffic: TrafficConfig{Rate: "50/s"},
+		}
+		stats, spans := backfillRun(t, cfg, 10*time.Second)
+		assert.Positive(t, stats.CircuitBreakerTrips)
+		return maxInFlight(spans)
+	}
+
+	one := 1
+	assert.Equal(t, 1, run(&one), "probes beyond the cap should be rejected while one is in flight")
+	assert.Greater(t, run(nil), 1, "without a cap every half-open request probes")
+}
+
+func TestColdStartSpentAfterInvocations(t *testing.T) {
+	t.Parallel()
+
+	os := &OperationState{ColdStartLatency: 50 * time.Millisecond, ColdStartRemaining: 2}
+	assert.Equal(t, 50*time.Millisecond, os.ColdStart())
+	assert.Equal(t, 50*time.Millisecond, os.ColdStart())
+	assert.Zero(t, os.ColdStart(), "cold start should stop after the configured invocations")
+}
+
+func TestEngineColdStart(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{{
+			Name: "svc",
+			Operations: []OperationConfig{{
+				Name:      "op",
+				Duration:  "10ms",
+				ColdStart: &ColdStartConfig{Latency: "50ms", Invocations: 1},
+			}},
+		}},
+		Traffic: TrafficConfig{Rate: "100/s"},
+	}
+
+	for _, realtime := range []bool{false, true} {
+		engine, exporter, tp := newTestEngine(t, cfg)
+		engine.State = NewSimulationState(engine.Topology)
+		engine.Duration = time.Minute
+		engine.MaxTraces = 3
+		engine.Realtime = realtime
+		_, err := engine.Run(t.Context())
+		require.NoError(t, err)
+		require.NoError(t, tp.ForceFlush(context.Background()))
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 3)
+		slices.SortFunc(spans, func(a, b tracetest.SpanStub) int {
+			return a.StartTime.Compare(b.StartTime)
+		})
+		assert.Equal(t, 60*time.Millisecond, spans[0].EndTime.Sub(spans[0].StartTime), "realtime=%v: the first invocation is cold", realtime)
+		for _, s := range spans[1:] {
+			assert.Equal(t, 10*time.Millisecond, s.EndTime.Sub(s.StartTime), "realtime=%v: later invocations are warm", realtime)
+		}
+	}
+}
+
 func TestEngineStateNotCreatedWithoutConfig(t *testing.T) {
 	t.Parallel()
 
@@ -462,7 +969,7 @@ func TestCircuitBreakerPriorityOverQueueDepth(t *testing.T) {
 	}
 	rng := rand.New(rand.NewPCG(1, 0)) //nolint:gosec // deterministic seed for testing
 
-	_, _, rejected, reason := os.Admit(100*time.Millisecond, rng)
+	_, _, rejected, reason := os.Admit(simAt(100*time.Millisecond), 100*time.Millisecond, rng)
 	assert.True(t, rejected)
 	assert.Equal(t, ReasonCircuitOpen, reason, "circuit breaker should take priority over queue depth")
 }
@@ -91,11 +91,7 @@ func (e *Engine) forcedChoice(kind choiceKind, operationRef, targetRef string, c
 }
 
 func effectiveErrorRate(op *Operation, overrides map[string]Override) float64 {
-	errorRate := op.ErrorRate
-	if ov, ok := overrides[op.Ref]; ok && ov.HasErrorRate {
-		errorRate = ov.ErrorRate
-	}
-	return errorRate
+	return overrides[op.Ref].errorRate(op.ErrorRate)
 }
 
 func isChoiceRate(rate float64) bool {
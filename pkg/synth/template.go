@@ -0,0 +1,96 @@
+// Operation templates: reusable operation fields referenced by name
+// Merges a template's fields into each operation that names it before validation
+package synth
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// resolveTemplates merges each operation's named template into it, leaving
+// fields the operation sets itself untouched. Map fields such as attributes
+// merge by key with the operation's entries winning. A template may name
+// another template; unknown names and cycles are errors.
+func resolveTemplates(raw *rawConfig) error {
+	resolved := make(map[string]rawOperationConfig, len(raw.Templates))
+	var resolve func(name string, chain []string) (rawOperationConfig, error)
+	resolve = func(name string, chain []string) (rawOperationConfig, error) {
+		if t, ok := resolved[name]; ok {
+			return t, nil
+		}
+		if slices.Contains(chain, name) {
+			return rawOperationConfig{}, fmt.Errorf("template cycle: %s", strings.Join(append(chain, name), " -> "))
+		}
+		t, ok := raw.Templates[name]
+		if !ok {
+			return rawOperationConfig{}, fmt.Errorf("unknown template %q", name)
+		}
+		if t.Template != "" {
+			base, err := resolve(t.Template, append(chain, name))
+			if err != nil {
+				return rawOperationConfig{}, err
+			}
+			t = overlayOperation(base, t)
+		}
+		t.Template = ""
+		resolved[name] = t
+		return t, nil
+	}
+
+	// Resolve every template, used or not, so cycles are always reported.
+	names := make([]string, 0, len(raw.Templates))
+	for name := range raw.Templates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if _, err := resolve(name, nil); err != nil {
+			return fmt.Errorf("template %q: %w", name, err)
+		}
+	}
+
+	for svcName, svc := range raw.Services {
+		for opName, op := range svc.Operations {
+			if op.Template == "" {
+				continue
+			}
+			t, err := resolve(op.Template, nil)
+			if err != nil {
+				return fmt.Errorf("service %q operation %q: %w", svcName, opName, err)
+			}
+			merged := overlayOperation(t, op)
+			merged.Template = ""
+			svc.Operations[opName] = merged
+		}
+	}
+	return nil
+}
+
+// overlayOperation returns base with every non-zero field of over applied.
+// Maps are merged by key, with over's entries winning.
+func overlayOperation(base, over rawOperationConfig) rawOperationConfig {
+	out := base
+	dst := reflect.ValueOf(&out).Elem()
+	src := reflect.ValueOf(over)
+	for i := range src.NumField() {
+		field := src.Field(i)
+		if field.IsZero() {
+			continue
+		}
+		if field.Kind() == reflect.Map && !dst.Field(i).IsNil() {
+			merged := reflect.MakeMapWithSize(field.Type(), dst.Field(i).Len()+field.Len())
+			for _, m := range []reflect.Value{dst.Field(i), field} {
+				iter := m.MapRange()
+				for iter.Next() {
+					merged.SetMapIndex(iter.Key(), iter.Value())
+				}
+			}
+			dst.Field(i).Set(merged)
+			continue
+		}
+		dst.Field(i).Set(field)
+	}
+	return out
+}
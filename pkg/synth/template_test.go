@@ -0,0 +1,120 @@
+// Tests for reusable operation templates
+package synth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfigTemplates(t *testing.T) {
+	t.Parallel()
+
+	t.Run("template fields applied with operation fields winning", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := ParseConfig([]byte(`
+version: 1
+templates:
+  http-endpoint:
+    domain: http
+    duration: 20ms +/- 5ms
+    attributes:
+      http.response.status_code:
+        value: 200
+      deployment.tier:
+        value: edge
+services:
+  api:
+    operations:
+      GET /users:
+        template: http-endpoint
+      POST /users:
+        template: http-endpoint
+        duration: 80ms
+        attributes:
+          http.response.status_code:
+            value: 201
+traffic:
+  rate: 10/s
+`))
+		require.NoError(t, err)
+		require.Len(t, cfg.Services, 1)
+		ops := cfg.Services[0].Operations
+		require.Len(t, ops, 2)
+
+		get, post := ops[0], ops[1]
+		assert.Equal(t, "GET /users", get.Name)
+		assert.Equal(t, "http", get.Domain)
+		assert.Equal(t, "20ms +/- 5ms", get.Duration)
+		assert.Equal(t, 200, get.Attributes["http.response.status_code"].Value)
+
+		assert.Equal(t, "POST /users", post.Name)
+		assert.Equal(t, "http", post.Domain)
+		assert.Equal(t, "80ms", post.Duration, "operation duration should win")
+		assert.Equal(t, 201, post.Attributes["http.response.status_code"].Value, "operation attribute should win")
+		assert.Equal(t, "edge", post.Attributes["deployment.tier"].Value, "template attributes should merge by key")
+	})
+
+	t.Run("templates may extend templates", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := ParseConfig([]byte(`
+version: 1
+templates:
+  base:
+    duration: 10ms
+    error_rate: 1%
+  slow:
+    template: base
+    duration: 500ms
+services:
+  svc:
+    operations:
+      op:
+        template: slow
+traffic:
+  rate: 10/s
+`))
+		require.NoError(t, err)
+		op := cfg.Services[0].Operations[0]
+		assert.Equal(t, "500ms", op.Duration)
+		assert.Equal(t, "1%", op.ErrorRate)
+	})
+
+	t.Run("unknown template rejected", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseConfig([]byte(`
+version: 1
+services:
+  svc:
+    operations:
+      op:
+        template: missing
+traffic:
+  rate: 10/s
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `service "svc" operation "op": unknown template "missing"`)
+	})
+
+	t.Run("template cycle rejected", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseConfig([]byte(`
+version: 1
+templates:
+  a:
+    template: b
+  b:
+    template: a
+services:
+  svc:
+    operations:
+      op:
+        duration: 10ms
+traffic:
+  rate: 10/s
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "template cycle: a -> b -> a")
+	})
+}
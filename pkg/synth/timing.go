@@ -0,0 +1,54 @@
+// Parent-child timing: how long an operation's span waits for the calls it makes
+// Does its own work after its calls, alongside them, or returns without waiting for them
+package synth
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timing constants for an operation's timing field.
+const (
+	timingWaitAll      = "wait-all"
+	timingWaitCritical = "wait-critical"
+	timingReturnEarly  = "return-early"
+)
+
+// validateTiming checks an operation's timing.
+func validateTiming(timing string) error {
+	switch timing {
+	case "", timingWaitAll, timingWaitCritical, timingReturnEarly:
+		return nil
+	default:
+		return fmt.Errorf("timing must be %q, %q, or %q; got %q", timingWaitAll, timingWaitCritical, timingReturnEarly, timing)
+	}
+}
+
+// awaits reports whether op's span waits for a synchronous call that the
+// caller sees end at end: whether the call can push back op's own end, and
+// whether its failure cascades to it. returnBy is when op finishes its own
+// work, which a return-early operation does not wait beyond. No timing waits
+// for async calls.
+func (op *Operation) awaits(end, returnBy time.Time) bool {
+	return op.Timing != timingReturnEarly || !end.After(returnBy)
+}
+
+// spanEnd returns when op's span ends, given the end of the latest call it
+// waited for, the time it spends after its calls, and when its own work is
+// done. A wait-all operation does its remaining work after its calls
+// return; a wait-critical one does it while they run, so it ends with the
+// slowest call or its own work, whichever is later; a return-early one ends
+// at returnBy whatever its calls do.
+func (op *Operation) spanEnd(latestChildEnd time.Time, postCallDuration time.Duration, returnBy time.Time) time.Time {
+	switch op.Timing {
+	case timingWaitCritical:
+		if latestChildEnd.After(returnBy) {
+			return latestChildEnd
+		}
+		return returnBy
+	case timingReturnEarly:
+		return returnBy
+	default:
+		return latestChildEnd.Add(postCallDuration)
+	}
+}
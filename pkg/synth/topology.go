@@ -5,15 +5,25 @@ package synth
 import (
 	"cmp"
 	"fmt"
+	"maps"
+	"math"
 	"slices"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Topology is the resolved service graph ready for simulation.
 type Topology struct {
 	Services map[string]*Service
 	Roots    []*Operation
+	// TraceAttributes are generated once per trace at the root and attached
+	// to every span in that trace.
+	TraceAttributes Attributes
+	// regional is set when any service declares regions, so traces only
+	// track their services' regions when there are some to pick.
+	regional bool
 }
 
 // MetricDefinition is a resolved metric instrument definition.
@@ -50,6 +60,24 @@ type Service struct {
 	Baggage            map[string]string
 	Metrics            []MetricDefinition
 	Logs               []LogDefinition
+	SLO                *ResolvedSLO  // nil when the service declares no SLO
+	Flap               *ResolvedFlap // nil when the service does not flap
+	// TraceState is set on the root span context of traces starting in this
+	// service: its own tracestate entries merged over the top-level ones.
+	TraceState trace.TraceState
+	// Regions picks the region each trace's instance of the service runs
+	// in; nil when the service declares no regions.
+	Regions *WeightedChoice
+	// ScopeName and ScopeVersion name the instrumentation scope the
+	// service's signals are emitted under; empty means motel's own.
+	ScopeName    string
+	ScopeVersion string
+}
+
+// ResolvedSLO holds a service's parsed SLO.
+type ResolvedSLO struct {
+	Target float64
+	Window time.Duration
 }
 
 // ResolvedBackpressure holds parsed backpressure settings for an operation.
@@ -57,13 +85,44 @@ type ResolvedBackpressure struct {
 	LatencyThreshold   time.Duration
 	DurationMultiplier float64
 	ErrorRateAdd       float64
+	QueueModel         string
 }
 
 // ResolvedCircuitBreaker holds parsed circuit breaker settings for an operation.
+// A zero HalfOpenMaxProbes leaves half-open probes uncapped.
 type ResolvedCircuitBreaker struct {
-	FailureThreshold int
-	Window           time.Duration
-	Cooldown         time.Duration
+	FailureThreshold  int
+	Window            time.Duration
+	Cooldown          time.Duration
+	HalfOpenMaxProbes int
+}
+
+// ResolvedRateLimit holds parsed rate limit settings for an operation.
+// A zero MaxRate count or MaxConcurrency disables that limit.
+type ResolvedRateLimit struct {
+	MaxRate        Rate
+	MaxConcurrency int
+}
+
+// ResolvedColdStart holds parsed cold start settings for an operation.
+type ResolvedColdStart struct {
+	Latency     time.Duration
+	Invocations int
+}
+
+// ResolvedCache holds parsed cache settings for an operation.
+type ResolvedCache struct {
+	HitRate   float64
+	SkipCalls map[string]bool
+}
+
+// ErrorType is a resolved error category. When an operation's own error
+// fires, one of its error types is chosen by weight.
+type ErrorType struct {
+	Name       string
+	Weight     int
+	Message    string
+	Attributes Attributes
 }
 
 // Event represents a resolved span event emitted during an operation.
@@ -86,8 +145,16 @@ type Operation struct {
 	Ref        string
 	Duration   Distribution
 	ErrorRate  float64
+	ErrorTypes []ErrorType
 	Calls      []Call
+	OneOf      []Call // exactly one fires per span, chosen by Weight
 	CallStyle  string
+	CallJitter Distribution // delays the start of each parallel call; zero means none
+	// Timing says how the span's end relates to its synchronous calls: it
+	// does the rest of its own work after they return (wait-all, or empty),
+	// alongside them (wait-critical), or ends after its own duration without
+	// waiting for them (return-early). No timing waits for async calls.
+	Timing     string
 	Attributes Attributes
 	// Baggage is the operation's declared baggage: service-level entries merged
 	// with operation-level entries (operation wins). Set on the context when the
@@ -103,19 +170,77 @@ type Operation struct {
 	QueueDepth          int
 	Backpressure        *ResolvedBackpressure
 	CircuitBreaker      *ResolvedCircuitBreaker
+	RateLimit           *ResolvedRateLimit
+	ColdStart           *ResolvedColdStart
+	Cache               *ResolvedCache
+	// Concurrency is how many requests the operation serves at once (0 =
+	// unlimited). Requests beyond it wait in QueueDepth queue slots; without
+	// a concurrency, QueueDepth caps requests in flight instead.
+	Concurrency int
+	// ErrorRateAtPeak multiplies the error rate when traffic is at its peak,
+	// scaling in proportion from no change at the trough. Zero leaves the
+	// error rate independent of traffic.
+	ErrorRateAtPeak float64
+	// Traffic is a root operation's own arrival process, independent of the
+	// topology's traffic, which covers the roots without one. Nil for most.
+	Traffic TrafficPattern
+	// ErrorMessage is the status description of the operation's own errors,
+	// with {key} placeholders for span attributes. Empty means the default.
+	ErrorMessage string
+	// SwallowErrors keeps the operation's calls' failures from erroring its
+	// own span, as a fallback would; it can still error on its own.
+	SwallowErrors bool
+	// SpanName names the operation's spans, with {key} placeholders for span
+	// attributes. Empty names them after the operation.
+	SpanName string
+	// SlowThreshold marks spans lasting longer as slow, and replaces the
+	// LogObserver's own threshold for this operation. SlowStatus says what a
+	// slow span does: errors, gains synth.slow, or stays unset. Zero disables.
+	SlowThreshold time.Duration
+	SlowStatus    string
+	// GeneratedBaggage produces baggage values afresh each time the span
+	// starts; they are set on the context alongside Baggage.
+	GeneratedBaggage Attributes
+	// Domains are the semconv domains the operation inherits attributes
+	// from, used to select operations for scenario matches.
+	Domains []string
 }
 
 // Call represents a resolved downstream call with optional modifiers.
+// When HasErrorRate is set, ErrorRate replaces the callee's own error rate
+// for spans made through this call. The wait before retry n (counting from 0) is RetryBackoff scaled by
+// RetryBackoffMultiplier^n, capped at an hour, less a random fraction of up to RetryJitter.
 type Call struct {
-	Operation    *Operation
-	Probability  float64
-	Condition    string
-	Count        int
-	Timeout      time.Duration
-	Retries      int
-	RetryBackoff time.Duration
-	Async        bool
-	Producer     bool
+	Operation              *Operation
+	Probability            float64
+	Condition              string
+	Count                  int
+	CountDist              *CountDistribution // when set, samples Count per parent span
+	ErrorRate              float64
+	HasErrorRate           bool
+	Timeout                time.Duration
+	Retries                int
+	RetryBackoff           time.Duration
+	RetryBackoffMultiplier float64
+	RetryJitter            float64
+	HedgeAfter             time.Duration
+	Async                  bool
+	Producer               bool
+	Weight                 int // chance of being chosen from a one_of group
+	// ProbabilityDecay scales Probability by decay^depth; zero means no decay.
+	ProbabilityDecay float64
+	// Attributes are merged over the callee's own attributes on the spans
+	// of this call.
+	Attributes Attributes
+}
+
+// probabilityAt returns the chance call fires from a span at depth, the root
+// span being depth 0.
+func (c Call) probabilityAt(depth int) float64 {
+	if c.ProbabilityDecay <= 0 {
+		return c.Probability
+	}
+	return c.Probability * math.Pow(c.ProbabilityDecay, float64(depth))
 }
 
 // DomainResolver maps a domain identifier to attribute generators.
@@ -126,16 +251,30 @@ type DomainResolver func(domain string) map[string]AttributeGenerator
 // cfg must have passed ValidateConfig first. Duration strings in backpressure
 // and circuit breaker config are parsed without error checks here because
 // validation has already rejected malformed values.
+// Disabled operations and calls are left out, along with calls to disabled
+// operations.
 // An optional DomainResolver enables the domain field on operations.
 func BuildTopology(cfg *Config, resolvers ...DomainResolver) (*Topology, error) {
 	var resolve DomainResolver
 	if len(resolvers) > 0 {
 		resolve = resolvers[0]
 	}
+	cfg = enabledConfig(cfg)
 
 	topo := &Topology{
 		Services: make(map[string]*Service, len(cfg.Services)),
 	}
+	if len(cfg.TraceAttributes) > 0 {
+		gens := make(map[string]AttributeGenerator, len(cfg.TraceAttributes))
+		for attrName, attrCfg := range cfg.TraceAttributes {
+			gen, err := NewAttributeGenerator(attrCfg)
+			if err != nil {
+				return nil, fmt.Errorf("trace_attributes: attribute %q: %w", attrName, err)
+			}
+			gens[attrName] = gen
+		}
+		topo.TraceAttributes = NewAttributes(gens)
+	}
 
 	// First pass: create all services and operations
 	for _, svcCfg := range cfg.Services {
@@ -145,6 +284,26 @@ func BuildTopology(cfg *Config, resolvers ...DomainResolver) (*Topology, error)
 			ResourceAttributes: svcCfg.ResourceAttributes,
 			Attributes:         svcCfg.Attributes,
 			Baggage:            svcCfg.Baggage,
+			ScopeName:          cmp.Or(svcCfg.ScopeName, cfg.ScopeName),
+			ScopeVersion:       cmp.Or(svcCfg.ScopeVersion, cfg.ScopeVersion),
+			TraceState:         buildTraceState(cfg.TraceState, svcCfg.TraceState),
+		}
+		regions, err := buildRegions(svcCfg.Regions)
+		if err != nil {
+			return nil, fmt.Errorf("service %q: regions: %w", svcCfg.Name, err)
+		}
+		if regions != nil {
+			svc.Regions = regions
+			topo.regional = true
+		}
+		if svcCfg.SLO != nil {
+			svc.SLO = &ResolvedSLO{Target: svcCfg.SLO.Target, Window: defaultSLOWindow}
+			if svcCfg.SLO.Window != "" {
+				svc.SLO.Window, _ = time.ParseDuration(svcCfg.SLO.Window)
+			}
+		}
+		if svcCfg.Flap != nil {
+			svc.Flap = newResolvedFlap(svcCfg.Flap, svcCfg.Name)
 		}
 		if len(svcCfg.Metrics) > 0 {
 			resolved, err := resolveMetrics(svcCfg.Metrics, svcCfg.Name, "")
@@ -172,15 +331,39 @@ func BuildTopology(cfg *Config, resolvers ...DomainResolver) (*Topology, error)
 					return nil, fmt.Errorf("service %q operation %q: %w", svcCfg.Name, opCfg.Name, err)
 				}
 			}
+			// Later domains win on key conflicts.
 			var attrs map[string]AttributeGenerator
-			if opCfg.Domain != "" {
+			for _, domain := range opCfg.DomainNames() {
 				if resolve == nil {
-					return nil, fmt.Errorf("service %q operation %q: domain %q specified but no domain resolver configured", svcCfg.Name, opCfg.Name, opCfg.Domain)
+					return nil, fmt.Errorf("service %q operation %q: domain %q specified but no domain resolver configured", svcCfg.Name, opCfg.Name, domain)
+				}
+				domainAttrs := resolve(domain)
+				if domainAttrs == nil {
+					return nil, fmt.Errorf("service %q operation %q: unknown domain %q", svcCfg.Name, opCfg.Name, domain)
 				}
-				attrs = resolve(opCfg.Domain)
 				if attrs == nil {
-					return nil, fmt.Errorf("service %q operation %q: unknown domain %q", svcCfg.Name, opCfg.Name, opCfg.Domain)
+					attrs = make(map[string]AttributeGenerator, len(domainAttrs))
+				}
+				maps.Copy(attrs, domainAttrs)
+			}
+			// The engine derives an http operation's status code from the
+			// span outcome, so the domain's example codes are dropped.
+			if slices.Contains(opCfg.DomainNames(), httpDomain) {
+				delete(attrs, httpStatusCodeAttribute)
+			}
+			// Service defaults sit beneath everything set at operation level,
+			// including domain attributes.
+			if len(svcCfg.DefaultAttributes) > 0 {
+				merged := make(map[string]AttributeGenerator, len(svcCfg.DefaultAttributes)+len(attrs)+len(opCfg.Attributes))
+				for name, acfg := range svcCfg.DefaultAttributes {
+					gen, err := NewAttributeGenerator(acfg)
+					if err != nil {
+						return nil, fmt.Errorf("service %q default attribute %q: %w", svcCfg.Name, name, err)
+					}
+					merged[name] = gen
 				}
+				maps.Copy(merged, attrs)
+				attrs = merged
 			}
 			if len(opCfg.Attributes) > 0 {
 				if attrs == nil {
@@ -209,10 +392,41 @@ func BuildTopology(cfg *Config, resolvers ...DomainResolver) (*Topology, error)
 				Duration:            dist,
 				ErrorRate:           errorRate,
 				CallStyle:           opCfg.CallStyle,
+				Timing:              opCfg.Timing,
 				Attributes:          NewAttributes(attrs),
 				Baggage:             mergeDeclaredBaggage(svcCfg.Baggage, opCfg.Baggage),
 				BaggageAsAttributes: baggageAsAttrs,
+				Concurrency:         opCfg.Concurrency,
+				ErrorRateAtPeak:     opCfg.ErrorRateAtPeak,
 				QueueDepth:          opCfg.QueueDepth,
+				ErrorMessage:        opCfg.ErrorMessage,
+				SwallowErrors:       opCfg.SwallowErrors,
+				SpanName:            opCfg.SpanName,
+				SlowStatus:          opCfg.SlowStatus,
+				Domains:             opCfg.DomainNames(),
+			}
+			if opCfg.SlowThreshold != "" {
+				op.SlowThreshold, err = time.ParseDuration(opCfg.SlowThreshold)
+				if err != nil {
+					return nil, fmt.Errorf("service %q operation %q: slow_threshold: %w", svcCfg.Name, opCfg.Name, err)
+				}
+			}
+			if opCfg.CallJitter != "" {
+				op.CallJitter, err = ParseDistribution(opCfg.CallJitter)
+				if err != nil {
+					return nil, fmt.Errorf("service %q operation %q: call_jitter: %w", svcCfg.Name, opCfg.Name, err)
+				}
+			}
+			if len(opCfg.GeneratedBaggage) > 0 {
+				gens := make(map[string]AttributeGenerator, len(opCfg.GeneratedBaggage))
+				for key, acfg := range opCfg.GeneratedBaggage {
+					gen, err := NewAttributeGenerator(acfg)
+					if err != nil {
+						return nil, fmt.Errorf("service %q operation %q generated_baggage %q: %w", svcCfg.Name, opCfg.Name, key, err)
+					}
+					gens[key] = gen
+				}
+				op.GeneratedBaggage = NewAttributes(gens)
 			}
 			if len(opCfg.Metrics) > 0 {
 				resolved, mErr := resolveMetrics(opCfg.Metrics, svcCfg.Name, opCfg.Name)
@@ -229,7 +443,10 @@ func BuildTopology(cfg *Config, resolvers ...DomainResolver) (*Topology, error)
 				op.Logs = resolved
 			}
 			if opCfg.Backpressure != nil {
-				lt, _ := time.ParseDuration(opCfg.Backpressure.LatencyThreshold)
+				var lt time.Duration
+				if opCfg.Backpressure.LatencyThreshold != "" {
+					lt, _ = time.ParseDuration(opCfg.Backpressure.LatencyThreshold)
+				}
 				var errAdd float64
 				if opCfg.Backpressure.ErrorRateAdd != "" {
 					errAdd, _ = parseErrorRate(opCfg.Backpressure.ErrorRateAdd)
@@ -238,6 +455,7 @@ func BuildTopology(cfg *Config, resolvers ...DomainResolver) (*Topology, error)
 					LatencyThreshold:   lt,
 					DurationMultiplier: opCfg.Backpressure.DurationMultiplier,
 					ErrorRateAdd:       errAdd,
+					QueueModel:         opCfg.Backpressure.QueueModel,
 				}
 			}
 			if opCfg.CircuitBreaker != nil {
@@ -248,6 +466,54 @@ func BuildTopology(cfg *Config, resolvers ...DomainResolver) (*Topology, error)
 					Window:           w,
 					Cooldown:         cd,
 				}
+				if opCfg.CircuitBreaker.HalfOpenMaxProbes != nil {
+					op.CircuitBreaker.HalfOpenMaxProbes = *opCfg.CircuitBreaker.HalfOpenMaxProbes
+				}
+			}
+			if opCfg.RateLimit != nil {
+				op.RateLimit = &ResolvedRateLimit{MaxConcurrency: opCfg.RateLimit.MaxConcurrency}
+				if opCfg.RateLimit.MaxRate != "" {
+					op.RateLimit.MaxRate, _ = ParseRate(opCfg.RateLimit.MaxRate)
+				}
+			}
+			if opCfg.ColdStart != nil {
+				latency, _ := time.ParseDuration(opCfg.ColdStart.Latency)
+				op.ColdStart = &ResolvedColdStart{Latency: latency, Invocations: opCfg.ColdStart.Invocations}
+			}
+			if opCfg.Traffic != nil {
+				traffic, err := NewTrafficPattern(*opCfg.Traffic)
+				if err != nil {
+					return nil, fmt.Errorf("service %q operation %q: traffic: %w", svcCfg.Name, opCfg.Name, err)
+				}
+				op.Traffic = traffic
+			}
+			if opCfg.Cache != nil {
+				skip := make(map[string]bool, len(opCfg.Cache.SkipCalls))
+				for _, target := range opCfg.Cache.SkipCalls {
+					skip[target] = true
+				}
+				op.Cache = &ResolvedCache{
+					HitRate:   opCfg.Cache.HitRate,
+					SkipCalls: skip,
+				}
+			}
+			for _, etCfg := range opCfg.ErrorTypes {
+				et := ErrorType{Name: etCfg.Name, Weight: 1, Message: etCfg.Message}
+				if etCfg.Weight != nil {
+					et.Weight = *etCfg.Weight
+				}
+				if len(etCfg.Attributes) > 0 {
+					gens := make(map[string]AttributeGenerator, len(etCfg.Attributes))
+					for name, acfg := range etCfg.Attributes {
+						gen, err := NewAttributeGenerator(acfg)
+						if err != nil {
+							return nil, fmt.Errorf("service %q operation %q error type %q attribute %q: %w", svcCfg.Name, opCfg.Name, etCfg.Name, name, err)
+						}
+						gens[name] = gen
+					}
+					et.Attributes = NewAttributes(gens)
+				}
+				op.ErrorTypes = append(op.ErrorTypes, et)
 			}
 			if len(opCfg.Events) > 0 {
 				op.Events = make([]Event, len(opCfg.Events))
@@ -306,33 +572,19 @@ func BuildTopology(cfg *Config, resolvers ...DomainResolver) (*Topology, error)
 				})
 			}
 			for _, callCfg := range opCfg.Calls {
-				_, targetOp, err := resolveRef(topo, callCfg.Target)
+				call, err := buildCall(topo, callCfg)
 				if err != nil {
 					return nil, fmt.Errorf("service %q operation %q: %w", svcCfg.Name, opCfg.Name, err)
 				}
-				call := Call{
-					Operation:   targetOp,
-					Probability: callCfg.Probability,
-					Condition:   callCfg.Condition,
-					Count:       callCfg.Count,
-					Retries:     callCfg.Retries,
-					Async:       callCfg.Async,
-					Producer:    callCfg.Producer,
-				}
-				if callCfg.Timeout != "" {
-					call.Timeout, err = time.ParseDuration(callCfg.Timeout)
-					if err != nil {
-						return nil, fmt.Errorf("service %q operation %q: call %q: invalid timeout: %w", svcCfg.Name, opCfg.Name, callCfg.Target, err)
-					}
-				}
-				if callCfg.RetryBackoff != "" {
-					call.RetryBackoff, err = time.ParseDuration(callCfg.RetryBackoff)
-					if err != nil {
-						return nil, fmt.Errorf("service %q operation %q: call %q: invalid retry_backoff: %w", svcCfg.Name, opCfg.Name, callCfg.Target, err)
-					}
-				}
 				op.Calls = append(op.Calls, call)
 			}
+			for _, callCfg := range opCfg.OneOf {
+				call, err := buildCall(topo, callCfg)
+				if err != nil {
+					return nil, fmt.Errorf("service %q operation %q: one_of: %w", svcCfg.Name, opCfg.Name, err)
+				}
+				op.OneOf = append(op.OneOf, call)
+			}
 		}
 	}
 
@@ -343,10 +595,82 @@ func BuildTopology(cfg *Config, resolvers ...DomainResolver) (*Topology, error)
 
 	// Detect root operations (not called by any other operation)
 	topo.Roots = findRoots(topo)
+	for _, svcCfg := range cfg.Services {
+		for _, opCfg := range svcCfg.Operations {
+			op := topo.Services[svcCfg.Name].Operations[opCfg.Name]
+			if op.Traffic != nil && !slices.Contains(topo.Roots, op) {
+				return nil, fmt.Errorf("service %q operation %q: traffic applies only to root operations", svcCfg.Name, opCfg.Name)
+			}
+		}
+	}
 
 	return topo, nil
 }
 
+// buildCall resolves a call's target and parses its durations and rates.
+func buildCall(topo *Topology, callCfg CallConfig) (Call, error) {
+	_, targetOp, err := resolveRef(topo, callCfg.Target)
+	if err != nil {
+		return Call{}, err
+	}
+	call := Call{
+		Operation:              targetOp,
+		Probability:            callCfg.Probability,
+		ProbabilityDecay:       callCfg.ProbabilityDecay,
+		Condition:              callCfg.Condition,
+		Count:                  callCfg.Count,
+		Retries:                callCfg.Retries,
+		RetryBackoffMultiplier: max(callCfg.RetryBackoffMultiplier, 1),
+		RetryJitter:            callCfg.RetryJitter,
+		Async:                  callCfg.Async,
+		Producer:               callCfg.Producer,
+		Weight:                 callCfg.Weight,
+	}
+	if callCfg.ErrorRate != "" {
+		call.ErrorRate, err = parseErrorRate(callCfg.ErrorRate)
+		if err != nil {
+			return Call{}, fmt.Errorf("call %q: %w", callCfg.Target, err)
+		}
+		call.HasErrorRate = true
+	}
+	if callCfg.CountDistribution != "" {
+		call.CountDist, err = ParseCountDistribution(callCfg.CountDistribution)
+		if err != nil {
+			return Call{}, fmt.Errorf("call %q: invalid count_distribution: %w", callCfg.Target, err)
+		}
+	}
+	if callCfg.Timeout != "" {
+		call.Timeout, err = time.ParseDuration(callCfg.Timeout)
+		if err != nil {
+			return Call{}, fmt.Errorf("call %q: invalid timeout: %w", callCfg.Target, err)
+		}
+	}
+	if callCfg.RetryBackoff != "" {
+		call.RetryBackoff, err = time.ParseDuration(callCfg.RetryBackoff)
+		if err != nil {
+			return Call{}, fmt.Errorf("call %q: invalid retry_backoff: %w", callCfg.Target, err)
+		}
+	}
+	if callCfg.HedgeAfter != "" {
+		call.HedgeAfter, err = time.ParseDuration(callCfg.HedgeAfter)
+		if err != nil {
+			return Call{}, fmt.Errorf("call %q: invalid hedge_after: %w", callCfg.Target, err)
+		}
+	}
+	if len(callCfg.Attributes) > 0 {
+		gens := make(map[string]AttributeGenerator, len(callCfg.Attributes))
+		for attrName, attrCfg := range callCfg.Attributes {
+			gen, genErr := NewAttributeGenerator(attrCfg)
+			if genErr != nil {
+				return Call{}, fmt.Errorf("call %q: attribute %q: %w", callCfg.Target, attrName, genErr)
+			}
+			gens[attrName] = gen
+		}
+		call.Attributes = NewAttributes(gens)
+	}
+	return call, nil
+}
+
 // resolveMetrics converts MetricConfig entries into MetricDefinitions.
 func resolveMetrics(configs []MetricConfig, svcName, opName string) ([]MetricDefinition, error) {
 	defs := make([]MetricDefinition, len(configs))
@@ -485,7 +809,7 @@ func findRoots(topo *Topology) []*Operation {
 	called := make(map[*Operation]bool)
 	for _, svc := range topo.Services {
 		for _, op := range svc.Operations {
-			for _, call := range op.Calls {
+			for _, call := range slices.Concat(op.Calls, op.OneOf) {
 				called[call.Operation] = true
 			}
 		}
@@ -526,7 +850,7 @@ func detectCycles(topo *Topology) error {
 			return nil
 		}
 		state[op] = visiting
-		for _, call := range op.Calls {
+		for _, call := range slices.Concat(op.Calls, op.OneOf) {
 			if err := visit(call.Operation); err != nil {
 				return err
 			}
@@ -13,6 +13,23 @@ import (
 func TestBuildTopology(t *testing.T) {
 	t.Parallel()
 
+	t.Run("slo", func(t *testing.T) {
+		t.Parallel()
+		cfg := &Config{
+			Services: []ServiceConfig{
+				{Name: "a", SLO: &SLOConfig{Target: 0.999}, Operations: []OperationConfig{{Name: "op", Duration: "1ms"}}},
+				{Name: "b", SLO: &SLOConfig{Target: 0.99, Window: "1h"}, Operations: []OperationConfig{{Name: "op", Duration: "1ms"}}},
+				{Name: "c", Operations: []OperationConfig{{Name: "op", Duration: "1ms"}}},
+			},
+			Traffic: TrafficConfig{Rate: "100/s"},
+		}
+		topo, err := BuildTopology(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, &ResolvedSLO{Target: 0.999, Window: defaultSLOWindow}, topo.Services["a"].SLO)
+		assert.Equal(t, &ResolvedSLO{Target: 0.99, Window: time.Hour}, topo.Services["b"].SLO)
+		assert.Nil(t, topo.Services["c"].SLO)
+	})
+
 	t.Run("simple two-service graph", func(t *testing.T) {
 		t.Parallel()
 		cfg := &Config{
@@ -285,6 +302,41 @@ func TestBuildTopology(t *testing.T) {
 		assert.Equal(t, "/api/v1/users", op.Attributes.Get("http.route").Generate(nil))
 	})
 
+	t.Run("http domain status code is left to the engine", func(t *testing.T) {
+		t.Parallel()
+		resolver := func(domain string) map[string]AttributeGenerator {
+			return map[string]AttributeGenerator{
+				"http.route":                &StaticValue{Value: "/default"},
+				"http.response.status_code": &StaticValue{Value: int64(200)},
+			}
+		}
+		cfg := &Config{
+			Services: []ServiceConfig{{
+				Name: "svc",
+				Operations: []OperationConfig{
+					{Name: "derived", Domain: "http", Duration: "10ms"},
+					{
+						Name:     "explicit",
+						Domain:   "http",
+						Duration: "10ms",
+						Attributes: map[string]AttributeValueConfig{
+							"http.response.status_code": {Value: 201},
+						},
+					},
+				},
+			}},
+			Traffic: TrafficConfig{Rate: "10/s"},
+		}
+
+		topo, err := BuildTopology(cfg, resolver)
+		require.NoError(t, err)
+		derived := topo.Services["svc"].Operations["derived"]
+		assert.Nil(t, derived.Attributes.Get("http.response.status_code"))
+		assert.NotNil(t, derived.Attributes.Get("http.route"))
+		explicit := topo.Services["svc"].Operations["explicit"]
+		assert.Equal(t, 201, explicit.Attributes.Get("http.response.status_code").Generate(nil))
+	})
+
 	t.Run("domain with no resolver returns error", func(t *testing.T) {
 		t.Parallel()
 		cfg := &Config{
@@ -357,6 +409,70 @@ func TestBuildTopology(t *testing.T) {
 		assert.Equal(t, "/api/v1/users", op.Attributes.Get("http.route").Generate(nil))
 	})
 
+	t.Run("multiple domains merge with later domains winning", func(t *testing.T) {
+		t.Parallel()
+		resolver := func(domain string) map[string]AttributeGenerator {
+			switch domain {
+			case "http":
+				return map[string]AttributeGenerator{
+					"http.method": &StaticValue{Value: "GET"},
+					"shared":      &StaticValue{Value: "http"},
+				}
+			case "url":
+				return map[string]AttributeGenerator{
+					"url.path": &StaticValue{Value: "/items"},
+					"shared":   &StaticValue{Value: "url"},
+				}
+			}
+			return nil
+		}
+		cfg := &Config{
+			Services: []ServiceConfig{{
+				Name: "svc",
+				Operations: []OperationConfig{{
+					Name:     "op",
+					Domains:  []string{"http", "url"},
+					Duration: "10ms",
+				}},
+			}},
+			Traffic: TrafficConfig{Rate: "10/s"},
+		}
+
+		topo, err := BuildTopology(cfg, resolver)
+		require.NoError(t, err)
+		op := topo.Services["svc"].Operations["op"]
+		require.Len(t, op.Attributes, 3)
+		assert.Equal(t, "GET", op.Attributes.Get("http.method").Generate(nil))
+		assert.Equal(t, "/items", op.Attributes.Get("url.path").Generate(nil))
+		assert.Equal(t, "url", op.Attributes.Get("shared").Generate(nil))
+	})
+
+	t.Run("unknown domain in domains returns error", func(t *testing.T) {
+		t.Parallel()
+		resolver := func(domain string) map[string]AttributeGenerator {
+			if domain == "http" {
+				return map[string]AttributeGenerator{"http.method": &StaticValue{Value: "GET"}}
+			}
+			return nil
+		}
+		cfg := &Config{
+			Services: []ServiceConfig{{
+				Name: "svc",
+				Operations: []OperationConfig{{
+					Name:     "op",
+					Domain:   "http",
+					Domains:  []string{"nonexistent"},
+					Duration: "10ms",
+				}},
+			}},
+			Traffic: TrafficConfig{Rate: "10/s"},
+		}
+
+		_, err := BuildTopology(cfg, resolver)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown domain "nonexistent"`)
+	})
+
 	t.Run("no domain ignores resolver", func(t *testing.T) {
 		t.Parallel()
 		called := false
@@ -559,3 +675,54 @@ func TestBuildTopologyLogs(t *testing.T) {
 		assert.Contains(t, err.Error(), "invalid delay")
 	})
 }
+
+func TestBuildTopologyDisabled(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		Services: []ServiceConfig{
+			{Name: "gateway", Operations: []OperationConfig{
+				{Name: "request", Duration: "1ms", Calls: []CallConfig{
+					{Target: "backend.list"},
+					{Target: "backend.count", Disabled: true},
+					{Target: "audit.record"},
+				}, OneOf: []CallConfig{
+					{Target: "backend.list", Weight: 1},
+					{Target: "audit.record", Weight: 1},
+				}},
+				{Name: "admin", Duration: "1ms", Disabled: true, Calls: []CallConfig{{Target: "backend.export"}}},
+			}},
+			{Name: "backend", Operations: []OperationConfig{
+				{Name: "list", Duration: "1ms"},
+				{Name: "count", Duration: "1ms"},
+				{Name: "export", Duration: "1ms"},
+			}},
+			{Name: "audit", Operations: []OperationConfig{
+				{Name: "record", Duration: "1ms", Disabled: true},
+			}},
+		},
+		Traffic: TrafficConfig{Rate: "10/s"},
+	}
+	require.NoError(t, ValidateConfig(cfg))
+
+	topo, err := BuildTopology(cfg)
+	require.NoError(t, err)
+
+	assert.NotContains(t, topo.Services, "audit", "a service with every operation disabled is dropped")
+	gateway := topo.Services["gateway"]
+	assert.NotContains(t, gateway.Operations, "admin")
+	request := gateway.Operations["request"]
+	require.Len(t, request.Calls, 1)
+	assert.Equal(t, "backend.list", request.Calls[0].Operation.Ref)
+	require.Len(t, request.OneOf, 1)
+	assert.Equal(t, "backend.list", request.OneOf[0].Operation.Ref)
+
+	var roots []string
+	for _, op := range topo.Roots {
+		roots = append(roots, op.Ref)
+	}
+	assert.ElementsMatch(t, []string{"gateway.request", "backend.count", "backend.export"}, roots,
+		"operations only called by disabled calls or operations become roots")
+	assert.Len(t, cfg.Services[0].Operations, 2, "the config itself is not modified")
+	assert.Len(t, cfg.Services[0].Operations[0].Calls, 3)
+}
@@ -289,13 +289,26 @@ func parseStdouttraceReader(r io.Reader) ([]Span, error) {
 	return spans, nil
 }
 
+// parseOTLP reads one OTLP JSON export request, or a sequence of them such
+// as the newline-delimited output of the collector's file exporter.
 func parseOTLP(data []byte) ([]Span, error) {
-	var req otlpTraces
-	if err := json.Unmarshal(data, &req); err != nil {
-		return nil, fmt.Errorf("parsing OTLP: %w", err)
+	var spans []Span
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var req otlpTraces
+		if err := dec.Decode(&req); err != nil {
+			return nil, fmt.Errorf("parsing OTLP: %w", err)
+		}
+		spans = appendOTLPSpans(spans, req)
 	}
 
-	var spans []Span
+	if len(spans) == 0 {
+		return nil, fmt.Errorf("no spans found in input")
+	}
+	return spans, nil
+}
+
+func appendOTLPSpans(spans []Span, req otlpTraces) []Span {
 	for _, rs := range req.ResourceSpans {
 		// Extract service.name from resource attributes
 		serviceName := ""
@@ -342,11 +355,7 @@ func parseOTLP(data []byte) ([]Span, error) {
 			}
 		}
 	}
-
-	if len(spans) == 0 {
-		return nil, fmt.Errorf("no spans found in input")
-	}
-	return spans, nil
+	return spans
 }
 
 // stringAttr returns the string value of the attribute with the given key,
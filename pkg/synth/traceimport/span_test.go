@@ -142,6 +142,23 @@ func TestParseOTLP_HexEncodedIDs(t *testing.T) {
 	assert.Equal(t, "0312e23151fd56ee", s.ParentID)
 }
 
+func TestParseOTLP_NewlineDelimited(t *testing.T) {
+	line := func(service, spanID string) string {
+		return `{"resourceSpans": [{"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "` + service + `"}}]},` +
+			`"scopeSpans": [{"spans": [{"traceId": "9da7c5910d265353de4ae5973ea6b727", "spanId": "` + spanID + `", "name": "op",` +
+			`"startTimeUnixNano": "1700000000000000000", "endTimeUnixNano": "1700000000030000000", "status": {}}]}]}]}`
+	}
+	input := line("api", "b86a4a145c519715") + "\n" + line("db", "0312e23151fd56ee") + "\n"
+
+	for _, format := range []Format{FormatOTLP, FormatAuto} {
+		spans, err := ParseSpans(strings.NewReader(input), format)
+		require.NoError(t, err, "format %s", format)
+		require.Len(t, spans, 2, "one span from each export request")
+		assert.Equal(t, "api", spans[0].Service)
+		assert.Equal(t, "db", spans[1].Service)
+	}
+}
+
 func TestParseOTLP_GrafanaTempoExport(t *testing.T) {
 	input := `{
 		"batches": [{
@@ -0,0 +1,63 @@
+// W3C tracestate entries set on the span context of each trace's root
+// Top-level entries apply to every trace; a root service's own entries win
+package synth
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxTraceStateMembers is the most list-members W3C allows in a tracestate.
+const maxTraceStateMembers = 32
+
+// validateTraceState checks that each entry is a valid W3C tracestate
+// list-member and that entries, together with the inherited entries they
+// are merged over, stay within maxTraceStateMembers.
+func validateTraceState(entries, inherited map[string]string, prefix string) error {
+	for _, k := range slices.Sorted(maps.Keys(entries)) {
+		if _, err := (trace.TraceState{}).Insert(k, entries[k]); err != nil {
+			return fmt.Errorf("%s: tracestate entry %q=%q: %w", prefix, k, entries[k], err)
+		}
+	}
+	merged := len(inherited)
+	for k := range entries {
+		if _, ok := inherited[k]; !ok {
+			merged++
+		}
+	}
+	if merged > maxTraceStateMembers {
+		return fmt.Errorf("%s: tracestate has %d entries, more than the W3C limit of %d", prefix, merged, maxTraceStateMembers)
+	}
+	return nil
+}
+
+// buildTraceState merges a service's tracestate entries over the top-level
+// ones. The service's keys come first, as the most recently added, then the
+// remaining top-level keys; each group is in key order. The entries must
+// have passed validateTraceState.
+func buildTraceState(top, svc map[string]string) trace.TraceState {
+	var ts trace.TraceState
+	for _, entries := range []map[string]string{top, svc} {
+		keys := slices.Sorted(maps.Keys(entries))
+		for _, k := range slices.Backward(keys) {
+			ts, _ = ts.Insert(k, entries[k])
+		}
+	}
+	return ts
+}
+
+// withRootTraceState returns ctx carrying the tracestate of op's service,
+// for starting the root span of a trace. The SDK's samplers copy the
+// tracestate of the context's span context onto the new span, and children
+// inherit it from there.
+func withRootTraceState(ctx context.Context, op *Operation) context.Context {
+	ts := op.Service.TraceState
+	if ts.Len() == 0 {
+		return ctx
+	}
+	return trace.ContextWithSpanContext(ctx, trace.SpanContext{}.WithTraceState(ts))
+}
@@ -6,7 +6,9 @@ import (
 	"cmp"
 	"fmt"
 	"math"
+	"math/rand/v2"
 	"slices"
+	"sync"
 	"time"
 )
 
@@ -19,11 +21,36 @@ const (
 	defaultDiurnalPeriod    = 24 * time.Hour
 )
 
+// burstScheduleSeed seeds the RNG that jitters burst starts, so a jittered
+// bursty pattern fires on the same schedule every run.
+const burstScheduleSeed = 0x6275727374
+
 // TrafficPattern determines the trace generation rate at any given elapsed time.
 type TrafficPattern interface {
 	Rate(elapsed time.Duration) float64 // traces per second
 }
 
+// rateRanger is implemented by the built-in traffic patterns, which know the
+// lowest and highest rates they reach.
+type rateRanger interface {
+	rateRange() (low, high float64)
+}
+
+// trafficPeak reports how close p's rate at elapsed is to its peak: 0 at its
+// lowest rate and 1 at its highest. A pattern whose rate never changes, or
+// that does not report its range, is always at 0.
+func trafficPeak(p TrafficPattern, elapsed time.Duration) float64 {
+	r, ok := p.(rateRanger)
+	if !ok {
+		return 0
+	}
+	low, high := r.rateRange()
+	if high <= low {
+		return 0
+	}
+	return min(max((p.Rate(elapsed)-low)/(high-low), 0), 1)
+}
+
 // NewTrafficPattern creates a TrafficPattern from configuration.
 func NewTrafficPattern(cfg TrafficConfig) (TrafficPattern, error) {
 	base, err := newBasePattern(cfg)
@@ -31,26 +58,71 @@ func NewTrafficPattern(cfg TrafficConfig) (TrafficPattern, error) {
 		return nil, err
 	}
 
-	if cfg.Overlay == nil {
+	if len(cfg.Overlays) == 0 {
 		return base, nil
 	}
 
-	overlayPattern, err := newBasePattern(*cfg.Overlay)
-	if err != nil {
-		return nil, fmt.Errorf("overlay: %w", err)
+	overlays := make([]TrafficPattern, 0, len(cfg.Overlays))
+	for i, oc := range cfg.Overlays {
+		if len(oc.Overlays) > 0 {
+			return nil, fmt.Errorf("overlays[%d]: nested overlays are not supported", i)
+		}
+		overlay, err := newBasePattern(oc)
+		if err != nil {
+			return nil, fmt.Errorf("overlays[%d]: %w", i, err)
+		}
+		overlays = append(overlays, overlay)
 	}
 
-	overlayRate, err := ParseRate(cfg.Overlay.Rate)
+	return &compositePattern{Base: base, Overlays: overlays}, nil
+}
+
+// ScaleTrafficRate replaces cfg's base rate with rate, keeping the pattern's
+// shape: the rates of custom segments and of overlays are scaled by the same
+// factor, and multipliers are left as they are. It does not touch scenario
+// or per-operation traffic.
+func ScaleTrafficRate(cfg *TrafficConfig, rate string) error {
+	to, err := ParseRate(rate)
+	if err != nil {
+		return err
+	}
+	from, err := ParseRate(cfg.Rate)
 	if err != nil {
-		return nil, fmt.Errorf("overlay: invalid traffic rate: %w", err)
+		return fmt.Errorf("invalid traffic rate: %w", err)
+	}
+	cfg.Rate = rate
+	factor := to.PerSecond() / from.PerSecond()
+	if err := scaleSegments(cfg.Segments, factor); err != nil {
+		return err
+	}
+	for i := range cfg.Overlays {
+		overlay := &cfg.Overlays[i]
+		r, err := ParseRate(overlay.Rate)
+		if err != nil {
+			return fmt.Errorf("overlays[%d]: invalid traffic rate: %w", i, err)
+		}
+		if overlay.Rate, err = formatRate(r.PerSecond() * factor); err != nil {
+			return fmt.Errorf("overlays[%d]: %w", i, err)
+		}
+		if err := scaleSegments(overlay.Segments, factor); err != nil {
+			return fmt.Errorf("overlays[%d]: %w", i, err)
+		}
 	}
-	overlayBaseRate := float64(overlayRate.Count()) / overlayRate.Period().Seconds()
+	return nil
+}
 
-	return &compositePattern{
-		Base:            base,
-		Overlay:         overlayPattern,
-		OverlayBaseRate: overlayBaseRate,
-	}, nil
+// scaleSegments scales the rate of each custom segment by factor, in place.
+func scaleSegments(segments []SegmentConfig, factor float64) error {
+	for i := range segments {
+		r, err := ParseRate(segments[i].Rate)
+		if err != nil {
+			return fmt.Errorf("segments[%d]: invalid rate: %w", i, err)
+		}
+		if segments[i].Rate, err = formatRate(r.PerSecond() * factor); err != nil {
+			return fmt.Errorf("segments[%d]: %w", i, err)
+		}
+	}
+	return nil
 }
 
 func newBasePattern(cfg TrafficConfig) (TrafficPattern, error) {
@@ -116,11 +188,28 @@ func newBurstyPattern(baseRate float64, cfg TrafficConfig) (*BurstyPattern, erro
 		return nil, fmt.Errorf("burst_duration (%s) must be less than burst_interval (%s)", duration, interval)
 	}
 
+	var jitter time.Duration
+	if cfg.BurstIntervalJitter != "" {
+		d, err := time.ParseDuration(cfg.BurstIntervalJitter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid burst_interval_jitter %q: %w", cfg.BurstIntervalJitter, err)
+		}
+		jitter = d
+	}
+	if jitter < 0 {
+		return nil, fmt.Errorf("burst_interval_jitter must not be negative, got %s", jitter)
+	}
+	if interval-jitter <= duration {
+		return nil, fmt.Errorf("burst_interval_jitter (%s) must leave the shortest gap between bursts (%s) longer than burst_duration (%s)",
+			jitter, interval-jitter, duration)
+	}
+
 	return &BurstyPattern{
-		BaseRate:        baseRate,
-		BurstMultiplier: multiplier,
-		BurstInterval:   interval,
-		BurstDuration:   duration,
+		BaseRate:            baseRate,
+		BurstMultiplier:     multiplier,
+		BurstInterval:       interval,
+		BurstDuration:       duration,
+		BurstIntervalJitter: jitter,
 	}, nil
 }
 
@@ -175,6 +264,10 @@ func (p *UniformPattern) Rate(_ time.Duration) float64 {
 	return p.BaseRate
 }
 
+func (p *UniformPattern) rateRange() (float64, float64) {
+	return p.BaseRate, p.BaseRate
+}
+
 // DiurnalPattern models a day/night cycle using a sine wave oscillating between
 // trough and peak multipliers over a configurable period.
 type DiurnalPattern struct {
@@ -194,23 +287,71 @@ func (p *DiurnalPattern) Rate(elapsed time.Duration) float64 {
 	return p.BaseRate * factor
 }
 
+func (p *DiurnalPattern) rateRange() (float64, float64) {
+	return p.BaseRate * p.TroughMultiplier, p.BaseRate * p.PeakMultiplier
+}
+
 // BurstyPattern alternates between a base rate and periodic high-rate bursts.
+// With a BurstIntervalJitter, the gap from one burst start to the next is
+// drawn uniformly from BurstInterval plus or minus the jitter instead.
 type BurstyPattern struct {
-	BaseRate        float64
-	BurstMultiplier float64
-	BurstInterval   time.Duration
-	BurstDuration   time.Duration
+	BaseRate            float64
+	BurstMultiplier     float64
+	BurstInterval       time.Duration
+	BurstDuration       time.Duration
+	BurstIntervalJitter time.Duration
+
+	// mu guards the jittered schedule, which Rate extends as elapsed grows.
+	mu     sync.Mutex
+	rng    *rand.Rand
+	starts []time.Duration
 }
 
 // Rate returns the burst rate during burst windows and the base rate otherwise.
 func (p *BurstyPattern) Rate(elapsed time.Duration) float64 {
-	cyclePos := elapsed % p.BurstInterval
-	if cyclePos < p.BurstDuration {
+	if p.burstStart(elapsed) < p.BurstDuration {
 		return p.BaseRate * p.BurstMultiplier
 	}
 	return p.BaseRate
 }
 
+func (p *BurstyPattern) rateRange() (float64, float64) {
+	burst := p.BaseRate * p.BurstMultiplier
+	return min(p.BaseRate, burst), max(p.BaseRate, burst)
+}
+
+// burstStart returns how long ago the most recent burst started.
+func (p *BurstyPattern) burstStart(elapsed time.Duration) time.Duration {
+	if p.BurstIntervalJitter == 0 {
+		return elapsed % p.BurstInterval
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	starts := p.burstStarts(elapsed)
+	i, found := slices.BinarySearch(starts, elapsed)
+	if !found {
+		i--
+	}
+	if i < 0 {
+		return elapsed
+	}
+	return elapsed - starts[i]
+}
+
+// burstStarts extends the jittered schedule of burst start times until it
+// passes elapsed. The first burst starts at zero, as without jitter.
+func (p *BurstyPattern) burstStarts(elapsed time.Duration) []time.Duration {
+	if p.rng == nil {
+		p.rng = rand.New(rand.NewPCG(burstScheduleSeed, 0)) //nolint:gosec // synthetic data, not security-sensitive
+		p.starts = []time.Duration{0}
+	}
+	for last := p.starts[len(p.starts)-1]; last <= elapsed; last = p.starts[len(p.starts)-1] {
+		offset := time.Duration((2*p.rng.Float64() - 1) * float64(p.BurstIntervalJitter))
+		p.starts = append(p.starts, last+p.BurstInterval+offset)
+	}
+	return p.starts
+}
+
 // segment is a parsed time-bounded rate used by customPattern.
 type segment struct {
 	Until time.Duration
@@ -233,20 +374,40 @@ func (p *customPattern) Rate(elapsed time.Duration) float64 {
 	return p.BaseRate
 }
 
-// compositePattern layers an overlay pattern on top of a base pattern. The
-// overlay modulates the base rate by the ratio of overlay rate to overlay base.
+func (p *customPattern) rateRange() (float64, float64) {
+	low, high := p.BaseRate, p.BaseRate
+	for _, seg := range p.Segments {
+		low, high = min(low, seg.Rate), max(high, seg.Rate)
+	}
+	return low, high
+}
+
+// compositePattern adds independent overlay patterns on top of a base
+// pattern: its rate is the sum of all their rates.
 type compositePattern struct {
-	Base            TrafficPattern
-	Overlay         TrafficPattern
-	OverlayBaseRate float64
+	Base     TrafficPattern
+	Overlays []TrafficPattern
 }
 
 func (p *compositePattern) Rate(elapsed time.Duration) float64 {
-	if p.OverlayBaseRate == 0 {
-		return p.Base.Rate(elapsed)
+	rate := p.Base.Rate(elapsed)
+	for _, overlay := range p.Overlays {
+		rate += overlay.Rate(elapsed)
+	}
+	return rate
+}
+
+// rateRange bounds the composite rate by the sums of its parts' lowest and
+// highest rates; the parts need not reach them at the same time.
+func (p *compositePattern) rateRange() (float64, float64) {
+	var low, high float64
+	for _, part := range append([]TrafficPattern{p.Base}, p.Overlays...) {
+		if r, ok := part.(rateRanger); ok {
+			l, h := r.rateRange()
+			low, high = low+l, high+h
+		}
 	}
-	factor := p.Overlay.Rate(elapsed) / p.OverlayBaseRate
-	return p.Base.Rate(elapsed) * factor
+	return low, high
 }
 
 func newCustomPattern(baseRate float64, cfg TrafficConfig) (*customPattern, error) {
@@ -148,18 +148,18 @@ func TestNewTrafficPattern(t *testing.T) {
 		assert.Contains(t, err.Error(), "rate")
 	})
 
-	t.Run("with overlay produces composite", func(t *testing.T) {
+	t.Run("with overlays produces composite", func(t *testing.T) {
 		t.Parallel()
 		p, err := NewTrafficPattern(TrafficConfig{
 			Rate:    "100/s",
 			Pattern: "diurnal",
-			Overlay: &TrafficConfig{
+			Overlays: []TrafficConfig{{
 				Rate:            "100/s",
 				Pattern:         "bursty",
 				BurstMultiplier: 3,
 				BurstInterval:   "2m",
 				BurstDuration:   "15s",
-			},
+			}},
 		})
 		require.NoError(t, err)
 		assert.IsType(t, &compositePattern{}, p)
@@ -170,13 +170,13 @@ func TestNewTrafficPattern(t *testing.T) {
 		_, err := NewTrafficPattern(TrafficConfig{
 			Rate:    "100/s",
 			Pattern: "uniform",
-			Overlay: &TrafficConfig{
+			Overlays: []TrafficConfig{{
 				Rate:    "bad",
 				Pattern: "bursty",
-			},
+			}},
 		})
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "overlay")
+		assert.Contains(t, err.Error(), "overlays[0]")
 	})
 
 	t.Run("unknown pattern", func(t *testing.T) {
@@ -313,6 +313,57 @@ func TestBurstyPattern(t *testing.T) {
 	assert.Greater(t, burstRate, normalRate)
 }
 
+func TestBurstyPatternJitter(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewTrafficPattern(TrafficConfig{
+		Rate:                "100/s",
+		Pattern:             "bursty",
+		BurstInterval:       "1m",
+		BurstDuration:       "5s",
+		BurstIntervalJitter: "20s",
+	})
+	require.NoError(t, err)
+
+	// Find each burst's start by stepping a second at a time.
+	var starts []time.Duration
+	inBurst := false
+	for elapsed := time.Duration(0); elapsed < 500*time.Minute; elapsed += time.Second {
+		bursting := p.Rate(elapsed) > 100
+		if bursting && !inBurst {
+			starts = append(starts, elapsed)
+		}
+		inBurst = bursting
+	}
+	require.Greater(t, len(starts), 400)
+	assert.Equal(t, time.Duration(0), starts[0], "the first burst starts immediately, as without jitter")
+
+	gaps := make(map[time.Duration]bool)
+	var total time.Duration
+	for i := 1; i < len(starts); i++ {
+		gap := starts[i] - starts[i-1]
+		assert.GreaterOrEqual(t, gap, 40*time.Second-time.Second)
+		assert.LessOrEqual(t, gap, 80*time.Second+time.Second)
+		gaps[gap] = true
+		total += gap
+	}
+	assert.Greater(t, len(gaps), 10, "gaps between bursts should vary")
+	mean := total / time.Duration(len(starts)-1)
+	assert.InDelta(t, float64(time.Minute), float64(mean), float64(3*time.Second), "gaps should average near burst_interval")
+
+	again, err := NewTrafficPattern(TrafficConfig{
+		Rate:                "100/s",
+		Pattern:             "bursty",
+		BurstInterval:       "1m",
+		BurstDuration:       "5s",
+		BurstIntervalJitter: "20s",
+	})
+	require.NoError(t, err)
+	for _, start := range starts {
+		assert.Greater(t, again.Rate(start), 100.0, "the schedule is the same for every pattern built from the config")
+	}
+}
+
 func TestCustomPattern(t *testing.T) {
 	t.Parallel()
 
@@ -382,56 +433,57 @@ func TestCustomPattern(t *testing.T) {
 func TestCompositePattern(t *testing.T) {
 	t.Parallel()
 
-	t.Run("overlay modulates base rate", func(t *testing.T) {
+	t.Run("overlay adds to base rate", func(t *testing.T) {
 		t.Parallel()
 		base := &UniformPattern{BaseRate: 100}
 		overlay := &BurstyPattern{
-			BaseRate:        100,
+			BaseRate:        20,
 			BurstMultiplier: 5,
 			BurstInterval:   5 * time.Minute,
 			BurstDuration:   30 * time.Second,
 		}
-		cp := &compositePattern{Base: base, Overlay: overlay, OverlayBaseRate: 100}
-
-		// During burst (start of cycle), overlay rate = 500, factor = 500/100 = 5
-		assert.InDelta(t, 500.0, cp.Rate(0), 0.001)
+		cp := &compositePattern{Base: base, Overlays: []TrafficPattern{overlay}}
 
-		// During normal period (1 min into cycle), overlay rate = 100, factor = 1
-		assert.InDelta(t, 100.0, cp.Rate(1*time.Minute), 0.001)
+		// Burst: 100 + 20*5; between bursts: 100 + 20
+		assert.InDelta(t, 200.0, cp.Rate(0), 0.001)
+		assert.InDelta(t, 120.0, cp.Rate(1*time.Minute), 0.001)
 	})
 
-	t.Run("diurnal base with bursty overlay", func(t *testing.T) {
+	t.Run("diurnal base with two bursty overlays", func(t *testing.T) {
 		t.Parallel()
-		base := &DiurnalPattern{
-			BaseRate:         100,
-			PeakMultiplier:   defaultPeakMultiplier,
-			TroughMultiplier: defaultTroughMultiplier,
-			Period:           defaultDiurnalPeriod,
-		}
-		overlay := &BurstyPattern{
-			BaseRate:        100,
-			BurstMultiplier: 3,
-			BurstInterval:   10 * time.Minute,
-			BurstDuration:   1 * time.Minute,
-		}
-		cp := &compositePattern{Base: base, Overlay: overlay, OverlayBaseRate: 100}
-
-		// At 2 minutes (normal overlay period), composite = base diurnal rate * 1.0
-		baseAt2m := base.Rate(2 * time.Minute)
-		assert.InDelta(t, baseAt2m, cp.Rate(2*time.Minute), 0.001)
+		p, err := NewTrafficPattern(TrafficConfig{
+			Rate:    "100/s",
+			Pattern: "diurnal",
+			Overlays: []TrafficConfig{
+				{Rate: "10/s", Pattern: "bursty", BurstMultiplier: 3, BurstInterval: "10m", BurstDuration: "1m"},
+				{Rate: "5/s", Pattern: "bursty", BurstMultiplier: 4, BurstInterval: "7m", BurstDuration: "30s"},
+			},
+		})
+		require.NoError(t, err)
+		cp, ok := p.(*compositePattern)
+		require.True(t, ok)
+		require.Len(t, cp.Overlays, 2)
 
-		// At 10 minutes (burst start), composite = base diurnal rate * 3.0
-		baseAt10m := base.Rate(10 * time.Minute)
-		assert.InDelta(t, baseAt10m*3.0, cp.Rate(10*time.Minute), 0.001)
+		for elapsed := time.Duration(0); elapsed < time.Hour; elapsed += 15 * time.Second {
+			want := cp.Base.Rate(elapsed) + cp.Overlays[0].Rate(elapsed) + cp.Overlays[1].Rate(elapsed)
+			assert.InDelta(t, want, p.Rate(elapsed), 1e-9, "rate at %s", elapsed)
+		}
+		// At 0 both overlays burst; at 2 minutes neither does.
+		assert.InDelta(t, 10.0*3+5.0*4, p.Rate(0)-cp.Base.Rate(0), 1e-9)
+		assert.InDelta(t, 10.0+5.0, p.Rate(2*time.Minute)-cp.Base.Rate(2*time.Minute), 1e-9)
 	})
 
-	t.Run("zero overlay base rate returns base rate", func(t *testing.T) {
+	t.Run("nested overlays rejected", func(t *testing.T) {
 		t.Parallel()
-		base := &UniformPattern{BaseRate: 100}
-		overlay := &UniformPattern{BaseRate: 50}
-		cp := &compositePattern{Base: base, Overlay: overlay, OverlayBaseRate: 0}
-
-		assert.InDelta(t, 100.0, cp.Rate(0), 0.001)
+		_, err := NewTrafficPattern(TrafficConfig{
+			Rate: "100/s",
+			Overlays: []TrafficConfig{{
+				Rate:     "10/s",
+				Overlays: []TrafficConfig{{Rate: "1/s"}},
+			}},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "nested overlays are not supported")
 	})
 }
 
@@ -484,6 +536,41 @@ func TestBurstyPatternValidation(t *testing.T) {
 		assert.Contains(t, err.Error(), "burst_duration")
 	})
 
+	t.Run("negative burst interval jitter rejected", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewTrafficPattern(TrafficConfig{
+			Rate:                "100/s",
+			Pattern:             "bursty",
+			BurstIntervalJitter: "-1s",
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "burst_interval_jitter must not be negative")
+	})
+
+	t.Run("burst interval jitter overlapping bursts rejected", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewTrafficPattern(TrafficConfig{
+			Rate:                "100/s",
+			Pattern:             "bursty",
+			BurstInterval:       "1m",
+			BurstDuration:       "30s",
+			BurstIntervalJitter: "30s",
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "longer than burst_duration")
+	})
+
+	t.Run("invalid burst interval jitter rejected", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewTrafficPattern(TrafficConfig{
+			Rate:                "100/s",
+			Pattern:             "bursty",
+			BurstIntervalJitter: "soon",
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid burst_interval_jitter")
+	})
+
 	t.Run("negative burst multiplier rejected", func(t *testing.T) {
 		t.Parallel()
 		_, err := NewTrafficPattern(TrafficConfig{
@@ -507,6 +594,21 @@ func TestBurstyPatternValidation(t *testing.T) {
 	})
 }
 
+func TestTrafficPeak(t *testing.T) {
+	t.Parallel()
+
+	diurnal := &DiurnalPattern{BaseRate: 100, PeakMultiplier: 1.5, TroughMultiplier: 0.5, Period: 4 * time.Hour}
+	assert.InDelta(t, 0.0, trafficPeak(diurnal, 0), 1e-9, "the period starts at the trough")
+	assert.InDelta(t, 0.5, trafficPeak(diurnal, time.Hour), 1e-9)
+	assert.InDelta(t, 1.0, trafficPeak(diurnal, 2*time.Hour), 1e-9, "the peak is halfway through the period")
+
+	bursty := &BurstyPattern{BaseRate: 10, BurstMultiplier: 5, BurstInterval: time.Minute, BurstDuration: 10 * time.Second}
+	assert.InDelta(t, 1.0, trafficPeak(bursty, 5*time.Second), 1e-9, "inside a burst")
+	assert.InDelta(t, 0.0, trafficPeak(bursty, 30*time.Second), 1e-9, "between bursts")
+
+	assert.Zero(t, trafficPeak(&UniformPattern{BaseRate: 10}, time.Hour), "a constant rate has no peak")
+}
+
 func TestDiurnalPatternValidation(t *testing.T) {
 	t.Parallel()
 
@@ -584,3 +686,62 @@ func TestCustomPatternValidation(t *testing.T) {
 		assert.Contains(t, err.Error(), "duplicate")
 	})
 }
+
+func TestScaleTrafficRate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("diurnal keeps its multipliers", func(t *testing.T) {
+		t.Parallel()
+		cfg := TrafficConfig{Rate: "100/s", Pattern: "diurnal", PeakMultiplier: 2, TroughMultiplier: 0.5, Period: "1h"}
+		require.NoError(t, ScaleTrafficRate(&cfg, "10/s"))
+		assert.Equal(t, "10/s", cfg.Rate)
+		assert.InDelta(t, 2.0, cfg.PeakMultiplier, 1e-9)
+		assert.InDelta(t, 0.5, cfg.TroughMultiplier, 1e-9)
+	})
+
+	t.Run("custom segments and overlays scale with the base", func(t *testing.T) {
+		t.Parallel()
+		cfg := TrafficConfig{
+			Rate:    "100/s",
+			Pattern: "custom",
+			Segments: []SegmentConfig{
+				{Until: "1m", Rate: "50/s"},
+				{Until: "2m", Rate: "3/s"},
+			},
+			Overlays: []TrafficConfig{{Rate: "20/s"}},
+		}
+		require.NoError(t, ScaleTrafficRate(&cfg, "10/s"))
+		assert.Equal(t, "10/s", cfg.Rate)
+		assert.Equal(t, "5/s", cfg.Segments[0].Rate)
+		assert.Equal(t, "18/m", cfg.Segments[1].Rate)
+		assert.Equal(t, "2/s", cfg.Overlays[0].Rate)
+
+		p, err := NewTrafficPattern(cfg)
+		require.NoError(t, err)
+		assert.InDelta(t, 7.0, p.Rate(30*time.Second), 1e-9)
+		assert.InDelta(t, 2.3, p.Rate(90*time.Second), 1e-9)
+		assert.InDelta(t, 12.0, p.Rate(3*time.Minute), 1e-9)
+	})
+
+	t.Run("tiny scaled rates round in the coarsest unit", func(t *testing.T) {
+		t.Parallel()
+		cfg := TrafficConfig{Rate: "1000/s", Pattern: "custom", Segments: []SegmentConfig{{Until: "1m", Rate: "1/s"}}}
+		require.NoError(t, ScaleTrafficRate(&cfg, "1/h"))
+		assert.Equal(t, "1/h", cfg.Segments[0].Rate)
+	})
+
+	t.Run("scaled rate above the maximum", func(t *testing.T) {
+		t.Parallel()
+		cfg := TrafficConfig{Rate: "1/s", Pattern: "custom", Segments: []SegmentConfig{{Until: "1m", Rate: "100/s"}}}
+		err := ScaleTrafficRate(&cfg, "1000/s")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "segments[0]: rate of 100000/s exceeds 10000/s")
+	})
+
+	t.Run("invalid rate", func(t *testing.T) {
+		t.Parallel()
+		cfg := TrafficConfig{Rate: "100/s"}
+		require.Error(t, ScaleTrafficRate(&cfg, "fast"))
+		assert.Equal(t, "100/s", cfg.Rate)
+	})
+}
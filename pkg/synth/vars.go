@@ -0,0 +1,118 @@
+// Config vars: named values defined once and referenced across the topology
+// Replaces {{ vars.name }} references in string fields before validation
+package synth
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// varRefPattern matches a {{ vars.name }} reference, with optional spaces
+// inside the braces.
+var varRefPattern = regexp.MustCompile(`\{\{\s*vars\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// interpolateVars replaces {{ vars.name }} references in every string field
+// of raw with the value of the named var. A field holding a single reference
+// to a numeric or boolean var in an untyped position, such as an attribute
+// value, takes the var's type; everywhere else the value is formatted into
+// the string. References to undefined vars are an error naming each one.
+func interpolateVars(raw *rawConfig) error {
+	vars := raw.Vars
+	raw.Vars = nil
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		switch vars[name].(type) {
+		case string, int, int64, uint64, float64, bool:
+		default:
+			return fmt.Errorf("var %q must be a string, number, or boolean", name)
+		}
+	}
+
+	missing := make(map[string]bool)
+	interpolateValue(reflect.ValueOf(raw).Elem(), vars, missing)
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return fmt.Errorf("undefined var(s): %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// interpolateValue walks v, rewriting strings in place. Map values are not
+// addressable, so each is copied, rewritten, and stored back.
+func interpolateValue(v reflect.Value, vars map[string]any, missing map[string]bool) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(interpolateString(v.String(), vars, missing))
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			interpolateValue(v.Elem(), vars, missing)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if field := v.Field(i); field.CanSet() {
+				interpolateValue(field, vars, missing)
+			}
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			interpolateValue(v.Index(i), vars, missing)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			interpolateValue(elem, vars, missing)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return
+		}
+		inner := v.Elem()
+		if inner.Kind() == reflect.String {
+			v.Set(reflect.ValueOf(interpolateAny(inner.String(), vars, missing)))
+			return
+		}
+		elem := reflect.New(inner.Type()).Elem()
+		elem.Set(inner)
+		interpolateValue(elem, vars, missing)
+		v.Set(elem)
+	}
+}
+
+func interpolateString(s string, vars map[string]any, missing map[string]bool) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return varRefPattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := varRefPattern.FindStringSubmatch(match)[1]
+		val, ok := vars[name]
+		if !ok {
+			missing[name] = true
+			return match
+		}
+		return fmt.Sprint(val)
+	})
+}
+
+// interpolateAny is interpolateString for untyped values: a string that is
+// exactly one reference becomes the var's own value.
+func interpolateAny(s string, vars map[string]any, missing map[string]bool) any {
+	if loc := varRefPattern.FindStringSubmatchIndex(s); loc != nil && loc[0] == 0 && loc[1] == len(s) {
+		name := s[loc[2]:loc[3]]
+		if val, ok := vars[name]; ok {
+			return val
+		}
+	}
+	return interpolateString(s, vars, missing)
+}
@@ -0,0 +1,167 @@
+// Tests for config vars interpolation
+package synth
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfigVars(t *testing.T) {
+	t.Parallel()
+
+	t.Run("var shared by two operations parses to the same distribution", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := ParseConfig([]byte(`
+version: 1
+vars:
+  base_latency: 40ms
+  jitter: 5
+services:
+  api:
+    operations:
+      list:
+        duration: "{{ vars.base_latency }} +/- {{vars.jitter}}ms"
+      get:
+        duration: "{{ vars.base_latency }} +/- {{ vars.jitter }}ms"
+traffic:
+  rate: 10/s
+`))
+		require.NoError(t, err)
+		require.NoError(t, ValidateConfig(cfg))
+		ops := cfg.Services[0].Operations
+		require.Len(t, ops, 2)
+
+		first, err := ParseDistribution(ops[0].Duration)
+		require.NoError(t, err)
+		second, err := ParseDistribution(ops[1].Duration)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+		assert.Equal(t, "40ms +/- 5ms", ops[0].Duration)
+	})
+
+	t.Run("numeric var keeps its type as an attribute value", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := ParseConfig([]byte(`
+version: 1
+vars:
+  status: 200
+  region: eu-west-1
+services:
+  api:
+    operations:
+      get:
+        duration: 10ms
+        attributes:
+          http.response.status_code:
+            value: "{{ vars.status }}"
+          cloud.region:
+            value: "{{ vars.region }}"
+          server.address:
+            value: "api.{{ vars.region }}.example.com"
+traffic:
+  rate: "{{ vars.status }}/s"
+`))
+		require.NoError(t, err)
+		attrs := cfg.Services[0].Operations[0].Attributes
+		assert.Equal(t, 200, attrs["http.response.status_code"].Value)
+		assert.Equal(t, "eu-west-1", attrs["cloud.region"].Value)
+		assert.Equal(t, "api.eu-west-1.example.com", attrs["server.address"].Value)
+		assert.Equal(t, "200/s", cfg.Traffic.Rate)
+	})
+
+	t.Run("undefined vars are errors", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseConfig([]byte(`
+version: 1
+services:
+  api:
+    operations:
+      get:
+        duration: "{{ vars.latency }}"
+        error_rate: "{{ vars.errors }}"
+traffic:
+  rate: 10/s
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "undefined var(s): errors, latency")
+	})
+
+	t.Run("structured var values are rejected", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseConfig([]byte(`
+version: 1
+vars:
+  latency:
+    mean: 10ms
+services:
+  api:
+    operations:
+      get:
+        duration: 10ms
+traffic:
+  rate: 10/s
+`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `var "latency" must be a string, number, or boolean`)
+	})
+
+	t.Run("vars apply inside templates and scenarios", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := ParseConfig([]byte(`
+version: 1
+vars:
+  slow: 500ms
+templates:
+  db:
+    duration: "{{ vars.slow }}"
+services:
+  api:
+    operations:
+      get:
+        template: db
+traffic:
+  rate: 10/s
+scenarios:
+  - name: degraded
+    at: +1m
+    duration: 5m
+    override:
+      api.get:
+        duration: "{{ vars.slow }}"
+`))
+		require.NoError(t, err)
+		assert.Equal(t, "500ms", cfg.Services[0].Operations[0].Duration)
+		assert.Equal(t, "500ms", cfg.Scenarios[0].Override["api.get"].Duration)
+	})
+
+	t.Run("vars from included files are visible and overridable", func(t *testing.T) {
+		t.Parallel()
+		dir := writeConfigFiles(t, map[string]string{
+			"main.yaml": `
+version: 1
+include: [shared.yaml]
+vars:
+  rate: 25
+traffic:
+  rate: "{{ vars.rate }}/s"
+`,
+			"shared.yaml": `
+vars:
+  latency: 30ms
+  rate: 5
+services:
+  api:
+    operations:
+      get:
+        duration: "{{ vars.latency }}"
+`,
+		})
+		cfg, err := LoadConfig(filepath.Join(dir, "main.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, "30ms", cfg.Services[0].Operations[0].Duration)
+		assert.Equal(t, "25/s", cfg.Traffic.Rate, "including file's var wins")
+	})
+}
@@ -5,9 +5,11 @@
 //
 //	go run ./tools/dgg2motel -dir sample_data/DGG_gen_cgs/20250109_150211 -out topologies/
 //	go run ./tools/dgg2motel -file graph1.json
+//	go run ./tools/dgg2motel -file graph1.json -error-rate 2% -error-label blackhole
 package main
 
 import (
+	"cmp"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -16,7 +18,9 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // DGG JSON structures.
@@ -43,6 +47,12 @@ type dggEdge struct {
 // motel topology structures — just enough to marshal YAML by hand
 // (avoids pulling in a YAML library for a standalone tool).
 
+// convertOptions controls how DGG graphs are converted.
+type convertOptions struct {
+	errorLabel string // node label whose operations fail; matched case-insensitively
+	errorRate  string // error_rate given to those operations, e.g. "5%"; empty disables
+}
+
 // funcRE matches DGG node names with a _funcN suffix, e.g. "MS_normal+2.1_func2".
 // The greedy (.+) assumes _funcN only appears as a terminal suffix in DGG output.
 var funcRE = regexp.MustCompile(`^(.+)_(func\d+)$`)
@@ -51,19 +61,25 @@ func main() {
 	fileFlag := flag.String("file", "", "single DGG JSON file to convert")
 	dirFlag := flag.String("dir", "", "directory tree of DGG JSON files to convert")
 	outFlag := flag.String("out", "", "output directory (default: stdout for -file, required for -dir)")
+	errorRateFlag := flag.String("error-rate", "5%", "error rate for operations of nodes with the -error-label label (0% disables)")
+	errorLabelFlag := flag.String("error-label", "blackhole", "DGG node label treated as a failure sink")
 	flag.Parse()
 
 	if *fileFlag == "" && *dirFlag == "" {
 		fmt.Fprintln(os.Stderr, "usage: dgg2motel -file graph.json | -dir path/to/DGG_gen_cgs/")
 		os.Exit(1)
 	}
+	opts, err := newConvertOptions(*errorLabelFlag, *errorRateFlag)
+	if err != nil {
+		fatal(err)
+	}
 
 	if *fileFlag != "" {
 		data, err := os.ReadFile(*fileFlag)
 		if err != nil {
 			fatal(err)
 		}
-		yaml, err := convertOne(data)
+		yaml, err := convertOne(data, opts)
 		if err != nil {
 			fatal(fmt.Errorf("%s: %w", *fileFlag, err))
 		}
@@ -84,7 +100,7 @@ func main() {
 	}
 
 	var converted, skipped int
-	err := filepath.WalkDir(*dirFlag, func(path string, d fs.DirEntry, err error) error {
+	err = filepath.WalkDir(*dirFlag, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -96,7 +112,7 @@ func main() {
 		if err != nil {
 			return err
 		}
-		yaml, err := convertOne(data)
+		yaml, err := convertOne(data, opts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "skip %s: %v\n", path, err)
 			skipped++
@@ -122,7 +138,23 @@ func main() {
 	fmt.Fprintf(os.Stderr, "converted %d graphs, skipped %d\n", converted, skipped)
 }
 
-func convertOne(data []byte) (string, error) {
+// newConvertOptions checks the -error-label and -error-rate flag values.
+func newConvertOptions(errorLabel, errorRate string) (convertOptions, error) {
+	pct, ok := strings.CutSuffix(errorRate, "%")
+	if !ok {
+		return convertOptions{}, fmt.Errorf("-error-rate must be a percentage, e.g. 5%%, got %q", errorRate)
+	}
+	rate, err := strconv.ParseFloat(pct, 64)
+	if err != nil || rate < 0 || rate > 100 {
+		return convertOptions{}, fmt.Errorf("-error-rate must be between 0%% and 100%%, got %q", errorRate)
+	}
+	if rate == 0 {
+		return convertOptions{}, nil
+	}
+	return convertOptions{errorLabel: errorLabel, errorRate: errorRate}, nil
+}
+
+func convertOne(data []byte, opts convertOptions) (string, error) {
 	var g dggGraph
 	if err := json.Unmarshal(data, &g); err != nil {
 		return "", fmt.Errorf("parse JSON: %w", err)
@@ -142,6 +174,7 @@ func convertOne(data []byte) (string, error) {
 	type opInfo struct {
 		name  string
 		label string
+		scale float64 // latency scale from the first edge into the node; 0 if none
 		calls []childEdge
 	}
 	type svcInfo struct {
@@ -196,8 +229,12 @@ func convertOne(data []byte) (string, error) {
 		nodeToSvc[n.Node] = baseName
 	}
 
-	// Wire up calls.
+	// Wire up calls. The first edge into each node, including edges from
+	// USER, sets the latency scale of the node's operation.
 	for _, e := range g.Edges {
+		if op, ok := nodeToOp[e.DM]; ok && op.scale == 0 {
+			op.scale = comparaScale(e.Compara)
+		}
 		if e.UM == "USER" {
 			continue
 		}
@@ -224,7 +261,10 @@ func convertOne(data []byte) (string, error) {
 
 		for _, op := range svc.ops {
 			fmt.Fprintf(&b, "      %s:\n", op.name)
-			fmt.Fprintf(&b, "        duration: %s\n", durationForLabel(op.label))
+			fmt.Fprintf(&b, "        duration: %s\n", durationForLabel(op.label, cmp.Or(op.scale, 1)))
+			if opts.errorRate != "" && strings.EqualFold(op.label, opts.errorLabel) {
+				fmt.Fprintf(&b, "        error_rate: %s\n", opts.errorRate)
+			}
 
 			// Add calls.
 			if len(op.calls) > 0 {
@@ -277,18 +317,51 @@ func cleanName(name string) string {
 	return name
 }
 
-// durationForLabel returns a reasonable synthetic duration based on the DGG service label.
-func durationForLabel(label string) string {
+// durationForLabel returns a reasonable synthetic duration based on the DGG
+// service label, with the mean and spread multiplied by scale.
+func durationForLabel(label string, scale float64) string {
+	var mean, spread time.Duration
 	switch strings.ToLower(label) {
 	case "memcached":
-		return "1ms +/- 500us"
+		mean, spread = time.Millisecond, 500*time.Microsecond
 	case "blackhole":
-		return "5ms +/- 2ms"
+		mean, spread = 5*time.Millisecond, 2*time.Millisecond
 	case "relay":
-		return "10ms +/- 5ms"
+		mean, spread = 10*time.Millisecond, 5*time.Millisecond
 	default: // "normal" and anything else
-		return "20ms +/- 10ms"
+		mean, spread = 20*time.Millisecond, 10*time.Millisecond
+	}
+	return formatDuration(time.Duration(float64(mean)*scale)) + " +/- " + formatDuration(time.Duration(float64(spread)*scale))
+}
+
+// comparaScale returns the latency scale for a call edge's compara field.
+// A positive number is taken as the callee's latency relative to its label
+// default; otherwise compara names the protocol, and slower protocols get a
+// larger scale. Unknown protocols keep the label default.
+func comparaScale(compara string) float64 {
+	if f, err := strconv.ParseFloat(compara, 64); err == nil && f > 0 {
+		return f
+	}
+	switch strings.ToLower(compara) {
+	case "mq":
+		return 0.5
+	case "http":
+		return 1.5
+	case "db":
+		return 2
+	default: // "rpc", "mc", and anything else
+		return 1
+	}
+}
+
+// formatDuration renders d in whole milliseconds when it has no fractional
+// millisecond, and in microseconds otherwise.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Microsecond)
+	if d%time.Millisecond == 0 {
+		return fmt.Sprintf("%dms", d/time.Millisecond)
 	}
+	return fmt.Sprintf("%dus", d/time.Microsecond)
 }
 
 func writeFile(path string, content string) error {
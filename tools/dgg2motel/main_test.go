@@ -31,19 +31,85 @@ func TestCleanName(t *testing.T) {
 func TestDurationForLabel(t *testing.T) {
 	tests := []struct {
 		label string
+		scale float64
 		want  string
 	}{
-		{"memcached", "1ms +/- 500us"},
-		{"Memcached", "1ms +/- 500us"},
-		{"blackhole", "5ms +/- 2ms"},
-		{"relay", "10ms +/- 5ms"},
-		{"normal", "20ms +/- 10ms"},
-		{"anything-else", "20ms +/- 10ms"},
+		{"memcached", 1, "1ms +/- 500us"},
+		{"Memcached", 1, "1ms +/- 500us"},
+		{"blackhole", 1, "5ms +/- 2ms"},
+		{"relay", 1, "10ms +/- 5ms"},
+		{"normal", 1, "20ms +/- 10ms"},
+		{"anything-else", 1, "20ms +/- 10ms"},
+		{"normal", 1.5, "30ms +/- 15ms"},
+		{"memcached", 1.5, "1500us +/- 750us"},
+		{"blackhole", 0.5, "2500us +/- 1ms"},
 	}
 	for _, tt := range tests {
-		got := durationForLabel(tt.label)
+		got := durationForLabel(tt.label, tt.scale)
 		if got != tt.want {
-			t.Errorf("durationForLabel(%q) = %q, want %q", tt.label, got, tt.want)
+			t.Errorf("durationForLabel(%q, %v) = %q, want %q", tt.label, tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestComparaScale(t *testing.T) {
+	tests := []struct {
+		compara string
+		want    float64
+	}{
+		{"rpc", 1},
+		{"mc", 1},
+		{"http", 1.5},
+		{"HTTP", 1.5},
+		{"db", 2},
+		{"mq", 0.5},
+		{"unknown", 1},
+		{"", 1},
+		{"3", 3},
+		{"0.25", 0.25},
+		{"0", 1},
+		{"-2", 1},
+	}
+	for _, tt := range tests {
+		got := comparaScale(tt.compara)
+		if got != tt.want {
+			t.Errorf("comparaScale(%q) = %v, want %v", tt.compara, got, tt.want)
+		}
+	}
+}
+
+func TestConvertOneComparaDurations(t *testing.T) {
+	input := `{
+		"nodes": [
+			{"node": "USER", "label": "relay"},
+			{"node": "MS_normal+2.1", "label": "normal"},
+			{"node": "MS_normal+3.1", "label": "normal"},
+			{"node": "MS_normal+4.1", "label": "normal"},
+			{"node": "MS_normal+5.1", "label": "normal"}
+		],
+		"edges": [
+			{"rpcid": "0", "um": "USER", "dm": "MS_normal+2.1", "time": 1, "compara": "rpc"},
+			{"rpcid": "0.1", "um": "MS_normal+2.1", "dm": "MS_normal+3.1", "time": 1, "compara": "db"},
+			{"rpcid": "0.2", "um": "MS_normal+2.1", "dm": "MS_normal+4.1", "time": 1, "compara": "0.5"},
+			{"rpcid": "0.3", "um": "MS_normal+2.1", "dm": "MS_normal+5.1", "time": 1, "compara": "3"}
+		],
+		"num": 1
+	}`
+
+	got, err := convertOne([]byte(input), convertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"normal-2-1": "20ms +/- 10ms",
+		"normal-3-1": "40ms +/- 20ms",
+		"normal-4-1": "10ms +/- 5ms",
+		"normal-5-1": "60ms +/- 30ms",
+	}
+	for svc, duration := range want {
+		block := svc + ":\n    operations:\n      handle:\n        duration: " + duration + "\n"
+		if !strings.Contains(got, block) {
+			t.Errorf("expected %s to have duration %q, got:\n%s", svc, duration, got)
 		}
 	}
 }
@@ -62,7 +128,7 @@ func TestConvertOne(t *testing.T) {
 		"num": 100
 	}`
 
-	got, err := convertOne([]byte(input))
+	got, err := convertOne([]byte(input), convertOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -94,7 +160,7 @@ func TestConvertOneFuncSuffix(t *testing.T) {
 		"num": 50
 	}`
 
-	got, err := convertOne([]byte(input))
+	got, err := convertOne([]byte(input), convertOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -127,7 +193,7 @@ func TestConvertOneCallCount(t *testing.T) {
 		"num": 10
 	}`
 
-	got, err := convertOne([]byte(input))
+	got, err := convertOne([]byte(input), convertOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -149,7 +215,7 @@ func TestConvertOneDanglingEdge(t *testing.T) {
 		"num": 1
 	}`
 
-	got, err := convertOne([]byte(input))
+	got, err := convertOne([]byte(input), convertOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -161,7 +227,7 @@ func TestConvertOneDanglingEdge(t *testing.T) {
 
 func TestConvertOneEmptyGraph(t *testing.T) {
 	input := `{"nodes": [], "edges": [], "num": 0}`
-	_, err := convertOne([]byte(input))
+	_, err := convertOne([]byte(input), convertOptions{})
 	if err == nil {
 		t.Error("expected error for empty graph")
 	}
@@ -180,7 +246,7 @@ func TestConvertOneNameCollision(t *testing.T) {
 		"num": 1
 	}`
 
-	_, err := convertOne([]byte(input))
+	_, err := convertOne([]byte(input), convertOptions{})
 	if err == nil {
 		t.Error("expected error for name collision")
 	}
@@ -188,3 +254,74 @@ func TestConvertOneNameCollision(t *testing.T) {
 		t.Errorf("expected collision error, got: %v", err)
 	}
 }
+
+func TestConvertOneErrorLabel(t *testing.T) {
+	input := `{
+		"nodes": [
+			{"node": "USER", "label": "relay"},
+			{"node": "MS_normal+2.1", "label": "normal"},
+			{"node": "MS_blackhole.1_func1", "label": "blackhole"}
+		],
+		"edges": [
+			{"rpcid": "0", "um": "USER", "dm": "MS_normal+2.1", "time": 1, "compara": "rpc"},
+			{"rpcid": "0.1", "um": "MS_normal+2.1", "dm": "MS_blackhole.1_func1", "time": 1, "compara": "rpc"}
+		],
+		"num": 1
+	}`
+
+	opts, err := newConvertOptions("blackhole", "2.5%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := convertOne([]byte(input), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "      func1:\n        duration: 5ms +/- 2ms\n        error_rate: 2.5%\n") {
+		t.Errorf("expected blackhole-1.func1 to get error_rate 2.5%%, got:\n%s", got)
+	}
+	if strings.Count(got, "error_rate:") != 1 {
+		t.Errorf("expected only the blackhole operation to get an error rate, got:\n%s", got)
+	}
+
+	opts, err = newConvertOptions("normal", "10%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = convertOne([]byte(input), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "      handle:\n        duration: 20ms +/- 10ms\n        error_rate: 10%\n") {
+		t.Errorf("expected normal-2-1.handle to get error_rate 10%%, got:\n%s", got)
+	}
+	if strings.Count(got, "error_rate:") != 1 {
+		t.Errorf("expected only the normal operation to get an error rate, got:\n%s", got)
+	}
+}
+
+func TestNewConvertOptions(t *testing.T) {
+	tests := []struct {
+		rate    string
+		want    convertOptions
+		wantErr bool
+	}{
+		{"5%", convertOptions{errorLabel: "blackhole", errorRate: "5%"}, false},
+		{"0.1%", convertOptions{errorLabel: "blackhole", errorRate: "0.1%"}, false},
+		{"0%", convertOptions{}, false},
+		{"5", convertOptions{}, true},
+		{"101%", convertOptions{}, true},
+		{"-1%", convertOptions{}, true},
+		{"x%", convertOptions{}, true},
+	}
+	for _, tt := range tests {
+		got, err := newConvertOptions("blackhole", tt.rate)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("newConvertOptions(%q) error = %v, wantErr %v", tt.rate, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("newConvertOptions(%q) = %+v, want %+v", tt.rate, got, tt.want)
+		}
+	}
+}